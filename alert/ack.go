@@ -0,0 +1,186 @@
+package alert
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrAlertNotFound 没有该指纹的触发中告警
+	ErrAlertNotFound = errors.New("alert not found")
+	// ErrAlreadyAcknowledged 告警已被确认
+	ErrAlreadyAcknowledged = errors.New("alert already acknowledged")
+)
+
+// maxAlertEvents 保留的告警生命周期事件数
+const maxAlertEvents = 200
+
+// 告警生命周期事件类型
+const (
+	AlertEventFiring       = "firing"
+	AlertEventEscalated    = "escalated"
+	AlertEventAcknowledged = "acknowledged"
+	AlertEventResolved     = "resolved"
+)
+
+// AlertEvent 告警生命周期中的一次状态变化
+type AlertEvent struct {
+	Time        time.Time `json:"time"`
+	Fingerprint string    `json:"fingerprint"`
+	Alertname   string    `json:"alertname"`
+	Kind        string    `json:"kind"`
+	Detail      string    `json:"detail,omitempty"`
+}
+
+// AlertState 触发中告警的生命周期状态
+type AlertState struct {
+	Alert
+	State           string     `json:"state"` // suppressed (维护窗口内未通知)/firing/acknowledged
+	NotifiedAt      *time.Time `json:"notified_at,omitempty"`
+	EscalationLevel int        `json:"escalation_level,omitempty"` // 匹配升级策略时已通知到的级数
+	AckedBy         string     `json:"acked_by,omitempty"`
+	AckedAt         *time.Time `json:"acked_at,omitempty"`
+	AckComment      string     `json:"ack_comment,omitempty"`
+}
+
+// SetAckSecret 设置签名确认链接的密钥，与 externalURL 都不为空时触发的告警附带确认链接
+func (e *Engine) SetAckSecret(secret string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ackSecret = secret
+}
+
+// ackSignature 确认链接的签名，绑定告警指纹和开始时间，告警恢复后再次触发时旧链接失效
+func ackSignature(secret, fingerprint string, startsAt time.Time) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fingerprint + "\n" + strconv.FormatInt(startsAt.Unix(), 10)))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// ackURL 告警的确认页面地址，未配置密钥或外部访问地址时为空，调用方需持有 e.mu
+func (e *Engine) ackURL(a *Alert) string {
+	if e.ackSecret == "" || e.externalURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/alerts/%s/ack?sig=%s", strings.TrimRight(e.externalURL, "/"),
+		url.PathEscape(a.Fingerprint), ackSignature(e.ackSecret, a.Fingerprint, a.StartsAt))
+}
+
+// VerifyAck 校验确认链接的签名
+func (e *Engine) VerifyAck(fingerprint, sig string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.ackSecret == "" || sig == "" {
+		return false
+	}
+	state := e.stateByFingerprintLocked(fingerprint)
+	if state == nil {
+		return false
+	}
+	expected := ackSignature(e.ackSecret, fingerprint, state.alert.StartsAt)
+	return hmac.Equal([]byte(sig), []byte(expected))
+}
+
+// stateByFingerprintLocked 查找触发中的告警，调用方需持有 e.mu
+func (e *Engine) stateByFingerprintLocked(fingerprint string) *ruleState {
+	for _, state := range e.states {
+		if state.alert != nil && state.alert.Fingerprint == fingerprint {
+			return state
+		}
+	}
+	return nil
+}
+
+// Acknowledge 确认触发中的告警，停止后续升级；恢复通知仍发送到所有已通知过的渠道
+func (e *Engine) Acknowledge(ctx context.Context, fingerprint, by, comment string) (AlertState, error) {
+	now := time.Now()
+	e.mu.Lock()
+	var key string
+	var state *ruleState
+	for k, s := range e.states {
+		if s.alert != nil && s.alert.Fingerprint == fingerprint {
+			key, state = k, s
+			break
+		}
+	}
+	if state == nil {
+		e.mu.Unlock()
+		return AlertState{}, ErrAlertNotFound
+	}
+	if !state.ackedAt.IsZero() {
+		result := state.view()
+		e.mu.Unlock()
+		return result, ErrAlreadyAcknowledged
+	}
+	state.ackedBy, state.ackedAt, state.ackComment = by, now, comment
+	e.recordEventLocked(AlertEventAcknowledged, *state.alert, now, by)
+	result := state.view()
+	saved := state.persisted(key)
+	// 解锁后告警可能已恢复 (state.alert 被置为 nil)，日志使用持锁时取出的名称
+	alertname := state.alert.Labels["alertname"]
+	e.mu.Unlock()
+
+	e.logger.Info(fmt.Sprintf("告警已确认: %s", alertname), fmt.Sprintf("确认人: %s %s", by, comment))
+	e.saveStates(ctx, []persistedState{saved})
+	return result, nil
+}
+
+// Lifecycle 触发中告警的生命周期状态，按开始时间升序
+func (e *Engine) Lifecycle() []AlertState {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	states := []AlertState{}
+	for _, state := range e.states {
+		if state.alert != nil {
+			states = append(states, state.view())
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].StartsAt.Before(states[j].StartsAt) })
+	return states
+}
+
+// Events 最近的告警生命周期事件，按时间升序
+func (e *Engine) Events() []AlertEvent {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]AlertEvent{}, e.events...)
+}
+
+// recordEventLocked 记录生命周期事件，只保留最近 maxAlertEvents 条，调用方需持有 e.mu
+func (e *Engine) recordEventLocked(kind string, a Alert, now time.Time, detail string) {
+	e.events = append(e.events, AlertEvent{
+		Time:        now,
+		Fingerprint: a.Fingerprint,
+		Alertname:   a.Labels["alertname"],
+		Kind:        kind,
+		Detail:      detail,
+	})
+	if len(e.events) > maxAlertEvents {
+		e.events = e.events[len(e.events)-maxAlertEvents:]
+	}
+}
+
+// view 规则状态对外的表示，调用方需持有 e.mu 且 s.alert 不为 nil
+func (s *ruleState) view() AlertState {
+	v := AlertState{Alert: *s.alert, State: "suppressed", EscalationLevel: s.escalated, AckedBy: s.ackedBy, AckComment: s.ackComment}
+	if s.notified {
+		v.State = "firing"
+		notifiedAt := s.notifiedAt
+		v.NotifiedAt = &notifiedAt
+	}
+	if !s.ackedAt.IsZero() {
+		v.State = "acknowledged"
+		ackedAt := s.ackedAt
+		v.AckedAt = &ackedAt
+	}
+	return v
+}
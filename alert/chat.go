@@ -0,0 +1,365 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// defaultMessageTemplate 聊天机器人消息的内置模板
+const defaultMessageTemplate = `{{range .Alerts}}[{{if eq .Status "firing"}}告警{{else}}恢复{{end}}] {{index .Labels "alertname"}} ({{index .Labels "severity"}})
+{{index .Annotations "summary"}}：{{index .Annotations "description"}}
+实例：{{index .Labels "instance"}}
+开始：{{formatTime .StartsAt}}{{if eq .Status "resolved"}}
+恢复：{{formatTime .EndsAt}}{{else if index .Annotations "ack_url"}}
+确认：{{index .Annotations "ack_url"}}{{end}}
+{{end}}`
+
+// messageTopErrors 消息模板数据中最多包含的错误数
+const messageTopErrors = 3
+
+// messageData 消息模板数据
+// 组内告警的 profile 标签不同时，服务器相关的字段取第一条告警的服务器
+type messageData struct {
+	Status        string // 组内任一告警触发中则为 firing
+	Alerts        []Alert
+	Firing        int // 触发中的告警数
+	Resolved      int // 已恢复的告警数
+	Profile       string
+	ProfileLabels map[string]string // 服务器标签，包括 profile
+	LastError     string            // 服务器最近一次连接错误
+	Errors        []messageError    // 服务器出现次数最多的错误
+}
+
+// messageError 消息模板中的错误统计
+type messageError struct {
+	Type     string
+	Code     string
+	Count    int
+	LastSeen time.Time
+	Example  string
+}
+
+// newMessageData 根据告警和所属服务器的状态生成模板数据
+func newMessageData(alerts []Alert) messageData {
+	data := messageData{Status: groupStatus(alerts), Alerts: alerts, Profile: database.DefaultProfile}
+	for _, a := range alerts {
+		if a.Status == "firing" {
+			data.Firing++
+		} else {
+			data.Resolved++
+		}
+	}
+	if len(alerts) > 0 && alerts[0].Labels["profile"] != "" {
+		data.Profile = alerts[0].Labels["profile"]
+	}
+
+	registry := database.GetProfileRegistry()
+	data.ProfileLabels = registry.Labels(data.Profile)
+	if reconnector, ok := registry.Reconnector(data.Profile); ok {
+		if err := reconnector.GetLastError(); err != nil {
+			data.LastError = err.Error()
+		}
+	}
+	if analyzer, ok := registry.ErrorAnalyzer(data.Profile); ok {
+		// 复制需要的字段，渲染时不持有错误分析器的数据
+		for _, s := range analyzer.GetTopErrors(messageTopErrors) {
+			e := messageError{Type: string(s.Type), Code: s.Code, Count: s.Count, LastSeen: s.LastSeen}
+			if len(s.Examples) > 0 {
+				e.Example = s.Examples[len(s.Examples)-1]
+			}
+			data.Errors = append(data.Errors, e)
+		}
+	}
+	return data
+}
+
+// sampleMessageData 检查模板时使用的示例数据
+func sampleMessageData() messageData {
+	now := time.Now()
+	return messageData{
+		Status: "firing",
+		Alerts: []Alert{{
+			Status:      "firing",
+			Labels:      map[string]string{"alertname": "ReconnectFailures", "severity": "critical", "instance": "127.0.0.1:3306", "profile": database.DefaultProfile},
+			Annotations: map[string]string{"summary": "连续重连失败", "description": "5 >= 3"},
+			StartsAt:    now,
+			EndsAt:      now,
+		}},
+		Firing:        1,
+		Profile:       database.DefaultProfile,
+		ProfileLabels: map[string]string{"profile": database.DefaultProfile},
+		LastError:     "dial tcp 127.0.0.1:3306: connect: connection refused",
+		Errors:        []messageError{{Type: "network", Code: "NET_001", Count: 5, LastSeen: now, Example: "connection refused"}},
+	}
+}
+
+// messageFuncs 消息模板可用的函数
+// formatTime 按 TIME_ZONE 和 TIME_FORMAT_DISPLAY 格式化时间，default 在值为空时使用默认值
+var messageFuncs = template.FuncMap{
+	"formatTime": config.FormatDisplayTime,
+	"join":       func(items []string, sep string) string { return strings.Join(items, sep) },
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// messageRenderer 按模板渲染告警文本
+type messageRenderer struct {
+	tmpl *template.Template
+}
+
+// newMessageRenderer 解析渠道模板，text 为空时使用默认模板 fallback，两者都为空时使用内置模板
+// 模板以 @ 开头时从文件读取；解析后用示例数据试渲染一次，引用不存在的字段时在启动时报错
+func newMessageRenderer(name, text, fallback string) (*messageRenderer, error) {
+	if text == "" {
+		text = fallback
+	}
+	if text == "" {
+		text = defaultMessageTemplate
+	}
+	if path, ok := strings.CutPrefix(text, "@"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s template: %w", name, err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New(name).Funcs(messageFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s template: %w", name, err)
+	}
+	if err := tmpl.Execute(io.Discard, sampleMessageData()); err != nil {
+		return nil, fmt.Errorf("check %s template: %w", name, err)
+	}
+	return &messageRenderer{tmpl: tmpl}, nil
+}
+
+// render 渲染告警消息
+func (r *messageRenderer) render(alerts []Alert) (string, error) {
+	var buf bytes.Buffer
+	if err := r.tmpl.Execute(&buf, newMessageData(alerts)); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// DingTalkNotifier 钉钉自定义机器人
+type DingTalkNotifier struct {
+	webhook  string
+	secret   string
+	renderer *messageRenderer
+	client   *http.Client
+}
+
+// Name 渠道名称
+func (d *DingTalkNotifier) Name() string {
+	return "dingtalk"
+}
+
+// Notify 发送文本消息，配置了密钥时按加签方式附加 timestamp 与 sign
+func (d *DingTalkNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	text, err := d.renderer.render(alerts)
+	if err != nil {
+		return err
+	}
+
+	target := d.webhook
+	if d.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(d.secret))
+		mac.Write([]byte(timestamp + "\n" + d.secret))
+		sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+		target += separator + "timestamp=" + timestamp + "&sign=" + url.QueryEscape(sign)
+	}
+
+	payload := map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": text},
+	}
+	var resp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := postJSON(ctx, d.client, target, payload, &resp); err != nil {
+		return err
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("dingtalk: %d %s", resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
+
+// FeishuNotifier 飞书自定义机器人
+type FeishuNotifier struct {
+	webhook  string
+	secret   string
+	renderer *messageRenderer
+	client   *http.Client
+}
+
+// Name 渠道名称
+func (f *FeishuNotifier) Name() string {
+	return "feishu"
+}
+
+// Notify 发送文本消息，配置了密钥时在请求体中附加签名
+func (f *FeishuNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	text, err := f.renderer.render(alerts)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"msg_type": "text",
+		"content":  map[string]string{"text": text},
+	}
+	if f.secret != "" {
+		// 飞书以 "timestamp\nsecret" 作为 HMAC 密钥对空消息签名
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(timestamp+"\n"+f.secret))
+		payload["timestamp"] = timestamp
+		payload["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	var resp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := postJSON(ctx, f.client, f.webhook, payload, &resp); err != nil {
+		return err
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("feishu: %d %s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+// TelegramNotifier Telegram 机器人
+type TelegramNotifier struct {
+	apiURL   string
+	token    string
+	chatID   string
+	renderer *messageRenderer
+	client   *http.Client
+}
+
+// Name 渠道名称
+func (t *TelegramNotifier) Name() string {
+	return "telegram"
+}
+
+// Notify 通过 sendMessage 发送文本消息
+func (t *TelegramNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	text, err := t.renderer.render(alerts)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"chat_id": t.chatID,
+		"text":    text,
+	}
+	var resp struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	target := strings.TrimRight(t.apiURL, "/") + "/bot" + t.token + "/sendMessage"
+	if err := postJSON(ctx, t.client, target, payload, &resp); err != nil {
+		// 错误信息中不包含带令牌的地址
+		return fmt.Errorf("telegram sendMessage: %w", unwrapURLError(err))
+	}
+	if !resp.OK {
+		return fmt.Errorf("telegram: %s", resp.Description)
+	}
+	return nil
+}
+
+// ChatNotifiersFromConfig 根据配置创建聊天机器人通知渠道
+func ChatNotifiersFromConfig(cfg *config.AlertConfig) ([]Notifier, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	var notifiers []Notifier
+
+	if cfg.DingTalkWebhook != "" {
+		renderer, err := newMessageRenderer("dingtalk", cfg.DingTalkTemplate, cfg.Template)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, &DingTalkNotifier{webhook: cfg.DingTalkWebhook, secret: cfg.DingTalkSecret, renderer: renderer, client: client})
+	}
+	if cfg.FeishuWebhook != "" {
+		renderer, err := newMessageRenderer("feishu", cfg.FeishuTemplate, cfg.Template)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, &FeishuNotifier{webhook: cfg.FeishuWebhook, secret: cfg.FeishuSecret, renderer: renderer, client: client})
+	}
+	if cfg.TelegramBotToken != "" && cfg.TelegramChatID != "" {
+		renderer, err := newMessageRenderer("telegram", cfg.TelegramTemplate, cfg.Template)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, &TelegramNotifier{apiURL: cfg.TelegramAPIURL, token: cfg.TelegramBotToken, chatID: cfg.TelegramChatID, renderer: renderer, client: client})
+	}
+	return notifiers, nil
+}
+
+// postJSON 发送 JSON 请求并解析 JSON 响应
+func postJSON(ctx context.Context, client *http.Client, target string, payload, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 && !json.Valid(data) {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, out)
+}
+
+// unwrapURLError 去掉 *url.Error 中的请求地址
+func unwrapURLError(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package alert
+
+import (
+	"context"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/checks"
+)
+
+// CheckRules 为每个扩展检查生成 CheckWarning 和 CheckCritical 规则，取值来自检查最近一次的结果
+// 告警附加 check 标签，描述为检查输出的消息；结果为 unknown 或尚未运行时按暂无数据处理
+func CheckRules(runner *checks.Runner, forDuration time.Duration) []Rule {
+	var rules []Rule
+	for _, result := range runner.Results() {
+		name := result.Check
+		labels := map[string]string{"check": name}
+		description := func() string {
+			last, _ := runner.Result(name)
+			return last.Message
+		}
+		for _, level := range []struct {
+			alertname, severity, status, summary string
+		}{
+			{"CheckWarning", "warning", checks.StatusWarning, "扩展检查结果为 warning"},
+			{"CheckCritical", "critical", checks.StatusCritical, "扩展检查结果为 critical"},
+		} {
+			status := level.status
+			rules = append(rules, Rule{
+				Name:        level.alertname,
+				Severity:    level.severity,
+				Summary:     level.summary,
+				Threshold:   0,
+				For:         forDuration,
+				Labels:      labels,
+				Description: description,
+				Value: func(ctx context.Context) (float64, bool, error) {
+					last, ok := runner.Result(name)
+					if !ok || last.Status == checks.StatusUnknown {
+						return 0, false, nil
+					}
+					if last.Status == status {
+						return 1, true, nil
+					}
+					return 0, true, nil
+				},
+			})
+		}
+	}
+	return rules
+}
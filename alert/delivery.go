@@ -0,0 +1,80 @@
+package alert
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// DeliveryStatus 通知渠道 (包括心跳) 最近的发送情况
+type DeliveryStatus struct {
+	Channel             string     `json:"channel"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"` // 从未成功时为空
+	LastFailure         *time.Time `json:"last_failure,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Deliveries          int        `json:"deliveries"`
+	Failures            int        `json:"failures"`
+}
+
+// deliveries 各渠道的发送情况，进程内所有通知渠道共用
+var deliveries = struct {
+	mu       sync.Mutex
+	channels map[string]*DeliveryStatus
+}{channels: make(map[string]*DeliveryStatus)}
+
+// recordDelivery 记录一次发送结果，失败时交给错误分析器按 notification 类型统计
+func recordDelivery(name string, err error) {
+	channel := channelLabel(name)
+	now := time.Now()
+
+	deliveries.mu.Lock()
+	s, ok := deliveries.channels[channel]
+	if !ok {
+		s = &DeliveryStatus{Channel: channel}
+		deliveries.channels[channel] = s
+	}
+	s.Deliveries++
+	if err == nil {
+		s.LastSuccess = &now
+		s.ConsecutiveFailures = 0
+		deliveries.mu.Unlock()
+		return
+	}
+	s.Failures++
+	s.ConsecutiveFailures++
+	s.LastFailure = &now
+	s.LastError = err.Error()
+	failures := s.ConsecutiveFailures
+	deliveries.mu.Unlock()
+
+	database.GetErrorAnalyzer().AnalyzeNotificationError(channel, err, failures)
+}
+
+// DeliveryStatuses 各渠道的发送情况，按渠道名称排序，尚未发送过的渠道不包含在内
+func DeliveryStatuses() []DeliveryStatus {
+	deliveries.mu.Lock()
+	defer deliveries.mu.Unlock()
+	statuses := make([]DeliveryStatus, 0, len(deliveries.channels))
+	for _, s := range deliveries.channels {
+		statuses = append(statuses, *s)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Channel < statuses[j].Channel })
+	return statuses
+}
+
+// channelLabel 渠道的显示名称，名称中带地址的 (如 webhook) 只保留主机名，不暴露完整地址
+func channelLabel(name string) string {
+	kind, rawURL, ok := strings.Cut(name, " ")
+	if !ok {
+		return name
+	}
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return kind + " " + u.Host
+	}
+	return kind
+}
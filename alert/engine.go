@@ -0,0 +1,458 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/storage"
+)
+
+// Alert 单条告警，字段与 Alertmanager webhook 中的 alerts 元素一致
+type Alert struct {
+	Status       string            `json:"status"` // firing/resolved
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// Rule 阈值规则：取值持续超过阈值 For 时长后触发
+type Rule struct {
+	Name      string
+	Severity  string
+	Summary   string
+	Threshold float64
+	For       time.Duration
+	// Labels 附加在告警上的标签 (如服务器标签)，不会覆盖 alertname 和 severity
+	Labels map[string]string
+	// Value 获取当前值，ok 为 false 表示暂无数据 (按未超过阈值处理)
+	Value func(ctx context.Context) (value float64, ok bool, err error)
+	// Description 告警的描述，为 nil 或返回空字符串时描述为当前值与阈值
+	Description func() string
+}
+
+// Notifier 告警通知渠道
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, alerts []Alert) error
+}
+
+// ruleState 规则的评估状态
+type ruleState struct {
+	pendingSince time.Time
+	alert        *Alert
+	notified     bool      // 触发通知是否已发送 (维护窗口内会延后发送)
+	notifiedAt   time.Time // 首次发送触发通知的时间，升级策略从此时开始计时
+	escalated    int       // 匹配升级策略时已通知到的级数
+	ackedBy      string    // 确认人，确认后停止升级
+	ackedAt      time.Time
+	ackComment   string
+}
+
+// outgoing 待发送的通知
+type outgoing struct {
+	alert    Alert
+	channels []string // 不为 nil 时只发送到这些渠道 (升级策略)，否则按路由规则选择
+	level    int      // 升级通知的级别，从 2 开始，其余通知为 0
+}
+
+// Engine 定期评估规则并在触发/恢复时发送通知
+type Engine struct {
+	mu          sync.RWMutex
+	rules       []Rule
+	states      map[string]*ruleState
+	notifiers   []Notifier
+	routes      []Route
+	escalations []Escalation
+	throttle    *Throttle       // 为 nil 时不限流
+	storage     storage.Storage // 保存告警状态，为 nil 时重启后状态丢失
+	instance    string
+	externalURL string
+	ackSecret   string       // 签名确认链接的密钥
+	events      []AlertEvent // 最近的生命周期事件
+	stop        chan struct{}
+	lastEval    time.Time
+	logger      *database.ComponentLogger // 规则评估日志
+	notifyLog   *database.ComponentLogger // 通知发送日志
+}
+
+// NewEngine 创建规则引擎
+func NewEngine(rules []Rule, notifiers []Notifier, instance, externalURL string) *Engine {
+	return &Engine{
+		rules:       rules,
+		states:      make(map[string]*ruleState),
+		notifiers:   notifiers,
+		instance:    instance,
+		externalURL: externalURL,
+		logger:      database.GetDatabaseLogger().Component(database.ComponentScheduler),
+		notifyLog:   database.GetDatabaseLogger().Component(database.ComponentNotifier),
+	}
+}
+
+// SetRoutes 设置按标签路由通知渠道的规则
+func (e *Engine) SetRoutes(routes []Route) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.routes = routes
+}
+
+// SetEscalations 设置告警升级策略
+func (e *Engine) SetEscalations(escalations []Escalation) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.escalations = escalations
+}
+
+// SetThrottle 设置通知限流，为 nil 时不限流
+func (e *Engine) SetThrottle(t *Throttle) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.throttle = t
+}
+
+// escalationFor 告警匹配的第一条升级策略，没有匹配时返回 nil，调用方需持有 e.mu
+func (e *Engine) escalationFor(labels map[string]string) *Escalation {
+	for i := range e.escalations {
+		if e.escalations[i].Matches(labels) {
+			return &e.escalations[i]
+		}
+	}
+	return nil
+}
+
+// Start 按间隔开始评估
+func (e *Engine) Start(interval time.Duration) {
+	e.mu.Lock()
+	if e.stop != nil || interval <= 0 {
+		e.mu.Unlock()
+		return
+	}
+	e.stop = make(chan struct{})
+	stop := e.stop
+	e.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				e.Evaluate(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop 停止评估
+func (e *Engine) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stop != nil {
+		close(e.stop)
+		e.stop = nil
+	}
+}
+
+// Evaluate 立即评估所有规则，规则带有 profile 标签时按该服务器的维护窗口暂停通知
+func (e *Engine) Evaluate(ctx context.Context) {
+	now := time.Now()
+	var changed []outgoing
+	var dirty []persistedState
+
+	for _, rule := range e.rules {
+		value, ok, err := rule.Value(ctx)
+		if err != nil {
+			// 取值失败时保持原状态，避免因查询抖动反复触发/恢复
+			e.logger.Debug(fmt.Sprintf("告警规则 %s 取值失败", rule.Name), err.Error())
+			continue
+		}
+
+		profile := rule.Labels["profile"]
+		if profile == "" {
+			profile = database.DefaultProfile
+		}
+		maintenance := database.GetMaintenanceManager().Active(profile)
+
+		e.mu.Lock()
+		key := ruleKey(rule)
+		state, exists := e.states[key]
+		if !exists {
+			state = &ruleState{}
+			e.states[key] = state
+		}
+		before := *state
+
+		if ok && value > rule.Threshold {
+			if state.pendingSince.IsZero() {
+				state.pendingSince = now
+			}
+			if state.alert == nil && now.Sub(state.pendingSince) >= rule.For {
+				state.alert = e.newAlert(rule, value, state.pendingSince)
+				state.notified = false
+			}
+			// 维护窗口内只记录状态，窗口结束后仍在触发的告警再发送通知
+			if state.alert != nil && !state.notified && maintenance == nil {
+				state.notified = true
+				state.notifiedAt = now
+				out := outgoing{alert: *state.alert}
+				if escalation := e.escalationFor(state.alert.Labels); escalation != nil {
+					out.channels = escalation.Levels[0].Channels
+					state.escalated = 1
+				}
+				changed = append(changed, out)
+				e.recordEventLocked(AlertEventFiring, *state.alert, now, state.alert.Annotations["description"])
+			}
+			// 首次通知之后持续未恢复且未被确认时依次通知升级策略的后续各级，维护窗口内暂停升级
+			if state.alert != nil && state.notified && state.ackedAt.IsZero() && maintenance == nil {
+				if escalation := e.escalationFor(state.alert.Labels); escalation != nil {
+					for state.escalated < len(escalation.Levels) && now.Sub(state.notifiedAt) >= escalation.Levels[state.escalated].After {
+						state.escalated++
+						changed = append(changed, outgoing{
+							alert:    escalatedAlert(*state.alert, state.escalated),
+							channels: escalation.Levels[state.escalated-1].Channels,
+							level:    state.escalated,
+						})
+						e.recordEventLocked(AlertEventEscalated, *state.alert, now,
+							fmt.Sprintf("第 %d 级: %s", state.escalated, strings.Join(escalation.Levels[state.escalated-1].Channels, ", ")))
+					}
+				}
+			}
+		} else {
+			state.pendingSince = time.Time{}
+			if state.alert != nil {
+				// 未发送过触发通知的告警无需发送恢复通知，升级过的告警通知所有已通知过的渠道
+				if state.notified {
+					resolved := *state.alert
+					resolved.Status = "resolved"
+					resolved.EndsAt = now
+					out := outgoing{alert: resolved}
+					if escalation := e.escalationFor(resolved.Labels); escalation != nil && state.escalated > 0 {
+						levels := state.escalated
+						if levels > len(escalation.Levels) {
+							levels = len(escalation.Levels)
+						}
+						out.channels = escalation.channels(levels)
+					}
+					changed = append(changed, out)
+					e.recordEventLocked(AlertEventResolved, resolved, now, "")
+				}
+				state.alert = nil
+				state.notified = false
+				state.notifiedAt = time.Time{}
+				state.escalated = 0
+				state.ackedBy, state.ackedAt, state.ackComment = "", time.Time{}, ""
+			}
+		}
+		if *state != before {
+			dirty = append(dirty, state.persisted(key))
+		}
+		e.mu.Unlock()
+	}
+
+	for _, out := range changed {
+		a := out.alert
+		switch {
+		case out.level > 0:
+			e.logger.Warn(fmt.Sprintf("告警升级: %s (第 %d 级)", a.Labels["alertname"], out.level), strings.Join(out.channels, ", "))
+		case a.Status == "firing":
+			e.logger.Warn(fmt.Sprintf("告警触发: %s", a.Labels["alertname"]), a.Annotations["description"])
+		default:
+			e.logger.Info(fmt.Sprintf("告警恢复: %s", a.Labels["alertname"]))
+		}
+	}
+	if len(changed) > 0 {
+		e.deliver(ctx, changed)
+	}
+	e.flushThrottle(ctx, now)
+	e.saveStates(ctx, dirty)
+
+	e.mu.Lock()
+	e.lastEval = now
+	e.mu.Unlock()
+}
+
+// LastEvaluated 最近一次评估的时间，尚未评估时为零值
+func (e *Engine) LastEvaluated() time.Time {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.lastEval
+}
+
+// Active 获取当前处于触发状态的告警
+func (e *Engine) Active() []Alert {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	alerts := []Alert{}
+	for _, state := range e.states {
+		if state.alert != nil {
+			alerts = append(alerts, *state.alert)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].StartsAt.Before(alerts[j].StartsAt)
+	})
+	return alerts
+}
+
+// newAlert 根据规则构造触发中的告警
+func (e *Engine) newAlert(rule Rule, value float64, startsAt time.Time) *Alert {
+	labels := map[string]string{
+		"instance": e.instance,
+		"service":  "block-checker",
+	}
+	for k, v := range rule.Labels {
+		labels[k] = v
+	}
+	labels["alertname"] = rule.Name
+	labels["severity"] = rule.Severity
+	a := &Alert{
+		Status: "firing",
+		Labels: labels,
+		Annotations: map[string]string{
+			"summary":     rule.Summary,
+			"description": fmt.Sprintf("当前值 %g，阈值 %g", value, rule.Threshold),
+		},
+		StartsAt:     startsAt,
+		GeneratorURL: e.externalURL,
+		Fingerprint:  fingerprint(labels),
+	}
+	if rule.Description != nil {
+		if description := rule.Description(); description != "" {
+			a.Annotations["description"] = description
+		}
+	}
+	if ackURL := e.ackURL(a); ackURL != "" {
+		a.Annotations["ack_url"] = ackURL
+	}
+	return a
+}
+
+// NotifyEvent 发送一次性事件通知 (如自动终止会话)，不参与规则评估，同样按路由规则选择通知渠道
+func (e *Engine) NotifyEvent(ctx context.Context, name, severity, summary, description string, extra map[string]string) {
+	labels := map[string]string{
+		"instance": e.instance,
+		"service":  "block-checker",
+	}
+	for k, v := range extra {
+		labels[k] = v
+	}
+	labels["alertname"] = name
+	labels["severity"] = severity
+	e.notify(ctx, []Alert{{
+		Status: "firing",
+		Labels: labels,
+		Annotations: map[string]string{
+			"summary":     summary,
+			"description": description,
+		},
+		StartsAt:     time.Now(),
+		GeneratorURL: e.externalURL,
+		Fingerprint:  fingerprint(labels),
+	}})
+}
+
+// notify 按路由规则将告警发送到对应的通知渠道
+func (e *Engine) notify(ctx context.Context, alerts []Alert) {
+	items := make([]outgoing, len(alerts))
+	for i, a := range alerts {
+		items[i] = outgoing{alert: a}
+	}
+	e.deliver(ctx, items)
+}
+
+// deliver 发送通知，指定了渠道的通知只发送到这些渠道，其余按路由规则选择
+func (e *Engine) deliver(ctx context.Context, items []outgoing) {
+	e.mu.RLock()
+	routes := e.routes
+	throttle := e.throttle
+	e.mu.RUnlock()
+
+	now := time.Now()
+	for _, n := range e.notifiers {
+		var routed []Alert
+		for _, item := range items {
+			if item.channels != nil {
+				if selects(item.channels, n) {
+					routed = append(routed, item.alert)
+				}
+			} else if routeSends(routes, n, item.alert) {
+				routed = append(routed, item.alert)
+			}
+		}
+		if throttle != nil {
+			routed = throttle.filter(n, routed, now)
+		}
+		if len(routed) == 0 {
+			continue
+		}
+		err := n.Notify(ctx, routed)
+		recordDelivery(n.Name(), err)
+		if err != nil {
+			e.notifyLog.Warn(fmt.Sprintf("告警通知发送失败 (%s)", channelLabel(n.Name())), err.Error())
+		}
+	}
+}
+
+// flushThrottle 发送限流到期的汇总消息，汇总消息本身不受限流
+func (e *Engine) flushThrottle(ctx context.Context, now time.Time) {
+	e.mu.RLock()
+	throttle := e.throttle
+	e.mu.RUnlock()
+	if throttle == nil {
+		return
+	}
+	for _, s := range throttle.summaries(e.notifiers, now) {
+		s.alert.Labels["instance"] = e.instance
+		e.logger.Info(fmt.Sprintf("告警通知限流汇总: %s", s.alert.Annotations["summary"]), s.alert.Annotations["description"])
+		for _, n := range e.notifiers {
+			if s.channel != "" && n.Name() != s.channel {
+				continue
+			}
+			err := n.Notify(ctx, []Alert{s.alert})
+			recordDelivery(n.Name(), err)
+			if err != nil {
+				e.notifyLog.Warn(fmt.Sprintf("告警通知发送失败 (%s)", channelLabel(n.Name())), err.Error())
+			}
+		}
+	}
+}
+
+// routeSends 判断告警是否应发送到该渠道：匹配第一条路由规则的告警只发送到规则中的渠道，未匹配的发送到所有渠道
+func routeSends(routes []Route, n Notifier, a Alert) bool {
+	for _, r := range routes {
+		if r.Matches(a.Labels) {
+			return r.sends(n)
+		}
+	}
+	return true
+}
+
+// escalatedAlert 升级通知中的告警，注释 escalation_level 为升级到的级别
+func escalatedAlert(a Alert, level int) Alert {
+	annotations := make(map[string]string, len(a.Annotations)+1)
+	for k, v := range a.Annotations {
+		annotations[k] = v
+	}
+	annotations["escalation_level"] = strconv.Itoa(level)
+	a.Annotations = annotations
+	return a
+}
+
+// ruleKey 规则状态的键，同名规则按附加标签区分 (如不同服务器的同一规则)
+func ruleKey(rule Rule) string {
+	if len(rule.Labels) == 0 {
+		return rule.Name
+	}
+	return rule.Name + "/" + fingerprint(rule.Labels)
+}
@@ -0,0 +1,79 @@
+package alert
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// EscalationLevel 升级策略中的一级：首次通知 After 之后告警仍未恢复时通知 Channels
+type EscalationLevel struct {
+	After    time.Duration
+	Channels []string
+}
+
+// Escalation 告警升级策略：标签全部匹配的告警先通知第一级渠道，持续未恢复时依次通知后续各级，恢复时通知所有已通知过的渠道
+type Escalation struct {
+	Matchers map[string]string
+	Levels   []EscalationLevel // 第一级的 After 为 0，之后各级递增
+}
+
+// ParseEscalations 解析告警升级策略，策略之间以分号分隔，每条策略为 "标签条件:渠道列表>时长>渠道列表..."
+// 例如 "profile=orders:dingtalk>15m>telegram>1h>webhook;severity=critical:feishu>10m>telegram"
+// 时长从首次通知算起；告警按顺序匹配第一条满足的策略，没有策略匹配的告警按路由规则发送
+func ParseEscalations(text string) ([]Escalation, error) {
+	var escalations []Escalation
+	for _, item := range strings.Split(text, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		matchers, chain, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("escalation %q: expected labels:channels>duration>channels", item)
+		}
+		selector, err := database.ParseLabelSelector(splitList(matchers))
+		if err != nil {
+			return nil, fmt.Errorf("escalation %q: %w", item, err)
+		}
+
+		parts := strings.Split(chain, ">")
+		if len(parts)%2 == 0 {
+			return nil, fmt.Errorf("escalation %q: expected channels>duration>channels", item)
+		}
+		escalation := Escalation{Matchers: selector}
+		var after time.Duration
+		for i := 0; i < len(parts); i += 2 {
+			if i > 0 {
+				d, err := time.ParseDuration(strings.TrimSpace(parts[i-1]))
+				if err != nil || d <= after {
+					return nil, fmt.Errorf("escalation %q: level %d: duration %q must be greater than %v", item, i/2+1, strings.TrimSpace(parts[i-1]), after)
+				}
+				after = d
+			}
+			channels := splitList(parts[i])
+			if len(channels) == 0 {
+				return nil, fmt.Errorf("escalation %q: level %d: no channels", item, i/2+1)
+			}
+			escalation.Levels = append(escalation.Levels, EscalationLevel{After: after, Channels: channels})
+		}
+		escalations = append(escalations, escalation)
+	}
+	return escalations, nil
+}
+
+// Matches 判断告警标签是否满足升级策略的条件
+func (p Escalation) Matches(labels map[string]string) bool {
+	return database.MatchLabels(labels, p.Matchers)
+}
+
+// channels 前 levels 级的渠道，用于发送恢复通知
+func (p Escalation) channels(levels int) []string {
+	var channels []string
+	for _, level := range p.Levels[:levels] {
+		channels = append(channels, level.Channels...)
+	}
+	return channels
+}
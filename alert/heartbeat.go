@@ -0,0 +1,118 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// Heartbeat 数据库健康时定期请求外部监控地址 (healthchecks.io 风格)
+// 心跳中断即由外部监控告警，block-checker 自身宕机时同样生效
+type Heartbeat struct {
+	mu     sync.Mutex
+	url    string
+	method string
+	store  database.Store
+	client *http.Client
+	stop   chan struct{}
+	logger *database.ComponentLogger
+}
+
+// NewHeartbeat 根据配置创建心跳推送
+func NewHeartbeat(store database.Store, cfg *config.HeartbeatConfig) *Heartbeat {
+	method := cfg.Method
+	if method != http.MethodPost {
+		method = http.MethodGet
+	}
+	return &Heartbeat{
+		url:    cfg.URL,
+		method: method,
+		store:  store,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: database.GetDatabaseLogger().Component(database.ComponentNotifier),
+	}
+}
+
+// Start 按间隔开始推送
+func (h *Heartbeat) Start(interval time.Duration) {
+	h.mu.Lock()
+	if h.stop != nil || interval <= 0 || h.url == "" {
+		h.mu.Unlock()
+		return
+	}
+	h.stop = make(chan struct{})
+	stop := h.stop
+	h.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		h.Beat(context.Background())
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				h.Beat(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop 停止推送
+func (h *Heartbeat) Stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stop != nil {
+		close(h.stop)
+		h.stop = nil
+	}
+}
+
+// Beat 数据库健康 (或处于维护窗口内) 时发送一次心跳
+func (h *Heartbeat) Beat(ctx context.Context) {
+	status := h.store.CheckStatus(ctx)
+	if status.Status != "OK" && !database.InMaintenance() {
+		h.logger.Debug("数据库不健康，跳过心跳", status.Error)
+		return
+	}
+
+	err := h.send(ctx, status.Status)
+	recordDelivery("heartbeat", err)
+	if err != nil {
+		h.logger.Warn("心跳推送失败", err.Error())
+	}
+}
+
+// send 请求心跳地址，POST 时以数据库状态作为请求体
+func (h *Heartbeat) send(ctx context.Context, status string) error {
+	var body io.Reader
+	if h.method == http.MethodPost {
+		body = strings.NewReader(status)
+	}
+	req, err := http.NewRequestWithContext(ctx, h.method, h.url, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("heartbeat: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package alert
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// NotifyIdleTrxAction 将空闲事务策略的每个操作作为事件通知发出，附加默认连接的标签
+func (e *Engine) NotifyIdleTrxAction(action database.IdleTrxAction) {
+	name, severity, summary := "IdleTransactionKilled", "warning", "空闲事务的会话已被自动终止"
+	switch {
+	case action.DryRun:
+		name, severity, summary = "IdleTransactionKillDryRun", "info", "[演练] 空闲事务的会话将被自动终止"
+	case !action.Killed:
+		name, summary = "IdleTransactionKillFailed", "自动终止空闲事务的会话失败"
+	}
+	description := action.Describe()
+	if action.Error != "" {
+		description += ": " + action.Error
+	}
+
+	labels := database.GetProfileRegistry().Labels(database.DefaultProfile)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels["session"] = strconv.FormatInt(action.SessionID, 10)
+	labels["user"] = action.User
+	labels["database"] = action.DB
+	e.NotifyEvent(context.Background(), name, severity, summary, description, labels)
+}
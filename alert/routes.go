@@ -0,0 +1,74 @@
+package alert
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// Route 告警路由：标签全部匹配的告警只发送到指定的通知渠道
+type Route struct {
+	Matchers map[string]string
+	Channels []string // 渠道名称 (dingtalk/feishu/telegram/webhook) 或 webhook 地址
+}
+
+// ParseRoutes 解析告警路由规则，规则之间以分号分隔，每条规则为 "标签条件:渠道列表"
+// 例如 "env=prod,team=payments:dingtalk,webhook;env=staging:telegram"
+// 告警按顺序匹配第一条满足的规则，没有规则匹配的告警发送到所有渠道
+func ParseRoutes(text string) ([]Route, error) {
+	var routes []Route
+	for _, item := range strings.Split(text, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		matchers, channels, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("route %q: expected labels:channels", item)
+		}
+		selector, err := database.ParseLabelSelector(splitList(matchers))
+		if err != nil {
+			return nil, fmt.Errorf("route %q: %w", item, err)
+		}
+		route := Route{Matchers: selector, Channels: splitList(channels)}
+		if len(route.Channels) == 0 {
+			return nil, fmt.Errorf("route %q: no channels", item)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// Matches 判断告警标签是否满足路由条件
+func (r Route) Matches(labels map[string]string) bool {
+	return database.MatchLabels(labels, r.Matchers)
+}
+
+// sends 判断路由是否发送到该渠道
+func (r Route) sends(n Notifier) bool {
+	return selects(r.Channels, n)
+}
+
+// selects 判断渠道列表是否包含该渠道，渠道名称可以是完整名称或其第一个单词 (如 webhook)
+func selects(channels []string, n Notifier) bool {
+	name := n.Name()
+	kind, _, _ := strings.Cut(name, " ")
+	for _, channel := range channels {
+		if channel == name || channel == kind || "webhook "+channel == name {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList 拆分逗号分隔的列表，忽略空项
+func splitList(text string) []string {
+	var list []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
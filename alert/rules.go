@@ -0,0 +1,294 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// RulesFromConfig 根据配置生成内置规则，阈值为 0 的规则不启用
+// 错误频率异常规则以异常错误代码数为取值，出现任一异常即触发
+// 默认连接的规则附加默认连接的标签，PROFILES 中的服务器按 ProfileRules 生成规则
+func RulesFromConfig(cfg *config.AlertConfig, store database.Store) []Rule {
+	rules := defaultRules(cfg, store)
+	for i := range rules {
+		labels := database.GetProfileRegistry().Labels(database.DefaultProfile)
+		for k, v := range rules[i].Labels {
+			if labels == nil {
+				labels = make(map[string]string)
+			}
+			labels[k] = v
+		}
+		rules[i].Labels = labels
+	}
+	return append(rules, ProfileRules(cfg, database.GetProfileRegistry())...)
+}
+
+// ProfileRules 为 PROFILES 中的每个服务器生成无法连接和锁等待规则，取值来自服务器总览的最近一次检查
+// 复制延迟规则的取值来自复制延迟采样，最近一次采样超过两个采样间隔时按暂无数据处理
+// 告警附加服务器的标签，instance 为该服务器的地址
+func ProfileRules(cfg *config.AlertConfig, registry *database.ProfileRegistry) []Rule {
+	var rules []Rule
+	lagInterval := config.GetReplicaLagConfig().SampleInterval
+	lagTracker := database.GetReplicaLagTracker()
+	for _, status := range registry.Statuses() {
+		if status.Default {
+			continue
+		}
+		name := status.Name
+		labels := registry.Labels(name)
+		if status.Host != "" {
+			labels["instance"] = status.Host
+		}
+
+		if cfg.ProfileDown {
+			rules = append(rules, Rule{
+				Name:      "ProfileDown",
+				Severity:  "critical",
+				Summary:   "服务器无法连接",
+				Threshold: 0,
+				For:       cfg.ProfileDownFor,
+				Labels:    labels,
+				Value: func(ctx context.Context) (float64, bool, error) {
+					s, ok := registry.Status(name)
+					if !ok || s.Status == database.ProfileStatusUnknown {
+						return 0, false, nil
+					}
+					if s.Status == database.ProfileStatusDown {
+						return 1, true, nil
+					}
+					return 0, true, nil
+				},
+			})
+		}
+
+		if cfg.BlockedSessionsThreshold > 0 {
+			rules = append(rules, Rule{
+				Name:      "BlockedSessions",
+				Severity:  "warning",
+				Summary:   "锁等待会话过多",
+				Threshold: float64(cfg.BlockedSessionsThreshold),
+				For:       cfg.BlockedSessionsFor,
+				Labels:    labels,
+				Value: func(ctx context.Context) (float64, bool, error) {
+					s, ok := registry.Status(name)
+					if !ok || s.BlockedSessions == nil {
+						return 0, false, nil
+					}
+					return float64(*s.BlockedSessions), true, nil
+				},
+			})
+		}
+
+		if cfg.ReplicaLagThreshold > 0 && lagInterval > 0 {
+			rules = append(rules, Rule{
+				Name:      "ReplicaLag",
+				Severity:  "warning",
+				Summary:   "复制延迟过高",
+				Threshold: float64(cfg.ReplicaLagThreshold),
+				For:       cfg.ReplicaLagFor,
+				Labels:    labels,
+				Value: func(ctx context.Context) (float64, bool, error) {
+					s, ok := lagTracker.Latest(name)
+					if !ok || s.Seconds == nil || time.Since(s.Timestamp) > 2*lagInterval {
+						return 0, false, nil
+					}
+					return float64(*s.Seconds), true, nil
+				},
+			})
+		}
+
+		if cfg.TopologyChangeHold > 0 {
+			rules = append(rules, topologyRule(cfg, name, labels))
+		}
+	}
+	return rules
+}
+
+// topologyRule 服务器拓扑变化规则：最近一次变化在 TopologyChangeHold 内时触发，之后自动恢复
+func topologyRule(cfg *config.AlertConfig, name string, labels map[string]string) Rule {
+	watcher := database.GetTopologyWatcher()
+	return Rule{
+		Name:      "TopologyChanged",
+		Severity:  "critical",
+		Summary:   "服务器发生故障切换或读写角色变化",
+		Threshold: 0,
+		Labels:    labels,
+		Value: func(ctx context.Context) (float64, bool, error) {
+			change, ok := watcher.LastChange(name)
+			if !ok {
+				return 0, true, nil
+			}
+			if time.Since(change.Time) > cfg.TopologyChangeHold {
+				return 0, true, nil
+			}
+			return 1, true, nil
+		},
+	}
+}
+
+// diskFullRule 磁盘即将写满规则：按表增长预测的剩余天数低于 days 时触发
+// 未配置磁盘容量或数据量没有增长时没有预测，按暂无数据处理
+func diskFullRule(days float64, tracker *database.GrowthTracker) Rule {
+	remaining := func() (float64, bool) {
+		forecasts := tracker.Forecast()
+		if len(forecasts) == 0 || forecasts[0].DaysUntilFull == nil {
+			return 0, false
+		}
+		return *forecasts[0].DaysUntilFull, true
+	}
+	return Rule{
+		Name:      "DiskFullSoon",
+		Severity:  "warning",
+		Summary:   "按数据增长趋势磁盘即将写满",
+		Threshold: 0,
+		Description: func() string {
+			left, ok := remaining()
+			if !ok {
+				return ""
+			}
+			return fmt.Sprintf("预计 %.1f 天后写满，阈值 %g 天", left, days)
+		},
+		Value: func(ctx context.Context) (float64, bool, error) {
+			left, ok := remaining()
+			if !ok {
+				return 0, false, nil
+			}
+			if left < days {
+				return 1, true, nil
+			}
+			return 0, true, nil
+		},
+	}
+}
+
+// defaultRules 默认连接的内置规则
+func defaultRules(cfg *config.AlertConfig, store database.Store) []Rule {
+	var rules []Rule
+
+	if cfg.ReconnectThreshold > 0 {
+		rules = append(rules, Rule{
+			Name:      "DatabaseReconnectFailures",
+			Severity:  "critical",
+			Summary:   "数据库连续重连失败",
+			Threshold: float64(cfg.ReconnectThreshold),
+			For:       cfg.ReconnectFor,
+			Value: func(ctx context.Context) (float64, bool, error) {
+				return float64(database.GetReconnector().GetRetryCount()), true, nil
+			},
+		})
+	}
+
+	if cfg.BlockedSessionsThreshold > 0 {
+		rules = append(rules, Rule{
+			Name:      "BlockedSessions",
+			Severity:  "warning",
+			Summary:   "锁等待会话过多",
+			Threshold: float64(cfg.BlockedSessionsThreshold),
+			For:       cfg.BlockedSessionsFor,
+			Value: func(ctx context.Context) (float64, bool, error) {
+				n, err := store.CountBlockedSessions(ctx)
+				return float64(n), err == nil, err
+			},
+		})
+	}
+
+	if cfg.ReplicaLagThreshold > 0 {
+		rules = append(rules, Rule{
+			Name:      "ReplicaLag",
+			Severity:  "warning",
+			Summary:   "复制延迟过高",
+			Threshold: float64(cfg.ReplicaLagThreshold),
+			For:       cfg.ReplicaLagFor,
+			Value: func(ctx context.Context) (float64, bool, error) {
+				lag, err := store.GetReplicaLag(ctx)
+				if err != nil || lag == nil {
+					return 0, false, err
+				}
+				return float64(*lag), true, nil
+			},
+		})
+	}
+
+	if cfg.ErrorAnomaly {
+		rules = append(rules, Rule{
+			Name:      "ErrorRateAnomaly",
+			Severity:  "warning",
+			Summary:   "错误频率显著高于基线",
+			Threshold: 0,
+			For:       cfg.ErrorAnomalyFor,
+			Value: func(ctx context.Context) (float64, bool, error) {
+				return float64(len(database.GetErrorAnalyzer().DetectAnomalies())), true, nil
+			},
+		})
+	}
+
+	if cfg.ConnectionUsagePercent > 0 {
+		rules = append(rules, Rule{
+			Name:      "ConnectionUsageHigh",
+			Severity:  "warning",
+			Summary:   "连接数接近 max_connections",
+			Threshold: cfg.ConnectionUsagePercent,
+			For:       cfg.ConnectionUsageFor,
+			Value: func(ctx context.Context) (float64, bool, error) {
+				usage, err := store.GetThreadUsage(ctx)
+				if err != nil || usage.MaxConnections == 0 {
+					return 0, false, err
+				}
+				return usage.UsagePercent, true, nil
+			},
+		})
+	}
+
+	if cfg.ThreadsRunningThreshold > 0 {
+		rules = append(rules, Rule{
+			Name:      "ThreadsRunningHigh",
+			Severity:  "warning",
+			Summary:   "活跃线程数过多",
+			Threshold: float64(cfg.ThreadsRunningThreshold),
+			For:       cfg.ThreadsRunningFor,
+			Value: func(ctx context.Context) (float64, bool, error) {
+				usage, err := store.GetThreadUsage(ctx)
+				if err != nil {
+					return 0, false, err
+				}
+				return float64(usage.Running), true, nil
+			},
+		})
+	}
+
+	if cfg.ChecksumMismatch {
+		monitor := database.GetChecksumMonitor()
+		rules = append(rules, Rule{
+			Name:      "TableChecksumMismatch",
+			Severity:  "critical",
+			Summary:   "主从库表数据不一致",
+			Threshold: 0,
+			Labels:    map[string]string{"replica": config.GetChecksumConfig().Replica},
+			Value: func(ctx context.Context) (float64, bool, error) {
+				run, ok := monitor.Last()
+				if !ok {
+					return 0, false, nil
+				}
+				return float64(run.Mismatches()), true, nil
+			},
+		})
+	}
+
+	if cfg.TopologyChangeHold > 0 {
+		rules = append(rules, topologyRule(cfg, database.DefaultProfile, nil))
+	}
+
+	if cfg.DiskDaysUntilFull > 0 {
+		rules = append(rules, diskFullRule(cfg.DiskDaysUntilFull, database.GetGrowthTracker()))
+	}
+
+	// 配置有误时不生成表行数规则，错误由 -validate 报告
+	tables, _ := ParseTableRowRules(cfg.TableRows, cfg.RowDropPercent, cfg.RowStallFor)
+	rules = append(rules, TableRowRules(tables, cfg.RowDropWindow, int64(cfg.RowDropMinRows), database.GetGrowthTracker())...)
+
+	return rules
+}
@@ -0,0 +1,130 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/storage"
+)
+
+// alertStateBucket 告警状态在持久化存储中的分组
+const alertStateBucket = "alert_state"
+
+// persistedState 保存的规则状态，重启后触发中的告警不会重复通知，升级策略继续计时
+type persistedState struct {
+	Key          string    `json:"key"`
+	PendingSince time.Time `json:"pending_since"`
+	Alert        *Alert    `json:"alert,omitempty"`
+	Notified     bool      `json:"notified"`
+	NotifiedAt   time.Time `json:"notified_at"`
+	Escalated    int       `json:"escalated"`
+	AckedBy      string    `json:"acked_by,omitempty"`
+	AckedAt      time.Time `json:"acked_at"`
+	AckComment   string    `json:"ack_comment,omitempty"`
+}
+
+// persisted 规则状态的副本，用于保存
+func (s *ruleState) persisted(key string) persistedState {
+	p := persistedState{
+		Key:          key,
+		PendingSince: s.pendingSince,
+		Notified:     s.notified,
+		NotifiedAt:   s.notifiedAt,
+		Escalated:    s.escalated,
+		AckedBy:      s.ackedBy,
+		AckedAt:      s.ackedAt,
+		AckComment:   s.ackComment,
+	}
+	if s.alert != nil {
+		a := *s.alert
+		p.Alert = &a
+	}
+	return p
+}
+
+// idle 规则既未等待也未触发，无需保存
+func (p persistedState) idle() bool {
+	return p.Alert == nil && p.PendingSince.IsZero()
+}
+
+// stateKey 规则状态的存储键，规则键中可能含有存储键不允许的字符
+func stateKey(key string) string {
+	return fingerprint(map[string]string{"rule": key})
+}
+
+// SetStorage 设置保存告警状态的持久化存储并恢复已保存的状态，需在 Start 之前调用
+// 已不存在的规则的状态被丢弃
+func (e *Engine) SetStorage(st storage.Storage) {
+	if st == nil {
+		return
+	}
+	ctx := context.Background()
+	items, err := st.List(ctx, alertStateBucket)
+	if err != nil {
+		e.logger.Warn("读取告警状态失败", err.Error())
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.storage = st
+	rules := make(map[string]bool, len(e.rules))
+	for _, rule := range e.rules {
+		rules[ruleKey(rule)] = true
+	}
+	restored := 0
+	for _, item := range items {
+		data, err := st.Get(ctx, alertStateBucket, item.Key)
+		if err != nil {
+			e.logger.Warn("读取告警状态失败", err.Error())
+			continue
+		}
+		var p persistedState
+		if err := json.Unmarshal(data, &p); err != nil || !rules[p.Key] {
+			st.Delete(ctx, alertStateBucket, item.Key)
+			continue
+		}
+		e.states[p.Key] = &ruleState{
+			pendingSince: p.PendingSince,
+			alert:        p.Alert,
+			notified:     p.Notified,
+			notifiedAt:   p.NotifiedAt,
+			escalated:    p.Escalated,
+			ackedBy:      p.AckedBy,
+			ackedAt:      p.AckedAt,
+			ackComment:   p.AckComment,
+		}
+		restored++
+	}
+	if restored > 0 {
+		e.logger.Info("已恢复告警状态", strconv.Itoa(restored)+" 条规则")
+	}
+}
+
+// saveStates 保存发生变化的规则状态，恢复空闲的规则删除保存的状态
+func (e *Engine) saveStates(ctx context.Context, states []persistedState) {
+	e.mu.RLock()
+	st := e.storage
+	e.mu.RUnlock()
+	if st == nil {
+		return
+	}
+	for _, p := range states {
+		if p.idle() {
+			if err := st.Delete(ctx, alertStateBucket, stateKey(p.Key)); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				e.logger.Warn("删除告警状态失败", err.Error())
+			}
+			continue
+		}
+		data, err := json.Marshal(p)
+		if err == nil {
+			err = st.Put(ctx, alertStateBucket, stateKey(p.Key), data)
+		}
+		if err != nil {
+			e.logger.Warn("保存告警状态失败", err.Error())
+		}
+	}
+}
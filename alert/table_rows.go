@@ -0,0 +1,99 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// TableRowRule 单个表的行数变化告警阈值，为 0 的项不启用
+type TableRowRule struct {
+	Table       string        // 库名.表名
+	DropPercent float64       // 行数相对窗口内最大值下降超过该百分比时告警 (可能是误删)
+	StallFor    time.Duration // 通常持续增长的表超过该时长没有增长时告警 (可能是写入管道卡住)
+}
+
+// ParseTableRowRules 解析表行数变化告警配置，表之间以分号分隔，每项为 "库名.表名[:drop=百分比,stall=时长]"
+// 例如 "shop.orders:drop=20,stall=2h;shop.events:stall=30m"，未指定的阈值使用 dropPercent 和 stallFor
+func ParseTableRowRules(text string, dropPercent float64, stallFor time.Duration) ([]TableRowRule, error) {
+	var rules []TableRowRule
+	for _, item := range strings.Split(text, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		table, options, _ := strings.Cut(item, ":")
+		table = strings.TrimSpace(table)
+		if db, name, ok := strings.Cut(table, "."); !ok || db == "" || name == "" {
+			return nil, fmt.Errorf("table %q: expected database.table", table)
+		}
+		rule := TableRowRule{Table: table, DropPercent: dropPercent, StallFor: stallFor}
+		for _, option := range splitList(options) {
+			key, value, ok := strings.Cut(option, "=")
+			if !ok {
+				return nil, fmt.Errorf("table %s: expected key=value, got %q", table, option)
+			}
+			switch strings.TrimSpace(key) {
+			case "drop":
+				percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+				if err != nil || percent < 0 || percent > 100 {
+					return nil, fmt.Errorf("table %s: invalid drop %q", table, value)
+				}
+				rule.DropPercent = percent
+			case "stall":
+				d, err := time.ParseDuration(strings.TrimSpace(value))
+				if err != nil || d < 0 {
+					return nil, fmt.Errorf("table %s: invalid stall %q", table, value)
+				}
+				rule.StallFor = d
+			default:
+				return nil, fmt.Errorf("table %s: unknown option %q", table, key)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// TableRowRules 根据表增长采样为每个表生成行数下降和停止增长规则，告警附加 table 标签
+// 行数下降以下降百分比为取值，停止增长以距上次增长的秒数为取值
+func TableRowRules(tables []TableRowRule, window time.Duration, minRows int64, tracker *database.GrowthTracker) []Rule {
+	var rules []Rule
+	for _, t := range tables {
+		table := t.Table
+		labels := map[string]string{"table": table}
+
+		if t.DropPercent > 0 {
+			rules = append(rules, Rule{
+				Name:      "TableRowCountDrop",
+				Severity:  "critical",
+				Summary:   fmt.Sprintf("表 %s 行数骤降，可能被误删", table),
+				Threshold: t.DropPercent,
+				Labels:    labels,
+				Value: func(ctx context.Context) (float64, bool, error) {
+					percent, ok := tracker.RowDropPercent(table, window, minRows)
+					return percent, ok, nil
+				},
+			})
+		}
+
+		if t.StallFor > 0 {
+			rules = append(rules, Rule{
+				Name:      "TableRowCountStalled",
+				Severity:  "warning",
+				Summary:   fmt.Sprintf("表 %s 行数停止增长，写入可能中断", table),
+				Threshold: t.StallFor.Seconds(),
+				Labels:    labels,
+				Value: func(ctx context.Context) (float64, bool, error) {
+					stalled, ok := tracker.RowStalledFor(table)
+					return stalled.Seconds(), ok, nil
+				},
+			})
+		}
+	}
+	return rules
+}
@@ -0,0 +1,270 @@
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// QuietHours 每天的静默时段，分钟数从 0 点起算，Start 大于 End 时跨越午夜
+type QuietHours struct {
+	Start int
+	End   int
+}
+
+// Contains 判断时间是否处于静默时段内
+func (q QuietHours) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if q.Start <= q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// String 以 HH:MM-HH:MM 表示静默时段
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.Start/60, q.Start%60, q.End/60, q.End%60)
+}
+
+// Throttle 通知限流：静默时段内暂缓非 critical 通知，同一告警在最小间隔内不重复通知，每小时通知总数超过上限的不再发送
+// 暂缓和超出上限的通知在静默时段结束或下一个小时开始时以一条汇总消息告知
+type Throttle struct {
+	mu         sync.Mutex
+	quiet      map[string]QuietHours    // 渠道 -> 静默时段，键为空表示所有渠道
+	renotify   map[string]time.Duration // 渠道 -> 重复通知的最小间隔，键为空表示所有渠道
+	maxPerHour int
+
+	lastSent map[string]time.Time // 渠道、告警指纹和状态 -> 最近一次发送时间
+	held     map[string][]string  // 渠道 -> 静默时段内暂缓的告警名称
+	hour     time.Time            // 当前计数的小时
+	sent     int                  // 当前小时已发送的通知数
+	overflow []string             // 当前小时超出上限未发送的告警名称
+}
+
+// ParseThrottle 根据配置创建通知限流，未配置任何限流时返回 nil
+// ALERT_QUIET_HOURS 为逗号分隔的 "渠道=HH:MM-HH:MM"，省略渠道时作用于所有渠道，如 "22:00-08:00,telegram=23:00-07:00"
+// ALERT_RENOTIFY_INTERVAL 为逗号分隔的 "渠道=时长"，同样可以省略渠道，如 "10m,dingtalk=30m"
+// 渠道名称与路由规则相同，时间按 TIME_ZONE 计算
+func ParseThrottle(cfg *config.AlertConfig) (*Throttle, error) {
+	t := &Throttle{
+		quiet:      make(map[string]QuietHours),
+		renotify:   make(map[string]time.Duration),
+		maxPerHour: cfg.MaxPerHour,
+		lastSent:   make(map[string]time.Time),
+		held:       make(map[string][]string),
+	}
+	for _, item := range splitList(cfg.QuietHours) {
+		channel, value := splitChannelSetting(item)
+		q, err := parseQuietHours(value)
+		if err != nil {
+			return nil, fmt.Errorf("ALERT_QUIET_HOURS %q: %w", item, err)
+		}
+		t.quiet[channel] = q
+	}
+	for _, item := range splitList(cfg.RenotifyInterval) {
+		channel, value := splitChannelSetting(item)
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("ALERT_RENOTIFY_INTERVAL %q: expected a positive duration", item)
+		}
+		t.renotify[channel] = d
+	}
+	if cfg.MaxPerHour < 0 {
+		return nil, fmt.Errorf("ALERT_MAX_PER_HOUR must not be negative")
+	}
+	if len(t.quiet) == 0 && len(t.renotify) == 0 && t.maxPerHour == 0 {
+		return nil, nil
+	}
+	return t, nil
+}
+
+// splitChannelSetting 拆分 "渠道=值"，没有渠道时渠道为空
+func splitChannelSetting(item string) (string, string) {
+	channel, value, ok := strings.Cut(item, "=")
+	if !ok {
+		return "", strings.TrimSpace(item)
+	}
+	return strings.TrimSpace(channel), strings.TrimSpace(value)
+}
+
+// parseQuietHours 解析 HH:MM-HH:MM
+func parseQuietHours(value string) (QuietHours, error) {
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("expected HH:MM-HH:MM")
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(from))
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(to))
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid end: %w", err)
+	}
+	q := QuietHours{Start: start.Hour()*60 + start.Minute(), End: end.Hour()*60 + end.Minute()}
+	if q.Start == q.End {
+		return QuietHours{}, fmt.Errorf("start and end must differ")
+	}
+	return q, nil
+}
+
+// settingKeys 查找渠道设置时依次使用的键：完整名称、名称的第一个单词 (如 webhook)、所有渠道
+func settingKeys(n Notifier) []string {
+	kind, _, _ := strings.Cut(n.Name(), " ")
+	return []string{n.Name(), kind, ""}
+}
+
+// quietHoursFor 渠道的静默时段，调用方需持有 t.mu
+func (t *Throttle) quietHoursFor(n Notifier) (QuietHours, bool) {
+	for _, key := range settingKeys(n) {
+		if q, ok := t.quiet[key]; ok {
+			return q, true
+		}
+	}
+	return QuietHours{}, false
+}
+
+// renotifyFor 渠道重复通知的最小间隔，调用方需持有 t.mu
+func (t *Throttle) renotifyFor(n Notifier) (time.Duration, bool) {
+	for _, key := range settingKeys(n) {
+		if d, ok := t.renotify[key]; ok {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// filter 选出现在可以发送到该渠道的告警，其余记录为暂缓或超出上限
+func (t *Throttle) filter(n Notifier, alerts []Alert, now time.Time) []Alert {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollHourLocked(now)
+
+	quiet, hasQuiet := t.quietHoursFor(n)
+	interval, hasInterval := t.renotifyFor(n)
+	var allowed []Alert
+	for _, a := range alerts {
+		name := a.Labels["alertname"]
+		if hasQuiet && quiet.Contains(now) && a.Labels["severity"] != "critical" {
+			t.held[n.Name()] = append(t.held[n.Name()], name)
+			continue
+		}
+		key := n.Name() + "\xff" + a.Fingerprint + "\xff" + a.Status
+		if hasInterval && now.Sub(t.lastSent[key]) < interval {
+			continue
+		}
+		if t.maxPerHour > 0 && t.sent >= t.maxPerHour {
+			t.overflow = append(t.overflow, name)
+			continue
+		}
+		t.sent++
+		t.lastSent[key] = now
+		allowed = append(allowed, a)
+	}
+	return allowed
+}
+
+// rollHourLocked 进入新的小时时重置计数，超出上限的告警名称保留到发送汇总
+func (t *Throttle) rollHourLocked(now time.Time) {
+	if hour := now.Truncate(time.Hour); !hour.Equal(t.hour) {
+		t.hour = hour
+		t.sent = 0
+	}
+}
+
+// throttleSummary 需要发送的汇总消息
+type throttleSummary struct {
+	channel string // 为空时发送到所有渠道
+	alert   Alert
+}
+
+// summaries 取出到期的汇总消息：静默时段已结束的渠道汇总暂缓的通知，新的小时开始时汇总上一小时超出上限的通知
+// notifiers 用于判断各渠道的静默时段是否结束
+func (t *Throttle) summaries(notifiers []Notifier, now time.Time) []throttleSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var result []throttleSummary
+	for _, n := range notifiers {
+		names := t.held[n.Name()]
+		if len(names) == 0 {
+			continue
+		}
+		quiet, ok := t.quietHoursFor(n)
+		if ok && quiet.Contains(now) {
+			continue
+		}
+		delete(t.held, n.Name())
+		result = append(result, throttleSummary{
+			channel: n.Name(),
+			alert: summaryAlert("QuietHoursSummary", now,
+				fmt.Sprintf("静默时段 (%s) 内暂缓了 %d 条告警通知", quiet, len(names)), describeNames(names)),
+		})
+	}
+
+	if len(t.overflow) > 0 && !now.Truncate(time.Hour).Equal(t.hour) {
+		result = append(result, throttleSummary{
+			alert: summaryAlert("NotificationsThrottled", now,
+				fmt.Sprintf("上一小时有 %d 条告警通知超过每小时 %d 条的上限未发送", len(t.overflow), t.maxPerHour), describeNames(t.overflow)),
+		})
+		t.overflow = nil
+	}
+	t.rollHourLocked(now)
+
+	// 清理已超过最小间隔的发送记录
+	var longest time.Duration
+	for _, d := range t.renotify {
+		if d > longest {
+			longest = d
+		}
+	}
+	for key, sent := range t.lastSent {
+		if now.Sub(sent) >= longest {
+			delete(t.lastSent, key)
+		}
+	}
+	return result
+}
+
+// describeNames 按出现次数列出告警名称，如 "ReplicaLag ×3, BlockedSessions ×1"
+func describeNames(names []string) string {
+	counts := make(map[string]int)
+	for _, name := range names {
+		counts[name]++
+	}
+	unique := make([]string, 0, len(counts))
+	for name := range counts {
+		unique = append(unique, name)
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		if counts[unique[i]] != counts[unique[j]] {
+			return counts[unique[i]] > counts[unique[j]]
+		}
+		return unique[i] < unique[j]
+	})
+	parts := make([]string, len(unique))
+	for i, name := range unique {
+		parts[i] = fmt.Sprintf("%s ×%d", name, counts[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// summaryAlert 汇总消息，以一次性告警的形式发送以便沿用各渠道的消息模板
+func summaryAlert(name string, now time.Time, summary, description string) Alert {
+	labels := map[string]string{
+		"alertname": name,
+		"severity":  "warning",
+		"service":   "block-checker",
+	}
+	return Alert{
+		Status:      "firing",
+		Labels:      labels,
+		Annotations: map[string]string{"summary": summary, "description": description},
+		StartsAt:    now,
+		Fingerprint: fingerprint(labels),
+	}
+}
@@ -0,0 +1,151 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// webhookMessage Alertmanager webhook 负载 (version 4)
+type webhookMessage struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	TruncatedAlerts   int               `json:"truncatedAlerts"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []Alert           `json:"alerts"`
+}
+
+// WebhookNotifier 以 Alertmanager webhook 格式发送告警
+type WebhookNotifier struct {
+	URL         string
+	ExternalURL string
+	client      *http.Client
+}
+
+// NewWebhookNotifier 创建 webhook 通知渠道
+func NewWebhookNotifier(url, externalURL string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:         url,
+		ExternalURL: externalURL,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name 渠道名称
+func (w *WebhookNotifier) Name() string {
+	return "webhook " + w.URL
+}
+
+// Notify 按告警名分组，每组发送一条消息
+func (w *WebhookNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	groups := make(map[string][]Alert)
+	var names []string
+	for _, a := range alerts {
+		name := a.Labels["alertname"]
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], a)
+	}
+
+	for _, name := range names {
+		group := groups[name]
+		msg := webhookMessage{
+			Version:           "4",
+			GroupKey:          fmt.Sprintf("{}:{alertname=%q}", name),
+			Status:            groupStatus(group),
+			Receiver:          "block-checker",
+			GroupLabels:       map[string]string{"alertname": name},
+			CommonLabels:      commonPairs(group, func(a Alert) map[string]string { return a.Labels }),
+			CommonAnnotations: commonPairs(group, func(a Alert) map[string]string { return a.Annotations }),
+			ExternalURL:       w.ExternalURL,
+			Alerts:            group,
+		}
+		if err := w.post(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post 发送一条 webhook 消息
+func (w *WebhookNotifier) post(ctx context.Context, msg webhookMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode webhook message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook %s: %s: %s", w.URL, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// groupStatus 组内任一告警触发中则为 firing
+func groupStatus(alerts []Alert) string {
+	for _, a := range alerts {
+		if a.Status == "firing" {
+			return "firing"
+		}
+	}
+	return "resolved"
+}
+
+// commonPairs 计算组内所有告警共有的键值对
+func commonPairs(alerts []Alert, get func(Alert) map[string]string) map[string]string {
+	common := make(map[string]string)
+	if len(alerts) == 0 {
+		return common
+	}
+	for k, v := range get(alerts[0]) {
+		common[k] = v
+	}
+	for _, a := range alerts[1:] {
+		pairs := get(a)
+		for k, v := range common {
+			if pairs[k] != v {
+				delete(common, k)
+			}
+		}
+	}
+	return common
+}
+
+// fingerprint 根据标签计算稳定的告警指纹
+func fingerprint(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k + "\xff" + labels[k] + "\xff"))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,226 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 检查结果状态
+const (
+	StatusOK       = "ok"
+	StatusWarning  = "warning"
+	StatusCritical = "critical"
+	StatusUnknown  = "unknown"
+)
+
+// Check 可扩展的检查项
+// 编译进二进制的检查在 init 中调用 Register 注册，外部检查以子进程方式运行 (见 ExecCheck)
+type Check interface {
+	Name() string
+	Interval() time.Duration // 运行间隔，为 0 时使用 Runner 的默认间隔
+	Run(ctx context.Context) Result
+}
+
+// Result 一次检查的结果
+type Result struct {
+	Check      string                 `json:"check"`
+	Status     string                 `json:"status"`
+	Message    string                 `json:"message,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	Time       time.Time              `json:"time"`
+	DurationMs float64                `json:"duration_ms"`
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]Check)
+)
+
+// Register 注册编译进二进制的检查，名称重复时 panic
+func Register(c Check) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[c.Name()]; ok {
+		panic("checks: duplicate check " + c.Name())
+	}
+	registry[c.Name()] = c
+}
+
+// Registered 返回已注册的检查，按名称排序
+func Registered() []Check {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	list := make([]Check, 0, len(registry))
+	for _, c := range registry {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
+
+// defaultHistoryLimit 每个检查默认保留的历史结果数
+const defaultHistoryLimit = 100
+
+// Runner 按各检查的间隔定期运行检查，保留最近一次结果和最近的历史结果
+type Runner struct {
+	mu           sync.Mutex
+	checks       []Check
+	results      map[string]Result
+	history      map[string][]Result // 按时间升序
+	historyLimit int
+	interval     time.Duration
+	timeout      time.Duration
+	stop         chan struct{}
+}
+
+// NewRunner 创建运行器，interval 为检查未指定间隔时的默认值，timeout 为单次运行的超时
+func NewRunner(checks []Check, interval, timeout time.Duration) *Runner {
+	return &Runner{
+		checks:       checks,
+		results:      make(map[string]Result),
+		history:      make(map[string][]Result),
+		historyLimit: defaultHistoryLimit,
+		interval:     interval,
+		timeout:      timeout,
+	}
+}
+
+// SetHistoryLimit 设置每个检查保留的历史结果数，需在 Start 之前调用
+func (r *Runner) SetHistoryLimit(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n > 0 {
+		r.historyLimit = n
+	}
+}
+
+// Start 为每个检查启动独立的定时运行
+func (r *Runner) Start() {
+	r.mu.Lock()
+	if r.stop != nil {
+		r.mu.Unlock()
+		return
+	}
+	r.stop = make(chan struct{})
+	stop := r.stop
+	r.mu.Unlock()
+
+	for _, c := range r.checks {
+		interval := c.Interval()
+		if interval <= 0 {
+			interval = r.interval
+		}
+		if interval <= 0 {
+			continue
+		}
+		go func(c Check, interval time.Duration) {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			r.run(c)
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+					r.run(c)
+				}
+			}
+		}(c, interval)
+	}
+}
+
+// Stop 停止所有检查
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+}
+
+// RunNow 立即运行指定检查并返回结果
+func (r *Runner) RunNow(name string) (Result, error) {
+	for _, c := range r.checks {
+		if c.Name() == name {
+			return r.run(c), nil
+		}
+	}
+	return Result{}, fmt.Errorf("check %q not found", name)
+}
+
+// History 返回指定检查最近的结果，按时间升序
+func (r *Runner) History(name string) ([]Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.checks {
+		if c.Name() == name {
+			return append([]Result{}, r.history[name]...), nil
+		}
+	}
+	return nil, fmt.Errorf("check %q not found", name)
+}
+
+// Result 返回指定检查最近一次的结果，尚未运行时 ok 为 false
+func (r *Runner) Result(name string) (Result, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result, ok := r.results[name]
+	return result, ok
+}
+
+// Results 返回各检查最近一次的结果，按名称排序，尚未运行的检查状态为 unknown
+func (r *Runner) Results() []Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]Result, 0, len(r.checks))
+	for _, c := range r.checks {
+		result, ok := r.results[c.Name()]
+		if !ok {
+			result = Result{Check: c.Name(), Status: StatusUnknown, Message: "尚未运行"}
+		}
+		list = append(list, result)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Check < list[j].Check })
+	return list
+}
+
+// run 运行一次检查，补全结果的名称、时间和耗时，panic 视为 critical
+func (r *Runner) run(c Check) (result Result) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	start := time.Now()
+
+	defer func() {
+		if p := recover(); p != nil {
+			result = Result{Status: StatusCritical, Message: fmt.Sprintf("check panicked: %v", p)}
+		}
+		result.Check = c.Name()
+		result.Time = start
+		result.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+		if !validStatus(result.Status) {
+			result.Status = StatusUnknown
+		}
+		r.mu.Lock()
+		r.results[c.Name()] = result
+		history := append(r.history[c.Name()], result)
+		if len(history) > r.historyLimit {
+			history = history[len(history)-r.historyLimit:]
+		}
+		r.history[c.Name()] = history
+		r.mu.Unlock()
+	}()
+	return c.Run(ctx)
+}
+
+// validStatus 判断状态是否为已知取值
+func validStatus(status string) bool {
+	switch status {
+	case StatusOK, StatusWarning, StatusCritical, StatusUnknown:
+		return true
+	}
+	return false
+}
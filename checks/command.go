@@ -0,0 +1,141 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseCommandChecks 解析配置的外部命令检查，检查之间以分号分隔，每项为 "名称[:间隔[:超时]]=命令 参数..."
+// 例如 "proxy:30s:5s=/usr/lib/nagios/plugins/check_tcp -H proxy -p 6033;backup=/opt/check_backup.sh"
+// 命令不经过 shell 执行，参数按空白拆分，可用单引号或双引号包含空白和分号；需要管道等 shell 功能时使用 sh -c '...'
+// 输出和退出码的约定与 ExecCheck 相同
+func ParseCommandChecks(text string) ([]*ExecCheck, error) {
+	var list []*ExecCheck
+	names := make(map[string]bool)
+	for _, item := range splitOutsideQuotes(text, ';') {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		head, command, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("check %q: expected name=command", item)
+		}
+		parts := strings.Split(strings.TrimSpace(head), ":")
+		name := parts[0]
+		if name == "" || strings.ContainsAny(name, " /\t") {
+			return nil, fmt.Errorf("check %q: invalid name %q", item, name)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("check %q: duplicate name %q", item, name)
+		}
+		if len(parts) > 3 {
+			return nil, fmt.Errorf("check %q: expected name[:interval[:timeout]]", item)
+		}
+		var durations [2]time.Duration
+		for i, value := range parts[1:] {
+			d, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("check %q: %q is not a positive duration", item, value)
+			}
+			durations[i] = d
+		}
+		argv, err := splitCommand(command)
+		if err != nil {
+			return nil, fmt.Errorf("check %q: %w", item, err)
+		}
+		if len(argv) == 0 {
+			return nil, fmt.Errorf("check %q: empty command", item)
+		}
+		names[name] = true
+		list = append(list, NewCommandCheck(name, argv, durations[0], durations[1]))
+	}
+	return list, nil
+}
+
+// splitOutsideQuotes 按分隔符拆分，忽略单引号和双引号内的分隔符
+func splitOutsideQuotes(text string, sep rune) []string {
+	var (
+		items   []string
+		start   int
+		quote   rune
+		escaped bool
+	)
+	for i, r := range text {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' {
+				escaped = true
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '\\':
+			escaped = true
+		case r == sep:
+			items = append(items, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, text[start:])
+}
+
+// splitCommand 按空白拆分命令行，单引号内原样保留，双引号内支持 \" 和 \\ 转义
+func splitCommand(command string) ([]string, error) {
+	var (
+		argv    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == '\\':
+			escaped, inArg = true, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				argv = append(argv, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inArg {
+		argv = append(argv, current.String())
+	}
+	return argv, nil
+}
@@ -0,0 +1,132 @@
+package checks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxExecOutput 子进程输出的读取上限
+const maxExecOutput = 1 << 20
+
+// ExecCheck 以子进程方式运行的外部检查
+// 子进程在标准输出打印一个 JSON 对象: {"status": "ok|warning|critical", "message": "...", "details": {...}}
+// 输出不是合法 JSON 时按退出码判定: 0 为 ok，1 为 warning，其他为 critical (与 Nagios 插件约定一致)
+type ExecCheck struct {
+	name     string
+	path     string
+	args     []string
+	interval time.Duration
+	timeout  time.Duration // 为 0 时只受 Runner 的超时限制
+}
+
+// NewExecCheck 创建外部检查，interval 为 0 时使用 Runner 的默认间隔
+func NewExecCheck(name, path string, interval time.Duration) *ExecCheck {
+	return &ExecCheck{name: name, path: path, interval: interval}
+}
+
+// NewCommandCheck 创建运行指定命令的外部检查，timeout 为 0 时只受 Runner 的超时限制
+func NewCommandCheck(name string, argv []string, interval, timeout time.Duration) *ExecCheck {
+	return &ExecCheck{name: name, path: argv[0], args: argv[1:], interval: interval, timeout: timeout}
+}
+
+// Name 检查名称
+func (e *ExecCheck) Name() string { return e.name }
+
+// Interval 运行间隔
+func (e *ExecCheck) Interval() time.Duration { return e.interval }
+
+// Path 可执行文件的路径或命令名
+func (e *ExecCheck) Path() string { return e.path }
+
+// Run 运行子进程并解析输出
+func (e *ExecCheck) Run(ctx context.Context) Result {
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+	var stdout, stderr limitedBuffer
+	cmd := exec.CommandContext(ctx, e.path, e.args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// 子进程派生的进程可能继续占用输出管道，超时后不再等待其关闭
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+
+	if ctx.Err() != nil {
+		return Result{Status: StatusCritical, Message: fmt.Sprintf("check timed out: %v", ctx.Err())}
+	}
+
+	var result Result
+	if jsonErr := json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &result); jsonErr == nil && result.Status != "" {
+		result.Status = strings.ToLower(result.Status)
+		return result
+	}
+
+	message := strings.TrimSpace(stdout.String())
+	if message == "" {
+		message = strings.TrimSpace(stderr.String())
+	}
+	if err == nil {
+		return Result{Status: StatusOK, Message: message}
+	}
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return Result{Status: StatusCritical, Message: fmt.Sprintf("run check: %v", err)}
+	}
+	if message == "" {
+		message = err.Error()
+	}
+	if exitErr.ExitCode() == 1 {
+		return Result{Status: StatusWarning, Message: message}
+	}
+	return Result{Status: StatusCritical, Message: message}
+}
+
+// ExecChecksFromDir 将目录中的每个可执行文件注册为外部检查，检查名称为去掉扩展名的文件名
+func ExecChecksFromDir(dir string, interval time.Duration) ([]Check, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read check directory: %w", err)
+	}
+
+	var list []Check
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode()&0o111 == 0 {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		list = append(list, NewExecCheck(name, filepath.Join(dir, entry.Name()), interval))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list, nil
+}
+
+// limitedBuffer 超过 maxExecOutput 后丢弃多余输出的缓冲区
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+// Write 写入输出，超出上限的部分被丢弃但不报错，避免子进程因管道关闭而失败
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := maxExecOutput - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
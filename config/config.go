@@ -1,6 +1,18 @@
 package config
 
-import "os"
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	invalidMu  sync.Mutex
+	invalidEnv = make(map[string]string)
+)
 
 // DBConfig 数据库连接配置
 type DBConfig struct {
@@ -9,29 +21,223 @@ type DBConfig struct {
 	User string
 	Pass string
 	Name string
+
+	// PassSecondary 轮换密码期间的备用密码 (DB_PASS_SECONDARY)，主密码认证失败时自动改用，仅适用于静态密码
+	PassSecondary string
+
+	// Hosts 按优先级排列的候选主机 (DB_HOSTS)，未配置时只包含 Host:Port
+	Hosts []DBHost
+
+	// Driver 数据库后端 (DB_DRIVER)：mysql 连接真实的 MySQL，demo 使用内存中的演示数据，用于演示界面和在 CI 中运行端到端测试
+	Driver string
+
+	// AuthMode 密码来源：空为静态 DB_PASS，command 为执行 PassCommand，rds-iam 为生成 RDS IAM 令牌
+	AuthMode        string
+	PassCommand     string
+	PassTTL         time.Duration // 动态密码的缓存时间
+	TLS             string        // 驱动的 tls 参数 (true/skip-verify/preferred)
+	AWSRegion       string
+	AWSAccessKey    string
+	AWSSecretKey    string
+	AWSSessionToken string
+
+	ReadOnly            bool          // 只读模式，拒绝 SELECT/SHOW/EXPLAIN 以外的语句
+	QueryTimeout        time.Duration // 单条查询的超时时间
+	MetadataConcurrency int           // 同时执行的 information_schema 查询上限
+	ReconnectMaxRetries int           // 连续重连失败达到该次数后放弃，为 0 时无限重试
+	PoolWarmup          int           // 连接成功后预先建立的连接数，为 0 时不预热
+	StmtCache           bool          // 是否缓存热点元数据查询的预处理语句
+
+	ReconnectInitialDelay  time.Duration // 首次重试间隔，之后按指数增长
+	ReconnectMaxDelay      time.Duration // 重试间隔上限
+	ReconnectJitter        float64       // 重试间隔的随机浮动比例 (0-1)
+	ReconnectBudget        int           // 每个窗口内允许的重连尝试次数，为 0 时不限制
+	ReconnectBudgetWindow  time.Duration // 重连预算的时间窗口
+	ReconnectGiveUpOnFatal bool          // 遇到认证或配置错误时立即放弃重连
+	ReconnectHistorySize   int           // 每个服务器保留的重连尝试记录数，为 0 时不限制
+	ReconnectHistoryMaxAge time.Duration // 重连尝试记录的保留时长，为 0 时不限制
+}
+
+// 数据库后端
+const (
+	DriverMySQL = "mysql"
+	DriverDemo  = "demo"
+)
+
+// DBHost 数据库主机地址
+type DBHost struct {
+	Host string
+	Port string
+}
+
+// Address 返回 host:port 形式的地址
+func (h DBHost) Address() string {
+	return net.JoinHostPort(h.Host, h.Port)
 }
 
 // ServerConfig 应用配置
 type ServerConfig struct {
-	Port string
+	Port       string
+	SocketPath string // 非空时监听 unix domain socket 而非 TCP 端口
+	EnableH2C  bool   // 是否启用 h2c (明文 HTTP/2)
+
+	WaitForDB        bool          // 启动时是否阻塞等待数据库可达
+	WaitForDBTimeout time.Duration // 等待数据库的最长时间
+
+	ShutdownTimeout time.Duration // 收到退出信号或致命错误后等待进行中的请求完成的最长时间
+
+	OperatorToken string // 操作员令牌，为空时禁用所有操作员功能
 }
 
 // GetDBConfig 从环境变量读取数据库配置
 func GetDBConfig() *DBConfig {
-	return &DBConfig{
+	cfg := &DBConfig{
 		Host: getEnv("DB_HOST", "localhost"),
 		Port: getEnv("DB_PORT", "3306"),
 		User: getEnv("DB_USER", "root"),
 		Pass: getEnv("DB_PASS", ""),
 		Name: getEnv("DB_NAME", "mysql"),
+
+		PassSecondary: getEnv("DB_PASS_SECONDARY", ""),
+		Driver:        strings.ToLower(getEnv("DB_DRIVER", DriverMySQL)),
+
+		ReadOnly:            getEnvBool("READ_ONLY", false),
+		QueryTimeout:        getEnvDuration("DB_QUERY_TIMEOUT", 10*time.Second),
+		MetadataConcurrency: getEnvInt("DB_METADATA_CONCURRENCY", 4),
+		ReconnectMaxRetries: getEnvInt("DB_RECONNECT_MAX_RETRIES", 0),
+		PoolWarmup:          getEnvInt("DB_POOL_WARMUP", 0),
+		StmtCache:           getEnvBool("DB_STMT_CACHE", true),
+
+		ReconnectInitialDelay:  getEnvDuration("DB_RECONNECT_INITIAL_DELAY", time.Second),
+		ReconnectMaxDelay:      getEnvDuration("DB_RECONNECT_MAX_DELAY", 30*time.Second),
+		ReconnectJitter:        getEnvFloat("DB_RECONNECT_JITTER", 0.2),
+		ReconnectBudget:        getEnvInt("DB_RECONNECT_BUDGET", 0),
+		ReconnectBudgetWindow:  getEnvDuration("DB_RECONNECT_BUDGET_WINDOW", 10*time.Minute),
+		ReconnectGiveUpOnFatal: getEnvBool("DB_RECONNECT_GIVE_UP_ON_FATAL", true),
+		ReconnectHistorySize:   getEnvInt("DB_RECONNECT_HISTORY_SIZE", 500),
+		ReconnectHistoryMaxAge: getEnvDuration("DB_RECONNECT_HISTORY_MAX_AGE", 7*24*time.Hour),
+
+		AuthMode:        strings.ToLower(getEnv("DB_AUTH", "")),
+		PassCommand:     getEnv("DB_PASS_COMMAND", ""),
+		PassTTL:         getEnvDuration("DB_PASS_TTL", 5*time.Minute),
+		TLS:             getEnv("DB_TLS", ""),
+		AWSRegion:       getEnv("AWS_REGION", getEnv("AWS_DEFAULT_REGION", "")),
+		AWSAccessKey:    getEnv("AWS_ACCESS_KEY_ID", ""),
+		AWSSecretKey:    getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		AWSSessionToken: getEnv("AWS_SESSION_TOKEN", ""),
+	}
+	cfg.Hosts = parseHosts(getEnvList("DB_HOSTS"), cfg.Port)
+	if len(cfg.Hosts) == 0 {
+		cfg.Hosts = []DBHost{{Host: cfg.Host, Port: cfg.Port}}
+	} else {
+		// 主机列表优先，Host/Port 保持为首选主机
+		cfg.Host, cfg.Port = cfg.Hosts[0].Host, cfg.Hosts[0].Port
+	}
+	return cfg
+}
+
+// parseHosts 解析 host[:port] 列表，未指定端口时使用默认端口
+func parseHosts(items []string, defaultPort string) []DBHost {
+	var hosts []DBHost
+	for _, item := range items {
+		host, port, err := net.SplitHostPort(item)
+		if err != nil {
+			host, port = strings.Trim(item, "[]"), defaultPort
+		}
+		hosts = append(hosts, DBHost{Host: host, Port: port})
 	}
+	return hosts
 }
 
 // GetServerConfig 从环境变量读取应用配置
 func GetServerConfig() *ServerConfig {
 	return &ServerConfig{
-		Port: getEnv("PORT", "5000"),
+		Port:       getEnv("PORT", "5000"),
+		SocketPath: getEnv("LISTEN_SOCKET", ""),
+		EnableH2C:  getEnvBool("ENABLE_H2C", false),
+
+		WaitForDB:        getEnvBool("WAIT_FOR_DB", false),
+		WaitForDBTimeout: getEnvDuration("WAIT_FOR_DB_TIMEOUT", 60*time.Second),
+
+		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 10*time.Second),
+
+		OperatorToken: getEnv("OPERATOR_TOKEN", ""),
+	}
+}
+
+// TimeConfig 时间输出配置，对日志、错误分析、页面和 API 统一生效
+type TimeConfig struct {
+	Location      *time.Location // 时区 (TIME_ZONE，如 UTC、Asia/Shanghai)，默认使用本地时区
+	APIFormat     string         // API 响应中字符串时间的格式，默认 RFC3339
+	DisplayFormat string         // 页面、报告和日志消息中的时间格式
+	LogFormat     string         // 标准输出日志行首的时间格式，为空时使用 log 包的默认格式
+}
+
+// timeLayouts 时间格式配置可以使用的预定义格式名称，其他值按 Go 时间格式解析
+var timeLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123Z":    time.RFC1123Z,
+	"DateTime":    "2006-01-02 15:04:05",
+}
+
+// GetTimeConfig 从环境变量读取时间输出配置
+func GetTimeConfig() *TimeConfig {
+	cfg := &TimeConfig{
+		Location:      time.Local,
+		APIFormat:     getEnvTimeLayout("TIME_FORMAT_API", time.RFC3339),
+		DisplayFormat: getEnvTimeLayout("TIME_FORMAT_DISPLAY", "2006-01-02 15:04:05"),
+		LogFormat:     getEnvTimeLayout("TIME_FORMAT_LOG", ""),
+	}
+	if name := getEnv("TIME_ZONE", ""); name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			cfg.Location = loc
+		} else {
+			recordInvalid("TIME_ZONE")
+		}
 	}
+	return cfg
+}
+
+// getEnvTimeLayout 获取时间格式，可以是预定义格式名称或 Go 时间格式，不含任何时间字段时使用默认值
+func getEnvTimeLayout(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	if layout, ok := timeLayouts[value]; ok {
+		return layout
+	}
+	if time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC).Format(value) == value {
+		recordInvalid(key)
+		return defaultValue
+	}
+	return value
+}
+
+var (
+	timeConfig     *TimeConfig
+	timeConfigOnce sync.Once
+)
+
+// currentTimeConfig 格式化时使用的时间配置，时区数据只加载一次
+func currentTimeConfig() *TimeConfig {
+	timeConfigOnce.Do(func() {
+		timeConfig = GetTimeConfig()
+	})
+	return timeConfig
+}
+
+// FormatAPITime 按 TIME_ZONE 和 TIME_FORMAT_API 格式化 API 响应中的时间
+func FormatAPITime(t time.Time) string {
+	cfg := currentTimeConfig()
+	return t.In(cfg.Location).Format(cfg.APIFormat)
+}
+
+// FormatDisplayTime 按 TIME_ZONE 和 TIME_FORMAT_DISPLAY 格式化页面和日志消息中的时间
+func FormatDisplayTime(t time.Time) string {
+	cfg := currentTimeConfig()
+	return t.In(cfg.Location).Format(cfg.DisplayFormat)
 }
 
 // getEnv 获取环境变量，提供默认值
@@ -41,3 +247,808 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvBool 获取布尔型环境变量，无法识别时使用默认值
+func getEnvBool(key string, defaultValue bool) bool {
+	switch strings.ToLower(os.Getenv(key)) {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	case "":
+		return defaultValue
+	default:
+		recordInvalid(key)
+		return defaultValue
+	}
+}
+
+// getEnvDuration 获取时长型环境变量 (如 "30s", "2m")，解析失败时使用默认值
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		} else if value != "0" {
+			recordInvalid(key)
+		}
+	}
+	return defaultValue
+}
+
+// getEnvInt 获取整数型环境变量，解析失败时使用默认值
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+		recordInvalid(key)
+	}
+	return defaultValue
+}
+
+// getEnvFloat 获取浮点型环境变量，解析失败时使用默认值
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+		recordInvalid(key)
+	}
+	return defaultValue
+}
+
+// recordInvalid 记录无法解析而回退为默认值的环境变量
+func recordInvalid(key string) {
+	invalidMu.Lock()
+	defer invalidMu.Unlock()
+	invalidEnv[key] = os.Getenv(key)
+}
+
+// InvalidEnv 返回读取配置时无法解析的环境变量 (变量名 → 原值)，这些变量已回退为默认值
+func InvalidEnv() map[string]string {
+	invalidMu.Lock()
+	defer invalidMu.Unlock()
+	result := make(map[string]string, len(invalidEnv))
+	for k, v := range invalidEnv {
+		result[k] = v
+	}
+	return result
+}
+
+// GrowthConfig 表增长采样配置
+type GrowthConfig struct {
+	SampleInterval    time.Duration // 采样间隔，为 0 时关闭采样
+	MaxSamples        int           // 最多保留的采样数
+	DiskCapacityBytes int64         // 磁盘容量，用于估算剩余天数，为 0 时不估算
+}
+
+// GetGrowthConfig 从环境变量读取表增长采样配置
+func GetGrowthConfig() *GrowthConfig {
+	sampleInterval := getEnvDuration("GROWTH_SAMPLE_INTERVAL", time.Hour)
+	if getEnv("GROWTH_SAMPLE_INTERVAL", "") == "0" {
+		sampleInterval = 0
+	}
+	return &GrowthConfig{
+		SampleInterval:    sampleInterval,
+		MaxSamples:        getEnvInt("GROWTH_MAX_SAMPLES", 720),
+		DiskCapacityBytes: int64(getEnvInt("DISK_CAPACITY_GB", 0)) << 30,
+	}
+}
+
+// PressureConfig 临时表与排序压力采样配置
+type PressureConfig struct {
+	SampleInterval time.Duration // 采样间隔，为 0 时关闭采样
+	MaxSamples     int           // 最多保留的采样数
+}
+
+// GetPressureConfig 从环境变量读取临时表与排序压力采样配置
+func GetPressureConfig() *PressureConfig {
+	sampleInterval := getEnvDuration("PRESSURE_SAMPLE_INTERVAL", time.Minute)
+	if getEnv("PRESSURE_SAMPLE_INTERVAL", "") == "0" {
+		sampleInterval = 0
+	}
+	return &PressureConfig{
+		SampleInterval: sampleInterval,
+		MaxSamples:     getEnvInt("PRESSURE_MAX_SAMPLES", 1440),
+	}
+}
+
+// ThreadsConfig 连接数与活跃线程采样配置
+type ThreadsConfig struct {
+	SampleInterval time.Duration // 采样间隔，为 0 时关闭采样
+	MaxSamples     int           // 最多保留的采样数
+}
+
+// GetThreadsConfig 从环境变量读取连接数与活跃线程采样配置
+func GetThreadsConfig() *ThreadsConfig {
+	sampleInterval := getEnvDuration("THREADS_SAMPLE_INTERVAL", 30*time.Second)
+	if getEnv("THREADS_SAMPLE_INTERVAL", "") == "0" {
+		sampleInterval = 0
+	}
+	return &ThreadsConfig{
+		SampleInterval: sampleInterval,
+		MaxSamples:     getEnvInt("THREADS_MAX_SAMPLES", 2880),
+	}
+}
+
+// ReplicaLagConfig 复制延迟采样配置
+type ReplicaLagConfig struct {
+	SampleInterval time.Duration // 采样间隔，为 0 时关闭采样
+	MaxSamples     int           // 每个服务器最多保留的采样数
+}
+
+// GetReplicaLagConfig 从环境变量读取复制延迟采样配置
+func GetReplicaLagConfig() *ReplicaLagConfig {
+	sampleInterval := getEnvDuration("REPLICA_LAG_SAMPLE_INTERVAL", 30*time.Second)
+	if getEnv("REPLICA_LAG_SAMPLE_INTERVAL", "") == "0" {
+		sampleInterval = 0
+	}
+	return &ReplicaLagConfig{
+		SampleInterval: sampleInterval,
+		MaxSamples:     getEnvInt("REPLICA_LAG_MAX_SAMPLES", 2880),
+	}
+}
+
+// TopologyConfig 服务器拓扑变化检测配置
+type TopologyConfig struct {
+	CheckInterval time.Duration // 检查间隔，为 0 时关闭检测
+	MaxChanges    int           // 最多保留的拓扑变化记录数
+}
+
+// GetTopologyConfig 从环境变量读取服务器拓扑变化检测配置
+func GetTopologyConfig() *TopologyConfig {
+	checkInterval := getEnvDuration("TOPOLOGY_CHECK_INTERVAL", time.Minute)
+	if getEnv("TOPOLOGY_CHECK_INTERVAL", "") == "0" {
+		checkInterval = 0
+	}
+	return &TopologyConfig{
+		CheckInterval: checkInterval,
+		MaxChanges:    getEnvInt("TOPOLOGY_MAX_CHANGES", 500),
+	}
+}
+
+// IdleTrxConfig 空闲事务自动终止策略配置 (默认关闭)
+type IdleTrxConfig struct {
+	KillAfter     time.Duration // 事务空闲超过该时长的会话被终止，为 0 时关闭策略
+	Schemas       []string      // 只处理当前数据库在列表中的会话，为空时策略不生效
+	DryRun        bool          // 只记录和通知将要终止的会话，不执行 KILL
+	CheckInterval time.Duration // 检查间隔
+	MaxActions    int           // 最多保留的操作记录数
+}
+
+// GetIdleTrxConfig 从环境变量读取空闲事务自动终止策略配置
+func GetIdleTrxConfig() *IdleTrxConfig {
+	return &IdleTrxConfig{
+		KillAfter:     getEnvDuration("IDLE_TRX_KILL_AFTER", 0),
+		Schemas:       getEnvList("IDLE_TRX_KILL_SCHEMAS"),
+		DryRun:        getEnvBool("IDLE_TRX_KILL_DRY_RUN", true),
+		CheckInterval: getEnvDuration("IDLE_TRX_KILL_INTERVAL", 30*time.Second),
+		MaxActions:    getEnvInt("IDLE_TRX_KILL_MAX_ACTIONS", 500),
+	}
+}
+
+// Enabled 是否配置了空闲时长阈值和适用的数据库
+func (c *IdleTrxConfig) Enabled() bool {
+	return c.KillAfter > 0 && len(c.Schemas) > 0
+}
+
+// LogConfig 日志保留配置，对每个服务器的日志管理器分别生效
+type LogConfig struct {
+	MaxEntries int            // 保留的日志条数 (不含固定的条目)，超出时先淘汰级别最低的条目
+	Retention  map[string]int // 按级别限制保留的条数，如 debug=20,info=50，未配置的级别只受 MaxEntries 限制
+	// ClearKeepLevel 清空日志时保留该级别及以上的条目，为空时全部清空；固定的条目始终保留
+	ClearKeepLevel string
+	MaxPinned      int // 最多固定的条目数
+	// DedupWindow 同一组件、同一级别的相同消息在该时长内再次出现时合并到已有条目，不要求连续出现
+	DedupWindow time.Duration
+	// DedupSummaryInterval 每隔该时长为被合并的消息写入一条 "最近重复 K 次" 的汇总条目
+	DedupSummaryInterval time.Duration
+	// FatalExit 致命日志立即调用 os.Exit(1) (旧行为)，跳过数据库连接关闭等清理；默认通知 main 优雅关闭
+	FatalExit bool
+}
+
+// GetLogConfig 从环境变量读取日志保留配置
+func GetLogConfig() *LogConfig {
+	cfg := &LogConfig{
+		MaxEntries:     getEnvInt("LOG_MAX_ENTRIES", 100),
+		Retention:      make(map[string]int),
+		ClearKeepLevel: strings.ToLower(getEnv("LOG_CLEAR_KEEP_LEVEL", "fatal")),
+		MaxPinned:      getEnvInt("LOG_MAX_PINNED", 50),
+
+		DedupWindow:          getEnvDuration("LOG_DEDUP_WINDOW", 10*time.Second),
+		DedupSummaryInterval: getEnvDuration("LOG_DEDUP_SUMMARY_INTERVAL", time.Minute),
+
+		FatalExit: getEnvBool("LOG_FATAL_EXIT", false),
+	}
+	for level, v := range getEnvLabels("LOG_RETENTION") {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			recordInvalid("LOG_RETENTION")
+			continue
+		}
+		cfg.Retention[strings.ToLower(level)] = n
+	}
+	return cfg
+}
+
+// ShareConfig 分享链接配置
+type ShareConfig struct {
+	DefaultTTL time.Duration // 未指定有效期时使用的默认值
+	MaxTTL     time.Duration // 允许的最长有效期
+}
+
+// GetShareConfig 从环境变量读取分享链接配置
+func GetShareConfig() *ShareConfig {
+	return &ShareConfig{
+		DefaultTTL: getEnvDuration("SHARE_DEFAULT_TTL", 24*time.Hour),
+		MaxTTL:     getEnvDuration("SHARE_MAX_TTL", 7*24*time.Hour),
+	}
+}
+
+// TableMetricsConfig 按表导出 Prometheus 指标的配置
+type TableMetricsConfig struct {
+	Enabled   bool
+	Allowlist []string // db.table 形式的模式，支持 * 和 ? 通配，为空时导出全部表
+	MaxTables int      // 最多导出的表数，用于限制指标基数
+}
+
+// GetTableMetricsConfig 从环境变量读取按表导出指标的配置
+func GetTableMetricsConfig() *TableMetricsConfig {
+	return &TableMetricsConfig{
+		Enabled:   getEnvBool("TABLE_METRICS_ENABLED", false),
+		Allowlist: getEnvList("TABLE_METRICS_ALLOWLIST"),
+		MaxTables: getEnvInt("TABLE_METRICS_MAX_TABLES", 500),
+	}
+}
+
+// SnapshotConfig 结构快照定期导出配置
+type SnapshotConfig struct {
+	Interval  time.Duration // 导出间隔，为 0 时关闭
+	Retention int           // 保留最近的快照数
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3Prefix    string
+	S3AccessKey string
+	S3SecretKey string
+}
+
+// GetSnapshotConfig 从环境变量读取结构快照导出配置
+func GetSnapshotConfig() *SnapshotConfig {
+	return &SnapshotConfig{
+		Interval:  getEnvDuration("SNAPSHOT_INTERVAL", 0),
+		Retention: getEnvInt("SNAPSHOT_RETENTION", 30),
+
+		S3Endpoint:  getEnv("SNAPSHOT_S3_ENDPOINT", ""),
+		S3Region:    getEnv("SNAPSHOT_S3_REGION", "us-east-1"),
+		S3Bucket:    getEnv("SNAPSHOT_S3_BUCKET", ""),
+		S3Prefix:    getEnv("SNAPSHOT_S3_PREFIX", "block-checker/schema/"),
+		S3AccessKey: getEnv("SNAPSHOT_S3_ACCESS_KEY", ""),
+		S3SecretKey: getEnv("SNAPSHOT_S3_SECRET_KEY", ""),
+	}
+}
+
+// Enabled 是否已配置定期导出
+func (c *SnapshotConfig) Enabled() bool {
+	return c.Interval > 0 && c.S3Endpoint != "" && c.S3Bucket != ""
+}
+
+// MigrationConfig 迁移目录结构漂移检测配置
+type MigrationConfig struct {
+	Dir           string        // 迁移 SQL 文件目录，为空时关闭
+	Database      string        // 与迁移结果比较的数据库
+	CheckInterval time.Duration // 定期检测间隔，为 0 时只按需检测
+}
+
+// GetMigrationConfig 从环境变量读取迁移漂移检测配置
+func GetMigrationConfig() *MigrationConfig {
+	return &MigrationConfig{
+		Dir:           getEnv("MIGRATIONS_DIR", ""),
+		Database:      getEnv("MIGRATIONS_DATABASE", getEnv("DB_NAME", "mysql")),
+		CheckInterval: getEnvDuration("MIGRATIONS_CHECK_INTERVAL", 0),
+	}
+}
+
+// Enabled 是否已配置迁移目录
+func (c *MigrationConfig) Enabled() bool {
+	return c.Dir != ""
+}
+
+// AlertConfig 告警规则配置，阈值为 0 的规则不启用
+type AlertConfig struct {
+	EvalInterval time.Duration // 规则评估间隔
+	WebhookURLs  []string      // Alertmanager 格式的 webhook 地址
+	ExternalURL  string        // 告警中 generatorURL 使用的外部访问地址
+	// AckSecret 签名告警确认链接的密钥，与 ExternalURL 都配置时通知中附带确认链接 (注释 ack_url)
+	AckSecret string
+	// Routes 按标签路由到通知渠道的规则，格式见 alert.ParseRoutes，为空时发送到所有渠道
+	Routes string
+	// Escalations 告警升级策略，格式见 alert.ParseEscalations，匹配升级策略的告警不再按 Routes 路由
+	Escalations string
+	// 通知限流，格式见 alert.ParseThrottle：各渠道的静默时段、同一告警重复通知的最小间隔，以及每小时通知总数上限
+	QuietHours       string
+	RenotifyInterval string
+	MaxPerHour       int // 为 0 时不限制
+
+	ReconnectThreshold       int           // 连续重连失败次数阈值
+	ReconnectFor             time.Duration // 持续多久后触发
+	BlockedSessionsThreshold int           // 锁等待会话数阈值
+	BlockedSessionsFor       time.Duration
+	ReplicaLagThreshold      int           // 复制延迟阈值 (秒)
+	ReplicaLagFor            time.Duration // 短暂的延迟峰值属于正常现象，默认持续 5 分钟才触发
+	ErrorAnomaly             bool          // 任一错误代码的当前小时频率显著偏离基线时告警
+	ErrorAnomalyFor          time.Duration
+	ConnectionUsagePercent   float64 // 已用连接占 max_connections 的百分比阈值
+	ConnectionUsageFor       time.Duration
+	ThreadsRunningThreshold  int // 活跃线程数阈值
+	ThreadsRunningFor        time.Duration
+	ProfileDown              bool // PROFILES 中的服务器无法连接时告警
+	ProfileDownFor           time.Duration
+
+	// TableRows 按表增长采样监控行数变化的表及各自阈值，格式见 alert.ParseTableRowRules
+	TableRows      string
+	RowDropPercent float64       // 未单独指定时的行数下降百分比阈值
+	RowDropWindow  time.Duration // 与该时间窗口内的最大行数比较
+	RowDropMinRows int           // 窗口内最大行数低于该值的表不判定下降
+	RowStallFor    time.Duration // 未单独指定时的停止增长时长阈值
+
+	ChecksumMismatch bool // 主从表数据校验发现不一致的分块时告警
+
+	// DiskDaysUntilFull 按表增长预测的磁盘写满天数低于该值时告警，为 0 时不启用 (需配置 DISK_CAPACITY_GB)
+	DiskDaysUntilFull float64
+
+	// CheckFailed 扩展检查 (包括外部命令检查) 结果为 warning 或 critical 时以相应级别告警
+	CheckFailed    bool
+	CheckFailedFor time.Duration
+
+	// TopologyChangeHold 服务器拓扑变化 (故障切换、DNS 指向变化) 后告警保持触发的时长，为 0 时不告警
+	TopologyChangeHold time.Duration
+
+	// Template 聊天机器人消息的默认模板 (text/template)，数据字段见 alert.messageData
+	// 为空时使用内置模板；以 @ 开头时从该路径的文件读取，渠道模板同样适用
+	Template string
+
+	// 聊天机器人通知渠道，模板为空时使用 Template
+	DingTalkWebhook  string
+	DingTalkSecret   string // 加签密钥
+	DingTalkTemplate string
+	FeishuWebhook    string
+	FeishuSecret     string // 签名校验密钥
+	FeishuTemplate   string
+	TelegramBotToken string
+	TelegramChatID   string
+	TelegramAPIURL   string
+	TelegramTemplate string
+}
+
+// GetAlertConfig 从环境变量读取告警规则配置
+func GetAlertConfig() *AlertConfig {
+	return &AlertConfig{
+		EvalInterval: getEnvDuration("ALERT_EVAL_INTERVAL", 30*time.Second),
+		WebhookURLs:  getEnvList("ALERT_WEBHOOK_URLS"),
+		ExternalURL:  getEnv("ALERT_EXTERNAL_URL", ""),
+		AckSecret:    getEnv("ALERT_ACK_SECRET", ""),
+		Routes:       getEnv("ALERT_ROUTES", ""),
+		Escalations:  getEnv("ALERT_ESCALATIONS", ""),
+
+		QuietHours:       getEnv("ALERT_QUIET_HOURS", ""),
+		RenotifyInterval: getEnv("ALERT_RENOTIFY_INTERVAL", ""),
+		MaxPerHour:       getEnvInt("ALERT_MAX_PER_HOUR", 0),
+
+		ReconnectThreshold:       getEnvInt("ALERT_RECONNECT_THRESHOLD", 0),
+		ReconnectFor:             getEnvDuration("ALERT_RECONNECT_FOR", 0),
+		BlockedSessionsThreshold: getEnvInt("ALERT_BLOCKED_SESSIONS_THRESHOLD", 0),
+		BlockedSessionsFor:       getEnvDuration("ALERT_BLOCKED_SESSIONS_FOR", 0),
+		ReplicaLagThreshold:      getEnvInt("ALERT_REPLICA_LAG_THRESHOLD", 0),
+		ReplicaLagFor:            getEnvDuration("ALERT_REPLICA_LAG_FOR", 5*time.Minute),
+		ErrorAnomaly:             getEnvBool("ALERT_ERROR_ANOMALY", false),
+		ErrorAnomalyFor:          getEnvDuration("ALERT_ERROR_ANOMALY_FOR", 0),
+		ConnectionUsagePercent:   getEnvFloat("ALERT_CONNECTION_USAGE_PERCENT", 0),
+		ConnectionUsageFor:       getEnvDuration("ALERT_CONNECTION_USAGE_FOR", 0),
+		ThreadsRunningThreshold:  getEnvInt("ALERT_THREADS_RUNNING_THRESHOLD", 0),
+		ThreadsRunningFor:        getEnvDuration("ALERT_THREADS_RUNNING_FOR", 0),
+		ProfileDown:              getEnvBool("ALERT_PROFILE_DOWN", false),
+		ProfileDownFor:           getEnvDuration("ALERT_PROFILE_DOWN_FOR", 0),
+
+		TableRows:      getEnv("ALERT_TABLE_ROWS", ""),
+		RowDropPercent: getEnvFloat("ALERT_ROW_DROP_PERCENT", 30),
+		RowDropWindow:  getEnvDuration("ALERT_ROW_DROP_WINDOW", 6*time.Hour),
+		RowDropMinRows: getEnvInt("ALERT_ROW_DROP_MIN_ROWS", 1000),
+		RowStallFor:    getEnvDuration("ALERT_ROW_STALL_FOR", 0),
+
+		ChecksumMismatch: getEnvBool("ALERT_CHECKSUM_MISMATCH", false),
+
+		DiskDaysUntilFull: getEnvFloat("ALERT_DISK_DAYS_UNTIL_FULL", 0),
+
+		CheckFailed:    getEnvBool("ALERT_CHECK_FAILED", false),
+		CheckFailedFor: getEnvDuration("ALERT_CHECK_FAILED_FOR", 0),
+
+		TopologyChangeHold: getEnvDuration("ALERT_TOPOLOGY_CHANGE_HOLD", 0),
+
+		Template: getEnv("ALERT_TEMPLATE", ""),
+
+		DingTalkWebhook:  getEnv("DINGTALK_WEBHOOK_URL", ""),
+		DingTalkSecret:   getEnv("DINGTALK_SECRET", ""),
+		DingTalkTemplate: getEnv("DINGTALK_TEMPLATE", ""),
+		FeishuWebhook:    getEnv("FEISHU_WEBHOOK_URL", ""),
+		FeishuSecret:     getEnv("FEISHU_SECRET", ""),
+		FeishuTemplate:   getEnv("FEISHU_TEMPLATE", ""),
+		TelegramBotToken: getEnv("TELEGRAM_BOT_TOKEN", ""),
+		TelegramChatID:   getEnv("TELEGRAM_CHAT_ID", ""),
+		TelegramAPIURL:   getEnv("TELEGRAM_API_URL", "https://api.telegram.org"),
+		TelegramTemplate: getEnv("TELEGRAM_TEMPLATE", ""),
+	}
+}
+
+// Enabled 是否配置了任一告警规则
+func (c *AlertConfig) Enabled() bool {
+	return c.ReconnectThreshold > 0 || c.BlockedSessionsThreshold > 0 || c.ReplicaLagThreshold > 0 ||
+		c.ErrorAnomaly || c.ConnectionUsagePercent > 0 || c.ThreadsRunningThreshold > 0 || c.ProfileDown ||
+		c.TableRows != "" || c.ChecksumMismatch || c.TopologyChangeHold > 0 || c.CheckFailed
+}
+
+// getEnvList 获取逗号分隔的列表型环境变量，忽略空项
+func getEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// HeartbeatConfig 外部心跳推送配置
+type HeartbeatConfig struct {
+	URL      string        // 心跳地址，为空时关闭
+	Method   string        // GET 或 POST
+	Interval time.Duration // 推送间隔
+}
+
+// GetHeartbeatConfig 从环境变量读取心跳推送配置
+func GetHeartbeatConfig() *HeartbeatConfig {
+	return &HeartbeatConfig{
+		URL:      getEnv("HEARTBEAT_URL", ""),
+		Method:   strings.ToUpper(getEnv("HEARTBEAT_METHOD", "GET")),
+		Interval: getEnvDuration("HEARTBEAT_INTERVAL", time.Minute),
+	}
+}
+
+// MaskingConfig 数据脱敏配置
+type MaskingConfig struct {
+	Rules []string // 规则列表，格式为 db.table.column=action，各段可用 * 通配
+	Salt  string   // hash 脱敏使用的密钥
+}
+
+// GetMaskingConfig 从环境变量读取数据脱敏配置
+func GetMaskingConfig() *MaskingConfig {
+	return &MaskingConfig{
+		Rules: getEnvList("MASKING_RULES"),
+		Salt:  getEnv("MASKING_SALT", ""),
+	}
+}
+
+// SchemaGenConfig Avro/Protobuf 结构生成配置
+type SchemaGenConfig struct {
+	TypeMapping []string // 类型映射覆盖，格式为 format:mysqltype=type，如 avro:datetime=string、proto:decimal=double
+}
+
+// GetSchemaGenConfig 从环境变量读取结构生成配置
+func GetSchemaGenConfig() *SchemaGenConfig {
+	return &SchemaGenConfig{
+		TypeMapping: getEnvList("SCHEMAGEN_TYPE_MAPPING"),
+	}
+}
+
+// ErrorAnalysisConfig 错误频率基线、异常检测与频率数据保留配置
+type ErrorAnalysisConfig struct {
+	Window   time.Duration // 基线统计的时间窗口
+	Sigma    float64       // 超过均值多少个标准差视为异常
+	MinCount int           // 当前小时至少出现多少次才判定异常
+
+	HourlyRetention time.Duration // 按小时保留的时长，更早的数据合并为按天统计
+	Retention       time.Duration // 频率数据的总保留时长
+	CompactInterval time.Duration // 合并清理的执行间隔
+}
+
+// GetErrorAnalysisConfig 从环境变量读取错误分析配置
+// 按小时保留的时长不短于基线窗口，总保留时长不短于按小时保留的时长
+func GetErrorAnalysisConfig() *ErrorAnalysisConfig {
+	cfg := &ErrorAnalysisConfig{
+		Window:   getEnvDuration("ERROR_BASELINE_WINDOW", 7*24*time.Hour),
+		Sigma:    getEnvFloat("ERROR_ANOMALY_SIGMA", 3),
+		MinCount: getEnvInt("ERROR_ANOMALY_MIN_COUNT", 5),
+
+		HourlyRetention: getEnvDuration("ERROR_HOURLY_RETENTION", 7*24*time.Hour),
+		Retention:       getEnvDuration("ERROR_RETENTION", 90*24*time.Hour),
+		CompactInterval: getEnvDuration("ERROR_COMPACT_INTERVAL", time.Hour),
+	}
+	if cfg.HourlyRetention < cfg.Window {
+		cfg.HourlyRetention = cfg.Window
+	}
+	if cfg.Retention < cfg.HourlyRetention {
+		cfg.Retention = cfg.HourlyRetention
+	}
+	return cfg
+}
+
+// SLOConfig 健康评分与 SLO 配置
+type SLOConfig struct {
+	Enabled               bool
+	Interval              time.Duration // 评分间隔
+	Target                float64       // SLO 目标 (百分比)，即评分达标的时间占比
+	Window                time.Duration // SLO 统计窗口，同时决定评分历史的保留时长
+	GoodScore             float64       // 评分不低于该值视为达标
+	LatencyTarget         time.Duration // 状态检查 p95 延迟目标
+	ReplicaLagTarget      time.Duration // 复制延迟目标
+	BlockedSessionsTarget int           // 可容忍的锁等待会话数
+}
+
+// GetSLOConfig 从环境变量读取健康评分与 SLO 配置
+func GetSLOConfig() *SLOConfig {
+	return &SLOConfig{
+		Enabled:               getEnvBool("SLO_ENABLED", true),
+		Interval:              getEnvDuration("SLO_EVAL_INTERVAL", time.Minute),
+		Target:                getEnvFloat("SLO_TARGET", 99.9),
+		Window:                getEnvDuration("SLO_WINDOW", 30*24*time.Hour),
+		GoodScore:             getEnvFloat("SLO_GOOD_SCORE", 80),
+		LatencyTarget:         getEnvDuration("SLO_LATENCY_TARGET", 100*time.Millisecond),
+		ReplicaLagTarget:      getEnvDuration("SLO_REPLICA_LAG_TARGET", 30*time.Second),
+		BlockedSessionsTarget: getEnvInt("SLO_BLOCKED_SESSIONS_TARGET", 0),
+	}
+}
+
+// QueryBudgetConfig 请求查询预算配置
+type QueryBudgetConfig struct {
+	MaxQueries int           // 单个请求的查询次数上限，超过时记录警告，为 0 时不检查
+	MaxTime    time.Duration // 单个请求的查询累计耗时上限，超过时记录警告
+	Debug      bool          // 是否在响应头 X-DB-Queries 中返回查询统计
+}
+
+// GetQueryBudgetConfig 从环境变量读取请求查询预算配置
+func GetQueryBudgetConfig() *QueryBudgetConfig {
+	return &QueryBudgetConfig{
+		MaxQueries: getEnvInt("QUERY_BUDGET_MAX_QUERIES", 20),
+		MaxTime:    getEnvDuration("QUERY_BUDGET_MAX_TIME", time.Second),
+		Debug:      getEnvBool("QUERY_BUDGET_DEBUG", false),
+	}
+}
+
+// ChecksConfig 扩展检查配置
+type ChecksConfig struct {
+	Interval time.Duration // 检查未指定间隔时的默认运行间隔
+	Timeout  time.Duration // 单次检查的超时
+	ExecDir  string        // 外部检查目录，其中每个可执行文件作为一个检查运行，为空时不加载
+	Commands string        // 外部命令检查，格式见 checks.ParseCommandChecks
+	History  int           // 每个检查保留的历史结果数
+}
+
+// GetChecksConfig 从环境变量读取扩展检查配置
+func GetChecksConfig() *ChecksConfig {
+	return &ChecksConfig{
+		Interval: getEnvDuration("CHECKS_INTERVAL", time.Minute),
+		Timeout:  getEnvDuration("CHECKS_TIMEOUT", 30*time.Second),
+		ExecDir:  getEnv("CHECKS_EXEC_DIR", ""),
+		Commands: getEnv("CHECKS_COMMANDS", ""),
+		History:  getEnvInt("CHECKS_HISTORY", 100),
+	}
+}
+
+// DictionaryConfig 数据字典配置
+type DictionaryConfig struct {
+	PinyinFile string        // pinyin-data 格式的拼音文件，配置后支持按拼音搜索注释
+	CacheTTL   time.Duration // 注释索引的缓存时长
+}
+
+// GetDictionaryConfig 从环境变量读取数据字典配置
+func GetDictionaryConfig() *DictionaryConfig {
+	return &DictionaryConfig{
+		PinyinFile: getEnv("DICTIONARY_PINYIN_FILE", ""),
+		CacheTTL:   getEnvDuration("DICTIONARY_CACHE_TTL", 10*time.Minute),
+	}
+}
+
+// ProcessListConfig 进程列表实时跟踪配置
+type ProcessListConfig struct {
+	TailInterval time.Duration // 默认的采样间隔
+	MaxTails     int           // 同时跟踪的客户端数上限，为 0 时不限制
+}
+
+// GetProcessListConfig 从环境变量读取进程列表实时跟踪配置
+func GetProcessListConfig() *ProcessListConfig {
+	return &ProcessListConfig{
+		TailInterval: getEnvDuration("PROCESSLIST_TAIL_INTERVAL", 2*time.Second),
+		MaxTails:     getEnvInt("PROCESSLIST_MAX_TAILS", 5),
+	}
+}
+
+// BlockHistoryConfig 阻塞事件历史配置
+type BlockHistoryConfig struct {
+	ScanInterval time.Duration // 锁等待扫描间隔，为 0 时关闭扫描
+	Threshold    time.Duration // 最长等待达到该值的阻塞才记为事件
+	MaxEvents    int           // 最多保留的事件数
+}
+
+// GetBlockHistoryConfig 从环境变量读取阻塞事件历史配置
+func GetBlockHistoryConfig() *BlockHistoryConfig {
+	scanInterval := getEnvDuration("BLOCK_SCAN_INTERVAL", 10*time.Second)
+	if getEnv("BLOCK_SCAN_INTERVAL", "") == "0" {
+		scanInterval = 0
+	}
+	return &BlockHistoryConfig{
+		ScanInterval: scanInterval,
+		Threshold:    getEnvDuration("BLOCK_HISTORY_THRESHOLD", 10*time.Second),
+		MaxEvents:    getEnvInt("BLOCK_HISTORY_MAX_EVENTS", 1000),
+	}
+}
+
+// AnnotationConfig 时间段注释配置
+type AnnotationConfig struct {
+	MaxAnnotations int // 最多保留的注释数，超出时删除最早创建的注释
+}
+
+// GetAnnotationConfig 从环境变量读取时间段注释配置
+func GetAnnotationConfig() *AnnotationConfig {
+	return &AnnotationConfig{
+		MaxAnnotations: getEnvInt("ANNOTATIONS_MAX", 1000),
+	}
+}
+
+// DiagnosticsConfig 故障诊断包配置
+type DiagnosticsConfig struct {
+	Enabled                 bool
+	BlockThreshold          time.Duration // 阻塞的最长等待达到该值时采集
+	ConnectionLossThreshold time.Duration // 连接中断持续该时长时采集
+	Cooldown                time.Duration // 两次自动采集的最小间隔
+	MaxBundles              int           // 最多保留的诊断包数
+	LogEntries              int           // 诊断包中包含的最近日志条数
+}
+
+// GetDiagnosticsConfig 从环境变量读取故障诊断包配置
+func GetDiagnosticsConfig() *DiagnosticsConfig {
+	return &DiagnosticsConfig{
+		Enabled:                 getEnvBool("DIAG_ENABLED", true),
+		BlockThreshold:          getEnvDuration("DIAG_BLOCK_THRESHOLD", 30*time.Second),
+		ConnectionLossThreshold: getEnvDuration("DIAG_CONNECTION_LOSS_THRESHOLD", time.Minute),
+		Cooldown:                getEnvDuration("DIAG_COOLDOWN", 10*time.Minute),
+		MaxBundles:              getEnvInt("DIAG_MAX_BUNDLES", 20),
+		LogEntries:              getEnvInt("DIAG_LOG_ENTRIES", 500),
+	}
+}
+
+// StorageConfig 持久化存储配置，用于保存阻塞事件历史和诊断包
+type StorageConfig struct {
+	Backend     string // bbolt (默认)、sqlite、file、mysql 或 none，为 none 时只保存在内存中且不采集诊断包
+	Dir         string // bbolt、sqlite 和 file 后端的数据目录
+	MySQLDSN    string // mysql 后端的连接串，可以指向被检测的服务器或单独的实例
+	MySQLSchema string // mysql 后端使用的库，不存在时自动创建
+}
+
+// GetStorageConfig 从环境变量读取持久化存储配置
+func GetStorageConfig() *StorageConfig {
+	return &StorageConfig{
+		Backend:     strings.ToLower(getEnv("STORAGE_BACKEND", "bbolt")),
+		Dir:         getEnv("DATA_DIR", "data"),
+		MySQLDSN:    getEnv("STORAGE_MYSQL_DSN", ""),
+		MySQLSchema: getEnv("STORAGE_MYSQL_SCHEMA", "block_checker"),
+	}
+}
+
+// ProfileConfig 额外监控的服务器 (连接配置)
+type ProfileConfig struct {
+	Name string
+	DSN  string // go-sql-driver 格式的连接串
+	URL  string // 该服务器自己的 block-checker 地址，仪表盘中作为详情链接
+	// Labels 服务器标签 (如 env=prod, team=payments)，用于筛选、分组和告警路由
+	Labels map[string]string
+}
+
+// ProfilesConfig 多服务器总览配置
+type ProfilesConfig struct {
+	Profiles      []ProfileConfig
+	DefaultLabels map[string]string // 默认连接 (DB_*) 的标签
+	CheckInterval time.Duration     // 各服务器的检查间隔
+}
+
+// GetProfilesConfig 从环境变量读取多服务器总览配置
+// PROFILES 为逗号分隔的名称列表，每个名称通过 PROFILE_<NAME>_DSN、PROFILE_<NAME>_URL 和 PROFILE_<NAME>_LABELS 配置
+// 名称转为大写，- 和 . 替换为 _ 后作为环境变量名的一部分；默认连接的标签为 PROFILE_DEFAULT_LABELS
+func GetProfilesConfig() *ProfilesConfig {
+	cfg := &ProfilesConfig{
+		DefaultLabels: getEnvLabels("PROFILE_DEFAULT_LABELS"),
+		CheckInterval: getEnvDuration("PROFILES_CHECK_INTERVAL", 30*time.Second),
+	}
+	for _, name := range getEnvList("PROFILES") {
+		prefix := "PROFILE_" + strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToUpper(name)) + "_"
+		cfg.Profiles = append(cfg.Profiles, ProfileConfig{
+			Name:   name,
+			DSN:    getEnv(prefix+"DSN", ""),
+			URL:    getEnv(prefix+"URL", ""),
+			Labels: getEnvLabels(prefix + "LABELS"),
+		})
+	}
+	return cfg
+}
+
+// getEnvLabels 获取 key=value 形式、逗号分隔的标签型环境变量，存在无法解析的项时记录并忽略该项
+func getEnvLabels(key string) map[string]string {
+	labels := make(map[string]string)
+	for _, item := range getEnvList(key) {
+		k, v, ok := strings.Cut(item, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			recordInvalid(key)
+			continue
+		}
+		labels[k] = strings.TrimSpace(v)
+	}
+	return labels
+}
+
+// ChecksumConfig 主从表数据校验配置
+type ChecksumConfig struct {
+	Tables            []string      // 要校验的表 (库名.表名)，为空时关闭
+	Source            string        // 主库服务器名称，default 为默认连接
+	Replica           string        // 从库服务器名称 (PROFILES 中的名称)
+	Interval          time.Duration // 定期校验间隔，为 0 时只按需校验
+	ChunkSize         int           // 每个分块的行数
+	ChunkPause        time.Duration // 两个分块之间的暂停时间，降低对主从库的压力
+	MaxThreadsRunning int           // 主库活跃线程数超过该值时暂停校验，为 0 时不检查
+	RecheckDelay      time.Duration // 分块不一致时等待该时长后重新校验，排除复制延迟造成的误报
+}
+
+// GetChecksumConfig 从环境变量读取主从表数据校验配置
+func GetChecksumConfig() *ChecksumConfig {
+	return &ChecksumConfig{
+		Tables:            getEnvList("CHECKSUM_TABLES"),
+		Source:            getEnv("CHECKSUM_SOURCE", "default"),
+		Replica:           getEnv("CHECKSUM_REPLICA", ""),
+		Interval:          getEnvDuration("CHECKSUM_INTERVAL", 0),
+		ChunkSize:         getEnvInt("CHECKSUM_CHUNK_SIZE", 1000),
+		ChunkPause:        getEnvDuration("CHECKSUM_CHUNK_PAUSE", 100*time.Millisecond),
+		MaxThreadsRunning: getEnvInt("CHECKSUM_MAX_THREADS_RUNNING", 25),
+		RecheckDelay:      getEnvDuration("CHECKSUM_RECHECK_DELAY", 5*time.Second),
+	}
+}
+
+// Enabled 是否已配置要校验的表和从库
+func (c *ChecksumConfig) Enabled() bool {
+	return len(c.Tables) > 0 && c.Replica != ""
+}
+
+// CanaryConfig 写入探测配置：定期在专用表中插入、读回并删除一行，发现 Ping 正常但写入挂起的故障
+type CanaryConfig struct {
+	Enabled     bool
+	Interval    time.Duration // 探测间隔
+	Timeout     time.Duration // 单次探测的超时，写入挂起时以此判定失败
+	Database    string        // 探测表所在的数据库
+	Table       string        // 探测表，不存在时自动创建
+	WarnLatency time.Duration // 写入耗时超过该值时为 warning
+}
+
+// GetCanaryConfig 从环境变量读取写入探测配置
+func GetCanaryConfig() *CanaryConfig {
+	return &CanaryConfig{
+		Enabled:     getEnvBool("CANARY_ENABLED", false),
+		Interval:    getEnvDuration("CANARY_INTERVAL", time.Minute),
+		Timeout:     getEnvDuration("CANARY_TIMEOUT", 10*time.Second),
+		Database:    getEnv("CANARY_DATABASE", getEnv("DB_NAME", "mysql")),
+		Table:       getEnv("CANARY_TABLE", "block_checker_canary"),
+		WarnLatency: getEnvDuration("CANARY_WARN_LATENCY", time.Second),
+	}
+}
+
+// ChaosConfig 故障模拟 (开发者模式) 配置，开启后可通过 API 注入连接中断、查询延迟和指定分类的错误
+type ChaosConfig struct {
+	Enabled     bool          // 仅用于测试环境，生产环境不应开启
+	MaxDuration time.Duration // 单个模拟故障的最长持续时间，到期后自动恢复
+}
+
+// GetChaosConfig 从环境变量读取故障模拟配置
+func GetChaosConfig() *ChaosConfig {
+	return &ChaosConfig{
+		Enabled:     getEnvBool("CHAOS_ENABLED", false),
+		MaxDuration: getEnvDuration("CHAOS_MAX_DURATION", 30*time.Minute),
+	}
+}
@@ -0,0 +1,238 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/storage"
+)
+
+// annotationsBucket 注释在持久化存储中的分组
+const annotationsBucket = "annotations"
+
+// maxAnnotationTextLen 注释内容的最大长度 (字节)
+const maxAnnotationTextLen = 1024
+
+// ErrAnnotationNotFound 注释不存在
+var ErrAnnotationNotFound = errors.New("annotation not found")
+
+// Annotation 操作员对一段时间的注释，如 "14:05 发布 v2.3"、"故障转移演练"
+// 叠加显示在日志、错误趋势和故障报告中，便于把指标变化与人为操作对应起来
+type Annotation struct {
+	ID        int64     `json:"id"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"` // 与 Start 相同时表示一个时间点
+	Text      string    `json:"text"`
+	Tags      []string  `json:"tags,omitempty"`
+	Profile   string    `json:"profile,omitempty"` // 为空时作用于所有服务器
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Overlaps 判断注释是否与 from 至 to 的时间段重叠，时间点注释落在时间段内即重叠
+func (a Annotation) Overlaps(from, to time.Time) bool {
+	return !a.End.Before(from) && !a.Start.After(to)
+}
+
+// appliesTo 判断注释是否作用于指定服务器，profile 为空时匹配所有注释
+func (a Annotation) appliesTo(profile string) bool {
+	return profile == "" || a.Profile == "" || strings.EqualFold(a.Profile, profile)
+}
+
+// AnnotationStore 时间段注释，配置了持久化存储时重启后保留
+type AnnotationStore struct {
+	mu          sync.RWMutex
+	annotations []Annotation // 按 ID 升序
+	nextID      int64
+	max         int
+	storage     storage.Storage // 为 nil 时只保存在内存中
+	logger      *ComponentLogger
+}
+
+var (
+	annotationStore     *AnnotationStore
+	annotationStoreOnce sync.Once
+)
+
+// GetAnnotations 获取注释实例，首次调用时从持久化存储加载
+func GetAnnotations() *AnnotationStore {
+	annotationStoreOnce.Do(func() {
+		annotationStore = &AnnotationStore{
+			max:    config.GetAnnotationConfig().MaxAnnotations,
+			logger: GetDatabaseLogger().Component(ComponentScheduler),
+		}
+		if persistentStorage != nil {
+			annotationStore.storage = persistentStorage
+			if err := annotationStore.load(context.Background()); err != nil {
+				annotationStore.logger.Warn("加载时间段注释失败", err.Error())
+			}
+		}
+	})
+	return annotationStore
+}
+
+// load 读取保存的注释
+func (s *AnnotationStore) load(ctx context.Context) error {
+	items, err := s.storage.List(ctx, annotationsBucket)
+	if err != nil {
+		return err
+	}
+	annotations := make([]Annotation, 0, len(items))
+	for _, item := range items {
+		data, err := s.storage.Get(ctx, annotationsBucket, item.Key)
+		if err != nil {
+			return err
+		}
+		var a Annotation
+		if err := json.Unmarshal(data, &a); err != nil {
+			s.logger.Warn("跳过无法解析的时间段注释", item.Key)
+			continue
+		}
+		annotations = append(annotations, a)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.annotations = annotations
+	for _, a := range annotations {
+		if a.ID > s.nextID {
+			s.nextID = a.ID
+		}
+	}
+	return nil
+}
+
+// Add 添加注释，end 为零值时表示 start 这一时间点
+func (s *AnnotationStore) Add(ctx context.Context, start, end time.Time, text string, tags []string, profile string) (*Annotation, error) {
+	text = strings.TrimSpace(text)
+	switch {
+	case text == "":
+		return nil, fmt.Errorf("text 不能为空")
+	case len(text) > maxAnnotationTextLen:
+		return nil, fmt.Errorf("text 不能超过 %d 字节", maxAnnotationTextLen)
+	}
+	if end.IsZero() {
+		end = start
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end 不能早于 start")
+	}
+	var cleanTags []string
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			cleanTags = append(cleanTags, tag)
+		}
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	a := Annotation{
+		ID:        s.nextID,
+		Start:     start,
+		End:       end,
+		Text:      text,
+		Tags:      cleanTags,
+		Profile:   strings.TrimSpace(profile),
+		CreatedAt: time.Now(),
+	}
+	var dropped []int64
+	for s.max > 0 && len(s.annotations) >= s.max {
+		dropped = append(dropped, s.annotations[0].ID)
+		s.annotations = s.annotations[1:]
+	}
+	s.annotations = append(s.annotations, a)
+	s.mu.Unlock()
+
+	if s.storage != nil {
+		data, err := json.Marshal(a)
+		if err == nil {
+			err = s.storage.Put(ctx, annotationsBucket, annotationKey(a.ID), data)
+		}
+		if err != nil {
+			s.logger.Error("保存时间段注释失败", err.Error())
+		}
+		for _, id := range dropped {
+			if err := s.storage.Delete(ctx, annotationsBucket, annotationKey(id)); err != nil {
+				s.logger.Warn("删除旧时间段注释失败", err.Error())
+			}
+		}
+	}
+	GetDatabaseLogger().Info(fmt.Sprintf("已添加时间段注释 #%d: %s", a.ID, a.Text), a.describeRange())
+	return &a, nil
+}
+
+// Remove 删除注释
+func (s *AnnotationStore) Remove(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	index := -1
+	for i, a := range s.annotations {
+		if a.ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		s.mu.Unlock()
+		return ErrAnnotationNotFound
+	}
+	s.annotations = append(s.annotations[:index], s.annotations[index+1:]...)
+	s.mu.Unlock()
+
+	if s.storage != nil {
+		if err := s.storage.Delete(ctx, annotationsBucket, annotationKey(id)); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("delete annotation: %w", err)
+		}
+	}
+	return nil
+}
+
+// Between 获取与 from 至 to 重叠且作用于 profile 的注释，按开始时间升序
+// from、to 为零值时不限制对应一端，profile 为空时返回所有服务器的注释
+func (s *AnnotationStore) Between(from, to time.Time, profile string) []Annotation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := []Annotation{}
+	for _, a := range s.annotations {
+		if a.End.Before(from) || (!to.IsZero() && a.Start.After(to)) || !a.appliesTo(profile) {
+			continue
+		}
+		result = append(result, a)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Start.Before(result[j].Start) })
+	return result
+}
+
+// describeRange 注释时间的描述，时间点只显示一个时间
+func (a Annotation) describeRange() string {
+	if a.End.Equal(a.Start) {
+		return config.FormatDisplayTime(a.Start)
+	}
+	return config.FormatDisplayTime(a.Start) + " ~ " + config.FormatDisplayTime(a.End)
+}
+
+// annotationKey 注释的存储键，补零使键的顺序与编号一致
+func annotationKey(id int64) string {
+	return fmt.Sprintf("%012d", id)
+}
+
+// OverlayAnnotations 将注释编号标注到与之重叠的小时上，时间点注释标注到所在的小时
+func OverlayAnnotations(hours []TrendHour, annotations []Annotation) {
+	for i := range hours {
+		start, err := time.ParseInLocation(hourKeyFormat, hours[i].Hour, time.Local)
+		if err != nil {
+			continue
+		}
+		end := start.Add(time.Hour)
+		for _, a := range annotations {
+			if a.Start.Before(end) && (a.End.After(start) || !a.Start.Before(start)) {
+				hours[i].Annotations = append(hours[i].Annotations, a.ID)
+			}
+		}
+	}
+}
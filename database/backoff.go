@@ -0,0 +1,62 @@
+package database
+
+import (
+	"math/rand"
+	"time"
+)
+
+// jitterDelay 在 d 的基础上随机浮动 ±fraction，避免多个实例同时重连
+func jitterDelay(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return d
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	factor := 1 + fraction*(2*rand.Float64()-1)
+	return time.Duration(float64(d) * factor)
+}
+
+// retryBudget 时间窗口内的重连次数预算
+type retryBudget struct {
+	limit    int // 窗口内允许的尝试次数，为 0 时不限制
+	window   time.Duration
+	attempts []time.Time
+}
+
+// wait 返回预算恢复前还需等待的时长，预算充足时为 0
+func (b *retryBudget) wait(now time.Time) time.Duration {
+	if b.limit <= 0 {
+		return 0
+	}
+	cutoff := now.Add(-b.window)
+	i := 0
+	for i < len(b.attempts) && !b.attempts[i].After(cutoff) {
+		i++
+	}
+	b.attempts = b.attempts[i:]
+	if len(b.attempts) < b.limit {
+		return 0
+	}
+	return b.attempts[0].Sub(cutoff)
+}
+
+// record 记录一次尝试
+func (b *retryBudget) record(now time.Time) {
+	if b.limit > 0 {
+		b.attempts = append(b.attempts, now)
+	}
+}
+
+// retryable 根据错误分析器的分类判断错误能否通过重试解决
+// 认证错误和配置错误 (如数据库不存在) 重试无效
+func retryable(err error) bool {
+	if err == nil {
+		return true
+	}
+	switch GetErrorAnalyzer().matchErrorPattern(err.Error()).Type {
+	case ErrorTypeAuth, ErrorTypeConfig:
+		return false
+	}
+	return true
+}
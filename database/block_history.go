@@ -0,0 +1,395 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/storage"
+)
+
+// 阻塞事件的结束方式
+const (
+	ResolutionReleased       = "released"        // 持锁会话仍在，锁已释放 (提交、回滚或语句结束)
+	ResolutionBlockerGone    = "blocker_gone"    // 持锁会话已断开或被终止
+	ResolutionMonitorStopped = "monitor_stopped" // 阻塞未结束时服务停止
+	ResolutionUnknown        = "unknown"         // 无法确认持锁会话的状态
+)
+
+// blockEventsBucket 阻塞事件在持久化存储中的分组
+const blockEventsBucket = "block_events"
+
+// BlockSession 阻塞事件中的会话
+type BlockSession struct {
+	PID         int64  `json:"pid"`
+	User        string `json:"user,omitempty"`
+	Host        string `json:"host,omitempty"`
+	Query       string `json:"query,omitempty"`
+	WaitSeconds int64  `json:"wait_seconds,omitempty"` // 观察到的最长等待时间，仅等待者有
+	Isolation   string `json:"isolation,omitempty"`    // 事务隔离级别
+}
+
+// BlockEvent 一次阻塞：一个持锁会话阻塞了一个或多个会话
+type BlockEvent struct {
+	ID             int64          `json:"id"`
+	Start          time.Time      `json:"start"` // 按首次观察时最长的等待时间推算
+	End            time.Time      `json:"end"`
+	Duration       float64        `json:"duration_seconds"`
+	MaxWaitSeconds int64          `json:"max_wait_seconds"`
+	LockType       string         `json:"lock_type"`
+	Tables         []string       `json:"tables,omitempty"`
+	Blocker        BlockSession   `json:"blocker"`
+	Victims        []BlockSession `json:"victims"`
+	Resolution     string         `json:"resolution"`
+
+	captured bool // 是否已为该阻塞采集诊断包
+}
+
+// BlockScanner 定期扫描锁等待，把超过阈值的阻塞在结束后记为事件并持久化
+type BlockScanner struct {
+	mu        sync.Mutex
+	active    map[int64]*BlockEvent // 按持锁会话 ID 索引的进行中阻塞
+	events    []BlockEvent
+	nextID    int64
+	threshold time.Duration
+	maxEvents int
+	storage   storage.Storage // 为 nil 时只保存在内存中
+	store     Store
+	logger    *ComponentLogger
+	stop      chan struct{}
+}
+
+var (
+	blockScanner     *BlockScanner
+	blockScannerOnce sync.Once
+)
+
+// GetBlockScanner 获取阻塞扫描器实例，首次调用时从数据目录加载历史事件
+func GetBlockScanner() *BlockScanner {
+	blockScannerOnce.Do(func() {
+		cfg := config.GetBlockHistoryConfig()
+		blockScanner = &BlockScanner{
+			active:    make(map[int64]*BlockEvent),
+			threshold: cfg.Threshold,
+			maxEvents: cfg.MaxEvents,
+			store:     defaultStore,
+			logger:    GetDatabaseLogger().Component(ComponentScheduler),
+		}
+		if persistentStorage != nil {
+			blockScanner.storage = persistentStorage
+			if err := blockScanner.load(context.Background()); err != nil {
+				blockScanner.logger.Warn("加载阻塞事件历史失败", err.Error())
+			}
+		}
+	})
+	return blockScanner
+}
+
+// load 读取历史事件，超过保留数量时删除最早的事件
+func (bs *BlockScanner) load(ctx context.Context) error {
+	items, err := bs.storage.List(ctx, blockEventsBucket)
+	if err != nil {
+		return err
+	}
+	if bs.maxEvents > 0 && len(items) > bs.maxEvents {
+		for _, item := range items[:len(items)-bs.maxEvents] {
+			if err := bs.storage.Delete(ctx, blockEventsBucket, item.Key); err != nil {
+				return err
+			}
+		}
+		items = items[len(items)-bs.maxEvents:]
+	}
+
+	events := make([]BlockEvent, 0, len(items))
+	skipped := 0
+	for _, item := range items {
+		data, err := bs.storage.Get(ctx, blockEventsBucket, item.Key)
+		if err != nil {
+			return err
+		}
+		var e BlockEvent
+		if err := json.Unmarshal(data, &e); err != nil {
+			skipped++
+			continue
+		}
+		events = append(events, e)
+	}
+	if skipped > 0 {
+		bs.logger.Warn(fmt.Sprintf("阻塞事件历史中有 %d 条无法解析，已跳过", skipped))
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.events = events
+	for _, e := range events {
+		if e.ID > bs.nextID {
+			bs.nextID = e.ID
+		}
+	}
+	return nil
+}
+
+// Start 按指定间隔开始扫描，interval 为 0 时不启动
+func (bs *BlockScanner) Start(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	bs.mu.Lock()
+	if bs.stop != nil {
+		bs.mu.Unlock()
+		return
+	}
+	bs.stop = make(chan struct{})
+	stop := bs.stop
+	bs.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				bs.Scan(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop 停止扫描，仍在进行且已超过阈值的阻塞以 monitor_stopped 记录
+func (bs *BlockScanner) Stop() {
+	bs.mu.Lock()
+	if bs.stop != nil {
+		close(bs.stop)
+		bs.stop = nil
+	}
+	var pending []*BlockEvent
+	for pid, e := range bs.active {
+		delete(bs.active, pid)
+		pending = append(pending, e)
+	}
+	bs.mu.Unlock()
+
+	now := time.Now()
+	for _, e := range pending {
+		bs.finish(e, now, ResolutionMonitorStopped)
+	}
+}
+
+// Scan 执行一次扫描：更新进行中的阻塞，并结束本次未再出现的阻塞
+func (bs *BlockScanner) Scan(ctx context.Context) {
+	waits, err := bs.store.GetLockWaits(ctx)
+	if err != nil {
+		bs.logger.Debug("锁等待扫描失败", err.Error())
+		return
+	}
+	now := time.Now()
+
+	bs.mu.Lock()
+	seen := make(map[int64]bool)
+	for _, w := range waits {
+		seen[w.BlockingPID] = true
+		e, ok := bs.active[w.BlockingPID]
+		if !ok {
+			e = &BlockEvent{
+				Start:    now,
+				LockType: w.LockType,
+				Blocker:  BlockSession{PID: w.BlockingPID},
+				Victims:  []BlockSession{},
+			}
+			bs.active[w.BlockingPID] = e
+		}
+		if start := now.Add(-time.Duration(w.WaitSeconds) * time.Second); start.Before(e.Start) {
+			e.Start = start
+		}
+		if e.LockType != w.LockType {
+			e.LockType = "mixed"
+		}
+		e.Blocker.User, e.Blocker.Host = w.BlockingUser, w.BlockingHost
+		if w.BlockingIsolation != "" {
+			e.Blocker.Isolation = w.BlockingIsolation
+		}
+		if w.BlockingQuery != "" {
+			// 持锁会话在事务中空闲时没有语句，保留最后看到的语句
+			e.Blocker.Query = w.BlockingQuery
+		}
+		if w.Table != "" && !containsString(e.Tables, w.Table) {
+			e.Tables = append(e.Tables, w.Table)
+		}
+		if w.WaitSeconds > e.MaxWaitSeconds {
+			e.MaxWaitSeconds = w.WaitSeconds
+		}
+		e.addVictim(BlockSession{PID: w.WaitingPID, Query: w.WaitingQuery, WaitSeconds: w.WaitSeconds, Isolation: w.WaitingIsolation})
+	}
+
+	// 阻塞仍在进行时采集诊断包，结束后现场就没有了
+	var capture []string
+	diagThreshold := GetDiagnostics().cfg.BlockThreshold
+	for _, e := range bs.active {
+		if !e.captured && time.Duration(e.MaxWaitSeconds)*time.Second >= diagThreshold {
+			e.captured = true
+			capture = append(capture, fmt.Sprintf("会话 %d 阻塞了 %d 个会话，最长等待 %d 秒", e.Blocker.PID, len(e.Victims), e.MaxWaitSeconds))
+		}
+	}
+
+	var resolved []*BlockEvent
+	for pid, e := range bs.active {
+		if !seen[pid] {
+			delete(bs.active, pid)
+			resolved = append(resolved, e)
+		}
+	}
+	bs.mu.Unlock()
+
+	for _, message := range capture {
+		GetDiagnostics().Trigger(BundleReasonBlocking, message)
+	}
+	if len(resolved) == 0 {
+		return
+	}
+	alive, err := bs.livePIDs(ctx)
+	for _, e := range resolved {
+		resolution := ResolutionUnknown
+		if err == nil {
+			resolution = ResolutionBlockerGone
+			if alive[e.Blocker.PID] {
+				resolution = ResolutionReleased
+			}
+		}
+		bs.finish(e, now, resolution)
+	}
+}
+
+// livePIDs 获取当前存在的会话 ID
+func (bs *BlockScanner) livePIDs(ctx context.Context) (map[int64]bool, error) {
+	processes, err := bs.store.GetProcessList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alive := make(map[int64]bool, len(processes))
+	for _, p := range processes {
+		alive[p.ID] = true
+	}
+	return alive, nil
+}
+
+// addVictim 记录被阻塞的会话，同一会话保留最长的等待时间
+func (e *BlockEvent) addVictim(v BlockSession) {
+	for i := range e.Victims {
+		if e.Victims[i].PID == v.PID {
+			if v.WaitSeconds > e.Victims[i].WaitSeconds {
+				e.Victims[i].WaitSeconds = v.WaitSeconds
+			}
+			if v.Query != "" {
+				e.Victims[i].Query = v.Query
+			}
+			if v.Isolation != "" {
+				e.Victims[i].Isolation = v.Isolation
+			}
+			return
+		}
+	}
+	e.Victims = append(e.Victims, v)
+}
+
+// finish 结束阻塞，最长等待未达到阈值的阻塞不记录
+func (bs *BlockScanner) finish(e *BlockEvent, end time.Time, resolution string) {
+	if time.Duration(e.MaxWaitSeconds)*time.Second < bs.threshold {
+		return
+	}
+	e.End = end
+	e.Duration = end.Sub(e.Start).Round(time.Second).Seconds()
+	e.Resolution = resolution
+
+	var dropped int64
+	bs.mu.Lock()
+	bs.nextID++
+	e.ID = bs.nextID
+	if bs.maxEvents > 0 && len(bs.events) >= bs.maxEvents {
+		dropped = bs.events[0].ID
+		bs.events = bs.events[1:]
+	}
+	bs.events = append(bs.events, *e)
+	bs.mu.Unlock()
+
+	bs.logger.Warn(fmt.Sprintf("会话 %d 阻塞了 %d 个会话，持续 %.0f 秒 (%s)", e.Blocker.PID, len(e.Victims), e.Duration, resolution), e.Blocker.Query)
+	if bs.storage == nil {
+		return
+	}
+	ctx := context.Background()
+	data, err := json.Marshal(e)
+	if err == nil {
+		err = bs.storage.Put(ctx, blockEventsBucket, blockEventKey(e.ID), data)
+	}
+	if err != nil {
+		bs.logger.Error("保存阻塞事件失败", err.Error())
+	}
+	if dropped > 0 {
+		if err := bs.storage.Delete(ctx, blockEventsBucket, blockEventKey(dropped)); err != nil {
+			bs.logger.Warn("删除旧阻塞事件失败", err.Error())
+		}
+	}
+}
+
+// blockEventKey 阻塞事件的存储键，补零使键的顺序与编号一致
+func blockEventKey(id int64) string {
+	return fmt.Sprintf("%012d", id)
+}
+
+// Events 获取 since 之后开始的历史事件，按开始时间倒序，limit 为 0 时不限制
+func (bs *BlockScanner) Events(since time.Time, limit int) []BlockEvent {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	events := []BlockEvent{}
+	for i := len(bs.events) - 1; i >= 0; i-- {
+		if bs.events[i].Start.Before(since) {
+			continue
+		}
+		events = append(events, bs.events[i])
+		if limit > 0 && len(events) >= limit {
+			break
+		}
+	}
+	return events
+}
+
+// Active 获取进行中的阻塞，按开始时间排序
+func (bs *BlockScanner) Active() []BlockEvent {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	active := make([]BlockEvent, 0, len(bs.active))
+	for _, e := range bs.active {
+		copied := *e
+		copied.Victims = append([]BlockSession{}, e.Victims...)
+		copied.Tables = append([]string(nil), e.Tables...)
+		active = append(active, copied)
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Start.Before(active[j].Start) })
+	return active
+}
+
+// containsString 判断切片中是否包含字符串
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// MixedIsolation 持锁会话与被阻塞会话的隔离级别是否不同，隔离级别未知的会话不参与比较
+func (e BlockEvent) MixedIsolation() bool {
+	levels := make(map[string]bool)
+	for _, s := range append([]BlockSession{e.Blocker}, e.Victims...) {
+		if s.Isolation != "" {
+			levels[s.Isolation] = true
+		}
+	}
+	return len(levels) > 1
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/checks"
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// canaryStaleAge 探测表中超过该时长仍未删除的行 (上次探测中途失败遗留) 在下次探测时清理
+const canaryStaleAge = time.Hour
+
+// Canary 写入探测：每个间隔在专用表中插入一行、按主键读回并删除，测量写入耗时并确认能读到刚写入的数据
+// 用于发现 Ping 正常但写入挂起 (如磁盘写满、锁等待、半同步复制卡住) 的故障
+type Canary struct {
+	mu      sync.RWMutex
+	config  *config.CanaryConfig
+	created bool           // 探测表已确认存在
+	last    *checks.Result // 最近一次探测结果
+	logger  *ComponentLogger
+}
+
+var (
+	canary     *Canary
+	canaryOnce sync.Once
+)
+
+// GetCanary 获取写入探测实例
+func GetCanary() *Canary {
+	canaryOnce.Do(func() {
+		canary = &Canary{
+			config: config.GetCanaryConfig(),
+			logger: GetDatabaseLogger().Component(ComponentScheduler),
+		}
+	})
+	return canary
+}
+
+// Name 检查名称
+func (c *Canary) Name() string {
+	return "canary"
+}
+
+// Interval 探测间隔
+func (c *Canary) Interval() time.Duration {
+	return c.config.Interval
+}
+
+// Config 写入探测配置
+func (c *Canary) Config() *config.CanaryConfig {
+	return c.config
+}
+
+// LastResult 最近一次探测结果，尚未探测时返回 nil
+func (c *Canary) LastResult() *checks.Result {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.last == nil {
+		return nil
+	}
+	result := *c.last
+	return &result
+}
+
+// Run 执行一次写入探测，写入、读回或删除失败以及读到的数据不一致时为 critical，写入耗时超过 CANARY_WARN_LATENCY 时为 warning
+func (c *Canary) Run(ctx context.Context) checks.Result {
+	result := c.run(ctx)
+	result.Check = c.Name()
+	result.Time = time.Now()
+
+	c.mu.Lock()
+	previous := c.last
+	c.last = &result
+	c.mu.Unlock()
+
+	if previous == nil || previous.Status != result.Status {
+		switch result.Status {
+		case checks.StatusCritical:
+			c.logger.Error("写入探测失败", result.Message)
+		case checks.StatusWarning:
+			c.logger.Warn("写入探测耗时过长", result.Message)
+		case checks.StatusOK:
+			if previous != nil {
+				c.logger.Info("写入探测已恢复", result.Message)
+			}
+		}
+	}
+	return result
+}
+
+// run 执行插入、读回和删除，返回未补全名称和时间的结果
+func (c *Canary) run(ctx context.Context) checks.Result {
+	if config.GetDBConfig().ReadOnly {
+		return checks.Result{Status: checks.StatusUnknown, Message: "READ_ONLY 模式下不执行写入探测"}
+	}
+	if config.GetDBConfig().Driver == config.DriverDemo {
+		return checks.Result{Status: checks.StatusUnknown, Message: "演示模式下不执行写入探测"}
+	}
+	db := GetDB()
+	if db == nil {
+		return checks.Result{Status: checks.StatusCritical, Message: "数据库未连接"}
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	table := QuoteTable(c.config.Database, c.config.Table)
+	if err := c.ensureTable(ctx, db, table); err != nil {
+		return canaryFailure("create table", err, nil)
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return canaryFailure("generate token", err, nil)
+	}
+	token := hex.EncodeToString(buf)
+	details := map[string]interface{}{"table": c.config.Database + "." + c.config.Table}
+
+	start := time.Now()
+	res, err := db.ExecContext(ctx, "INSERT INTO "+table+" (token, created_at) VALUES (?, ?)", token, start)
+	writeLatency := time.Since(start)
+	details["write_ms"] = float64(writeLatency.Microseconds()) / 1000
+	if err != nil {
+		// 故障转移后新的主库上可能没有探测表，下次探测时重新确认
+		c.mu.Lock()
+		c.created = false
+		c.mu.Unlock()
+		return canaryFailure("insert", err, details)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return canaryFailure("insert", err, details)
+	}
+
+	// 读回刚写入的行，连接池中的其他连接或经过代理转发到从库时可能读不到
+	start = time.Now()
+	var readBack string
+	err = db.QueryRowContext(ctx, "SELECT token FROM "+table+" WHERE id = ?", id).Scan(&readBack)
+	readLatency := time.Since(start)
+	details["read_ms"] = float64(readLatency.Microseconds()) / 1000
+	if errors.Is(err, sql.ErrNoRows) {
+		c.deleteRow(ctx, db, table, id)
+		return checks.Result{Status: checks.StatusCritical, Message: "写入成功但读不到刚写入的行", Details: details}
+	}
+	if err != nil {
+		c.deleteRow(ctx, db, table, id)
+		return canaryFailure("select", err, details)
+	}
+	if readBack != token {
+		c.deleteRow(ctx, db, table, id)
+		return checks.Result{Status: checks.StatusCritical, Message: "读回的数据与写入的不一致", Details: details}
+	}
+
+	start = time.Now()
+	_, err = db.ExecContext(ctx, "DELETE FROM "+table+" WHERE id = ? OR created_at < ?", id, time.Now().Add(-canaryStaleAge))
+	deleteLatency := time.Since(start)
+	details["delete_ms"] = float64(deleteLatency.Microseconds()) / 1000
+	if err != nil {
+		return canaryFailure("delete", err, details)
+	}
+
+	if c.config.WarnLatency > 0 && writeLatency > c.config.WarnLatency {
+		return checks.Result{Status: checks.StatusWarning, Message: fmt.Sprintf("写入耗时 %v 超过 %v", writeLatency.Round(time.Millisecond), c.config.WarnLatency), Details: details}
+	}
+	return checks.Result{Status: checks.StatusOK, Details: details, Message: fmt.Sprintf("写入 %v，读回 %v，删除 %v",
+		writeLatency.Round(time.Microsecond), readLatency.Round(time.Microsecond), deleteLatency.Round(time.Microsecond))}
+}
+
+// ensureTable 探测表不存在时创建，确认一次后不再检查
+func (c *Canary) ensureTable(ctx context.Context, db *sql.DB, table string) error {
+	c.mu.RLock()
+	created := c.created
+	c.mu.RUnlock()
+	if created {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+table+` (
+	id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	token CHAR(32) NOT NULL,
+	created_at DATETIME(6) NOT NULL,
+	KEY idx_created_at (created_at)
+) COMMENT 'block-checker write canary'`)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.created = true
+	c.mu.Unlock()
+	return nil
+}
+
+// deleteRow 读回失败时尽量删除已写入的行，失败的行由后续探测按 canaryStaleAge 清理
+func (c *Canary) deleteRow(ctx context.Context, db *sql.DB, table string, id int64) {
+	if _, err := db.ExecContext(ctx, "DELETE FROM "+table+" WHERE id = ?", id); err != nil {
+		c.logger.Debug("删除写入探测行失败", err.Error())
+	}
+}
+
+// canaryFailure 探测某一步失败的结果，超时时说明写入可能挂起
+func canaryFailure(step string, err error, details map[string]interface{}) checks.Result {
+	result := checks.Result{Status: checks.StatusCritical, Message: fmt.Sprintf("%s: %v", step, err), Details: details}
+	if IsTimeout(err) {
+		result.Message = fmt.Sprintf("%s: 超时未完成，写入可能挂起", step)
+	}
+	return result
+}
@@ -0,0 +1,225 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCaptureRunning 已有采集正在进行
+var ErrCaptureRunning = errors.New("capture already running")
+
+// MaxCaptureDuration 单次采集的最长时长
+const MaxCaptureDuration = time.Minute
+
+// captureTopN 报告中保留的等待事件和阶段事件数
+const captureTopN = 20
+
+// captureMu 同一时间只允许一次采集
+var captureMu sync.Mutex
+
+// WaitCapture 一段时间内的等待事件和阶段事件统计
+type WaitCapture struct {
+	StartedAt  time.Time      `json:"started_at"`
+	Seconds    float64        `json:"seconds"`
+	TotalMs    float64        `json:"total_wait_ms"` // 不含 idle 的等待总时长
+	Categories []WaitCategory `json:"categories"`
+	Waits      []WaitEvent    `json:"top_waits"`
+	Stages     []WaitEvent    `json:"top_stages"`
+}
+
+// WaitCategory 按类别汇总的等待
+type WaitCategory struct {
+	Category string  `json:"category"`
+	Count    int64   `json:"count"`
+	TotalMs  float64 `json:"total_ms"`
+	Percent  float64 `json:"percent"`
+}
+
+// WaitEvent 单个事件在采集期间的增量
+type WaitEvent struct {
+	Event    string  `json:"event"`
+	Category string  `json:"category"`
+	Count    int64   `json:"count"`
+	TotalMs  float64 `json:"total_ms"`
+	AvgMs    float64 `json:"avg_ms"`
+	Percent  float64 `json:"percent"`
+}
+
+// eventCounter performance_schema 汇总表中的累计值
+type eventCounter struct {
+	count int64
+	timer int64 // 皮秒
+}
+
+// CaptureWaits 采集一段时间内的等待事件
+func CaptureWaits(ctx context.Context, duration time.Duration) (*WaitCapture, error) {
+	return defaultStore.CaptureWaits(ctx, duration)
+}
+
+// CaptureWaits 在采集开始和结束时读取 performance_schema 的全局等待和阶段汇总，按差值给出耗时最多的事件
+func (s *MySQLStore) CaptureWaits(ctx context.Context, duration time.Duration) (*WaitCapture, error) {
+	if duration <= 0 || duration > MaxCaptureDuration {
+		return nil, fmt.Errorf("capture duration must be between 0 and %v", MaxCaptureDuration)
+	}
+	if !captureMu.TryLock() {
+		return nil, ErrCaptureRunning
+	}
+	defer captureMu.Unlock()
+
+	db := GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	queryCtx, cancel := s.withQueryTimeout(ctx)
+	var enabled int
+	err := db.QueryRowContext(queryCtx, "SELECT @@performance_schema").Scan(&enabled)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("check performance_schema: %w", err)
+	}
+	if enabled == 0 {
+		return nil, fmt.Errorf("performance_schema is disabled")
+	}
+
+	startWaits, startStages, err := s.readEventSummaries(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	started := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(duration):
+	}
+
+	endWaits, endStages, err := s.readEventSummaries(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	capture := &WaitCapture{StartedAt: started, Seconds: time.Since(started).Seconds()}
+	var byCategory map[string]*WaitCategory
+	capture.Waits, byCategory, capture.TotalMs = diffEvents(startWaits, endWaits, waitCategory)
+	capture.Stages, _, _ = diffEvents(startStages, endStages, func(string) string { return "stage" })
+
+	capture.Categories = []WaitCategory{}
+	for _, c := range byCategory {
+		if capture.TotalMs > 0 {
+			c.Percent = round2(100 * c.TotalMs / capture.TotalMs)
+		}
+		capture.Categories = append(capture.Categories, *c)
+	}
+	sort.Slice(capture.Categories, func(i, j int) bool {
+		return capture.Categories[i].TotalMs > capture.Categories[j].TotalMs
+	})
+	return capture, nil
+}
+
+// readEventSummaries 读取等待和阶段事件的全局累计值，阶段事件表不可用时返回空
+func (s *MySQLStore) readEventSummaries(ctx context.Context, db *sql.DB) (map[string]eventCounter, map[string]eventCounter, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	waits, err := readEventCounters(ctx, db, "performance_schema.events_waits_summary_global_by_event_name")
+	if err != nil {
+		return nil, nil, fmt.Errorf("query wait events: %w", err)
+	}
+	stages, err := readEventCounters(ctx, db, "performance_schema.events_stages_summary_global_by_event_name")
+	if err != nil {
+		stages = map[string]eventCounter{}
+	}
+	return waits, stages, nil
+}
+
+// readEventCounters 读取汇总表中 COUNT_STAR 不为 0 的事件
+func readEventCounters(ctx context.Context, db *sql.DB, table string) (map[string]eventCounter, error) {
+	rows, err := db.QueryContext(ctx, "SELECT EVENT_NAME, COUNT_STAR, SUM_TIMER_WAIT FROM "+table+" WHERE COUNT_STAR > 0")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counters := make(map[string]eventCounter)
+	for rows.Next() {
+		var name string
+		var c eventCounter
+		if err := rows.Scan(&name, &c.count, &c.timer); err != nil {
+			return nil, err
+		}
+		counters[name] = c
+	}
+	return counters, rows.Err()
+}
+
+// diffEvents 计算两次读取之间的增量，返回耗时最多的事件、按类别的汇总和总耗时 (毫秒)
+func diffEvents(start, end map[string]eventCounter, category func(string) string) ([]WaitEvent, map[string]*WaitCategory, float64) {
+	events := []WaitEvent{}
+	categories := make(map[string]*WaitCategory)
+	var totalMs float64
+
+	for name, e := range end {
+		cat := category(name)
+		if cat == "" {
+			continue
+		}
+		s := start[name]
+		count, timer := e.count-s.count, e.timer-s.timer
+		if count <= 0 || timer < 0 {
+			continue // 计数器在采集期间被重置 (TRUNCATE) 时忽略
+		}
+		ms := float64(timer) / 1e9
+		events = append(events, WaitEvent{Event: name, Category: cat, Count: count, TotalMs: round2(ms), AvgMs: round2(ms / float64(count))})
+		if categories[cat] == nil {
+			categories[cat] = &WaitCategory{Category: cat}
+		}
+		categories[cat].Count += count
+		categories[cat].TotalMs += ms
+		totalMs += ms
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].TotalMs > events[j].TotalMs })
+	if len(events) > captureTopN {
+		events = events[:captureTopN]
+	}
+	for i := range events {
+		if totalMs > 0 {
+			events[i].Percent = round2(100 * events[i].TotalMs / totalMs)
+		}
+	}
+	for _, c := range categories {
+		c.TotalMs = round2(c.TotalMs)
+	}
+	return events, categories, round2(totalMs)
+}
+
+// waitCategory 按事件名前缀归类，idle 事件返回空字符串以便忽略
+func waitCategory(event string) string {
+	switch {
+	case strings.HasPrefix(event, "idle"):
+		return ""
+	case strings.HasPrefix(event, "wait/io/socket/"):
+		return "network"
+	case strings.HasPrefix(event, "wait/io/file/"):
+		return "io"
+	case strings.HasPrefix(event, "wait/io/table/"):
+		return "table_io"
+	case strings.HasPrefix(event, "wait/lock/"):
+		return "lock"
+	case strings.HasPrefix(event, "wait/synch/"):
+		return "synch"
+	}
+	return "other"
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return float64(int64(v*100+0.5)) / 100
+}
@@ -0,0 +1,258 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// 模拟故障类型
+const (
+	ChaosDrop    = "drop"    // 连接中断：新建连接、Ping 和语句都失败，直到故障结束
+	ChaosLatency = "latency" // 每条语句执行前增加延迟
+	ChaosError   = "error"   // 语句返回指定错误分类的错误
+)
+
+// ErrChaosDisabled 未开启故障模拟
+var ErrChaosDisabled = errors.New("chaos mode is disabled")
+
+// ChaosFault 一个正在生效的模拟故障
+type ChaosFault struct {
+	Kind      string    `json:"kind"`
+	LatencyMs int64     `json:"latency_ms,omitempty"` // latency 故障增加的延迟
+	ErrorCode string    `json:"error_code,omitempty"` // error 故障模拟的错误分类代码，如 NET_003
+	Message   string    `json:"message,omitempty"`    // drop 和 error 故障返回的错误消息
+	Rate      float64   `json:"rate"`                 // 受影响的语句比例，drop 故障始终为 1
+	Started   time.Time `json:"started"`
+	Expires   time.Time `json:"expires"`
+}
+
+// Chaos 故障模拟 (开发者模式)：在默认连接的驱动层注入连接中断、查询延迟和指定分类的错误
+// 用于在不破坏真实数据库的情况下验证仪表盘、告警和重连流程，每种故障同时只有一个，到期后自动恢复
+// PROFILES 中的服务器不受影响
+type Chaos struct {
+	mu     sync.Mutex
+	config *config.ChaosConfig
+	faults map[string]*ChaosFault
+	logger *ComponentLogger
+}
+
+var (
+	chaos     *Chaos
+	chaosOnce sync.Once
+)
+
+// GetChaos 获取故障模拟实例
+func GetChaos() *Chaos {
+	chaosOnce.Do(func() {
+		chaos = &Chaos{
+			config: config.GetChaosConfig(),
+			faults: make(map[string]*ChaosFault),
+			logger: GetDatabaseLogger().Component(ComponentReconnector),
+		}
+	})
+	return chaos
+}
+
+// Enabled 是否开启了故障模拟
+func (c *Chaos) Enabled() bool {
+	return c.config.Enabled
+}
+
+// ChaosErrorCodes 可以模拟的错误分类代码
+func ChaosErrorCodes() []string {
+	ea := GetErrorAnalyzer()
+	ea.mu.RLock()
+	defer ea.mu.RUnlock()
+	codes := make([]string, 0, len(ea.patterns))
+	for _, pattern := range ea.patterns {
+		codes = append(codes, pattern.Code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// chaosErrorMessage 能被错误分析器归入指定分类的错误消息
+func chaosErrorMessage(code string) (string, bool) {
+	ea := GetErrorAnalyzer()
+	ea.mu.RLock()
+	defer ea.mu.RUnlock()
+	for _, pattern := range ea.patterns {
+		if strings.EqualFold(pattern.Code, code) && len(pattern.Keywords) > 0 {
+			return "chaos: simulated " + pattern.Keywords[0], true
+		}
+	}
+	return "", false
+}
+
+// Inject 注入模拟故障，同类故障已存在时替换；duration 超过 CHAOS_MAX_DURATION 时报错
+// latency 仅用于 latency 故障，errorCode 仅用于 error 故障，rate 为 0 时影响所有语句
+func (c *Chaos) Inject(ctx context.Context, kind string, duration, latency time.Duration, errorCode string, rate float64) (*ChaosFault, error) {
+	if !c.config.Enabled {
+		return nil, ErrChaosDisabled
+	}
+	if duration <= 0 || duration > c.config.MaxDuration {
+		return nil, fmt.Errorf("duration 必须在 0 到 %v 之间", c.config.MaxDuration)
+	}
+	if rate < 0 || rate > 1 {
+		return nil, fmt.Errorf("rate 必须在 0 到 1 之间")
+	}
+	if rate == 0 {
+		rate = 1
+	}
+
+	now := time.Now()
+	fault := &ChaosFault{Kind: kind, Rate: rate, Started: now, Expires: now.Add(duration)}
+	switch kind {
+	case ChaosDrop:
+		fault.Rate = 1
+		fault.Message = "chaos: simulated connection refused"
+	case ChaosLatency:
+		if latency <= 0 || latency > time.Minute {
+			return nil, fmt.Errorf("latency 必须在 0 到 1m 之间")
+		}
+		fault.LatencyMs = latency.Milliseconds()
+	case ChaosError:
+		message, ok := chaosErrorMessage(errorCode)
+		if !ok {
+			return nil, fmt.Errorf("未知的错误分类 %q，可选 %s", errorCode, strings.Join(ChaosErrorCodes(), "/"))
+		}
+		fault.ErrorCode = strings.ToUpper(errorCode)
+		fault.Message = message
+	default:
+		return nil, fmt.Errorf("未知的故障类型 %q，可选 drop/latency/error", kind)
+	}
+
+	c.mu.Lock()
+	c.faults[kind] = fault
+	c.mu.Unlock()
+
+	result := *fault
+	c.logger.Warn(fmt.Sprintf("已注入模拟故障: %s", result.describe()), fmt.Sprintf("持续到 %s", config.FormatDisplayTime(result.Expires)))
+	if _, err := GetAnnotations().Add(ctx, result.Started, result.Expires, "模拟故障: "+result.describe(), []string{"chaos"}, DefaultProfile); err != nil {
+		c.logger.Debug("添加模拟故障注释失败", err.Error())
+	}
+	return &result, nil
+}
+
+// Clear 结束模拟故障，kind 为空时结束所有故障，返回结束的故障数
+func (c *Chaos) Clear(kind string) int {
+	c.mu.Lock()
+	var cleared []string
+	for k := range c.faults {
+		if kind == "" || k == kind {
+			cleared = append(cleared, k)
+			delete(c.faults, k)
+		}
+	}
+	c.mu.Unlock()
+
+	sort.Strings(cleared)
+	for _, k := range cleared {
+		c.logger.Info(fmt.Sprintf("已结束模拟故障: %s", k))
+	}
+	return len(cleared)
+}
+
+// Active 正在生效的模拟故障，按类型排序
+func (c *Chaos) Active() []ChaosFault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expireLocked(time.Now())
+	faults := make([]ChaosFault, 0, len(c.faults))
+	for _, fault := range c.faults {
+		faults = append(faults, *fault)
+	}
+	sort.Slice(faults, func(i, j int) bool { return faults[i].Kind < faults[j].Kind })
+	return faults
+}
+
+// expireLocked 删除已到期的故障，调用方需持有 c.mu
+func (c *Chaos) expireLocked(now time.Time) {
+	for kind, fault := range c.faults {
+		if !now.Before(fault.Expires) {
+			delete(c.faults, kind)
+			c.logger.Info(fmt.Sprintf("模拟故障已到期: %s", kind))
+		}
+	}
+}
+
+// fault 取出正在生效的指定类型故障，未开启故障模拟时直接返回
+func (c *Chaos) fault(kind string) (ChaosFault, bool) {
+	if !c.config.Enabled {
+		return ChaosFault{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.faults) == 0 {
+		return ChaosFault{}, false
+	}
+	c.expireLocked(time.Now())
+	fault, ok := c.faults[kind]
+	if !ok || (fault.Rate < 1 && rand.Float64() >= fault.Rate) {
+		return ChaosFault{}, false
+	}
+	return *fault, true
+}
+
+// beforeConnect 新建连接前注入连接中断
+func (c *Chaos) beforeConnect() error {
+	if fault, ok := c.fault(ChaosDrop); ok {
+		return errors.New(fault.Message)
+	}
+	return nil
+}
+
+// beforeStatement 执行语句前注入故障
+// 连接中断时返回 driver.ErrBadConn，连接池丢弃该连接后新建连接同样失败
+func (c *Chaos) beforeStatement(ctx context.Context) error {
+	if _, ok := c.fault(ChaosDrop); ok {
+		return driver.ErrBadConn
+	}
+	if fault, ok := c.fault(ChaosLatency); ok {
+		timer := time.NewTimer(time.Duration(fault.LatencyMs) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if fault, ok := c.fault(ChaosError); ok {
+		return errors.New(fault.Message)
+	}
+	return nil
+}
+
+// beforePing Ping 前注入故障，连接中断时直接返回连接被拒绝 (Ping 不会因 driver.ErrBadConn 换用新连接重试)
+func (c *Chaos) beforePing(ctx context.Context) error {
+	if fault, ok := c.fault(ChaosDrop); ok {
+		return errors.New(fault.Message)
+	}
+	return c.beforeStatement(ctx)
+}
+
+// describe 故障的简短描述
+func (f ChaosFault) describe() string {
+	var desc string
+	switch f.Kind {
+	case ChaosLatency:
+		desc = fmt.Sprintf("查询延迟 %dms", f.LatencyMs)
+	case ChaosError:
+		desc = fmt.Sprintf("错误 %s", f.ErrorCode)
+	default:
+		desc = "连接中断"
+	}
+	if f.Rate < 1 {
+		desc += fmt.Sprintf(" (%.0f%% 的语句)", f.Rate*100)
+	}
+	return desc
+}
@@ -0,0 +1,453 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/sqltools"
+)
+
+// ErrChecksumRunning 已有校验正在进行
+var ErrChecksumRunning = errors.New("checksum already running")
+
+// maxLoadWait 主库负载持续过高时单个分块最多等待的时长
+const maxLoadWait = 5 * time.Minute
+
+// ChunkMismatch 主从数据不一致的分块
+type ChunkMismatch struct {
+	Lower           string `json:"lower,omitempty"` // 分块主键下界 (不含)，为空表示从表头开始
+	Upper           string `json:"upper,omitempty"` // 分块主键上界 (含)，为空表示到表末尾
+	SourceRows      int64  `json:"source_rows"`
+	ReplicaRows     int64  `json:"replica_rows"`
+	SourceChecksum  uint64 `json:"source_checksum"`
+	ReplicaChecksum uint64 `json:"replica_checksum"`
+}
+
+// TableChecksum 单个表的校验结果
+type TableChecksum struct {
+	Table      string          `json:"table"`
+	Chunks     int             `json:"chunks"`
+	Rows       int64           `json:"rows"`
+	Mismatches []ChunkMismatch `json:"mismatches,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Throttled  float64         `json:"throttled_seconds"` // 因主库负载过高暂停的时长
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+}
+
+// ChecksumRun 一次主从数据校验
+type ChecksumRun struct {
+	Source     string          `json:"source"`
+	Replica    string          `json:"replica"`
+	Tables     []TableChecksum `json:"tables"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at,omitempty"`
+}
+
+// Mismatches 不一致的分块总数
+func (r ChecksumRun) Mismatches() int {
+	n := 0
+	for _, t := range r.Tables {
+		n += len(t.Mismatches)
+	}
+	return n
+}
+
+// ChecksumMonitor 定期按主键分块计算主从库中指定表的校验和，发现不一致时记录警告
+// 每个分块计算 BIT_XOR(CRC32(整行)) 和行数，分块之间暂停并在主库活跃线程过多时等待，避免校验本身造成压力
+type ChecksumMonitor struct {
+	mu       sync.RWMutex
+	cfg      *config.ChecksumConfig
+	registry *ProfileRegistry
+	running  *ChecksumRun // 进行中的校验，已完成的表会追加到 Tables
+	last     *ChecksumRun
+	cancel   context.CancelFunc
+	stop     chan struct{}
+	logger   *ComponentLogger
+}
+
+var (
+	checksumMonitor     *ChecksumMonitor
+	checksumMonitorOnce sync.Once
+)
+
+// GetChecksumMonitor 获取主从数据校验器实例
+func GetChecksumMonitor() *ChecksumMonitor {
+	checksumMonitorOnce.Do(func() {
+		checksumMonitor = &ChecksumMonitor{
+			cfg:      config.GetChecksumConfig(),
+			registry: GetProfileRegistry(),
+			logger:   GetDatabaseLogger().Component(ComponentScheduler),
+		}
+	})
+	return checksumMonitor
+}
+
+// Enabled 是否已配置要校验的表和从库
+func (cm *ChecksumMonitor) Enabled() bool {
+	return cm.cfg.Enabled()
+}
+
+// Start 按指定间隔开始校验，interval 为 0 或未配置时不启动
+func (cm *ChecksumMonitor) Start(interval time.Duration) {
+	if interval <= 0 || !cm.Enabled() {
+		return
+	}
+	cm.mu.Lock()
+	if cm.stop != nil {
+		cm.mu.Unlock()
+		return
+	}
+	cm.stop = make(chan struct{})
+	stop := cm.stop
+	cm.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if _, err := cm.Run(context.Background()); err != nil && !errors.Is(err, ErrChecksumRunning) {
+					cm.logger.Warn("主从数据校验失败", err.Error())
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止定期校验并中止进行中的校验
+func (cm *ChecksumMonitor) Stop() {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if cm.stop != nil {
+		close(cm.stop)
+		cm.stop = nil
+	}
+	if cm.cancel != nil {
+		cm.cancel()
+	}
+}
+
+// Run 立即校验所有配置的表，已有校验进行时返回 ErrChecksumRunning
+func (cm *ChecksumMonitor) Run(ctx context.Context) (ChecksumRun, error) {
+	if !cm.Enabled() {
+		return ChecksumRun{}, fmt.Errorf("checksum not configured: set CHECKSUM_TABLES and CHECKSUM_REPLICA")
+	}
+	source, ok := cm.registry.DB(cm.cfg.Source)
+	if !ok {
+		return ChecksumRun{}, fmt.Errorf("source profile %q not found", cm.cfg.Source)
+	}
+	replica, ok := cm.registry.DB(cm.cfg.Replica)
+	if !ok {
+		return ChecksumRun{}, fmt.Errorf("replica profile %q not found", cm.cfg.Replica)
+	}
+	if source == nil || replica == nil {
+		return ChecksumRun{}, fmt.Errorf("source or replica not connected")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cm.mu.Lock()
+	if cm.running != nil {
+		cm.mu.Unlock()
+		return ChecksumRun{}, ErrChecksumRunning
+	}
+	run := &ChecksumRun{Source: cm.cfg.Source, Replica: cm.cfg.Replica, StartedAt: time.Now()}
+	cm.running = run
+	cm.cancel = cancel
+	cm.mu.Unlock()
+
+	for _, table := range cm.cfg.Tables {
+		if ctx.Err() != nil {
+			break
+		}
+		result := cm.checksumTable(ctx, source, replica, table)
+		if result.Error != "" {
+			cm.logger.Warn(fmt.Sprintf("表 %s 校验失败", table), result.Error)
+		} else if len(result.Mismatches) > 0 {
+			cm.logger.Warn(fmt.Sprintf("表 %s 主从数据不一致: %d/%d 个分块", table, len(result.Mismatches), result.Chunks),
+				fmt.Sprintf("%s → %s", cm.cfg.Source, cm.cfg.Replica))
+		}
+		cm.mu.Lock()
+		run.Tables = append(run.Tables, result)
+		cm.mu.Unlock()
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	run.FinishedAt = time.Now()
+	cm.running = nil
+	cm.cancel = nil
+	if err := ctx.Err(); err != nil {
+		return *run, fmt.Errorf("checksum interrupted: %w", err)
+	}
+	cm.last = run
+	return *run, nil
+}
+
+// Last 获取最近一次完成的校验，尚未完成过时返回 false
+func (cm *ChecksumMonitor) Last() (ChecksumRun, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	if cm.last == nil {
+		return ChecksumRun{}, false
+	}
+	return *cm.last, true
+}
+
+// Running 获取进行中的校验 (只含已完成的表)，没有进行中的校验时返回 false
+func (cm *ChecksumMonitor) Running() (ChecksumRun, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	if cm.running == nil {
+		return ChecksumRun{}, false
+	}
+	run := *cm.running
+	run.Tables = append([]TableChecksum(nil), cm.running.Tables...)
+	return run, true
+}
+
+// checksumTable 按主键分块校验一个表
+func (cm *ChecksumMonitor) checksumTable(ctx context.Context, source, replica *sql.DB, table string) TableChecksum {
+	result := TableChecksum{Table: table, StartedAt: time.Now()}
+	fail := func(err error) TableChecksum {
+		result.Error = err.Error()
+		result.FinishedAt = time.Now()
+		return result
+	}
+	databaseName, tableName, ok := strings.Cut(table, ".")
+	if !ok {
+		return fail(fmt.Errorf("expected database.table"))
+	}
+	columns, err := queryColumnNames(ctx, source, `
+		SELECT COLUMN_NAME FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION`, databaseName, tableName)
+	if err != nil {
+		return fail(fmt.Errorf("list columns: %w", err))
+	}
+	if len(columns) == 0 {
+		return fail(fmt.Errorf("table not found"))
+	}
+	keys, err := queryColumnNames(ctx, source, `
+		SELECT COLUMN_NAME FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND CONSTRAINT_NAME = 'PRIMARY' ORDER BY ORDINAL_POSITION`, databaseName, tableName)
+	if err != nil {
+		return fail(fmt.Errorf("list primary key: %w", err))
+	}
+	if len(keys) == 0 {
+		return fail(fmt.Errorf("table has no primary key, cannot be chunked"))
+	}
+
+	q := newChecksumQuery(QuoteTable(databaseName, tableName), columns, keys)
+	offset := cm.cfg.ChunkSize - 1
+	if offset < 0 {
+		offset = 0
+	}
+	var lower []interface{}
+	for {
+		args := append(append([]interface{}{}, lower...), offset)
+		upper, err := scanKey(source.QueryRowContext(ctx, q.boundary(lower != nil), args...), len(keys))
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fail(fmt.Errorf("find chunk boundary: %w", err))
+		}
+
+		where, whereArgs := q.where(lower, upper)
+		mismatch, sourceRows, err := compareChunk(ctx, source, replica, q.checksum(where), whereArgs)
+		if err == nil && mismatch != nil && cm.cfg.RecheckDelay > 0 {
+			// 复制延迟会让刚修改的行暂时不一致，等待后重新校验
+			select {
+			case <-ctx.Done():
+				return fail(ctx.Err())
+			case <-time.After(cm.cfg.RecheckDelay):
+			}
+			mismatch, sourceRows, err = compareChunk(ctx, source, replica, q.checksum(where), whereArgs)
+		}
+		if err != nil {
+			return fail(err)
+		}
+		if mismatch != nil {
+			mismatch.Lower = formatKey(lower)
+			mismatch.Upper = formatKey(upper)
+			result.Mismatches = append(result.Mismatches, *mismatch)
+		}
+		result.Chunks++
+		result.Rows += sourceRows
+
+		if upper == nil {
+			result.FinishedAt = time.Now()
+			return result
+		}
+		lower = upper
+
+		throttled, err := cm.throttle(ctx, source)
+		result.Throttled += throttled.Seconds()
+		if err != nil {
+			return fail(err)
+		}
+	}
+}
+
+// throttle 分块之间暂停，主库活跃线程数超过阈值时等待负载下降
+func (cm *ChecksumMonitor) throttle(ctx context.Context, source *sql.DB) (time.Duration, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-time.After(cm.cfg.ChunkPause):
+	}
+	if cm.cfg.MaxThreadsRunning <= 0 {
+		return 0, nil
+	}
+
+	started := time.Now()
+	for {
+		var name, value string
+		if err := source.QueryRowContext(ctx, "SHOW GLOBAL STATUS LIKE 'Threads_running'").Scan(&name, &value); err != nil {
+			return time.Since(started), fmt.Errorf("read Threads_running: %w", err)
+		}
+		running, _ := strconv.Atoi(value)
+		if running <= cm.cfg.MaxThreadsRunning {
+			return time.Since(started), nil
+		}
+		if time.Since(started) > maxLoadWait {
+			return time.Since(started), fmt.Errorf("source Threads_running stayed above %d for %v", cm.cfg.MaxThreadsRunning, maxLoadWait)
+		}
+		select {
+		case <-ctx.Done():
+			return time.Since(started), ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// checksumQuery 一个表的分块校验语句
+type checksumQuery struct {
+	table   string
+	columns []string // 已引用的列名
+	keys    []string // 已引用的主键列名
+}
+
+// newChecksumQuery 根据列名和主键列名构造分块校验语句
+func newChecksumQuery(table string, columns, keys []string) checksumQuery {
+	q := checksumQuery{table: table}
+	for _, c := range columns {
+		q.columns = append(q.columns, sqltools.QuoteIdentifier(c))
+	}
+	for _, k := range keys {
+		q.keys = append(q.keys, sqltools.QuoteIdentifier(k))
+	}
+	return q
+}
+
+// tuple 主键的行构造器及对应的占位符
+func (q checksumQuery) tuple() (string, string) {
+	return "(" + strings.Join(q.keys, ", ") + ")", "(" + strings.TrimSuffix(strings.Repeat("?, ", len(q.keys)), ", ") + ")"
+}
+
+// boundary 查找分块上界：下界之后按主键排序的第 N 行
+func (q checksumQuery) boundary(hasLower bool) string {
+	keys, placeholders := q.tuple()
+	where := ""
+	if hasLower {
+		where = " WHERE " + keys + " > " + placeholders
+	}
+	return "SELECT " + strings.Join(q.keys, ", ") + " FROM " + q.table + where +
+		" ORDER BY " + strings.Join(q.keys, ", ") + " LIMIT 1 OFFSET ?"
+}
+
+// where 分块范围条件，lower 不含、upper 含，为 nil 时不限制
+func (q checksumQuery) where(lower, upper []interface{}) (string, []interface{}) {
+	keys, placeholders := q.tuple()
+	var conditions []string
+	var args []interface{}
+	if lower != nil {
+		conditions = append(conditions, keys+" > "+placeholders)
+		args = append(args, lower...)
+	}
+	if upper != nil {
+		conditions = append(conditions, keys+" <= "+placeholders)
+		args = append(args, upper...)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// checksum 分块的行数与校验和，NULL 单独标记以区分 NULL 和空字符串
+func (q checksumQuery) checksum(where string) string {
+	nulls := make([]string, len(q.columns))
+	for i, c := range q.columns {
+		nulls[i] = "ISNULL(" + c + ")"
+	}
+	row := "CONCAT_WS('#', " + strings.Join(q.columns, ", ") + ", CONCAT(" + strings.Join(nulls, ", ") + "))"
+	return "SELECT COUNT(*), COALESCE(BIT_XOR(CAST(CRC32(" + row + ") AS UNSIGNED)), 0) FROM " + q.table + where
+}
+
+// compareChunk 在主从库上计算同一分块的校验和，不一致时返回差异
+func compareChunk(ctx context.Context, source, replica *sql.DB, query string, args []interface{}) (*ChunkMismatch, int64, error) {
+	var m ChunkMismatch
+	if err := source.QueryRowContext(ctx, query, args...).Scan(&m.SourceRows, &m.SourceChecksum); err != nil {
+		return nil, 0, fmt.Errorf("checksum on source: %w", err)
+	}
+	if err := replica.QueryRowContext(ctx, query, args...).Scan(&m.ReplicaRows, &m.ReplicaChecksum); err != nil {
+		return nil, 0, fmt.Errorf("checksum on replica: %w", err)
+	}
+	if m.SourceRows == m.ReplicaRows && m.SourceChecksum == m.ReplicaChecksum {
+		return nil, m.SourceRows, nil
+	}
+	return &m, m.SourceRows, nil
+}
+
+// queryColumnNames 执行返回单列名称的查询
+func queryColumnNames(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// scanKey 读取主键值，作为下一次查询的参数
+func scanKey(row *sql.Row, n int) ([]interface{}, error) {
+	values := make([]string, n)
+	dest := make([]interface{}, n)
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	key := make([]interface{}, n)
+	for i, v := range values {
+		key[i] = v
+	}
+	return key, nil
+}
+
+// formatKey 以逗号连接主键值，用于展示分块范围
+func formatKey(key []interface{}) string {
+	parts := make([]string, len(key))
+	for i, v := range key {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// ConnState 数据库连接状态
+type ConnState string
+
+const (
+	StateConnected    ConnState = "connected"    // 已连接首选主机
+	StateDegraded     ConnState = "degraded"     // 已连接，但使用的是故障转移后的备用主机
+	StateReconnecting ConnState = "reconnecting" // 连接丢失，正在重连
+	StateGaveUp       ConnState = "gave_up"      // 重连次数达到上限后放弃
+	StateStopped      ConnState = "stopped"      // 未连接且未在重连 (启动前或已停止)
+)
+
+// maxStateHistory 保留的状态转换记录数
+const maxStateHistory = 100
+
+// StateTransition 一次状态转换
+type StateTransition struct {
+	From   ConnState `json:"from"`
+	To     ConnState `json:"to"`
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// Connected 判断状态是否可用 (已连接首选主机或备用主机)
+func (s ConnState) Connected() bool {
+	return s == StateConnected || s == StateDegraded
+}
+
+// State 获取当前连接状态
+func (r *Reconnector) State() ConnState {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.state
+}
+
+// StateHistory 获取当前状态、进入当前状态的时间和状态转换历史 (按时间先后)
+func (r *Reconnector) StateHistory() (ConnState, time.Time, []StateTransition) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	history := make([]StateTransition, len(r.transitions))
+	copy(history, r.transitions)
+	return r.state, r.stateSince, history
+}
+
+// setStateLocked 切换状态并记录转换，状态不变时忽略，调用方需持有 r.mu
+func (r *Reconnector) setStateLocked(to ConnState, reason string) {
+	if r.state == to {
+		return
+	}
+	now := time.Now()
+	if len(r.transitions) >= maxStateHistory {
+		r.transitions = r.transitions[1:]
+	}
+	r.transitions = append(r.transitions, StateTransition{From: r.state, To: to, At: now, Reason: reason})
+	r.state = to
+	r.stateSince = now
+}
+
+// connectedStateFor 连接首选主机时为 Connected，连接备用主机时为 Degraded
+func (r *Reconnector) connectedStateFor(host config.DBHost) ConnState {
+	if len(r.config.Hosts) > 1 && host != r.config.Hosts[0] {
+		return StateDegraded
+	}
+	return StateConnected
+}
+
+// markConnected 记录已连接到指定主机
+func (r *Reconnector) markConnected(host config.DBHost, reason string) {
+	r.mu.Lock()
+	r.setStateLocked(r.connectedStateFor(host), reason)
+	r.mu.Unlock()
+}
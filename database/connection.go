@@ -1,14 +1,13 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/furutachiKurea/block-checker/config"
-
-	_ "github.com/go-sql-driver/mysql"
 )
 
 var (
@@ -25,32 +24,35 @@ const (
 	ErrorTypeConfig       ErrorType = "configuration"
 	ErrorTypeTimeout      ErrorType = "timeout"
 	ErrorTypeSQL          ErrorType = "sql"
+	ErrorTypeNotification ErrorType = "notification" // 告警通知或心跳发送失败
 	ErrorTypeUnknown      ErrorType = "unknown"
 )
 
 // ErrorDetails 详细错误信息
 type ErrorDetails struct {
-	Type        ErrorType `json:"type"`
-	Code        string    `json:"code,omitempty"`
-	Message     string    `json:"message"`
-	Cause       string    `json:"cause,omitempty"`
-	Suggestion  string    `json:"suggestion,omitempty"`
-	Timestamp   string    `json:"timestamp"`
-	RetryCount  int       `json:"retry_count,omitempty"`
+	Type        ErrorType           `json:"type"`
+	Code        string              `json:"code,omitempty"`
+	Message     string              `json:"message"`
+	Cause       string              `json:"cause,omitempty"`
+	Suggestion  string              `json:"suggestion,omitempty"`
+	Timestamp   string              `json:"timestamp"`
+	RetryCount  int                 `json:"retry_count,omitempty"`
+	Network     *NetworkDiagnostics `json:"network,omitempty"` // 网络错误时的域名解析和 TCP 连接诊断
 }
 
 // DBStatus 数据库状态响应
 type DBStatus struct {
 	Status       string        `json:"status"`
 	Timestamp    string        `json:"timestamp,omitempty"`
+	Host         string        `json:"host,omitempty"` // 当前连接的主机
 	Error        string        `json:"error,omitempty"`
 	ErrorDetails *ErrorDetails `json:"error_details,omitempty"`
 }
 
 // InitDB 初始化数据库连接
+// 配置了多个主机时按优先级依次尝试，全部不可达时由重连器继续尝试
 func InitDB() error {
 	config := config.GetDBConfig()
-	dsn := buildDSN(config)
 
 	// 创建连接信息对象
 	connInfo := &ConnectionInfo{
@@ -61,52 +63,94 @@ func InitDB() error {
 		Database: config.Name,
 	}
 
-	var err error
-	mu.Lock()
-	db, err = sql.Open("mysql", dsn)
-	mu.Unlock()
-
+	newDB, host, err := openAvailable(config)
 	if err != nil {
-		logger := GetDatabaseLogger()
-		logger.ErrorWithConnection("数据库连接打开失败", connInfo, err.Error())
-		return fmt.Errorf("open database: %v", err)
-	}
+		logger := GetDatabaseLogger().Component(ComponentReconnector)
 
-	// 设置连接池参数
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(time.Hour)
+		// 保留首选主机的连接句柄，便于状态检查触发重连
+		fallback, openErr := openDB(config, config.Hosts[0])
+		if openErr != nil {
+			logger.ErrorWithConnection("数据库连接打开失败", connInfo, openErr.Error())
+			return fmt.Errorf("open database: %v", openErr)
+		}
+		mu.Lock()
+		db = fallback
+		mu.Unlock()
+		if err := replaceMonitorDB(config, config.Hosts[0]); err != nil {
+			logger.Warn("健康检查连接打开失败", err.Error())
+		}
 
-	// 测试连接
-	if err := db.Ping(); err != nil {
-		logger := GetDatabaseLogger()
-		
 		// 分析错误并记录
 		errorDetails := analyzeError(err, 0)
-		logger.ErrorWithConnection("❌ 数据库连接测试失败", connInfo,
+		logger.addEntryWithConnection(connectionFailureLevel(LogLevelError), "❌ 数据库连接测试失败",
 			fmt.Sprintf("错误类型: %s, 错误代码: %s, 问题原因: %s, 解决建议: %s",
-				errorDetails.Type, errorDetails.Code, errorDetails.Cause, errorDetails.Suggestion))
-		
+				errorDetails.Type, errorDetails.Code, errorDetails.Cause, errorDetails.Suggestion), connInfo)
+
 		// 启动重连器
+		GetUptimeTracker().markDown()
 		reconnector := GetReconnector()
 		reconnector.StartReconnection()
 		return nil
 	}
 
-	logger := GetDatabaseLogger()
-	logger.InfoWithConnection(fmt.Sprintf("✅ 数据库连接成功: %s:%s", config.Host, config.Port), connInfo)
+	mu.Lock()
+	db = newDB
+	mu.Unlock()
+	setActiveHost(host)
+	if err := replaceMonitorDB(config, host); err != nil {
+		GetDatabaseLogger().Component(ComponentReconnector).Warn("健康检查连接打开失败", err.Error())
+	}
+	go warmPool(newDB, config.PoolWarmup)
+
+	connInfo.Host, connInfo.Port = host.Host, host.Port
+	logger := GetDatabaseLogger().Component(ComponentReconnector)
+	logger.InfoWithConnection(fmt.Sprintf("✅ 数据库连接成功: %s:%s", host.Host, host.Port), connInfo)
+	if config.ReadOnly {
+		logger.Info("只读模式已启用，SELECT/SHOW/EXPLAIN 以外的语句将被拒绝")
+	}
 
 	// 标记为已连接
-	reconnector := GetReconnector()
-	reconnector.mu.Lock()
-	reconnector.isConnected = true
-	reconnector.mu.Unlock()
+	GetReconnector().markConnected(host, fmt.Sprintf("初始连接成功: %s", host.Address()))
+	GetUptimeTracker().markUp()
 
 	return nil
 }
 
+// WaitForDB 阻塞等待数据库可达，超过 timeout 仍未连接则返回错误
+func WaitForDB(timeout time.Duration) error {
+	reconnector := GetReconnector()
+	if reconnector.IsConnected() {
+		return nil
+	}
+	// 重试过程由重连器记录，这里只记录等待的最终结果
+	reconnector.StartReconnection()
+	logger := GetDatabaseLogger().Component(ComponentReconnector)
+	start := time.Now()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-deadline.C:
+			lastErr := reconnector.GetLastError()
+			logger.Error("❌ 等待数据库可达超时", fmt.Sprintf("等待: %v, 最后错误: %v", timeout, lastErr))
+			return fmt.Errorf("database not reachable within %v: %v", timeout, lastErr)
+		case <-ticker.C:
+			if reconnector.IsConnected() {
+				logger.Info("✅ 数据库已可达", fmt.Sprintf("等待: %v", time.Since(start).Round(time.Second)))
+				return nil
+			}
+		}
+	}
+}
+
 // GetDB 获取数据库连接
 func GetDB() *sql.DB {
+	mu.RLock()
+	defer mu.RUnlock()
 	return db
 }
 
@@ -130,19 +174,29 @@ func CloseDB() {
 	mu.Lock()
 	if db != nil {
 		if err := db.Close(); err != nil {
-			logger := GetDatabaseLogger()
+			logger := GetDatabaseLogger().Component(ComponentReconnector)
 			logger.ErrorWithConnection("关闭数据库连接失败", connInfo, err.Error())
 		} else {
-			logger := GetDatabaseLogger()
+			logger := GetDatabaseLogger().Component(ComponentReconnector)
 			logger.InfoWithConnection("数据库连接已关闭", connInfo)
 		}
 		db = nil
 	}
 	mu.Unlock()
+	closeMonitorDB()
 }
 
 // CheckStatus 检查数据库状态
-func CheckStatus() *DBStatus {
+func CheckStatus(ctx context.Context) *DBStatus {
+	return defaultStore.CheckStatus(ctx)
+}
+
+// CheckStatus 检查数据库状态
+// 使用健康检查专用连接，不与浏览查询争用连接池
+func (s *MySQLStore) CheckStatus(ctx context.Context) *DBStatus {
+	db := GetMonitorDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
 	reconnector := GetReconnector()
 
 	if db == nil {
@@ -152,7 +206,7 @@ func CheckStatus() *DBStatus {
 			Message:    "Database not initialized",
 			Cause:      "数据库连接未初始化",
 			Suggestion: "请检查数据库配置和启动过程",
-			Timestamp:  time.Now().Format("2006-01-02 15:04:05"),
+			Timestamp:  config.FormatAPITime(time.Now()),
 		}
 		return &DBStatus{
 			Status:       "Not Connected",
@@ -162,10 +216,12 @@ func CheckStatus() *DBStatus {
 	}
 
 	// 先测试连接
-	if err := db.Ping(); err != nil {
+	start := time.Now()
+	if err := db.PingContext(ctx); err != nil {
 		// 获取重连次数
 		retryCount := reconnector.GetRetryCount()
 		errorDetails := analyzeError(err, retryCount)
+		attachNetworkDiagnostics(errorDetails, err, defaultDialAddress(), GetDatabaseLogger())
 		
 		// 触发重连
 		reconnector.OnConnectionLost()
@@ -192,9 +248,10 @@ func CheckStatus() *DBStatus {
 
 	// 执行简单查询获取当前时间
 	var currentTime string
-	err := db.QueryRow("SELECT NOW()").Scan(&currentTime)
+	err := db.QueryRowContext(ctx, "SELECT NOW()").Scan(&currentTime)
 	if err != nil {
 		errorDetails := analyzeError(err, 0)
+		GetUptimeTracker().markDown()
 		return &DBStatus{
 			Status:       "Failed",
 			Error:        fmt.Sprintf("Query failed: %v", err),
@@ -202,9 +259,12 @@ func CheckStatus() *DBStatus {
 		}
 	}
 
+	GetIncidentRecorder().recordLatency(time.Since(start))
+	GetUptimeTracker().markUp()
 	return &DBStatus{
 		Status:    "OK",
 		Timestamp: currentTime,
+		Host:      ActiveHost(),
 	}
 }
 
@@ -219,8 +279,3 @@ func analyzeError(err error, retryCount int) *ErrorDetails {
 	return analyzer.AnalyzeError(err, retryCount)
 }
 
-// buildDSN 构建数据库连接字符串
-func buildDSN(config *config.DBConfig) string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&loc=Local",
-		config.User, config.Pass, config.Host, config.Port, config.Name)
-}
@@ -0,0 +1,333 @@
+package database
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// PasswordProvider 在建立连接时提供密码，用于短期凭据
+type PasswordProvider interface {
+	Password(ctx context.Context, host config.DBHost) (string, error)
+}
+
+// newPasswordProvider 根据配置选择密码来源，静态密码返回 nil
+func newPasswordProvider(cfg *config.DBConfig) (PasswordProvider, error) {
+	if cfg.PassSecondary != "" && cfg.AuthMode != "" && cfg.AuthMode != "password" {
+		return nil, fmt.Errorf("DB_PASS_SECONDARY only applies to static passwords, not DB_AUTH=%s", cfg.AuthMode)
+	}
+	switch cfg.AuthMode {
+	case "", "password":
+		return nil, nil
+	case "command":
+		if cfg.PassCommand == "" {
+			return nil, fmt.Errorf("DB_AUTH=command requires DB_PASS_COMMAND")
+		}
+		return newCachedPassword(cfg.PassTTL, func(ctx context.Context, host config.DBHost) (string, error) {
+			return commandPassword(ctx, cfg.PassCommand, host)
+		}), nil
+	case "rds-iam":
+		if cfg.AWSRegion == "" || cfg.AWSAccessKey == "" || cfg.AWSSecretKey == "" {
+			return nil, fmt.Errorf("DB_AUTH=rds-iam requires AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		// 令牌有效期 15 分钟，缓存时间不超过 10 分钟
+		ttl := cfg.PassTTL
+		if ttl > 10*time.Minute {
+			ttl = 10 * time.Minute
+		}
+		return newCachedPassword(ttl, func(ctx context.Context, host config.DBHost) (string, error) {
+			return rdsAuthToken(host, cfg.User, cfg.AWSRegion, cfg.AWSAccessKey, cfg.AWSSecretKey, cfg.AWSSessionToken, time.Now().UTC()), nil
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown DB_AUTH mode %q", cfg.AuthMode)
+	}
+}
+
+// ResolveCredentials 按配置获取一次首选主机的密码，用于启动前校验动态凭据是否可用
+func ResolveCredentials(ctx context.Context) error {
+	cfg := config.GetDBConfig()
+	provider, err := newPasswordProvider(cfg)
+	if err != nil || provider == nil {
+		return err
+	}
+	_, err = provider.Password(ctx, cfg.Hosts[0])
+	return err
+}
+
+// cachedPassword 按主机缓存动态密码，避免每个新连接都重新生成
+type cachedPassword struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	generate func(ctx context.Context, host config.DBHost) (string, error)
+	entries  map[string]cachedEntry
+}
+
+// cachedEntry 缓存的密码
+type cachedEntry struct {
+	password  string
+	expiresAt time.Time
+}
+
+// newCachedPassword 创建带缓存的密码来源
+func newCachedPassword(ttl time.Duration, generate func(ctx context.Context, host config.DBHost) (string, error)) *cachedPassword {
+	return &cachedPassword{ttl: ttl, generate: generate, entries: make(map[string]cachedEntry)}
+}
+
+// Password 获取密码，缓存过期时重新生成
+func (c *cachedPassword) Password(ctx context.Context, host config.DBHost) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := host.Address()
+	if entry, ok := c.entries[key]; ok && time.Now().Before(entry.expiresAt) {
+		return entry.password, nil
+	}
+	password, err := c.generate(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	c.entries[key] = cachedEntry{password: password, expiresAt: time.Now().Add(c.ttl)}
+	return password, nil
+}
+
+// invalidate 清除缓存，重连时强制重新获取凭据
+func (c *cachedPassword) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cachedEntry)
+}
+
+// commandPassword 执行命令并以其标准输出作为密码
+// 命令可通过 DB_HOST/DB_PORT 环境变量得知目标主机
+func commandPassword(ctx context.Context, command string, host config.DBHost) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(cmd.Environ(), "DB_HOST="+host.Host, "DB_PORT="+host.Port)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("password command: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("password command: %w", err)
+	}
+	password := strings.TrimRight(string(output), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("password command printed nothing")
+	}
+	return password, nil
+}
+
+// rdsAuthToken 生成 RDS IAM 认证令牌 (预签名的 rds-db:connect 请求，有效期 15 分钟)
+func rdsAuthToken(host config.DBHost, user, region, accessKey, secretKey, sessionToken string, now time.Time) string {
+	endpoint := host.Address()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	scope := dateStamp + "/" + region + "/rds-db/aws4_request"
+
+	query := url.Values{}
+	query.Set("Action", "connect")
+	query.Set("DBUser", user)
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", accessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", "900")
+	query.Set("X-Amz-SignedHeaders", "host")
+	if sessionToken != "" {
+		query.Set("X-Amz-Security-Token", sessionToken)
+	}
+	canonicalQuery := sigV4Query(query)
+
+	emptyHash := sha256.Sum256(nil)
+	canonicalRequest := strings.Join([]string{
+		"GET",
+		"/",
+		canonicalQuery,
+		"host:" + endpoint + "\n",
+		"host",
+		hex.EncodeToString(emptyHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signingKey := hmacSum([]byte("AWS4"+secretKey), dateStamp)
+	signingKey = hmacSum(signingKey, region)
+	signingKey = hmacSum(signingKey, "rds-db")
+	signingKey = hmacSum(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSum(signingKey, stringToSign))
+
+	// 与 AWS SDK (feature/rds/auth.BuildAuthToken) 的格式一致：地址后直接跟查询参数
+	return endpoint + "?" + canonicalQuery + "&X-Amz-Signature=" + signature
+}
+
+// sigV4Query 按 SigV4 规则编码并排序查询参数
+func sigV4Query(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, sigV4Escape(k)+"="+sigV4Escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// sigV4Escape 按 RFC 3986 编码，非保留字符 (字母、数字和 -_.~) 保持原样，其余字节编码为 %XX
+func sigV4Escape(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&15])
+		}
+	}
+	return b.String()
+}
+
+// hmacSum 计算 HMAC-SHA256
+func hmacSum(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// 双密码轮换中的密码
+const (
+	SecretPrimary   = "primary"
+	SecretSecondary = "secondary"
+)
+
+// SecretStatus 双密码轮换状态
+type SecretStatus struct {
+	SecondaryConfigured bool      `json:"secondary_configured"`
+	Active              string    `json:"active"` // 最近一次认证成功使用的密码，新连接优先使用
+	SwitchedAt          time.Time `json:"switched_at,omitempty"`
+	Switches            int       `json:"switches"`
+}
+
+var (
+	secretStatus   = SecretStatus{Active: SecretPrimary}
+	secretStatusMu sync.RWMutex
+)
+
+// GetSecretStatus 获取默认连接的双密码轮换状态
+func GetSecretStatus() SecretStatus {
+	secretStatusMu.RLock()
+	defer secretStatusMu.RUnlock()
+	status := secretStatus
+	status.SecondaryConfigured = config.GetDBConfig().PassSecondary != ""
+	return status
+}
+
+// activeSecret 新连接优先使用的密码
+func activeSecret() string {
+	secretStatusMu.RLock()
+	defer secretStatusMu.RUnlock()
+	return secretStatus.Active
+}
+
+// switchSecret 记录另一个密码认证成功，之后的新连接优先使用该密码
+func switchSecret(secret string) {
+	secretStatusMu.Lock()
+	if secretStatus.Active == secret {
+		secretStatusMu.Unlock()
+		return
+	}
+	secretStatus.Active = secret
+	secretStatus.SwitchedAt = time.Now()
+	secretStatus.Switches++
+	secretStatusMu.Unlock()
+
+	logger := GetDatabaseLogger().Component(ComponentReconnector)
+	if secret == SecretSecondary {
+		logger.Warn("主密码认证失败，已改用备用密码 (DB_PASS_SECONDARY)", "请在密码轮换完成后更新 DB_PASS")
+	} else {
+		logger.Info("备用密码认证失败，已改回主密码")
+	}
+}
+
+// dualSecretConnector 使用主密码和备用密码的连接器：当前密码认证失败时尝试另一个密码
+// 轮换密码时先把新密码配置为备用密码，数据库侧修改密码后连接自动切换，无需重启
+type dualSecretConnector struct {
+	primary   driver.Connector
+	secondary driver.Connector
+}
+
+// newDualSecretConnector 以 mysqlConfig 中的密码为主密码、secondary 为备用密码创建连接器
+func newDualSecretConnector(mysqlConfig *mysql.Config, secondary string) (driver.Connector, error) {
+	primary, err := mysql.NewConnector(mysqlConfig)
+	if err != nil {
+		return nil, err
+	}
+	secondaryConfig := mysqlConfig.Clone()
+	secondaryConfig.Passwd = secondary
+	alternate, err := mysql.NewConnector(secondaryConfig)
+	if err != nil {
+		return nil, err
+	}
+	return dualSecretConnector{primary: primary, secondary: alternate}, nil
+}
+
+// Connect 先使用最近一次认证成功的密码，认证失败 (1045) 时改用另一个密码
+func (c dualSecretConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	current, other := SecretPrimary, SecretSecondary
+	if activeSecret() == SecretSecondary {
+		current, other = other, current
+	}
+	conn, err := c.connector(current).Connect(ctx)
+	if err == nil || !isAuthError(err) {
+		return conn, err
+	}
+	conn, otherErr := c.connector(other).Connect(ctx)
+	if otherErr != nil {
+		// 两个密码都失败时返回当前密码的错误
+		return nil, err
+	}
+	switchSecret(other)
+	return conn, nil
+}
+
+// Driver 返回底层驱动
+func (c dualSecretConnector) Driver() driver.Driver {
+	return c.primary.Driver()
+}
+
+// connector 按密码选择连接器
+func (c dualSecretConnector) connector(secret string) driver.Connector {
+	if secret == SecretSecondary {
+		return c.secondary
+	}
+	return c.primary
+}
+
+// isAuthError 是否为密码错误 (ER_ACCESS_DENIED_ERROR)
+func isAuthError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1045
+}
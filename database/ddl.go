@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/sqltools"
+)
+
+// ExportDDL 导出数据库中所有表的建表语句
+func ExportDDL(ctx context.Context, databaseName string) (string, error) {
+	return defaultStore.ExportDDL(ctx, databaseName)
+}
+
+// ExportDDL 导出数据库中所有表的建表语句，表名补全库名，便于在其他实例直接执行
+func (s *MySQLStore) ExportDDL(ctx context.Context, databaseName string) (string, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return "", fmt.Errorf("database not initialized")
+	}
+	if err := checkDatabase(ctx, db, databaseName); err != nil {
+		return "", err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT TABLE_NAME FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TABLE_NAME`, databaseName)
+	if err != nil {
+		return "", fmt.Errorf("query tables: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return "", fmt.Errorf("scan table: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("iterate tables: %w", err)
+	}
+
+	var statements []string
+	for _, table := range tables {
+		var name, ddl string
+		query := "SHOW CREATE TABLE " + QuoteTable(databaseName, table)
+		if err := db.QueryRowContext(ctx, query).Scan(&name, &ddl); err != nil {
+			return "", fmt.Errorf("show create table %s: %w", table, err)
+		}
+		qualified, err := sqltools.Qualify(ddl, databaseName)
+		if err != nil {
+			return "", fmt.Errorf("qualify %s: %w", table, err)
+		}
+		statements = append(statements, qualified+";")
+	}
+	return strings.Join(statements, "\n\n"), nil
+}
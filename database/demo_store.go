@@ -0,0 +1,1200 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/sqltools"
+)
+
+// 演示数据中的阻塞周期：每个周期的前 demoBlockActive 存在一条行锁等待链，之后恢复正常，便于演示阻塞告警的触发和恢复
+const (
+	demoBlockCycle  = 5 * time.Minute
+	demoBlockActive = 2 * time.Minute
+)
+
+const (
+	// demoLockWaitTimeout 演示数据的 innodb_lock_wait_timeout，等待的会话超时后立即重试
+	demoLockWaitTimeout = 50
+	// demoSearchRows 值搜索在每张表中检查的行数 (自增主键列按范围判断，不受该限制)
+	demoSearchRows = 2000
+	// demoUptimeBase 启动时模拟的服务器已运行秒数
+	demoUptimeBase = 38 * 24 * 3600
+	// demoCollation 演示数据字符串列的排序规则
+	demoCollation = "utf8mb4_0900_ai_ci"
+)
+
+// 阻塞链中的会话：持锁会话更新订单后未提交，另外两个会话更新同一订单时等待
+const (
+	demoHolderPID   = 1042
+	demoWaiterPID   = 1051
+	demoWaiter2PID  = 1057
+	demoLockedOrder = 47913
+	demoCronPID     = 1101 // 持有命名锁的定时任务
+)
+
+// demoColumn 演示表的字段
+type demoColumn struct {
+	name     string
+	typ      string // 列类型，如 varchar(255)
+	nullable bool
+	extra    string
+	comment  string
+}
+
+// demoIndex 演示表的索引，第一个为主键
+type demoIndex struct {
+	name    string
+	columns []string
+	unique  bool
+}
+
+// demoTable 演示表，行数从 rows 开始每小时增长 growth 行
+type demoTable struct {
+	name     string
+	comment  string
+	engine   string
+	columns  []demoColumn
+	indexes  []demoIndex
+	fks      []ForeignKey
+	triggers []TableTrigger
+	rows     int64
+	growth   int64
+	rowSize  int64 // 平均行长度 (字节)
+}
+
+// demoSchema 演示数据库
+type demoSchema struct {
+	name   string
+	tables []demoTable
+}
+
+// demoSchemas 演示数据库和表结构，按名称排序
+var demoSchemas = []demoSchema{
+	{name: "analytics", tables: []demoTable{
+		{
+			name: "daily_sales", engine: "MyISAM",
+			columns: []demoColumn{
+				{"day", "date", false, "", "日期"},
+				{"orders", "int unsigned", false, "", "订单数"},
+				{"revenue", "decimal(14,2)", false, "", "销售额"},
+			},
+			indexes: []demoIndex{{"PRIMARY", []string{"day"}, true}},
+			rows:    730, rowSize: 40,
+		},
+		{
+			name: "page_views", comment: "页面访问日志", engine: "InnoDB",
+			columns: []demoColumn{
+				{"id", "bigint unsigned", false, "auto_increment", ""},
+				{"path", "varchar(255)", false, "", "访问路径"},
+				{"customer_id", "bigint unsigned", true, "", "登录用户，匿名访问为空"},
+				{"viewed_at", "datetime", false, "", "访问时间"},
+			},
+			indexes: []demoIndex{
+				{"PRIMARY", []string{"id"}, true},
+				{"idx_viewed_at", []string{"viewed_at"}, false},
+			},
+			rows: 2400000, growth: 12000, rowSize: 110,
+		},
+	}},
+	{name: "shop", tables: []demoTable{
+		{
+			name: "customers", comment: "客户", engine: "InnoDB",
+			columns: []demoColumn{
+				{"id", "bigint unsigned", false, "auto_increment", "客户 ID"},
+				{"email", "varchar(255)", false, "", "登录邮箱"},
+				{"name", "varchar(100)", false, "", "姓名"},
+				{"phone", "varchar(32)", true, "", "手机号"},
+				{"created_at", "datetime", false, "", "注册时间"},
+			},
+			indexes: []demoIndex{
+				{"PRIMARY", []string{"id"}, true},
+				{"uk_email", []string{"email"}, true},
+			},
+			rows: 12000, growth: 40, rowSize: 180,
+		},
+		{
+			name: "inventory", comment: "库存", engine: "InnoDB",
+			columns: []demoColumn{
+				{"product_id", "int unsigned", false, "", "商品 ID"},
+				{"quantity", "int", false, "", "可售库存"},
+				{"updated_at", "datetime", false, "", "更新时间"},
+			},
+			indexes: []demoIndex{{"PRIMARY", []string{"product_id"}, true}},
+			fks: []ForeignKey{
+				{Name: "fk_inventory_product", Columns: []string{"product_id"}, ReferencedDatabase: "shop", ReferencedTable: "products", ReferencedColumns: []string{"id"}},
+			},
+			rows: 850, rowSize: 48,
+		},
+		{
+			name: "order_items", comment: "订单明细", engine: "InnoDB",
+			columns: []demoColumn{
+				{"id", "bigint unsigned", false, "auto_increment", ""},
+				{"order_id", "bigint unsigned", false, "", "订单 ID"},
+				{"product_id", "int unsigned", false, "", "商品 ID"},
+				{"quantity", "int", false, "", "数量"},
+				{"price", "decimal(10,2)", false, "", "成交单价"},
+			},
+			indexes: []demoIndex{
+				{"PRIMARY", []string{"id"}, true},
+				{"idx_order", []string{"order_id"}, false},
+				{"idx_product", []string{"product_id"}, false},
+			},
+			fks: []ForeignKey{
+				{Name: "fk_items_order", Columns: []string{"order_id"}, ReferencedDatabase: "shop", ReferencedTable: "orders", ReferencedColumns: []string{"id"}},
+				{Name: "fk_items_product", Columns: []string{"product_id"}, ReferencedDatabase: "shop", ReferencedTable: "products", ReferencedColumns: []string{"id"}},
+			},
+			triggers: []TableTrigger{{
+				Name: "trg_order_items_stock", Timing: "AFTER", Event: "INSERT", Definer: "app@10.0.1.%",
+				Statement: "UPDATE inventory SET quantity = quantity - NEW.quantity, updated_at = NOW() WHERE product_id = NEW.product_id",
+			}},
+			rows: 150000, growth: 900, rowSize: 64,
+		},
+		{
+			name: "orders", comment: "订单", engine: "InnoDB",
+			columns: []demoColumn{
+				{"id", "bigint unsigned", false, "auto_increment", "订单 ID"},
+				{"customer_id", "bigint unsigned", false, "", "下单客户"},
+				{"status", "enum('pending','paid','shipped','cancelled')", false, "", "订单状态"},
+				{"total", "decimal(12,2)", false, "", "订单金额"},
+				{"created_at", "datetime", false, "", "下单时间"},
+			},
+			indexes: []demoIndex{
+				{"PRIMARY", []string{"id"}, true},
+				{"idx_customer", []string{"customer_id"}, false},
+				{"idx_created_at", []string{"created_at"}, false},
+			},
+			fks: []ForeignKey{
+				{Name: "fk_orders_customer", Columns: []string{"customer_id"}, ReferencedDatabase: "shop", ReferencedTable: "customers", ReferencedColumns: []string{"id"}},
+			},
+			rows: 48000, growth: 300, rowSize: 96,
+		},
+		{
+			name: "products", comment: "商品", engine: "InnoDB",
+			columns: []demoColumn{
+				{"id", "int unsigned", false, "auto_increment", "商品 ID"},
+				{"sku", "varchar(64)", false, "", "库存单位编码"},
+				{"name", "varchar(200)", false, "", "商品名称"},
+				{"price", "decimal(10,2)", false, "", "单价"},
+				{"created_at", "datetime", false, "", "上架时间"},
+			},
+			indexes: []demoIndex{
+				{"PRIMARY", []string{"id"}, true},
+				{"uk_sku", []string{"sku"}, true},
+			},
+			rows: 850, growth: 1, rowSize: 220,
+		},
+		{
+			name: "session_cache", comment: "登录会话缓存", engine: "MEMORY",
+			columns: []demoColumn{
+				{"token", "char(64)", false, "", "会话令牌"},
+				{"customer_id", "bigint unsigned", false, "", "客户 ID"},
+				{"expires_at", "datetime", false, "", "过期时间"},
+			},
+			indexes: []demoIndex{{"PRIMARY", []string{"token"}, true}},
+			rows:    3200, rowSize: 120,
+		},
+	}},
+}
+
+var (
+	demoNames    = []string{"Alice Chen", "Bob Li", "Carol Wang", "David Zhang", "Eve Liu", "Frank Zhao", "Grace Wu", "Henry Sun", "Ivy Zhou", "Jack Xu"}
+	demoProducts = []string{"Mechanical Keyboard", "Wireless Mouse", "USB-C Hub", "27\" Monitor", "Laptop Stand", "Noise Cancelling Headphones", "Webcam", "Desk Lamp"}
+	demoStatuses = []string{"paid", "shipped", "shipped", "pending", "cancelled"}
+	demoPaths    = []string{"/", "/products", "/products/{id}", "/cart", "/checkout", "/account/orders", "/search"}
+)
+
+// DemoStore 内存中的演示数据 (DB_DRIVER=demo)，不连接任何 MySQL
+// 提供固定的表结构和随时间变化的合成数据：表行数持续增长，连接数周期性波动，每 5 分钟出现一次持续 2 分钟的行锁阻塞链
+// 用于演示界面和在 CI 中运行端到端测试；直接使用连接池的功能 (如空闲事务处理、外键检查、校验和) 在演示模式下不可用
+type DemoStore struct {
+	started time.Time
+}
+
+// NewDemoStore 创建演示数据存储，合成数据从当前时间开始变化
+func NewDemoStore() *DemoStore {
+	return &DemoStore{started: time.Now()}
+}
+
+// UseDemoStore 将默认存储替换为演示数据并标记为已连接，需在启动各采样器之前调用
+func UseDemoStore() *DemoStore {
+	s := NewDemoStore()
+	defaultStore = s
+
+	setActiveHost(config.DBHost{Host: "demo", Port: "3306"})
+	reconnector := GetReconnector()
+	reconnector.mu.Lock()
+	reconnector.setStateLocked(StateConnected, "演示模式")
+	reconnector.mu.Unlock()
+	GetUptimeTracker().markUp()
+	GetDatabaseLogger().Component(ComponentReconnector).Info("演示模式已启用，使用内存中的演示数据，不连接 MySQL")
+	return s
+}
+
+// schema 查找演示数据库
+func (s *DemoStore) schema(databaseName string) (*demoSchema, error) {
+	if err := ValidateIdentifier(databaseName); err != nil {
+		return nil, err
+	}
+	for i := range demoSchemas {
+		if demoSchemas[i].name == databaseName {
+			return &demoSchemas[i], nil
+		}
+	}
+	return nil, ErrDatabaseNotFound
+}
+
+// table 查找演示表
+func (s *DemoStore) table(databaseName, tableName string) (*demoTable, error) {
+	schema, err := s.schema(databaseName)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateIdentifier(tableName); err != nil {
+		return nil, err
+	}
+	for i := range schema.tables {
+		if schema.tables[i].name == tableName {
+			return &schema.tables[i], nil
+		}
+	}
+	return nil, ErrTableNotFound
+}
+
+// rowCount 表当前的行数
+func (s *DemoStore) rowCount(t *demoTable, now time.Time) int64 {
+	return t.rows + int64(float64(t.growth)*now.Sub(s.started).Hours())
+}
+
+// stat 表当前的行数和空间占用
+func (s *DemoStore) stat(t *demoTable, now time.Time) TableStat {
+	rows := s.rowCount(t, now)
+	return TableStat{Rows: rows, DataSize: rows * t.rowSize, IndexSize: rows * 24 * int64(len(t.indexes)-1)}
+}
+
+// isPrimary 字段是否属于主键
+func (t *demoTable) isPrimary(column string) bool {
+	for _, c := range t.indexes[0].columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// columnCategory 字段类型所属的值搜索类别
+func columnCategory(typ string) string {
+	base := strings.ToLower(typ)
+	if i := strings.IndexAny(base, "( "); i >= 0 {
+		base = base[:i]
+	}
+	return findTypeCategories[base]
+}
+
+// value 第 id 行 (从 1 开始，按主键顺序) 的字段值，同一行总是生成相同的值，日期按行数倒推
+func (s *DemoStore) value(t *demoTable, c demoColumn, id, total int64, now time.Time) interface{} {
+	pick := func(items []string) string { return items[(id*7919)%int64(len(items))] }
+	switch c.name {
+	case "id":
+		return id
+	case "email":
+		return fmt.Sprintf("%s%d@example.com", strings.ToLower(strings.Fields(pick(demoNames))[0]), id)
+	case "name":
+		if t.name == "products" {
+			return fmt.Sprintf("%s #%d", demoProducts[id%int64(len(demoProducts))], id)
+		}
+		return pick(demoNames)
+	case "phone":
+		if id%5 == 0 {
+			return nil
+		}
+		return fmt.Sprintf("138%08d", (id*7919)%100000000)
+	case "sku":
+		return fmt.Sprintf("SKU-%05d", id)
+	case "status":
+		if total-id < 20 {
+			return "pending"
+		}
+		return pick(demoStatuses)
+	case "path":
+		return strings.Replace(pick(demoPaths), "{id}", strconv.FormatInt(id%850+1, 10), 1)
+	case "token":
+		sum := sha256.Sum256([]byte(strconv.FormatInt(id, 10)))
+		return hex.EncodeToString(sum[:])
+	case "customer_id":
+		if t.name == "page_views" && id%3 == 0 {
+			return nil
+		}
+		return (id*7919)%12000 + 1
+	case "order_id":
+		return (id + 2) / 3
+	case "product_id":
+		if t.name == "inventory" {
+			return id
+		}
+		return (id*31)%850 + 1
+	case "quantity":
+		if t.name == "inventory" {
+			return (id * 37) % 500
+		}
+		return id%4 + 1
+	case "price":
+		productID := id
+		if t.name == "order_items" {
+			productID = (id*31)%850 + 1
+		}
+		return fmt.Sprintf("%.2f", float64((productID*7919)%50000+990)/100)
+	case "total":
+		return fmt.Sprintf("%.2f", float64((id*104729)%200000+1990)/100)
+	case "orders":
+		return 120 + (id*37)%90
+	case "revenue":
+		return fmt.Sprintf("%.2f", float64(((120+(id*37)%90)*(id*7919%20000+15000))/100))
+	case "day":
+		return now.AddDate(0, 0, -int(total-id+1)).Format("2006-01-02")
+	case "expires_at":
+		return now.Add(time.Duration(id%720) * time.Minute).Format("2006-01-02 15:04:05")
+	}
+	if strings.HasPrefix(c.typ, "datetime") {
+		// 行按主键顺序写入，最后一行刚刚写入
+		gap := 10 * time.Minute
+		if t.growth > 0 {
+			gap = time.Hour / time.Duration(t.growth)
+		}
+		return now.Add(-time.Duration(total-id) * gap).Format("2006-01-02 15:04:05")
+	}
+	return nil
+}
+
+// blockingPhase 当前阻塞周期已持续的秒数，不在阻塞阶段时 ok 为 false
+func (s *DemoStore) blockingPhase(now time.Time) (seconds int64, ok bool) {
+	phase := now.Sub(s.started) % demoBlockCycle
+	if phase >= demoBlockActive {
+		return 0, false
+	}
+	return int64(phase.Seconds()), true
+}
+
+// processes 当前的会话列表，会话数按 15 分钟的周期在 20 到 70 之间波动
+func (s *DemoStore) processes(now time.Time) []Process {
+	elapsed := now.Sub(s.started)
+	connected := int(45 + 25*math.Sin(2*math.Pi*elapsed.Minutes()/15))
+	uptime := demoUptimeBase + int64(elapsed.Seconds())
+
+	procs := []Process{
+		{ID: 5, User: "event_scheduler", Host: "localhost", Command: "Daemon", Time: uptime, State: "Waiting on empty queue"},
+		{ID: demoCronPID, User: "cron", Host: "10.0.2.5:41822", DB: "analytics", Command: "Sleep", Time: int64(elapsed.Seconds())%600 + 1, Isolation: "READ-COMMITTED"},
+	}
+	if phase, ok := s.blockingPhase(now); ok {
+		update := "UPDATE orders SET status = '%s' WHERE id = %d"
+		procs = append(procs,
+			Process{ID: demoHolderPID, User: "app", Host: "10.0.1.15:52044", DB: "shop", Command: "Sleep", Time: phase + 30, Isolation: "REPEATABLE-READ"},
+			Process{ID: demoWaiterPID, User: "app", Host: "10.0.1.16:50312", DB: "shop", Command: "Query", Time: phase % demoLockWaitTimeout,
+				State: "updating", Info: fmt.Sprintf(update, "shipped", demoLockedOrder), Isolation: "REPEATABLE-READ"})
+		if phase >= 15 {
+			procs = append(procs, Process{ID: demoWaiter2PID, User: "app", Host: "10.0.1.17:50988", DB: "shop", Command: "Query", Time: (phase - 15) % demoLockWaitTimeout,
+				State: "updating", Info: fmt.Sprintf(update, "cancelled", demoLockedOrder), Isolation: "REPEATABLE-READ"})
+		}
+	}
+
+	// 少量正在执行的查询，其余为连接池中的空闲连接
+	running := 1 + int(elapsed.Seconds())%3
+	for i := 0; i < running; i++ {
+		procs = append(procs, Process{ID: int64(2000 + i), User: "app", Host: fmt.Sprintf("10.0.1.%d:4%04d", 20+i, i*37), DB: "shop", Command: "Query",
+			Time: int64(i), State: "executing", Info: fmt.Sprintf("SELECT * FROM orders WHERE customer_id = %d ORDER BY created_at DESC LIMIT 20", 1000+i*77),
+			Isolation: "REPEATABLE-READ"})
+	}
+	for i := 0; len(procs) < connected; i++ {
+		procs = append(procs, Process{ID: int64(3000 + i), User: "app", Host: fmt.Sprintf("10.0.1.%d:5%04d", 30+i%8, i), DB: "shop", Command: "Sleep",
+			Time: int64((i * 13) % 60), Isolation: "REPEATABLE-READ"})
+	}
+	sort.SliceStable(procs, func(i, j int) bool { return procs[i].Time > procs[j].Time })
+	return procs
+}
+
+// lockWaits 当前的行锁等待，只在阻塞阶段存在
+func (s *DemoStore) lockWaits(now time.Time) []LockWait {
+	waits := []LockWait{}
+	for _, p := range s.processes(now) {
+		if p.ID != demoWaiterPID && p.ID != demoWaiter2PID {
+			continue
+		}
+		waits = append(waits, LockWait{
+			LockType:          LockTypeRow,
+			Table:             "`shop`.`orders`",
+			WaitingPID:        p.ID,
+			WaitingQuery:      p.Info,
+			WaitSeconds:       p.Time,
+			BlockingPID:       demoHolderPID,
+			BlockingUser:      "app",
+			BlockingHost:      "10.0.1.15:52044",
+			WaitingIsolation:  p.Isolation,
+			BlockingIsolation: "REPEATABLE-READ",
+		})
+	}
+	return waits
+}
+
+// globalStatus 随时间增长的全局状态计数器
+func (s *DemoStore) globalStatus(now time.Time) map[string]int64 {
+	e := now.Sub(s.started).Seconds()
+	procs := s.processes(now)
+	var running int64
+	for _, p := range procs {
+		if p.Command == "Query" {
+			running++
+		}
+	}
+	waits := int64(len(s.lockWaits(now)))
+	cycles := int64(now.Sub(s.started) / demoBlockCycle)
+	return map[string]int64{
+		"Uptime":                        demoUptimeBase + int64(e),
+		"Questions":                     1250000 + int64(850*e),
+		"Queries":                       1310000 + int64(870*e),
+		"Com_select":                    820000 + int64(600*e),
+		"Com_insert":                    210000 + int64(120*e),
+		"Com_update":                    96000 + int64(60*e),
+		"Com_delete":                    8200 + int64(8*e),
+		"Com_commit":                    300000 + int64(180*e),
+		"Threads_connected":             int64(len(procs)),
+		"Threads_running":               running,
+		"Threads_created":               420 + int64(e/60),
+		"Max_used_connections":          96,
+		"Connections":                   52000 + int64(e/2),
+		"Aborted_connects":              12,
+		"Aborted_clients":               37,
+		"Created_tmp_disk_tables":       1200 + int64(0.4*e),
+		"Created_tmp_tables":            9800 + int64(3*e),
+		"Select_full_join":              300 + int64(0.1*e),
+		"Sort_merge_passes":             40 + int64(0.05*e),
+		"Slow_queries":                  86 + int64(0.02*e),
+		"Innodb_row_lock_waits":         520 + cycles*6 + waits,
+		"Innodb_row_lock_current_waits": waits,
+		"Innodb_row_lock_time":          (520 + cycles*6) * 18000,
+		"Innodb_deadlocks":              3,
+		"Bytes_received":                9200000000 + int64(120000*e),
+		"Bytes_sent":                    48000000000 + int64(610000*e),
+	}
+}
+
+// demoGlobalVariables 演示服务器的全局变量
+func demoGlobalVariables() map[string]string {
+	return map[string]string{
+		"version":                        "8.0.36-demo",
+		"version_comment":                "block-checker demo backend",
+		"max_connections":                "151",
+		"innodb_lock_wait_timeout":       strconv.Itoa(demoLockWaitTimeout),
+		"innodb_deadlock_detect":         "ON",
+		"innodb_buffer_pool_size":        "1073741824",
+		"innodb_flush_log_at_trx_commit": "1",
+		"transaction_isolation":          "REPEATABLE-READ",
+		"autocommit":                     "ON",
+		"binlog_format":                  "ROW",
+		"log_bin":                        "ON",
+		"gtid_mode":                      "ON",
+		"sync_binlog":                    "1",
+		"long_query_time":                "2.000000",
+		"slow_query_log":                 "ON",
+		"read_only":                      "OFF",
+		"performance_schema":             "ON",
+		"character_set_server":           "utf8mb4",
+		"collation_server":               demoCollation,
+		"time_zone":                      "SYSTEM",
+		"wait_timeout":                   "28800",
+		"sql_mode":                       "ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ZERO_IN_DATE,NO_ZERO_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION",
+	}
+}
+
+// CheckStatus 演示后端始终可用
+func (s *DemoStore) CheckStatus(ctx context.Context) *DBStatus {
+	return &DBStatus{Status: "OK", Timestamp: config.FormatAPITime(time.Now()), Host: ActiveHost()}
+}
+
+// IsReconnecting 演示后端不会重连
+func (s *DemoStore) IsReconnecting() bool {
+	return false
+}
+
+// GetDatabases 获取演示数据库列表
+func (s *DemoStore) GetDatabases(ctx context.Context) ([]DatabaseInfo, error) {
+	databases := make([]DatabaseInfo, len(demoSchemas))
+	for i, schema := range demoSchemas {
+		databases[i] = DatabaseInfo{Name: schema.name}
+	}
+	return databases, nil
+}
+
+// GetTables 获取演示数据库的表列表
+func (s *DemoStore) GetTables(ctx context.Context, databaseName string) ([]TableInfo, error) {
+	schema, err := s.schema(databaseName)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tables := make([]TableInfo, len(schema.tables))
+	for i := range schema.tables {
+		t := &schema.tables[i]
+		stat := s.stat(t, now)
+		tables[i] = TableInfo{
+			Name:    t.name,
+			Comment: t.comment,
+			Rows:    stat.Rows,
+			Size:    fmt.Sprintf("%.2f MB", float64(stat.DataSize+stat.IndexSize)/1024/1024),
+		}
+	}
+	return tables, nil
+}
+
+// GetTableDetail 获取演示表的字段、索引、约束和触发器
+func (s *DemoStore) GetTableDetail(ctx context.Context, databaseName, tableName string) (*TableDetail, error) {
+	t, err := s.table(databaseName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	rows := s.rowCount(t, time.Now())
+	detail := &TableDetail{Fields: []TableField{}, Indexes: []TableIndex{}, Constraints: []TableConstraint{}, Triggers: []TableTrigger{}}
+	for _, c := range t.columns {
+		detail.Fields = append(detail.Fields, TableField{
+			Name: c.name, Type: c.typ, IsNullable: c.nullable, IsPrimary: t.isPrimary(c.name), Extra: c.extra, Comment: c.comment,
+		})
+	}
+
+	indexType := "BTREE"
+	if t.engine == "MEMORY" {
+		indexType = "HASH"
+	}
+	for _, idx := range t.indexes {
+		cardinality := rows
+		if !idx.unique {
+			cardinality = rows / 8
+		}
+		detail.Indexes = append(detail.Indexes, TableIndex{
+			Name: idx.name, Columns: idx.columns, Unique: idx.unique, Type: indexType, Cardinality: cardinality, Visible: true,
+		})
+		switch {
+		case idx.name == "PRIMARY":
+			detail.Constraints = append(detail.Constraints, TableConstraint{Name: idx.name, Type: "PRIMARY KEY", Columns: idx.columns})
+		case idx.unique:
+			detail.Constraints = append(detail.Constraints, TableConstraint{Name: idx.name, Type: "UNIQUE", Columns: idx.columns})
+		}
+	}
+	for _, fk := range t.fks {
+		table, column := fk.ReferencedTable, fk.ReferencedColumns[0]
+		detail.Constraints = append(detail.Constraints, TableConstraint{
+			Name: fk.Name, Type: "FOREIGN KEY", Columns: fk.Columns, ReferencedTable: &table, ReferencedColumn: &column,
+		})
+	}
+	detail.Triggers = append(detail.Triggers, t.triggers...)
+	return detail, nil
+}
+
+// demoAccounts 演示服务器的账号
+func demoAccounts() []UserAccount {
+	changed := "2025-03-02 10:15:00"
+	lifetime := int64(90)
+	return []UserAccount{
+		{User: "app", Host: "10.0.1.%", PasswordLastChanged: &changed, PasswordLifetime: &lifetime,
+			GlobalPrivileges: []string{}, SchemaPrivileges: map[string][]string{"analytics": {"INSERT", "SELECT"}, "shop": {"DELETE", "INSERT", "SELECT", "UPDATE"}}},
+		{User: "block_checker", Host: "%", GlobalPrivileges: []string{"PROCESS", "REPLICATION CLIENT", "SELECT"}, SchemaPrivileges: map[string][]string{}},
+		{User: "cron", Host: "10.0.2.%", GlobalPrivileges: []string{}, SchemaPrivileges: map[string][]string{"analytics": {"DELETE", "INSERT", "SELECT", "UPDATE"}}},
+		{User: "legacy_report", Host: "%", AccountLocked: true, GlobalPrivileges: []string{"SELECT"}, SchemaPrivileges: map[string][]string{}},
+		{User: "root", Host: "localhost", GlobalPrivileges: []string{"ALL PRIVILEGES"}, SchemaPrivileges: map[string][]string{}},
+	}
+}
+
+// GetUsers 获取演示服务器的账号和权限
+func (s *DemoStore) GetUsers(ctx context.Context) ([]UserAccount, error) {
+	return demoAccounts(), nil
+}
+
+// GetGrants 获取可访问演示数据库 (及可选的表) 的账号和权限
+func (s *DemoStore) GetGrants(ctx context.Context, databaseName, tableName string) ([]ObjectGrant, error) {
+	if _, err := s.schema(databaseName); err != nil {
+		return nil, err
+	}
+	var grants []ObjectGrant
+	for _, a := range demoAccounts() {
+		if len(a.GlobalPrivileges) > 0 {
+			grants = append(grants, ObjectGrant{User: a.User, Host: a.Host, Level: "global", Object: "*.*", Privileges: a.GlobalPrivileges})
+		}
+		if privileges, ok := a.SchemaPrivileges[databaseName]; ok {
+			grants = append(grants, ObjectGrant{User: a.User, Host: a.Host, Level: "schema", Object: databaseName + ".*", Privileges: privileges})
+		}
+	}
+	if databaseName == "analytics" && (tableName == "" || tableName == "daily_sales") {
+		grants = append(grants, ObjectGrant{User: "finance", Host: "10.0.3.%", Level: "table", Object: "analytics.daily_sales", Privileges: []string{"SELECT"}})
+	}
+	sort.SliceStable(grants, func(i, j int) bool {
+		if grants[i].User != grants[j].User {
+			return grants[i].User < grants[j].User
+		}
+		return grants[i].Host < grants[j].Host
+	})
+	return grants, nil
+}
+
+// GetBinlogStatus 获取演示服务器的二进制日志状态，当前文件随写入增长
+func (s *DemoStore) GetBinlogStatus(ctx context.Context) (*BinlogStatus, error) {
+	e := time.Since(s.started).Seconds()
+	status := &BinlogStatus{
+		Enabled:         true,
+		CurrentFile:     "binlog.000042",
+		Position:        157 + int64(2400*e),
+		GTIDMode:        "ON",
+		ExecutedGTIDSet: fmt.Sprintf("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-%d", 3400000+int64(180*e)),
+	}
+	for i, size := range []int64{1073742100, 1073741980, 1073743012, 1073741899} {
+		status.Files = append(status.Files, BinaryLogFile{Name: fmt.Sprintf("binlog.%06d", 38+i), Size: size})
+	}
+	status.Files = append(status.Files, BinaryLogFile{Name: status.CurrentFile, Size: status.Position})
+	for _, f := range status.Files {
+		status.TotalSize += f.Size
+	}
+	status.TotalSizeHuman = formatBytes(status.TotalSize)
+	return status, nil
+}
+
+// GetTableStats 获取所有演示表的行数和空间占用，键为 "库名.表名"
+func (s *DemoStore) GetTableStats(ctx context.Context) (map[string]TableStat, error) {
+	now := time.Now()
+	stats := make(map[string]TableStat)
+	for _, schema := range demoSchemas {
+		for i := range schema.tables {
+			stats[schema.name+"."+schema.tables[i].name] = s.stat(&schema.tables[i], now)
+		}
+	}
+	return stats, nil
+}
+
+// CountBlockedSessions 统计演示数据中处于锁等待的会话数
+func (s *DemoStore) CountBlockedSessions(ctx context.Context) (int, error) {
+	blocked := len(s.lockWaits(time.Now()))
+	if blocked > 0 {
+		GetIncidentRecorder().recordEvent(EventBlocking, fmt.Sprintf("%d 个会话处于锁等待", blocked), float64(blocked))
+	}
+	return blocked, nil
+}
+
+// GetReplicaLag 演示服务器不是从库
+func (s *DemoStore) GetReplicaLag(ctx context.Context) (*int64, error) {
+	return nil, nil
+}
+
+// SampleTable 对演示表做可复现的随机采样，单列自增主键的表按 seed 选取行，其他表取前 n 行
+func (s *DemoStore) SampleTable(ctx context.Context, databaseName, tableName string, n int, seed int64) (*TableSample, error) {
+	t, err := s.table(databaseName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		n = 100
+	}
+	if n > MaxSampleRows {
+		n = MaxSampleRows
+	}
+	now := time.Now()
+	total := s.rowCount(t, now)
+	if int64(n) > total {
+		n = int(total)
+	}
+
+	sample := &TableSample{Database: databaseName, Table: tableName, Seed: seed, Method: "head", Rows: [][]interface{}{}}
+	for _, c := range t.columns {
+		sample.Columns = append(sample.Columns, c.name)
+	}
+	ids := make([]int64, n)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	if t.columns[0].extra == "auto_increment" && total > int64(n) {
+		sample.Method = "pk-chunk"
+		rng := rand.New(rand.NewSource(seed))
+		seen := make(map[int64]bool, n)
+		for i := range ids {
+			id := 1 + rng.Int63n(total)
+			for seen[id] {
+				id = 1 + rng.Int63n(total)
+			}
+			seen[id] = true
+			ids[i] = id
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+	for _, id := range ids {
+		row := make([]interface{}, len(t.columns))
+		for i, c := range t.columns {
+			row[i] = s.value(t, c, id, total, now)
+		}
+		sample.Rows = append(sample.Rows, row)
+	}
+	sample.Masked = GetMasker().MaskRows(databaseName, tableName, sample.Columns, sample.Rows)
+	return sample, nil
+}
+
+// createTableDDL 生成与 SHOW CREATE TABLE 格式相同的建表语句
+func (t *demoTable) createTableDDL() string {
+	var lines []string
+	for _, c := range t.columns {
+		line := "  " + sqltools.QuoteIdentifier(c.name) + " " + c.typ
+		if !c.nullable {
+			line += " NOT NULL"
+		} else {
+			line += " DEFAULT NULL"
+		}
+		if c.extra != "" {
+			line += " " + strings.ToUpper(c.extra)
+		}
+		if c.comment != "" {
+			line += " COMMENT '" + c.comment + "'"
+		}
+		lines = append(lines, line)
+	}
+	quoteColumns := func(columns []string) string {
+		quoted := make([]string, len(columns))
+		for i, c := range columns {
+			quoted[i] = sqltools.QuoteIdentifier(c)
+		}
+		return strings.Join(quoted, ",")
+	}
+	for _, idx := range t.indexes {
+		switch {
+		case idx.name == "PRIMARY":
+			lines = append(lines, "  PRIMARY KEY ("+quoteColumns(idx.columns)+")")
+		case idx.unique:
+			lines = append(lines, "  UNIQUE KEY "+sqltools.QuoteIdentifier(idx.name)+" ("+quoteColumns(idx.columns)+")")
+		default:
+			lines = append(lines, "  KEY "+sqltools.QuoteIdentifier(idx.name)+" ("+quoteColumns(idx.columns)+")")
+		}
+	}
+	for _, fk := range t.fks {
+		lines = append(lines, fmt.Sprintf("  CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)", sqltools.QuoteIdentifier(fk.Name),
+			quoteColumns(fk.Columns), sqltools.QuoteIdentifier(fk.ReferencedTable), quoteColumns(fk.ReferencedColumns)))
+	}
+	ddl := "CREATE TABLE " + sqltools.QuoteIdentifier(t.name) + " (\n" + strings.Join(lines, ",\n") +
+		"\n) ENGINE=" + t.engine + " DEFAULT CHARSET=utf8mb4 COLLATE=" + demoCollation
+	if t.comment != "" {
+		ddl += " COMMENT='" + t.comment + "'"
+	}
+	return ddl
+}
+
+// ExportDDL 导出演示数据库的建表语句
+func (s *DemoStore) ExportDDL(ctx context.Context, databaseName string) (string, error) {
+	schema, err := s.schema(databaseName)
+	if err != nil {
+		return "", err
+	}
+	var statements []string
+	for i := range schema.tables {
+		t := &schema.tables[i]
+		qualified, err := sqltools.Qualify(t.createTableDDL(), databaseName)
+		if err != nil {
+			return "", fmt.Errorf("qualify %s: %w", t.name, err)
+		}
+		statements = append(statements, qualified+";")
+	}
+	return strings.Join(statements, "\n\n"), nil
+}
+
+// CaptureWaits 在 duration 内模拟等待事件，阻塞阶段锁等待占比更高
+func (s *DemoStore) CaptureWaits(ctx context.Context, duration time.Duration) (*WaitCapture, error) {
+	capture := &WaitCapture{StartedAt: time.Now()}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("capture waits: %w", ctx.Err())
+	case <-timer.C:
+	}
+	capture.Seconds = round2(time.Since(capture.StartedAt).Seconds())
+
+	lockMs := 40.0
+	if _, ok := s.blockingPhase(time.Now()); ok {
+		lockMs = 1000 * capture.Seconds * float64(len(s.lockWaits(time.Now())))
+	}
+	events := []WaitEvent{
+		{Event: "wait/io/table/sql/handler", Count: 5200, TotalMs: 310},
+		{Event: "wait/io/file/innodb/innodb_log_file", Count: 480, TotalMs: 220},
+		{Event: "wait/io/file/innodb/innodb_data_file", Count: 160, TotalMs: 95},
+		{Event: "wait/lock/table/sql/handler", Count: 2, TotalMs: lockMs},
+		{Event: "wait/synch/mutex/innodb/trx_sys_mutex", Count: 900, TotalMs: 12},
+		{Event: "wait/io/socket/sql/client_connection", Count: 2600, TotalMs: 64},
+	}
+	capture.Waits = s.rankEvents(events, capture.Seconds, waitCategory)
+	for _, w := range capture.Waits {
+		capture.TotalMs += w.TotalMs
+	}
+	byCategory := make(map[string]*WaitCategory)
+	for _, w := range capture.Waits {
+		c := byCategory[w.Category]
+		if c == nil {
+			c = &WaitCategory{Category: w.Category}
+			byCategory[w.Category] = c
+		}
+		c.Count += w.Count
+		c.TotalMs += w.TotalMs
+	}
+	capture.Categories = []WaitCategory{}
+	for _, c := range byCategory {
+		if capture.TotalMs > 0 {
+			c.Percent = round2(100 * c.TotalMs / capture.TotalMs)
+		}
+		c.TotalMs = round2(c.TotalMs)
+		capture.Categories = append(capture.Categories, *c)
+	}
+	sort.Slice(capture.Categories, func(i, j int) bool {
+		return capture.Categories[i].TotalMs > capture.Categories[j].TotalMs
+	})
+	capture.TotalMs = round2(capture.TotalMs)
+
+	stages := []WaitEvent{
+		{Event: "stage/sql/executing", Count: 1700, TotalMs: 420},
+		{Event: "stage/sql/waiting for handler commit", Count: 380, TotalMs: 160},
+		{Event: "stage/sql/Opening tables", Count: 1700, TotalMs: 35},
+		{Event: "stage/sql/freeing items", Count: 1700, TotalMs: 18},
+	}
+	capture.Stages = s.rankEvents(stages, capture.Seconds, func(string) string { return "stage" })
+	return capture, nil
+}
+
+// rankEvents 按采集时长缩放每秒的事件量，补全分类、平均耗时和占比后按总耗时降序
+func (s *DemoStore) rankEvents(events []WaitEvent, seconds float64, category func(string) string) []WaitEvent {
+	var total float64
+	for i := range events {
+		events[i].Category = category(events[i].Event)
+		events[i].Count = int64(float64(events[i].Count)*seconds) + 1
+		if events[i].Category != "lock" {
+			events[i].TotalMs *= seconds
+		}
+		total += events[i].TotalMs
+	}
+	for i := range events {
+		events[i].AvgMs = round2(events[i].TotalMs / float64(events[i].Count))
+		if total > 0 {
+			events[i].Percent = round2(100 * events[i].TotalMs / total)
+		}
+		events[i].TotalMs = round2(events[i].TotalMs)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].TotalMs > events[j].TotalMs })
+	return events
+}
+
+// GetNamedLocks 定时任务始终持有报表锁，阻塞阶段另有一个会话等待该锁
+func (s *DemoStore) GetNamedLocks(ctx context.Context) ([]NamedLock, error) {
+	now := time.Now()
+	lock := NamedLock{
+		Name:    "analytics.nightly_rollup",
+		Holders: []LockSession{{ProcessID: demoCronPID, User: "cron", Host: "10.0.2.5:41822", Time: int64(now.Sub(s.started).Seconds())%600 + 1}},
+		Waiters: []LockSession{},
+	}
+	if phase, ok := s.blockingPhase(now); ok {
+		lock.Waiters = append(lock.Waiters, LockSession{ProcessID: 1120, User: "cron", Host: "10.0.2.6:39110", Time: phase,
+			State: "User lock", Query: "SELECT GET_LOCK('analytics.nightly_rollup', 300)"})
+	}
+	return []NamedLock{lock}, nil
+}
+
+// GetGlobalStatus 读取演示服务器的全局状态计数器，不存在的变量不返回
+func (s *DemoStore) GetGlobalStatus(ctx context.Context, names []string) (map[string]int64, error) {
+	all := s.globalStatus(time.Now())
+	status := make(map[string]int64, len(names))
+	for _, name := range names {
+		if v, ok := all[name]; ok {
+			status[name] = v
+		}
+	}
+	return status, nil
+}
+
+// GetThreadUsage 获取演示服务器的连接数和 max_connections 的使用比例
+func (s *DemoStore) GetThreadUsage(ctx context.Context) (*ThreadUsage, error) {
+	now := time.Now()
+	status := s.globalStatus(now)
+	maxConnections, _ := strconv.ParseInt(demoGlobalVariables()["max_connections"], 10, 64)
+	usage := &ThreadUsage{
+		Timestamp:      now,
+		Connected:      status["Threads_connected"],
+		Running:        status["Threads_running"],
+		MaxConnections: maxConnections,
+	}
+	usage.UsagePercent = round2(100 * float64(usage.Connected) / float64(maxConnections))
+	return usage, nil
+}
+
+// GetEngineReport 按存储引擎统计演示表，并列出使用表级锁引擎的表
+func (s *DemoStore) GetEngineReport(ctx context.Context) (*EngineReport, error) {
+	now := time.Now()
+	report := &EngineReport{Engines: []EngineSummary{}, Databases: []DatabaseEngines{}, Flagged: []FlaggedTable{}}
+	engines := make(map[string]*EngineSummary)
+	for _, schema := range demoSchemas {
+		dbEngines := DatabaseEngines{Database: schema.name, Engines: make(map[string]int)}
+		for i := range schema.tables {
+			t := &schema.tables[i]
+			stat := s.stat(t, now)
+			size := stat.DataSize + stat.IndexSize
+			summary := engines[t.engine]
+			if summary == nil {
+				summary = &EngineSummary{Engine: t.engine}
+				engines[t.engine] = summary
+			}
+			summary.Tables++
+			summary.Size += size
+			dbEngines.Engines[t.engine]++
+			if reason, ok := tableLockEngines[strings.ToUpper(t.engine)]; ok {
+				report.Flagged = append(report.Flagged, FlaggedTable{
+					Database: schema.name, Table: t.name, Engine: t.engine, Rows: stat.Rows, SizeHuman: formatBytes(size), Reason: reason,
+				})
+			}
+		}
+		report.Databases = append(report.Databases, dbEngines)
+	}
+	for _, summary := range engines {
+		summary.SizeHuman = formatBytes(summary.Size)
+		report.Engines = append(report.Engines, *summary)
+	}
+	sort.Slice(report.Engines, func(i, j int) bool {
+		if report.Engines[i].Tables != report.Engines[j].Tables {
+			return report.Engines[i].Tables > report.Engines[j].Tables
+		}
+		return report.Engines[i].Engine < report.Engines[j].Engine
+	})
+	return report, nil
+}
+
+// FindValue 在演示数据中定位包含该值的表和列，每张表只检查前 demoSearchRows 行
+// 字符串列不区分大小写 (与 _ci 排序规则一致)，自增主键列按行数范围判断，配置了脱敏规则的列不检查
+func (s *DemoStore) FindValue(ctx context.Context, databaseName string, opts FindValueOptions) (*FindValueResult, error) {
+	if opts.Value == "" {
+		return nil, fmt.Errorf("%w: value must not be empty", ErrInvalidFindOptions)
+	}
+	if opts.Type == "" {
+		opts.Type = FindTypeString
+	}
+	switch opts.Type {
+	case FindTypeString, FindTypeNumeric, FindTypeTemporal, FindTypeAll:
+	default:
+		return nil, fmt.Errorf("%w: unknown column type filter %q", ErrInvalidFindOptions, opts.Type)
+	}
+	schema, err := s.schema(databaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	result := &FindValueResult{
+		Database: databaseName,
+		Value:    opts.Value,
+		Type:     opts.Type,
+		Contains: opts.Contains,
+		Matches:  []ValueLocation{},
+		Skipped:  []ValueLocation{},
+	}
+	needle := strings.ToLower(opts.Value)
+	for i := range schema.tables {
+		t := &schema.tables[i]
+		total := s.rowCount(t, started)
+		limit := total
+		if limit > demoSearchRows {
+			limit = demoSearchRows
+		}
+		for _, c := range t.columns {
+			category := columnCategory(c.typ)
+			if opts.Type != FindTypeAll && category != opts.Type {
+				continue
+			}
+			if GetMasker().ActionFor(databaseName, t.name, c.name) != "" {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			result.Checked++
+			loc := ValueLocation{Table: t.name, Column: c.name, Type: c.typ}
+			if category == FindTypeString {
+				loc.Collation = demoCollation
+			}
+
+			found := false
+			if c.extra == "auto_increment" {
+				id, err := strconv.ParseInt(opts.Value, 10, 64)
+				found = err == nil && id >= 1 && id <= total
+			}
+			for id := int64(1); !found && id <= limit; id++ {
+				v := s.value(t, c, id, total, started)
+				if v == nil {
+					continue
+				}
+				text := fmt.Sprint(v)
+				switch {
+				case category != FindTypeString:
+					found = text == opts.Value
+				case opts.Contains:
+					found = strings.Contains(strings.ToLower(text), needle)
+				default:
+					found = strings.EqualFold(text, opts.Value)
+				}
+			}
+			if found {
+				result.Matches = append(result.Matches, loc)
+			}
+		}
+	}
+	result.Elapsed = time.Since(started).Round(time.Millisecond).String()
+	return result, nil
+}
+
+// GetForeignKeys 获取演示数据库中各表的外键，键为表名
+func (s *DemoStore) GetForeignKeys(ctx context.Context, databaseName string) (map[string][]ForeignKey, error) {
+	schema, err := s.schema(databaseName)
+	if err != nil {
+		return nil, err
+	}
+	fks := make(map[string][]ForeignKey)
+	for _, t := range schema.tables {
+		if len(t.fks) > 0 {
+			fks[t.name] = append([]ForeignKey{}, t.fks...)
+		}
+	}
+	return fks, nil
+}
+
+// GetServerVersion 演示服务器的版本
+func (s *DemoStore) GetServerVersion(ctx context.Context) (*ServerVersion, error) {
+	variables := demoGlobalVariables()
+	return &ServerVersion{Version: variables["version"], Comment: variables["version_comment"], Flavor: FlavorMySQL}, nil
+}
+
+// GetComments 按表、列顺序返回演示数据库的注释
+func (s *DemoStore) GetComments(ctx context.Context, databaseName string) ([]CommentEntry, error) {
+	schema, err := s.schema(databaseName)
+	if err != nil {
+		return nil, err
+	}
+	entries := []CommentEntry{}
+	for _, t := range schema.tables {
+		entries = append(entries, CommentEntry{Database: databaseName, Table: t.name, Comment: t.comment})
+		for _, c := range t.columns {
+			entries = append(entries, CommentEntry{Database: databaseName, Table: t.name, Column: c.name, Type: c.typ, Comment: c.comment})
+		}
+	}
+	return entries, nil
+}
+
+// GetProcessList 获取演示服务器的会话，按持续时间降序
+func (s *DemoStore) GetProcessList(ctx context.Context) ([]Process, error) {
+	return s.processes(time.Now()), nil
+}
+
+// GetLockWaits 获取演示数据中的锁等待关系
+func (s *DemoStore) GetLockWaits(ctx context.Context) ([]LockWait, error) {
+	return s.lockWaits(time.Now()), nil
+}
+
+// GetInnoDBStatus 生成 SHOW ENGINE INNODB STATUS 格式的演示输出，阻塞阶段包含锁等待的事务
+func (s *DemoStore) GetInnoDBStatus(ctx context.Context) (string, error) {
+	now := time.Now()
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n=====================================\n%s INNODB MONITOR OUTPUT\n=====================================\n", now.Format("2006-01-02 15:04:05"))
+	b.WriteString("Per second averages calculated from the last 30 seconds\n")
+	b.WriteString("-----------------\nBACKGROUND THREAD\n-----------------\nsrv_master_thread loops: 412 srv_active, 0 srv_shutdown, 3281 srv_idle\n")
+	b.WriteString("----------\nSEMAPHORES\n----------\nOS WAIT ARRAY INFO: reservation count 1184\nOS WAIT ARRAY INFO: signal count 1102\n")
+	b.WriteString("------------------------\nLATEST DETECTED DEADLOCK\n------------------------\n")
+	fmt.Fprintf(&b, "%s 0x7f3a2c1f8700\n*** (1) TRANSACTION:\nTRANSACTION 421880, ACTIVE 2 sec starting index read\n", s.started.Add(-26*time.Hour).Format("2006-01-02 15:04:05"))
+	b.WriteString("mysql tables in use 1, locked 1\nLOCK WAIT 3 lock struct(s), heap size 1128, 2 row lock(s)\n")
+	b.WriteString("UPDATE inventory SET quantity = quantity - 1 WHERE product_id = 17\n")
+	b.WriteString("*** (2) TRANSACTION:\nTRANSACTION 421881, ACTIVE 2 sec starting index read\n")
+	b.WriteString("UPDATE inventory SET quantity = quantity - 2 WHERE product_id = 12\n*** WE ROLL BACK TRANSACTION (2)\n")
+	b.WriteString("------------\nTRANSACTIONS\n------------\n")
+	fmt.Fprintf(&b, "Trx id counter %d\nHistory list length 27\nLIST OF TRANSACTIONS FOR EACH SESSION:\n", 421937+int64(now.Sub(s.started).Seconds())*60)
+	if phase, ok := s.blockingPhase(now); ok {
+		fmt.Fprintf(&b, "---TRANSACTION 421937, ACTIVE %d sec\n2 lock struct(s), heap size 1128, 1 row lock(s), undo log entries 1\n", phase+30)
+		fmt.Fprintf(&b, "MySQL thread id %d, OS thread handle 139887, query id 882731 10.0.1.15 app\n", demoHolderPID)
+		for _, w := range s.lockWaits(now) {
+			fmt.Fprintf(&b, "---TRANSACTION %d, ACTIVE %d sec starting index read\nmysql tables in use 1, locked 1\n", 421940+w.WaitingPID, w.WaitSeconds)
+			fmt.Fprintf(&b, "LOCK WAIT 2 lock struct(s), heap size 1128, 1 row lock(s)\nMySQL thread id %d, OS thread handle 139901, query id 882790 updating\n%s\n", w.WaitingPID, w.WaitingQuery)
+			fmt.Fprintf(&b, "------- TRX HAS BEEN WAITING %d SEC FOR THIS LOCK TO BE GRANTED:\n", w.WaitSeconds)
+			fmt.Fprintf(&b, "RECORD LOCKS space id 12 page no 1409 n bits 112 index PRIMARY of table `shop`.`orders` trx id %d lock_mode X locks rec but not gap waiting\n", 421940+w.WaitingPID)
+		}
+	}
+	b.WriteString("--------\nFILE I/O\n--------\nPending normal aio reads: [0, 0, 0, 0] , aio writes: [0, 0, 0, 0] ,\n")
+	b.WriteString("----------------------\nBUFFER POOL AND MEMORY\n----------------------\n")
+	b.WriteString("Total large memory allocated 1098907648\nBuffer pool size   65536\nFree buffers       1024\nDatabase pages     63890\nBuffer pool hit rate 999 / 1000\n")
+	b.WriteString("--------------\nROW OPERATIONS\n--------------\n")
+	fmt.Fprintf(&b, "%d queries inside InnoDB, 0 queries in queue\n", s.globalStatus(now)["Threads_running"])
+	b.WriteString("----------------------------\nEND OF INNODB MONITOR OUTPUT\n============================\n")
+	return b.String(), nil
+}
+
+// GetGlobalVariables 获取演示服务器的全局变量
+func (s *DemoStore) GetGlobalVariables(ctx context.Context) (map[string]string, error) {
+	return demoGlobalVariables(), nil
+}
+
+// GetSessionDetail 获取演示会话的事务、持有和等待的锁以及会话变量
+func (s *DemoStore) GetSessionDetail(ctx context.Context, id int64) (*SessionDetail, error) {
+	now := time.Now()
+	detail := &SessionDetail{Locks: []SessionLock{}, Variables: []SessionVariable{}, Blocking: []LockWait{}, BlockedBy: []LockWait{}}
+	found := false
+	for _, p := range s.processes(now) {
+		if p.ID == id {
+			detail.Process, found = p, true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrSessionNotFound
+	}
+
+	for _, w := range s.lockWaits(now) {
+		if w.BlockingPID == id {
+			detail.Blocking = append(detail.Blocking, w)
+		}
+		if w.WaitingPID == id {
+			detail.BlockedBy = append(detail.BlockedBy, w)
+		}
+	}
+	data := strconv.Itoa(demoLockedOrder)
+	switch {
+	case id == demoHolderPID:
+		detail.Transaction = &SessionTransaction{
+			ID: "421937", State: "RUNNING", Started: now.Add(-time.Duration(detail.Process.Time) * time.Second), Seconds: detail.Process.Time,
+			IsolationLevel: "REPEATABLE READ", RowsLocked: 1, RowsModified: 1, TablesLocked: 1,
+		}
+		detail.Locks = append(detail.Locks, SessionLock{LockType: LockTypeRow, Object: "shop.orders", Index: "PRIMARY", Mode: "X,REC_NOT_GAP", Status: "GRANTED", Data: data})
+	case len(detail.BlockedBy) > 0:
+		detail.Transaction = &SessionTransaction{
+			ID: strconv.FormatInt(421940+id, 10), State: "LOCK WAIT", Started: now.Add(-time.Duration(detail.Process.Time) * time.Second), Seconds: detail.Process.Time,
+			IsolationLevel: "REPEATABLE READ", OperationState: "starting index read", RowsLocked: 1, TablesLocked: 1, Query: detail.Process.Info,
+		}
+		detail.Locks = append(detail.Locks, SessionLock{LockType: LockTypeRow, Object: "shop.orders", Index: "PRIMARY", Mode: "X,REC_NOT_GAP", Status: "WAITING", Data: data})
+	}
+
+	globals := demoGlobalVariables()
+	for _, name := range []string{"autocommit", "innodb_lock_wait_timeout", "sql_mode", "time_zone", "transaction_isolation", "wait_timeout"} {
+		v := SessionVariable{Name: name, Value: globals[name], Global: globals[name]}
+		if name == "transaction_isolation" && detail.Process.Isolation != "" {
+			v.Value = detail.Process.Isolation
+		}
+		if name == "autocommit" && id == demoHolderPID {
+			v.Value = "OFF"
+		}
+		v.Modified = v.Value != v.Global
+		detail.Variables = append(detail.Variables, v)
+	}
+	sort.SliceStable(detail.Variables, func(i, j int) bool {
+		if detail.Variables[i].Modified != detail.Variables[j].Modified {
+			return detail.Variables[i].Modified
+		}
+		return detail.Variables[i].Name < detail.Variables[j].Name
+	})
+	return detail, nil
+}
@@ -0,0 +1,300 @@
+package database
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/storage"
+)
+
+var (
+	// ErrDiagnosticsDisabled 未配置持久化存储或关闭了诊断包
+	ErrDiagnosticsDisabled = errors.New("diagnostics disabled")
+	// ErrDiagnosticsRunning 已有诊断包正在采集
+	ErrDiagnosticsRunning = errors.New("diagnostics capture already running")
+	// ErrBundleNotFound 诊断包不存在
+	ErrBundleNotFound = errors.New("bundle not found")
+)
+
+// 诊断包的触发原因
+const (
+	BundleReasonBlocking       = "blocking"
+	BundleReasonConnectionLost = "connection_lost"
+	BundleReasonManual         = "manual"
+)
+
+// diagnosticsTimeout 一次采集的超时
+const diagnosticsTimeout = 30 * time.Second
+
+// bundlesBucket 诊断包在持久化存储中的分组
+const bundlesBucket = "bundles"
+
+// bundleIDPattern 诊断包编号的格式
+var bundleIDPattern = regexp.MustCompile(`^\d{8}-\d{6}-[a-z_]+$`)
+
+// BundleInfo 诊断包信息
+type BundleInfo struct {
+	ID        string    `json:"id"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+	Size      int64     `json:"size"`
+}
+
+// bundleManifest 诊断包中的 manifest.json
+type bundleManifest struct {
+	ID        string            `json:"id"`
+	Reason    string            `json:"reason"`
+	Message   string            `json:"message"`
+	CreatedAt time.Time         `json:"created_at"`
+	Host      string            `json:"host,omitempty"`
+	Files     []string          `json:"files"`
+	Errors    map[string]string `json:"errors,omitempty"` // 采集失败的项目及原因
+}
+
+// Diagnostics 在故障发生时采集诊断包 (进程列表、锁等待、InnoDB 状态、全局变量、最近日志、连接状态历史)
+// 以 zip 格式保存在持久化存储中
+type Diagnostics struct {
+	mu      sync.Mutex
+	running bool
+	last    time.Time // 最近一次自动采集的时间，用于冷却
+	storage storage.Storage
+	cfg     *config.DiagnosticsConfig
+	store   Store
+	logger  *ComponentLogger
+}
+
+var (
+	diagnostics     *Diagnostics
+	diagnosticsOnce sync.Once
+)
+
+// GetDiagnostics 获取诊断包采集器实例
+func GetDiagnostics() *Diagnostics {
+	diagnosticsOnce.Do(func() {
+		diagnostics = &Diagnostics{
+			storage: persistentStorage,
+			cfg:     config.GetDiagnosticsConfig(),
+			store:   defaultStore,
+			logger:  GetDatabaseLogger().Component(ComponentScheduler),
+		}
+	})
+	return diagnostics
+}
+
+// Enabled 判断是否可以采集诊断包
+func (d *Diagnostics) Enabled() bool {
+	return d.cfg.Enabled && d.storage != nil
+}
+
+// Trigger 自动触发一次后台采集，冷却时间内或已有采集在进行时忽略
+func (d *Diagnostics) Trigger(reason, message string) {
+	if !d.Enabled() {
+		return
+	}
+	d.mu.Lock()
+	if d.running || (!d.last.IsZero() && time.Since(d.last) < d.cfg.Cooldown) {
+		d.mu.Unlock()
+		return
+	}
+	d.running = true
+	d.last = time.Now()
+	d.mu.Unlock()
+
+	go func() {
+		info, err := d.capture(reason, message)
+		if err != nil {
+			d.logger.Error("采集诊断包失败", err.Error())
+			return
+		}
+		d.logger.Warn(fmt.Sprintf("已采集诊断包 %s", info.ID), message)
+	}()
+}
+
+// Capture 立即采集一次诊断包，不受冷却时间限制
+func (d *Diagnostics) Capture(reason, message string) (*BundleInfo, error) {
+	if !d.Enabled() {
+		return nil, ErrDiagnosticsDisabled
+	}
+	d.mu.Lock()
+	if d.running {
+		d.mu.Unlock()
+		return nil, ErrDiagnosticsRunning
+	}
+	d.running = true
+	d.mu.Unlock()
+	return d.capture(reason, message)
+}
+
+// capture 采集并写入诊断包，调用前需将 running 置为 true
+// 单项采集失败 (例如数据库已断开) 时记录在 manifest 中，其余项目照常保存
+func (d *Diagnostics) capture(reason, message string) (*BundleInfo, error) {
+	defer func() {
+		d.mu.Lock()
+		d.running = false
+		d.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+	defer cancel()
+
+	now := time.Now()
+	manifest := bundleManifest{
+		ID:        now.Format("20060102-150405") + "-" + reason,
+		Reason:    reason,
+		Message:   message,
+		CreatedAt: now,
+		Errors:    make(map[string]string),
+	}
+	manifest.Host = ActiveHost()
+
+	files := make(map[string][]byte)
+	addJSON := func(name string, v interface{}, err error) {
+		if err != nil {
+			manifest.Errors[name] = err.Error()
+			return
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			manifest.Errors[name] = err.Error()
+			return
+		}
+		files[name] = data
+	}
+
+	processes, err := d.store.GetProcessList(ctx)
+	addJSON("processlist.json", processes, err)
+	waits, err := d.store.GetLockWaits(ctx)
+	addJSON("lock_waits.json", waits, err)
+	if status, err := d.store.GetInnoDBStatus(ctx); err != nil {
+		manifest.Errors["innodb_status.txt"] = err.Error()
+	} else {
+		files["innodb_status.txt"] = []byte(status)
+	}
+	variables, err := d.store.GetGlobalVariables(ctx)
+	addJSON("variables.json", variables, err)
+
+	// 日志中的连接信息包含明文密码，写入文件前去掉
+	entries := GetDatabaseLogger().GetRecentEntries(d.cfg.LogEntries)
+	for i := range entries {
+		if entries[i].ConnectionInfo != nil {
+			info := *entries[i].ConnectionInfo
+			info.Password = ""
+			entries[i].ConnectionInfo = &info
+		}
+	}
+	addJSON("logs.json", entries, nil)
+	state, since, transitions := GetReconnector().StateHistory()
+	addJSON("connection_state.json", map[string]interface{}{
+		"state":       state,
+		"since":       since,
+		"transitions": transitions,
+	}, nil)
+
+	for name := range files {
+		manifest.Files = append(manifest.Files, name)
+	}
+	sort.Strings(manifest.Files)
+
+	data, err := buildBundle(manifest, files)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.storage.Put(ctx, bundlesBucket, manifest.ID+".zip", data); err != nil {
+		return nil, fmt.Errorf("save bundle: %w", err)
+	}
+	d.prune(ctx)
+	return &BundleInfo{ID: manifest.ID, Reason: reason, CreatedAt: now, Size: int64(len(data))}, nil
+}
+
+// buildBundle 生成 zip 格式的诊断包
+func buildBundle(manifest bundleManifest, files map[string][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, data []byte) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: manifest.CreatedAt})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode manifest: %w", err)
+	}
+	if err := write("manifest.json", manifestData); err != nil {
+		return nil, fmt.Errorf("write bundle: %w", err)
+	}
+	for _, name := range manifest.Files {
+		if err := write(name, files[name]); err != nil {
+			return nil, fmt.Errorf("write bundle: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("write bundle: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// prune 超过保留数量时删除最早的诊断包
+func (d *Diagnostics) prune(ctx context.Context) {
+	if d.cfg.MaxBundles <= 0 {
+		return
+	}
+	bundles, err := d.List(ctx)
+	if err != nil || len(bundles) <= d.cfg.MaxBundles {
+		return
+	}
+	for _, b := range bundles[d.cfg.MaxBundles:] {
+		if err := d.storage.Delete(ctx, bundlesBucket, b.ID+".zip"); err != nil {
+			d.logger.Warn("删除旧诊断包失败", err.Error())
+		}
+	}
+}
+
+// List 列出已保存的诊断包，按时间倒序
+func (d *Diagnostics) List(ctx context.Context) ([]BundleInfo, error) {
+	bundles := []BundleInfo{}
+	if d.storage == nil {
+		return bundles, nil
+	}
+	items, err := d.storage.List(ctx, bundlesBucket)
+	if err != nil {
+		return nil, fmt.Errorf("list bundles: %w", err)
+	}
+	for _, item := range items {
+		id := strings.TrimSuffix(item.Key, ".zip")
+		if id == item.Key || !bundleIDPattern.MatchString(id) {
+			continue
+		}
+		created, err := time.ParseInLocation("20060102-150405", id[:15], time.Local)
+		if err != nil {
+			created = item.Modified
+		}
+		bundles = append(bundles, BundleInfo{ID: id, Reason: id[16:], CreatedAt: created, Size: item.Size})
+	}
+	sort.Slice(bundles, func(i, j int) bool { return bundles[i].ID > bundles[j].ID })
+	return bundles, nil
+}
+
+// Open 读取诊断包的内容
+func (d *Diagnostics) Open(ctx context.Context, id string) ([]byte, error) {
+	if d.storage == nil || !bundleIDPattern.MatchString(id) {
+		return nil, ErrBundleNotFound
+	}
+	data, err := d.storage.Get(ctx, bundlesBucket, id+".zip")
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrBundleNotFound
+	}
+	return data, err
+}
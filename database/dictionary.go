@@ -0,0 +1,226 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"golang.org/x/text/width"
+)
+
+// CommentEntry 数据字典条目，Column 为空时为表注释
+type CommentEntry struct {
+	Database string `json:"database"`
+	Table    string `json:"table"`
+	Column   string `json:"column,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Comment  string `json:"comment"`
+}
+
+// CommentMatch 数据字典搜索结果，MatchedOn 为命中的字段 (comment/name/pinyin/initials)
+type CommentMatch struct {
+	CommentEntry
+	MatchedOn string `json:"matched_on"`
+}
+
+// GetComments 获取数据库中全部表和列的注释
+func GetComments(ctx context.Context, databaseName string) ([]CommentEntry, error) {
+	return defaultStore.GetComments(ctx, databaseName)
+}
+
+// GetComments 按表、列顺序返回注释，没有注释的表和列也会返回以便导出时看出缺失
+func (s *MySQLStore) GetComments(ctx context.Context, databaseName string) ([]CommentEntry, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if err := checkDatabase(ctx, db, databaseName); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT TABLE_NAME, COALESCE(TABLE_COMMENT, '')
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TABLE_NAME`, databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("query table comments: %w", err)
+	}
+	tableComments := make(map[string]string)
+	var tables []string
+	for rows.Next() {
+		var table, comment string
+		if err := rows.Scan(&table, &comment); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan table comment: %w", err)
+		}
+		tables = append(tables, table)
+		tableComments[table] = comment
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query table comments: %w", err)
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT TABLE_NAME, COLUMN_NAME, COLUMN_TYPE, COALESCE(COLUMN_COMMENT, '')
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, ORDINAL_POSITION`, databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("query column comments: %w", err)
+	}
+	defer rows.Close()
+	columns := make(map[string][]CommentEntry)
+	for rows.Next() {
+		e := CommentEntry{Database: databaseName}
+		if err := rows.Scan(&e.Table, &e.Column, &e.Type, &e.Comment); err != nil {
+			return nil, fmt.Errorf("scan column comment: %w", err)
+		}
+		columns[e.Table] = append(columns[e.Table], e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query column comments: %w", err)
+	}
+
+	entries := []CommentEntry{}
+	for _, table := range tables {
+		entries = append(entries, CommentEntry{Database: databaseName, Table: table, Comment: tableComments[table]})
+		entries = append(entries, columns[table]...)
+	}
+	return entries, nil
+}
+
+// NormalizeComment 规范化注释用于搜索：全角转半角、转小写、合并空白
+func NormalizeComment(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(width.Fold.String(s))), " ")
+}
+
+// indexedComment 预先计算好搜索字段的条目
+type indexedComment struct {
+	entry    CommentEntry
+	comment  string // 规范化后的注释
+	name     string // 规范化后的 表.列
+	pinyin   string // 注释的全拼，未加载拼音表时为空
+	initials string // 注释的拼音首字母
+}
+
+// CommentIndex 数据库的注释索引
+type CommentIndex struct {
+	Database string
+	Built    time.Time
+	Entries  []CommentEntry
+	items    []indexedComment
+}
+
+// NewCommentIndex 为注释建立索引，pinyin 为 nil 时不支持拼音搜索
+func NewCommentIndex(databaseName string, entries []CommentEntry, pinyin *PinyinTable) *CommentIndex {
+	idx := &CommentIndex{Database: databaseName, Built: time.Now(), Entries: entries}
+	for _, e := range entries {
+		item := indexedComment{
+			entry:   e,
+			comment: NormalizeComment(e.Comment),
+			name:    NormalizeComment(e.Table + "." + e.Column),
+		}
+		if pinyin != nil && item.comment != "" {
+			item.pinyin, item.initials = pinyin.Transliterate(item.comment)
+		}
+		idx.items = append(idx.items, item)
+	}
+	return idx
+}
+
+// Search 按注释、表名列名、拼音全拼和首字母依次匹配，结果按该顺序排列，limit 为 0 时不限制
+func (idx *CommentIndex) Search(query string, limit int) []CommentMatch {
+	q := NormalizeComment(query)
+	compact := strings.ReplaceAll(q, " ", "")
+	matches := []CommentMatch{}
+	if q == "" {
+		return matches
+	}
+
+	fields := []struct {
+		name  string
+		value func(indexedComment) string
+		query string
+	}{
+		{"comment", func(i indexedComment) string { return i.comment }, q},
+		{"name", func(i indexedComment) string { return i.name }, q},
+		{"pinyin", func(i indexedComment) string { return i.pinyin }, compact},
+		{"initials", func(i indexedComment) string { return i.initials }, compact},
+	}
+	seen := make(map[int]bool)
+	for _, field := range fields {
+		for i, item := range idx.items {
+			if seen[i] {
+				continue
+			}
+			value := field.value(item)
+			if value == "" || !strings.Contains(value, field.query) {
+				continue
+			}
+			seen[i] = true
+			matches = append(matches, CommentMatch{CommentEntry: item.entry, MatchedOn: field.name})
+			if limit > 0 && len(matches) >= limit {
+				return matches
+			}
+		}
+	}
+	return matches
+}
+
+// commentIndexCache 各数据库的注释索引缓存
+var commentIndexCache = struct {
+	sync.Mutex
+	entries map[string]*CommentIndex
+}{entries: make(map[string]*CommentIndex)}
+
+// GetCommentIndex 获取数据库的注释索引，缓存超过 DICTIONARY_CACHE_TTL 或 refresh 为 true 时重建
+func GetCommentIndex(ctx context.Context, databaseName string, refresh bool) (*CommentIndex, error) {
+	ttl := config.GetDictionaryConfig().CacheTTL
+	commentIndexCache.Lock()
+	idx, ok := commentIndexCache.entries[databaseName]
+	commentIndexCache.Unlock()
+	if ok && !refresh && time.Since(idx.Built) < ttl {
+		return idx, nil
+	}
+
+	entries, err := defaultStore.GetComments(ctx, databaseName)
+	if err != nil {
+		return nil, err
+	}
+	idx = NewCommentIndex(databaseName, entries, getPinyinTable())
+
+	commentIndexCache.Lock()
+	commentIndexCache.entries[databaseName] = idx
+	commentIndexCache.Unlock()
+	return idx, nil
+}
+
+// DictionaryMarkdown 将数据字典导出为 Markdown，每张表一节
+func DictionaryMarkdown(databaseName string, entries []CommentEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# 数据字典 %s\n", databaseName)
+	for _, e := range entries {
+		if e.Column == "" {
+			fmt.Fprintf(&b, "\n## %s\n\n", e.Table)
+			if e.Comment != "" {
+				fmt.Fprintf(&b, "%s\n\n", markdownCell(e.Comment))
+			}
+			b.WriteString("| 列 | 类型 | 注释 |\n|---|---|---|\n")
+			continue
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", markdownCell(e.Column), markdownCell(e.Type), markdownCell(e.Comment))
+	}
+	return b.String()
+}
+
+// markdownCell 转义表格单元格中的竖线和换行
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ").Replace(s)
+}
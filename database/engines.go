@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// tableLockEngines 只支持表级锁的存储引擎及说明
+var tableLockEngines = map[string]string{
+	"MYISAM":     "表级锁，写入会阻塞同表的所有读写；不支持事务，崩溃后可能需要修复",
+	"MEMORY":     "表级锁，重启后数据丢失",
+	"HEAP":       "表级锁，重启后数据丢失",
+	"MRG_MYISAM": "表级锁 (MERGE 表锁定全部底层 MyISAM 表)",
+	"ARCHIVE":    "表级锁，仅支持插入和查询",
+	"CSV":        "表级锁，不支持索引",
+}
+
+// EngineSummary 单个存储引擎的表数和空间占用
+type EngineSummary struct {
+	Engine    string `json:"engine"`
+	Tables    int    `json:"tables"`
+	Size      int64  `json:"size"`
+	SizeHuman string `json:"size_human"`
+}
+
+// DatabaseEngines 单个数据库内各存储引擎的表数
+type DatabaseEngines struct {
+	Database string         `json:"database"`
+	Engines  map[string]int `json:"engines"`
+}
+
+// FlaggedTable 使用表级锁引擎的表
+type FlaggedTable struct {
+	Database  string `json:"database"`
+	Table     string `json:"table"`
+	Engine    string `json:"engine"`
+	Rows      int64  `json:"rows"`
+	SizeHuman string `json:"size_human"`
+	Reason    string `json:"reason"`
+}
+
+// EngineReport 存储引擎分布报告
+type EngineReport struct {
+	Engines   []EngineSummary   `json:"engines"`
+	Databases []DatabaseEngines `json:"databases"`
+	Flagged   []FlaggedTable    `json:"flagged"`
+}
+
+// GetEngineReport 获取存储引擎分布报告
+func GetEngineReport(ctx context.Context) (*EngineReport, error) {
+	return defaultStore.GetEngineReport(ctx)
+}
+
+// GetEngineReport 按存储引擎统计用户数据库中的表，并列出使用表级锁引擎的表
+func (s *MySQLStore) GetEngineReport(ctx context.Context) (*EngineReport, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		SELECT TABLE_SCHEMA, TABLE_NAME, COALESCE(ENGINE, ''),
+		       COALESCE(TABLE_ROWS, 0), COALESCE(DATA_LENGTH, 0) + COALESCE(INDEX_LENGTH, 0)
+		FROM information_schema.TABLES
+		WHERE TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TABLE_SCHEMA, TABLE_NAME`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query tables: %w", err)
+	}
+	defer rows.Close()
+
+	report := &EngineReport{Engines: []EngineSummary{}, Databases: []DatabaseEngines{}, Flagged: []FlaggedTable{}}
+	engines := make(map[string]*EngineSummary)
+	databases := make(map[string]int)
+	for rows.Next() {
+		var dbName, table, engine string
+		var tableRows, size int64
+		if err := rows.Scan(&dbName, &table, &engine, &tableRows, &size); err != nil {
+			return nil, fmt.Errorf("scan table: %w", err)
+		}
+		if IsSystemDatabase(dbName) {
+			continue
+		}
+
+		summary := engines[engine]
+		if summary == nil {
+			summary = &EngineSummary{Engine: engine}
+			engines[engine] = summary
+		}
+		summary.Tables++
+		summary.Size += size
+
+		i, ok := databases[dbName]
+		if !ok {
+			i = len(report.Databases)
+			databases[dbName] = i
+			report.Databases = append(report.Databases, DatabaseEngines{Database: dbName, Engines: make(map[string]int)})
+		}
+		report.Databases[i].Engines[engine]++
+
+		if reason, ok := tableLockEngines[strings.ToUpper(engine)]; ok {
+			report.Flagged = append(report.Flagged, FlaggedTable{
+				Database:  dbName,
+				Table:     table,
+				Engine:    engine,
+				Rows:      tableRows,
+				SizeHuman: formatBytes(size),
+				Reason:    reason,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tables: %w", err)
+	}
+
+	for _, summary := range engines {
+		summary.SizeHuman = formatBytes(summary.Size)
+		report.Engines = append(report.Engines, *summary)
+	}
+	sort.Slice(report.Engines, func(i, j int) bool {
+		if report.Engines[i].Tables != report.Engines[j].Tables {
+			return report.Engines[i].Tables > report.Engines[j].Tables
+		}
+		return report.Engines[i].Engine < report.Engines[j].Engine
+	})
+	return report, nil
+}
+
+// EngineNames 报告中出现的存储引擎名称，按表数降序
+func (r *EngineReport) EngineNames() []string {
+	names := make([]string, len(r.Engines))
+	for i, e := range r.Engines {
+		names[i] = e.Engine
+	}
+	return names
+}
@@ -2,9 +2,12 @@ package database
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
 )
 
 // ErrorPattern 错误模式
@@ -36,6 +39,8 @@ type ErrorAnalyzer struct {
 	summaries      map[string]*ErrorSummary // key: type_code
 	maxExamples    int
 	logger         *DatabaseLogger
+	config         *config.ErrorAnalysisConfig
+	stop           chan struct{} // 后台合并任务的停止信号
 }
 
 var (
@@ -46,16 +51,22 @@ var (
 // GetErrorAnalyzer 获取错误分析器实例
 func GetErrorAnalyzer() *ErrorAnalyzer {
 	analyzerOnce.Do(func() {
-		errorAnalyzer = &ErrorAnalyzer{
-			patterns:    initializeErrorPatterns(),
-			summaries:   make(map[string]*ErrorSummary),
-			maxExamples: 10,
-			logger:      GetDatabaseLogger(),
-		}
+		errorAnalyzer = newErrorAnalyzer(GetDatabaseLogger())
 	})
 	return errorAnalyzer
 }
 
+// newErrorAnalyzer 创建错误分析器，分析结果写入指定的日志管理器
+func newErrorAnalyzer(logger *DatabaseLogger) *ErrorAnalyzer {
+	return &ErrorAnalyzer{
+		patterns:    initializeErrorPatterns(),
+		summaries:   make(map[string]*ErrorSummary),
+		maxExamples: 10,
+		logger:      logger,
+		config:      config.GetErrorAnalysisConfig(),
+	}
+}
+
 // initializeErrorPatterns 初始化错误模式
 func initializeErrorPatterns() []ErrorPattern {
 	return []ErrorPattern{
@@ -68,7 +79,7 @@ func initializeErrorPatterns() []ErrorPattern {
 			Severity:   4,
 		},
 		{
-			Keywords:   []string{"no route to host", "网络不可达"},
+			Keywords:   []string{"no route to host", "no such host", "网络不可达"},
 			Type:       ErrorTypeNetwork,
 			Code:       "NET_002",
 			Cause:      "网络路由问题",
@@ -144,7 +155,7 @@ func (ea *ErrorAnalyzer) AnalyzeError(err error, retryCount int) *ErrorDetails {
 		Message:    errorMsg,
 		Cause:      pattern.Cause,
 		Suggestion: ea.enhanceSuggestion(pattern, retryCount),
-		Timestamp:  now.Format("2006-01-02 15:04:05"),
+		Timestamp:  config.FormatAPITime(now),
 		RetryCount: retryCount,
 	}
 
@@ -228,7 +239,7 @@ func (ea *ErrorAnalyzer) updateErrorSummary(details *ErrorDetails, errorMsg stri
 	summary.LastSeen = timestamp
 	
 	// 按小时统计频率
-	hourKey := timestamp.Format("2006-01-02-15")
+	hourKey := timestamp.Format(hourKeyFormat)
 	summary.FrequencyData[hourKey]++
 	
 	// 添加错误示例（避免重复）
@@ -365,6 +376,7 @@ func (ea *ErrorAnalyzer) GetErrorTrends() map[string]interface{} {
 		"total_errors": 0,
 		"error_types":  make(map[string]int),
 		"hourly_data":  make(map[string]int),
+		"daily_data":   make(map[string]int),
 		"resolved_count": 0,
 	}
 	
@@ -372,6 +384,7 @@ func (ea *ErrorAnalyzer) GetErrorTrends() map[string]interface{} {
 	resolvedCount := 0
 	errorTypes := make(map[string]int)
 	hourlyData := make(map[string]int)
+	dailyData := make(map[string]int)
 	
 	for _, summary := range ea.summaries {
 		totalErrors += summary.Count
@@ -381,15 +394,114 @@ func (ea *ErrorAnalyzer) GetErrorTrends() map[string]interface{} {
 			resolvedCount++
 		}
 		
-		for hour, count := range summary.FrequencyData {
-			hourlyData[hour] += count
+		for key, count := range summary.FrequencyData {
+			// 已合并为按天统计的数据只计入 daily_data
+			if len(key) == len(hourKeyFormat) {
+				hourlyData[key] += count
+			}
 		}
+		dailyCounts(summary.FrequencyData, dailyData)
 	}
 	
 	trends["total_errors"] = totalErrors
 	trends["error_types"] = errorTypes
 	trends["hourly_data"] = hourlyData
+	trends["daily_data"] = dailyData
 	trends["resolved_count"] = resolvedCount
+	trends["anomalies"] = ea.detectAnomalies(time.Now())
 	
 	return trends
-}
\ No newline at end of file
+}
+
+// hourKeyFormat FrequencyData 中小时桶的键格式
+const hourKeyFormat = "2006-01-02-15"
+
+// minBaselineHours 基线至少需要的历史小时数，不足时不做异常判定
+const minBaselineHours = 6
+
+// ErrorAnomaly 当前小时错误数显著偏离基线的错误代码
+type ErrorAnomaly struct {
+	Type   ErrorType `json:"type"`
+	Code   string    `json:"code"`
+	Hour   string    `json:"hour"`
+	Count  int       `json:"count"`  // 当前小时的错误数
+	Mean   float64   `json:"mean"`   // 基线小时均值
+	StdDev float64   `json:"stddev"` // 基线小时标准差
+	Score  float64   `json:"score"`  // 偏离的标准差倍数
+}
+
+// DetectAnomalies 检测当前小时错误数超过基线均值 Sigma 个标准差的错误代码
+func (ea *ErrorAnalyzer) DetectAnomalies() []ErrorAnomaly {
+	ea.mu.RLock()
+	defer ea.mu.RUnlock()
+	return ea.detectAnomalies(time.Now())
+}
+
+// detectAnomalies 检测异常，调用方需持有读锁
+func (ea *ErrorAnalyzer) detectAnomalies(now time.Time) []ErrorAnomaly {
+	anomalies := []ErrorAnomaly{}
+	currentHour := truncateHour(now)
+	hourKey := currentHour.Format(hourKeyFormat)
+
+	for _, summary := range ea.summaries {
+		if summary.Resolved {
+			continue
+		}
+		count := summary.FrequencyData[hourKey]
+		if count < ea.config.MinCount {
+			continue
+		}
+		mean, stddev, hours := hourlyBaseline(summary, currentHour, ea.config.Window)
+		if hours < minBaselineHours {
+			continue
+		}
+
+		// 基线完全平稳时标准差为 0，以 1 为下限避免任何波动都被判为异常
+		score := (float64(count) - mean) / math.Max(stddev, 1)
+		if score > ea.config.Sigma {
+			anomalies = append(anomalies, ErrorAnomaly{
+				Type:   summary.Type,
+				Code:   summary.Code,
+				Hour:   hourKey,
+				Count:  count,
+				Mean:   mean,
+				StdDev: stddev,
+				Score:  score,
+			})
+		}
+	}
+	return anomalies
+}
+
+// hourlyBaseline 统计当前小时之前窗口内每小时错误数的均值和标准差，没有记录的小时计为 0
+// 窗口起点不早于该错误首次出现的小时，返回参与统计的小时数
+func hourlyBaseline(summary *ErrorSummary, currentHour time.Time, window time.Duration) (float64, float64, int) {
+	start := currentHour.Add(-window)
+	if first := truncateHour(summary.FirstSeen); first.After(start) {
+		start = first
+	}
+
+	var counts []float64
+	for h := start; h.Before(currentHour); h = h.Add(time.Hour) {
+		counts = append(counts, float64(summary.FrequencyData[h.Format(hourKeyFormat)]))
+	}
+	if len(counts) == 0 {
+		return 0, 0, 0
+	}
+
+	var sum float64
+	for _, c := range counts {
+		sum += c
+	}
+	mean := sum / float64(len(counts))
+	var variance float64
+	for _, c := range counts {
+		variance += (c - mean) * (c - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(counts))), len(counts)
+}
+
+// truncateHour 截断到本地时间的整点，与 FrequencyData 的键保持一致
+func truncateHour(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+}
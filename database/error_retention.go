@@ -0,0 +1,104 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// dayKeyFormat FrequencyData 中合并后按天统计的键格式
+const dayKeyFormat = "2006-01-02"
+
+// StartCompaction 按指定间隔合并、清理错误频率数据，interval 为 0 时不启动
+func (ea *ErrorAnalyzer) StartCompaction(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ea.mu.Lock()
+	if ea.stop != nil {
+		ea.mu.Unlock()
+		return
+	}
+	ea.stop = make(chan struct{})
+	stop := ea.stop
+	ea.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ea.Compact(time.Now())
+			}
+		}
+	}()
+}
+
+// StopCompaction 停止后台合并
+func (ea *ErrorAnalyzer) StopCompaction() {
+	ea.mu.Lock()
+	defer ea.mu.Unlock()
+	if ea.stop != nil {
+		close(ea.stop)
+		ea.stop = nil
+	}
+}
+
+// Compact 将超过按小时保留时长的整天数据合并为按天统计，并删除超过总保留时长的数据
+// 错误总数 Count 不受影响，返回合并和删除的桶数
+func (ea *ErrorAnalyzer) Compact(now time.Time) (merged, trimmed int) {
+	ea.mu.Lock()
+	defer ea.mu.Unlock()
+
+	hourlyCutoff := truncateDay(now.Add(-ea.config.HourlyRetention))
+	retentionCutoff := truncateDay(now.Add(-ea.config.Retention))
+
+	for _, summary := range ea.summaries {
+		for key, count := range summary.FrequencyData {
+			if len(key) != len(hourKeyFormat) {
+				continue
+			}
+			hour, err := time.ParseInLocation(hourKeyFormat, key, now.Location())
+			if err != nil || !hour.Before(hourlyCutoff) {
+				continue
+			}
+			delete(summary.FrequencyData, key)
+			summary.FrequencyData[hour.Format(dayKeyFormat)] += count
+			merged++
+		}
+
+		for key := range summary.FrequencyData {
+			if len(key) != len(dayKeyFormat) {
+				continue
+			}
+			day, err := time.ParseInLocation(dayKeyFormat, key, now.Location())
+			if err != nil || !day.Before(retentionCutoff) {
+				continue
+			}
+			delete(summary.FrequencyData, key)
+			trimmed++
+		}
+	}
+
+	if merged > 0 || trimmed > 0 {
+		ea.logger.Debug("错误频率数据已压缩", fmt.Sprintf("合并 %d 个小时桶，删除 %d 个过期日桶", merged, trimmed))
+	}
+	return merged, trimmed
+}
+
+// truncateDay 截断到本地时间的零点
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// dailyCounts 汇总各天的错误数，包含按小时和已合并为按天的数据
+func dailyCounts(frequency map[string]int, into map[string]int) {
+	for key, count := range frequency {
+		if len(key) == len(hourKeyFormat) {
+			key = key[:len(dayKeyFormat)]
+		}
+		into[key] += count
+	}
+}
@@ -2,9 +2,14 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+
+	"github.com/go-sql-driver/mysql"
 )
 
 // DatabaseInfo 数据库信息
@@ -29,6 +34,9 @@ type TableField struct {
 	Default    *string `json:"default"`
 	Extra      string  `json:"extra"`
 	Comment    string  `json:"comment"`
+	// Generated 生成列类型 (VIRTUAL/STORED)，普通字段为空
+	Generated            string `json:"generated,omitempty"`
+	GenerationExpression string `json:"generation_expression,omitempty"`
 }
 
 // TableIndex 索引信息
@@ -36,6 +44,23 @@ type TableIndex struct {
 	Name    string   `json:"name"`
 	Columns []string `json:"columns"`
 	Unique  bool     `json:"unique"`
+	Type    string   `json:"type,omitempty"` // BTREE/FULLTEXT/SPATIAL/HASH
+	// SubParts 与 Columns 一一对应的前缀长度，0 表示整列
+	SubParts    []int64 `json:"sub_parts,omitempty"`
+	Cardinality int64   `json:"cardinality"`
+	Visible     bool    `json:"visible"`
+}
+
+// ColumnLabels 带前缀长度的字段展示名，如 name(10)
+func (idx TableIndex) ColumnLabels() []string {
+	labels := make([]string, len(idx.Columns))
+	for i, col := range idx.Columns {
+		labels[i] = col
+		if i < len(idx.SubParts) && idx.SubParts[i] > 0 {
+			labels[i] = fmt.Sprintf("%s(%d)", col, idx.SubParts[i])
+		}
+	}
+	return labels
 }
 
 // TableConstraint 约束信息
@@ -47,27 +72,62 @@ type TableConstraint struct {
 	ReferencedColumn *string  `json:"referenced_column,omitempty"`
 }
 
+// TableTrigger 表上的触发器
+type TableTrigger struct {
+	Name      string `json:"name"`
+	Timing    string `json:"timing"` // BEFORE/AFTER
+	Event     string `json:"event"`  // INSERT/UPDATE/DELETE
+	Statement string `json:"statement"`
+	Definer   string `json:"definer"`
+}
+
 // TableDetail 表结构详情
 type TableDetail struct {
 	Fields      []TableField      `json:"fields"`
 	Indexes     []TableIndex      `json:"indexes"`
 	Constraints []TableConstraint `json:"constraints"`
+	Triggers    []TableTrigger    `json:"triggers"`
 }
 
 // GetDatabases 获取数据库列表
-func GetDatabases() ([]DatabaseInfo, error) {
+func GetDatabases(ctx context.Context) ([]DatabaseInfo, error) {
+	return defaultStore.GetDatabases(ctx)
+}
+
+// GetDatabases 获取数据库列表，并发的相同请求共享一次查询
+func (s *MySQLStore) GetDatabases(ctx context.Context) ([]DatabaseInfo, error) {
+	v, err := s.flight.Do(ctx, "databases", func(ctx context.Context) (interface{}, error) {
+		// 共享的查询不受发起者取消的影响，使用存储自身的查询超时
+		ctx, cancel := s.withQueryTimeout(ctx)
+		defer cancel()
+		release, err := s.acquireMetadataSlot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return s.getDatabases(ctx)
+	})
+	databases, _ := v.([]DatabaseInfo)
+	return databases, err
+}
+
+// getDatabases 查询数据库列表
+func (s *MySQLStore) getDatabases(ctx context.Context) ([]DatabaseInfo, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
 	if db == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("check connection: %v", err)
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("check connection: %w", err)
 	}
 
 	var databases []DatabaseInfo
 	query := "SELECT SCHEMA_NAME FROM information_schema.SCHEMATA"
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("query databases: %v", err)
+		return nil, fmt.Errorf("query databases: %w", err)
 	}
 	defer func() {
 		if closeErr := rows.Close(); closeErr != nil {
@@ -80,7 +140,7 @@ func GetDatabases() ([]DatabaseInfo, error) {
 		if err := rows.Scan(&dbName); err != nil {
 			continue
 		}
-		if !isSystemDatabase(dbName) {
+		if !IsSystemDatabase(dbName) {
 			databases = append(databases, DatabaseInfo{Name: dbName})
 		}
 	}
@@ -88,12 +148,37 @@ func GetDatabases() ([]DatabaseInfo, error) {
 }
 
 // GetTables 获取指定数据库的表列表
-func GetTables(databaseName string) ([]TableInfo, error) {
+func GetTables(ctx context.Context, databaseName string) ([]TableInfo, error) {
+	return defaultStore.GetTables(ctx, databaseName)
+}
+
+// GetTables 获取指定数据库的表列表，并发的相同请求共享一次查询
+func (s *MySQLStore) GetTables(ctx context.Context, databaseName string) ([]TableInfo, error) {
+	v, err := s.flight.Do(ctx, flightKey("tables", databaseName), func(ctx context.Context) (interface{}, error) {
+		// 共享的查询不受发起者取消的影响，使用存储自身的查询超时
+		ctx, cancel := s.withQueryTimeout(ctx)
+		defer cancel()
+		release, err := s.acquireMetadataSlot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return s.getTables(ctx, databaseName)
+	})
+	tables, _ := v.([]TableInfo)
+	return tables, err
+}
+
+// getTables 查询指定数据库的表列表
+func (s *MySQLStore) getTables(ctx context.Context, databaseName string) ([]TableInfo, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
 	if db == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("check connection: %v", err)
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("check connection: %w", err)
 	}
 
 	var tables []TableInfo
@@ -107,9 +192,9 @@ func GetTables(databaseName string) ([]TableInfo, error) {
 		WHERE t.TABLE_SCHEMA = ?
 		AND t.TABLE_TYPE = 'BASE TABLE'
 		ORDER BY t.TABLE_NAME`
-	rows, err := db.Query(query, databaseName)
+	rows, err := queryHot(ctx, db, query, databaseName)
 	if err != nil {
-		return nil, fmt.Errorf("query tables: %v", err)
+		return nil, fmt.Errorf("query tables: %w", err)
 	}
 	defer func() {
 		if closeErr := rows.Close(); closeErr != nil {
@@ -128,24 +213,50 @@ func GetTables(databaseName string) ([]TableInfo, error) {
 }
 
 // GetTableDetail 获取表结构详细信息
-func GetTableDetail(databaseName, tableName string) (*TableDetail, error) {
+func GetTableDetail(ctx context.Context, databaseName, tableName string) (*TableDetail, error) {
+	return defaultStore.GetTableDetail(ctx, databaseName, tableName)
+}
+
+// GetTableDetail 获取表结构详细信息，并发的相同请求共享一次查询
+func (s *MySQLStore) GetTableDetail(ctx context.Context, databaseName, tableName string) (*TableDetail, error) {
+	v, err := s.flight.Do(ctx, flightKey("detail", databaseName, tableName), func(ctx context.Context) (interface{}, error) {
+		// 共享的查询不受发起者取消的影响，使用存储自身的查询超时
+		ctx, cancel := s.withQueryTimeout(ctx)
+		defer cancel()
+		release, err := s.acquireMetadataSlot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return s.getTableDetail(ctx, databaseName, tableName)
+	})
+	detail, _ := v.(*TableDetail)
+	return detail, err
+}
+
+// getTableDetail 查询表结构详细信息
+func (s *MySQLStore) getTableDetail(ctx context.Context, databaseName, tableName string) (*TableDetail, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
 	if db == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("check connection: %v", err)
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("check connection: %w", err)
 	}
 
 	// 字段信息
 	fieldQuery := `
-		SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY, COLUMN_DEFAULT, EXTRA, COLUMN_COMMENT
+		SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY, COLUMN_DEFAULT, EXTRA, COLUMN_COMMENT,
+			COALESCE(GENERATION_EXPRESSION, '')
 		FROM information_schema.COLUMNS
 		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
 		ORDER BY ORDINAL_POSITION
 	`
-	fieldRows, err := db.Query(fieldQuery, databaseName, tableName)
+	fieldRows, err := queryHot(ctx, db, fieldQuery, databaseName, tableName)
 	if err != nil {
-		return nil, fmt.Errorf("query fields: %v", err)
+		return nil, fmt.Errorf("query fields: %w", err)
 	}
 	defer fieldRows.Close()
 
@@ -153,49 +264,74 @@ func GetTableDetail(databaseName, tableName string) (*TableDetail, error) {
 	for fieldRows.Next() {
 		var f TableField
 		var isNullable, columnKey string
-		if err := fieldRows.Scan(&f.Name, &f.Type, &isNullable, &columnKey, &f.Default, &f.Extra, &f.Comment); err != nil {
+		if err := fieldRows.Scan(&f.Name, &f.Type, &isNullable, &columnKey, &f.Default, &f.Extra, &f.Comment, &f.GenerationExpression); err != nil {
 			continue
 		}
 		f.IsNullable = isNullable == "YES"
 		f.IsPrimary = columnKey == "PRI"
+		if f.GenerationExpression != "" {
+			// EXTRA 形如 "VIRTUAL GENERATED" 或 "STORED GENERATED" (MariaDB 为 "PERSISTENT GENERATED")
+			f.Generated = "VIRTUAL"
+			if extra := strings.ToUpper(f.Extra); strings.Contains(extra, "STORED") || strings.Contains(extra, "PERSISTENT") {
+				f.Generated = "STORED"
+			}
+		}
 		fields = append(fields, f)
 	}
 
 	// 索引信息
-	indexQuery := `
-		SELECT INDEX_NAME, GROUP_CONCAT(COLUMN_NAME ORDER BY SEQ_IN_INDEX), NON_UNIQUE
-		FROM information_schema.STATISTICS
-		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
-		GROUP BY INDEX_NAME, NON_UNIQUE
+	indexes, err := queryIndexes(ctx, db, databaseName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	// 约束信息
+	constraints, err := queryConstraints(ctx, db, databaseName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	// 触发器信息
+	triggerQuery := `
+		SELECT TRIGGER_NAME, ACTION_TIMING, EVENT_MANIPULATION, ACTION_STATEMENT, DEFINER
+		FROM information_schema.TRIGGERS
+		WHERE EVENT_OBJECT_SCHEMA = ? AND EVENT_OBJECT_TABLE = ?
+		ORDER BY ACTION_TIMING, EVENT_MANIPULATION, ACTION_ORDER
 	`
-	indexRows, err := db.Query(indexQuery, databaseName, tableName)
+	triggerRows, err := db.QueryContext(ctx, triggerQuery, databaseName, tableName)
 	if err != nil {
-		return nil, fmt.Errorf("query indexes: %v", err)
+		return nil, fmt.Errorf("query triggers: %w", err)
 	}
-	defer indexRows.Close()
+	defer triggerRows.Close()
 
-	var indexes []TableIndex
-	for indexRows.Next() {
-		var idx TableIndex
-		var columns string
-		var nonUnique int
-		if err := indexRows.Scan(&idx.Name, &columns, &nonUnique); err != nil {
+	triggers := []TableTrigger{}
+	for triggerRows.Next() {
+		var t TableTrigger
+		if err := triggerRows.Scan(&t.Name, &t.Timing, &t.Event, &t.Statement, &t.Definer); err != nil {
 			continue
 		}
-		idx.Columns = strings.Split(columns, ",")
-		idx.Unique = nonUnique == 0
-		indexes = append(indexes, idx)
+		triggers = append(triggers, t)
 	}
 
-	// 约束信息
+	return &TableDetail{
+		Fields:      fields,
+		Indexes:     indexes,
+		Constraints: constraints,
+		Triggers:    triggers,
+	}, nil
+}
+
+// queryConstraints 查询表的约束及其字段
+// 约束列表和全部约束字段 (含外键引用) 各用一次查询获取，在内存中按约束名组装
+func queryConstraints(ctx context.Context, db *sql.DB, databaseName, tableName string) ([]TableConstraint, error) {
 	constraintQuery := `
 		SELECT CONSTRAINT_NAME, CONSTRAINT_TYPE
 		FROM information_schema.TABLE_CONSTRAINTS
 		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
 	`
-	constraintRows, err := db.Query(constraintQuery, databaseName, tableName)
+	constraintRows, err := db.QueryContext(ctx, constraintQuery, databaseName, tableName)
 	if err != nil {
-		return nil, fmt.Errorf("query constraints: %v", err)
+		return nil, fmt.Errorf("query constraints: %w", err)
 	}
 	defer constraintRows.Close()
 
@@ -205,50 +341,108 @@ func GetTableDetail(databaseName, tableName string) (*TableDetail, error) {
 		if err := constraintRows.Scan(&c.Name, &c.Type); err != nil {
 			continue
 		}
-		// 获取约束涉及的字段
-		colQuery := `
-			SELECT COLUMN_NAME
-			FROM information_schema.KEY_COLUMN_USAGE
-			WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND CONSTRAINT_NAME = ?
-			ORDER BY ORDINAL_POSITION
-		`
-		colRows, err := db.Query(colQuery, databaseName, tableName, c.Name)
-		if err == nil {
-			var cols []string
-			for colRows.Next() {
-				var col string
-				if err := colRows.Scan(&col); err == nil {
-					cols = append(cols, col)
-				}
-			}
-			colRows.Close()
-			c.Columns = cols
+		constraints = append(constraints, c)
+	}
+	if len(constraints) == 0 {
+		return constraints, nil
+	}
+
+	colQuery := `
+		SELECT CONSTRAINT_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY CONSTRAINT_NAME, ORDINAL_POSITION
+	`
+	colRows, err := db.QueryContext(ctx, colQuery, databaseName, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("query constraint columns: %w", err)
+	}
+	defer colRows.Close()
+
+	byName := make(map[string]*TableConstraint, len(constraints))
+	for i := range constraints {
+		byName[constraints[i].Name] = &constraints[i]
+	}
+	for colRows.Next() {
+		var name, col string
+		var refTable, refCol *string
+		if err := colRows.Scan(&name, &col, &refTable, &refCol); err != nil {
+			continue
 		}
-		// 外键约束补充引用表和字段
-		if c.Type == "FOREIGN KEY" {
-			refQuery := `
-				SELECT REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
-				FROM information_schema.KEY_COLUMN_USAGE
-				WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND CONSTRAINT_NAME = ? LIMIT 1
-			`
-			refRow := db.QueryRow(refQuery, databaseName, tableName, c.Name)
-			var refTable, refCol *string
-			_ = refRow.Scan(&refTable, &refCol)
+		c, ok := byName[name]
+		if !ok {
+			continue
+		}
+		c.Columns = append(c.Columns, col)
+		// 外键约束以第一个字段的引用作为引用表和字段
+		if c.Type == "FOREIGN KEY" && c.ReferencedTable == nil {
 			c.ReferencedTable = refTable
 			c.ReferencedColumn = refCol
 		}
-		constraints = append(constraints, c)
 	}
+	return constraints, nil
+}
 
-	return &TableDetail{
-		Fields:      fields,
-		Indexes:     indexes,
-		Constraints: constraints,
-	}, nil
+// isBadFieldError 是否为未知列错误 (ER_BAD_FIELD_ERROR)，用于判断旧版本服务器缺少的列
+func isBadFieldError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1054
+}
+
+// queryIndexes 查询表的索引，包括类型、前缀长度、基数与可见性
+// IS_VISIBLE 仅 MySQL 8.0+ 提供，旧版本报未知列错误时回退为全部可见，其他错误 (如超时) 直接返回
+func queryIndexes(ctx context.Context, db *sql.DB, databaseName, tableName string) ([]TableIndex, error) {
+	indexQuery := `
+		SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE, INDEX_TYPE, SUB_PART, CARDINALITY, IS_VISIBLE
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY INDEX_NAME, SEQ_IN_INDEX
+	`
+	indexRows, err := queryHot(ctx, db, indexQuery, databaseName, tableName)
+	if isBadFieldError(err) {
+		indexRows, err = queryHot(ctx, db, strings.Replace(indexQuery, "IS_VISIBLE", "'YES'", 1), databaseName, tableName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query indexes: %w", err)
+	}
+	defer indexRows.Close()
+
+	var indexes []TableIndex
+	for indexRows.Next() {
+		var name, indexType, visible string
+		var column sql.NullString
+		var nonUnique int
+		var subPart, cardinality sql.NullInt64
+		if err := indexRows.Scan(&name, &column, &nonUnique, &indexType, &subPart, &cardinality, &visible); err != nil {
+			continue
+		}
+		if n := len(indexes); n == 0 || indexes[n-1].Name != name {
+			indexes = append(indexes, TableIndex{
+				Name:    name,
+				Unique:  nonUnique == 0,
+				Type:    indexType,
+				Visible: visible == "YES",
+			})
+		}
+		idx := &indexes[len(indexes)-1]
+		// 函数索引的键部分没有字段名
+		if column.Valid {
+			idx.Columns = append(idx.Columns, column.String)
+			idx.SubParts = append(idx.SubParts, subPart.Int64)
+		}
+		// 索引的基数取最后一个键部分的值
+		idx.Cardinality = cardinality.Int64
+	}
+	return indexes, nil
+}
+
+// IsTimeout 判断错误是否由查询超时导致
+func IsTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
 }
 
-// isSystemDatabase 判断是否为系统数据库
-func isSystemDatabase(dbName string) bool {
+// IsSystemDatabase 判断是否为系统数据库
+func IsSystemDatabase(dbName string) bool {
 	systemDBs := []string{"information_schema", "mysql", "performance_schema", "sys"}
 	for _, sysDB := range systemDBs {
 		if strings.EqualFold(dbName, sysDB) {
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	activeHost   config.DBHost
+	activeHostMu sync.RWMutex
+)
+
+// ActiveHost 获取当前连接的主机地址，尚未连接时返回空字符串
+func ActiveHost() string {
+	activeHostMu.RLock()
+	defer activeHostMu.RUnlock()
+	if activeHost.Host == "" {
+		return ""
+	}
+	return activeHost.Address()
+}
+
+// setActiveHost 记录当前连接的主机
+func setActiveHost(host config.DBHost) {
+	activeHostMu.Lock()
+	defer activeHostMu.Unlock()
+	activeHost = host
+}
+
+var (
+	passwordProvider     PasswordProvider
+	passwordProviderErr  error
+	passwordProviderOnce sync.Once
+)
+
+// getPasswordProvider 获取配置的动态密码来源，静态密码时返回 nil
+func getPasswordProvider(cfg *config.DBConfig) (PasswordProvider, error) {
+	passwordProviderOnce.Do(func() {
+		passwordProvider, passwordProviderErr = newPasswordProvider(cfg)
+	})
+	return passwordProvider, passwordProviderErr
+}
+
+// refreshCredentials 清除缓存的动态密码，下次连接时重新获取
+func refreshCredentials() {
+	if cached, ok := passwordProvider.(*cachedPassword); ok {
+		cached.invalidate()
+	}
+}
+
+// buildMySQLConfig 构建指定主机的驱动配置
+// 使用动态密码时，每个新连接建立前都会向密码来源获取 (带缓存) 密码
+func buildMySQLConfig(cfg *config.DBConfig, host config.DBHost) (*mysql.Config, error) {
+	mysqlConfig := mysql.NewConfig()
+	mysqlConfig.User = cfg.User
+	mysqlConfig.Passwd = cfg.Pass
+	mysqlConfig.Net = "tcp"
+	mysqlConfig.Addr = host.Address()
+	mysqlConfig.DBName = cfg.Name
+	mysqlConfig.ParseTime = true
+	mysqlConfig.Loc = time.Local
+	mysqlConfig.TLSConfig = cfg.TLS
+
+	provider, err := getPasswordProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.AuthMode == "rds-iam" {
+		// IAM 令牌通过 mysql_clear_password 插件明文发送，必须启用 TLS
+		mysqlConfig.AllowCleartextPasswords = true
+		if mysqlConfig.TLSConfig == "" {
+			mysqlConfig.TLSConfig = "true"
+		}
+	}
+	if provider != nil {
+		err := mysqlConfig.Apply(mysql.BeforeConnect(func(ctx context.Context, c *mysql.Config) error {
+			password, err := provider.Password(ctx, host)
+			if err != nil {
+				return fmt.Errorf("get database password: %w", err)
+			}
+			c.Passwd = password
+			return nil
+		}))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mysqlConfig, nil
+}
+
+// openDB 打开指定主机的连接池
+func openDB(cfg *config.DBConfig, host config.DBHost) (*sql.DB, error) {
+	mysqlConfig, err := buildMySQLConfig(cfg, host)
+	if err != nil {
+		return nil, err
+	}
+	var connector driver.Connector
+	if cfg.PassSecondary != "" {
+		connector, err = newDualSecretConnector(mysqlConfig, cfg.PassSecondary)
+	} else {
+		connector, err = mysql.NewConnector(mysqlConfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+	connector = statsConnector{connector}
+	if cfg.ReadOnly {
+		connector = readOnlyConnector{connector}
+	}
+	newDB := sql.OpenDB(connector)
+
+	// 设置连接池参数
+	newDB.SetMaxOpenConns(10)
+	newDB.SetMaxIdleConns(5)
+	newDB.SetConnMaxLifetime(time.Hour)
+	return newDB, nil
+}
+
+// openAvailable 按优先级依次尝试候选主机，返回第一个可连通的连接池
+func openAvailable(cfg *config.DBConfig) (*sql.DB, config.DBHost, error) {
+	var lastErr error
+	for _, host := range cfg.Hosts {
+		newDB, err := openDB(cfg, host)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := newDB.Ping(); err != nil {
+			lastErr = fmt.Errorf("%s: %w", host.Address(), err)
+			newDB.Close()
+			if len(cfg.Hosts) > 1 {
+				GetDatabaseLogger().Component(ComponentReconnector).Debug(fmt.Sprintf("主机 %s 不可用", host.Address()), err.Error())
+			}
+			continue
+		}
+		return newDB, host, nil
+	}
+	return nil, config.DBHost{}, lastErr
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"os"
+	"time"
+)
+
+// FatalError 通过 Fatal/FatalWithConnection 记录的致命错误，由 main 收到后优雅关闭进程
+type FatalError struct {
+	Profile string    // 所属服务器名称，默认连接为空
+	Message string    // 日志消息
+	Details string    // 日志详情
+	Time    time.Time // 记录时间
+}
+
+// Error 实现 error 接口
+func (e *FatalError) Error() string {
+	s := e.Message
+	if e.Profile != "" {
+		s = "[" + e.Profile + "] " + s
+	}
+	if e.Details != "" {
+		s += ": " + e.Details
+	}
+	return s
+}
+
+// fatalErrors 只保留第一个致命错误，之后的致命错误在关闭过程中只写入日志
+var fatalErrors = make(chan *FatalError, 1)
+
+// FatalErrors 致命错误通知，main 收到后停止服务并执行 defer 的清理
+func FatalErrors() <-chan *FatalError {
+	return fatalErrors
+}
+
+// escalate 上报致命错误，不阻塞调用方，可在持有其他锁或后台协程中调用
+// 配置 LOG_FATAL_EXIT 时保持旧行为立即退出
+func (dl *DatabaseLogger) escalate(message, details string) {
+	if dl.fatalExit {
+		os.Exit(1)
+	}
+	err := &FatalError{Profile: dl.name, Message: message, Details: details, Time: time.Now()}
+	select {
+	case fatalErrors <- err:
+	default:
+		// 已有致命错误在等待处理，进程即将关闭
+	}
+}
@@ -0,0 +1,225 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/sqltools"
+)
+
+var (
+	// ErrFindValueRunning 已有值搜索正在进行
+	ErrFindValueRunning = errors.New("value search already running")
+	// ErrInvalidFindOptions 值搜索参数无效
+	ErrInvalidFindOptions = errors.New("invalid find options")
+)
+
+const (
+	// findValueColumnTimeout 单列查询的超时
+	findValueColumnTimeout = 5 * time.Second
+	// findValueTotalTimeout 整次搜索的超时，超时后剩余的列记为跳过
+	findValueTotalTimeout = time.Minute
+	// MaxFindValueColumns 单次搜索最多检查的列数
+	MaxFindValueColumns = 1000
+)
+
+// 值搜索的列类型过滤
+const (
+	FindTypeString   = "string"
+	FindTypeNumeric  = "numeric"
+	FindTypeTemporal = "temporal"
+	FindTypeAll      = "all"
+)
+
+// findValueMu 同一时间只允许一次搜索
+var findValueMu sync.Mutex
+
+// findTypeCategories 各数据类型所属的过滤类别
+var findTypeCategories = map[string]string{
+	"char": FindTypeString, "varchar": FindTypeString, "tinytext": FindTypeString, "text": FindTypeString,
+	"mediumtext": FindTypeString, "longtext": FindTypeString, "enum": FindTypeString, "set": FindTypeString,
+	"tinyint": FindTypeNumeric, "smallint": FindTypeNumeric, "mediumint": FindTypeNumeric, "int": FindTypeNumeric,
+	"bigint": FindTypeNumeric, "decimal": FindTypeNumeric, "float": FindTypeNumeric, "double": FindTypeNumeric,
+	"date": FindTypeTemporal, "datetime": FindTypeTemporal, "timestamp": FindTypeTemporal, "time": FindTypeTemporal,
+	"year": FindTypeTemporal,
+}
+
+// FindValueOptions 值搜索选项
+type FindValueOptions struct {
+	Value    string
+	Type     string // 列类型过滤，默认为字符串列
+	Contains bool   // 子串匹配 (LIKE)，仅对字符串列生效
+}
+
+// ValueLocation 包含目标值的列
+type ValueLocation struct {
+	Table     string `json:"table"`
+	Column    string `json:"column"`
+	Type      string `json:"type"`
+	Collation string `json:"collation,omitempty"`
+}
+
+// FindValueResult 值搜索结果
+type FindValueResult struct {
+	Database  string          `json:"database"`
+	Value     string          `json:"value"`
+	Type      string          `json:"type"`
+	Contains  bool            `json:"contains"`
+	Checked   int             `json:"checked"` // 已检查的列数
+	Matches   []ValueLocation `json:"matches"`
+	Skipped   []ValueLocation `json:"skipped"` // 超时或出错而未完成检查的列
+	Truncated bool            `json:"truncated"`
+	Elapsed   string          `json:"elapsed"`
+}
+
+// FindValue 在数据库的候选列中查找值
+func FindValue(ctx context.Context, databaseName string, opts FindValueOptions) (*FindValueResult, error) {
+	return defaultStore.FindValue(ctx, databaseName, opts)
+}
+
+// FindValue 逐列执行带 LIMIT 1 的等值 (或 LIKE) 查询，定位包含该值的表和列
+// 比较使用列自身的排序规则，因此 _ci 排序规则的列不区分大小写；长度不足或类型不匹配的列不会查询
+// 配置了脱敏规则的列不会查询，避免通过搜索结果推断被脱敏的值
+func (s *MySQLStore) FindValue(ctx context.Context, databaseName string, opts FindValueOptions) (*FindValueResult, error) {
+	if opts.Value == "" {
+		return nil, fmt.Errorf("%w: value must not be empty", ErrInvalidFindOptions)
+	}
+	if opts.Type == "" {
+		opts.Type = FindTypeString
+	}
+	switch opts.Type {
+	case FindTypeString, FindTypeNumeric, FindTypeTemporal, FindTypeAll:
+	default:
+		return nil, fmt.Errorf("%w: unknown column type filter %q", ErrInvalidFindOptions, opts.Type)
+	}
+	if !findValueMu.TryLock() {
+		return nil, ErrFindValueRunning
+	}
+	defer findValueMu.Unlock()
+
+	db := GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	started := time.Now()
+	queryCtx, cancel := s.withQueryTimeout(ctx)
+	if err := checkDatabase(queryCtx, db, databaseName); err != nil {
+		cancel()
+		return nil, err
+	}
+	rows, err := db.QueryContext(queryCtx, `
+		SELECT c.TABLE_NAME, c.COLUMN_NAME, c.DATA_TYPE, c.COLUMN_TYPE,
+		       COALESCE(c.CHARACTER_MAXIMUM_LENGTH, 0), COALESCE(c.COLLATION_NAME, '')
+		FROM information_schema.COLUMNS c
+		JOIN information_schema.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+		WHERE c.TABLE_SCHEMA = ? AND t.TABLE_TYPE = 'BASE TABLE'
+		ORDER BY c.TABLE_NAME, c.ORDINAL_POSITION`, databaseName)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("query columns: %w", err)
+	}
+
+	type candidate struct {
+		ValueLocation
+		category string
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		var dataType string
+		var maxLength int64
+		if err := rows.Scan(&c.Table, &c.Column, &dataType, &c.Type, &maxLength, &c.Collation); err != nil {
+			rows.Close()
+			cancel()
+			return nil, fmt.Errorf("scan column: %w", err)
+		}
+		c.category = findTypeCategories[strings.ToLower(dataType)]
+		if !columnCanHold(c.category, opts, maxLength) {
+			continue
+		}
+		if opts.Type != FindTypeAll && c.category != opts.Type {
+			continue
+		}
+		if GetMasker().ActionFor(databaseName, c.Table, c.Column) != "" {
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+	rows.Close()
+	cancel()
+
+	result := &FindValueResult{
+		Database: databaseName,
+		Value:    opts.Value,
+		Type:     opts.Type,
+		Contains: opts.Contains,
+		Matches:  []ValueLocation{},
+		Skipped:  []ValueLocation{},
+	}
+	if len(candidates) > MaxFindValueColumns {
+		candidates = candidates[:MaxFindValueColumns]
+		result.Truncated = true
+	}
+
+	searchCtx, cancelSearch := context.WithTimeout(ctx, findValueTotalTimeout)
+	defer cancelSearch()
+	for _, c := range candidates {
+		if searchCtx.Err() != nil {
+			result.Skipped = append(result.Skipped, c.ValueLocation)
+			continue
+		}
+
+		column := sqltools.QuoteIdentifier(c.Column)
+		query := "SELECT 1 FROM " + QuoteTable(databaseName, c.Table) +
+			" WHERE " + column + " = ? LIMIT 1"
+		arg := opts.Value
+		if opts.Contains && c.category == FindTypeString {
+			query = "SELECT 1 FROM " + QuoteTable(databaseName, c.Table) +
+				" WHERE " + column + " LIKE ? LIMIT 1"
+			arg = "%" + escapeLike(opts.Value) + "%"
+		}
+
+		columnCtx, cancel := context.WithTimeout(searchCtx, findValueColumnTimeout)
+		var found int
+		err := db.QueryRowContext(columnCtx, query, arg).Scan(&found)
+		cancel()
+		switch {
+		case err == nil:
+			result.Checked++
+			result.Matches = append(result.Matches, c.ValueLocation)
+		case errors.Is(err, sql.ErrNoRows):
+			result.Checked++
+		default:
+			result.Skipped = append(result.Skipped, c.ValueLocation)
+		}
+	}
+
+	result.Elapsed = time.Since(started).Round(time.Millisecond).String()
+	return result, nil
+}
+
+// columnCanHold 判断列能否存放目标值：数值列要求值为数字，定长或变长字符串列的长度不能小于值的长度
+func columnCanHold(category string, opts FindValueOptions, maxLength int64) bool {
+	switch category {
+	case FindTypeString:
+		return maxLength == 0 || opts.Contains || int64(len([]rune(opts.Value))) <= maxLength
+	case FindTypeNumeric:
+		_, err := strconv.ParseFloat(opts.Value, 64)
+		return err == nil
+	case FindTypeTemporal:
+		return len(opts.Value) >= 4 && opts.Value[0] >= '0' && opts.Value[0] <= '9'
+	}
+	return false
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
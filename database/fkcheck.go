@@ -0,0 +1,466 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/sqltools"
+)
+
+const (
+	// fkCheckChunkSize 每个分块扫描的行数
+	fkCheckChunkSize = 5000
+	// MaxFKViolations 单次校验最多记录的违规行数
+	MaxFKViolations = 10000
+	// maxFKCheckJobs 保留的校验任务数
+	maxFKCheckJobs = 20
+)
+
+// 校验任务状态
+const (
+	FKCheckRunning   = "running"
+	FKCheckDone      = "done"
+	FKCheckFailed    = "failed"
+	FKCheckCancelled = "cancelled"
+)
+
+// ErrFKCheckNotFound 校验任务不存在
+var ErrFKCheckNotFound = errors.New("foreign key check not found")
+
+// ForeignKey 外键定义
+type ForeignKey struct {
+	Name               string   `json:"name"`
+	Columns            []string `json:"columns"`
+	ReferencedDatabase string   `json:"referenced_database"`
+	ReferencedTable    string   `json:"referenced_table"`
+	ReferencedColumns  []string `json:"referenced_columns"`
+}
+
+// FKViolation 引用了不存在父行的子行
+type FKViolation struct {
+	Constraint string   `json:"constraint"`
+	Key        string   `json:"key,omitempty"` // 子行主键，表没有整数主键时为空
+	Values     []string `json:"values"`        // 外键列的取值
+}
+
+// FKCheckJob 外键校验任务
+type FKCheckJob struct {
+	ID             int          `json:"id"`
+	Database       string       `json:"database"`
+	Table          string       `json:"table"`
+	Status         string       `json:"status"`
+	Error          string       `json:"error,omitempty"`
+	ForeignKeys    []ForeignKey `json:"foreign_keys"`
+	Chunked        bool         `json:"chunked"` // 按整数主键分块扫描，否则整表一次扫描
+	ChunksDone     int          `json:"chunks_done"`
+	Progress       float64      `json:"progress"` // 完成百分比
+	ViolationCount int          `json:"violation_count"`
+	Truncated      bool         `json:"truncated"` // 违规行超过上限，之后的不再记录
+	StartedAt      time.Time    `json:"started_at"`
+	FinishedAt     *time.Time   `json:"finished_at,omitempty"`
+
+	violations []FKViolation
+	cancel     context.CancelFunc
+}
+
+// FKChecker 外键校验任务管理器
+type FKChecker struct {
+	mu           sync.RWMutex
+	jobs         []*FKCheckJob
+	nextID       int
+	queryTimeout time.Duration
+}
+
+var (
+	fkChecker *FKChecker
+	fkOnce    sync.Once
+)
+
+// GetFKChecker 获取外键校验任务管理器实例
+func GetFKChecker() *FKChecker {
+	fkOnce.Do(func() {
+		fkChecker = &FKChecker{nextID: 1, queryTimeout: config.GetDBConfig().QueryTimeout}
+	})
+	return fkChecker
+}
+
+// Start 为指定表创建后台校验任务，逐个分块查找违反外键约束的行
+// 用于 FOREIGN_KEY_CHECKS=0 导入数据之后重新校验
+func (fc *FKChecker) Start(ctx context.Context, databaseName, tableName string) (*FKCheckJob, error) {
+	db := GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	queryCtx, cancel := fc.withQueryTimeout(ctx)
+	defer cancel()
+	if err := checkTable(queryCtx, db, databaseName, tableName); err != nil {
+		return nil, err
+	}
+	fks, err := foreignKeys(queryCtx, db, databaseName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(fks) == 0 {
+		return nil, fmt.Errorf("table %s.%s has no foreign keys", databaseName, tableName)
+	}
+	pk, _, err := integerPrimaryKey(queryCtx, db, databaseName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	runCtx, runCancel := context.WithCancel(context.Background())
+	fc.mu.Lock()
+	job := &FKCheckJob{
+		ID:          fc.nextID,
+		Database:    databaseName,
+		Table:       tableName,
+		Status:      FKCheckRunning,
+		ForeignKeys: fks,
+		Chunked:     pk != "",
+		StartedAt:   time.Now(),
+		violations:  []FKViolation{},
+		cancel:      runCancel,
+	}
+	fc.nextID++
+	fc.jobs = append(fc.jobs, job)
+	fc.trimLocked()
+	snapshot := *job
+	fc.mu.Unlock()
+
+	GetDatabaseLogger().Info(fmt.Sprintf("开始外键校验 #%d: %s.%s", job.ID, databaseName, tableName))
+	go fc.run(runCtx, db, job, pk)
+	return &snapshot, nil
+}
+
+// run 执行校验并更新任务状态
+func (fc *FKChecker) run(ctx context.Context, db *sql.DB, job *FKCheckJob, pk string) {
+	var err error
+	if pk != "" {
+		err = fc.scanChunks(ctx, db, job, pk)
+	} else {
+		err = fc.scanRange(ctx, db, job, "", nil, nil)
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	now := time.Now()
+	job.FinishedAt = &now
+	job.cancel()
+	switch {
+	case errors.Is(err, context.Canceled):
+		job.Status = FKCheckCancelled
+	case err != nil:
+		job.Status = FKCheckFailed
+		job.Error = err.Error()
+	default:
+		job.Status = FKCheckDone
+		job.Progress = 100
+	}
+	GetDatabaseLogger().Info(fmt.Sprintf("外键校验 #%d 结束: %s", job.ID, job.Status),
+		fmt.Sprintf("%s.%s 违规行 %d", job.Database, job.Table, job.ViolationCount))
+}
+
+// scanChunks 按主键顺序每次取 fkCheckChunkSize 行的范围进行校验
+func (fc *FKChecker) scanChunks(ctx context.Context, db *sql.DB, job *FKCheckJob, pk string) error {
+	table := QuoteTable(job.Database, job.Table)
+	column := sqltools.QuoteIdentifier(pk)
+
+	queryCtx, cancel := fc.withQueryTimeout(ctx)
+	var minKey, maxKey sql.NullInt64
+	err := db.QueryRowContext(queryCtx, "SELECT MIN("+column+"), MAX("+column+") FROM "+table).Scan(&minKey, &maxKey)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("query key range: %w", err)
+	}
+	if !minKey.Valid {
+		return nil
+	}
+
+	lo := minKey.Int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// 下一个分块的起点，不存在时当前分块为最后一块
+		queryCtx, cancel := fc.withQueryTimeout(ctx)
+		var next int64
+		err := db.QueryRowContext(queryCtx,
+			"SELECT "+column+" FROM "+table+" WHERE "+column+" >= ? ORDER BY "+column+" LIMIT 1 OFFSET ?",
+			lo, fkCheckChunkSize).Scan(&next)
+		cancel()
+		last := errors.Is(err, sql.ErrNoRows)
+		if err != nil && !last {
+			return fmt.Errorf("find chunk boundary: %w", err)
+		}
+
+		var hi *int64
+		if !last {
+			hi = &next
+		}
+		if err := fc.scanRange(ctx, db, job, pk, &lo, hi); err != nil {
+			return err
+		}
+
+		fc.mu.Lock()
+		job.ChunksDone++
+		if !last && maxKey.Int64 > minKey.Int64 {
+			job.Progress = float64(int64(float64(next-minKey.Int64)*10000/float64(maxKey.Int64-minKey.Int64))) / 100
+		}
+		truncated := job.Truncated
+		fc.mu.Unlock()
+		if last || truncated {
+			return nil
+		}
+		lo = next
+	}
+}
+
+// scanRange 在主键范围 [lo, hi) 内查找各外键的违规行，pk 为空时扫描整表
+func (fc *FKChecker) scanRange(ctx context.Context, db *sql.DB, job *FKCheckJob, pk string, lo, hi *int64) error {
+	for _, fk := range job.ForeignKeys {
+		fc.mu.RLock()
+		remaining := MaxFKViolations - len(job.violations)
+		fc.mu.RUnlock()
+		if remaining <= 0 {
+			return nil
+		}
+
+		query, args := violationQuery(job.Database, job.Table, fk, pk, lo, hi, remaining+1)
+		queryCtx, cancel := fc.withQueryTimeout(ctx)
+		rows, err := db.QueryContext(queryCtx, query, args...)
+		if err != nil {
+			cancel()
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("check %s: %w", fk.Name, err)
+		}
+		found, err := scanViolations(rows, fk.Name, pk != "")
+		cancel()
+		if err != nil {
+			return fmt.Errorf("scan %s: %w", fk.Name, err)
+		}
+
+		fc.mu.Lock()
+		if len(found) > remaining {
+			found = found[:remaining]
+			job.Truncated = true
+		}
+		job.violations = append(job.violations, found...)
+		job.ViolationCount = len(job.violations)
+		fc.mu.Unlock()
+	}
+	return nil
+}
+
+// violationQuery 构造查找违规行的语句：外键列均非 NULL 且父表中没有匹配的行
+func violationQuery(databaseName, tableName string, fk ForeignKey, pk string, lo, hi *int64, limit int) (string, []interface{}) {
+	var selects, joins, conds []string
+	if pk != "" {
+		selects = append(selects, "c."+sqltools.QuoteIdentifier(pk))
+	}
+	for i, col := range fk.Columns {
+		child := "c." + sqltools.QuoteIdentifier(col)
+		selects = append(selects, child)
+		joins = append(joins, "p."+sqltools.QuoteIdentifier(fk.ReferencedColumns[i])+" = "+child)
+		conds = append(conds, child+" IS NOT NULL")
+	}
+	conds = append(conds, "p."+sqltools.QuoteIdentifier(fk.ReferencedColumns[0])+" IS NULL")
+
+	var args []interface{}
+	if lo != nil {
+		conds = append(conds, "c."+sqltools.QuoteIdentifier(pk)+" >= ?")
+		args = append(args, *lo)
+	}
+	if hi != nil {
+		conds = append(conds, "c."+sqltools.QuoteIdentifier(pk)+" < ?")
+		args = append(args, *hi)
+	}
+	args = append(args, limit)
+
+	query := "SELECT " + strings.Join(selects, ", ") +
+		" FROM " + QuoteTable(databaseName, tableName) + " c" +
+		" LEFT JOIN " + QuoteTable(fk.ReferencedDatabase, fk.ReferencedTable) + " p" +
+		" ON " + strings.Join(joins, " AND ") +
+		" WHERE " + strings.Join(conds, " AND ") +
+		" LIMIT ?"
+	return query, args
+}
+
+// scanViolations 读取违规行，withKey 为 true 时第一列为主键
+func scanViolations(rows *sql.Rows, constraint string, withKey bool) ([]FKViolation, error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []FKViolation
+	for rows.Next() {
+		values := make([]sql.RawBytes, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		v := FKViolation{Constraint: constraint}
+		for i, value := range values {
+			if i == 0 && withKey {
+				v.Key = string(value)
+				continue
+			}
+			v.Values = append(v.Values, string(value))
+		}
+		found = append(found, v)
+	}
+	return found, rows.Err()
+}
+
+// foreignKeys 查询表上定义的外键
+func foreignKeys(ctx context.Context, db *sql.DB, databaseName, tableName string) ([]ForeignKey, error) {
+	byTable, err := queryForeignKeys(ctx, db, databaseName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return byTable[tableName], nil
+}
+
+// GetForeignKeys 获取数据库中全部表的外键，键为表名
+func GetForeignKeys(ctx context.Context, databaseName string) (map[string][]ForeignKey, error) {
+	return defaultStore.GetForeignKeys(ctx, databaseName)
+}
+
+// GetForeignKeys 获取数据库中全部表的外键，包含多列外键的完整引用关系
+func (s *MySQLStore) GetForeignKeys(ctx context.Context, databaseName string) (map[string][]ForeignKey, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return queryForeignKeys(ctx, db, databaseName, "")
+}
+
+// queryForeignKeys 按表名分组查询外键，tableName 为空时查询整个数据库
+func queryForeignKeys(ctx context.Context, db *sql.DB, databaseName, tableName string) (map[string][]ForeignKey, error) {
+	query := `
+		SELECT TABLE_NAME, CONSTRAINT_NAME, COLUMN_NAME, REFERENCED_TABLE_SCHEMA, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = ? AND REFERENCED_TABLE_NAME IS NOT NULL`
+	args := []interface{}{databaseName}
+	if tableName != "" {
+		query += " AND TABLE_NAME = ?"
+		args = append(args, tableName)
+	}
+	rows, err := db.QueryContext(ctx, query+" ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION", args...)
+	if err != nil {
+		return nil, fmt.Errorf("query foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	byTable := make(map[string][]ForeignKey)
+	for rows.Next() {
+		var table, name, column, refDatabase, refTable, refColumn string
+		if err := rows.Scan(&table, &name, &column, &refDatabase, &refTable, &refColumn); err != nil {
+			return nil, fmt.Errorf("scan foreign key: %w", err)
+		}
+		fks := byTable[table]
+		if len(fks) == 0 || fks[len(fks)-1].Name != name {
+			fks = append(fks, ForeignKey{Name: name, ReferencedDatabase: refDatabase, ReferencedTable: refTable})
+		}
+		fk := &fks[len(fks)-1]
+		fk.Columns = append(fk.Columns, column)
+		fk.ReferencedColumns = append(fk.ReferencedColumns, refColumn)
+		byTable[table] = fks
+	}
+	return byTable, rows.Err()
+}
+
+// List 获取全部任务，最新的在前
+func (fc *FKChecker) List() []FKCheckJob {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+	jobs := make([]FKCheckJob, 0, len(fc.jobs))
+	for _, job := range fc.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID })
+	return jobs
+}
+
+// Get 获取任务状态
+func (fc *FKChecker) Get(id int) (*FKCheckJob, error) {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+	job := fc.find(id)
+	if job == nil {
+		return nil, ErrFKCheckNotFound
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// Violations 获取任务已发现的违规行
+func (fc *FKChecker) Violations(id int) ([]FKViolation, error) {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+	job := fc.find(id)
+	if job == nil {
+		return nil, ErrFKCheckNotFound
+	}
+	violations := make([]FKViolation, len(job.violations))
+	copy(violations, job.violations)
+	return violations, nil
+}
+
+// Cancel 取消正在运行的任务
+func (fc *FKChecker) Cancel(id int) error {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+	job := fc.find(id)
+	if job == nil {
+		return ErrFKCheckNotFound
+	}
+	job.cancel()
+	return nil
+}
+
+// find 按 ID 查找任务，调用方需持有锁
+func (fc *FKChecker) find(id int) *FKCheckJob {
+	for _, job := range fc.jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// trimLocked 超过保留数时移除最早的已结束任务，调用方需持有写锁
+func (fc *FKChecker) trimLocked() {
+	for i := 0; len(fc.jobs) > maxFKCheckJobs && i < len(fc.jobs); {
+		if fc.jobs[i].Status == FKCheckRunning {
+			i++
+			continue
+		}
+		fc.jobs = append(fc.jobs[:i], fc.jobs[i+1:]...)
+	}
+}
+
+// withQueryTimeout 为单个分块的查询附加语句超时
+func (fc *FKChecker) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if fc.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, fc.queryTimeout)
+}
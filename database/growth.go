@@ -0,0 +1,305 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// TableStat 表的行数与空间占用
+type TableStat struct {
+	Rows      int64 `json:"rows"`
+	DataSize  int64 `json:"data_size"`
+	IndexSize int64 `json:"index_size"`
+}
+
+// GrowthSample 一次表增长采样，Tables 的键为 "库名.表名"
+type GrowthSample struct {
+	Timestamp time.Time            `json:"timestamp"`
+	Tables    map[string]TableStat `json:"tables"`
+}
+
+// SizeForecast 基于采样数据的线性容量预测
+type SizeForecast struct {
+	Name              string   `json:"name"`
+	CurrentSize       int64    `json:"current_size"`
+	CurrentSizeHuman  string   `json:"current_size_human"`
+	GrowthPerDay      float64  `json:"growth_per_day"` // 字节/天
+	GrowthPerDayHuman string   `json:"growth_per_day_human"`
+	Samples           int      `json:"samples"`
+	DaysUntilFull     *float64 `json:"days_until_full,omitempty"`
+}
+
+// DaysUntilFullText 预计写满天数的展示文本
+func (f SizeForecast) DaysUntilFullText() string {
+	if f.DaysUntilFull == nil {
+		return "—"
+	}
+	return fmt.Sprintf("%.1f 天后", *f.DaysUntilFull)
+}
+
+// GrowthTracker 定期采样表大小并保存历史
+type GrowthTracker struct {
+	mu         sync.RWMutex
+	samples    []GrowthSample
+	maxSamples int
+	capacity   int64
+	store      Store
+	logger     *ComponentLogger
+	stop       chan struct{}
+}
+
+var (
+	growthTracker *GrowthTracker
+	growthOnce    sync.Once
+)
+
+// GetGrowthTracker 获取表增长采样器实例
+func GetGrowthTracker() *GrowthTracker {
+	growthOnce.Do(func() {
+		growthConfig := config.GetGrowthConfig()
+		growthTracker = &GrowthTracker{
+			samples:    make([]GrowthSample, 0),
+			maxSamples: growthConfig.MaxSamples,
+			capacity:   growthConfig.DiskCapacityBytes,
+			store:      defaultStore,
+			logger:     GetDatabaseLogger().Component(ComponentScheduler),
+		}
+	})
+	return growthTracker
+}
+
+// Start 按指定间隔开始采样，interval 为 0 时不启动
+func (gt *GrowthTracker) Start(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	gt.mu.Lock()
+	if gt.stop != nil {
+		gt.mu.Unlock()
+		return
+	}
+	gt.stop = make(chan struct{})
+	stop := gt.stop
+	gt.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		gt.Sample(context.Background())
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				gt.Sample(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop 停止采样
+func (gt *GrowthTracker) Stop() {
+	gt.mu.Lock()
+	defer gt.mu.Unlock()
+	if gt.stop != nil {
+		close(gt.stop)
+		gt.stop = nil
+	}
+}
+
+// Sample 立即执行一次采样
+func (gt *GrowthTracker) Sample(ctx context.Context) {
+	stats, err := gt.store.GetTableStats(ctx)
+	if err != nil {
+		gt.logger.Debug("表增长采样失败", err.Error())
+		return
+	}
+
+	gt.mu.Lock()
+	defer gt.mu.Unlock()
+	if gt.maxSamples > 0 && len(gt.samples) >= gt.maxSamples {
+		gt.samples = gt.samples[1:]
+	}
+	gt.samples = append(gt.samples, GrowthSample{Timestamp: time.Now(), Tables: stats})
+}
+
+// GetSamples 获取采样历史副本
+func (gt *GrowthTracker) GetSamples() []GrowthSample {
+	gt.mu.RLock()
+	defer gt.mu.RUnlock()
+	samples := make([]GrowthSample, len(gt.samples))
+	copy(samples, gt.samples)
+	return samples
+}
+
+// Forecast 预测总大小及各数据库大小的增长趋势
+// 返回的第一项为全部数据库的合计
+func (gt *GrowthTracker) Forecast() []SizeForecast {
+	samples := gt.GetSamples()
+
+	// 按数据库汇总每次采样的大小
+	totals := make([]map[string]int64, len(samples))
+	names := make(map[string]bool)
+	for i, sample := range samples {
+		totals[i] = make(map[string]int64)
+		for key, stat := range sample.Tables {
+			dbName := key
+			if idx := strings.Index(key, "."); idx >= 0 {
+				dbName = key[:idx]
+			}
+			size := stat.DataSize + stat.IndexSize
+			totals[i][dbName] += size
+			totals[i][""] += size
+			names[dbName] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	forecasts := []SizeForecast{gt.forecastSeries("全部", samples, totals, "", gt.capacity)}
+	for _, name := range sorted {
+		forecasts = append(forecasts, gt.forecastSeries(name, samples, totals, name, 0))
+	}
+	return forecasts
+}
+
+// forecastSeries 对单个序列做最小二乘线性拟合并估算到达容量的天数
+func (gt *GrowthTracker) forecastSeries(label string, samples []GrowthSample, totals []map[string]int64, key string, capacity int64) SizeForecast {
+	forecast := SizeForecast{Name: label, Samples: len(samples)}
+	if len(samples) == 0 {
+		forecast.CurrentSizeHuman = formatBytes(0)
+		forecast.GrowthPerDayHuman = formatBytes(0)
+		return forecast
+	}
+
+	forecast.CurrentSize = totals[len(totals)-1][key]
+	forecast.CurrentSizeHuman = formatBytes(forecast.CurrentSize)
+
+	if len(samples) >= 2 {
+		origin := samples[0].Timestamp
+		var sumX, sumY, sumXY, sumXX float64
+		n := float64(len(samples))
+		for i, sample := range samples {
+			x := sample.Timestamp.Sub(origin).Hours() / 24
+			y := float64(totals[i][key])
+			sumX += x
+			sumY += y
+			sumXY += x * y
+			sumXX += x * x
+		}
+		if denom := n*sumXX - sumX*sumX; denom != 0 {
+			forecast.GrowthPerDay = (n*sumXY - sumX*sumY) / denom
+		}
+	}
+	forecast.GrowthPerDayHuman = formatBytes(int64(forecast.GrowthPerDay)) + "/天"
+
+	if capacity > 0 && forecast.GrowthPerDay > 0 {
+		days := float64(capacity-forecast.CurrentSize) / forecast.GrowthPerDay
+		if days < 0 {
+			days = 0
+		}
+		forecast.DaysUntilFull = &days
+	}
+	return forecast
+}
+
+// GetTableStats 获取所有用户表的行数与空间占用
+func (s *MySQLStore) GetTableStats(ctx context.Context) (map[string]TableStat, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		SELECT TABLE_SCHEMA, TABLE_NAME,
+			COALESCE(TABLE_ROWS, 0), COALESCE(DATA_LENGTH, 0), COALESCE(INDEX_LENGTH, 0)
+		FROM information_schema.TABLES
+		WHERE TABLE_TYPE = 'BASE TABLE'`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query table stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[string]TableStat)
+	for rows.Next() {
+		var schema, table string
+		var stat TableStat
+		if err := rows.Scan(&schema, &table, &stat.Rows, &stat.DataSize, &stat.IndexSize); err != nil {
+			continue
+		}
+		if IsSystemDatabase(schema) {
+			continue
+		}
+		stats[schema+"."+table] = stat
+	}
+	return stats, nil
+}
+
+// RowDropPercent 表行数相对 window 内最大值的下降百分比，表不再出现在最新采样中时按 0 行计算
+// window 内不足两次采样或表从未出现时 ok 为 false；行数少于 minRows 的表不判定，避免小表的估算波动
+func (gt *GrowthTracker) RowDropPercent(table string, window time.Duration, minRows int64) (percent float64, ok bool) {
+	samples := gt.GetSamples()
+	if len(samples) < 2 {
+		return 0, false
+	}
+	latest := samples[len(samples)-1]
+	since := latest.Timestamp.Add(-window)
+	var peak int64
+	seen := false
+	for _, sample := range samples[:len(samples)-1] {
+		if sample.Timestamp.Before(since) {
+			continue
+		}
+		if stat, exists := sample.Tables[table]; exists {
+			seen = true
+			if stat.Rows > peak {
+				peak = stat.Rows
+			}
+		}
+	}
+	if !seen || peak < minRows || peak == 0 {
+		return 0, false
+	}
+	current := latest.Tables[table].Rows
+	if current >= peak {
+		return 0, true
+	}
+	return float64(peak-current) * 100 / float64(peak), true
+}
+
+// RowStalledFor 表行数自上次增长以来经过的时间
+// 采样历史中表的行数从未增长过 (不是持续增长的表) 时 ok 为 false
+func (gt *GrowthTracker) RowStalledFor(table string) (stalled time.Duration, ok bool) {
+	samples := gt.GetSamples()
+	var lastGrowth time.Time
+	var prev int64
+	seen := false
+	for _, sample := range samples {
+		stat, exists := sample.Tables[table]
+		if !exists {
+			continue
+		}
+		if seen && stat.Rows > prev {
+			lastGrowth = sample.Timestamp
+		}
+		prev = stat.Rows
+		seen = true
+	}
+	if lastGrowth.IsZero() {
+		return 0, false
+	}
+	return samples[len(samples)-1].Timestamp.Sub(lastGrowth), true
+}
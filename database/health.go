@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+// CountBlockedSessions 统计正在等待锁的会话数
+func CountBlockedSessions(ctx context.Context) (int, error) {
+	return defaultStore.CountBlockedSessions(ctx)
+}
+
+// CountBlockedSessions 统计等待行锁 (InnoDB) 与元数据锁的会话数
+func (s *MySQLStore) CountBlockedSessions(ctx context.Context) (int, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+
+	blocked, err := countBlockedSessions(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	if blocked > 0 {
+		GetIncidentRecorder().recordEvent(EventBlocking, fmt.Sprintf("%d 个会话处于锁等待", blocked), float64(blocked))
+	}
+	return blocked, nil
+}
+
+// countBlockedSessions 在指定连接上统计等待行锁与元数据锁的会话数
+func countBlockedSessions(ctx context.Context, db *sql.DB) (int, error) {
+	// 行锁等待：8.0+ 使用 performance_schema.data_lock_waits，旧版本及 MariaDB 使用 INNODB_LOCK_WAITS
+	var rowLockWaits int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(DISTINCT REQUESTING_ENGINE_TRANSACTION_ID) FROM performance_schema.data_lock_waits").Scan(&rowLockWaits)
+	if err != nil {
+		err = db.QueryRowContext(ctx, "SELECT COUNT(DISTINCT requesting_trx_id) FROM information_schema.INNODB_LOCK_WAITS").Scan(&rowLockWaits)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("query lock waits: %w", err)
+	}
+
+	var metadataLockWaits int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM information_schema.PROCESSLIST WHERE STATE LIKE 'Waiting for%metadata lock'").Scan(&metadataLockWaits); err != nil {
+		return 0, fmt.Errorf("query metadata lock waits: %w", err)
+	}
+
+	return rowLockWaits + metadataLockWaits, nil
+}
+
+// GetReplicaLag 获取复制延迟 (秒)
+func GetReplicaLag(ctx context.Context) (*int64, error) {
+	return defaultStore.GetReplicaLag(ctx)
+}
+
+// GetReplicaLag 获取复制延迟 (秒)，非从库或复制线程未运行时返回 nil
+func (s *MySQLStore) GetReplicaLag(ctx context.Context) (*int64, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	lag, _, err := queryReplicaLag(ctx, db)
+	return lag, err
+}
+
+// queryReplicaLag 在指定连接上读取 Seconds_Behind_Source
+// 非从库时 replica 为 false；是从库但复制线程未运行 (延迟为 NULL) 时 lag 为 nil
+func queryReplicaLag(ctx context.Context, db *sql.DB) (lag *int64, replica bool, err error) {
+	// 8.0.22+ 使用 SHOW REPLICA STATUS，旧版本使用 SHOW SLAVE STATUS
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = db.QueryContext(ctx, "SHOW SLAVE STATUS")
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("show replica status: %w", err)
+	}
+	status, err := scanRowMaps(rows)
+	if err != nil {
+		return nil, false, fmt.Errorf("scan replica status: %w", err)
+	}
+	if len(status) == 0 {
+		return nil, false, nil
+	}
+
+	value := status[0]["Seconds_Behind_Source"]
+	if value == "" {
+		value = status[0]["Seconds_Behind_Master"]
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, true, nil
+	}
+	return &seconds, true, nil
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/furutachiKurea/block-checker/sqltools"
+)
+
+var (
+	// ErrInvalidIdentifier 库名或表名不是合法的 MySQL 标识符
+	ErrInvalidIdentifier = errors.New("invalid identifier")
+	// ErrDatabaseNotFound 数据库不存在
+	ErrDatabaseNotFound = errors.New("database not found")
+)
+
+// maxIdentifierLength MySQL 库名和表名的最大长度 (字符)
+const maxIdentifierLength = 64
+
+// knownObjectTTL 已确认存在的库和表在缓存中保留的时长
+const knownObjectTTL = 5 * time.Minute
+
+// ValidateIdentifier 检查库名或表名是否可能是合法的 MySQL 标识符
+// 允许空格、Unicode 和反引号 (引用时转义)，拒绝空名、超长、非 UTF-8、NUL 字符和结尾空格
+func ValidateIdentifier(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: empty name", ErrInvalidIdentifier)
+	case !utf8.ValidString(name):
+		return fmt.Errorf("%w: not valid UTF-8", ErrInvalidIdentifier)
+	case utf8.RuneCountInString(name) > maxIdentifierLength:
+		return fmt.Errorf("%w: longer than %d characters", ErrInvalidIdentifier, maxIdentifierLength)
+	case strings.ContainsRune(name, 0):
+		return fmt.Errorf("%w: contains NUL", ErrInvalidIdentifier)
+	case strings.HasSuffix(name, " "):
+		return fmt.Errorf("%w: ends with space", ErrInvalidIdentifier)
+	}
+	return nil
+}
+
+// QuoteTable 返回引用后的 `库`.`表`
+func QuoteTable(databaseName, tableName string) string {
+	return sqltools.QuoteIdentifier(databaseName) + "." + sqltools.QuoteIdentifier(tableName)
+}
+
+// knownObjects 已确认存在的库和表，避免每次拼接查询前都访问 information_schema
+type knownObjects struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+var known = &knownObjects{entries: make(map[string]time.Time)}
+
+// has 判断对象是否在有效期内确认过存在
+func (k *knownObjects) has(key string) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	at, ok := k.entries[key]
+	if ok && time.Since(at) > knownObjectTTL {
+		delete(k.entries, key)
+		return false
+	}
+	return ok
+}
+
+// add 记录对象存在
+func (k *knownObjects) add(key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.entries[key] = time.Now()
+}
+
+// checkDatabase 校验库名并确认数据库存在，用于把库名拼接进查询之前
+func checkDatabase(ctx context.Context, db *sql.DB, databaseName string) error {
+	if err := ValidateIdentifier(databaseName); err != nil {
+		return err
+	}
+	if known.has(databaseName) {
+		return nil
+	}
+	var exists int
+	if err := db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = ?", databaseName).Scan(&exists); err != nil {
+		return fmt.Errorf("check database: %w", err)
+	}
+	if exists == 0 {
+		return ErrDatabaseNotFound
+	}
+	known.add(databaseName)
+	return nil
+}
+
+// checkTable 校验库名和表名并确认基表存在，用于把表名拼接进查询之前
+func checkTable(ctx context.Context, db *sql.DB, databaseName, tableName string) error {
+	if err := ValidateIdentifier(databaseName); err != nil {
+		return err
+	}
+	if err := ValidateIdentifier(tableName); err != nil {
+		return err
+	}
+	key := databaseName + "\x00" + tableName
+	if known.has(key) {
+		return nil
+	}
+	var exists int
+	if err := db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND TABLE_TYPE = 'BASE TABLE'",
+		databaseName, tableName).Scan(&exists); err != nil {
+		return fmt.Errorf("check table: %w", err)
+	}
+	if exists == 0 {
+		return ErrTableNotFound
+	}
+	known.add(key)
+	return nil
+}
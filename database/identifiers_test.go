@@ -0,0 +1,70 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateIdentifier(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		valid bool
+	}{
+		{"plain", "orders", true},
+		{"underscore and digits", "order_items_2024", true},
+		{"empty", "", false},
+		{"backtick", "we`ird", true},
+		{"only backticks", "``", true},
+		{"unicode", "订单表", true},
+		{"emoji", "stats_📈", true},
+		{"inner space", "order items", true},
+		{"leading space", " orders", true},
+		{"trailing space", "orders ", false},
+		{"only space", " ", false},
+		{"NUL", "ord\x00ers", false},
+		{"invalid UTF-8", "ord\xffers", false},
+		{"64 characters", strings.Repeat("a", 64), true},
+		{"65 characters", strings.Repeat("a", 65), false},
+		{"64 multibyte characters", strings.Repeat("表", 64), true},
+		{"65 multibyte characters", strings.Repeat("表", 65), false},
+		{"injection attempt", "x` WHERE 1=1; DROP TABLE t; -- ", false},
+		{"injection attempt without trailing space", "x`; DROP TABLE t; --", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIdentifier(tt.input)
+			if tt.valid && err != nil {
+				t.Fatalf("ValidateIdentifier(%q) = %v, want nil", tt.input, err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidIdentifier) {
+				t.Fatalf("ValidateIdentifier(%q) = %v, want ErrInvalidIdentifier", tt.input, err)
+			}
+		})
+	}
+}
+
+func TestQuoteTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		database string
+		table    string
+		want     string
+	}{
+		{"plain", "shop", "orders", "`shop`.`orders`"},
+		{"backtick doubled", "sh`op", "or`ders", "`sh``op`.`or``ders`"},
+		{"only backticks", "`", "``", "````.``````"},
+		{"dot stays inside quotes", "shop.v2", "orders", "`shop.v2`.`orders`"},
+		{"unicode", "商店", "订单", "`商店`.`订单`"},
+		{"spaces", " shop", "order items", "` shop`.`order items`"},
+		{"injection attempt", "shop", "x`; DROP TABLE t; --", "`shop`.`x``; DROP TABLE t; --`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuoteTable(tt.database, tt.table); got != tt.want {
+				t.Fatalf("QuoteTable(%q, %q) = %s, want %s", tt.database, tt.table, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// IdleTrxAction 空闲事务策略对一个会话采取的操作，演练模式下只记录不终止
+type IdleTrxAction struct {
+	Time         time.Time `json:"time"`
+	SessionID    int64     `json:"session_id"`
+	User         string    `json:"user"`
+	Host         string    `json:"host"`
+	DB           string    `json:"db"`
+	TrxID        string    `json:"trx_id"`
+	IdleSeconds  int64     `json:"idle_seconds"`  // 会话空闲 (Sleep) 的秒数
+	TrxSeconds   int64     `json:"trx_seconds"`   // 事务已持续的秒数
+	RowsModified int64     `json:"rows_modified"` // 终止后将回滚的修改行数
+	DryRun       bool      `json:"dry_run"`
+	Killed       bool      `json:"killed"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Describe 操作的单行描述，用于日志和通知
+func (a IdleTrxAction) Describe() string {
+	return fmt.Sprintf("会话 #%d %s@%s 数据库 %s，事务 %s 已持续 %d 秒，空闲 %d 秒，未提交修改 %d 行",
+		a.SessionID, a.User, a.Host, a.DB, a.TrxID, a.TrxSeconds, a.IdleSeconds, a.RowsModified)
+}
+
+// IdleTrxKiller 空闲事务自动终止策略：定期查找在指定数据库上空闲超过阈值的事务并终止其会话
+// 每次操作都写入日志并通过通知回调发出；同一事务只处理一次
+type IdleTrxKiller struct {
+	mu      sync.RWMutex
+	cfg     *config.IdleTrxConfig
+	actions []IdleTrxAction
+	handled map[string]bool // 已处理的会话与事务，演练模式下避免重复通知
+	notify  func(IdleTrxAction)
+	logger  *ComponentLogger
+	stop    chan struct{}
+}
+
+var (
+	idleTrxKiller     *IdleTrxKiller
+	idleTrxKillerOnce sync.Once
+)
+
+// GetIdleTrxKiller 获取空闲事务策略实例
+func GetIdleTrxKiller() *IdleTrxKiller {
+	idleTrxKillerOnce.Do(func() {
+		idleTrxKiller = &IdleTrxKiller{
+			cfg:     config.GetIdleTrxConfig(),
+			handled: make(map[string]bool),
+			logger:  GetDatabaseLogger().Component(ComponentScheduler),
+		}
+	})
+	return idleTrxKiller
+}
+
+// Config 获取策略配置
+func (k *IdleTrxKiller) Config() *config.IdleTrxConfig {
+	return k.cfg
+}
+
+// SetNotifier 设置操作通知回调，每个操作 (包括演练) 调用一次
+func (k *IdleTrxKiller) SetNotifier(notify func(IdleTrxAction)) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.notify = notify
+}
+
+// Start 按指定间隔开始执行策略，interval 为 0 或未配置阈值和数据库时不启动
+func (k *IdleTrxKiller) Start(interval time.Duration) {
+	if interval <= 0 || !k.cfg.Enabled() {
+		return
+	}
+	k.mu.Lock()
+	if k.stop != nil {
+		k.mu.Unlock()
+		return
+	}
+	k.stop = make(chan struct{})
+	stop := k.stop
+	k.mu.Unlock()
+
+	mode := "执行"
+	if k.cfg.DryRun {
+		mode = "演练"
+	}
+	k.logger.Info(fmt.Sprintf("空闲事务自动终止策略已启用 (%s模式)", mode),
+		fmt.Sprintf("空闲超过 %v，数据库 %v", k.cfg.KillAfter, k.cfg.Schemas))
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if _, err := k.Enforce(context.Background()); err != nil {
+					k.logger.Debug("空闲事务检查失败", err.Error())
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止执行策略
+func (k *IdleTrxKiller) Stop() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.stop != nil {
+		close(k.stop)
+		k.stop = nil
+	}
+}
+
+// Enforce 立即执行一次策略，返回本次采取的操作
+func (k *IdleTrxKiller) Enforce(ctx context.Context) ([]IdleTrxAction, error) {
+	db := GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	candidates, err := findIdleTrx(ctx, db, k.cfg.KillAfter, k.cfg.Schemas)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]bool, len(candidates))
+	var actions []IdleTrxAction
+	for _, c := range candidates {
+		key := strconv.FormatInt(c.SessionID, 10) + "/" + c.TrxID
+		current[key] = true
+		k.mu.RLock()
+		handled := k.handled[key]
+		k.mu.RUnlock()
+		if handled {
+			continue
+		}
+
+		action := c
+		action.Time = time.Now()
+		action.DryRun = k.cfg.DryRun
+		if !action.DryRun {
+			action.Error = killIdleSession(ctx, db, c, k.cfg.KillAfter)
+			action.Killed = action.Error == ""
+		}
+		k.record(key, action)
+		actions = append(actions, action)
+	}
+
+	// 已结束的事务不再需要记住
+	k.mu.Lock()
+	for key := range k.handled {
+		if !current[key] {
+			delete(k.handled, key)
+		}
+	}
+	k.mu.Unlock()
+	return actions, nil
+}
+
+// record 保存操作记录，写入审计日志并发送通知
+func (k *IdleTrxKiller) record(key string, action IdleTrxAction) {
+	k.mu.Lock()
+	k.handled[key] = true
+	if k.cfg.MaxActions > 0 && len(k.actions) >= k.cfg.MaxActions {
+		k.actions = k.actions[1:]
+	}
+	k.actions = append(k.actions, action)
+	notify := k.notify
+	k.mu.Unlock()
+
+	switch {
+	case action.DryRun:
+		k.logger.Warn("[演练] 空闲事务策略将终止会话", action.Describe())
+	case action.Killed:
+		k.logger.Warn("空闲事务策略已终止会话", action.Describe())
+	default:
+		k.logger.Error("空闲事务策略终止会话失败", action.Describe()+": "+action.Error)
+	}
+	if notify != nil {
+		notify(action)
+	}
+}
+
+// Actions 最近的操作记录，按时间倒序，limit 为 0 时不限制
+func (k *IdleTrxKiller) Actions(limit int) []IdleTrxAction {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	actions := []IdleTrxAction{}
+	for i := len(k.actions) - 1; i >= 0; i-- {
+		if limit > 0 && len(actions) >= limit {
+			break
+		}
+		actions = append(actions, k.actions[i])
+	}
+	return actions
+}
+
+// idleTrxQuery 查找处于 Sleep 状态且有未提交事务的会话
+const idleTrxQuery = `
+	SELECT p.ID, p.USER, p.HOST, COALESCE(p.DB, ''), p.TIME, t.trx_id,
+	       TIMESTAMPDIFF(SECOND, t.trx_started, NOW()), t.trx_rows_modified
+	FROM information_schema.INNODB_TRX t
+	JOIN information_schema.PROCESSLIST p ON p.ID = t.trx_mysql_thread_id
+	WHERE p.COMMAND = 'Sleep' AND p.TIME >= ? AND p.ID <> CONNECTION_ID()`
+
+// findIdleTrx 查找在指定数据库上空闲超过 idleFor 的事务
+func findIdleTrx(ctx context.Context, db *sql.DB, idleFor time.Duration, schemas []string) ([]IdleTrxAction, error) {
+	rows, err := db.QueryContext(ctx, idleTrxQuery+" ORDER BY p.TIME DESC", int64(idleFor.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("query idle transactions: %w", err)
+	}
+	defer rows.Close()
+
+	allowed := make(map[string]bool, len(schemas))
+	for _, s := range schemas {
+		allowed[s] = true
+	}
+	var found []IdleTrxAction
+	for rows.Next() {
+		var t IdleTrxAction
+		if err := rows.Scan(&t.SessionID, &t.User, &t.Host, &t.DB, &t.IdleSeconds, &t.TrxID, &t.TrxSeconds, &t.RowsModified); err != nil {
+			return nil, fmt.Errorf("scan idle transaction: %w", err)
+		}
+		if allowed[t.DB] {
+			found = append(found, t)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query idle transactions: %w", err)
+	}
+	return found, nil
+}
+
+// killIdleSession 终止前再确认一次会话仍在同一事务中空闲，避免误杀刚恢复执行的会话，返回错误信息
+func killIdleSession(ctx context.Context, db *sql.DB, t IdleTrxAction, idleFor time.Duration) string {
+	var trxID string
+	err := db.QueryRowContext(ctx, idleTrxQuery+" AND p.ID = ?", int64(idleFor.Seconds()), t.SessionID).
+		Scan(new(int64), new(string), new(string), new(string), new(int64), &trxID, new(int64), new(int64))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && trxID != t.TrxID) {
+		return "会话已恢复执行或事务已结束，未终止"
+	}
+	if err != nil {
+		return err.Error()
+	}
+	// KILL 不支持占位符，会话 ID 为整数
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("KILL %d", t.SessionID)); err != nil {
+		return err.Error()
+	}
+	return ""
+}
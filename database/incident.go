@@ -0,0 +1,347 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// 事件类型
+const (
+	EventConnectionLost   = "connection_lost"   // 连接丢失
+	EventReconnectAttempt = "reconnect_attempt" // 一次重连尝试
+	EventReconnected      = "reconnected"       // 重连成功
+	EventReconnectFailed  = "reconnect_failed"  // 重连最终失败
+	EventBlocking         = "blocking"          // 检测到锁等待会话
+	EventTopologyChanged  = "topology_changed"  // 故障切换、DNS 指向或读写角色变化
+)
+
+// maxIncidentEvents 保留的事件数上限
+const maxIncidentEvents = 2000
+
+// maxLatencySamples 保留的延迟样本数上限
+const maxLatencySamples = 10000
+
+// IncidentEvent 故障复盘使用的事件记录
+type IncidentEvent struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Message string    `json:"message"`
+	Value   float64   `json:"value,omitempty"`
+}
+
+// latencySample 状态检查的往返耗时
+type latencySample struct {
+	time     time.Time
+	duration time.Duration
+}
+
+// IncidentRecorder 记录连接事件和延迟样本，供生成故障报告
+type IncidentRecorder struct {
+	mu      sync.Mutex
+	events  []IncidentEvent
+	latency []latencySample
+}
+
+var (
+	incidentRecorder     *IncidentRecorder
+	incidentRecorderOnce sync.Once
+)
+
+// GetIncidentRecorder 获取事件记录器单例
+func GetIncidentRecorder() *IncidentRecorder {
+	incidentRecorderOnce.Do(func() {
+		incidentRecorder = &IncidentRecorder{}
+	})
+	return incidentRecorder
+}
+
+// recordEvent 记录一个事件，超出上限时丢弃最早的记录
+func (r *IncidentRecorder) recordEvent(kind, message string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.events) >= maxIncidentEvents {
+		r.events = r.events[1:]
+	}
+	r.events = append(r.events, IncidentEvent{Time: time.Now(), Kind: kind, Message: message, Value: value})
+}
+
+// recordLatency 记录一次状态检查的耗时
+func (r *IncidentRecorder) recordLatency(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.latency) >= maxLatencySamples {
+		r.latency = r.latency[1:]
+	}
+	r.latency = append(r.latency, latencySample{time: time.Now(), duration: d})
+}
+
+// IncidentReport 指定时间段的故障报告
+type IncidentReport struct {
+	From          time.Time         `json:"from"`
+	To            time.Time         `json:"to"`
+	GeneratedAt   time.Time         `json:"generated_at"`
+	Reconnections ReconnectionStats `json:"reconnections"`
+	Blocking      BlockingStats     `json:"blocking"`
+	Latency       LatencyStats      `json:"latency"`
+	Errors        []IncidentError   `json:"errors"`
+	Events        []IncidentEvent   `json:"events"` // 不含逐次重连尝试
+	Logs          []LogEntry        `json:"logs"`
+	Annotations   []Annotation      `json:"annotations"` // 操作员标注的发布、演练等
+}
+
+// ReconnectionStats 时间段内的连接丢失与重连统计
+type ReconnectionStats struct {
+	ConnectionLost int `json:"connection_lost"`
+	Attempts       int `json:"attempts"`
+	Successes      int `json:"successes"`
+	Failures       int `json:"failures"`
+}
+
+// BlockingStats 时间段内观测到的锁等待
+type BlockingStats struct {
+	Observations int `json:"observations"` // 检测到锁等待的次数
+	MaxSessions  int `json:"max_sessions"` // 单次检测到的最大等待会话数
+}
+
+// LatencyStats 状态检查往返耗时统计 (毫秒)
+type LatencyStats struct {
+	Samples int     `json:"samples"`
+	Min     float64 `json:"min_ms"`
+	Avg     float64 `json:"avg_ms"`
+	P50     float64 `json:"p50_ms"`
+	P95     float64 `json:"p95_ms"`
+	P99     float64 `json:"p99_ms"`
+	Max     float64 `json:"max_ms"`
+}
+
+// IncidentError 时间段内出现的错误
+type IncidentError struct {
+	Type      ErrorType `json:"type"`
+	Code      string    `json:"code"`
+	Count     int       `json:"count"` // 时间段内的次数，按统计桶估算
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Examples  []string  `json:"examples"`
+}
+
+// BuildIncidentReport 汇总时间段内的日志、错误、重连、锁等待和延迟数据
+func BuildIncidentReport(from, to time.Time) *IncidentReport {
+	report := &IncidentReport{
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now(),
+		Errors:      []IncidentError{},
+		Events:      []IncidentEvent{},
+		Logs:        []LogEntry{},
+		Annotations: GetAnnotations().Between(from, to, DefaultProfile),
+	}
+	inWindow := func(t time.Time) bool {
+		return !t.Before(from) && !t.After(to)
+	}
+
+	for _, entry := range GetDatabaseLogger().GetEntries() {
+		if inWindow(entry.Timestamp) {
+			if entry.ConnectionInfo != nil {
+				// 报告可能被广泛传阅，不包含密码
+				info := *entry.ConnectionInfo
+				info.Password = ""
+				entry.ConnectionInfo = &info
+			}
+			report.Logs = append(report.Logs, entry)
+		}
+	}
+
+	for _, summary := range GetErrorAnalyzer().GetErrorSummaries() {
+		if summary.LastSeen.Before(from) || summary.FirstSeen.After(to) {
+			continue
+		}
+		report.Errors = append(report.Errors, IncidentError{
+			Type:      summary.Type,
+			Code:      summary.Code,
+			Count:     countInWindow(summary.FrequencyData, from, to),
+			FirstSeen: summary.FirstSeen,
+			LastSeen:  summary.LastSeen,
+			Examples:  summary.Examples,
+		})
+	}
+	sort.Slice(report.Errors, func(i, j int) bool { return report.Errors[i].Count > report.Errors[j].Count })
+
+	r := GetIncidentRecorder()
+	report.Latency = r.LatencyStats(from, to)
+	r.mu.Lock()
+	for _, event := range r.events {
+		if !inWindow(event.Time) {
+			continue
+		}
+		switch event.Kind {
+		case EventConnectionLost:
+			report.Reconnections.ConnectionLost++
+		case EventReconnectAttempt:
+			report.Reconnections.Attempts++
+			continue
+		case EventReconnected:
+			report.Reconnections.Successes++
+		case EventReconnectFailed:
+			report.Reconnections.Failures++
+		case EventBlocking:
+			report.Blocking.Observations++
+			if n := int(event.Value); n > report.Blocking.MaxSessions {
+				report.Blocking.MaxSessions = n
+			}
+		}
+		report.Events = append(report.Events, event)
+	}
+	r.mu.Unlock()
+	return report
+}
+
+// LatencyStats 统计时间段内状态检查的耗时分布
+func (r *IncidentRecorder) LatencyStats(from, to time.Time) LatencyStats {
+	r.mu.Lock()
+	var latencies []time.Duration
+	for _, s := range r.latency {
+		if !s.time.Before(from) && !s.time.After(to) {
+			latencies = append(latencies, s.duration)
+		}
+	}
+	r.mu.Unlock()
+	return latencyStats(latencies)
+}
+
+// countInWindow 按统计桶估算时间段内的错误数，桶与时间段部分重叠时按重叠比例计入
+func countInWindow(frequency map[string]int, from, to time.Time) int {
+	var total float64
+	for key, count := range frequency {
+		var start time.Time
+		var size time.Duration
+		var err error
+		if len(key) == len(hourKeyFormat) {
+			start, err = time.ParseInLocation(hourKeyFormat, key, from.Location())
+			size = time.Hour
+		} else {
+			start, err = time.ParseInLocation(dayKeyFormat, key, from.Location())
+			size = 24 * time.Hour
+		}
+		if err != nil {
+			continue
+		}
+		end := start.Add(size)
+		overlapStart, overlapEnd := start, end
+		if from.After(overlapStart) {
+			overlapStart = from
+		}
+		if to.Before(overlapEnd) {
+			overlapEnd = to
+		}
+		if overlapEnd.After(overlapStart) {
+			total += float64(count) * float64(overlapEnd.Sub(overlapStart)) / float64(size)
+		}
+	}
+	return int(math.Round(total))
+}
+
+// latencyStats 计算耗时分布
+func latencyStats(samples []time.Duration) LatencyStats {
+	stats := LatencyStats{Samples: len(samples)}
+	if len(samples) == 0 {
+		return stats
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	ms := func(d time.Duration) float64 {
+		return math.Round(float64(d)/float64(time.Millisecond)*100) / 100
+	}
+	percentile := func(q float64) float64 {
+		i := int(math.Ceil(q*float64(len(samples)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return ms(samples[i])
+	}
+
+	var sum time.Duration
+	for _, d := range samples {
+		sum += d
+	}
+	stats.Min = ms(samples[0])
+	stats.Max = ms(samples[len(samples)-1])
+	stats.Avg = ms(sum / time.Duration(len(samples)))
+	stats.P50 = percentile(0.50)
+	stats.P95 = percentile(0.95)
+	stats.P99 = percentile(0.99)
+	return stats
+}
+
+// Markdown 以 Markdown 格式输出报告，便于直接粘贴到复盘文档
+func (r *IncidentReport) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# 故障报告 %s ~ %s\n\n", config.FormatDisplayTime(r.From), config.FormatDisplayTime(r.To))
+	fmt.Fprintf(&b, "生成时间: %s\n\n", config.FormatDisplayTime(r.GeneratedAt))
+
+	b.WriteString("## 概览\n\n")
+	fmt.Fprintf(&b, "- 连接丢失: %d 次\n", r.Reconnections.ConnectionLost)
+	fmt.Fprintf(&b, "- 重连尝试: %d 次 (成功 %d, 最终失败 %d)\n",
+		r.Reconnections.Attempts, r.Reconnections.Successes, r.Reconnections.Failures)
+	fmt.Fprintf(&b, "- 锁等待: 检测到 %d 次, 最多 %d 个会话\n", r.Blocking.Observations, r.Blocking.MaxSessions)
+	if r.Latency.Samples > 0 {
+		fmt.Fprintf(&b, "- 状态检查延迟 (%d 个样本): avg %.2fms, p50 %.2fms, p95 %.2fms, p99 %.2fms, max %.2fms\n",
+			r.Latency.Samples, r.Latency.Avg, r.Latency.P50, r.Latency.P95, r.Latency.P99, r.Latency.Max)
+	} else {
+		b.WriteString("- 状态检查延迟: 无样本\n")
+	}
+
+	b.WriteString("\n## 错误\n\n")
+	if len(r.Errors) == 0 {
+		b.WriteString("无\n")
+	} else {
+		b.WriteString("| 代码 | 类型 | 次数 | 首次出现 | 最近出现 |\n|---|---|---|---|---|\n")
+		for _, e := range r.Errors {
+			fmt.Fprintf(&b, "| %s | %s | %d | %s | %s |\n", e.Code, e.Type, e.Count,
+				config.FormatDisplayTime(e.FirstSeen), config.FormatDisplayTime(e.LastSeen))
+		}
+	}
+
+	b.WriteString("\n## 注释\n\n")
+	if len(r.Annotations) == 0 {
+		b.WriteString("无\n")
+	}
+	for _, a := range r.Annotations {
+		fmt.Fprintf(&b, "- `%s` %s", a.describeRange(), a.Text)
+		if len(a.Tags) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(a.Tags, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n## 事件时间线\n\n")
+	if len(r.Events) == 0 {
+		b.WriteString("无\n")
+	}
+	for _, e := range r.Events {
+		fmt.Fprintf(&b, "- `%s` **%s** %s\n", config.FormatDisplayTime(e.Time), e.Kind, e.Message)
+	}
+
+	b.WriteString("\n## 日志\n\n")
+	if len(r.Logs) == 0 {
+		b.WriteString("无\n")
+	} else {
+		b.WriteString("```\n")
+		logger := GetDatabaseLogger()
+		for _, entry := range r.Logs {
+			fmt.Fprintf(&b, "%s [%s] %s", config.FormatDisplayTime(entry.Timestamp), logger.getLevelString(entry.Level), entry.Message)
+			if entry.Details != "" {
+				fmt.Fprintf(&b, " | %s", entry.Details)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("```\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,218 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 建议的严重程度
+const (
+	AdviceOK      = "ok"
+	AdviceInfo    = "info"
+	AdviceWarning = "warning"
+)
+
+const (
+	// lockWaitTimeoutMax 超过该值时被阻塞的会话长时间占用连接，OLTP 场景建议不超过 60 秒
+	lockWaitTimeoutMax = 60
+	// lockWaitTimeoutMin 低于该值时正常的短暂锁等待也会失败
+	lockWaitTimeoutMin = 5
+	// deadlockTimeoutMax 关闭死锁检测时锁等待超时的上限，死锁只能靠超时解除
+	deadlockTimeoutMax = 10
+)
+
+// LockSettings 与锁等待相关的全局设置，变量不存在时对应字段为空
+type LockSettings struct {
+	LockWaitTimeout int64  `json:"innodb_lock_wait_timeout"`
+	DeadlockDetect  string `json:"innodb_deadlock_detect,omitempty"` // MySQL 5.7.15 之前和 MariaDB 没有该变量
+	IsolationLevel  string `json:"transaction_isolation"`
+	Autocommit      string `json:"autocommit"`
+	BinlogFormat    string `json:"binlog_format,omitempty"`
+}
+
+// BlockHistoryStats 建议所依据的阻塞历史统计
+type BlockHistoryStats struct {
+	Since          time.Time `json:"since"`
+	Events         int       `json:"events"`
+	RowLockEvents  int       `json:"row_lock_events"`
+	IdleBlockers   int       `json:"idle_blockers"`    // 持锁会话没有正在执行的语句 (事务中空闲)
+	TimedOut       int       `json:"timed_out"`        // 等待时间达到 innodb_lock_wait_timeout 的阻塞
+	P95WaitSeconds int64     `json:"p95_wait_seconds"` // 各阻塞最长等待时间的 95 分位
+	MaxWaitSeconds int64     `json:"max_wait_seconds"`
+}
+
+// LockAdvice 一条调优建议
+type LockAdvice struct {
+	Setting     string `json:"setting"`
+	Current     string `json:"current"`
+	Recommended string `json:"recommended,omitempty"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+}
+
+// LockAdvisorReport 锁设置检查结果
+type LockAdvisorReport struct {
+	Settings  LockSettings      `json:"settings"`
+	History   BlockHistoryStats `json:"history"`
+	Advice    []LockAdvice      `json:"advice"`
+	CheckedAt time.Time         `json:"checked_at"`
+}
+
+// AdviseLockSettings 读取锁相关的全局变量，结合 since 之后的阻塞事件给出调优建议
+func AdviseLockSettings(ctx context.Context, since time.Time) (*LockAdvisorReport, error) {
+	variables, err := defaultStore.GetGlobalVariables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings := lockSettingsFromVariables(variables)
+	stats := blockHistoryStats(GetBlockScanner().Events(since, 0), settings.LockWaitTimeout)
+	stats.Since = since
+	return &LockAdvisorReport{
+		Settings:  settings,
+		History:   stats,
+		Advice:    adviseLockSettings(settings, stats),
+		CheckedAt: time.Now(),
+	}, nil
+}
+
+// lockSettingsFromVariables 从全局变量中取出锁相关设置，5.7 之前的隔离级别变量名为 tx_isolation
+func lockSettingsFromVariables(variables map[string]string) LockSettings {
+	settings := LockSettings{
+		DeadlockDetect: variables["innodb_deadlock_detect"],
+		IsolationLevel: variables["transaction_isolation"],
+		Autocommit:     variables["autocommit"],
+		BinlogFormat:   variables["binlog_format"],
+	}
+	if settings.IsolationLevel == "" {
+		settings.IsolationLevel = variables["tx_isolation"]
+	}
+	settings.LockWaitTimeout, _ = strconv.ParseInt(variables["innodb_lock_wait_timeout"], 10, 64)
+	return settings
+}
+
+// blockingStats 统计阻塞事件，timeout 为 0 时不统计超时次数
+func blockHistoryStats(events []BlockEvent, timeout int64) BlockHistoryStats {
+	stats := BlockHistoryStats{Events: len(events)}
+	waits := make([]int64, 0, len(events))
+	for _, e := range events {
+		if e.LockType == LockTypeRow {
+			stats.RowLockEvents++
+		}
+		if e.Blocker.Query == "" {
+			stats.IdleBlockers++
+		}
+		// 扫描间隔内的等待观察不到，差 1 秒也视为达到超时
+		if timeout > 0 && e.LockType == LockTypeRow && e.MaxWaitSeconds >= timeout-1 {
+			stats.TimedOut++
+		}
+		waits = append(waits, e.MaxWaitSeconds)
+		if e.MaxWaitSeconds > stats.MaxWaitSeconds {
+			stats.MaxWaitSeconds = e.MaxWaitSeconds
+		}
+	}
+	if len(waits) > 0 {
+		sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+		stats.P95WaitSeconds = waits[(len(waits)*95-1)/100]
+	}
+	return stats
+}
+
+// adviseLockSettings 按最佳实践范围和阻塞历史逐项检查，没有问题的设置也给出一条 ok 结论
+func adviseLockSettings(s LockSettings, stats BlockHistoryStats) []LockAdvice {
+	var advice []LockAdvice
+	add := func(setting, current, recommended, severity, format string, args ...interface{}) {
+		advice = append(advice, LockAdvice{
+			Setting:     setting,
+			Current:     current,
+			Recommended: recommended,
+			Severity:    severity,
+			Message:     fmt.Sprintf(format, args...),
+		})
+	}
+
+	// innodb_lock_wait_timeout
+	timeout := strconv.FormatInt(s.LockWaitTimeout, 10)
+	switch {
+	case s.LockWaitTimeout == 0:
+	case stats.TimedOut > 0:
+		add("innodb_lock_wait_timeout", timeout, timeout, AdviceWarning,
+			"最近有 %d 次阻塞的等待达到了超时，被阻塞的语句以 ERROR 1205 失败；应处理持锁会话 (其中 %d 次持锁会话在事务中空闲)，调大超时只会让等待更久",
+			stats.TimedOut, stats.IdleBlockers)
+	case s.LockWaitTimeout > lockWaitTimeoutMax:
+		recommended := int64(lockWaitTimeoutMax)
+		if stats.Events > 0 {
+			// 为最近阻塞等待的 95 分位留出一倍余量
+			recommended = stats.P95WaitSeconds * 2
+			if recommended < 10 {
+				recommended = 10
+			}
+			if recommended > lockWaitTimeoutMax {
+				recommended = lockWaitTimeoutMax
+			}
+		}
+		add("innodb_lock_wait_timeout", timeout, strconv.FormatInt(recommended, 10), AdviceWarning,
+			"超时过长，被阻塞的会话会长时间占用连接和线程，阻塞容易堆积成连接耗尽；最近阻塞的等待 95 分位为 %d 秒，建议调整为 %d 秒",
+			stats.P95WaitSeconds, recommended)
+	case s.LockWaitTimeout < lockWaitTimeoutMin:
+		add("innodb_lock_wait_timeout", timeout, strconv.Itoa(lockWaitTimeoutMin), AdviceInfo,
+			"超时过短，正常的短暂锁等待也会失败，应用需要处理 ERROR 1205 并重试；没有特殊需求时建议不低于 %d 秒", lockWaitTimeoutMin)
+	default:
+		add("innodb_lock_wait_timeout", timeout, "", AdviceOK, "在建议范围 %d-%d 秒内", lockWaitTimeoutMin, lockWaitTimeoutMax)
+	}
+
+	// innodb_deadlock_detect
+	switch {
+	case s.DeadlockDetect == "":
+	case !isOn(s.DeadlockDetect) && s.LockWaitTimeout > deadlockTimeoutMax:
+		add("innodb_deadlock_detect", s.DeadlockDetect, "ON", AdviceWarning,
+			"死锁检测已关闭，死锁只能等 innodb_lock_wait_timeout (%d 秒) 超时后解除；除非高并发下死锁检测本身成为瓶颈，建议开启，否则需将超时降到 %d 秒以内",
+			s.LockWaitTimeout, deadlockTimeoutMax)
+	case !isOn(s.DeadlockDetect):
+		add("innodb_deadlock_detect", s.DeadlockDetect, "", AdviceInfo,
+			"死锁检测已关闭，死锁依赖较短的锁等待超时解除，确认这是针对高并发热点行的有意设置")
+	default:
+		add("innodb_deadlock_detect", s.DeadlockDetect, "", AdviceOK, "死锁会被立即检测并回滚代价较小的事务")
+	}
+
+	// transaction_isolation
+	level := strings.ToUpper(strings.ReplaceAll(s.IsolationLevel, "_", "-"))
+	statementBinlog := strings.EqualFold(s.BinlogFormat, "STATEMENT")
+	switch {
+	case level == "":
+	case level == "SERIALIZABLE":
+		add("transaction_isolation", s.IsolationLevel, "REPEATABLE-READ", AdviceWarning,
+			"SERIALIZABLE 下普通 SELECT 也会加共享锁，读写互相阻塞，只在确有需要的会话中单独设置")
+	case level == "READ-UNCOMMITTED":
+		add("transaction_isolation", s.IsolationLevel, "READ-COMMITTED", AdviceWarning,
+			"READ-UNCOMMITTED 会读到未提交的数据 (脏读)，不应作为全局默认值")
+	case level == "READ-COMMITTED" && statementBinlog:
+		add("transaction_isolation", s.IsolationLevel, "REPEATABLE-READ", AdviceWarning,
+			"READ-COMMITTED 与 binlog_format=STATEMENT 组合时写入语句会失败，需要改用 ROW 格式或 REPEATABLE-READ")
+	case level == "REPEATABLE-READ" && stats.RowLockEvents > 0 && !statementBinlog:
+		add("transaction_isolation", s.IsolationLevel, "READ-COMMITTED", AdviceInfo,
+			"最近有 %d 次行锁阻塞；REPEATABLE-READ 下范围更新和删除会加间隙锁 (next-key lock)，阻塞范围比实际修改的行更大，应用不依赖可重复读时可改为 READ-COMMITTED",
+			stats.RowLockEvents)
+	default:
+		add("transaction_isolation", s.IsolationLevel, "", AdviceOK, "没有发现与隔离级别相关的问题")
+	}
+
+	// autocommit
+	switch {
+	case s.Autocommit == "":
+	case !isOn(s.Autocommit):
+		add("autocommit", s.Autocommit, "ON", AdviceWarning,
+			"全局关闭了自动提交，每个会话的第一条语句就开启事务，忘记提交的会话会一直持有锁 (最近 %d 次阻塞的持锁会话在事务中空闲)；建议开启，由应用显式开启事务",
+			stats.IdleBlockers)
+	case stats.IdleBlockers > 0:
+		add("autocommit", s.Autocommit, "", AdviceInfo,
+			"最近有 %d 次阻塞的持锁会话在事务中空闲，可能是应用或连接池关闭了自动提交却没有及时提交；检查连接参数，必要时启用 IDLE_TRX_KILL_AFTER 自动终止空闲事务",
+			stats.IdleBlockers)
+	default:
+		add("autocommit", s.Autocommit, "", AdviceOK, "自动提交已开启")
+	}
+	return advice
+}
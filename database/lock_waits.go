@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// 锁类型
+const (
+	LockTypeRow      = "row"      // InnoDB 行锁
+	LockTypeMetadata = "metadata" // 元数据锁
+)
+
+// LockWait 一个会话等待另一个会话持有的锁
+type LockWait struct {
+	LockType      string `json:"lock_type"`
+	Table         string `json:"table,omitempty"`
+	WaitingPID    int64  `json:"waiting_pid"`
+	WaitingQuery  string `json:"waiting_query,omitempty"`
+	WaitSeconds   int64  `json:"wait_seconds"`
+	BlockingPID   int64  `json:"blocking_pid"`
+	BlockingUser  string `json:"blocking_user,omitempty"`
+	BlockingHost  string `json:"blocking_host,omitempty"`
+	BlockingQuery string `json:"blocking_query,omitempty"` // 持锁会话正在执行的语句，事务空闲时为空
+	// 双方会话的事务隔离级别，performance_schema 不可用时为空
+	WaitingIsolation  string `json:"waiting_isolation,omitempty"`
+	BlockingIsolation string `json:"blocking_isolation,omitempty"`
+}
+
+// GetLockWaits 获取当前的锁等待关系
+func GetLockWaits(ctx context.Context) ([]LockWait, error) {
+	return defaultStore.GetLockWaits(ctx)
+}
+
+// GetLockWaits 读取行锁和元数据锁的等待关系
+// 行锁优先使用 sys.innodb_lock_waits (5.7+)，不可用时 (MariaDB 等) 使用 INNODB_LOCK_WAITS；
+// 元数据锁依赖 sys.schema_table_lock_waits 和 mdl instrument，不可用时忽略
+func (s *MySQLStore) GetLockWaits(ctx context.Context) ([]LockWait, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT COALESCE(w.locked_table, ''), w.waiting_pid, COALESCE(w.waiting_query, ''), w.wait_age_secs,
+		       w.blocking_pid, COALESCE(p.USER, ''), COALESCE(p.HOST, ''), COALESCE(p.INFO, '')
+		FROM sys.innodb_lock_waits w
+		LEFT JOIN information_schema.PROCESSLIST p ON p.ID = w.blocking_pid`)
+	if err != nil {
+		rows, err = db.QueryContext(ctx, `
+			SELECT COALESCE(l.lock_table, ''), r.trx_mysql_thread_id, COALESCE(r.trx_query, ''),
+			       TIMESTAMPDIFF(SECOND, r.trx_wait_started, NOW()),
+			       b.trx_mysql_thread_id, COALESCE(p.USER, ''), COALESCE(p.HOST, ''), COALESCE(b.trx_query, '')
+			FROM information_schema.INNODB_LOCK_WAITS w
+			JOIN information_schema.INNODB_TRX b ON b.trx_id = w.blocking_trx_id
+			JOIN information_schema.INNODB_TRX r ON r.trx_id = w.requesting_trx_id
+			LEFT JOIN information_schema.INNODB_LOCKS l ON l.lock_id = w.requested_lock_id
+			LEFT JOIN information_schema.PROCESSLIST p ON p.ID = b.trx_mysql_thread_id`)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query lock waits: %w", err)
+	}
+	waits, err := scanLockWaits(rows, LockTypeRow)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT CONCAT(w.object_schema, '.', w.object_name), w.waiting_pid, COALESCE(w.waiting_query, ''), w.waiting_query_secs,
+		       w.blocking_pid, COALESCE(p.USER, ''), COALESCE(p.HOST, ''), COALESCE(p.INFO, '')
+		FROM sys.schema_table_lock_waits w
+		LEFT JOIN information_schema.PROCESSLIST p ON p.ID = w.blocking_pid
+		WHERE w.waiting_pid <> w.blocking_pid`)
+	if err != nil {
+		setLockWaitIsolation(ctx, db, waits)
+		return waits, nil
+	}
+	metadataWaits, err := scanLockWaits(rows, LockTypeMetadata)
+	if err != nil {
+		return nil, err
+	}
+	waits = append(waits, metadataWaits...)
+	setLockWaitIsolation(ctx, db, waits)
+	return waits, nil
+}
+
+// setLockWaitIsolation 填充锁等待双方的隔离级别，没有锁等待时不查询
+func setLockWaitIsolation(ctx context.Context, db *sql.DB, waits []LockWait) {
+	if len(waits) == 0 {
+		return
+	}
+	isolation := sessionIsolationLevels(ctx, db)
+	for i := range waits {
+		waits[i].WaitingIsolation = isolation[waits[i].WaitingPID]
+		waits[i].BlockingIsolation = isolation[waits[i].BlockingPID]
+	}
+}
+
+// scanLockWaits 读取锁等待查询的结果并关闭 rows
+func scanLockWaits(rows *sql.Rows, lockType string) ([]LockWait, error) {
+	defer rows.Close()
+	waits := []LockWait{}
+	for rows.Next() {
+		w := LockWait{LockType: lockType}
+		var waitSeconds sql.NullInt64
+		if err := rows.Scan(&w.Table, &w.WaitingPID, &w.WaitingQuery, &waitSeconds,
+			&w.BlockingPID, &w.BlockingUser, &w.BlockingHost, &w.BlockingQuery); err != nil {
+			return nil, fmt.Errorf("scan lock wait: %w", err)
+		}
+		w.WaitSeconds = waitSeconds.Int64
+		waits = append(waits, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query lock waits: %w", err)
+	}
+	return waits, nil
+}
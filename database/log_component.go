@@ -0,0 +1,150 @@
+package database
+
+import "fmt"
+
+// LogComponent 产生日志的组件，各组件可单独设置日志级别
+type LogComponent string
+
+const (
+	ComponentReconnector LogComponent = "reconnector" // 连接建立、重连与故障转移
+	ComponentExplorer    LogComponent = "explorer"    // 数据库浏览查询
+	ComponentHTTP        LogComponent = "http"        // HTTP 请求
+	ComponentScheduler   LogComponent = "scheduler"   // 采样、扫描、告警评估等后台任务
+	ComponentNotifier    LogComponent = "notifier"    // 告警通知与心跳推送
+	ComponentExternal    LogComponent = "external"    // 外部脚本 (如备份任务) 通过 API 写入的事件
+)
+
+// LogComponents 所有可单独设置日志级别的组件
+var LogComponents = []LogComponent{
+	ComponentReconnector,
+	ComponentExplorer,
+	ComponentHTTP,
+	ComponentScheduler,
+	ComponentNotifier,
+	ComponentExternal,
+}
+
+// ParseLogComponent 解析组件名称
+func ParseLogComponent(name string) (LogComponent, error) {
+	for _, c := range LogComponents {
+		if string(c) == name {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown log component %q", name)
+}
+
+// LogLevel 获取全局日志级别
+func (dl *DatabaseLogger) LogLevel() LogLevel {
+	dl.mu.RLock()
+	defer dl.mu.RUnlock()
+	return dl.currentLevel
+}
+
+// SetComponentLogLevel 设置组件的日志级别，覆盖全局日志级别
+func (dl *DatabaseLogger) SetComponentLogLevel(component LogComponent, level LogLevel) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	dl.componentLevels[component] = level
+}
+
+// ResetComponentLogLevel 取消组件的日志级别，之后使用全局日志级别
+func (dl *DatabaseLogger) ResetComponentLogLevel(component LogComponent) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	delete(dl.componentLevels, component)
+}
+
+// ComponentLogLevels 获取单独设置了日志级别的组件
+func (dl *DatabaseLogger) ComponentLogLevels() map[LogComponent]LogLevel {
+	dl.mu.RLock()
+	defer dl.mu.RUnlock()
+	levels := make(map[LogComponent]LogLevel, len(dl.componentLevels))
+	for c, level := range dl.componentLevels {
+		levels[c] = level
+	}
+	return levels
+}
+
+// Enabled 判断组件的该级别日志是否会被记录，组件为空时按全局日志级别判断
+func (dl *DatabaseLogger) Enabled(component LogComponent, level LogLevel) bool {
+	dl.mu.RLock()
+	defer dl.mu.RUnlock()
+	if min, ok := dl.componentLevels[component]; ok {
+		return level >= min
+	}
+	return level >= dl.currentLevel
+}
+
+// ComponentLogger 写入指定组件日志的记录器
+type ComponentLogger struct {
+	dl        *DatabaseLogger
+	component LogComponent
+}
+
+// Component 获取写入指定组件日志的记录器
+func (dl *DatabaseLogger) Component(component LogComponent) *ComponentLogger {
+	return &ComponentLogger{dl: dl, component: component}
+}
+
+// Enabled 判断该级别的日志是否会被记录，可在构造耗时的日志内容前判断
+func (cl *ComponentLogger) Enabled(level LogLevel) bool {
+	return cl.dl.Enabled(cl.component, level)
+}
+
+// Debug 记录调试日志
+func (cl *ComponentLogger) Debug(message string, details ...string) {
+	cl.log(LogLevelDebug, message, details)
+}
+
+// Info 记录信息日志
+func (cl *ComponentLogger) Info(message string, details ...string) {
+	cl.log(LogLevelInfo, message, details)
+}
+
+// Warn 记录警告日志
+func (cl *ComponentLogger) Warn(message string, details ...string) {
+	cl.log(LogLevelWarn, message, details)
+}
+
+// Error 记录错误日志
+func (cl *ComponentLogger) Error(message string, details ...string) {
+	cl.log(LogLevelError, message, details)
+}
+
+// log 记录日志，details 只使用第一项
+func (cl *ComponentLogger) log(level LogLevel, message string, details []string) {
+	detail := ""
+	if len(details) > 0 {
+		detail = details[0]
+	}
+	cl.dl.addComponentEntry(cl.component, level, message, detail)
+}
+
+// addEntry 记录日志，可附带连接信息
+func (cl *ComponentLogger) addEntry(level LogLevel, message, details string, connInfo ...*ConnectionInfo) {
+	cl.dl.addComponentEntry(cl.component, level, message, details, connInfo...)
+}
+
+// InfoWithConnection 记录包含连接信息的信息日志
+func (cl *ComponentLogger) InfoWithConnection(message string, connInfo *ConnectionInfo, details ...string) {
+	detail := ""
+	if len(details) > 0 {
+		detail = details[0]
+	}
+	cl.addEntry(LogLevelInfo, message, detail, connInfo)
+}
+
+// ErrorWithConnection 记录包含连接信息的错误日志
+func (cl *ComponentLogger) ErrorWithConnection(message string, connInfo *ConnectionInfo, details ...string) {
+	detail := ""
+	if len(details) > 0 {
+		detail = details[0]
+	}
+	cl.addEntry(LogLevelError, message, detail, connInfo)
+}
+
+// addEntryWithConnection 记录包含连接信息的日志
+func (cl *ComponentLogger) addEntryWithConnection(level LogLevel, message, details string, connInfo *ConnectionInfo) {
+	cl.addEntry(level, message, details, connInfo)
+}
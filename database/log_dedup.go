@@ -0,0 +1,130 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// dedupKey 判断日志是否重复的依据
+type dedupKey struct {
+	component LogComponent
+	level     LogLevel
+	source    string
+	message   string
+}
+
+// dedupState 最近出现过的消息
+type dedupState struct {
+	id       int64 // 重复出现时合并到的条目
+	lastSeen time.Time
+	repeats  int // 上次汇总之后被合并的次数
+}
+
+// logDedup 按时间窗口合并重复日志的设置和状态
+type logDedup struct {
+	window   time.Duration
+	interval time.Duration
+	recent   map[dedupKey]*dedupState
+}
+
+// newLogDedup 从配置创建重复日志合并设置
+func newLogDedup(cfg *config.LogConfig) logDedup {
+	return logDedup{
+		window:   cfg.DedupWindow,
+		interval: cfg.DedupSummaryInterval,
+		recent:   make(map[dedupKey]*dedupState),
+	}
+}
+
+// addLocked 添加条目，窗口内出现过的相同消息合并到已有条目且不再输出到标准日志
+// 返回添加或合并后的条目，调用方需持有 dl.mu
+func (dl *DatabaseLogger) addLocked(entry LogEntry) LogEntry {
+	key := dedupKey{component: entry.Component, level: entry.Level, source: entry.Source, message: entry.Message}
+	if dl.suppressDuplicates {
+		if merged, ok := dl.mergeDuplicateLocked(key, entry.Timestamp); ok {
+			return merged
+		}
+	}
+	entry = dl.appendLocked(entry)
+	if dl.suppressDuplicates {
+		dl.rememberLocked(key, entry)
+	}
+	return entry
+}
+
+// mergeDuplicateLocked 消息在窗口内出现过时合并到已有条目并返回合并后的条目，调用方需持有 dl.mu
+// 已有条目被清空或淘汰时返回 false，由调用方添加新条目
+func (dl *DatabaseLogger) mergeDuplicateLocked(key dedupKey, now time.Time) (LogEntry, bool) {
+	s, ok := dl.dedup.recent[key]
+	if !ok || now.Sub(s.lastSeen) > dl.dedup.window {
+		return LogEntry{}, false
+	}
+	for i := len(dl.entries) - 1; i >= 0; i-- {
+		if dl.entries[i].ID == s.id {
+			dl.entries[i].Count++
+			dl.entries[i].Timestamp = now
+			s.lastSeen = now
+			s.repeats++
+			return dl.entries[i], true
+		}
+	}
+	return LogEntry{}, false
+}
+
+// rememberLocked 记录新添加的条目，之后窗口内出现的相同消息合并到该条目，调用方需持有 dl.mu
+func (dl *DatabaseLogger) rememberLocked(key dedupKey, entry LogEntry) {
+	s, ok := dl.dedup.recent[key]
+	if !ok {
+		s = &dedupState{}
+		dl.dedup.recent[key] = s
+	}
+	// 尚未汇总的重复次数保留到下次汇总
+	s.id, s.lastSeen = entry.ID, entry.Timestamp
+}
+
+// summarizeDuplicates 定期为被合并的消息写入汇总条目，日志管理器与进程同生命周期
+func (dl *DatabaseLogger) summarizeDuplicates(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		dl.flushDuplicateSummaries(time.Now())
+	}
+}
+
+// flushDuplicateSummaries 为上次汇总之后被合并过的消息各写入一条汇总，并清理超出窗口的消息
+// 汇总条目最高为警告级别，避免重复的错误在级别统计中被计算两次
+func (dl *DatabaseLogger) flushDuplicateSummaries(now time.Time) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+
+	keys := make([]dedupKey, 0, len(dl.dedup.recent))
+	for key, s := range dl.dedup.recent {
+		if s.repeats > 0 {
+			keys = append(keys, key)
+		} else if now.Sub(s.lastSeen) > dl.dedup.window {
+			delete(dl.dedup.recent, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return dl.dedup.recent[keys[i]].id < dl.dedup.recent[keys[j]].id })
+
+	for _, key := range keys {
+		s := dl.dedup.recent[key]
+		level := key.level
+		if level > LogLevelWarn {
+			level = LogLevelWarn
+		}
+		dl.appendLocked(LogEntry{
+			Level:     level,
+			Message:   fmt.Sprintf("消息 \"%s\" 在最近 %v 内重复了 %d 次", key.message, dl.dedup.interval, s.repeats),
+			Timestamp: now,
+			Details:   "最后一次出现于 " + config.FormatDisplayTime(s.lastSeen),
+			Count:     1,
+			Component: key.component,
+			Source:    key.source,
+		})
+		s.repeats = 0
+	}
+}
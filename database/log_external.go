@@ -0,0 +1,47 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// maxExternalMessageLen 外部日志消息和详情的最大长度 (字节)
+const maxExternalMessageLen = 4096
+
+// externalSourcePattern 外部来源名称，如 backup-job、deploy.v2
+var externalSourcePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ValidateExternalLog 检查外部写入的日志，致命级别只能由本进程产生
+func ValidateExternalLog(source string, level LogLevel, message, details string) error {
+	switch {
+	case !externalSourcePattern.MatchString(source):
+		return fmt.Errorf("source 必须为 1-64 个字母、数字、点、下划线或连字符")
+	case message == "":
+		return fmt.Errorf("message 不能为空")
+	case len(message) > maxExternalMessageLen || len(details) > maxExternalMessageLen:
+		return fmt.Errorf("message 和 details 不能超过 %d 字节", maxExternalMessageLen)
+	case level == LogLevelFatal:
+		return fmt.Errorf("外部日志不能使用 fatal 级别")
+	}
+	return nil
+}
+
+// AddExternal 记录外部来源写入的日志，归入 external 组件并标注来源，与本进程的日志在同一时间线上
+// 低于 external 组件日志级别时不记录并返回 false；窗口内重复的消息合并到已有条目
+func (dl *DatabaseLogger) AddExternal(source string, level LogLevel, message, details string) (LogEntry, bool) {
+	if !dl.Enabled(ComponentExternal, level) {
+		return LogEntry{}, false
+	}
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	return dl.addLocked(LogEntry{
+		Level:     level,
+		Message:   message,
+		Timestamp: time.Now(),
+		Details:   details,
+		Count:     1,
+		Component: ComponentExternal,
+		Source:    source,
+	}), true
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+var (
+	// ErrLogEntryNotFound 日志条目不存在或已被淘汰
+	ErrLogEntryNotFound = errors.New("log entry not found")
+	// ErrTooManyPinned 固定的条目数已达上限
+	ErrTooManyPinned = errors.New("too many pinned log entries")
+)
+
+// logLevelNames 日志级别的英文名称，与接口和配置中使用的名称一致
+var logLevelNames = map[string]LogLevel{
+	"debug": LogLevelDebug,
+	"info":  LogLevelInfo,
+	"warn":  LogLevelWarn,
+	"error": LogLevelError,
+	"fatal": LogLevelFatal,
+}
+
+// ParseLogLevel 解析日志级别名称 (debug/info/warn/error/fatal)
+func ParseLogLevel(name string) (LogLevel, error) {
+	if level, ok := logLevelNames[strings.ToLower(name)]; ok {
+		return level, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", name)
+}
+
+// logRetention 按级别保留和固定条目的设置
+type logRetention struct {
+	levelLimits map[LogLevel]int // 按级别保留的条数
+	clearKeep   *LogLevel        // 清空时保留该级别及以上的条目，为 nil 时全部清空
+	maxPinned   int
+}
+
+// newLogRetention 从配置创建保留设置，无法识别的级别名称被忽略 (由 -validate 报告)
+func newLogRetention(cfg *config.LogConfig) logRetention {
+	r := logRetention{levelLimits: make(map[LogLevel]int), maxPinned: cfg.MaxPinned}
+	for name, limit := range cfg.Retention {
+		if level, err := ParseLogLevel(name); err == nil {
+			r.levelLimits[level] = limit
+		}
+	}
+	if cfg.ClearKeepLevel != "" {
+		if level, err := ParseLogLevel(cfg.ClearKeepLevel); err == nil {
+			r.clearKeep = &level
+		}
+	}
+	return r
+}
+
+// evictLocked 为即将添加的 level 级别条目腾出空间，固定的条目不参与淘汰，调用方需持有 dl.mu
+// 该级别达到按级别保留的条数时淘汰该级别最早的条目；总数达到上限时淘汰级别最低的条目中最早的一条
+func (dl *DatabaseLogger) evictLocked(level LogLevel) {
+	counts := make(map[LogLevel]int)
+	total := 0
+	for _, e := range dl.entries {
+		if !e.Pinned {
+			counts[e.Level]++
+			total++
+		}
+	}
+	if limit, ok := dl.retention.levelLimits[level]; ok && counts[level] >= limit {
+		dl.removeOldestLocked(level)
+		counts[level]--
+		total--
+	}
+	if dl.maxEntries <= 0 || total < dl.maxEntries {
+		return
+	}
+	lowest := LogLevelFatal
+	for l, n := range counts {
+		if n > 0 && l < lowest {
+			lowest = l
+		}
+	}
+	dl.removeOldestLocked(lowest)
+}
+
+// removeOldestLocked 删除指定级别最早的未固定条目，调用方需持有 dl.mu
+func (dl *DatabaseLogger) removeOldestLocked(level LogLevel) {
+	for i, e := range dl.entries {
+		if !e.Pinned && e.Level == level {
+			dl.entries = append(dl.entries[:i], dl.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// Pin 固定日志条目，固定的条目在清空日志和淘汰时保留
+func (dl *DatabaseLogger) Pin(id int64) error {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	pinned := 0
+	index := -1
+	for i, e := range dl.entries {
+		if e.Pinned {
+			pinned++
+		}
+		if e.ID == id {
+			index = i
+		}
+	}
+	if index < 0 {
+		return ErrLogEntryNotFound
+	}
+	if dl.entries[index].Pinned {
+		return nil
+	}
+	if dl.retention.maxPinned > 0 && pinned >= dl.retention.maxPinned {
+		return ErrTooManyPinned
+	}
+	dl.entries[index].Pinned = true
+	return nil
+}
+
+// Unpin 取消固定日志条目，之后按正常规则淘汰
+func (dl *DatabaseLogger) Unpin(id int64) error {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	for i := range dl.entries {
+		if dl.entries[i].ID == id {
+			dl.entries[i].Pinned = false
+			return nil
+		}
+	}
+	return ErrLogEntryNotFound
+}
+
+// PinnedEntries 获取固定的日志条目，按时间先后
+func (dl *DatabaseLogger) PinnedEntries() []LogEntry {
+	dl.mu.RLock()
+	defer dl.mu.RUnlock()
+	entries := []LogEntry{}
+	for _, e := range dl.entries {
+		if e.Pinned {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// timestampWriter 在标准日志的每一行前加上按配置格式化的时间，log 包保证每行只调用一次 Write
+type timestampWriter struct {
+	out      io.Writer
+	location *time.Location
+	layout   string
+}
+
+// Write 实现 io.Writer
+func (w timestampWriter) Write(p []byte) (int, error) {
+	line := make([]byte, 0, len(w.layout)+1+len(p))
+	line = append(line, time.Now().In(w.location).Format(w.layout)...)
+	line = append(line, ' ')
+	line = append(line, p...)
+	if _, err := w.out.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// UseLogTimeFormat 标准日志 (包括日志管理器的输出) 使用 TIME_FORMAT_LOG 格式的时间，未配置时保持 log 包的默认格式
+func UseLogTimeFormat(cfg *config.TimeConfig) {
+	if cfg.LogFormat == "" {
+		return
+	}
+	log.SetFlags(0)
+	log.SetOutput(timestampWriter{out: os.Stderr, location: cfg.Location, layout: cfg.LogFormat})
+}
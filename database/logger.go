@@ -3,9 +3,10 @@ package database
 import (
 	"fmt"
 	"log"
-	"os"
 	"sync"
 	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
 )
 
 // LogLevel 日志级别
@@ -21,12 +22,16 @@ const (
 
 // LogEntry 日志条目
 type LogEntry struct {
+	ID             int64              `json:"id"`
 	Level          LogLevel           `json:"level"`
 	Message        string             `json:"message"`
 	Timestamp      time.Time          `json:"timestamp"`
 	Details        string             `json:"details,omitempty"`
 	Count          int                `json:"count,omitempty"` // 用于记录重复日志的次数
+	Component      LogComponent       `json:"component,omitempty"` // 产生日志的组件
 	ConnectionInfo *ConnectionInfo    `json:"connection_info,omitempty"` // 数据库连接信息
+	Source         string             `json:"source,omitempty"` // 外部来源名称，仅 external 组件的条目有值
+	Pinned         bool               `json:"pinned,omitempty"` // 固定的条目不会被清空或淘汰
 }
 
 // ConnectionInfo 数据库连接信息
@@ -44,8 +49,13 @@ type DatabaseLogger struct {
 	entries      []LogEntry
 	maxEntries   int
 	currentLevel LogLevel
-	lastEntry    *LogEntry
 	suppressDuplicates bool
+	dedup        logDedup // 按时间窗口合并重复日志
+	name         string // 所属服务器名称，默认连接为空，非空时输出到标准日志时作为前缀
+	componentLevels map[LogComponent]LogLevel // 按组件覆盖的日志级别
+	nextID       int64
+	retention    logRetention // 按级别保留和固定条目的设置
+	fatalExit    bool         // 致命日志立即退出进程 (LOG_FATAL_EXIT)
 }
 
 var (
@@ -56,16 +66,29 @@ var (
 // GetDatabaseLogger 获取数据库日志管理器实例
 func GetDatabaseLogger() *DatabaseLogger {
 	loggerOnce.Do(func() {
-		dbLogger = &DatabaseLogger{
-			entries:           make([]LogEntry, 0),
-			maxEntries:        100, // 最多保留100条日志
-			currentLevel:      LogLevelInfo,
-			suppressDuplicates: true,
-		}
+		dbLogger = newDatabaseLogger("")
 	})
 	return dbLogger
 }
 
+// newDatabaseLogger 创建日志管理器，name 为所属服务器名称
+func newDatabaseLogger(name string) *DatabaseLogger {
+	cfg := config.GetLogConfig()
+	dl := &DatabaseLogger{
+		entries:           make([]LogEntry, 0),
+		maxEntries:        cfg.MaxEntries,
+		currentLevel:      LogLevelInfo,
+		suppressDuplicates: true,
+		name:              name,
+		componentLevels:   make(map[LogComponent]LogLevel),
+		retention:         newLogRetention(cfg),
+		dedup:             newLogDedup(cfg),
+		fatalExit:         cfg.FatalExit,
+	}
+	go dl.summarizeDuplicates(cfg.DedupSummaryInterval)
+	return dl
+}
+
 // SetLogLevel 设置日志级别
 func (dl *DatabaseLogger) SetLogLevel(level LogLevel) {
 	dl.mu.Lock()
@@ -89,27 +112,25 @@ func (dl *DatabaseLogger) SetSuppressDuplicates(suppress bool) {
 
 // addEntry 添加日志条目
 func (dl *DatabaseLogger) addEntry(level LogLevel, message, details string, connInfo ...*ConnectionInfo) {
-	if level < dl.currentLevel {
+	dl.addComponentEntry("", level, message, details, connInfo...)
+}
+
+// addComponentEntry 添加指定组件的日志条目，低于该组件日志级别的条目被忽略
+func (dl *DatabaseLogger) addComponentEntry(component LogComponent, level LogLevel, message, details string, connInfo ...*ConnectionInfo) {
+	if !dl.Enabled(component, level) {
 		return
 	}
 
 	dl.mu.Lock()
 	defer dl.mu.Unlock()
 
-	// 检查是否是重复的日志消息
-	if dl.suppressDuplicates && dl.lastEntry != nil &&
-	   dl.lastEntry.Message == message && dl.lastEntry.Level == level {
-		dl.lastEntry.Count++
-		dl.lastEntry.Timestamp = time.Now()
-		return
-	}
-
 	entry := LogEntry{
 		Level:     level,
 		Message:   message,
 		Timestamp: time.Now(),
 		Details:   details,
 		Count:     1,
+		Component: component,
 	}
 
 	// 添加连接信息（如果提供）
@@ -117,16 +138,22 @@ func (dl *DatabaseLogger) addEntry(level LogLevel, message, details string, conn
 		entry.ConnectionInfo = connInfo[0]
 	}
 
-	// 保持日志条目数量在限制内
-	if len(dl.entries) >= dl.maxEntries {
-		dl.entries = dl.entries[1:]
-	}
+	dl.addLocked(entry)
+}
+
+// appendLocked 分配 ID 并添加条目，返回添加后的条目，调用方需持有 dl.mu
+func (dl *DatabaseLogger) appendLocked(entry LogEntry) LogEntry {
+	dl.nextID++
+	entry.ID = dl.nextID
+
+	// 保持日志条目数量在限制内，先淘汰级别低的条目
+	dl.evictLocked(entry.Level)
 
 	dl.entries = append(dl.entries, entry)
-	dl.lastEntry = &entry
 
 	// 输出到标准日志
 	dl.outputToStdLog(entry)
+	return entry
 }
 
 // addEntryWithConnection 专门用于记录包含连接信息的日志
@@ -137,11 +164,21 @@ func (dl *DatabaseLogger) addEntryWithConnection(level LogLevel, message, detail
 // outputToStdLog 输出到标准日志
 func (dl *DatabaseLogger) outputToStdLog(entry LogEntry) {
 	levelStr := dl.getLevelString(entry.Level)
+	message := entry.Message
+	if entry.Source != "" {
+		message = "[" + entry.Source + "] " + message
+	}
+	if entry.Component != "" {
+		message = "[" + string(entry.Component) + "] " + message
+	}
+	if dl.name != "" {
+		message = "[" + dl.name + "] " + message
+	}
 	
 	if entry.Count > 1 {
-		log.Printf("[%s] %s (重复 %d 次)", levelStr, entry.Message, entry.Count)
+		log.Printf("[%s] %s (重复 %d 次)", levelStr, message, entry.Count)
 	} else {
-		log.Printf("[%s] %s", levelStr, entry.Message)
+		log.Printf("[%s] %s", levelStr, message)
 	}
 	
 	if entry.Details != "" && entry.Level >= LogLevelWarn {
@@ -203,14 +240,14 @@ func (dl *DatabaseLogger) Error(message string, details ...string) {
 	dl.addEntry(LogLevelError, message, detail)
 }
 
-// Fatal 记录致命错误日志
+// Fatal 记录致命错误日志并通知 main 优雅关闭进程，不会立即退出
 func (dl *DatabaseLogger) Fatal(message string, details ...string) {
 	detail := ""
 	if len(details) > 0 {
 		detail = details[0]
 	}
 	dl.addEntry(LogLevelFatal, message, detail)
-	os.Exit(1)
+	dl.escalate(message, detail)
 }
 
 // 带连接信息的日志记录方法
@@ -250,14 +287,14 @@ func (dl *DatabaseLogger) ErrorWithConnection(message string, connInfo *Connecti
 	dl.addEntryWithConnection(LogLevelError, message, detail, connInfo)
 }
 
-// FatalWithConnection 记录包含连接信息的致命错误日志
+// FatalWithConnection 记录包含连接信息的致命错误日志并通知 main 优雅关闭进程
 func (dl *DatabaseLogger) FatalWithConnection(message string, connInfo *ConnectionInfo, details ...string) {
 	detail := ""
 	if len(details) > 0 {
 		detail = details[0]
 	}
 	dl.addEntryWithConnection(LogLevelFatal, message, detail, connInfo)
-	os.Exit(1)
+	dl.escalate(message, detail)
 }
 
 // GetEntries 获取所有日志条目
@@ -290,12 +327,19 @@ func (dl *DatabaseLogger) GetRecentEntries(n int) []LogEntry {
 	return entries
 }
 
-// Clear 清空日志
-func (dl *DatabaseLogger) Clear() {
+// Clear 清空日志，固定的条目和不低于 LOG_CLEAR_KEEP_LEVEL 的条目保留，返回保留的条数
+func (dl *DatabaseLogger) Clear() int {
 	dl.mu.Lock()
 	defer dl.mu.Unlock()
-	dl.entries = make([]LogEntry, 0)
-	dl.lastEntry = nil
+	kept := make([]LogEntry, 0)
+	for _, entry := range dl.entries {
+		if entry.Pinned || (dl.retention.clearKeep != nil && entry.Level >= *dl.retention.clearKeep) {
+			kept = append(kept, entry)
+		}
+	}
+	dl.entries = kept
+	dl.dedup.recent = make(map[dedupKey]*dedupState)
+	return len(kept)
 }
 
 // GetSummary 获取日志摘要
@@ -339,7 +383,7 @@ func (dl *DatabaseLogger) GetSummary() map[string]interface{} {
 		summary["last_entry"] = map[string]interface{}{
 			"level":     dl.getLevelString(lastEntry.Level),
 			"message":   lastEntry.Message,
-			"timestamp": lastEntry.Timestamp.Format("2006-01-02 15:04:05"),
+			"timestamp": config.FormatAPITime(lastEntry.Timestamp),
 			"count":     lastEntry.Count,
 		}
 	}
@@ -349,17 +393,19 @@ func (dl *DatabaseLogger) GetSummary() map[string]interface{} {
 
 // ReconnectionLogger 重连专用日志记录器
 type ReconnectionLogger struct {
-	logger *DatabaseLogger
+	logger *ComponentLogger
 	startTime time.Time
 	lastProgressTime time.Time
 	progressInterval time.Duration
+	incidents bool // 是否记录到事件时间线，仅默认连接记录
 }
 
 // NewReconnectionLogger 创建重连日志记录器
 func NewReconnectionLogger() *ReconnectionLogger {
 	return &ReconnectionLogger{
-		logger: GetDatabaseLogger(),
+		logger: GetDatabaseLogger().Component(ComponentReconnector),
 		progressInterval: 30 * time.Second, // 每30秒报告一次进度
+		incidents: true,
 	}
 }
 
@@ -373,6 +419,13 @@ func (rl *ReconnectionLogger) StartReconnection() {
 // LogRetry 记录重试信息
 func (rl *ReconnectionLogger) LogRetry(retryCount int, nextDelay time.Duration, lastError error) {
 	now := time.Now()
+	event := fmt.Sprintf("第 %d 次重连尝试", retryCount)
+	if lastError != nil {
+		event += fmt.Sprintf(": %v", lastError)
+	}
+	if rl.incidents {
+		GetIncidentRecorder().recordEvent(EventReconnectAttempt, event, float64(retryCount))
+	}
 	
 	// 只在特定条件下输出详细信息
 	shouldLog := false
@@ -411,7 +464,7 @@ func (rl *ReconnectionLogger) LogRetry(retryCount int, nextDelay time.Duration,
 	
 	if shouldLog {
 		if len(details) > 0 {
-			rl.logger.Warn(message, details)
+			rl.logger.addEntry(connectionFailureLevel(LogLevelWarn), message, details)
 		} else {
 			rl.logger.Info(message)
 		}
@@ -424,6 +477,9 @@ func (rl *ReconnectionLogger) LogSuccess(totalRetries int) {
 	message := "✅ 数据库重连成功"
 	details := fmt.Sprintf("总计重试: %d 次, 耗时: %v", totalRetries, elapsed.Round(time.Second))
 	rl.logger.Info(message, details)
+	if rl.incidents {
+		GetIncidentRecorder().recordEvent(EventReconnected, message+": "+details, float64(totalRetries))
+	}
 }
 
 // LogFailure 记录重连失败
@@ -432,5 +488,8 @@ func (rl *ReconnectionLogger) LogFailure(totalRetries int, finalError error) {
 	message := "❌ 数据库重连最终失败"
 	details := fmt.Sprintf("总计重试: %d 次, 耗时: %v, 最终错误: %v", 
 		totalRetries, elapsed.Round(time.Second), finalError)
-	rl.logger.Error(message, details)
+	rl.logger.addEntry(connectionFailureLevel(LogLevelError), message, details)
+	if rl.incidents {
+		GetIncidentRecorder().recordEvent(EventReconnectFailed, message+": "+details, float64(totalRetries))
+	}
 }
\ No newline at end of file
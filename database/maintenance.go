@@ -0,0 +1,144 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// DefaultProfile 当前连接配置的名称
+const DefaultProfile = "default"
+
+// MaintenanceWindow 维护窗口，窗口内抑制告警并降低连接失败日志级别
+type MaintenanceWindow struct {
+	ID        int       `json:"id"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Profile   string    `json:"profile,omitempty"` // 为空时作用于所有连接配置
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ActiveAt 判断窗口在指定时间是否生效
+func (w MaintenanceWindow) ActiveAt(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// appliesTo 判断窗口是否作用于指定连接配置
+func (w MaintenanceWindow) appliesTo(profile string) bool {
+	return w.Profile == "" || strings.EqualFold(w.Profile, profile)
+}
+
+// MaintenanceManager 维护窗口管理器
+type MaintenanceManager struct {
+	mu      sync.RWMutex
+	windows []MaintenanceWindow
+	nextID  int
+}
+
+var (
+	maintenanceManager *MaintenanceManager
+	maintenanceOnce    sync.Once
+)
+
+// GetMaintenanceManager 获取维护窗口管理器实例
+func GetMaintenanceManager() *MaintenanceManager {
+	maintenanceOnce.Do(func() {
+		maintenanceManager = &MaintenanceManager{nextID: 1}
+	})
+	return maintenanceManager
+}
+
+// Add 添加维护窗口
+func (m *MaintenanceManager) Add(start, end time.Time, profile, reason string) (*MaintenanceWindow, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("end must be after start")
+	}
+	if !end.After(time.Now()) {
+		return nil, fmt.Errorf("window has already ended")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	window := MaintenanceWindow{
+		ID:        m.nextID,
+		Start:     start,
+		End:       end,
+		Profile:   strings.TrimSpace(profile),
+		Reason:    strings.TrimSpace(reason),
+		CreatedAt: time.Now(),
+	}
+	m.nextID++
+	m.windows = append(m.windows, window)
+
+	GetDatabaseLogger().Info(fmt.Sprintf("已添加维护窗口 #%d", window.ID),
+		fmt.Sprintf("%s ~ %s %s", config.FormatDisplayTime(start), config.FormatDisplayTime(end), window.Reason))
+	return &window, nil
+}
+
+// Remove 删除维护窗口，不存在时返回 false
+func (m *MaintenanceManager) Remove(id int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, w := range m.windows {
+		if w.ID == id {
+			m.windows = append(m.windows[:i], m.windows[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// List 获取未结束的维护窗口，按开始时间排序，已结束的窗口会被清理
+func (m *MaintenanceManager) List() []MaintenanceWindow {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	windows := m.windows[:0]
+	for _, w := range m.windows {
+		if w.End.After(now) {
+			windows = append(windows, w)
+		}
+	}
+	m.windows = windows
+
+	result := make([]MaintenanceWindow, len(windows))
+	copy(result, windows)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result
+}
+
+// Active 获取当前作用于指定连接配置的维护窗口，不在维护中时返回 nil
+func (m *MaintenanceManager) Active(profile string) *MaintenanceWindow {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	for _, w := range m.windows {
+		if w.ActiveAt(now) && w.appliesTo(profile) {
+			window := w
+			return &window
+		}
+	}
+	return nil
+}
+
+// InMaintenance 当前连接是否处于维护窗口内
+func InMaintenance() bool {
+	return GetMaintenanceManager().Active(DefaultProfile) != nil
+}
+
+// connectionFailureLevel 连接失败类日志的级别，维护窗口内降级为 Info
+func connectionFailureLevel(level LogLevel) LogLevel {
+	if level > LogLevelInfo && InMaintenance() {
+		return LogLevelInfo
+	}
+	return level
+}
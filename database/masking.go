@@ -0,0 +1,158 @@
+package database
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// 脱敏方式
+const (
+	MaskRedact  = "redact"  // 整体替换
+	MaskHash    = "hash"    // 替换为带密钥的哈希，相同的值得到相同的结果
+	MaskPartial = "partial" // 仅保留首尾少量字符
+)
+
+// redactedValue redact 方式的替换值
+const redactedValue = "[REDACTED]"
+
+// MaskingRule 单条脱敏规则，Database/Table/Column 为 * 时匹配任意名称
+type MaskingRule struct {
+	Database string `json:"database"`
+	Table    string `json:"table"`
+	Column   string `json:"column"`
+	Action   string `json:"action"`
+}
+
+// ParseMaskingRule 解析 db.table.column=action 形式的规则
+func ParseMaskingRule(s string) (MaskingRule, error) {
+	target, action, ok := strings.Cut(s, "=")
+	if !ok {
+		return MaskingRule{}, fmt.Errorf("missing action in masking rule %q", s)
+	}
+	parts := strings.Split(strings.TrimSpace(target), ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return MaskingRule{}, fmt.Errorf("masking rule %q must target db.table.column", s)
+	}
+	action = strings.ToLower(strings.TrimSpace(action))
+	switch action {
+	case MaskRedact, MaskHash, MaskPartial:
+	default:
+		return MaskingRule{}, fmt.Errorf("unknown masking action %q", action)
+	}
+	return MaskingRule{Database: parts[0], Table: parts[1], Column: parts[2], Action: action}, nil
+}
+
+// matches 判断规则是否作用于指定字段
+func (r MaskingRule) matches(databaseName, tableName, column string) bool {
+	return matchName(r.Database, databaseName) && matchName(r.Table, tableName) && matchName(r.Column, column)
+}
+
+// matchName 不区分大小写地匹配名称，* 匹配任意名称
+func matchName(pattern, name string) bool {
+	return pattern == "*" || strings.EqualFold(pattern, name)
+}
+
+// Masker 按规则对查询到的数据脱敏，所有返回行数据的地方都应经过它
+type Masker struct {
+	rules []MaskingRule
+	salt  []byte
+}
+
+var (
+	masker     *Masker
+	maskerOnce sync.Once
+)
+
+// GetMasker 获取按配置创建的脱敏器单例，无效规则会被记录并忽略
+func GetMasker() *Masker {
+	maskerOnce.Do(func() {
+		cfg := config.GetMaskingConfig()
+		masker = &Masker{salt: []byte(cfg.Salt)}
+		for _, item := range cfg.Rules {
+			rule, err := ParseMaskingRule(item)
+			if err != nil {
+				log.Printf("忽略无效的脱敏规则: %v", err)
+				continue
+			}
+			masker.rules = append(masker.rules, rule)
+		}
+	})
+	return masker
+}
+
+// Rules 返回生效的脱敏规则
+func (m *Masker) Rules() []MaskingRule {
+	return m.rules
+}
+
+// ActionFor 返回字段对应的脱敏方式，按配置顺序取第一条匹配的规则，未匹配时返回空字符串
+func (m *Masker) ActionFor(databaseName, tableName, column string) string {
+	for _, rule := range m.rules {
+		if rule.matches(databaseName, tableName, column) {
+			return rule.Action
+		}
+	}
+	return ""
+}
+
+// MaskRows 原地对行数据脱敏，返回被脱敏的字段名
+func (m *Masker) MaskRows(databaseName, tableName string, columns []string, rows [][]interface{}) []string {
+	actions := make([]string, len(columns))
+	var masked []string
+	for i, column := range columns {
+		if actions[i] = m.ActionFor(databaseName, tableName, column); actions[i] != "" {
+			masked = append(masked, column)
+		}
+	}
+	if len(masked) == 0 {
+		return nil
+	}
+
+	for _, row := range rows {
+		for i, action := range actions {
+			if action != "" && i < len(row) {
+				row[i] = m.MaskValue(action, row[i])
+			}
+		}
+	}
+	return masked
+}
+
+// MaskValue 按脱敏方式处理单个值，NULL 保持不变
+func (m *Masker) MaskValue(action string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	s := fmt.Sprint(value)
+	switch action {
+	case MaskRedact:
+		return redactedValue
+	case MaskHash:
+		mac := hmac.New(sha256.New, m.salt)
+		mac.Write([]byte(s))
+		return "sha256:" + hex.EncodeToString(mac.Sum(nil))[:16]
+	case MaskPartial:
+		return maskPartial(s)
+	}
+	return value
+}
+
+// maskPartial 保留首尾各约四分之一 (最多 3 个) 字符，其余替换为 *
+func maskPartial(s string) string {
+	runes := []rune(s)
+	keep := len(runes) / 4
+	if keep > 3 {
+		keep = 3
+	}
+	if keep == 0 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:keep]) + strings.Repeat("*", len(runes)-2*keep) + string(runes[len(runes)-keep:])
+}
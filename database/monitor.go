@@ -0,0 +1,56 @@
+package database
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+var (
+	monitorDB *sql.DB
+	monitorMu sync.RWMutex
+)
+
+// GetMonitorDB 获取健康检查专用的单连接句柄，未初始化时退回到共享连接池
+// 独立于浏览查询使用的连接池，耗时的元数据查询占满连接池时健康检查仍能立即拿到连接，
+// 避免因排队超时被误判为连接丢失
+func GetMonitorDB() *sql.DB {
+	monitorMu.RLock()
+	m := monitorDB
+	monitorMu.RUnlock()
+	if m == nil {
+		return GetDB()
+	}
+	return m
+}
+
+// replaceMonitorDB 为指定主机打开新的健康检查句柄并关闭旧句柄
+func replaceMonitorDB(cfg *config.DBConfig, host config.DBHost) error {
+	m, err := openDB(cfg, host)
+	if err != nil {
+		return err
+	}
+	m.SetMaxOpenConns(1)
+	m.SetMaxIdleConns(1)
+
+	monitorMu.Lock()
+	old := monitorDB
+	monitorDB = m
+	monitorMu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// closeMonitorDB 关闭健康检查句柄
+func closeMonitorDB() {
+	monitorMu.Lock()
+	old := monitorDB
+	monitorDB = nil
+	monitorMu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+}
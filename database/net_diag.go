@@ -0,0 +1,218 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	// netDiagTimeout 域名解析和单个地址连接的超时
+	netDiagTimeout = 3 * time.Second
+	// netDiagTTL 同一地址的诊断结果缓存时长，连接持续失败时避免每次检查都重新诊断
+	netDiagTTL = 30 * time.Second
+	// netDiagMaxDials 最多尝试连接的解析地址数
+	netDiagMaxDials = 8
+	// mysqlDefaultPort MySQL 默认端口，配置了其他端口时额外尝试，用于发现端口配置错误
+	mysqlDefaultPort = "3306"
+)
+
+// DialResult 对一个解析地址的 TCP 连接结果
+type DialResult struct {
+	Address    string  `json:"address"`
+	DurationMs float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// NetworkDiagnostics 连接失败时对目标地址做的域名解析和 TCP 连接诊断
+type NetworkDiagnostics struct {
+	Target       string       `json:"target"`
+	ResolvedIPs  []string     `json:"resolved_ips"`
+	ResolveMs    float64      `json:"resolve_ms"`
+	ResolveError string       `json:"resolve_error,omitempty"`
+	Dials        []DialResult `json:"dials"`
+	// DefaultPortDial 配置的端口不是 3306 时，对第一个解析地址的 3306 端口的连接结果
+	DefaultPortDial *DialResult `json:"default_port_dial,omitempty"`
+	CheckedAt       time.Time   `json:"checked_at"`
+}
+
+// Finding 根据诊断结果判断问题所在的层次，供错误原因使用
+func (d *NetworkDiagnostics) Finding() string {
+	if d.ResolveError != "" {
+		return "域名解析失败，检查主机名和 DNS 配置"
+	}
+	if len(d.Dials) == 0 {
+		return ""
+	}
+	var failed []string
+	for _, dial := range d.Dials {
+		if dial.Error != "" {
+			failed = append(failed, dial.Address)
+		}
+	}
+	switch {
+	case len(failed) == 0:
+		return "TCP 连接正常，MySQL 握手或认证阶段失败，检查 TLS 配置、max_connect_errors 和服务端日志"
+	case len(failed) < len(d.Dials):
+		return fmt.Sprintf("部分解析地址无法连接 (%s)，其余地址 TCP 连接正常，检查 DNS 记录是否包含已下线的服务器", strings.Join(failed, ", "))
+	case d.DefaultPortDial != nil && d.DefaultPortDial.Error == "":
+		return fmt.Sprintf("配置的端口无法连接，但 %s 可以连接，检查端口配置", d.DefaultPortDial.Address)
+	default:
+		return "所有解析地址的 TCP 连接均失败，检查数据库服务是否运行、端口和防火墙设置"
+	}
+}
+
+// Summary 诊断结果的单行摘要，用于日志
+func (d *NetworkDiagnostics) Summary() string {
+	var parts []string
+	if d.ResolveError != "" {
+		parts = append(parts, fmt.Sprintf("解析 %s 失败 (%s, %.1fms)", d.Target, d.ResolveError, d.ResolveMs))
+	} else {
+		parts = append(parts, fmt.Sprintf("解析 %s → %s (%.1fms)", d.Target, strings.Join(d.ResolvedIPs, ", "), d.ResolveMs))
+	}
+	dials := d.Dials
+	if d.DefaultPortDial != nil {
+		dials = append(dials[:len(dials):len(dials)], *d.DefaultPortDial)
+	}
+	for _, dial := range dials {
+		if dial.Error != "" {
+			parts = append(parts, fmt.Sprintf("%s 连接失败 (%s, %.1fms)", dial.Address, dial.Error, dial.DurationMs))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s 连接成功 (%.1fms)", dial.Address, dial.DurationMs))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+var (
+	netDiagCache   = make(map[string]*NetworkDiagnostics)
+	netDiagCacheMu sync.Mutex
+	netDiagFlight  flightGroup
+)
+
+// DiagnoseNetwork 解析地址中的主机名，并对每个解析出的 IP 分别建立 TCP 连接计时
+func DiagnoseNetwork(ctx context.Context, address string) *NetworkDiagnostics {
+	diag := &NetworkDiagnostics{Target: address, ResolvedIPs: []string{}, Dials: []DialResult{}, CheckedAt: time.Now()}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		diag.ResolveError = err.Error()
+		return diag
+	}
+
+	resolveCtx, cancel := context.WithTimeout(ctx, netDiagTimeout)
+	started := time.Now()
+	ips, err := net.DefaultResolver.LookupHost(resolveCtx, host)
+	cancel()
+	diag.ResolveMs = durationMs(time.Since(started))
+	if err != nil {
+		diag.ResolveError = err.Error()
+		return diag
+	}
+	diag.ResolvedIPs = ips
+	if len(ips) > netDiagMaxDials {
+		ips = ips[:netDiagMaxDials]
+	}
+
+	// 各地址并发连接，总耗时不超过单个地址的超时
+	diag.Dials = make([]DialResult, len(ips))
+	var wg sync.WaitGroup
+	for i, ip := range ips {
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			diag.Dials[i] = dialOnce(ctx, addr)
+		}(i, net.JoinHostPort(ip, port))
+	}
+	wg.Wait()
+
+	if port != mysqlDefaultPort {
+		dial := dialOnce(ctx, net.JoinHostPort(ips[0], mysqlDefaultPort))
+		diag.DefaultPortDial = &dial
+	}
+	return diag
+}
+
+// dialOnce 建立一次 TCP 连接并立即关闭
+func dialOnce(ctx context.Context, addr string) DialResult {
+	result := DialResult{Address: addr}
+	dialer := net.Dialer{Timeout: netDiagTimeout}
+	started := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	result.DurationMs = durationMs(time.Since(started))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	conn.Close()
+	return result
+}
+
+// durationMs 毫秒数，保留小数
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// isNetworkError 是否为需要网络诊断的错误：拒绝连接、路由不可达、域名解析失败、连接超时和握手中断
+// 连接数过多 (NET_003) 是服务端的应答，网络本身是通的，不做诊断
+func isNetworkError(err error, details *ErrorDetails) bool {
+	if details != nil {
+		switch {
+		case details.Code == "NET_001", details.Code == "NET_002", details.Type == ErrorTypeTimeout:
+			return true
+		}
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return errors.As(err, &opErr) || errors.As(err, &dnsErr) ||
+		errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// attachNetworkDiagnostics 网络错误时诊断目标地址并附加到错误详情，诊断结论补充到错误原因中
+// 同一地址的诊断结果缓存一段时间，只有新的诊断才写入日志
+func attachNetworkDiagnostics(details *ErrorDetails, err error, address string, logger *DatabaseLogger) {
+	if details == nil || address == "" || !isNetworkError(err, details) {
+		return
+	}
+
+	// 同时失败的多个检查共享一次诊断
+	v, _ := netDiagFlight.Do(context.Background(), address, func(context.Context) (interface{}, error) {
+		netDiagCacheMu.Lock()
+		diag, ok := netDiagCache[address]
+		netDiagCacheMu.Unlock()
+		if ok && time.Since(diag.CheckedAt) <= netDiagTTL {
+			return diag, nil
+		}
+		// 调用方的上下文通常已因连接超时而结束，诊断使用独立的超时
+		diag = DiagnoseNetwork(context.Background(), address)
+		netDiagCacheMu.Lock()
+		netDiagCache[address] = diag
+		netDiagCacheMu.Unlock()
+		logger.Component(ComponentReconnector).Warn("网络诊断 ("+address+")", diag.Summary())
+		return diag, nil
+	})
+	details.Network = v.(*NetworkDiagnostics)
+	if finding := details.Network.Finding(); finding != "" {
+		details.Cause = fmt.Sprintf("%s (诊断: %s)", details.Cause, finding)
+	}
+}
+
+// defaultDialAddress 默认连接的诊断地址：当前连接的主机，尚未连接时为首选主机
+func defaultDialAddress() string {
+	if host := ActiveHost(); host != "" {
+		return host
+	}
+	if hosts := config.GetDBConfig().Hosts; len(hosts) > 0 {
+		return hosts[0].Address()
+	}
+	return ""
+}
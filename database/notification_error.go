@@ -0,0 +1,91 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// notificationPatterns 通知发送失败的错误模式，未匹配时使用 notificationFallback
+var notificationPatterns = []ErrorPattern{
+	{
+		Keywords:   []string{"timeout", "deadline exceeded"},
+		Type:       ErrorTypeNotification,
+		Code:       "NTF_002",
+		Cause:      "通知渠道响应超时",
+		Suggestion: "检查通知地址的网络延迟和对方服务负载",
+		Severity:   3,
+	},
+	{
+		Keywords:   []string{"connection refused", "no such host", "network is unreachable", "dial tcp"},
+		Type:       ErrorTypeNotification,
+		Code:       "NTF_001",
+		Cause:      "通知渠道不可达",
+		Suggestion: "检查通知地址、DNS 解析、代理和出站防火墙设置",
+		Severity:   4,
+	},
+	{
+		Keywords:   []string{"401", "403", "unauthorized", "forbidden", "token", "sign"},
+		Type:       ErrorTypeNotification,
+		Code:       "NTF_003",
+		Cause:      "通知渠道拒绝了请求",
+		Suggestion: "检查机器人令牌、签名密钥或 webhook 地址是否已失效",
+		Severity:   4,
+	},
+}
+
+// notificationFallback 未识别的通知发送失败
+var notificationFallback = ErrorPattern{
+	Type:       ErrorTypeNotification,
+	Code:       "NTF_004",
+	Cause:      "通知渠道返回错误",
+	Suggestion: "查看错误示例中的响应内容，检查消息格式和渠道配置",
+	Severity:   3,
+}
+
+// AnalyzeNotificationError 分析告警通知或心跳的发送失败并计入 notification 类型的错误统计
+// consecutiveFailures 为该渠道连续失败的次数，连续失败较多时提高日志级别
+func (ea *ErrorAnalyzer) AnalyzeNotificationError(channel string, err error, consecutiveFailures int) *ErrorDetails {
+	if err == nil {
+		return nil
+	}
+
+	errorMsg := fmt.Sprintf("%s: %s", channel, err.Error())
+	now := time.Now()
+
+	pattern := notificationFallback
+	lower := strings.ToLower(err.Error())
+match:
+	for _, p := range notificationPatterns {
+		for _, keyword := range p.Keywords {
+			if strings.Contains(lower, keyword) {
+				pattern = p
+				break match
+			}
+		}
+	}
+
+	suggestion := pattern.Suggestion
+	severity := pattern.Severity
+	if consecutiveFailures > 1 {
+		suggestion += fmt.Sprintf(" | %s 已连续失败%d次，告警可能无法送达", channel, consecutiveFailures)
+		if consecutiveFailures >= 3 {
+			severity = 5
+		}
+	}
+
+	details := &ErrorDetails{
+		Type:       pattern.Type,
+		Code:       pattern.Code,
+		Message:    errorMsg,
+		Cause:      pattern.Cause,
+		Suggestion: suggestion,
+		Timestamp:  config.FormatAPITime(now),
+		RetryCount: consecutiveFailures,
+	}
+	ea.updateErrorSummary(details, errorMsg, now)
+	ea.logErrorAnalysis(details, severity)
+	return details
+}
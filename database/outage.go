@@ -0,0 +1,132 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// Outage 一次连接中断：从进入重连状态到重新连接 (或放弃、停止) 为止
+type Outage struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`     // 仍在中断时为当前时间
+	Ongoing  bool      `json:"ongoing"` // 是否仍未恢复
+	Duration float64   `json:"duration_seconds"`
+	Reason   string    `json:"reason,omitempty"` // 进入重连状态的原因
+	EndState ConnState `json:"end_state"`        // 中断结束时的状态，仍在中断时为 reconnecting
+	Retries  int       `json:"retries"`          // 中断期间失败的重连尝试数
+	// 中断期间出现次数最多的错误，没有失败的尝试时为空
+	DominantCode  string         `json:"dominant_error_code,omitempty"`
+	DominantType  ErrorType      `json:"dominant_error_type,omitempty"`
+	DominantCause string         `json:"dominant_cause,omitempty"`
+	ErrorCodes    map[string]int `json:"error_codes"`
+	Summary       string         `json:"summary"`
+}
+
+// TrendHour 一小时的错误数与连接中断的重叠情况
+type TrendHour struct {
+	Hour          string  `json:"hour"` // 与错误趋势 hourly_data 的键相同
+	Errors        int     `json:"errors"`
+	OutageSeconds float64 `json:"outage_seconds"` // 该小时内处于中断状态的秒数
+	Outages       []int   `json:"outages"`        // 与该小时重叠的中断在 outages 中的下标
+	Annotations   []int64 `json:"annotations"`    // 与该小时重叠的注释编号，见 OverlayAnnotations
+}
+
+// Outages 根据状态转换和重连尝试记录还原 since 之后仍在进行的连接中断，按开始时间升序
+// 状态转换只保留最近 100 条，更早的中断无法还原
+func (r *Reconnector) Outages(since time.Time) []Outage {
+	state, _, transitions := r.StateHistory()
+	attempts := r.AttemptHistory(time.Time{}, 0)
+	now := time.Now()
+
+	outages := []Outage{}
+	var current *Outage
+	for _, t := range transitions {
+		switch {
+		case t.To == StateReconnecting && current == nil:
+			current = &Outage{Start: t.At, Reason: t.Reason}
+		case t.To != StateReconnecting && current != nil:
+			current.End, current.EndState = t.At, t.To
+			if !current.End.Before(since) {
+				outages = append(outages, *current)
+			}
+			current = nil
+		}
+	}
+	if current != nil {
+		current.End, current.EndState, current.Ongoing = now, state, true
+		outages = append(outages, *current)
+	}
+
+	for i := range outages {
+		o := &outages[i]
+		o.Duration = o.End.Sub(o.Start).Round(time.Second).Seconds()
+		o.ErrorCodes = make(map[string]int)
+		causes := make(map[string]ReconnectAttempt)
+		for _, a := range attempts {
+			if a.Success || a.Time.Before(o.Start) || a.Time.After(o.End) {
+				continue
+			}
+			o.Retries++
+			o.ErrorCodes[a.ErrorCode]++
+			causes[a.ErrorCode] = a
+		}
+		for code, n := range o.ErrorCodes {
+			if n > o.ErrorCodes[o.DominantCode] || (n == o.ErrorCodes[o.DominantCode] && code < o.DominantCode) {
+				o.DominantCode = code
+			}
+		}
+		if a, ok := causes[o.DominantCode]; ok {
+			o.DominantType, o.DominantCause = a.ErrorType, a.Cause
+		}
+		o.Summary = o.describe()
+	}
+	return outages
+}
+
+// describe 中断的单行描述，例如 "2026-10-18 03:12:05 起中断 8m0s，重试 15 次，主要错误 NET_001 (数据库服务器拒绝连接)，已重新连接"
+func (o *Outage) describe() string {
+	s := fmt.Sprintf("%s 起中断 %v，重试 %d 次", config.FormatDisplayTime(o.Start), time.Duration(o.Duration)*time.Second, o.Retries)
+	if o.DominantCode != "" {
+		s += fmt.Sprintf("，主要错误 %s (%s)", o.DominantCode, o.DominantCause)
+	}
+	switch {
+	case o.Ongoing:
+		s += "，仍未恢复"
+	case o.EndState.Connected():
+		s += "，已重新连接"
+	case o.EndState == StateGaveUp:
+		s += "，已放弃重连"
+	default:
+		s += "，重连已停止"
+	}
+	return s
+}
+
+// CorrelateOutages 将连接中断叠加到 from 至 to 之间每小时的错误数上
+// hourly 为错误趋势的 hourly_data，键格式为 hourKeyFormat
+func CorrelateOutages(hourly map[string]int, outages []Outage, from, to time.Time) []TrendHour {
+	hours := []TrendHour{}
+	for start := from.Truncate(time.Hour); start.Before(to); start = start.Add(time.Hour) {
+		end := start.Add(time.Hour)
+		h := TrendHour{Hour: start.Format(hourKeyFormat), Errors: hourly[start.Format(hourKeyFormat)], Outages: []int{}, Annotations: []int64{}}
+		var down time.Duration
+		for i, o := range outages {
+			overlapStart, overlapEnd := o.Start, o.End
+			if overlapStart.Before(start) {
+				overlapStart = start
+			}
+			if overlapEnd.After(end) {
+				overlapEnd = end
+			}
+			if overlapEnd.After(overlapStart) {
+				down += overlapEnd.Sub(overlapStart)
+				h.Outages = append(h.Outages, i)
+			}
+		}
+		h.OutageSeconds = down.Round(time.Second).Seconds()
+		hours = append(hours, h)
+	}
+	return hours
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// PinyinTable 汉字到拼音 (不带声调) 的映射
+type PinyinTable struct {
+	readings map[rune]string
+}
+
+// toneReplacer 去掉拼音的声调符号，ü 记为 v
+var toneReplacer = strings.NewReplacer(
+	"ā", "a", "á", "a", "ǎ", "a", "à", "a",
+	"ē", "e", "é", "e", "ě", "e", "è", "e", "ê", "e",
+	"ī", "i", "í", "i", "ǐ", "i", "ì", "i",
+	"ō", "o", "ó", "o", "ǒ", "o", "ò", "o",
+	"ū", "u", "ú", "u", "ǔ", "u", "ù", "u",
+	"ǖ", "v", "ǘ", "v", "ǚ", "v", "ǜ", "v", "ü", "v",
+	"ń", "n", "ň", "n", "ǹ", "n", "ḿ", "m",
+)
+
+// LoadPinyinTable 读取 pinyin-data 格式的拼音文件
+// 每行形如 "U+4E2D: zhōng,zhòng  # 中"，多音字取第一个读音，# 开头的行为注释
+func LoadPinyinTable(path string) (*PinyinTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open pinyin file: %w", err)
+	}
+	defer f.Close()
+
+	t := &PinyinTable{readings: make(map[rune]string)}
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		code, readings, ok := strings.Cut(text, ":")
+		if !ok || !strings.HasPrefix(code, "U+") {
+			return nil, fmt.Errorf("pinyin file line %d: malformed entry", line)
+		}
+		r, err := strconv.ParseUint(strings.TrimPrefix(code, "U+"), 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("pinyin file line %d: %w", line, err)
+		}
+		first, _, _ := strings.Cut(strings.TrimSpace(readings), ",")
+		if first = toneReplacer.Replace(strings.TrimSpace(first)); first != "" {
+			t.readings[rune(r)] = first
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read pinyin file: %w", err)
+	}
+	return t, nil
+}
+
+// Transliterate 将文本转为拼音全拼和首字母，汉字以外的字母和数字原样保留，其余字符忽略
+func (t *PinyinTable) Transliterate(s string) (full, initials string) {
+	var fb, ib strings.Builder
+	for _, r := range s {
+		if reading, ok := t.readings[r]; ok {
+			fb.WriteString(reading)
+			ib.WriteByte(reading[0])
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			fb.WriteRune(unicode.ToLower(r))
+			ib.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return fb.String(), ib.String()
+}
+
+var (
+	pinyinTable     *PinyinTable
+	pinyinTableOnce sync.Once
+)
+
+// getPinyinTable 加载 DICTIONARY_PINYIN_FILE 指定的拼音表，未配置或加载失败时返回 nil
+func getPinyinTable() *PinyinTable {
+	pinyinTableOnce.Do(func() {
+		path := config.GetDictionaryConfig().PinyinFile
+		if path == "" {
+			return
+		}
+		t, err := LoadPinyinTable(path)
+		if err != nil {
+			GetDatabaseLogger().Warn("拼音表加载失败，数据字典不支持拼音搜索", err.Error())
+			return
+		}
+		pinyinTable = t
+	})
+	return pinyinTable
+}
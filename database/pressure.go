@@ -0,0 +1,198 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// PressureCounters 采样的全局状态计数器，反映磁盘临时表、无索引连接和外部排序的压力
+var PressureCounters = []string{"Created_tmp_disk_tables", "Select_full_join", "Sort_merge_passes"}
+
+// PressureSample 两次采样之间计数器的增量
+type PressureSample struct {
+	Timestamp time.Time        `json:"timestamp"`
+	Seconds   float64          `json:"seconds"` // 与上一次采样的间隔
+	Deltas    map[string]int64 `json:"deltas"`
+}
+
+// PerMinute 计数器每分钟的平均增量
+func (s PressureSample) PerMinute(name string) float64 {
+	if s.Seconds <= 0 {
+		return 0
+	}
+	return float64(s.Deltas[name]) * 60 / s.Seconds
+}
+
+// GetGlobalStatus 获取指定的全局状态计数器
+func GetGlobalStatus(ctx context.Context, names []string) (map[string]int64, error) {
+	return defaultStore.GetGlobalStatus(ctx, names)
+}
+
+// GetGlobalStatus 通过 SHOW GLOBAL STATUS 读取指定的数值型状态变量
+func (s *MySQLStore) GetGlobalStatus(ctx context.Context, names []string) (map[string]int64, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if len(names) == 0 {
+		return map[string]int64{}, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(names)), ",")
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+	rows, err := db.QueryContext(ctx, "SHOW GLOBAL STATUS WHERE Variable_name IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, fmt.Errorf("show global status: %w", err)
+	}
+	values, err := scanRowMaps(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan global status: %w", err)
+	}
+
+	status := make(map[string]int64, len(values))
+	for _, v := range values {
+		n, err := strconv.ParseInt(v["Value"], 10, 64)
+		if err != nil {
+			continue
+		}
+		status[v["Variable_name"]] = n
+	}
+	return status, nil
+}
+
+// PressureTracker 定期采样临时表与排序计数器并保存增量历史
+type PressureTracker struct {
+	mu         sync.RWMutex
+	samples    []PressureSample
+	maxSamples int
+	last       map[string]int64 // 上一次读取的累计值
+	lastAt     time.Time
+	store      Store
+	logger     *ComponentLogger
+	stop       chan struct{}
+}
+
+var (
+	pressureTracker *PressureTracker
+	pressureOnce    sync.Once
+)
+
+// GetPressureTracker 获取压力采样器实例
+func GetPressureTracker() *PressureTracker {
+	pressureOnce.Do(func() {
+		pressureTracker = &PressureTracker{
+			samples:    make([]PressureSample, 0),
+			maxSamples: config.GetPressureConfig().MaxSamples,
+			store:      defaultStore,
+			logger:     GetDatabaseLogger().Component(ComponentScheduler),
+		}
+	})
+	return pressureTracker
+}
+
+// Start 按指定间隔开始采样，interval 为 0 时不启动
+func (pt *PressureTracker) Start(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	pt.mu.Lock()
+	if pt.stop != nil {
+		pt.mu.Unlock()
+		return
+	}
+	pt.stop = make(chan struct{})
+	stop := pt.stop
+	pt.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		pt.Sample(context.Background())
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				pt.Sample(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop 停止采样
+func (pt *PressureTracker) Stop() {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if pt.stop != nil {
+		close(pt.stop)
+		pt.stop = nil
+	}
+}
+
+// Sample 立即执行一次采样，首次采样或服务器重启导致计数器回退时只记录基准值
+func (pt *PressureTracker) Sample(ctx context.Context) {
+	status, err := pt.store.GetGlobalStatus(ctx, PressureCounters)
+	if err != nil {
+		pt.logger.Debug("临时表与排序压力采样失败", err.Error())
+		return
+	}
+	now := time.Now()
+
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	last, lastAt := pt.last, pt.lastAt
+	pt.last, pt.lastAt = status, now
+	if last == nil {
+		return
+	}
+
+	sample := PressureSample{Timestamp: now, Seconds: now.Sub(lastAt).Seconds(), Deltas: make(map[string]int64, len(status))}
+	for name, value := range status {
+		prev, ok := last[name]
+		if !ok {
+			continue
+		}
+		if value < prev {
+			return
+		}
+		sample.Deltas[name] = value - prev
+	}
+	if pt.maxSamples > 0 && len(pt.samples) >= pt.maxSamples {
+		pt.samples = pt.samples[1:]
+	}
+	pt.samples = append(pt.samples, sample)
+}
+
+// GetSamples 获取增量历史副本
+func (pt *PressureTracker) GetSamples() []PressureSample {
+	pt.mu.RLock()
+	defer pt.mu.RUnlock()
+	samples := make([]PressureSample, len(pt.samples))
+	copy(samples, pt.samples)
+	return samples
+}
+
+// Totals 获取最近一次读取的累计值，尚未采样时返回 nil
+func (pt *PressureTracker) Totals() map[string]int64 {
+	pt.mu.RLock()
+	defer pt.mu.RUnlock()
+	if pt.last == nil {
+		return nil
+	}
+	totals := make(map[string]int64, len(pt.last))
+	for name, value := range pt.last {
+		totals[name] = value
+	}
+	return totals
+}
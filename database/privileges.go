@@ -0,0 +1,230 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// UserAccount MySQL 账号及其权限信息
+type UserAccount struct {
+	User                string              `json:"user"`
+	Host                string              `json:"host"`
+	PasswordExpired     bool                `json:"password_expired"`
+	AccountLocked       bool                `json:"account_locked"`
+	PasswordLastChanged *string             `json:"password_last_changed,omitempty"`
+	PasswordLifetime    *int64              `json:"password_lifetime,omitempty"`
+	GlobalPrivileges    []string            `json:"global_privileges"`
+	SchemaPrivileges    map[string][]string `json:"schema_privileges"`
+}
+
+// GetUsers 获取账号及权限列表
+func GetUsers(ctx context.Context) ([]UserAccount, error) {
+	return defaultStore.GetUsers(ctx)
+}
+
+// GetUsers 获取账号及权限列表
+func (s *MySQLStore) GetUsers(ctx context.Context) ([]UserAccount, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	accounts := make(map[string]*UserAccount)
+	account := func(grantee string) *UserAccount {
+		if a, ok := accounts[grantee]; ok {
+			return a
+		}
+		user, host := parseGrantee(grantee)
+		a := &UserAccount{
+			User:             user,
+			Host:             host,
+			GlobalPrivileges: []string{},
+			SchemaPrivileges: make(map[string][]string),
+		}
+		accounts[grantee] = a
+		return a
+	}
+
+	// 全局权限
+	globalRows, err := db.QueryContext(ctx, `
+		SELECT GRANTEE, PRIVILEGE_TYPE
+		FROM information_schema.USER_PRIVILEGES
+		ORDER BY GRANTEE, PRIVILEGE_TYPE`)
+	if err != nil {
+		return nil, fmt.Errorf("query user privileges: %w", err)
+	}
+	defer globalRows.Close()
+	for globalRows.Next() {
+		var grantee, privilege string
+		if err := globalRows.Scan(&grantee, &privilege); err != nil {
+			continue
+		}
+		a := account(grantee)
+		if privilege != "USAGE" {
+			a.GlobalPrivileges = append(a.GlobalPrivileges, privilege)
+		}
+	}
+
+	// 库级权限
+	schemaRows, err := db.QueryContext(ctx, `
+		SELECT GRANTEE, TABLE_SCHEMA, PRIVILEGE_TYPE
+		FROM information_schema.SCHEMA_PRIVILEGES
+		ORDER BY GRANTEE, TABLE_SCHEMA, PRIVILEGE_TYPE`)
+	if err != nil {
+		return nil, fmt.Errorf("query schema privileges: %w", err)
+	}
+	defer schemaRows.Close()
+	for schemaRows.Next() {
+		var grantee, schema, privilege string
+		if err := schemaRows.Scan(&grantee, &schema, &privilege); err != nil {
+			continue
+		}
+		a := account(grantee)
+		a.SchemaPrivileges[schema] = append(a.SchemaPrivileges[schema], privilege)
+	}
+
+	// 密码过期与锁定状态需要 mysql.user 的读权限，读取失败时仅返回权限信息
+	statusRows, err := db.QueryContext(ctx, `
+		SELECT User, Host, password_expired, account_locked, password_last_changed, password_lifetime
+		FROM mysql.user`)
+	if err != nil {
+		log.Printf("Failed to query account status: %v", err)
+	} else {
+		defer statusRows.Close()
+		for statusRows.Next() {
+			var user, host, expired, locked string
+			var lastChanged sql.NullString
+			var lifetime sql.NullInt64
+			if err := statusRows.Scan(&user, &host, &expired, &locked, &lastChanged, &lifetime); err != nil {
+				continue
+			}
+			a := account(fmt.Sprintf("'%s'@'%s'", user, host))
+			a.PasswordExpired = expired == "Y"
+			a.AccountLocked = locked == "Y"
+			if lastChanged.Valid {
+				a.PasswordLastChanged = &lastChanged.String
+			}
+			if lifetime.Valid {
+				a.PasswordLifetime = &lifetime.Int64
+			}
+		}
+	}
+
+	users := make([]UserAccount, 0, len(accounts))
+	for _, a := range accounts {
+		users = append(users, *a)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].User != users[j].User {
+			return users[i].User < users[j].User
+		}
+		return users[i].Host < users[j].Host
+	})
+	return users, nil
+}
+
+// parseGrantee 将 'user'@'host' 形式的 GRANTEE 拆分为用户名和主机
+func parseGrantee(grantee string) (string, string) {
+	parts := strings.SplitN(grantee, "@", 2)
+	user := strings.Trim(parts[0], "'")
+	host := ""
+	if len(parts) == 2 {
+		host = strings.Trim(parts[1], "'")
+	}
+	return user, host
+}
+
+// ObjectGrant 账号在某一级别上对数据库对象拥有的权限
+type ObjectGrant struct {
+	User       string   `json:"user"`
+	Host       string   `json:"host"`
+	Level      string   `json:"level"`  // global / schema / table / column
+	Object     string   `json:"object"` // 授权对象，如 *.*、db.*、db.table
+	Privileges []string `json:"privileges"`
+}
+
+// GetGrants 获取可访问指定数据库 (及可选的表) 的账号和权限
+func GetGrants(ctx context.Context, databaseName, tableName string) ([]ObjectGrant, error) {
+	return defaultStore.GetGrants(ctx, databaseName, tableName)
+}
+
+// GetGrants 获取可访问指定数据库 (及可选的表) 的账号和权限
+// tableName 为空时返回库级及以上的权限，以及该库下所有表级权限
+func (s *MySQLStore) GetGrants(ctx context.Context, databaseName, tableName string) ([]ObjectGrant, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	tableFilter := ""
+	args := []interface{}{databaseName}
+	if tableName != "" {
+		tableFilter = " AND TABLE_NAME = ?"
+		args = append(args, tableName)
+	}
+
+	// 各级权限统一为 (GRANTEE, 级别, 对象, 权限) 的形式
+	query := `
+		SELECT GRANTEE, 'global', '*.*', PRIVILEGE_TYPE
+		FROM information_schema.USER_PRIVILEGES
+		WHERE PRIVILEGE_TYPE <> 'USAGE'
+		UNION ALL
+		SELECT GRANTEE, 'schema', CONCAT(TABLE_SCHEMA, '.*'), PRIVILEGE_TYPE
+		FROM information_schema.SCHEMA_PRIVILEGES
+		WHERE ? LIKE TABLE_SCHEMA
+		UNION ALL
+		SELECT GRANTEE, 'table', CONCAT(TABLE_SCHEMA, '.', TABLE_NAME), PRIVILEGE_TYPE
+		FROM information_schema.TABLE_PRIVILEGES
+		WHERE TABLE_SCHEMA = ?` + tableFilter + `
+		UNION ALL
+		SELECT GRANTEE, 'column', CONCAT(TABLE_SCHEMA, '.', TABLE_NAME, '.', COLUMN_NAME), PRIVILEGE_TYPE
+		FROM information_schema.COLUMN_PRIVILEGES
+		WHERE TABLE_SCHEMA = ?` + tableFilter
+	queryArgs := append([]interface{}{databaseName}, args...)
+	queryArgs = append(queryArgs, args...)
+
+	rows, err := db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("query grants: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []ObjectGrant
+	index := make(map[string]int)
+	for rows.Next() {
+		var grantee, level, object, privilege string
+		if err := rows.Scan(&grantee, &level, &object, &privilege); err != nil {
+			continue
+		}
+		key := grantee + "\x00" + level + "\x00" + object
+		if i, ok := index[key]; ok {
+			grants[i].Privileges = append(grants[i].Privileges, privilege)
+			continue
+		}
+		user, host := parseGrantee(grantee)
+		index[key] = len(grants)
+		grants = append(grants, ObjectGrant{
+			User:       user,
+			Host:       host,
+			Level:      level,
+			Object:     object,
+			Privileges: []string{privilege},
+		})
+	}
+
+	sort.SliceStable(grants, func(i, j int) bool {
+		if grants[i].User != grants[j].User {
+			return grants[i].User < grants[j].User
+		}
+		return grants[i].Host < grants[j].Host
+	})
+	return grants, nil
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Process 服务器上的一个会话
+type Process struct {
+	ID      int64  `json:"id"`
+	User    string `json:"user"`
+	Host    string `json:"host"`
+	DB      string `json:"db,omitempty"`
+	Command string `json:"command"`
+	Time    int64  `json:"time"` // 当前状态已持续的秒数
+	State   string `json:"state,omitempty"`
+	Info    string `json:"info,omitempty"`
+	// Isolation 会话的事务隔离级别，performance_schema 不可用时为空
+	Isolation string `json:"isolation,omitempty"`
+}
+
+// ProcessChange 两次采样之间命令、状态或语句发生变化的会话
+type ProcessChange struct {
+	Before Process `json:"before"`
+	After  Process `json:"after"`
+}
+
+// ProcessListDiff 两次进程列表采样之间的差异
+type ProcessListDiff struct {
+	Started []Process       `json:"started"`
+	Ended   []Process       `json:"ended"`
+	Changed []ProcessChange `json:"changed"`
+}
+
+// Empty 判断是否没有任何变化
+func (d *ProcessListDiff) Empty() bool {
+	return len(d.Started) == 0 && len(d.Ended) == 0 && len(d.Changed) == 0
+}
+
+// GetProcessList 获取当前的进程列表
+func GetProcessList(ctx context.Context) ([]Process, error) {
+	return defaultStore.GetProcessList(ctx)
+}
+
+// GetProcessList 从 information_schema.PROCESSLIST 读取会话，不包含本工具执行查询的连接，按持续时间降序
+func (s *MySQLStore) GetProcessList(ctx context.Context) ([]Process, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT ID, USER, HOST, DB, COMMAND, TIME, STATE, INFO
+		FROM information_schema.PROCESSLIST
+		WHERE ID <> CONNECTION_ID()
+		ORDER BY TIME DESC, ID`)
+	if err != nil {
+		return nil, fmt.Errorf("query processlist: %w", err)
+	}
+	defer rows.Close()
+
+	processes := []Process{}
+	for rows.Next() {
+		var p Process
+		var dbName, state, info sql.NullString
+		if err := rows.Scan(&p.ID, &p.User, &p.Host, &dbName, &p.Command, &p.Time, &state, &info); err != nil {
+			return nil, fmt.Errorf("scan process: %w", err)
+		}
+		p.DB, p.State, p.Info = dbName.String, state.String, info.String
+		processes = append(processes, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query processlist: %w", err)
+	}
+	rows.Close()
+
+	isolation := sessionIsolationLevels(ctx, db)
+	for i := range processes {
+		processes[i].Isolation = isolation[processes[i].ID]
+	}
+	return processes, nil
+}
+
+// sessionIsolationLevels 从 performance_schema.variables_by_thread 读取各会话的事务隔离级别，按会话 ID 索引
+// 5.7.20 之前的变量名为 tx_isolation；performance_schema 未开启时返回空
+func sessionIsolationLevels(ctx context.Context, db *sql.DB) map[int64]string {
+	levels := make(map[int64]string)
+	rows, err := db.QueryContext(ctx, `
+		SELECT t.PROCESSLIST_ID, v.VARIABLE_NAME, v.VARIABLE_VALUE
+		FROM performance_schema.variables_by_thread v
+		JOIN performance_schema.threads t ON t.THREAD_ID = v.THREAD_ID
+		WHERE v.VARIABLE_NAME IN ('transaction_isolation', 'tx_isolation') AND t.PROCESSLIST_ID IS NOT NULL`)
+	if err != nil {
+		return levels
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var name, value string
+		if err := rows.Scan(&id, &name, &value); err != nil {
+			return levels
+		}
+		// 两个变量同时存在时 (5.7.20 ~ 5.7 末) 取值相同，优先使用新变量名
+		if _, ok := levels[id]; !ok || name == "transaction_isolation" {
+			levels[id] = value
+		}
+	}
+	return levels
+}
+
+// DiffProcessList 按会话 ID 比较两次采样
+// 命令、状态、语句或隔离级别变化，或持续时间回退 (同一语句再次执行) 时记为变化，单纯的时间增长不算
+func DiffProcessList(prev, cur []Process) ProcessListDiff {
+	diff := ProcessListDiff{Started: []Process{}, Ended: []Process{}, Changed: []ProcessChange{}}
+	before := make(map[int64]Process, len(prev))
+	for _, p := range prev {
+		before[p.ID] = p
+	}
+	for _, p := range cur {
+		old, ok := before[p.ID]
+		if !ok {
+			diff.Started = append(diff.Started, p)
+			continue
+		}
+		delete(before, p.ID)
+		if old.Command != p.Command || old.State != p.State || old.Info != p.Info || old.Isolation != p.Isolation || p.Time < old.Time {
+			diff.Changed = append(diff.Changed, ProcessChange{Before: old, After: p})
+		}
+	}
+	for _, p := range prev {
+		if _, ok := before[p.ID]; ok {
+			diff.Ended = append(diff.Ended, p)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,509 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// 服务器状态
+const (
+	ProfileStatusOK           = "ok"
+	ProfileStatusReconnecting = "reconnecting"
+	ProfileStatusDown         = "down"
+	ProfileStatusUnknown      = "unknown"
+)
+
+// profileCheckTimeout 单个服务器一次检查的超时
+const profileCheckTimeout = 5 * time.Second
+
+// ProfileStatus 一个被监控服务器的最近一次检查结果
+type ProfileStatus struct {
+	Name            string              `json:"name"`
+	Default         bool                `json:"default"` // 是否为本实例直接管理的连接
+	Host            string              `json:"host,omitempty"`
+	URL             string              `json:"url,omitempty"`
+	Labels          map[string]string   `json:"labels"`
+	Status          string              `json:"status"`
+	LatencyMs       float64             `json:"latency_ms"`
+	BlockedSessions *int                `json:"blocked_sessions"` // 无法统计时为 nil
+	LastError       string              `json:"last_error,omitempty"`
+	LastErrorAt     time.Time           `json:"last_error_at,omitempty"`
+	Network         *NetworkDiagnostics `json:"network,omitempty"` // 最近一次检查因网络错误失败时的诊断
+	Maintenance     bool                `json:"maintenance"`
+	CheckedAt       time.Time           `json:"checked_at"`
+}
+
+// profile 一个额外监控的服务器，使用只做检查的单连接
+// 每个服务器有独立的日志、错误分析和重连器，一个服务器反复断连不会影响其他服务器的日志和统计
+type profile struct {
+	cfg         config.ProfileConfig
+	mysqlConfig *mysql.Config
+	host        string
+	status      ProfileStatus // 由 ProfileRegistry.mu 保护
+
+	mu          sync.RWMutex
+	db          *sql.DB // 连接配置无效时为 nil
+	logger      *DatabaseLogger
+	analyzer    *ErrorAnalyzer
+	reconnector *Reconnector // 连接配置无效时为 nil
+}
+
+// ProfileRegistry 定期检查默认连接和 PROFILES 中配置的服务器，供仪表盘展示
+type ProfileRegistry struct {
+	mu            sync.RWMutex
+	defaults      ProfileStatus
+	defaultLabels map[string]string
+	profiles      []*profile
+	store         Store
+	logger        *ComponentLogger
+	stop          chan struct{}
+}
+
+var (
+	profileRegistry     *ProfileRegistry
+	profileRegistryOnce sync.Once
+)
+
+// GetProfileRegistry 获取服务器总览实例
+func GetProfileRegistry() *ProfileRegistry {
+	profileRegistryOnce.Do(func() {
+		profileRegistry = &ProfileRegistry{
+			store:  defaultStore,
+			logger: GetDatabaseLogger().Component(ComponentScheduler),
+		}
+		profilesConfig := config.GetProfilesConfig()
+		profileRegistry.defaultLabels = profilesConfig.DefaultLabels
+		profileRegistry.defaults = ProfileStatus{Name: DefaultProfile, Default: true, Labels: profilesConfig.DefaultLabels, Status: ProfileStatusUnknown}
+		for _, cfg := range profilesConfig.Profiles {
+			p, err := newProfile(cfg)
+			if err != nil {
+				profileRegistry.logger.Warn(fmt.Sprintf("连接配置 %s 无效", cfg.Name), err.Error())
+				p.logger.Error("连接配置无效", err.Error())
+				p.status = ProfileStatus{Name: cfg.Name, URL: cfg.URL, Labels: cfg.Labels, Status: ProfileStatusDown, LastError: err.Error(), LastErrorAt: time.Now()}
+			}
+			profileRegistry.profiles = append(profileRegistry.profiles, p)
+		}
+	})
+	return profileRegistry
+}
+
+// ValidateProfile 检查连接配置的名称和连接串
+func ValidateProfile(cfg config.ProfileConfig) error {
+	_, err := parseProfileDSN(cfg)
+	return err
+}
+
+// parseProfileDSN 解析连接配置的连接串
+func parseProfileDSN(cfg config.ProfileConfig) (*mysql.Config, error) {
+	if cfg.Name == DefaultProfile {
+		return nil, fmt.Errorf("profile name %q is reserved", DefaultProfile)
+	}
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("profile %s has no DSN", cfg.Name)
+	}
+	mysqlConfig, err := mysql.ParseDSN(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: parse DSN: %w", cfg.Name, err)
+	}
+	return mysqlConfig, nil
+}
+
+// newProfile 根据连接串打开检查用的连接，连接配置无效时返回的服务器只有日志和错误分析
+func newProfile(cfg config.ProfileConfig) (*profile, error) {
+	logger := newDatabaseLogger(cfg.Name)
+	p := &profile{
+		cfg:      cfg,
+		logger:   logger,
+		analyzer: newErrorAnalyzer(logger),
+	}
+	mysqlConfig, err := parseProfileDSN(cfg)
+	if err != nil {
+		return p, err
+	}
+	mysqlConfig.Timeout = profileCheckTimeout
+	db, err := openProfileDB(mysqlConfig)
+	if err != nil {
+		return p, err
+	}
+	p.mysqlConfig = mysqlConfig
+	p.db = db
+	p.host = mysqlConfig.Addr
+	p.status = ProfileStatus{Name: cfg.Name, Host: p.host, URL: cfg.URL, Labels: cfg.Labels, Status: ProfileStatusUnknown}
+	p.reconnector = newProfileReconnector(cfg.Name, logger, p.reconnect)
+	return p, nil
+}
+
+// openProfileDB 打开服务器检查用的单连接连接池
+func openProfileDB(mysqlConfig *mysql.Config) (*sql.DB, error) {
+	connector, err := mysql.NewConnector(mysqlConfig)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(readOnlyConnector{connector})
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(time.Hour)
+	return db, nil
+}
+
+// reconnect 重连器的连接函数：打开新的连接池，连通后替换旧的连接池
+func (p *profile) reconnect() error {
+	db, err := openProfileDB(p.mysqlConfig)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), profileCheckTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		p.analyzer.AnalyzeError(err, p.reconnector.GetRetryCount())
+		return err
+	}
+
+	p.mu.Lock()
+	old := p.db
+	p.db = db
+	p.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// getDB 获取服务器当前的连接池
+func (p *profile) getDB() *sql.DB {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.db
+}
+
+// Start 按指定间隔开始检查，interval 为 0 时不启动
+func (pr *ProfileRegistry) Start(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	pr.mu.Lock()
+	if pr.stop != nil {
+		pr.mu.Unlock()
+		return
+	}
+	pr.stop = make(chan struct{})
+	stop := pr.stop
+	pr.mu.Unlock()
+
+	compactInterval := config.GetErrorAnalysisConfig().CompactInterval
+	for _, p := range pr.profiles {
+		p.analyzer.StartCompaction(compactInterval)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		pr.Check(context.Background())
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				pr.Check(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop 停止检查和各服务器的重连，关闭各服务器的连接
+func (pr *ProfileRegistry) Stop() {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	if pr.stop != nil {
+		close(pr.stop)
+		pr.stop = nil
+	}
+	for _, p := range pr.profiles {
+		p.analyzer.StopCompaction()
+		if p.reconnector != nil {
+			p.reconnector.StopReconnection()
+		}
+		if db := p.getDB(); db != nil {
+			db.Close()
+		}
+	}
+}
+
+// Check 并发检查所有服务器
+func (pr *ProfileRegistry) Check(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		status := pr.checkDefault(ctx)
+		pr.mu.Lock()
+		pr.defaults = mergeLastError(pr.defaults, status)
+		pr.mu.Unlock()
+	}()
+	for _, p := range pr.profiles {
+		if p.reconnector == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(p *profile) {
+			defer wg.Done()
+			status := p.check(ctx)
+			pr.mu.Lock()
+			p.status = mergeLastError(p.status, status)
+			pr.mu.Unlock()
+		}(p)
+	}
+	wg.Wait()
+}
+
+// checkDefault 检查本实例直接管理的连接，状态检查走健康检查专用连接
+func (pr *ProfileRegistry) checkDefault(ctx context.Context) ProfileStatus {
+	ctx, cancel := context.WithTimeout(ctx, profileCheckTimeout)
+	defer cancel()
+	status := ProfileStatus{Name: DefaultProfile, Default: true, Host: ActiveHost(), Labels: pr.defaultLabels, Status: ProfileStatusOK}
+
+	started := time.Now()
+	result := pr.store.CheckStatus(ctx)
+	status.LatencyMs = float64(time.Since(started).Microseconds()) / 1000
+	switch {
+	case result.Status == "OK":
+		if blocked, err := pr.store.CountBlockedSessions(ctx); err == nil {
+			status.BlockedSessions = &blocked
+		}
+	case GetReconnector().IsReconnecting():
+		status.Status = ProfileStatusReconnecting
+		status.LastError = result.Error
+	default:
+		status.Status = ProfileStatusDown
+		status.LastError = result.Error
+	}
+	status.Maintenance = GetMaintenanceManager().Active(DefaultProfile) != nil
+	status.CheckedAt = time.Now()
+	return status
+}
+
+// check 检查额外配置的服务器：连通性、延迟和锁等待会话数
+// 连接失败时交给该服务器自己的重连器处理，重连期间不再检查
+func (p *profile) check(ctx context.Context) ProfileStatus {
+	ctx, cancel := context.WithTimeout(ctx, profileCheckTimeout)
+	defer cancel()
+	status := ProfileStatus{Name: p.cfg.Name, Host: p.host, URL: p.cfg.URL, Labels: p.cfg.Labels, Status: ProfileStatusOK}
+	status.Maintenance = GetMaintenanceManager().Active(p.cfg.Name) != nil
+
+	switch p.reconnector.State() {
+	case StateReconnecting:
+		status.Status = ProfileStatusReconnecting
+		if err := p.reconnector.GetLastError(); err != nil {
+			status.LastError = err.Error()
+		}
+		status.CheckedAt = time.Now()
+		return status
+	case StateGaveUp:
+		status.Status = ProfileStatusDown
+		if err := p.reconnector.GetLastError(); err != nil {
+			status.LastError = err.Error()
+		}
+		status.CheckedAt = time.Now()
+		return status
+	}
+
+	db := p.getDB()
+	started := time.Now()
+	err := db.PingContext(ctx)
+	status.LatencyMs = float64(time.Since(started).Microseconds()) / 1000
+	if err != nil {
+		status.Status = ProfileStatusDown
+		status.LastError = err.Error()
+		details := p.analyzer.AnalyzeError(err, 0)
+		attachNetworkDiagnostics(details, err, p.host, p.logger)
+		status.Network = details.Network
+		p.reconnector.OnConnectionLost()
+	} else {
+		p.reconnector.markConnected(config.DBHost{}, "连接检查成功")
+		if blocked, err := countBlockedSessions(ctx, db); err == nil {
+			status.BlockedSessions = &blocked
+		} else {
+			status.LastError = err.Error()
+			p.logger.Warn("统计锁等待会话失败", err.Error())
+		}
+	}
+	status.CheckedAt = time.Now()
+	return status
+}
+
+// mergeLastError 本次检查没有错误时沿用上一次的错误，仪表盘上保留最近的错误信息
+func mergeLastError(prev, next ProfileStatus) ProfileStatus {
+	if next.LastError != "" {
+		next.LastErrorAt = next.CheckedAt
+		return next
+	}
+	next.LastError, next.LastErrorAt = prev.LastError, prev.LastErrorAt
+	return next
+}
+
+// Statuses 获取所有服务器的最近状态，默认连接在前，其余按名称排序
+func (pr *ProfileRegistry) Statuses() []ProfileStatus {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+	statuses := []ProfileStatus{pr.defaults}
+	others := make([]ProfileStatus, 0, len(pr.profiles))
+	for _, p := range pr.profiles {
+		others = append(others, p.status)
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i].Name < others[j].Name })
+	return append(statuses, others...)
+}
+
+// Status 获取指定服务器的最近状态
+func (pr *ProfileRegistry) Status(name string) (ProfileStatus, bool) {
+	for _, s := range pr.Statuses() {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return ProfileStatus{}, false
+}
+
+// Labels 获取服务器的标签，附加 profile 标签；服务器不存在时返回 nil
+func (pr *ProfileRegistry) Labels(name string) map[string]string {
+	status, ok := pr.Status(name)
+	if !ok {
+		return nil
+	}
+	labels := map[string]string{"profile": name}
+	for k, v := range status.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// ParseLabelSelector 解析 key=value 形式的标签选择条件，所有条件都满足时才匹配
+func ParseLabelSelector(items []string) (map[string]string, error) {
+	selector := make(map[string]string, len(items))
+	for _, item := range items {
+		k, v, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid label selector %q, expected key=value", item)
+		}
+		selector[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return selector, nil
+}
+
+// MatchLabels 判断标签是否满足全部选择条件，条件为空时总是匹配
+func MatchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterProfiles 筛选标签满足条件的服务器，profile 视为每个服务器都有的标签
+func FilterProfiles(statuses []ProfileStatus, selector map[string]string) []ProfileStatus {
+	filtered := make([]ProfileStatus, 0, len(statuses))
+	for _, s := range statuses {
+		labels := map[string]string{"profile": s.Name}
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+		if MatchLabels(labels, selector) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// ProfileGroup 按标签值分组的服务器
+type ProfileGroup struct {
+	Value    string          `json:"value"` // 标签值，没有该标签的服务器分组值为空
+	Profiles []ProfileStatus `json:"profiles"`
+}
+
+// GroupProfiles 按标签值分组，分组按标签值排序，没有该标签的分组排在最后
+func GroupProfiles(statuses []ProfileStatus, key string) []ProfileGroup {
+	index := make(map[string]int)
+	var groups []ProfileGroup
+	for _, s := range statuses {
+		value := s.Labels[key]
+		i, ok := index[value]
+		if !ok {
+			i = len(groups)
+			index[value] = i
+			groups = append(groups, ProfileGroup{Value: value})
+		}
+		groups[i].Profiles = append(groups[i].Profiles, s)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Value == "" || groups[j].Value == "" {
+			return groups[j].Value == "" && groups[i].Value != ""
+		}
+		return groups[i].Value < groups[j].Value
+	})
+	return groups
+}
+
+// Logger 获取服务器的日志管理器，默认连接为全局日志管理器
+func (pr *ProfileRegistry) Logger(name string) (*DatabaseLogger, bool) {
+	if name == "" || name == DefaultProfile {
+		return GetDatabaseLogger(), true
+	}
+	if p := pr.profile(name); p != nil {
+		return p.logger, true
+	}
+	return nil, false
+}
+
+// ErrorAnalyzer 获取服务器的错误分析器，默认连接为全局错误分析器
+func (pr *ProfileRegistry) ErrorAnalyzer(name string) (*ErrorAnalyzer, bool) {
+	if name == "" || name == DefaultProfile {
+		return GetErrorAnalyzer(), true
+	}
+	if p := pr.profile(name); p != nil {
+		return p.analyzer, true
+	}
+	return nil, false
+}
+
+// DB 获取服务器的连接池，默认连接为全局连接池，尚未连接时连接池为 nil
+func (pr *ProfileRegistry) DB(name string) (*sql.DB, bool) {
+	if name == "" || name == DefaultProfile {
+		return GetDB(), true
+	}
+	if p := pr.profile(name); p != nil {
+		return p.getDB(), true
+	}
+	return nil, false
+}
+
+// Reconnector 获取服务器的重连器，连接配置无效的服务器没有重连器
+func (pr *ProfileRegistry) Reconnector(name string) (*Reconnector, bool) {
+	if name == "" || name == DefaultProfile {
+		return GetReconnector(), true
+	}
+	if p := pr.profile(name); p != nil && p.reconnector != nil {
+		return p.reconnector, true
+	}
+	return nil, false
+}
+
+// profile 按名称查找额外配置的服务器
+func (pr *ProfileRegistry) profile(name string) *profile {
+	for _, p := range pr.profiles {
+		if p.cfg.Name == name {
+			return p
+		}
+	}
+	return nil
+}
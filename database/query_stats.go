@@ -0,0 +1,350 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// queryStatsKey 请求上下文中查询统计的键
+type queryStatsKey struct{}
+
+// maxStatementLabel 统计明细中语句标签的最大长度
+const maxStatementLabel = 80
+
+const (
+	// maxTracedQuery 追踪记录中语句的最大长度
+	maxTracedQuery = 2000
+	// maxTracedQueries 单个请求最多追踪的语句数，超出的只计数
+	maxTracedQueries = 500
+)
+
+// QueryStats 一个请求执行的数据库查询统计
+// 耗时为驱动返回首批结果前的时间，不含遍历结果集的时间
+type QueryStats struct {
+	mu          sync.Mutex
+	count       int
+	total       time.Duration
+	byStatement map[string]*StatementStat
+	started     time.Time
+	tracing     bool
+	trace       []TracedQuery
+	untraced    int // 超过追踪上限而未记录的语句数
+}
+
+// TracedQuery 追踪模式下记录的一次语句执行
+type TracedQuery struct {
+	Query    string        `json:"query"`
+	Args     int           `json:"args"`
+	Offset   time.Duration `json:"offset"`   // 相对请求开始的时间
+	Duration time.Duration `json:"duration"` // 驱动返回首批结果前的耗时
+	Error    string        `json:"error,omitempty"`
+}
+
+// StatementStat 同一语句的执行次数与累计耗时
+type StatementStat struct {
+	Statement string
+	Count     int
+	Total     time.Duration
+}
+
+// WithQueryStats 返回附带查询统计的上下文，其下执行的查询都会计入返回的统计
+func WithQueryStats(ctx context.Context) (context.Context, *QueryStats) {
+	stats := &QueryStats{byStatement: make(map[string]*StatementStat), started: time.Now()}
+	return context.WithValue(ctx, queryStatsKey{}, stats), stats
+}
+
+// QueryStatsFrom 获取上下文中的查询统计，没有时返回 nil
+func QueryStatsFrom(ctx context.Context) *QueryStats {
+	return queryStatsFrom(ctx)
+}
+
+// EnableTrace 开启追踪，之后执行的每条语句都按执行顺序记录完整语句、参数个数、耗时和错误
+func (s *QueryStats) EnableTrace() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tracing = true
+}
+
+// Trace 按执行顺序返回追踪记录，以及超过上限而未记录的语句数
+func (s *QueryStats) Trace() ([]TracedQuery, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	trace := make([]TracedQuery, len(s.trace))
+	copy(trace, s.trace)
+	return trace, s.untraced
+}
+
+// queryStatsFrom 获取上下文中的查询统计，没有时返回 nil
+func queryStatsFrom(ctx context.Context) *QueryStats {
+	stats, _ := ctx.Value(queryStatsKey{}).(*QueryStats)
+	return stats
+}
+
+// record 记录一次查询
+func (s *QueryStats) record(query string, d time.Duration) {
+	s.recordTraced(query, 0, time.Time{}, d, nil)
+}
+
+// recordTraced 记录一次查询，追踪模式下同时记录执行明细
+func (s *QueryStats) recordTraced(query string, args int, start time.Time, d time.Duration, err error) {
+	label := statementLabel(query)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tracing {
+		if len(s.trace) < maxTracedQueries {
+			traced := TracedQuery{Query: strings.Join(strings.Fields(query), " "), Args: args, Offset: start.Sub(s.started), Duration: d}
+			if len(traced.Query) > maxTracedQuery {
+				traced.Query = traced.Query[:maxTracedQuery] + "..."
+			}
+			if err != nil {
+				traced.Error = err.Error()
+			}
+			s.trace = append(s.trace, traced)
+		} else {
+			s.untraced++
+		}
+	}
+	s.count++
+	s.total += d
+	stat, ok := s.byStatement[label]
+	if !ok {
+		stat = &StatementStat{Statement: label}
+		s.byStatement[label] = stat
+	}
+	stat.Count++
+	stat.Total += d
+}
+
+// Count 查询次数
+func (s *QueryStats) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Total 查询累计耗时
+func (s *QueryStats) Total() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
+
+// Breakdown 按累计耗时从高到低返回各语句的统计
+func (s *QueryStats) Breakdown() []StatementStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]StatementStat, 0, len(s.byStatement))
+	for _, stat := range s.byStatement {
+		list = append(list, *stat)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Total > list[j].Total })
+	return list
+}
+
+// String 以 "语句 x次数 耗时" 的形式列出明细
+func (s *QueryStats) String() string {
+	var parts []string
+	for _, stat := range s.Breakdown() {
+		parts = append(parts, fmt.Sprintf("%s x%d %v", stat.Statement, stat.Count, stat.Total.Round(time.Microsecond)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// statementLabel 将语句压缩为单行并截断，作为统计明细的标签
+func statementLabel(query string) string {
+	label := strings.Join(strings.Fields(query), " ")
+	if len(label) > maxStatementLabel {
+		label = label[:maxStatementLabel] + "..."
+	}
+	return label
+}
+
+// observe 若上下文带有查询统计，返回记录本次查询耗时和结果的函数
+// 带有查询统计的是页面和接口发起的查询，explorer 组件的日志级别为调试时逐条记录
+// 驱动返回 driver.ErrSkip 时 database/sql 会改用预处理语句重新执行，此次不计入
+func observe(ctx context.Context, query string, args int) func(err error) {
+	stats := queryStatsFrom(ctx)
+	if stats == nil {
+		return func(error) {}
+	}
+	start := time.Now()
+	return func(err error) {
+		if errors.Is(err, driver.ErrSkip) {
+			return
+		}
+		elapsed := time.Since(start)
+		stats.recordTraced(query, args, start, elapsed, err)
+		if logger := GetDatabaseLogger().Component(ComponentExplorer); logger.Enabled(LogLevelDebug) {
+			logger.Debug(fmt.Sprintf("查询耗时 %v", elapsed.Round(time.Microsecond)), statementLabel(query))
+		}
+	}
+}
+
+// statsConnector 包装驱动连接器，按请求上下文统计查询，开启故障模拟时在此注入故障
+type statsConnector struct {
+	driver.Connector
+}
+
+// Connect 建立连接并包装
+func (c statsConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if err := GetChaos().beforeConnect(); err != nil {
+		return nil, err
+	}
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &statsConn{conn: conn}, nil
+}
+
+// statsConn 记录查询耗时的驱动连接
+type statsConn struct {
+	conn driver.Conn
+}
+
+// Prepare 预处理语句
+func (c *statsConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext 预处理语句，语句执行时按执行时的上下文统计
+func (c *statsConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if p, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &statsStmt{Stmt: stmt, query: query}, nil
+}
+
+// ExecContext 执行语句
+func (c *statsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	done := observe(ctx, query, len(args))
+	if err := GetChaos().beforeStatement(ctx); err != nil {
+		done(err)
+		return nil, err
+	}
+	result, err := e.ExecContext(ctx, query, args)
+	done(err)
+	return result, err
+}
+
+// QueryContext 执行查询
+func (c *statsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	done := observe(ctx, query, len(args))
+	if err := GetChaos().beforeStatement(ctx); err != nil {
+		done(err)
+		return nil, err
+	}
+	rows, err := q.QueryContext(ctx, query, args)
+	done(err)
+	return rows, err
+}
+
+// Begin 开始事务
+func (c *statsConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx 开始事务
+func (c *statsConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.conn.Begin()
+}
+
+// Close 关闭连接
+func (c *statsConn) Close() error {
+	return c.conn.Close()
+}
+
+// Ping 检查连接
+func (c *statsConn) Ping(ctx context.Context) error {
+	if err := GetChaos().beforePing(ctx); err != nil {
+		return err
+	}
+	if p, ok := c.conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession 归还连接池前重置会话
+func (c *statsConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid 连接是否可复用
+func (c *statsConn) IsValid() bool {
+	if v, ok := c.conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue 交由驱动转换参数类型
+func (c *statsConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// statsStmt 记录执行耗时的预处理语句
+type statsStmt struct {
+	driver.Stmt
+	query string
+}
+
+// ExecContext 执行语句
+func (s *statsStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		done := observe(ctx, s.query, len(args))
+		if err := GetChaos().beforeStatement(ctx); err != nil {
+			done(err)
+			return nil, err
+		}
+		result, err := e.ExecContext(ctx, args)
+		done(err)
+		return result, err
+	}
+	return nil, fmt.Errorf("driver statement does not support ExecContext")
+}
+
+// QueryContext 执行查询
+func (s *statsStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		done := observe(ctx, s.query, len(args))
+		if err := GetChaos().beforeStatement(ctx); err != nil {
+			done(err)
+			return nil, err
+		}
+		rows, err := q.QueryContext(ctx, args)
+		done(err)
+		return rows, err
+	}
+	return nil, fmt.Errorf("driver statement does not support QueryContext")
+}
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrReadOnly 只读模式下拒绝执行的语句
+var ErrReadOnly = errors.New("read-only mode: statement rejected")
+
+// readOnlyKeywords 只读模式允许的语句类型
+var readOnlyKeywords = map[string]bool{
+	"SELECT":   true,
+	"SHOW":     true,
+	"EXPLAIN":  true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"WITH":     true,
+}
+
+var (
+	// leadingNoise 语句开头的空白、注释与括号
+	leadingNoise = regexp.MustCompile(`^(\s+|(?s:/\*.*?\*/)|(--|#)[^\n]*\n?|\()+`)
+	// literals 字符串、带引号的标识符与注释，检查子句前先去掉
+	literals = regexp.MustCompile("'(?:[^'\\\\]|\\\\.)*'|\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`|(?s:/\\*.*?\\*/)")
+	// writeClause 只读语句中仍会产生写入的子句
+	writeClause = regexp.MustCompile(`(?i)\b(INTO\s+(OUTFILE|DUMPFILE)|UPDATE|DELETE|INSERT|REPLACE)\b`)
+)
+
+// checkReadOnly 判断语句是否允许在只读模式下执行
+func checkReadOnly(query string) error {
+	// MySQL 会执行 /*! ... */ 中的内容，不能当作注释跳过 (字符串中出现时同样拒绝)
+	if strings.Contains(query, "/*!") {
+		return fmt.Errorf("%w: executable comment", ErrReadOnly)
+	}
+	stmt := leadingNoise.ReplaceAllString(query, "")
+	keyword := stmt
+	if i := strings.IndexFunc(stmt, func(r rune) bool { return !isKeywordChar(r) }); i >= 0 {
+		keyword = stmt[:i]
+	}
+	keyword = strings.ToUpper(keyword)
+
+	if !readOnlyKeywords[keyword] {
+		return fmt.Errorf("%w: %s", ErrReadOnly, keyword)
+	}
+	// SELECT ... INTO OUTFILE、WITH ... UPDATE 等形式同样拒绝
+	// (SELECT ... FOR UPDATE 只加锁不写入，允许执行)
+	if m := writeClause.FindString(stripForUpdate(literals.ReplaceAllString(stmt, "''"))); m != "" {
+		return fmt.Errorf("%w: %s", ErrReadOnly, strings.ToUpper(m))
+	}
+	return nil
+}
+
+// forUpdate 匹配锁定读子句
+var forUpdate = regexp.MustCompile(`(?i)\bFOR\s+UPDATE\b`)
+
+// stripForUpdate 去掉 FOR UPDATE 子句，避免被误判为写入
+func stripForUpdate(stmt string) string {
+	return forUpdate.ReplaceAllString(stmt, "")
+}
+
+// isKeywordChar 判断是否为关键字字符
+func isKeywordChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// readOnlyConnector 包装驱动连接器，创建的连接拒绝非只读语句
+type readOnlyConnector struct {
+	driver.Connector
+}
+
+// Connect 建立连接并包装
+func (c readOnlyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyConn{conn: conn}, nil
+}
+
+// readOnlyConn 在执行前检查语句的驱动连接
+type readOnlyConn struct {
+	conn driver.Conn
+}
+
+// Prepare 预处理语句
+func (c *readOnlyConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext 预处理语句
+func (c *readOnlyConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := checkReadOnly(query); err != nil {
+		return nil, err
+	}
+	if p, ok := c.conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.conn.Prepare(query)
+}
+
+// ExecContext 执行语句
+func (c *readOnlyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := checkReadOnly(query); err != nil {
+		return nil, err
+	}
+	if e, ok := c.conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+// QueryContext 执行查询
+func (c *readOnlyConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := checkReadOnly(query); err != nil {
+		return nil, err
+	}
+	if q, ok := c.conn.(driver.QueryerContext); ok {
+		return q.QueryContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+// Begin 开始事务
+func (c *readOnlyConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx 开始只读事务
+func (c *readOnlyConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	opts.ReadOnly = true
+	if b, ok := c.conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.conn.Begin()
+}
+
+// Close 关闭连接
+func (c *readOnlyConn) Close() error {
+	return c.conn.Close()
+}
+
+// Ping 检查连接
+func (c *readOnlyConn) Ping(ctx context.Context) error {
+	if p, ok := c.conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession 归还连接池前重置会话
+func (c *readOnlyConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid 连接是否可复用
+func (c *readOnlyConn) IsValid() bool {
+	if v, ok := c.conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue 交由驱动转换参数类型
+func (c *readOnlyConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		allowed bool
+	}{
+		// 允许的只读语句
+		{"select", "SELECT * FROM orders", true},
+		{"lowercase select", "select 1", true},
+		{"select for update", "SELECT * FROM orders WHERE id = 1 FOR UPDATE", true},
+		{"select for update multiline", "SELECT id FROM orders FOR\n  UPDATE NOWAIT", true},
+		{"with select", "WITH recent AS (SELECT id FROM orders) SELECT * FROM recent", true},
+		{"show", "SHOW PROCESSLIST", true},
+		{"explain", "EXPLAIN SELECT * FROM orders", true},
+		{"describe", "DESCRIBE orders", true},
+		{"desc", "DESC orders", true},
+		{"leading whitespace", "\n\t  SELECT 1", true},
+		{"leading block comment", "/* dashboard */ SELECT 1", true},
+		{"leading line comment", "-- dashboard\nSELECT 1", true},
+		{"leading hash comment", "# dashboard\nSELECT 1", true},
+		{"leading parentheses", "((SELECT 1) UNION (SELECT 2))", true},
+		{"comment and parenthesis", "/* a */ ( SELECT 1 )", true},
+		{"UPDATE_RULE identifier", "SELECT UPDATE_RULE, DELETE_RULE FROM information_schema.REFERENTIAL_CONSTRAINTS", true},
+		{"last_update identifier", "SELECT last_update FROM film", true},
+		{"update_time identifier", "SELECT UPDATE_TIME FROM information_schema.TABLES", true},
+		{"write keyword in string", "SELECT * FROM audit WHERE action = 'DELETE'", true},
+		{"write keyword in quoted identifier", "SELECT `update` FROM t", true},
+		{"write keyword in inner comment", "SELECT /* no UPDATE here */ 1", true},
+		{"select into variable", "SELECT COUNT(*) INTO @n FROM orders", true},
+
+		// 拒绝的语句
+		{"select into outfile", "SELECT * FROM orders INTO OUTFILE '/tmp/orders.csv'", false},
+		{"select into dumpfile", "SELECT data FROM blobs INTO DUMPFILE '/tmp/x'", false},
+		{"commented delete", "/* cleanup */ DELETE FROM orders", false},
+		{"line commented delete", "-- cleanup\nDELETE FROM orders", false},
+		{"parenthesised delete", "(DELETE FROM orders)", false},
+		{"with update", "WITH x AS (SELECT id FROM orders) UPDATE orders SET paid = 1 WHERE id IN (SELECT id FROM x)", false},
+		{"with delete", "WITH x AS (SELECT 1) DELETE FROM orders", false},
+		{"with for update then update", "WITH x AS (SELECT id FROM orders FOR UPDATE) UPDATE orders SET paid = 1", false},
+		{"update", "UPDATE orders SET paid = 1", false},
+		{"insert", "INSERT INTO orders VALUES (1)", false},
+		{"replace", "REPLACE INTO orders VALUES (1)", false},
+		{"insert select", "INSERT INTO archive SELECT * FROM orders", false},
+		{"kill", "KILL 42", false},
+		{"kill query", "KILL QUERY 42", false},
+		{"set", "SET GLOBAL read_only = 0", false},
+		{"call", "CALL cleanup()", false},
+		{"drop", "DROP TABLE orders", false},
+		{"truncate", "TRUNCATE orders", false},
+		{"executable comment", "/*! DELETE FROM orders */ SELECT 1", false},
+		{"versioned executable comment", "/*!50000 DELETE FROM orders */", false},
+		{"empty", "", false},
+		{"only comment", "/* nothing */", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReadOnly(tt.query)
+			if tt.allowed && err != nil {
+				t.Fatalf("checkReadOnly(%q) = %v, want nil", tt.query, err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrReadOnly) {
+				t.Fatalf("checkReadOnly(%q) = %v, want ErrReadOnly", tt.query, err)
+			}
+		})
+	}
+}
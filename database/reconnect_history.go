@@ -0,0 +1,72 @@
+package database
+
+import (
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// ReconnectAttempt 一次重连尝试，失败时按错误分析器的规则分类
+type ReconnectAttempt struct {
+	Time       time.Time `json:"time"`
+	DurationMs float64   `json:"duration_ms"`
+	Attempt    int       `json:"attempt"` // 本次中断中的第几次尝试
+	Success    bool      `json:"success"`
+	Host       string    `json:"host,omitempty"` // 成功连接的主机，额外服务器为空
+	ErrorType  ErrorType `json:"error_type,omitempty"`
+	ErrorCode  string    `json:"error_code,omitempty"`
+	Cause      string    `json:"cause,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// recordAttempt 记录一次重连尝试，失败原因取自 attempt 设置的 lastError
+// 超过保留数量或保留时长的记录被丢弃
+func (r *Reconnector) recordAttempt(started time.Time, host config.DBHost, ok bool) {
+	a := ReconnectAttempt{
+		Time:       started,
+		DurationMs: durationMs(time.Since(started)),
+		Success:    ok,
+	}
+	if ok && host.Host != "" {
+		a.Host = host.Address()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a.Attempt = r.retryCount + 1
+	if !ok && r.lastError != nil {
+		pattern := GetErrorAnalyzer().matchErrorPattern(r.lastError.Error())
+		a.ErrorType, a.ErrorCode, a.Cause = pattern.Type, pattern.Code, pattern.Cause
+		a.Error = r.lastError.Error()
+	}
+
+	if maxAge := r.config.ReconnectHistoryMaxAge; maxAge > 0 {
+		cutoff := started.Add(-maxAge)
+		drop := 0
+		for drop < len(r.attempts) && r.attempts[drop].Time.Before(cutoff) {
+			drop++
+		}
+		r.attempts = r.attempts[drop:]
+	}
+	if size := r.config.ReconnectHistorySize; size > 0 && len(r.attempts) >= size {
+		r.attempts = r.attempts[len(r.attempts)-size+1:]
+	}
+	r.attempts = append(r.attempts, a)
+}
+
+// AttemptHistory 获取 since 之后的重连尝试，按时间倒序，limit 为 0 时不限制
+func (r *Reconnector) AttemptHistory(since time.Time, limit int) []ReconnectAttempt {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	attempts := []ReconnectAttempt{}
+	for i := len(r.attempts) - 1; i >= 0; i-- {
+		if r.attempts[i].Time.Before(since) {
+			break
+		}
+		attempts = append(attempts, r.attempts[i])
+		if limit > 0 && len(attempts) >= limit {
+			break
+		}
+	}
+	return attempts
+}
@@ -2,7 +2,6 @@ package database
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"sync"
 	"time"
@@ -13,14 +12,20 @@ import (
 // Reconnector 重连器
 type Reconnector struct {
 	mu           sync.RWMutex
-	isConnected  bool
-	reconnecting bool
-	ctx          context.Context
-	cancel       context.CancelFunc
+	state        ConnState
+	stateSince   time.Time
+	transitions  []StateTransition
+	cancel       context.CancelFunc // 当前重连循环的取消函数，未在重连时为 nil
+	nextAttempt  time.Time          // 下一次重连尝试的时间
 	config       *config.DBConfig
 	retryCount   int
 	lastError    error
 	errorHistory []string
+	attempts     []ReconnectAttempt
+	profile      string          // 所属服务器名称
+	logger       *DatabaseLogger // 所属服务器的日志管理器
+	// connect 额外服务器的连接函数，为 nil 时为默认连接，成功后替换全局连接池
+	connect func() error
 }
 
 var (
@@ -31,28 +36,54 @@ var (
 // GetReconnector 获取重连器实例
 func GetReconnector() *Reconnector {
 	once.Do(func() {
-		ctx, cancel := context.WithCancel(context.Background())
 		reconnector = &Reconnector{
-			ctx:    ctx,
-			cancel: cancel,
-			config: config.GetDBConfig(),
+			state:      StateStopped,
+			stateSince: time.Now(),
+			config:     config.GetDBConfig(),
+			profile:    DefaultProfile,
+			logger:     GetDatabaseLogger(),
 		}
 	})
 	return reconnector
 }
 
+// newProfileReconnector 创建额外服务器的重连器，重连参数与默认连接相同
+// 重连只影响该服务器自己的连接、日志和错误历史，不记录到默认连接的可用率和事件时间线
+func newProfileReconnector(profile string, logger *DatabaseLogger, connect func() error) *Reconnector {
+	cfg := *config.GetDBConfig()
+	cfg.Hosts = nil
+	return &Reconnector{
+		state:      StateStopped,
+		stateSince: time.Now(),
+		config:     &cfg,
+		profile:    profile,
+		logger:     logger,
+		connect:    connect,
+	}
+}
+
+// log 获取重连器组件的日志记录器
+func (r *Reconnector) log() *ComponentLogger {
+	return r.logger.Component(ComponentReconnector)
+}
+
+// isDefault 是否为默认连接的重连器
+func (r *Reconnector) isDefault() bool {
+	return r.connect == nil
+}
+
 // IsConnected 检查是否已连接
 func (r *Reconnector) IsConnected() bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return r.isConnected
+	return r.state.Connected()
 }
 
 // IsReconnecting 检查是否正在重连
 func (r *Reconnector) IsReconnecting() bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return r.reconnecting
+	return r.state == StateReconnecting
 }
 
 // GetRetryCount 获取重试次数
@@ -69,6 +100,16 @@ func (r *Reconnector) GetLastError() error {
 	return r.lastError
 }
 
+// RetryAfter 距下一次重连尝试的时长，至少为 1 秒，用于 Retry-After 响应头
+func (r *Reconnector) RetryAfter() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if wait := time.Until(r.nextAttempt); wait > time.Second {
+		return wait
+	}
+	return time.Second
+}
+
 // GetErrorHistory 获取错误历史
 func (r *Reconnector) GetErrorHistory() []string {
 	r.mu.RLock()
@@ -81,49 +122,106 @@ func (r *Reconnector) GetErrorHistory() []string {
 
 // StartReconnection 开始重连
 func (r *Reconnector) StartReconnection() {
+	r.startReconnection("开始重连")
+}
+
+// startReconnection 切换到重连状态并以新的上下文启动重连循环，已在重连时忽略
+func (r *Reconnector) startReconnection(reason string) {
 	r.mu.Lock()
-	if r.reconnecting {
+	if r.state == StateReconnecting {
 		r.mu.Unlock()
 		return
 	}
-	r.reconnecting = true
+	r.setStateLocked(StateReconnecting, reason)
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
 	r.mu.Unlock()
 
-	go r.reconnectionLoop()
+	go r.reconnectionLoop(ctx)
 }
 
-// StopReconnection 停止重连
+// StopReconnection 停止重连，之后可再次调用 StartReconnection 重新开始
 func (r *Reconnector) StopReconnection() {
 	r.mu.Lock()
-	r.reconnecting = false
+	defer r.mu.Unlock()
+	r.releaseLocked()
+	r.setStateLocked(StateStopped, "重连器已停止")
+}
+
+// Restart 手动重连：停止当前的重连循环，清零重试计数后重新开始
+// 已连接时同样会重新建立连接池，可用于切回首选主机或在放弃重连后恢复
+func (r *Reconnector) Restart() {
+	r.mu.Lock()
+	r.releaseLocked()
+	r.retryCount = 0
+	r.lastError = nil
+	r.setStateLocked(StateStopped, "手动重连")
 	r.mu.Unlock()
-	r.cancel()
+
+	r.startReconnection("手动重连")
 }
 
-// reconnectionLoop 重连循环
-func (r *Reconnector) reconnectionLoop() {
-	initialDelay := 1 * time.Second
-	maxDelay := 30 * time.Second
+// releaseLocked 取消当前重连循环的上下文，调用方需持有 r.mu
+func (r *Reconnector) releaseLocked() {
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+// reconnectionLoop 重连循环，ctx 取消时退出
+// 结束时在持锁状态下确认 ctx 未被取消，此时 r.cancel 必定属于本次循环
+// 重试间隔按指数退避并加入随机抖动，窗口内尝试次数超过预算时暂停，认证和配置错误可配置为立即放弃
+func (r *Reconnector) reconnectionLoop(ctx context.Context) {
+	initialDelay := r.config.ReconnectInitialDelay
+	maxDelay := r.config.ReconnectMaxDelay
 	currentDelay := initialDelay
+	budget := &retryBudget{limit: r.config.ReconnectBudget, window: r.config.ReconnectBudgetWindow}
+	diagnosed := false // 本次中断是否已采集诊断包
 	
 	// 创建重连专用日志记录器
 	reconnLogger := NewReconnectionLogger()
+	if !r.isDefault() {
+		reconnLogger.logger = r.log()
+		reconnLogger.incidents = false
+	}
 	reconnLogger.StartReconnection()
 
 	for {
 		select {
-		case <-r.ctx.Done():
+		case <-ctx.Done():
 			return
 		default:
+			// 窗口内的尝试次数已用完时等待预算恢复
+			if wait := budget.wait(time.Now()); wait > 0 {
+				r.log().Warn(fmt.Sprintf("重连次数超过预算 (%d 次/%v)，暂停 %v", budget.limit, budget.window, wait.Round(time.Second)))
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+			}
+			budget.record(time.Now())
+
 			// 尝试连接
-			if r.tryConnect() {
+			started := time.Now()
+			host, ok := r.attempt()
+			r.recordAttempt(started, host, ok)
+			if ok {
 				r.mu.Lock()
+				if ctx.Err() != nil {
+					r.mu.Unlock()
+					return
+				}
+				r.releaseLocked()
 				successRetryCount := r.retryCount
-				r.isConnected = true
-				r.reconnecting = false
+				r.setStateLocked(r.connectedStateFor(host), fmt.Sprintf("重连成功: %s", host.Address()))
 				r.retryCount = 0 // 重置重试计数
 				r.lastError = nil
 				r.mu.Unlock()
+				if r.isDefault() {
+					GetUptimeTracker().markUp()
+				}
 				
 				// 记录成功日志
 				reconnLogger.LogSuccess(successRetryCount)
@@ -131,19 +229,50 @@ func (r *Reconnector) reconnectionLoop() {
 			}
 
 			r.mu.Lock()
+			if ctx.Err() != nil {
+				r.mu.Unlock()
+				return
+			}
 			r.retryCount++
 			retryCount := r.retryCount
 			lastError := r.lastError
+			outage := time.Since(r.stateSince)
+			gaveUp := true
+			switch {
+			case r.config.ReconnectGiveUpOnFatal && !retryable(lastError):
+				r.setStateLocked(StateGaveUp, fmt.Sprintf("认证或配置错误，重试无效: %v", lastError))
+			case r.config.ReconnectMaxRetries > 0 && retryCount >= r.config.ReconnectMaxRetries:
+				r.setStateLocked(StateGaveUp, fmt.Sprintf("重连 %d 次后放弃: %v", retryCount, lastError))
+			default:
+				gaveUp = false
+			}
+			if gaveUp {
+				r.releaseLocked()
+			}
 			r.mu.Unlock()
 
+			if r.isDefault() && !diagnosed && outage >= GetDiagnostics().cfg.ConnectionLossThreshold {
+				diagnosed = true
+				GetDiagnostics().Trigger(BundleReasonConnectionLost, fmt.Sprintf("连接中断 %v，已重试 %d 次: %v", outage.Round(time.Second), retryCount, lastError))
+			}
+
+			if gaveUp {
+				reconnLogger.LogFailure(retryCount, lastError)
+				return
+			}
+
 			// 使用新的日志记录器
-			reconnLogger.LogRetry(retryCount, currentDelay, lastError)
+			delay := jitterDelay(currentDelay, r.config.ReconnectJitter)
+			r.mu.Lock()
+			r.nextAttempt = time.Now().Add(delay)
+			r.mu.Unlock()
+			reconnLogger.LogRetry(retryCount, delay, lastError)
 
 			// 等待后重试
 			select {
-			case <-r.ctx.Done():
+			case <-ctx.Done():
 				return
-			case <-time.After(currentDelay):
+			case <-time.After(delay):
 				// 指数退避，但不超过最大延迟
 				currentDelay *= 2
 				if currentDelay > maxDelay {
@@ -154,45 +283,33 @@ func (r *Reconnector) reconnectionLoop() {
 	}
 }
 
-// tryConnect 尝试连接
-func (r *Reconnector) tryConnect() bool {
-	dsn := buildDSN(r.config)
-
-	newDB, err := sql.Open("mysql", dsn)
-	if err != nil {
+// attempt 进行一次连接尝试，额外服务器使用自己的连接函数
+func (r *Reconnector) attempt() (config.DBHost, bool) {
+	if r.isDefault() {
+		return r.tryConnect()
+	}
+	if err := r.connect(); err != nil {
 		r.mu.Lock()
 		r.lastError = err
-		r.addErrorToHistory(fmt.Sprintf("打开数据库连接失败: %v", err))
+		r.addErrorToHistory(fmt.Sprintf("数据库连接测试失败: %v", err))
 		r.mu.Unlock()
-		return false
+		return config.DBHost{}, false
 	}
+	return config.DBHost{}, true
+}
 
-	// 设置连接池参数
-	newDB.SetMaxOpenConns(10)
-	newDB.SetMaxIdleConns(5)
-	newDB.SetConnMaxLifetime(time.Hour)
+// tryConnect 按优先级依次尝试候选主机，成功时返回连接的主机
+func (r *Reconnector) tryConnect() (config.DBHost, bool) {
+	// 短期凭据可能已过期，重连时重新获取
+	refreshCredentials()
 
-	// 测试连接
-	if err := newDB.Ping(); err != nil {
+	newDB, host, err := openAvailable(r.config)
+	if err != nil {
 		r.mu.Lock()
 		r.lastError = err
 		r.addErrorToHistory(fmt.Sprintf("数据库连接测试失败: %v", err))
 		r.mu.Unlock()
-		
-		// 创建连接信息对象
-		connInfo := &ConnectionInfo{
-			Host:     r.config.Host,
-			Port:     r.config.Port,
-			Username: r.config.User,
-			Password: r.config.Pass,
-			Database: r.config.Name,
-		}
-
-		if closeErr := newDB.Close(); closeErr != nil {
-			logger := GetDatabaseLogger()
-			logger.ErrorWithConnection("关闭新数据库连接失败", connInfo, closeErr.Error())
-		}
-		return false
+		return config.DBHost{}, false
 	}
 
 	// 替换全局数据库连接
@@ -207,19 +324,27 @@ func (r *Reconnector) tryConnect() bool {
 				Password: r.config.Pass,
 				Database: r.config.Name,
 			}
-			logger := GetDatabaseLogger()
+			logger := r.log()
 			logger.ErrorWithConnection("关闭旧数据库连接失败", connInfo, closeErr.Error())
 		}
 	}
 	db = newDB
 	mu.Unlock()
+	setActiveHost(host)
+	if err := replaceMonitorDB(r.config, host); err != nil {
+		r.log().Warn("健康检查连接打开失败", err.Error())
+	}
+	go warmPool(newDB, r.config.PoolWarmup)
 
-	return true
+	if len(r.config.Hosts) > 1 {
+		r.log().Info(fmt.Sprintf("当前连接主机: %s", host.Address()))
+	}
+	return host, true
 }
 
 // addErrorToHistory 添加错误到历史记录
 func (r *Reconnector) addErrorToHistory(errorMsg string) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	timestamp := config.FormatDisplayTime(time.Now())
 	entry := fmt.Sprintf("[%s] %s", timestamp, errorMsg)
 	
 	// 只保留最近的10条错误记录
@@ -230,38 +355,53 @@ func (r *Reconnector) addErrorToHistory(errorMsg string) {
 }
 
 // OnConnectionLost 连接丢失时的回调
+// 已放弃重连时不再自动重连，需显式调用 StartReconnection
 func (r *Reconnector) OnConnectionLost() {
 	r.mu.Lock()
-	r.isConnected = false
+	wasConnected := r.state.Connected()
+	gaveUp := r.state == StateGaveUp
 	r.mu.Unlock()
 
 	// 创建连接信息对象
-	connInfo := &ConnectionInfo{
-		Host:     r.config.Host,
-		Port:     r.config.Port,
-		Username: r.config.User,
-		Password: r.config.Pass,
-		Database: r.config.Name,
+	var connInfo *ConnectionInfo
+	if r.isDefault() {
+		GetUptimeTracker().markDown()
+		if wasConnected {
+			GetIncidentRecorder().recordEvent(EventConnectionLost, "数据库连接丢失", 0)
+		}
+		connInfo = &ConnectionInfo{
+			Host:     r.config.Host,
+			Port:     r.config.Port,
+			Username: r.config.User,
+			Password: r.config.Pass,
+			Database: r.config.Name,
+		}
 	}
 
-	logger := GetDatabaseLogger()
-	logger.WarnWithConnection("❌ 数据库连接丢失，启动重连程序...", connInfo)
-	r.StartReconnection()
+	logger := r.log()
+	if gaveUp {
+		return
+	}
+	logger.addEntryWithConnection(connectionFailureLevel(LogLevelWarn), "❌ 数据库连接丢失，启动重连程序...", "", connInfo)
+	r.startReconnection("连接丢失")
 }
 
 // CheckConnection 检查连接状态
 func (r *Reconnector) CheckConnection() bool {
-	if db == nil {
+	monitor := GetMonitorDB()
+	if monitor == nil {
 		return false
 	}
 
-	if err := db.Ping(); err != nil {
+	if err := monitor.Ping(); err != nil {
 		r.OnConnectionLost()
 		return false
 	}
 
-	r.mu.Lock()
-	r.isConnected = true
-	r.mu.Unlock()
+	activeHostMu.RLock()
+	host := activeHost
+	activeHostMu.RUnlock()
+	r.markConnected(host, "连接检查成功")
+	GetUptimeTracker().markUp()
 	return true
 }
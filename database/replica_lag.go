@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// LagSample 一次复制延迟采样
+type LagSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Seconds   *int64    `json:"seconds"` // 复制线程未运行时为 nil
+}
+
+// ReplicaLagTracker 定期采样各服务器的 Seconds_Behind_Source 并按服务器保存历史
+// 只记录作为从库的服务器，非从库的服务器没有历史
+type ReplicaLagTracker struct {
+	mu         sync.RWMutex
+	samples    map[string][]LagSample // 按服务器名称索引
+	maxSamples int
+	registry   *ProfileRegistry
+	logger     *ComponentLogger
+	stop       chan struct{}
+}
+
+var (
+	replicaLagTracker *ReplicaLagTracker
+	replicaLagOnce    sync.Once
+)
+
+// GetReplicaLagTracker 获取复制延迟采样器实例
+func GetReplicaLagTracker() *ReplicaLagTracker {
+	replicaLagOnce.Do(func() {
+		replicaLagTracker = &ReplicaLagTracker{
+			samples:    make(map[string][]LagSample),
+			maxSamples: config.GetReplicaLagConfig().MaxSamples,
+			registry:   GetProfileRegistry(),
+			logger:     GetDatabaseLogger().Component(ComponentScheduler),
+		}
+	})
+	return replicaLagTracker
+}
+
+// Start 按指定间隔开始采样，interval 为 0 时不启动
+func (rt *ReplicaLagTracker) Start(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	rt.mu.Lock()
+	if rt.stop != nil {
+		rt.mu.Unlock()
+		return
+	}
+	rt.stop = make(chan struct{})
+	stop := rt.stop
+	rt.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		rt.Sample(context.Background())
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				rt.Sample(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop 停止采样
+func (rt *ReplicaLagTracker) Stop() {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.stop != nil {
+		close(rt.stop)
+		rt.stop = nil
+	}
+}
+
+// Sample 立即对所有服务器执行一次采样
+func (rt *ReplicaLagTracker) Sample(ctx context.Context) {
+	for _, status := range rt.registry.Statuses() {
+		db, ok := rt.registry.DB(status.Name)
+		if !ok || db == nil {
+			continue
+		}
+		queryCtx, cancel := context.WithTimeout(ctx, profileCheckTimeout)
+		lag, replica, err := queryReplicaLag(queryCtx, db)
+		cancel()
+		if err != nil {
+			rt.logger.Debug("复制延迟采样失败 ("+status.Name+")", err.Error())
+			continue
+		}
+		if !replica {
+			continue
+		}
+		rt.record(status.Name, LagSample{Timestamp: time.Now(), Seconds: lag})
+	}
+}
+
+// record 追加一次采样，超过保留数量时丢弃最早的采样
+func (rt *ReplicaLagTracker) record(name string, sample LagSample) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	samples := rt.samples[name]
+	if rt.maxSamples > 0 && len(samples) >= rt.maxSamples {
+		samples = samples[1:]
+	}
+	rt.samples[name] = append(samples, sample)
+}
+
+// Profiles 有复制延迟历史的服务器名称，按名称排序
+func (rt *ReplicaLagTracker) Profiles() []string {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	names := make([]string, 0, len(rt.samples))
+	for name := range rt.samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// History 获取服务器在 since 之后的采样，since 为零值时返回全部
+func (rt *ReplicaLagTracker) History(name string, since time.Time) []LagSample {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	var samples []LagSample
+	for _, s := range rt.samples[name] {
+		if !s.Timestamp.Before(since) {
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
+// Latest 获取服务器最近一次采样，没有采样时返回 false
+func (rt *ReplicaLagTracker) Latest(name string) (LagSample, bool) {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	samples := rt.samples[name]
+	if len(samples) == 0 {
+		return LagSample{}, false
+	}
+	return samples[len(samples)-1], true
+}
+
+// SustainedAbove 延迟持续超过 threshold 秒的时长：从最近一次采样往前，连续超过阈值的最早采样到最近一次采样的时间
+// 最近一次采样未超过阈值时为 0
+func (rt *ReplicaLagTracker) SustainedAbove(name string, threshold int64) time.Duration {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	samples := rt.samples[name]
+	if len(samples) == 0 {
+		return 0
+	}
+	latest := samples[len(samples)-1]
+	since := latest.Timestamp
+	for i := len(samples) - 1; i >= 0; i-- {
+		if samples[i].Seconds == nil || *samples[i].Seconds <= threshold {
+			break
+		}
+		since = samples[i].Timestamp
+	}
+	if latest.Seconds == nil || *latest.Seconds <= threshold {
+		return 0
+	}
+	return latest.Timestamp.Sub(since)
+}
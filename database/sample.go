@@ -0,0 +1,252 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/furutachiKurea/block-checker/sqltools"
+)
+
+// ErrTableNotFound 表不存在
+var ErrTableNotFound = errors.New("table not found")
+
+// MaxSampleRows 单次采样的行数上限
+const MaxSampleRows = 1000
+
+// TableSample 表数据采样结果，Rows 中的值为字符串或 nil (NULL)
+type TableSample struct {
+	Database string          `json:"database"`
+	Table    string          `json:"table"`
+	Method   string          `json:"method"` // pk-chunk: 按主键分块随机采样；head: 取前 n 行
+	Seed     int64           `json:"seed"`
+	Columns  []string        `json:"columns"`
+	Rows     [][]interface{} `json:"rows"`
+	Masked   []string        `json:"masked,omitempty"` // 按脱敏规则处理过的字段
+}
+
+// sampleChunks 主键分块采样的块数
+const sampleChunks = 10
+
+// SampleTable 对表做可复现的随机采样
+func SampleTable(ctx context.Context, databaseName, tableName string, n int, seed int64) (*TableSample, error) {
+	return defaultStore.SampleTable(ctx, databaseName, tableName, n, seed)
+}
+
+// SampleTable 对表做可复现的随机采样
+// 单列整数主键时，以 seed 生成若干随机起点，按主键范围读取小块数据，避免全表扫描和 ORDER BY RAND()
+// 其他情况退化为读取前 n 行
+func (s *MySQLStore) SampleTable(ctx context.Context, databaseName, tableName string, n int, seed int64) (*TableSample, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if n <= 0 {
+		n = 100
+	}
+	if n > MaxSampleRows {
+		n = MaxSampleRows
+	}
+
+	if err := checkTable(ctx, db, databaseName, tableName); err != nil {
+		return nil, err
+	}
+
+	sample := &TableSample{Database: databaseName, Table: tableName, Seed: seed, Rows: [][]interface{}{}}
+	table := QuoteTable(databaseName, tableName)
+
+	pk, unsigned, err := integerPrimaryKey(ctx, db, databaseName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if pk == "" {
+		sample.Method = "head"
+		rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s LIMIT %d", table, n))
+		if err != nil {
+			return nil, fmt.Errorf("query sample: %w", err)
+		}
+		sample.Columns, sample.Rows, err = scanSampleRows(rows, -1, nil)
+		if err != nil {
+			return nil, fmt.Errorf("scan sample: %w", err)
+		}
+		sample.Masked = GetMasker().MaskRows(databaseName, tableName, sample.Columns, sample.Rows)
+		return sample, nil
+	}
+
+	sample.Method = "pk-chunk"
+	quotedPK := sqltools.QuoteIdentifier(pk)
+	// 以字符串读取范围，UNSIGNED BIGINT 可能超出 int64
+	var minText, maxText sql.NullString
+	if err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", quotedPK, quotedPK, table)).Scan(&minText, &maxText); err != nil {
+		return nil, fmt.Errorf("query key range: %w", err)
+	}
+	if !minText.Valid {
+		return sample, nil
+	}
+	starts, err := sampleStarts(minText.String, maxText.String, unsigned, seed)
+	if err != nil {
+		return nil, err
+	}
+	chunkSize := (n + sampleChunks - 1) / sampleChunks
+
+	seen := make(map[string]bool)
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s >= ? ORDER BY %s LIMIT %d", table, quotedPK, quotedPK, chunkSize)
+	for _, start := range starts {
+		rows, err := db.QueryContext(ctx, query, start)
+		if err != nil {
+			return nil, fmt.Errorf("query sample chunk: %w", err)
+		}
+		columns, chunk, err := scanSampleRows(rows, columnIndex(rows, pk), seen)
+		if err != nil {
+			return nil, fmt.Errorf("scan sample chunk: %w", err)
+		}
+		sample.Columns = columns
+		sample.Rows = append(sample.Rows, chunk...)
+		if len(sample.Rows) >= n {
+			sample.Rows = sample.Rows[:n]
+			break
+		}
+	}
+	sample.Masked = GetMasker().MaskRows(databaseName, tableName, sample.Columns, sample.Rows)
+	return sample, nil
+}
+
+// sampleStarts 在 [min, max] 内以 seed 生成 sampleChunks 个随机起点并排序
+// 范围按任意精度计算，主键跨越大半个 int64 (如负数哈希 ID) 或为超过 int64 的 UNSIGNED BIGINT 时不会溢出
+// 返回值为 int64 或 uint64 (unsigned 时)，可直接作为查询参数
+func sampleStarts(minText, maxText string, unsigned bool, seed int64) ([]interface{}, error) {
+	lo, ok := new(big.Int).SetString(minText, 10)
+	if !ok {
+		return nil, fmt.Errorf("parse key range: invalid minimum %q", minText)
+	}
+	hi, ok := new(big.Int).SetString(maxText, 10)
+	if !ok {
+		return nil, fmt.Errorf("parse key range: invalid maximum %q", maxText)
+	}
+	span := new(big.Int).Sub(hi, lo)
+	span.Add(span, big.NewInt(1))
+
+	// 随机起点排序后依次读取，结果按主键有序
+	rng := rand.New(rand.NewSource(seed))
+	keys := make([]*big.Int, sampleChunks)
+	for i := range keys {
+		keys[i] = new(big.Int).Add(lo, new(big.Int).Rand(rng, span))
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Cmp(keys[j]) < 0 })
+
+	starts := make([]interface{}, len(keys))
+	for i, key := range keys {
+		if unsigned {
+			starts[i] = key.Uint64()
+		} else {
+			starts[i] = key.Int64()
+		}
+	}
+	return starts, nil
+}
+
+// integerPrimaryKey 返回单列整数主键的列名及是否为 UNSIGNED，不满足条件时列名为空字符串
+func integerPrimaryKey(ctx context.Context, db *sql.DB, databaseName, tableName string) (string, bool, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT s.COLUMN_NAME, c.DATA_TYPE, c.COLUMN_TYPE
+		FROM information_schema.STATISTICS s
+		JOIN information_schema.COLUMNS c
+			ON c.TABLE_SCHEMA = s.TABLE_SCHEMA AND c.TABLE_NAME = s.TABLE_NAME AND c.COLUMN_NAME = s.COLUMN_NAME
+		WHERE s.TABLE_SCHEMA = ? AND s.TABLE_NAME = ? AND s.INDEX_NAME = 'PRIMARY'
+		ORDER BY s.SEQ_IN_INDEX`, databaseName, tableName)
+	if err != nil {
+		return "", false, fmt.Errorf("query primary key: %w", err)
+	}
+	defer rows.Close()
+
+	var columns, types []string
+	unsigned := false
+	for rows.Next() {
+		var column, dataType, columnType string
+		if err := rows.Scan(&column, &dataType, &columnType); err != nil {
+			return "", false, fmt.Errorf("scan primary key: %w", err)
+		}
+		columns = append(columns, column)
+		types = append(types, strings.ToLower(dataType))
+		unsigned = strings.Contains(strings.ToLower(columnType), "unsigned")
+	}
+	if len(columns) != 1 {
+		return "", false, nil
+	}
+	switch types[0] {
+	case "tinyint", "smallint", "mediumint", "int", "bigint":
+		return columns[0], unsigned, nil
+	}
+	return "", false, nil
+}
+
+// columnIndex 返回结果集中指定列的位置，不存在时返回 -1
+func columnIndex(rows *sql.Rows, name string) int {
+	columns, err := rows.Columns()
+	if err != nil {
+		return -1
+	}
+	for i, col := range columns {
+		if strings.EqualFold(col, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// scanSampleRows 读取结果集，keyIndex >= 0 时按该列去重 (seen 记录已出现的键)
+func scanSampleRows(rows *sql.Rows, keyIndex int, seen map[string]bool) ([]string, [][]interface{}, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result [][]interface{}
+	for rows.Next() {
+		raw := make([]sql.RawBytes, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range raw {
+			dest[i] = &raw[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, err
+		}
+
+		if keyIndex >= 0 {
+			key := string(raw[keyIndex])
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+
+		row := make([]interface{}, len(columns))
+		for i, value := range raw {
+			row[i] = displayValue(value)
+		}
+		result = append(result, row)
+	}
+	return columns, result, rows.Err()
+}
+
+// displayValue 将原始列值转换为可 JSON 序列化的值，二进制数据以十六进制表示
+func displayValue(value sql.RawBytes) interface{} {
+	if value == nil {
+		return nil
+	}
+	if !utf8.Valid(value) {
+		return "0x" + hex.EncodeToString(value)
+	}
+	return string(value)
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+// BinaryLogFile 二进制日志文件
+type BinaryLogFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// BinlogStatus 二进制日志与 GTID 状态
+type BinlogStatus struct {
+	Enabled         bool            `json:"enabled"`
+	CurrentFile     string          `json:"current_file,omitempty"`
+	Position        int64           `json:"position,omitempty"`
+	GTIDMode        string          `json:"gtid_mode,omitempty"`
+	ExecutedGTIDSet string          `json:"executed_gtid_set,omitempty"`
+	Files           []BinaryLogFile `json:"files"`
+	TotalSize       int64           `json:"total_size"`
+	TotalSizeHuman  string          `json:"total_size_human"`
+}
+
+// GetBinlogStatus 获取二进制日志状态
+func GetBinlogStatus(ctx context.Context) (*BinlogStatus, error) {
+	return defaultStore.GetBinlogStatus(ctx)
+}
+
+// GetBinlogStatus 获取二进制日志文件列表、当前位点及已执行的 GTID 集合
+func (s *MySQLStore) GetBinlogStatus(ctx context.Context) (*BinlogStatus, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	status := &BinlogStatus{Files: []BinaryLogFile{}}
+
+	var logBin int
+	if err := db.QueryRowContext(ctx, "SELECT @@GLOBAL.log_bin").Scan(&logBin); err != nil {
+		return nil, fmt.Errorf("query log_bin: %w", err)
+	}
+	status.Enabled = logBin == 1
+	status.TotalSizeHuman = formatBytes(0)
+	if !status.Enabled {
+		return status, nil
+	}
+
+	// GTID 相关变量在部分版本/分支上不存在，忽略查询错误
+	var gtidMode, gtidExecuted sql.NullString
+	if err := db.QueryRowContext(ctx, "SELECT @@GLOBAL.gtid_mode, @@GLOBAL.gtid_executed").Scan(&gtidMode, &gtidExecuted); err == nil {
+		status.GTIDMode = gtidMode.String
+		status.ExecutedGTIDSet = gtidExecuted.String
+	}
+
+	// 二进制日志文件列表
+	rows, err := db.QueryContext(ctx, "SHOW BINARY LOGS")
+	if err != nil {
+		return nil, fmt.Errorf("show binary logs: %w", err)
+	}
+	files, err := scanRowMaps(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan binary logs: %w", err)
+	}
+	for _, f := range files {
+		size, _ := strconv.ParseInt(f["File_size"], 10, 64)
+		status.Files = append(status.Files, BinaryLogFile{Name: f["Log_name"], Size: size})
+		status.TotalSize += size
+	}
+	status.TotalSizeHuman = formatBytes(status.TotalSize)
+
+	// 当前位点：8.2+ 使用 SHOW BINARY LOG STATUS，旧版本使用 SHOW MASTER STATUS
+	rows, err = db.QueryContext(ctx, "SHOW BINARY LOG STATUS")
+	if err != nil {
+		rows, err = db.QueryContext(ctx, "SHOW MASTER STATUS")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("show binary log status: %w", err)
+	}
+	current, err := scanRowMaps(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan binary log status: %w", err)
+	}
+	if len(current) > 0 {
+		status.CurrentFile = current[0]["File"]
+		status.Position, _ = strconv.ParseInt(current[0]["Position"], 10, 64)
+		if gtidSet := current[0]["Executed_Gtid_Set"]; gtidSet != "" {
+			status.ExecutedGTIDSet = gtidSet
+		}
+	}
+
+	return status, nil
+}
+
+// scanRowMaps 将结果集扫描为列名到字符串值的映射，适用于列随版本变化的 SHOW 语句
+func scanRowMaps(rows *sql.Rows) ([]map[string]string, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []map[string]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(columns))
+		for i, col := range columns {
+			row[col] = values[i].String
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// formatBytes 将字节数格式化为易读的字符串
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.2f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
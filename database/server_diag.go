@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetInnoDBStatus 获取 SHOW ENGINE INNODB STATUS 的输出
+func GetInnoDBStatus(ctx context.Context) (string, error) {
+	return defaultStore.GetInnoDBStatus(ctx)
+}
+
+// GetInnoDBStatus 执行 SHOW ENGINE INNODB STATUS，需要 PROCESS 权限
+func (s *MySQLStore) GetInnoDBStatus(ctx context.Context) (string, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return "", fmt.Errorf("database not initialized")
+	}
+
+	var engine, name, status string
+	if err := db.QueryRowContext(ctx, "SHOW ENGINE INNODB STATUS").Scan(&engine, &name, &status); err != nil {
+		return "", fmt.Errorf("show engine innodb status: %w", err)
+	}
+	return status, nil
+}
+
+// GetGlobalVariables 获取全部全局变量
+func GetGlobalVariables(ctx context.Context) (map[string]string, error) {
+	return defaultStore.GetGlobalVariables(ctx)
+}
+
+// GetGlobalVariables 执行 SHOW GLOBAL VARIABLES
+func (s *MySQLStore) GetGlobalVariables(ctx context.Context) (map[string]string, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := db.QueryContext(ctx, "SHOW GLOBAL VARIABLES")
+	if err != nil {
+		return nil, fmt.Errorf("show global variables: %w", err)
+	}
+	defer rows.Close()
+	variables := make(map[string]string)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("scan variable: %w", err)
+		}
+		variables[name] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("show global variables: %w", err)
+	}
+	return variables, nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// 数据库服务器分支
+const (
+	FlavorMySQL   = "MySQL"
+	FlavorMariaDB = "MariaDB"
+	FlavorPercona = "Percona Server"
+	FlavorTiDB    = "TiDB"
+)
+
+// ServerVersion 数据库服务器版本
+type ServerVersion struct {
+	Version string `json:"version"`
+	Comment string `json:"comment"`
+	Flavor  string `json:"flavor"`
+}
+
+// GetServerVersion 获取数据库服务器版本
+func GetServerVersion(ctx context.Context) (*ServerVersion, error) {
+	return defaultStore.GetServerVersion(ctx)
+}
+
+// GetServerVersion 查询 VERSION() 和 @@version_comment 并据此判断服务器分支
+func (s *MySQLStore) GetServerVersion(ctx context.Context) (*ServerVersion, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	var v ServerVersion
+	if err := db.QueryRowContext(ctx, "SELECT VERSION(), @@version_comment").Scan(&v.Version, &v.Comment); err != nil {
+		return nil, fmt.Errorf("query server version: %w", err)
+	}
+	v.Flavor = serverFlavor(v.Version, v.Comment)
+	return &v, nil
+}
+
+// serverFlavor 根据版本号和版本说明判断服务器分支，无法识别时视为 MySQL
+func serverFlavor(version, comment string) string {
+	version = strings.ToLower(version)
+	comment = strings.ToLower(comment)
+	switch {
+	case strings.Contains(version, "mariadb") || strings.Contains(comment, "mariadb"):
+		return FlavorMariaDB
+	case strings.Contains(version, "tidb"):
+		return FlavorTiDB
+	case strings.Contains(comment, "percona"):
+		return FlavorPercona
+	}
+	return FlavorMySQL
+}
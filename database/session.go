@@ -0,0 +1,229 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrSessionNotFound 会话不存在或已结束
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionTransaction 会话当前的 InnoDB 事务 (INNODB_TRX)
+type SessionTransaction struct {
+	ID             string    `json:"id"`
+	State          string    `json:"state"`
+	Started        time.Time `json:"started"`
+	Seconds        int64     `json:"seconds"` // 事务已持续的秒数
+	IsolationLevel string    `json:"isolation_level"`
+	OperationState string    `json:"operation_state,omitempty"`
+	RowsLocked     int64     `json:"rows_locked"`
+	RowsModified   int64     `json:"rows_modified"`
+	TablesLocked   int64     `json:"tables_locked"`
+	Query          string    `json:"query,omitempty"` // 事务空闲时为空
+}
+
+// SessionLock 会话持有或等待的锁
+type SessionLock struct {
+	LockType string `json:"lock_type"` // row 或 metadata，与 LockWait 相同
+	Object   string `json:"object"`    // schema.table
+	Index    string `json:"index,omitempty"`
+	Mode     string `json:"mode"`
+	Status   string `json:"status"` // GRANTED/WAITING (行锁) 或 GRANTED/PENDING (元数据锁)
+	Data     string `json:"data,omitempty"`
+}
+
+// SessionVariable 会话级变量，Modified 表示与全局值不同
+type SessionVariable struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Global   string `json:"global"`
+	Modified bool   `json:"modified"`
+}
+
+// SessionDetail 单个会话的完整上下文，用于决定是否终止该会话
+// 锁和会话变量来自 performance_schema，不可用时对应的 Error 字段给出原因
+type SessionDetail struct {
+	Process        Process             `json:"process"`
+	Transaction    *SessionTransaction `json:"transaction"` // 没有进行中的事务时为 nil
+	Locks          []SessionLock       `json:"locks"`
+	LocksError     string              `json:"locks_error,omitempty"`
+	Variables      []SessionVariable   `json:"variables"`
+	VariablesError string              `json:"variables_error,omitempty"`
+	Blocking       []LockWait          `json:"blocking"`   // 该会话阻塞的其他会话
+	BlockedBy      []LockWait          `json:"blocked_by"` // 阻塞该会话的其他会话
+}
+
+// GetSessionDetail 获取会话详情
+func GetSessionDetail(ctx context.Context, id int64) (*SessionDetail, error) {
+	return defaultStore.GetSessionDetail(ctx, id)
+}
+
+// GetSessionDetail 汇总会话的进程信息、当前事务、持有的锁和会话变量
+// 行锁读取 performance_schema.data_locks (8.0+)，元数据锁读取 performance_schema.metadata_locks，
+// 会话变量读取 performance_schema.variables_by_thread (5.7+)
+func (s *MySQLStore) GetSessionDetail(ctx context.Context, id int64) (*SessionDetail, error) {
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	detail := &SessionDetail{Locks: []SessionLock{}, Variables: []SessionVariable{}, Blocking: []LockWait{}, BlockedBy: []LockWait{}}
+	var dbName, state, info sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT ID, USER, HOST, DB, COMMAND, TIME, STATE, INFO
+		FROM information_schema.PROCESSLIST
+		WHERE ID = ?`, id).Scan(&detail.Process.ID, &detail.Process.User, &detail.Process.Host, &dbName,
+		&detail.Process.Command, &detail.Process.Time, &state, &info)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query session: %w", err)
+	}
+	detail.Process.DB, detail.Process.State, detail.Process.Info = dbName.String, state.String, info.String
+
+	trx, err := querySessionTransaction(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	detail.Transaction = trx
+
+	if locks, err := querySessionLocks(ctx, db, id); err != nil {
+		detail.LocksError = err.Error()
+	} else {
+		detail.Locks = locks
+	}
+
+	if variables, err := s.querySessionVariables(ctx, db, id); err != nil {
+		detail.VariablesError = err.Error()
+	} else {
+		detail.Variables = variables
+	}
+
+	if waits, err := s.GetLockWaits(ctx); err == nil {
+		for _, w := range waits {
+			if w.BlockingPID == id {
+				detail.Blocking = append(detail.Blocking, w)
+			}
+			if w.WaitingPID == id {
+				detail.BlockedBy = append(detail.BlockedBy, w)
+			}
+		}
+	}
+	return detail, nil
+}
+
+// querySessionTransaction 读取会话当前的事务，没有事务时返回 nil
+func querySessionTransaction(ctx context.Context, db *sql.DB, id int64) (*SessionTransaction, error) {
+	var trx SessionTransaction
+	var operationState, query sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT trx_id, trx_state, trx_started, TIMESTAMPDIFF(SECOND, trx_started, NOW()),
+		       trx_isolation_level, trx_operation_state, trx_rows_locked, trx_rows_modified, trx_tables_locked, trx_query
+		FROM information_schema.INNODB_TRX
+		WHERE trx_mysql_thread_id = ?`, id).Scan(&trx.ID, &trx.State, &trx.Started, &trx.Seconds,
+		&trx.IsolationLevel, &operationState, &trx.RowsLocked, &trx.RowsModified, &trx.TablesLocked, &query)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query session transaction: %w", err)
+	}
+	trx.OperationState, trx.Query = operationState.String, query.String
+	return &trx, nil
+}
+
+// querySessionLocks 读取会话的行锁和元数据锁，元数据锁表不可用时只返回行锁
+func querySessionLocks(ctx context.Context, db *sql.DB, id int64) ([]SessionLock, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT CONCAT(l.OBJECT_SCHEMA, '.', l.OBJECT_NAME), COALESCE(l.INDEX_NAME, ''),
+		       l.LOCK_MODE, l.LOCK_STATUS, COALESCE(l.LOCK_DATA, '')
+		FROM performance_schema.data_locks l
+		JOIN performance_schema.threads t ON t.THREAD_ID = l.THREAD_ID
+		WHERE t.PROCESSLIST_ID = ? AND l.LOCK_TYPE = 'RECORD'
+		ORDER BY l.OBJECT_SCHEMA, l.OBJECT_NAME, l.INDEX_NAME`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query data locks: %w", err)
+	}
+	locks, err := scanSessionLocks(rows, LockTypeRow)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT CONCAT(m.OBJECT_SCHEMA, '.', m.OBJECT_NAME), '', m.LOCK_TYPE, m.LOCK_STATUS, m.LOCK_DURATION
+		FROM performance_schema.metadata_locks m
+		JOIN performance_schema.threads t ON t.THREAD_ID = m.OWNER_THREAD_ID
+		WHERE t.PROCESSLIST_ID = ? AND m.OBJECT_TYPE = 'TABLE'
+		ORDER BY m.OBJECT_SCHEMA, m.OBJECT_NAME`, id)
+	if err != nil {
+		return locks, nil
+	}
+	metadataLocks, err := scanSessionLocks(rows, LockTypeMetadata)
+	if err != nil {
+		return nil, err
+	}
+	return append(locks, metadataLocks...), nil
+}
+
+// scanSessionLocks 读取锁查询的结果并关闭 rows
+func scanSessionLocks(rows *sql.Rows, lockType string) ([]SessionLock, error) {
+	defer rows.Close()
+	locks := []SessionLock{}
+	for rows.Next() {
+		l := SessionLock{LockType: lockType}
+		if err := rows.Scan(&l.Object, &l.Index, &l.Mode, &l.Status, &l.Data); err != nil {
+			return nil, fmt.Errorf("scan session lock: %w", err)
+		}
+		locks = append(locks, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query session locks: %w", err)
+	}
+	return locks, nil
+}
+
+// querySessionVariables 读取会话变量并与全局值比较，与全局值不同的变量排在前面
+func (s *MySQLStore) querySessionVariables(ctx context.Context, db *sql.DB, id int64) ([]SessionVariable, error) {
+	// 取不到全局变量时只展示会话值
+	globals, _ := s.GetGlobalVariables(ctx)
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT v.VARIABLE_NAME, COALESCE(v.VARIABLE_VALUE, '')
+		FROM performance_schema.variables_by_thread v
+		JOIN performance_schema.threads t ON t.THREAD_ID = v.THREAD_ID
+		WHERE t.PROCESSLIST_ID = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query session variables: %w", err)
+	}
+	defer rows.Close()
+
+	variables := []SessionVariable{}
+	for rows.Next() {
+		var v SessionVariable
+		if err := rows.Scan(&v.Name, &v.Value); err != nil {
+			return nil, fmt.Errorf("scan session variable: %w", err)
+		}
+		if global, ok := globals[v.Name]; ok {
+			v.Global = global
+			v.Modified = global != v.Value
+		}
+		variables = append(variables, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query session variables: %w", err)
+	}
+	sort.SliceStable(variables, func(i, j int) bool {
+		if variables[i].Modified != variables[j].Modified {
+			return variables[i].Modified
+		}
+		return variables[i].Name < variables[j].Name
+	})
+	return variables, nil
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// 分享快照类型
+const (
+	ShareKindTable = "table"
+	ShareKindDiff  = "diff"
+)
+
+// Share 只读分享快照，内容在创建时生成，之后不再随数据库变化
+type Share struct {
+	Token     string      `json:"token"`
+	Kind      string      `json:"kind"`
+	Title     string      `json:"title"`
+	CreatedAt time.Time   `json:"created_at"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	Data      interface{} `json:"-"` // 表结构详情或差异报告
+}
+
+// ShareManager 分享快照管理器
+type ShareManager struct {
+	mu     sync.Mutex
+	shares map[string]*Share
+	config *config.ShareConfig
+}
+
+var (
+	shareManager *ShareManager
+	shareOnce    sync.Once
+)
+
+// GetShareManager 获取分享快照管理器实例
+func GetShareManager() *ShareManager {
+	shareOnce.Do(func() {
+		shareManager = &ShareManager{
+			shares: make(map[string]*Share),
+			config: config.GetShareConfig(),
+		}
+	})
+	return shareManager
+}
+
+// Create 保存快照并返回分享令牌，ttl 为 0 时使用默认有效期
+func (m *ShareManager) Create(kind, title string, data interface{}, ttl time.Duration) (*Share, error) {
+	if ttl == 0 {
+		ttl = m.config.DefaultTTL
+	}
+	if ttl < 0 || ttl > m.config.MaxTTL {
+		return nil, fmt.Errorf("ttl must be between 0 and %v", m.config.MaxTTL)
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("generate token: %w", err)
+	}
+	now := time.Now()
+	share := &Share{
+		Token:     hex.EncodeToString(buf),
+		Kind:      kind,
+		Title:     title,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		Data:      data,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeLocked(now)
+	m.shares[share.Token] = share
+
+	GetDatabaseLogger().Info(fmt.Sprintf("已创建分享链接: %s", title), fmt.Sprintf("有效期至 %s", config.FormatDisplayTime(share.ExpiresAt)))
+	return share, nil
+}
+
+// Get 获取未过期的快照
+func (m *ShareManager) Get(token string) (*Share, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeLocked(time.Now())
+	share, ok := m.shares[token]
+	return share, ok
+}
+
+// Revoke 提前撤销分享
+func (m *ShareManager) Revoke(token string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.shares[token]; !ok {
+		return false
+	}
+	delete(m.shares, token)
+	return true
+}
+
+// purgeLocked 删除已过期的快照，调用方需持有锁
+func (m *ShareManager) purgeLocked(now time.Time) {
+	for token, share := range m.shares {
+		if !now.Before(share.ExpiresAt) {
+			delete(m.shares, token)
+		}
+	}
+}
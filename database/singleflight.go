@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// flightCall 一次进行中的调用
+type flightCall struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+// flightGroup 合并相同键的并发调用，使同时到达的相同请求只访问一次数据库
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// Do 执行 fn；若相同 key 的调用正在进行，则等待并共享其结果，等待期间 ctx 结束时提前返回
+// fn 在不继承 ctx 取消和截止时间的 context 上执行，发起请求的客户端断开或超时不会使其他等待方失败，超时由 fn 自行设置
+// fn panic 时等待方收到错误，panic 在发起者中重新抛出
+func (g *flightGroup) Do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.val, c.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c := &flightCall{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		r := recover()
+		if r != nil {
+			c.val, c.err = nil, fmt.Errorf("shared query panicked: %v", r)
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	c.val, c.err = fn(detachedContext{parent: ctx})
+	return c.val, c.err
+}
+
+// detachedContext 保留父 context 的值，但不继承其取消和截止时间
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (d detachedContext) Value(key interface{}) interface{} { return d.parent.Value(key) }
+
+// flightKey 由查询名称和参数构造合并键
+func flightKey(name string, args ...string) string {
+	return name + "\x00" + strings.Join(args, "\x00")
+}
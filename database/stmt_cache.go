@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// stmtCache 热点 information_schema 查询的预处理语句缓存，按连接配置和语句文本区分
+// 语句绑定在创建它的连接池上，连接池被替换 (重连、故障转移) 后整体失效
+type stmtCache struct {
+	mu    sync.Mutex
+	owner *sql.DB
+	stmts map[string]*sql.Stmt
+}
+
+var hotStmts = &stmtCache{stmts: make(map[string]*sql.Stmt)}
+
+// get 获取或创建预处理语句
+func (c *stmtCache) get(ctx context.Context, db *sql.DB, profile, query string) (*sql.Stmt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.owner != db {
+		c.resetLocked()
+		c.owner = db
+	}
+	key := profile + "\x00" + query
+	if stmt, ok := c.stmts[key]; ok {
+		return stmt, nil
+	}
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.stmts[key] = stmt
+	return stmt, nil
+}
+
+// resetLocked 关闭并清空所有语句，调用方需持有 c.mu
+func (c *stmtCache) resetLocked() {
+	for key, stmt := range c.stmts {
+		stmt.Close()
+		delete(c.stmts, key)
+	}
+	c.owner = nil
+}
+
+// queryHot 执行热点查询，启用语句缓存时使用缓存的预处理语句，预处理失败时退回普通查询
+func queryHot(ctx context.Context, db *sql.DB, query string, args ...interface{}) (*sql.Rows, error) {
+	if !config.GetDBConfig().StmtCache {
+		return db.QueryContext(ctx, query, args...)
+	}
+	stmt, err := hotStmts.get(ctx, db, DefaultProfile, query)
+	if err != nil {
+		return db.QueryContext(ctx, query, args...)
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// warmPool 预先建立 n 个连接并放回连接池，减少连接后首批请求的建连延迟
+// 放回后保留的空闲连接数受连接池 MaxIdleConns 限制
+func warmPool(db *sql.DB, n int) {
+	if n <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conns := make([]*sql.Conn, 0, n)
+	for i := 0; i < n; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			GetDatabaseLogger().Component(ComponentReconnector).Debug("连接池预热失败", err.Error())
+			break
+		}
+		conns = append(conns, conn)
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
@@ -0,0 +1,18 @@
+package database
+
+import (
+	"github.com/furutachiKurea/block-checker/storage"
+)
+
+// persistentStorage 持久化存储，为 nil 时阻塞事件只保存在内存中且不采集诊断包
+var persistentStorage storage.Storage
+
+// SetStorage 设置持久化存储，需在首次调用 GetBlockScanner 和 GetDiagnostics 之前设置
+func SetStorage(s storage.Storage) {
+	persistentStorage = s
+}
+
+// PersistentStorage 获取持久化存储，未配置时为 nil
+func PersistentStorage() storage.Storage {
+	return persistentStorage
+}
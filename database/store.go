@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// Store 数据库访问接口，处理器通过该接口访问数据库以便替换实现
+type Store interface {
+	CheckStatus(ctx context.Context) *DBStatus
+	IsReconnecting() bool
+	GetDatabases(ctx context.Context) ([]DatabaseInfo, error)
+	GetTables(ctx context.Context, databaseName string) ([]TableInfo, error)
+	GetTableDetail(ctx context.Context, databaseName, tableName string) (*TableDetail, error)
+	GetUsers(ctx context.Context) ([]UserAccount, error)
+	GetGrants(ctx context.Context, databaseName, tableName string) ([]ObjectGrant, error)
+	GetBinlogStatus(ctx context.Context) (*BinlogStatus, error)
+	GetTableStats(ctx context.Context) (map[string]TableStat, error)
+	CountBlockedSessions(ctx context.Context) (int, error)
+	GetReplicaLag(ctx context.Context) (*int64, error)
+	SampleTable(ctx context.Context, databaseName, tableName string, n int, seed int64) (*TableSample, error)
+	ExportDDL(ctx context.Context, databaseName string) (string, error)
+	CaptureWaits(ctx context.Context, duration time.Duration) (*WaitCapture, error)
+	GetNamedLocks(ctx context.Context) ([]NamedLock, error)
+	GetGlobalStatus(ctx context.Context, names []string) (map[string]int64, error)
+	GetThreadUsage(ctx context.Context) (*ThreadUsage, error)
+	GetEngineReport(ctx context.Context) (*EngineReport, error)
+	FindValue(ctx context.Context, databaseName string, opts FindValueOptions) (*FindValueResult, error)
+	GetForeignKeys(ctx context.Context, databaseName string) (map[string][]ForeignKey, error)
+	GetServerVersion(ctx context.Context) (*ServerVersion, error)
+	GetComments(ctx context.Context, databaseName string) ([]CommentEntry, error)
+	GetProcessList(ctx context.Context) ([]Process, error)
+	GetLockWaits(ctx context.Context) ([]LockWait, error)
+	GetInnoDBStatus(ctx context.Context) (string, error)
+	GetGlobalVariables(ctx context.Context) (map[string]string, error)
+	GetSessionDetail(ctx context.Context, id int64) (*SessionDetail, error)
+}
+
+// MySQLStore 基于全局 MySQL 连接的 Store 实现
+type MySQLStore struct {
+	queryTimeout time.Duration
+	flight       flightGroup
+	metadataSem  chan struct{} // 限制并发元数据查询，为 nil 时不限制
+}
+
+var defaultStore Store = NewMySQLStore()
+
+// NewMySQLStore 创建 MySQL 存储
+func NewMySQLStore() *MySQLStore {
+	dbConfig := config.GetDBConfig()
+	s := &MySQLStore{
+		queryTimeout: dbConfig.QueryTimeout,
+	}
+	if dbConfig.MetadataConcurrency > 0 {
+		s.metadataSem = make(chan struct{}, dbConfig.MetadataConcurrency)
+	}
+	return s
+}
+
+// DefaultStore 获取默认的 Store 实例
+func DefaultStore() Store {
+	return defaultStore
+}
+
+// IsReconnecting 检查重连器是否正在重连
+func (s *MySQLStore) IsReconnecting() bool {
+	return GetReconnector().IsReconnecting()
+}
+
+// withQueryTimeout 为查询附加语句超时
+func (s *MySQLStore) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.queryTimeout)
+}
+
+// acquireMetadataSlot 获取元数据查询槽位，返回释放函数
+func (s *MySQLStore) acquireMetadataSlot(ctx context.Context) (func(), error) {
+	if s.metadataSem == nil {
+		return func() {}, nil
+	}
+	select {
+	case s.metadataSem <- struct{}{}:
+		return func() { <-s.metadataSem }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("wait for metadata query slot: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,261 @@
+// Package storetest 提供测试用的 database.Store 实现，仅供测试代码导入
+package storetest
+
+import (
+	"context"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// 编译期检查 MockStore 实现了 database.Store
+var _ database.Store = (*MockStore)(nil)
+
+// MockStore 可替换行为的 Store 实现，用于在没有真实数据库时测试处理器
+// 未设置的函数字段返回零值
+type MockStore struct {
+	CheckStatusFunc     func(ctx context.Context) *database.DBStatus
+	IsReconnectingFunc  func() bool
+	GetDatabasesFunc    func(ctx context.Context) ([]database.DatabaseInfo, error)
+	GetTablesFunc       func(ctx context.Context, databaseName string) ([]database.TableInfo, error)
+	GetTableDetailFunc  func(ctx context.Context, databaseName, tableName string) (*database.TableDetail, error)
+	GetUsersFunc        func(ctx context.Context) ([]database.UserAccount, error)
+	GetGrantsFunc       func(ctx context.Context, databaseName, tableName string) ([]database.ObjectGrant, error)
+	GetBinlogStatusFunc func(ctx context.Context) (*database.BinlogStatus, error)
+	GetTableStatsFunc   func(ctx context.Context) (map[string]database.TableStat, error)
+
+	CountBlockedSessionsFunc func(ctx context.Context) (int, error)
+	GetReplicaLagFunc        func(ctx context.Context) (*int64, error)
+	SampleTableFunc          func(ctx context.Context, databaseName, tableName string, n int, seed int64) (*database.TableSample, error)
+	ExportDDLFunc            func(ctx context.Context, databaseName string) (string, error)
+	CaptureWaitsFunc         func(ctx context.Context, duration time.Duration) (*database.WaitCapture, error)
+	GetNamedLocksFunc        func(ctx context.Context) ([]database.NamedLock, error)
+	GetGlobalStatusFunc      func(ctx context.Context, names []string) (map[string]int64, error)
+	GetThreadUsageFunc       func(ctx context.Context) (*database.ThreadUsage, error)
+	GetEngineReportFunc      func(ctx context.Context) (*database.EngineReport, error)
+	FindValueFunc            func(ctx context.Context, databaseName string, opts database.FindValueOptions) (*database.FindValueResult, error)
+	GetForeignKeysFunc       func(ctx context.Context, databaseName string) (map[string][]database.ForeignKey, error)
+	GetServerVersionFunc     func(ctx context.Context) (*database.ServerVersion, error)
+	GetCommentsFunc          func(ctx context.Context, databaseName string) ([]database.CommentEntry, error)
+	GetProcessListFunc       func(ctx context.Context) ([]database.Process, error)
+	GetLockWaitsFunc         func(ctx context.Context) ([]database.LockWait, error)
+	GetInnoDBStatusFunc      func(ctx context.Context) (string, error)
+	GetGlobalVariablesFunc   func(ctx context.Context) (map[string]string, error)
+	GetSessionDetailFunc     func(ctx context.Context, id int64) (*database.SessionDetail, error)
+}
+
+// CheckStatus 检查数据库状态
+func (m *MockStore) CheckStatus(ctx context.Context) *database.DBStatus {
+	if m.CheckStatusFunc == nil {
+		return &database.DBStatus{Status: "OK"}
+	}
+	return m.CheckStatusFunc(ctx)
+}
+
+// IsReconnecting 检查是否正在重连
+func (m *MockStore) IsReconnecting() bool {
+	if m.IsReconnectingFunc == nil {
+		return false
+	}
+	return m.IsReconnectingFunc()
+}
+
+// GetDatabases 获取数据库列表
+func (m *MockStore) GetDatabases(ctx context.Context) ([]database.DatabaseInfo, error) {
+	if m.GetDatabasesFunc == nil {
+		return nil, nil
+	}
+	return m.GetDatabasesFunc(ctx)
+}
+
+// GetTables 获取指定数据库的表列表
+func (m *MockStore) GetTables(ctx context.Context, databaseName string) ([]database.TableInfo, error) {
+	if m.GetTablesFunc == nil {
+		return nil, nil
+	}
+	return m.GetTablesFunc(ctx, databaseName)
+}
+
+// GetTableDetail 获取表结构详细信息
+func (m *MockStore) GetTableDetail(ctx context.Context, databaseName, tableName string) (*database.TableDetail, error) {
+	if m.GetTableDetailFunc == nil {
+		return &database.TableDetail{}, nil
+	}
+	return m.GetTableDetailFunc(ctx, databaseName, tableName)
+}
+
+// GetUsers 获取账号及权限列表
+func (m *MockStore) GetUsers(ctx context.Context) ([]database.UserAccount, error) {
+	if m.GetUsersFunc == nil {
+		return nil, nil
+	}
+	return m.GetUsersFunc(ctx)
+}
+
+// GetGrants 获取可访问指定数据库对象的账号和权限
+func (m *MockStore) GetGrants(ctx context.Context, databaseName, tableName string) ([]database.ObjectGrant, error) {
+	if m.GetGrantsFunc == nil {
+		return nil, nil
+	}
+	return m.GetGrantsFunc(ctx, databaseName, tableName)
+}
+
+// GetBinlogStatus 获取二进制日志状态
+func (m *MockStore) GetBinlogStatus(ctx context.Context) (*database.BinlogStatus, error) {
+	if m.GetBinlogStatusFunc == nil {
+		return &database.BinlogStatus{Files: []database.BinaryLogFile{}}, nil
+	}
+	return m.GetBinlogStatusFunc(ctx)
+}
+
+// GetTableStats 获取所有用户表的行数与空间占用
+func (m *MockStore) GetTableStats(ctx context.Context) (map[string]database.TableStat, error) {
+	if m.GetTableStatsFunc == nil {
+		return map[string]database.TableStat{}, nil
+	}
+	return m.GetTableStatsFunc(ctx)
+}
+
+// CountBlockedSessions 统计正在等待锁的会话数
+func (m *MockStore) CountBlockedSessions(ctx context.Context) (int, error) {
+	if m.CountBlockedSessionsFunc == nil {
+		return 0, nil
+	}
+	return m.CountBlockedSessionsFunc(ctx)
+}
+
+// GetReplicaLag 获取复制延迟 (秒)
+func (m *MockStore) GetReplicaLag(ctx context.Context) (*int64, error) {
+	if m.GetReplicaLagFunc == nil {
+		return nil, nil
+	}
+	return m.GetReplicaLagFunc(ctx)
+}
+
+// SampleTable 对表做可复现的随机采样
+func (m *MockStore) SampleTable(ctx context.Context, databaseName, tableName string, n int, seed int64) (*database.TableSample, error) {
+	if m.SampleTableFunc == nil {
+		return &database.TableSample{Database: databaseName, Table: tableName, Seed: seed, Rows: [][]interface{}{}}, nil
+	}
+	return m.SampleTableFunc(ctx, databaseName, tableName, n, seed)
+}
+
+// ExportDDL 导出数据库中所有表的建表语句
+func (m *MockStore) ExportDDL(ctx context.Context, databaseName string) (string, error) {
+	if m.ExportDDLFunc == nil {
+		return "", nil
+	}
+	return m.ExportDDLFunc(ctx, databaseName)
+}
+
+// CaptureWaits 采集一段时间内的等待事件
+func (m *MockStore) CaptureWaits(ctx context.Context, duration time.Duration) (*database.WaitCapture, error) {
+	if m.CaptureWaitsFunc == nil {
+		return &database.WaitCapture{}, nil
+	}
+	return m.CaptureWaitsFunc(ctx, duration)
+}
+
+// GetNamedLocks 获取当前的命名锁
+func (m *MockStore) GetNamedLocks(ctx context.Context) ([]database.NamedLock, error) {
+	if m.GetNamedLocksFunc == nil {
+		return nil, nil
+	}
+	return m.GetNamedLocksFunc(ctx)
+}
+
+// GetGlobalStatus 获取指定的全局状态计数器
+func (m *MockStore) GetGlobalStatus(ctx context.Context, names []string) (map[string]int64, error) {
+	if m.GetGlobalStatusFunc == nil {
+		return map[string]int64{}, nil
+	}
+	return m.GetGlobalStatusFunc(ctx, names)
+}
+
+// GetThreadUsage 获取当前的连接数与活跃线程数
+func (m *MockStore) GetThreadUsage(ctx context.Context) (*database.ThreadUsage, error) {
+	if m.GetThreadUsageFunc == nil {
+		return &database.ThreadUsage{}, nil
+	}
+	return m.GetThreadUsageFunc(ctx)
+}
+
+// GetEngineReport 获取存储引擎分布报告
+func (m *MockStore) GetEngineReport(ctx context.Context) (*database.EngineReport, error) {
+	if m.GetEngineReportFunc == nil {
+		return &database.EngineReport{}, nil
+	}
+	return m.GetEngineReportFunc(ctx)
+}
+
+// FindValue 在数据库的候选列中查找值
+func (m *MockStore) FindValue(ctx context.Context, databaseName string, opts database.FindValueOptions) (*database.FindValueResult, error) {
+	if m.FindValueFunc == nil {
+		return &database.FindValueResult{}, nil
+	}
+	return m.FindValueFunc(ctx, databaseName, opts)
+}
+
+// GetForeignKeys 获取数据库中全部表的外键
+func (m *MockStore) GetForeignKeys(ctx context.Context, databaseName string) (map[string][]database.ForeignKey, error) {
+	if m.GetForeignKeysFunc == nil {
+		return map[string][]database.ForeignKey{}, nil
+	}
+	return m.GetForeignKeysFunc(ctx, databaseName)
+}
+
+// GetServerVersion 获取数据库服务器版本
+func (m *MockStore) GetServerVersion(ctx context.Context) (*database.ServerVersion, error) {
+	if m.GetServerVersionFunc == nil {
+		return &database.ServerVersion{Version: "8.0.0", Flavor: database.FlavorMySQL}, nil
+	}
+	return m.GetServerVersionFunc(ctx)
+}
+
+// GetComments 获取数据库中全部表和列的注释
+func (m *MockStore) GetComments(ctx context.Context, databaseName string) ([]database.CommentEntry, error) {
+	if m.GetCommentsFunc == nil {
+		return []database.CommentEntry{}, nil
+	}
+	return m.GetCommentsFunc(ctx, databaseName)
+}
+
+// GetProcessList 获取当前的进程列表
+func (m *MockStore) GetProcessList(ctx context.Context) ([]database.Process, error) {
+	if m.GetProcessListFunc == nil {
+		return []database.Process{}, nil
+	}
+	return m.GetProcessListFunc(ctx)
+}
+
+// GetLockWaits 获取当前的锁等待关系
+func (m *MockStore) GetLockWaits(ctx context.Context) ([]database.LockWait, error) {
+	if m.GetLockWaitsFunc == nil {
+		return []database.LockWait{}, nil
+	}
+	return m.GetLockWaitsFunc(ctx)
+}
+
+// GetInnoDBStatus 获取 SHOW ENGINE INNODB STATUS 的输出
+func (m *MockStore) GetInnoDBStatus(ctx context.Context) (string, error) {
+	if m.GetInnoDBStatusFunc == nil {
+		return "", nil
+	}
+	return m.GetInnoDBStatusFunc(ctx)
+}
+
+// GetGlobalVariables 获取全部全局变量
+func (m *MockStore) GetGlobalVariables(ctx context.Context) (map[string]string, error) {
+	if m.GetGlobalVariablesFunc == nil {
+		return map[string]string{}, nil
+	}
+	return m.GetGlobalVariablesFunc(ctx)
+}
+
+// GetSessionDetail 获取会话详情
+func (m *MockStore) GetSessionDetail(ctx context.Context, id int64) (*database.SessionDetail, error) {
+	if m.GetSessionDetailFunc == nil {
+		return nil, database.ErrSessionNotFound
+	}
+	return m.GetSessionDetailFunc(ctx, id)
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// ThreadUsage 连接数与活跃线程数
+type ThreadUsage struct {
+	Timestamp      time.Time `json:"timestamp"`
+	Connected      int64     `json:"threads_connected"`
+	Running        int64     `json:"threads_running"`
+	MaxConnections int64     `json:"max_connections"`
+	UsagePercent   float64   `json:"usage_percent"` // 已用连接占 max_connections 的百分比
+}
+
+// GetThreadUsage 获取当前的连接数与活跃线程数
+func GetThreadUsage(ctx context.Context) (*ThreadUsage, error) {
+	return defaultStore.GetThreadUsage(ctx)
+}
+
+// GetThreadUsage 读取 Threads_connected、Threads_running 与 max_connections
+func (s *MySQLStore) GetThreadUsage(ctx context.Context) (*ThreadUsage, error) {
+	status, err := s.GetGlobalStatus(ctx, []string{"Threads_connected", "Threads_running"})
+	if err != nil {
+		return nil, err
+	}
+
+	db := GetDB()
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	var maxConnections int64
+	if err := db.QueryRowContext(ctx, "SELECT @@GLOBAL.max_connections").Scan(&maxConnections); err != nil {
+		return nil, fmt.Errorf("query max_connections: %w", err)
+	}
+
+	usage := &ThreadUsage{
+		Timestamp:      time.Now(),
+		Connected:      status["Threads_connected"],
+		Running:        status["Threads_running"],
+		MaxConnections: maxConnections,
+	}
+	if maxConnections > 0 {
+		usage.UsagePercent = float64(int64(float64(usage.Connected)*10000/float64(maxConnections)+0.5)) / 100
+	}
+	return usage, nil
+}
+
+// ThreadTracker 定期采样连接数与活跃线程数并保存历史
+type ThreadTracker struct {
+	mu         sync.RWMutex
+	samples    []ThreadUsage
+	maxSamples int
+	store      Store
+	logger     *ComponentLogger
+	stop       chan struct{}
+}
+
+var (
+	threadTracker *ThreadTracker
+	threadOnce    sync.Once
+)
+
+// GetThreadTracker 获取连接数采样器实例
+func GetThreadTracker() *ThreadTracker {
+	threadOnce.Do(func() {
+		threadTracker = &ThreadTracker{
+			samples:    make([]ThreadUsage, 0),
+			maxSamples: config.GetThreadsConfig().MaxSamples,
+			store:      defaultStore,
+			logger:     GetDatabaseLogger().Component(ComponentScheduler),
+		}
+	})
+	return threadTracker
+}
+
+// Start 按指定间隔开始采样，interval 为 0 时不启动
+func (tt *ThreadTracker) Start(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	tt.mu.Lock()
+	if tt.stop != nil {
+		tt.mu.Unlock()
+		return
+	}
+	tt.stop = make(chan struct{})
+	stop := tt.stop
+	tt.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tt.Sample(context.Background())
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				tt.Sample(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop 停止采样
+func (tt *ThreadTracker) Stop() {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if tt.stop != nil {
+		close(tt.stop)
+		tt.stop = nil
+	}
+}
+
+// Sample 立即执行一次采样
+func (tt *ThreadTracker) Sample(ctx context.Context) {
+	usage, err := tt.store.GetThreadUsage(ctx)
+	if err != nil {
+		tt.logger.Debug("连接数采样失败", err.Error())
+		return
+	}
+
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if tt.maxSamples > 0 && len(tt.samples) >= tt.maxSamples {
+		tt.samples = tt.samples[1:]
+	}
+	tt.samples = append(tt.samples, *usage)
+}
+
+// GetSamples 获取采样历史副本
+func (tt *ThreadTracker) GetSamples() []ThreadUsage {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+	samples := make([]ThreadUsage, len(tt.samples))
+	copy(samples, tt.samples)
+	return samples
+}
@@ -0,0 +1,266 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// ServerIdentity 服务器身份与读写角色，用于发现故障切换和 DNS 指向变化
+type ServerIdentity struct {
+	ServerUUID    string    `json:"server_uuid,omitempty"` // MariaDB 没有 server_uuid
+	ServerID      string    `json:"server_id"`
+	Hostname      string    `json:"hostname"`
+	Port          string    `json:"port"`
+	ReadOnly      bool      `json:"read_only"`
+	SuperReadOnly bool      `json:"super_read_only"`
+	CheckedAt     time.Time `json:"checked_at"`
+}
+
+// TopologyChange 一次拓扑变化记录
+type TopologyChange struct {
+	Profile  string         `json:"profile"`
+	Time     time.Time      `json:"time"`
+	Kind     string         `json:"kind"`    // failover: 连接到了另一台服务器；role: 同一服务器的读写角色变化
+	Changes  []string       `json:"changes"` // 发生变化的字段，形如 "hostname: db1 → db2"
+	Previous ServerIdentity `json:"previous"`
+	Current  ServerIdentity `json:"current"`
+}
+
+// 拓扑变化类型
+const (
+	TopologyFailover = "failover"
+	TopologyRole     = "role"
+)
+
+// TopologyWatcher 定期读取各服务器的身份，身份或读写角色变化时记录拓扑变化
+type TopologyWatcher struct {
+	mu         sync.RWMutex
+	identities map[string]ServerIdentity // 按服务器名称索引的最近一次身份
+	changes    []TopologyChange
+	maxChanges int
+	registry   *ProfileRegistry
+	logger     *ComponentLogger
+	stop       chan struct{}
+}
+
+var (
+	topologyWatcher     *TopologyWatcher
+	topologyWatcherOnce sync.Once
+)
+
+// GetTopologyWatcher 获取拓扑变化检测实例
+func GetTopologyWatcher() *TopologyWatcher {
+	topologyWatcherOnce.Do(func() {
+		topologyWatcher = &TopologyWatcher{
+			identities: make(map[string]ServerIdentity),
+			maxChanges: config.GetTopologyConfig().MaxChanges,
+			registry:   GetProfileRegistry(),
+			logger:     GetDatabaseLogger().Component(ComponentScheduler),
+		}
+	})
+	return topologyWatcher
+}
+
+// Start 按指定间隔开始检测，interval 为 0 时不启动
+func (tw *TopologyWatcher) Start(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	tw.mu.Lock()
+	if tw.stop != nil {
+		tw.mu.Unlock()
+		return
+	}
+	tw.stop = make(chan struct{})
+	stop := tw.stop
+	tw.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tw.Check(context.Background())
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				tw.Check(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop 停止检测
+func (tw *TopologyWatcher) Stop() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.stop != nil {
+		close(tw.stop)
+		tw.stop = nil
+	}
+}
+
+// Check 立即读取所有服务器的身份并与上一次比较
+// 读取失败 (如连接中断) 时保留上一次的身份，重连到另一台服务器后仍能发现变化
+func (tw *TopologyWatcher) Check(ctx context.Context) {
+	for _, status := range tw.registry.Statuses() {
+		db, ok := tw.registry.DB(status.Name)
+		if !ok || db == nil {
+			continue
+		}
+		queryCtx, cancel := context.WithTimeout(ctx, profileCheckTimeout)
+		identity, err := queryServerIdentity(queryCtx, db)
+		cancel()
+		if err != nil {
+			tw.logger.Debug("读取服务器身份失败 ("+status.Name+")", err.Error())
+			continue
+		}
+		tw.observe(status.Name, identity)
+	}
+}
+
+// queryServerIdentity 读取服务器身份相关的变量，不存在的变量保持零值
+func queryServerIdentity(ctx context.Context, db *sql.DB) (ServerIdentity, error) {
+	rows, err := db.QueryContext(ctx, "SHOW GLOBAL VARIABLES WHERE Variable_name IN ('server_uuid', 'server_id', 'hostname', 'port', 'read_only', 'super_read_only')")
+	if err != nil {
+		return ServerIdentity{}, fmt.Errorf("query server identity: %w", err)
+	}
+	defer rows.Close()
+
+	identity := ServerIdentity{CheckedAt: time.Now()}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return ServerIdentity{}, fmt.Errorf("scan server identity: %w", err)
+		}
+		switch strings.ToLower(name) {
+		case "server_uuid":
+			identity.ServerUUID = value
+		case "server_id":
+			identity.ServerID = value
+		case "hostname":
+			identity.Hostname = value
+		case "port":
+			identity.Port = value
+		case "read_only":
+			identity.ReadOnly = isOn(value)
+		case "super_read_only":
+			identity.SuperReadOnly = isOn(value)
+		}
+	}
+	return identity, rows.Err()
+}
+
+// isOn 判断布尔型系统变量是否开启
+func isOn(value string) bool {
+	return strings.EqualFold(value, "ON") || value == "1"
+}
+
+// observe 记录服务器的最新身份，与上一次不同时记录拓扑变化并写入醒目的错误日志
+func (tw *TopologyWatcher) observe(name string, identity ServerIdentity) {
+	tw.mu.Lock()
+	prev, seen := tw.identities[name]
+	tw.identities[name] = identity
+	if !seen {
+		tw.mu.Unlock()
+		return
+	}
+	change, changed := diffIdentity(prev, identity)
+	if !changed {
+		tw.mu.Unlock()
+		return
+	}
+	change.Profile = name
+	change.Time = identity.CheckedAt
+	if tw.maxChanges > 0 && len(tw.changes) >= tw.maxChanges {
+		tw.changes = tw.changes[1:]
+	}
+	tw.changes = append(tw.changes, change)
+	tw.mu.Unlock()
+
+	details := strings.Join(change.Changes, "; ")
+	message := fmt.Sprintf("服务器 %s 发生故障切换或 DNS 指向变化", name)
+	if change.Kind == TopologyRole {
+		message = fmt.Sprintf("服务器 %s 的读写角色发生变化", name)
+	}
+	if logger, ok := tw.registry.Logger(name); ok {
+		logger.Error(message, details)
+	}
+	if name == DefaultProfile {
+		GetIncidentRecorder().recordEvent(EventTopologyChanged, message+": "+details, 0)
+	}
+}
+
+// diffIdentity 比较两次读取的身份，server_uuid、server_id、hostname 或 port 变化视为连接到了另一台服务器
+func diffIdentity(prev, next ServerIdentity) (TopologyChange, bool) {
+	change := TopologyChange{Kind: TopologyRole, Previous: prev, Current: next}
+	field := func(name, a, b string) {
+		if a != b {
+			change.Changes = append(change.Changes, fmt.Sprintf("%s: %s → %s", name, a, b))
+		}
+	}
+	field("server_uuid", prev.ServerUUID, next.ServerUUID)
+	field("server_id", prev.ServerID, next.ServerID)
+	field("hostname", prev.Hostname, next.Hostname)
+	field("port", prev.Port, next.Port)
+	if len(change.Changes) > 0 {
+		change.Kind = TopologyFailover
+	}
+	field("read_only", onOff(prev.ReadOnly), onOff(next.ReadOnly))
+	field("super_read_only", onOff(prev.SuperReadOnly), onOff(next.SuperReadOnly))
+	return change, len(change.Changes) > 0
+}
+
+// onOff 布尔型系统变量的显示值
+func onOff(v bool) string {
+	if v {
+		return "ON"
+	}
+	return "OFF"
+}
+
+// Identities 各服务器最近一次读取的身份，按服务器名称索引
+func (tw *TopologyWatcher) Identities() map[string]ServerIdentity {
+	tw.mu.RLock()
+	defer tw.mu.RUnlock()
+	identities := make(map[string]ServerIdentity, len(tw.identities))
+	for name, identity := range tw.identities {
+		identities[name] = identity
+	}
+	return identities
+}
+
+// Changes 拓扑变化记录，按时间倒序；profile 为空时返回所有服务器，since 为零值时不限制时间
+func (tw *TopologyWatcher) Changes(profile string, since time.Time) []TopologyChange {
+	tw.mu.RLock()
+	defer tw.mu.RUnlock()
+	changes := []TopologyChange{}
+	for i := len(tw.changes) - 1; i >= 0; i-- {
+		c := tw.changes[i]
+		if c.Time.Before(since) {
+			break
+		}
+		if profile == "" || c.Profile == profile {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// LastChange 服务器最近一次拓扑变化，没有变化时返回 false
+func (tw *TopologyWatcher) LastChange(profile string) (TopologyChange, bool) {
+	tw.mu.RLock()
+	defer tw.mu.RUnlock()
+	for i := len(tw.changes) - 1; i >= 0; i-- {
+		if tw.changes[i].Profile == profile {
+			return tw.changes[i], true
+		}
+	}
+	return TopologyChange{}, false
+}
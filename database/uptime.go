@@ -0,0 +1,88 @@
+package database
+
+import (
+	"sync"
+	"time"
+)
+
+// UptimeStats 连接可用性统计
+type UptimeStats struct {
+	Healthy      bool          `json:"healthy"`
+	Since        time.Time     `json:"since"`        // 进入当前状态的时间
+	Duration     time.Duration `json:"duration"`     // 处于当前状态的时长
+	StartedAt    time.Time     `json:"started_at"`   // 开始统计的时间
+	Availability float64       `json:"availability"` // 开始统计以来的可用百分比
+}
+
+// UptimeTracker 记录连接在可用与不可用之间的切换
+type UptimeTracker struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	healthy   bool
+	since     time.Time
+	downtime  time.Duration // 已结束的不可用时段累计
+}
+
+var (
+	uptimeTracker     *UptimeTracker
+	uptimeTrackerOnce sync.Once
+)
+
+// GetUptimeTracker 获取可用性统计单例，启动时视为不可用
+func GetUptimeTracker() *UptimeTracker {
+	uptimeTrackerOnce.Do(func() {
+		now := time.Now()
+		uptimeTracker = &UptimeTracker{startedAt: now, since: now}
+	})
+	return uptimeTracker
+}
+
+// markUp 标记连接可用
+func (t *UptimeTracker) markUp() {
+	t.set(true)
+}
+
+// markDown 标记连接不可用
+func (t *UptimeTracker) markDown() {
+	t.set(false)
+}
+
+// set 切换状态，状态未变化时保持原有起始时间
+func (t *UptimeTracker) set(healthy bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.healthy == healthy {
+		return
+	}
+	now := time.Now()
+	if !t.healthy {
+		t.downtime += now.Sub(t.since)
+	}
+	t.healthy = healthy
+	t.since = now
+}
+
+// Stats 返回当前的可用性统计
+func (t *UptimeTracker) Stats() UptimeStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	downtime := t.downtime
+	if !t.healthy {
+		downtime += now.Sub(t.since)
+	}
+	availability := 100.0
+	if total := now.Sub(t.startedAt); total > 0 {
+		availability = 100 * float64(total-downtime) / float64(total)
+	}
+
+	return UptimeStats{
+		Healthy:      t.healthy,
+		Since:        t.since,
+		Duration:     now.Sub(t.since),
+		StartedAt:    t.startedAt,
+		Availability: availability,
+	}
+}
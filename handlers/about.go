@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BuildInfo 构建信息
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+}
+
+// buildInfo 由 main 注入的构建信息
+var buildInfo = BuildInfo{Version: "dev"}
+
+// SetBuildInfo 注入构建信息，未通过 ldflags 注入提交时尝试使用 Go 工具链记录的 VCS 信息
+func SetBuildInfo(info BuildInfo) {
+	if info.Commit == "" || info.BuildDate == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range bi.Settings {
+				switch {
+				case setting.Key == "vcs.revision" && info.Commit == "":
+					info.Commit = setting.Value
+				case setting.Key == "vcs.time" && info.BuildDate == "":
+					info.BuildDate = setting.Value
+				}
+			}
+		}
+	}
+	buildInfo = info
+}
+
+// APIAboutHandler API 版本信息处理器，返回构建信息、运行时长和数据库服务器版本
+// 数据库不可用时仍返回其余信息，并在 mysql_error 中给出原因；配置了备用密码时在 credentials 中给出当前使用的密码
+func APIAboutHandler(c echo.Context) error {
+	result := map[string]interface{}{
+		"version":    buildInfo.Version,
+		"commit":     buildInfo.Commit,
+		"build_date": buildInfo.BuildDate,
+		"go_version": runtime.Version(),
+		"platform":   runtime.GOOS + "/" + runtime.GOARCH,
+		"started_at": processStart,
+		"uptime":     time.Since(processStart).Round(time.Second).String(),
+	}
+
+	if secret := database.GetSecretStatus(); secret.SecondaryConfigured {
+		result["credentials"] = secret
+	}
+
+	server, err := store.GetServerVersion(c.Request().Context())
+	if err != nil {
+		result["mysql_error"] = err.Error()
+	} else {
+		result["mysql"] = server
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/alert"
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// alertEngine 告警规则引擎，未配置任何规则时为 nil
+var alertEngine *alert.Engine
+
+// SetAlertEngine 设置告警规则引擎
+func SetAlertEngine(e *alert.Engine) {
+	alertEngine = e
+}
+
+// APIAlertsHandler 返回当前触发中的告警，可通过 label=key=value (可重复) 按标签筛选
+func APIAlertsHandler(c echo.Context) error {
+	selector, err := database.ParseLabelSelector(labelParams(c))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	alerts := []alert.Alert{}
+	if alertEngine != nil {
+		for _, a := range alertEngine.Active() {
+			if database.MatchLabels(a.Labels, selector) {
+				alerts = append(alerts, a)
+			}
+		}
+	}
+	lifecycle := []alert.AlertState{}
+	events := []alert.AlertEvent{}
+	if alertEngine != nil {
+		for _, s := range alertEngine.Lifecycle() {
+			if database.MatchLabels(s.Labels, selector) {
+				lifecycle = append(lifecycle, s)
+			}
+		}
+		events = alertEngine.Events()
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"enabled":     alertEngine != nil,
+		"alerts":      alerts,
+		"lifecycle":   lifecycle, // 触发中告警的通知、升级和确认状态
+		"events":      events,
+		"maintenance": database.GetMaintenanceManager().Active(database.DefaultProfile),
+	})
+}
+
+// ackRequest 确认告警的请求体
+type ackRequest struct {
+	By      string `json:"by" form:"by"`
+	Comment string `json:"comment" form:"comment"`
+}
+
+// APIAlertAckHandler 确认触发中的告警，:id 为告警指纹
+// 需要操作员令牌或通知中确认链接的签名 (sig 参数)，确认后停止升级通知
+func APIAlertAckHandler(c echo.Context) error {
+	if alertEngine == nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "未启用告警",
+		})
+	}
+	id := c.Param("id")
+	operator := IsOperator(c)
+	if !operator && !alertEngine.VerifyAck(id, c.QueryParam("sig")) {
+		return denyOperator(c, "需要有效的操作员令牌或确认链接")
+	}
+
+	var req ackRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+	}
+	by := strings.TrimSpace(req.By)
+	switch {
+	case len(by) > 64 || len(req.Comment) > 256:
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "by 不能超过 64 字节，comment 不能超过 256 字节",
+		})
+	case by == "" && operator:
+		by = "operator"
+	case by == "":
+		by = "ack-link"
+	}
+
+	state, err := alertEngine.Acknowledge(c.Request().Context(), id, by, strings.TrimSpace(req.Comment))
+	switch {
+	case errors.Is(err, alert.ErrAlertNotFound):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "告警已恢复或不存在",
+		})
+	case errors.Is(err, alert.ErrAlreadyAcknowledged):
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"error": "告警已被 " + state.AckedBy + " 确认",
+			"alert": state,
+		})
+	}
+	return c.JSON(http.StatusOK, state)
+}
+
+// AlertAckPageHandler 通知中确认链接打开的页面，由页面提交确认，避免链接预览直接确认告警
+func AlertAckPageHandler(c echo.Context) error {
+	id, sig := c.Param("id"), c.QueryParam("sig")
+	data := templates.AlertAckData{ID: id, Sig: sig}
+	if alertEngine != nil {
+		for _, s := range alertEngine.Lifecycle() {
+			if s.Fingerprint == id {
+				data.State = s
+			}
+		}
+	}
+	html, err := templates.RenderAlertAck(data)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
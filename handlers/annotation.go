@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// annotationRequest 添加时间段注释的请求体
+// start 为空时为当前时间；end 与 duration 都为空时表示一个时间点
+type annotationRequest struct {
+	Start    string   `json:"start" form:"start"`
+	End      string   `json:"end" form:"end"`
+	Duration string   `json:"duration" form:"duration"`
+	Text     string   `json:"text" form:"text"`
+	Tags     []string `json:"tags" form:"tags"`
+	Profile  string   `json:"profile" form:"profile"` // 为空时作用于所有服务器
+}
+
+// APIAnnotationListHandler API 时间段注释列表处理器
+// since、until 限定时间范围，profile 只返回作用于该服务器的注释，tag 按标签过滤
+func APIAnnotationListHandler(c echo.Context) error {
+	var from, to time.Time
+	for _, p := range []struct {
+		name   string
+		target *time.Time
+	}{{"since", &from}, {"until", &to}} {
+		if v := c.QueryParam(p.name); v != "" {
+			t, err := parseWindowTime(v)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]interface{}{
+					"error": "invalid " + p.name + ": " + err.Error(),
+				})
+			}
+			*p.target = t
+		}
+	}
+
+	annotations := database.GetAnnotations().Between(from, to, c.QueryParam("profile"))
+	if tag := c.QueryParam("tag"); tag != "" {
+		filtered := []database.Annotation{}
+		for _, a := range annotations {
+			for _, t := range a.Tags {
+				if strings.EqualFold(t, tag) {
+					filtered = append(filtered, a)
+					break
+				}
+			}
+		}
+		annotations = filtered
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"annotations": annotations,
+		"count":       len(annotations),
+	})
+}
+
+// APIAnnotationCreateHandler API 添加时间段注释处理器
+func APIAnnotationCreateHandler(c echo.Context) error {
+	var req annotationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+	}
+
+	start := time.Now()
+	if req.Start != "" {
+		t, err := parseWindowTime(req.Start)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid start: " + err.Error(),
+			})
+		}
+		start = t
+	}
+
+	var end time.Time
+	switch {
+	case req.End != "":
+		t, err := parseWindowTime(req.End)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid end: " + err.Error(),
+			})
+		}
+		end = t
+	case req.Duration != "":
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid duration: " + err.Error(),
+			})
+		}
+		end = start.Add(d)
+	}
+
+	annotation, err := database.GetAnnotations().Add(c.Request().Context(), start, end, req.Text, req.Tags, req.Profile)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusCreated, annotation)
+}
+
+// APIAnnotationDeleteHandler API 删除时间段注释处理器
+func APIAnnotationDeleteHandler(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid id",
+		})
+	}
+	if err := database.GetAnnotations().Remove(c.Request().Context(), id); err != nil {
+		if errors.Is(err, database.ErrAnnotationNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// annotationProfile 请求对应的服务器名称，用于选择叠加显示的注释
+func annotationProfile(c echo.Context) string {
+	if profile := c.QueryParam("profile"); profile != "" {
+		return profile
+	}
+	return database.DefaultProfile
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireOperator 操作员权限中间件，要求请求携带正确的操作员令牌
+// 令牌可通过 X-Operator-Token 头、Authorization: Bearer 头或 token 查询参数提供
+func RequireOperator(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if config.GetServerConfig().OperatorToken == "" {
+			return denyOperator(c, "未配置 OPERATOR_TOKEN，操作员功能已禁用")
+		}
+		if !IsOperator(c) {
+			return denyOperator(c, "需要有效的操作员令牌")
+		}
+		return next(c)
+	}
+}
+
+// IsOperator 判断当前请求是否携带有效的操作员令牌
+func IsOperator(c echo.Context) bool {
+	expected := config.GetServerConfig().OperatorToken
+	if expected == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(operatorToken(c)), []byte(expected)) == 1
+}
+
+// operatorToken 从请求中提取操作员令牌
+func operatorToken(c echo.Context) string {
+	if token := c.Request().Header.Get("X-Operator-Token"); token != "" {
+		return token
+	}
+	if auth := c.Request().Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return c.QueryParam("token")
+}
+
+// denyOperator 拒绝非操作员请求，API 路由返回 JSON，页面路由返回错误页
+func denyOperator(c echo.Context, message string) error {
+	if strings.HasPrefix(c.Path(), "/api/") {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": message,
+		})
+	}
+	data := templates.ErrorData{
+		Title:   "权限不足",
+		Message: message,
+	}
+	html, _ := templates.RenderError(data)
+	return c.HTML(http.StatusForbidden, html)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// badgeLabel 徽章左侧的文字
+const badgeLabel = "database"
+
+// badge 徽章内容，字段与 shields.io endpoint 格式一致
+type badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// badgeColors shields.io 颜色名对应的色值
+var badgeColors = map[string]string{
+	"brightgreen": "#4c1",
+	"yellow":      "#dfb317",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+}
+
+// currentBadge 根据连接状态和可用性统计生成徽章内容
+func currentBadge(c echo.Context) badge {
+	status := store.CheckStatus(c.Request().Context())
+	stats := database.GetUptimeTracker().Stats()
+
+	b := badge{SchemaVersion: 1, Label: badgeLabel}
+	switch {
+	case status.Status == "OK":
+		b.Message, b.Color = "up "+formatUptime(stats.Duration), "brightgreen"
+	case database.InMaintenance():
+		b.Message, b.Color = "maintenance", "blue"
+	case status.Status == "Reconnecting":
+		b.Message, b.Color = "reconnecting", "yellow"
+	default:
+		b.Message, b.Color = "down "+formatUptime(stats.Duration), "red"
+	}
+	return b
+}
+
+// APIStatusBadgeHandler 状态徽章 JSON，可直接作为 shields.io endpoint 数据源
+func APIStatusBadgeHandler(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "no-cache, max-age=0")
+	return c.JSON(http.StatusOK, currentBadge(c))
+}
+
+// StatusBadgeSVGHandler 状态徽章 SVG，便于嵌入 Wiki 和 README
+func StatusBadgeSVGHandler(c echo.Context) error {
+	// 禁止缓存，避免图片代理长期展示旧状态
+	c.Response().Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	return c.Blob(http.StatusOK, "image/svg+xml; charset=utf-8", []byte(renderBadgeSVG(currentBadge(c))))
+}
+
+// renderBadgeSVG 按 shields.io flat 风格绘制徽章
+func renderBadgeSVG(b badge) string {
+	labelWidth := textWidth(b.Label) + 10
+	messageWidth := textWidth(b.Message) + 10
+	width := labelWidth + messageWidth
+	label, message := html.EscapeString(b.Label), html.EscapeString(b.Message)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<title>%[4]s: %[5]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[7]d" y="14">%[4]s</text>
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text><text x="%[8]d" y="14">%[5]s</text>
+</g>
+</svg>`, width, labelWidth, messageWidth, label, message, badgeColors[b.Color], labelWidth/2, labelWidth+messageWidth/2)
+}
+
+// textWidth 估算 11px Verdana 文本宽度
+func textWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if r < 0x80 {
+			width += 7
+		} else {
+			width += 11
+		}
+	}
+	return width
+}
+
+// formatUptime 将时长格式化为最多两级单位的简写，如 3d 4h、5m
+func formatUptime(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return "<1m"
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultBlockHistoryLimit 阻塞事件历史默认返回的条数
+const defaultBlockHistoryLimit = 100
+
+// BlockHistoryPageHandler 阻塞事件历史页面处理器，view=compact 时使用紧凑视图
+func BlockHistoryPageHandler(c echo.Context) error {
+	scanner := database.GetBlockScanner()
+	html, err := templates.RenderBlockHistory(templates.BlockHistoryData{
+		Events:  scanner.Events(time.Time{}, defaultBlockHistoryLimit),
+		Active:  scanner.Active(),
+		Compact: compactView(c),
+	})
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
+
+// APIBlockHistoryHandler API 阻塞事件历史处理器，按开始时间倒序返回
+// since 支持 RFC3339 或 datetime-local 格式，limit 默认为 100，为 0 时不限制
+func APIBlockHistoryHandler(c echo.Context) error {
+	var since time.Time
+	if v := c.QueryParam("since"); v != "" {
+		t, err := parseWindowTime(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid since: " + err.Error(),
+			})
+		}
+		since = t
+	}
+	limit := defaultBlockHistoryLimit
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "limit 必须为非负整数",
+			})
+		}
+		limit = n
+	}
+
+	scanner := database.GetBlockScanner()
+	events := scanner.Events(since, limit)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"events": events,
+		"count":  len(events),
+		"active": scanner.Active(),
+	})
+}
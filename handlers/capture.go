@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultCaptureSeconds 未指定时长时的采集秒数
+const defaultCaptureSeconds = 10
+
+// APICaptureHandler API 性能快照处理器
+// 采集 seconds 秒内 performance_schema 的等待和阶段事件，结果作为 JSON 文件下载
+func APICaptureHandler(c echo.Context) error {
+	seconds := defaultCaptureSeconds
+	if v := c.QueryParam("seconds"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || time.Duration(parsed)*time.Second > database.MaxCaptureDuration {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "seconds 必须为 1 到 " + strconv.Itoa(int(database.MaxCaptureDuration/time.Second)) + " 之间的整数",
+			})
+		}
+		seconds = parsed
+	}
+
+	capture, err := store.CaptureWaits(c.Request().Context(), time.Duration(seconds)*time.Second)
+	if err != nil {
+		if errors.Is(err, database.ErrCaptureRunning) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"error": "已有采集正在进行",
+			})
+		}
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	filename := "capture-" + capture.StartedAt.Format("20060102-150405") + ".json"
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.JSONPretty(http.StatusOK, capture, "  ")
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// chaosRequest 注入模拟故障的请求体
+type chaosRequest struct {
+	Kind      string  `json:"kind" form:"kind"`             // drop/latency/error
+	Duration  string  `json:"duration" form:"duration"`     // 持续时间，如 5m
+	Latency   string  `json:"latency" form:"latency"`       // latency 故障增加的延迟，如 500ms
+	ErrorCode string  `json:"error_code" form:"error_code"` // error 故障的错误分类代码，如 NET_003
+	Rate      float64 `json:"rate" form:"rate"`             // 受影响的语句比例 (0-1]，默认 1
+}
+
+// APIChaosHandler API 正在生效的模拟故障和可模拟的错误分类
+func APIChaosHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"faults":      database.GetChaos().Active(),
+		"error_codes": database.ChaosErrorCodes(),
+	})
+}
+
+// APIChaosInjectHandler API 注入模拟故障 (仅操作员，需开启 CHAOS_ENABLED)
+func APIChaosInjectHandler(c echo.Context) error {
+	var req chaosRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid duration: " + req.Duration,
+		})
+	}
+	var latency time.Duration
+	if req.Latency != "" {
+		if latency, err = time.ParseDuration(req.Latency); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid latency: " + req.Latency,
+			})
+		}
+	}
+
+	fault, err := database.GetChaos().Inject(c.Request().Context(), req.Kind, duration, latency, req.ErrorCode, req.Rate)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusCreated, fault)
+}
+
+// APIChaosClearHandler API 结束模拟故障 (仅操作员)，kind 为空时结束所有故障
+func APIChaosClearHandler(c echo.Context) error {
+	cleared := database.GetChaos().Clear(c.QueryParam("kind"))
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"cleared": cleared,
+	})
+}
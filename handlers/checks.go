@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/furutachiKurea/block-checker/checks"
+
+	"github.com/labstack/echo/v4"
+)
+
+// checkRunner 扩展检查运行器，没有任何检查时为 nil
+var checkRunner *checks.Runner
+
+// SetCheckRunner 注入扩展检查运行器
+func SetCheckRunner(r *checks.Runner) {
+	checkRunner = r
+}
+
+// APIChecksHandler API 扩展检查结果处理器，返回各检查最近一次的结果
+func APIChecksHandler(c echo.Context) error {
+	if checkRunner == nil {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"checks": []checks.Result{},
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"checks": checkRunner.Results(),
+	})
+}
+
+// APICheckHistoryHandler API 指定检查最近的结果，按时间倒序
+func APICheckHistoryHandler(c echo.Context) error {
+	if checkRunner == nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "未配置扩展检查",
+		})
+	}
+	history, err := checkRunner.History(c.Param("name"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"check":   c.Param("name"),
+		"history": history,
+	})
+}
+
+// APICheckRunHandler API 立即运行指定检查 (仅操作员)
+func APICheckRunHandler(c echo.Context) error {
+	if checkRunner == nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "未配置扩展检查",
+		})
+	}
+	result, err := checkRunner.RunNow(c.Param("name"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, result)
+}
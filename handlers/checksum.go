@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APIChecksumHandler 主从表数据校验结果处理器，返回最近一次完成的校验和进行中的校验
+func APIChecksumHandler(c echo.Context) error {
+	monitor := database.GetChecksumMonitor()
+	response := map[string]interface{}{
+		"enabled": monitor.Enabled(),
+	}
+	if last, ok := monitor.Last(); ok {
+		response["last"] = last
+		response["mismatches"] = last.Mismatches()
+	}
+	if running, ok := monitor.Running(); ok {
+		response["running"] = running
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// APIChecksumRunHandler 立即开始一次主从表数据校验，校验在后台进行，通过 GET /api/checksum 查看进度
+func APIChecksumRunHandler(c echo.Context) error {
+	monitor := database.GetChecksumMonitor()
+	if !monitor.Enabled() {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "未配置主从数据校验，请设置 CHECKSUM_TABLES 和 CHECKSUM_REPLICA",
+		})
+	}
+	if _, ok := monitor.Running(); ok {
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"error": database.ErrChecksumRunning.Error(),
+		})
+	}
+
+	go func() {
+		if _, err := monitor.Run(context.Background()); err != nil && !errors.Is(err, database.ErrChecksumRunning) {
+			database.GetDatabaseLogger().Component(database.ComponentScheduler).Warn("主从数据校验失败", err.Error())
+		}
+	}()
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message": "checksum started",
+	})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// labelParams 获取 label 查询参数，参数可重复，也可在一个参数中以逗号分隔多个条件
+func labelParams(c echo.Context) []string {
+	var items []string
+	for _, param := range c.QueryParams()["label"] {
+		for _, item := range strings.Split(param, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// selectProfiles 按 label=key=value 筛选服务器
+func selectProfiles(c echo.Context) ([]database.ProfileStatus, error) {
+	selector, err := database.ParseLabelSelector(labelParams(c))
+	if err != nil {
+		return nil, err
+	}
+	return database.FilterProfiles(database.GetProfileRegistry().Statuses(), selector), nil
+}
+
+// DashboardPageHandler 服务器总览页面处理器，支持 label 筛选和 group_by 按标签分组
+func DashboardPageHandler(c echo.Context) error {
+	profiles, err := selectProfiles(c)
+	if err != nil {
+		return c.HTML(http.StatusBadRequest, "标签筛选条件无效: "+err.Error())
+	}
+	groupBy := c.QueryParam("group_by")
+	var groups []database.ProfileGroup
+	switch {
+	case groupBy != "":
+		groups = database.GroupProfiles(profiles, groupBy)
+	case len(profiles) > 0:
+		groups = []database.ProfileGroup{{Profiles: profiles}}
+	}
+	html, err := templates.RenderDashboard(templates.DashboardData{
+		Groups:   groups,
+		GroupBy:  groupBy,
+		Selector: strings.Join(labelParams(c), ","),
+		Interval: config.GetProfilesConfig().CheckInterval.String(),
+	})
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
+
+// APIProfilesHandler API 服务器总览处理器，默认连接在前
+// label=key=value 按标签筛选，group_by 指定标签时额外返回按该标签值的分组
+func APIProfilesHandler(c echo.Context) error {
+	profiles, err := selectProfiles(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	result := map[string]interface{}{
+		"profiles": profiles,
+		"count":    len(profiles),
+	}
+	if groupBy := c.QueryParam("group_by"); groupBy != "" {
+		result["group_by"] = groupBy
+		result["groups"] = database.GroupProfiles(profiles, groupBy)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// APIProfileHandler API 单个服务器状态处理器
+func APIProfileHandler(c echo.Context) error {
+	status, ok := database.GetProfileRegistry().Status(c.Param("name"))
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "profile not found",
+		})
+	}
+	return c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireDB 依赖数据库的路由中间件
+// 重连期间不再执行查询，统一返回 503，Retry-After 取距下一次重连尝试的秒数
+func RequireDB(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !store.IsReconnecting() {
+			return next(c)
+		}
+
+		reconnector := database.GetReconnector()
+		retryAfter := int(math.Ceil(reconnector.RetryAfter().Seconds()))
+		c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+		if !strings.HasPrefix(c.Path(), "/api/") && !prefers(c.Request(), "application/json", "text/html") {
+			html, _ := templates.RenderError(templates.ErrorData{
+				Title:   "数据库重连中",
+				Message: "正在尝试重新连接数据库，请 " + strconv.Itoa(retryAfter) + " 秒后再试",
+			})
+			return c.HTML(http.StatusServiceUnavailable, html)
+		}
+		return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+			"error":       "database reconnecting",
+			"state":       reconnector.State(),
+			"retry_after": retryAfter,
+			"retry_count": reconnector.GetRetryCount(),
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DebugTrace 请求级 SQL 追踪中间件，需在 QueryBudget 之后注册
+// 请求头 X-Debug-Trace: 1 或查询参数 debug_trace=1 开启，仅限操作员
+// 追踪结果在 JSON 响应中以 _debug 字段返回，页面以页脚形式附加，其他响应通过 X-Debug-Trace 响应头返回统计
+func DebugTrace(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !debugTraceRequested(c) {
+			return next(c)
+		}
+		if !IsOperator(c) {
+			return denyOperator(c, "SQL 追踪需要有效的操作员令牌")
+		}
+		stats := database.QueryStatsFrom(c.Request().Context())
+		// 事件流持续输出，无法缓冲后附加追踪结果
+		if stats == nil || strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "text/event-stream") {
+			return next(c)
+		}
+		stats.EnableTrace()
+
+		started := time.Now()
+		res := c.Response()
+		w := &traceCapture{ResponseWriter: res.Writer}
+		res.Writer = w
+		err := next(c)
+		res.Writer = w.ResponseWriter
+		if !res.Committed {
+			return err
+		}
+		return w.flush(stats, time.Since(started))
+	}
+}
+
+// debugTraceRequested 判断请求是否要求开启 SQL 追踪
+func debugTraceRequested(c echo.Context) bool {
+	value := c.Request().Header.Get("X-Debug-Trace")
+	if value == "" {
+		value = c.QueryParam("debug_trace")
+	}
+	enabled, _ := strconv.ParseBool(value)
+	return enabled
+}
+
+// traceCapture 缓冲完整响应以便附加追踪结果的 ResponseWriter
+type traceCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader 记录状态码，写出推迟到 flush
+func (w *traceCapture) WriteHeader(status int) {
+	w.status = status
+}
+
+// Write 缓冲响应体
+func (w *traceCapture) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// Flush 响应已缓冲，忽略处理器的主动刷新
+func (w *traceCapture) Flush() {}
+
+// flush 附加追踪结果后写出响应
+func (w *traceCapture) flush(stats *database.QueryStats, elapsed time.Duration) error {
+	statements, truncated := stats.Trace()
+	header := w.ResponseWriter.Header()
+	body := w.body.Bytes()
+	contentType := header.Get(echo.HeaderContentType)
+
+	switch {
+	case strings.HasPrefix(contentType, echo.MIMEApplicationJSON):
+		trimmed := bytes.TrimRight(body, " \r\n\t")
+		if bytes.HasPrefix(bytes.TrimLeft(trimmed, " \r\n\t"), []byte("{")) && bytes.HasSuffix(trimmed, []byte("}")) {
+			if payload, err := json.Marshal(debugTracePayload(stats, statements, truncated, elapsed)); err == nil {
+				var buf bytes.Buffer
+				buf.Write(trimmed[:len(trimmed)-1])
+				if len(bytes.TrimSpace(trimmed[:len(trimmed)-1])) > 1 {
+					buf.WriteByte(',')
+				}
+				buf.WriteString(`"_debug":`)
+				buf.Write(payload)
+				buf.WriteString("}\n")
+				body = buf.Bytes()
+			}
+		}
+	case strings.HasPrefix(contentType, echo.MIMETextHTML):
+		footer, err := templates.RenderDebugTrace(templates.DebugTraceData{
+			Queries:    stats.Count(),
+			Total:      stats.Total().Round(time.Microsecond).String(),
+			Elapsed:    elapsed.Round(time.Microsecond).String(),
+			Statements: traceRows(statements),
+			Truncated:  truncated,
+		})
+		if err == nil {
+			if i := bytes.LastIndex(body, []byte("</body>")); i >= 0 {
+				body = append(body[:i:i], append([]byte(footer), body[i:]...)...)
+			} else {
+				body = append(body, footer...)
+			}
+		}
+	}
+
+	header.Set("X-Debug-Trace", fmt.Sprintf("count=%d, total=%v, elapsed=%v",
+		stats.Count(), stats.Total().Round(time.Microsecond), elapsed.Round(time.Microsecond)))
+	header.Set(echo.HeaderContentLength, strconv.Itoa(len(body)))
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(status)
+	_, err := w.ResponseWriter.Write(body)
+	return err
+}
+
+// debugTracePayload JSON 响应中 _debug 字段的内容，耗时单位为毫秒
+func debugTracePayload(stats *database.QueryStats, statements []database.TracedQuery, truncated int, elapsed time.Duration) map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(statements))
+	for _, s := range statements {
+		row := map[string]interface{}{
+			"query":       s.Query,
+			"args":        s.Args,
+			"offset_ms":   durationMs(s.Offset),
+			"duration_ms": durationMs(s.Duration),
+		}
+		if s.Error != "" {
+			row["error"] = s.Error
+		}
+		rows = append(rows, row)
+	}
+	return map[string]interface{}{
+		"queries":    stats.Count(),
+		"total_ms":   durationMs(stats.Total()),
+		"elapsed_ms": durationMs(elapsed),
+		"statements": rows,
+		"truncated":  truncated,
+	}
+}
+
+// traceRows 页脚中展示的追踪记录，耗时取整到微秒
+func traceRows(statements []database.TracedQuery) []database.TracedQuery {
+	rows := make([]database.TracedQuery, len(statements))
+	for i, s := range statements {
+		s.Offset = s.Offset.Round(time.Microsecond)
+		s.Duration = s.Duration.Round(time.Microsecond)
+		rows[i] = s
+	}
+	return rows
+}
+
+// durationMs 将耗时转换为保留三位小数的毫秒数
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
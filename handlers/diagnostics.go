@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APIDiagnosticsListHandler API 诊断包列表处理器
+func APIDiagnosticsListHandler(c echo.Context) error {
+	diagnostics := database.GetDiagnostics()
+	bundles, err := diagnostics.List(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"enabled": diagnostics.Enabled(),
+		"bundles": bundles,
+	})
+}
+
+// APIDiagnosticsCaptureHandler API 手动采集诊断包处理器
+func APIDiagnosticsCaptureHandler(c echo.Context) error {
+	info, err := database.GetDiagnostics().Capture(database.BundleReasonManual, "手动采集")
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrDiagnosticsDisabled):
+			return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+				"error": "未配置持久化存储 (STORAGE_BACKEND) 或已关闭诊断包 (DIAG_ENABLED)",
+			})
+		case errors.Is(err, database.ErrDiagnosticsRunning):
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"error": "已有诊断包正在采集",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusCreated, info)
+}
+
+// APIDiagnosticsDownloadHandler API 下载诊断包处理器
+func APIDiagnosticsDownloadHandler(c echo.Context) error {
+	id := c.Param("id")
+	data, err := database.GetDiagnostics().Open(c.Request().Context(), id)
+	if errors.Is(err, database.ErrBundleNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "诊断包不存在",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="diagnostics-`+id+`.zip"`)
+	return c.Blob(http.StatusOK, "application/zip", data)
+}
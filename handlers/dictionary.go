@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultDictionaryLimit 数据字典搜索默认返回的条数
+const defaultDictionaryLimit = 100
+
+// APIDictionaryHandler API 数据字典处理器，基于表注释和列注释
+// 参数 q 为搜索词 (配置拼音表后可用全拼或首字母搜索中文注释)，不带 q 时返回完整字典
+// format=csv 或 markdown 时以附件导出，refresh=1 时重建索引
+func APIDictionaryHandler(c echo.Context) error {
+	databaseName := c.Param("database")
+	if databaseName == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "数据库名称不能为空",
+		})
+	}
+	limit := defaultDictionaryLimit
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "limit 必须为非负整数",
+			})
+		}
+		limit = n
+	}
+
+	refresh := c.QueryParam("refresh") == "1"
+	idx, err := database.GetCommentIndex(c.Request().Context(), databaseName, refresh)
+	if err != nil {
+		if status, message, ok := identifierError(err); ok {
+			return c.JSON(status, map[string]interface{}{
+				"error": message,
+			})
+		}
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	entries := idx.Entries
+	var matches []database.CommentMatch
+	query := c.QueryParam("q")
+	if query != "" {
+		matches = idx.Search(query, limit)
+		entries = make([]database.CommentEntry, len(matches))
+		for i, m := range matches {
+			entries[i] = m.CommentEntry
+		}
+	}
+
+	filename := "dictionary-" + databaseName
+	switch strings.ToLower(c.QueryParam("format")) {
+	case "csv":
+		c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`.csv"`)
+		c.Response().WriteHeader(http.StatusOK)
+		// 写入 BOM 以便 Excel 正确识别中文
+		_, _ = c.Response().Write([]byte("\xef\xbb\xbf"))
+		w := csv.NewWriter(c.Response())
+		_ = w.Write([]string{"table", "column", "type", "comment"})
+		for _, e := range entries {
+			_ = w.Write([]string{e.Table, e.Column, e.Type, e.Comment})
+		}
+		w.Flush()
+		return w.Error()
+	case "markdown", "md":
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`.md"`)
+		return c.Blob(http.StatusOK, "text/markdown; charset=utf-8", []byte(database.DictionaryMarkdown(databaseName, entries)))
+	}
+
+	if query != "" {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"database": databaseName,
+			"query":    query,
+			"matches":  matches,
+			"count":    len(matches),
+			"indexed":  idx.Built,
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"database": databaseName,
+		"entries":  entries,
+		"count":    len(entries),
+		"indexed":  idx.Built,
+	})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/snapshot"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxSchemaUploadSize 上传结构文件的大小上限
+const maxSchemaUploadSize = 16 << 20
+
+// UploadDiffHandler 比较上传的结构文件与在线数据库的差异 (仅操作员)
+// 参数: database 在线数据库名；schema 文件中的数据库名 (可选，默认与 database 相同)
+// 文件通过 multipart 字段 file 或直接作为请求体上传
+func UploadDiffHandler(c echo.Context) error {
+	report, status, err := uploadDiffReport(c)
+	if err != nil {
+		return c.JSON(status, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// uploadDiffReport 读取上传的结构文件并与在线数据库比较，失败时返回对应的状态码
+func uploadDiffReport(c echo.Context) (*snapshot.DiffReport, int, error) {
+	databaseName := c.QueryParam("database")
+	if databaseName == "" {
+		return nil, http.StatusBadRequest, errors.New("missing database parameter")
+	}
+	schemaName := c.QueryParam("schema")
+
+	data, filename, err := readUpload(c)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	expected, err := snapshot.Load(data, schemaName)
+	if err != nil && schemaName == "" {
+		// 未指定时尝试按在线数据库名在文件中查找
+		expected, err = snapshot.Load(data, databaseName)
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	actual, err := snapshot.BuildDatabase(c.Request().Context(), store, databaseName)
+	if err != nil {
+		if database.IsTimeout(err) {
+			return nil, http.StatusGatewayTimeout, errors.New("query timeout")
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return snapshot.Compare(filename, expected, databaseName, actual), http.StatusOK, nil
+}
+
+// readUpload 读取上传的文件内容及文件名
+func readUpload(c echo.Context) ([]byte, string, error) {
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxSchemaUploadSize)
+
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		return data, fileHeader.Filename, err
+	}
+
+	data, err := io.ReadAll(c.Request().Body)
+	return data, "upload", err
+}
+
+// driftChecker 迁移目录漂移检测器，未配置迁移目录时为 nil
+var driftChecker *snapshot.DriftChecker
+
+// SetDriftChecker 设置迁移漂移检测器
+func SetDriftChecker(d *snapshot.DriftChecker) {
+	driftChecker = d
+}
+
+// APIDriftHandler 返回迁移目录与在线数据库之间的结构漂移报告
+// 默认返回最近一次定期检测的结果，refresh=true 或尚无结果时立即检测
+func APIDriftHandler(c echo.Context) error {
+	if driftChecker == nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "migrations directory not configured",
+		})
+	}
+
+	result, ok := driftChecker.Last()
+	if !ok || c.QueryParam("refresh") == "true" {
+		result = driftChecker.Check(c.Request().Context())
+	}
+	if result.Error != "" {
+		return c.JSON(http.StatusInternalServerError, result)
+	}
+	return c.JSON(http.StatusOK, result)
+}
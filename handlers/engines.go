@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// EnginesPageHandler 存储引擎分布页面处理器
+func EnginesPageHandler(c echo.Context) error {
+	report, err := store.GetEngineReport(c.Request().Context())
+	if err != nil {
+		if database.IsTimeout(err) {
+			return renderTimeoutError(c)
+		}
+		data := templates.ErrorData{
+			Title:   "获取存储引擎分布失败",
+			Message: err.Error(),
+		}
+		html, _ := templates.RenderError(data)
+		return c.HTML(http.StatusInternalServerError, html)
+	}
+
+	html, err := templates.RenderEngines(templates.EnginesData{Report: report})
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
+
+// APIEnginesHandler API 存储引擎分布处理器
+func APIEnginesHandler(c echo.Context) error {
+	report, err := store.GetEngineReport(c.Request().Context())
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultOutageTrendHours 错误趋势与连接中断对照的默认小时数
+const defaultOutageTrendHours = 24
+
+// APIErrorOutagesHandler 错误趋势与连接中断对照处理器
+// 将最近 hours 小时 (默认 24) 内的连接中断叠加到每小时错误数上，并给出每次中断的起止、时长、重试次数和主要错误
+// profile 参数指定服务器，默认为默认连接
+func APIErrorOutagesHandler(c echo.Context) error {
+	registry := database.GetProfileRegistry()
+	analyzer, ok := registry.ErrorAnalyzer(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
+	reconnector, _ := registry.Reconnector(c.QueryParam("profile"))
+
+	hours := defaultOutageTrendHours
+	if v := c.QueryParam("hours"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "hours 必须为正整数",
+			})
+		}
+		hours = n
+	}
+
+	to := time.Now()
+	from := to.Add(-time.Duration(hours-1) * time.Hour).Truncate(time.Hour)
+	outages := reconnector.Outages(from)
+	hourly, _ := analyzer.GetErrorTrends()["hourly_data"].(map[string]int)
+
+	var downtime float64
+	for _, o := range outages {
+		downtime += o.Duration
+	}
+	annotations := database.GetAnnotations().Between(from, to, annotationProfile(c))
+	trend := database.CorrelateOutages(hourly, outages, from, to)
+	database.OverlayAnnotations(trend, annotations)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"from":             from,
+		"to":               to,
+		"outages":          outages,
+		"outage_count":     len(outages),
+		"downtime_seconds": downtime,
+		"hourly":           trend,
+		"annotations":      annotations,
+	})
+}
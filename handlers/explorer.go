@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/furutachiKurea/block-checker/database"
@@ -12,12 +14,15 @@ import (
 
 // DatabasesHandler 数据库列表处理器
 func DatabasesHandler(c echo.Context) error {
-	databases, err := database.GetDatabases()
+	databases, err := store.GetDatabases(c.Request().Context())
 	if err != nil {
+		if database.IsTimeout(err) {
+			return renderTimeoutError(c)
+		}
+
 		// 检查是否是连接问题
 		if strings.Contains(err.Error(), "connection failed") {
-			reconnector := database.GetReconnector()
-			if reconnector.IsReconnecting() {
+			if store.IsReconnecting() {
 				data := templates.ErrorData{
 					Title:   "数据库重连中",
 					Message: "正在尝试重新连接数据库，请稍后再试",
@@ -46,7 +51,7 @@ func DatabasesHandler(c echo.Context) error {
 	var dbInfos []templates.DatabaseInfo
 	for _, db := range databases {
 		// 获取数据库中的表数量
-		tables, err := database.GetTables(db.Name)
+		tables, err := store.GetTables(c.Request().Context(), db.Name)
 		tableCount := 0
 		if err == nil {
 			tableCount = len(tables)
@@ -82,12 +87,22 @@ func TablesHandler(c echo.Context) error {
 		return c.HTML(http.StatusBadRequest, html)
 	}
 
-	tables, err := database.GetTables(databaseName)
+	tables, err := store.GetTables(c.Request().Context(), databaseName)
 	if err != nil {
+		if database.IsTimeout(err) {
+			return renderTimeoutError(c)
+		}
+		if status, message, ok := identifierError(err); ok {
+			html, _ := templates.RenderError(templates.ErrorData{
+				Title:   "获取表列表失败",
+				Message: message,
+			})
+			return c.HTML(status, html)
+		}
+
 		// 检查是否是连接问题
 		if strings.Contains(err.Error(), "connection failed") {
-			reconnector := database.GetReconnector()
-			if reconnector.IsReconnecting() {
+			if store.IsReconnecting() {
 				data := templates.ErrorData{
 					Title:   "数据库重连中",
 					Message: "正在尝试重新连接数据库，请稍后再试",
@@ -126,6 +141,7 @@ func TablesHandler(c echo.Context) error {
 	data := templates.TablesData{
 		DatabaseName: databaseName,
 		Tables:       tableInfos,
+		Grants:       lookupGrants(c, databaseName, ""),
 	}
 
 	html, err := templates.RenderTables(data)
@@ -149,8 +165,19 @@ func TableDetailHandler(c echo.Context) error {
 		return c.HTML(http.StatusBadRequest, html)
 	}
 
-	detail, err := database.GetTableDetail(databaseName, tableName)
+	detail, err := store.GetTableDetail(c.Request().Context(), databaseName, tableName)
 	if err != nil {
+		if database.IsTimeout(err) {
+			return renderTimeoutError(c)
+		}
+		if status, message, ok := identifierError(err); ok {
+			html, _ := templates.RenderError(templates.ErrorData{
+				Title:   "获取表结构失败",
+				Message: message,
+			})
+			return c.HTML(status, html)
+		}
+
 		data := templates.ErrorData{
 			Title:   "获取表结构失败",
 			Message: err.Error(),
@@ -163,6 +190,7 @@ func TableDetailHandler(c echo.Context) error {
 		DatabaseName: databaseName,
 		TableName:    tableName,
 		Detail:       detail,
+		Grants:       lookupGrants(c, databaseName, tableName),
 	}
 	html, err := templates.RenderTableDetail(data)
 	if err != nil {
@@ -173,8 +201,13 @@ func TableDetailHandler(c echo.Context) error {
 
 // APIDatabasesHandler API 数据库列表处理器
 func APIDatabasesHandler(c echo.Context) error {
-	databases, err := database.GetDatabases()
+	databases, err := store.GetDatabases(c.Request().Context())
 	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"error": err.Error(),
 		})
@@ -194,8 +227,18 @@ func APITablesHandler(c echo.Context) error {
 		})
 	}
 
-	tables, err := database.GetTables(databaseName)
+	tables, err := store.GetTables(c.Request().Context(), databaseName)
 	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		if status, message, ok := identifierError(err); ok {
+			return c.JSON(status, map[string]interface{}{
+				"error": message,
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
 			"error": err.Error(),
 		})
@@ -206,3 +249,112 @@ func APITablesHandler(c echo.Context) error {
 		"tables":   tables,
 	})
 }
+
+// APITableDetailHandler API 表结构详情处理器
+func APITableDetailHandler(c echo.Context) error {
+	databaseName := c.Param("database")
+	tableName := c.Param("table")
+	if databaseName == "" || tableName == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "数据库名和表名不能为空",
+		})
+	}
+
+	detail, err := store.GetTableDetail(c.Request().Context(), databaseName, tableName)
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		if status, message, ok := identifierError(err); ok {
+			return c.JSON(status, map[string]interface{}{
+				"error": message,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"database": databaseName,
+		"table":    tableName,
+		"detail":   detail,
+	})
+}
+
+// APITableSampleHandler API 表数据采样处理器 (仅操作员，返回真实的行数据)
+// 相同的 seed 在数据不变时返回相同的样本
+func APITableSampleHandler(c echo.Context) error {
+	databaseName := c.Param("database")
+	tableName := c.Param("table")
+	if databaseName == "" || tableName == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "数据库名称和表名称不能为空",
+		})
+	}
+
+	n := 100
+	if v := c.QueryParam("n"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "n 必须为正整数",
+			})
+		}
+		n = parsed
+	}
+	seed := int64(1)
+	if v := c.QueryParam("seed"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "seed 必须为整数",
+			})
+		}
+		seed = parsed
+	}
+
+	sample, err := store.SampleTable(c.Request().Context(), databaseName, tableName, n, seed)
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		if status, message, ok := identifierError(err); ok {
+			return c.JSON(status, map[string]interface{}{
+				"error": message,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, sample)
+}
+
+// identifierError 将库名、表名校验错误映射为状态码和提示信息
+func identifierError(err error) (int, string, bool) {
+	switch {
+	case errors.Is(err, database.ErrInvalidIdentifier):
+		return http.StatusBadRequest, err.Error(), true
+	case errors.Is(err, database.ErrDatabaseNotFound):
+		return http.StatusNotFound, "数据库不存在", true
+	case errors.Is(err, database.ErrTableNotFound):
+		return http.StatusNotFound, "表不存在", true
+	}
+	return 0, "", false
+}
+
+// renderTimeoutError 渲染查询超时错误页面
+func renderTimeoutError(c echo.Context) error {
+	data := templates.ErrorData{
+		Title:   "查询超时",
+		Message: "数据库查询超过了配置的超时时间，请稍后再试",
+	}
+	html, _ := templates.RenderError(data)
+	return c.HTML(http.StatusGatewayTimeout, html)
+}
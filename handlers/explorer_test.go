@@ -0,0 +1,150 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/database/storetest"
+	"github.com/furutachiKurea/block-checker/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// serve 使用 mock 作为 Store，把处理器注册到 route 并请求 target，返回状态码和解析后的 JSON
+func serve(t *testing.T, mock database.Store, route string, handler echo.HandlerFunc, target string) (int, map[string]interface{}) {
+	t.Helper()
+	handlers.SetStore(mock)
+	t.Cleanup(func() { handlers.SetStore(database.DefaultStore()) })
+
+	e := echo.New()
+	e.GET(route, handler)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response is not JSON: %v: %s", err, rec.Body.String())
+	}
+	return rec.Code, body
+}
+
+func TestAPITableDetailHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCode  int
+		wantError string
+	}{
+		{"timeout", context.DeadlineExceeded, http.StatusGatewayTimeout, "query timeout"},
+		{"wrapped timeout", fmt.Errorf("query fields: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, "query timeout"},
+		{"database not found", database.ErrDatabaseNotFound, http.StatusNotFound, "数据库不存在"},
+		{"table not found", database.ErrTableNotFound, http.StatusNotFound, "表不存在"},
+		{"other error", fmt.Errorf("check connection: broken pipe"), http.StatusInternalServerError, "check connection: broken pipe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &storetest.MockStore{
+				GetTableDetailFunc: func(ctx context.Context, databaseName, tableName string) (*database.TableDetail, error) {
+					return nil, tt.err
+				},
+			}
+			code, body := serve(t, mock, "/api/databases/:database/tables/:table", handlers.APITableDetailHandler, "/api/databases/shop/tables/orders")
+			if code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", code, tt.wantCode)
+			}
+			if body["error"] != tt.wantError {
+				t.Fatalf("error = %v, want %q", body["error"], tt.wantError)
+			}
+		})
+	}
+}
+
+func TestAPITableDetailHandlerOK(t *testing.T) {
+	mock := &storetest.MockStore{
+		GetTableDetailFunc: func(ctx context.Context, databaseName, tableName string) (*database.TableDetail, error) {
+			return &database.TableDetail{}, nil
+		},
+	}
+	code, body := serve(t, mock, "/api/databases/:database/tables/:table", handlers.APITableDetailHandler, "/api/databases/shop/tables/orders")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", code, http.StatusOK)
+	}
+	if body["database"] != "shop" || body["table"] != "orders" || body["detail"] == nil {
+		t.Fatalf("unexpected body: %v", body)
+	}
+}
+
+func TestAPITablesHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCode  int
+		wantError string
+	}{
+		{"timeout", context.DeadlineExceeded, http.StatusGatewayTimeout, "query timeout"},
+		{"database not found", database.ErrDatabaseNotFound, http.StatusNotFound, "数据库不存在"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &storetest.MockStore{
+				GetTablesFunc: func(ctx context.Context, databaseName string) ([]database.TableInfo, error) {
+					return nil, tt.err
+				},
+			}
+			code, body := serve(t, mock, "/api/databases/:database/tables", handlers.APITablesHandler, "/api/databases/shop/tables")
+			if code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", code, tt.wantCode)
+			}
+			if body["error"] != tt.wantError {
+				t.Fatalf("error = %v, want %q", body["error"], tt.wantError)
+			}
+		})
+	}
+}
+
+func TestTablePagesIdentifierErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantText string
+	}{
+		{"invalid identifier", fmt.Errorf("%w: ends with space", database.ErrInvalidIdentifier), http.StatusBadRequest, "ends with space"},
+		{"database not found", database.ErrDatabaseNotFound, http.StatusNotFound, "数据库不存在"},
+		{"table not found", database.ErrTableNotFound, http.StatusNotFound, "表不存在"},
+		{"other error", fmt.Errorf("check connection: broken pipe"), http.StatusInternalServerError, "broken pipe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &storetest.MockStore{
+				GetTablesFunc: func(ctx context.Context, databaseName string) ([]database.TableInfo, error) {
+					return nil, tt.err
+				},
+				GetTableDetailFunc: func(ctx context.Context, databaseName, tableName string) (*database.TableDetail, error) {
+					return nil, tt.err
+				},
+			}
+			handlers.SetStore(mock)
+			t.Cleanup(func() { handlers.SetStore(database.DefaultStore()) })
+
+			e := echo.New()
+			e.GET("/databases/:database/tables", handlers.TablesHandler)
+			e.GET("/database/:database/table/:table", handlers.TableDetailHandler)
+			for _, target := range []string{"/databases/shop/tables", "/database/shop/table/orders"} {
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+				if rec.Code != tt.wantCode {
+					t.Errorf("%s: status = %d, want %d", target, rec.Code, tt.wantCode)
+				}
+				if !strings.Contains(rec.Body.String(), tt.wantText) {
+					t.Errorf("%s: page does not contain %q", target, tt.wantText)
+				}
+			}
+		})
+	}
+}
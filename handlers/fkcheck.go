@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APIFKCheckStartHandler API 启动外键校验处理器
+func APIFKCheckStartHandler(c echo.Context) error {
+	databaseName := c.Param("database")
+	tableName := c.Param("table")
+	if databaseName == "" || tableName == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "数据库名称和表名称不能为空",
+		})
+	}
+
+	job, err := database.GetFKChecker().Start(c.Request().Context(), databaseName, tableName)
+	if err != nil {
+		if status, message, ok := identifierError(err); ok {
+			return c.JSON(status, map[string]interface{}{
+				"error": message,
+			})
+		}
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// APIFKCheckListHandler API 外键校验任务列表处理器
+func APIFKCheckListHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"jobs": database.GetFKChecker().List(),
+	})
+}
+
+// APIFKCheckStatusHandler API 外键校验任务状态处理器
+func APIFKCheckStatusHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid id",
+		})
+	}
+
+	job, err := database.GetFKChecker().Get(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// APIFKCheckCancelHandler API 取消外键校验任务处理器
+func APIFKCheckCancelHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid id",
+		})
+	}
+
+	if err := database.GetFKChecker().Cancel(id); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// APIFKCheckViolationsHandler API 外键违规行下载处理器，format=json 时输出 JSON，默认为 CSV
+func APIFKCheckViolationsHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid id",
+		})
+	}
+
+	checker := database.GetFKChecker()
+	job, err := checker.Get(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	violations, err := checker.Violations(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	filename := "fk-violations-" + job.Database + "-" + job.Table + "-" + strconv.Itoa(job.ID)
+	if strings.EqualFold(c.QueryParam("format"), "json") {
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`.json"`)
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"job":        job,
+			"violations": violations,
+		})
+	}
+
+	columns := make(map[string]string, len(job.ForeignKeys))
+	for _, fk := range job.ForeignKeys {
+		columns[fk.Name] = strings.Join(fk.Columns, ",")
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`.csv"`)
+	c.Response().WriteHeader(http.StatusOK)
+	w := csv.NewWriter(c.Response())
+	_ = w.Write([]string{"constraint", "primary_key", "columns", "values"})
+	for _, v := range violations {
+		_ = w.Write([]string{v.Constraint, v.Key, columns[v.Constraint], strings.Join(v.Values, ",")})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GrowthPageHandler 容量增长页面处理器
+func GrowthPageHandler(c echo.Context) error {
+	tracker := database.GetGrowthTracker()
+	data := templates.GrowthData{
+		Forecasts: tracker.Forecast(),
+	}
+
+	html, err := templates.RenderGrowth(data)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
+
+// APIGrowthForecastHandler API 容量预测处理器
+func APIGrowthForecastHandler(c echo.Context) error {
+	tracker := database.GetGrowthTracker()
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"forecasts": tracker.Forecast(),
+	})
+}
@@ -9,9 +9,22 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-// HomeHandler 主页处理器
+// store 处理器使用的数据访问接口，默认使用 MySQL 实现
+var store database.Store = database.DefaultStore()
+
+// SetStore 注入处理器使用的 Store 实现
+func SetStore(s database.Store) {
+	store = s
+}
+
+// compactView 判断页面是否使用紧凑视图 (view=compact)，供手机访问
+func compactView(c echo.Context) bool {
+	return c.QueryParam("view") == "compact"
+}
+
+// HomeHandler 主页处理器，view=compact 时使用紧凑视图
 func HomeHandler(c echo.Context) error {
-	status := database.CheckStatus()
+	status := store.CheckStatus(c.Request().Context())
 
 	statusClass := "status-ok"
 	if status.Status == "Not Connected" {
@@ -34,14 +47,30 @@ func HomeHandler(c echo.Context) error {
 			Timestamp:  status.ErrorDetails.Timestamp,
 			RetryCount: status.ErrorDetails.RetryCount,
 		}
+		if status.ErrorDetails.Network != nil {
+			errorDetails.Network = status.ErrorDetails.Network
+		}
 	}
 
 	data := templates.HomeData{
 		Status:       status.Status,
 		StatusClass:  statusClass,
 		Timestamp:    status.Timestamp,
+		Host:         status.Host,
 		Error:        status.Error,
 		ErrorDetails: errorDetails,
+		Compact:      compactView(c),
+	}
+	if status.Status == "OK" {
+		if usage, err := store.GetThreadUsage(c.Request().Context()); err == nil {
+			data.Threads = &templates.ThreadGauge{
+				Connected:      usage.Connected,
+				Running:        usage.Running,
+				MaxConnections: usage.MaxConnections,
+				Percent:        usage.UsagePercent,
+				WarnPercent:    connectionWarnPercent(),
+			}
+		}
 	}
 
 	html, err := templates.RenderHome(data)
@@ -54,5 +83,8 @@ func HomeHandler(c echo.Context) error {
 
 // HealthHandler 健康检查处理器
 func HealthHandler(c echo.Context) error {
+	if v := c.QueryParam("verbose"); v == "1" || v == "true" {
+		return verboseHealth(c)
+	}
 	return c.NoContent(http.StatusOK)
 }
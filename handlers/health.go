@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/alert"
+	"github.com/furutachiKurea/block-checker/checks"
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 组件健康状态
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+	healthDown     = "down"
+)
+
+// healthDialTimeout 检查通知地址可达性的连接超时
+const healthDialTimeout = 2 * time.Second
+
+// processStart 进程启动时间
+var processStart = time.Now()
+
+// ComponentHealth 单个组件的健康状态
+type ComponentHealth struct {
+	Name       string                 `json:"name"`
+	Status     string                 `json:"status"`
+	Message    string                 `json:"message,omitempty"`
+	DurationMs float64                `json:"duration_ms"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+}
+
+// verboseHealth 检查各组件并返回明细，任一组件为 down 时返回 503
+func verboseHealth(c echo.Context) error {
+	ctx := c.Request().Context()
+	checks := []func(context.Context) ComponentHealth{
+		databaseHealth,
+		reconnectorHealth,
+		samplerHealth,
+		schedulerHealth,
+		notifierHealth,
+		canaryHealth,
+		chaosHealth,
+	}
+
+	components := make([]ComponentHealth, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check func(context.Context) ComponentHealth) {
+			defer wg.Done()
+			start := time.Now()
+			components[i] = check(ctx)
+			components[i].DurationMs = float64(time.Since(start).Microseconds()) / 1000
+		}(i, check)
+	}
+	wg.Wait()
+
+	overall := healthOK
+	for _, component := range components {
+		overall = worseHealth(overall, component.Status)
+	}
+	status := http.StatusOK
+	if overall == healthDown {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, map[string]interface{}{
+		"status":     overall,
+		"uptime":     time.Since(processStart).Round(time.Second).String(),
+		"components": components,
+	})
+}
+
+// worseHealth 返回两个状态中更差的一个
+func worseHealth(a, b string) string {
+	rank := map[string]int{healthOK: 0, healthDegraded: 1, healthDown: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// databaseHealth 通过健康检查连接执行一次状态检查
+func databaseHealth(ctx context.Context) ComponentHealth {
+	h := ComponentHealth{Name: "database", Status: healthOK}
+	status := store.CheckStatus(ctx)
+	if status.Status != "OK" {
+		h.Status = healthDown
+		h.Message = status.Error
+		return h
+	}
+	h.Details = map[string]interface{}{"host": status.Host}
+	return h
+}
+
+// reconnectorHealth 重连器状态：已连接首选主机为 ok，备用主机或重连中为 degraded，放弃或停止为 down
+func reconnectorHealth(ctx context.Context) ComponentHealth {
+	reconnector := database.GetReconnector()
+	state := reconnector.State()
+	h := ComponentHealth{Name: "reconnector", Status: healthOK, Details: map[string]interface{}{
+		"state":       state,
+		"retry_count": reconnector.GetRetryCount(),
+	}}
+	switch state {
+	case database.StateDegraded, database.StateReconnecting:
+		h.Status = healthDegraded
+	case database.StateGaveUp, database.StateStopped:
+		h.Status = healthDown
+	}
+	if err := reconnector.GetLastError(); err != nil {
+		h.Message = err.Error()
+	}
+	return h
+}
+
+// samplerHealth 采样数据的新鲜度，最近一次采样早于两个采样间隔时为 degraded
+// 表增长预测、压力和连接数页面都基于这些采样
+func samplerHealth(ctx context.Context) ComponentHealth {
+	h := ComponentHealth{Name: "samplers", Status: healthOK, Details: map[string]interface{}{}}
+	check := func(name string, interval time.Duration, last time.Time) {
+		if interval <= 0 {
+			h.Details[name] = "disabled"
+			return
+		}
+		fresh := freshness(last, interval)
+		h.Details[name] = fresh
+		if fresh["stale"] == true {
+			h.Status = healthDegraded
+		}
+	}
+
+	var last time.Time
+	if samples := database.GetGrowthTracker().GetSamples(); len(samples) > 0 {
+		last = samples[len(samples)-1].Timestamp
+	}
+	check("growth", config.GetGrowthConfig().SampleInterval, last)
+
+	last = time.Time{}
+	if samples := database.GetPressureTracker().GetSamples(); len(samples) > 0 {
+		last = samples[len(samples)-1].Timestamp
+	}
+	check("pressure", config.GetPressureConfig().SampleInterval, last)
+
+	last = time.Time{}
+	if samples := database.GetThreadTracker().GetSamples(); len(samples) > 0 {
+		last = samples[len(samples)-1].Timestamp
+	}
+	check("threads", config.GetThreadsConfig().SampleInterval, last)
+	return h
+}
+
+// schedulerHealth 告警评估和健康评分的定时任务是否仍在运行
+func schedulerHealth(ctx context.Context) ComponentHealth {
+	h := ComponentHealth{Name: "schedulers", Status: healthOK, Details: map[string]interface{}{}}
+	if alertEngine != nil {
+		fresh := freshness(alertEngine.LastEvaluated(), config.GetAlertConfig().EvalInterval)
+		h.Details["alerts"] = fresh
+		if fresh["stale"] == true {
+			h.Status = healthDegraded
+		}
+	}
+	if sloTracker != nil {
+		fresh := freshness(sloTracker.LastEvaluated(), config.GetSLOConfig().Interval)
+		h.Details["slo"] = fresh
+		if fresh["stale"] == true {
+			h.Status = healthDegraded
+		}
+	}
+	return h
+}
+
+// freshness 描述最近一次执行距今的时长，超过两个间隔 (进程刚启动时从启动时间算起) 视为过期
+func freshness(last time.Time, interval time.Duration) map[string]interface{} {
+	reference := last
+	if reference.IsZero() {
+		reference = processStart
+	}
+	age := time.Since(reference)
+	result := map[string]interface{}{
+		"interval": interval.String(),
+		"stale":    age > 2*interval,
+	}
+	if !last.IsZero() {
+		result["last"] = last
+		result["age"] = age.Round(time.Second).String()
+	}
+	return result
+}
+
+// notifierHealth 检查告警通知和心跳地址的 TCP 可达性，只报告主机名不暴露完整地址
+// 同时给出各渠道最近的发送情况，最近一次发送失败时为 degraded
+func notifierHealth(ctx context.Context) ComponentHealth {
+	h := ComponentHealth{Name: "notifiers", Status: healthOK}
+	alertConfig := config.GetAlertConfig()
+	targets := append([]string{}, alertConfig.WebhookURLs...)
+	targets = append(targets, alertConfig.DingTalkWebhook, alertConfig.FeishuWebhook, config.GetHeartbeatConfig().URL)
+	if alertConfig.TelegramBotToken != "" {
+		targets = append(targets, alertConfig.TelegramAPIURL)
+	}
+
+	results := make(map[string]interface{})
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		address, ok := dialAddress(target)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			dialer := net.Dialer{Timeout: healthDialTimeout}
+			conn, err := dialer.DialContext(ctx, "tcp", address)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				results[address] = err.Error()
+				h.Status = healthDegraded
+				return
+			}
+			conn.Close()
+			results[address] = healthOK
+		}(address)
+	}
+	wg.Wait()
+	if len(results) == 0 {
+		h.Message = "未配置通知"
+	}
+
+	statuses := alert.DeliveryStatuses()
+	for _, s := range statuses {
+		if s.ConsecutiveFailures > 0 {
+			h.Status = healthDegraded
+			h.Message = fmt.Sprintf("%s 已连续发送失败 %d 次", s.Channel, s.ConsecutiveFailures)
+		}
+	}
+	if len(statuses) > 0 {
+		results["deliveries"] = statuses
+	}
+	h.Details = results
+	return h
+}
+
+// canaryHealth 最近一次写入探测的结果：critical 为 down，warning 或结果过期为 degraded
+func canaryHealth(ctx context.Context) ComponentHealth {
+	h := ComponentHealth{Name: "canary", Status: healthOK, Details: map[string]interface{}{}}
+	canary := database.GetCanary()
+	cfg := canary.Config()
+	if !cfg.Enabled {
+		h.Details["enabled"] = false
+		return h
+	}
+	result := canary.LastResult()
+	if result == nil {
+		h.Message = "尚未运行"
+		return h
+	}
+	fresh := freshness(result.Time, cfg.Interval)
+	h.Details["last_run"] = fresh
+	for key, value := range result.Details {
+		h.Details[key] = value
+	}
+	h.Message = result.Message
+	switch result.Status {
+	case checks.StatusCritical:
+		h.Status = healthDown
+	case checks.StatusWarning:
+		h.Status = healthDegraded
+	}
+	if fresh["stale"] == true {
+		h.Status = worseHealth(h.Status, healthDegraded)
+	}
+	return h
+}
+
+// chaosHealth 故障模拟状态，有正在生效的模拟故障时为 degraded，提醒当前的异常是模拟的
+func chaosHealth(ctx context.Context) ComponentHealth {
+	h := ComponentHealth{Name: "chaos", Status: healthOK}
+	chaos := database.GetChaos()
+	if !chaos.Enabled() {
+		h.Details = map[string]interface{}{"enabled": false}
+		return h
+	}
+	faults := chaos.Active()
+	h.Details = map[string]interface{}{"enabled": true, "faults": faults}
+	if len(faults) > 0 {
+		h.Status = healthDegraded
+		h.Message = fmt.Sprintf("%d 个模拟故障正在生效", len(faults))
+	}
+	return h
+}
+
+// dialAddress 从 URL 中取出 host:port，未配置或无法解析时返回 false
+func dialAddress(rawURL string) (string, bool) {
+	if rawURL == "" {
+		return "", false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return "", false
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), true
+}
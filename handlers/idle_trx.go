@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APIIdleTrxHandler 空闲事务自动终止策略处理器，返回策略配置和最近的操作记录 (按时间倒序)
+// limit 限制返回的操作数，0 为不限制
+func APIIdleTrxHandler(c echo.Context) error {
+	limit := 0
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "limit 必须为非负整数",
+			})
+		}
+		limit = n
+	}
+
+	killer := database.GetIdleTrxKiller()
+	cfg := killer.Config()
+	actions := killer.Actions(limit)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"enabled":            cfg.Enabled(),
+		"dry_run":            cfg.DryRun,
+		"kill_after_seconds": cfg.KillAfter.Seconds(),
+		"schemas":            cfg.Schemas,
+		"actions":            actions,
+		"count":              len(actions),
+	})
+}
+
+// APIIdleTrxRunHandler 立即执行一次空闲事务策略，返回本次采取的操作
+func APIIdleTrxRunHandler(c echo.Context) error {
+	killer := database.GetIdleTrxKiller()
+	if !killer.Config().Enabled() {
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"error": "空闲事务策略未启用，需要配置 IDLE_TRX_KILL_AFTER 和 IDLE_TRX_KILL_SCHEMAS",
+		})
+	}
+	actions, err := killer.Enforce(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if actions == nil {
+		actions = []database.IdleTrxAction{}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"dry_run": killer.Config().DryRun,
+		"actions": actions,
+		"count":   len(actions),
+	})
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultIncidentWindow 未指定起始时间时报告覆盖的时长
+const defaultIncidentWindow = time.Hour
+
+// APIIncidentReportHandler API 故障报告处理器
+// from/to 支持 RFC3339 或 datetime-local 格式，默认为最近一小时；format=markdown 时输出 Markdown
+func APIIncidentReportHandler(c echo.Context) error {
+	to := time.Now()
+	if v := c.QueryParam("to"); v != "" {
+		t, err := parseWindowTime(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid to: " + err.Error(),
+			})
+		}
+		to = t
+	}
+	from := to.Add(-defaultIncidentWindow)
+	if v := c.QueryParam("from"); v != "" {
+		t, err := parseWindowTime(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid from: " + err.Error(),
+			})
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "from 必须早于 to",
+		})
+	}
+
+	report := database.BuildIncidentReport(from, to)
+
+	format := strings.ToLower(c.QueryParam("format"))
+	if format == "markdown" || format == "md" || strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "text/markdown") {
+		return c.Blob(http.StatusOK, "text/markdown; charset=utf-8", []byte(report.Markdown()))
+	}
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// externalLogRequest 外部日志写入请求
+type externalLogRequest struct {
+	Source  string `json:"source" form:"source"` // 来源名称，如 backup-job
+	Level   string `json:"level" form:"level"`   // debug/info/warn/error，默认 info
+	Message string `json:"message" form:"message"`
+	Details string `json:"details" form:"details"`
+}
+
+// AddLogHandler 外部脚本 (如备份任务) 写入日志，与本进程的日志显示在同一时间线上
+// 写入 profile 指定服务器的日志，低于 external 组件日志级别的条目不记录
+func AddLogHandler(c echo.Context) error {
+	logger, ok := database.GetProfileRegistry().Logger(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
+	var req externalLogRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+	}
+
+	level := database.LogLevelInfo
+	if req.Level != "" {
+		l, exists := logLevelMap[req.Level]
+		if !exists {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "无效的日志级别，可选 debug/info/warn/error",
+			})
+		}
+		level = l
+	}
+	if err := database.ValidateExternalLog(req.Source, level, req.Message, req.Details); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	entry, recorded := logger.AddExternal(req.Source, level, req.Message, req.Details)
+	if !recorded {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"recorded": false,
+			"message":  "低于 external 组件的日志级别，未记录",
+		})
+	}
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"recorded":  true,
+		"id":        entry.ID,
+		"count":     entry.Count, // 大于 1 时表示已合并到窗口内相同的消息
+		"timestamp": config.FormatAPITime(entry.Timestamp),
+	})
+}
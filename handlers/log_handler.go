@@ -1,12 +1,13 @@
 package handlers
 
 import (
-	"io/ioutil"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/furutachiKurea/block-checker/config"
 	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
 	"github.com/labstack/echo/v4"
 )
 
@@ -21,23 +22,26 @@ var logLevelMap = map[string]database.LogLevel{
 
 // LogEntry 前端日志条目结构
 type LogEntry struct {
+	ID             int64                         `json:"id"`
 	Level          string                        `json:"level"`
 	Message        string                        `json:"message"`
 	Timestamp      string                        `json:"timestamp"`
 	Details        string                        `json:"details,omitempty"`
 	Count          int                           `json:"count,omitempty"`
+	Component      string                        `json:"component,omitempty"`
+	Source         string                        `json:"source,omitempty"`
 	ConnectionInfo *database.ConnectionInfo      `json:"connection_info,omitempty"`
+	Pinned         bool                          `json:"pinned,omitempty"`
 }
 
-// LogsPageHandler 日志页面处理器
+// LogsPageHandler 日志页面处理器，view=compact 时使用紧凑视图
 func LogsPageHandler(c echo.Context) error {
-	// 读取日志页面模板
-	htmlContent, err := ioutil.ReadFile("templates/logs.html")
+	htmlContent, err := templates.RenderLogs(templates.LogsData{Compact: compactView(c)})
 	if err != nil {
 		return c.HTML(http.StatusInternalServerError, "日志页面加载失败")
 	}
 	
-	return c.HTML(http.StatusOK, string(htmlContent))
+	return c.HTML(http.StatusOK, htmlContent)
 }
 
 // LogSummaryResponse 日志摘要响应
@@ -61,7 +65,10 @@ type ErrorSummaryResponse struct {
 
 // GetLogsHandler 获取日志处理器
 func GetLogsHandler(c echo.Context) error {
-	logger := database.GetDatabaseLogger()
+	logger, ok := database.GetProfileRegistry().Logger(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
 	
 	// 获取查询参数
 	limitStr := c.QueryParam("limit")
@@ -84,40 +91,57 @@ func GetLogsHandler(c echo.Context) error {
 	// 获取最近的日志条目
 	entries := logger.GetRecentEntries(limit)
 	
-	// 按级别筛选
+	// 按级别、组件和外部来源筛选
+	componentFilter := database.LogComponent(c.QueryParam("component"))
+	sourceFilter := c.QueryParam("source")
 	var filteredEntries []database.LogEntry
-	if filterLevel != nil {
-		for _, entry := range entries {
-			if entry.Level == *filterLevel {
-				filteredEntries = append(filteredEntries, entry)
-			}
+	for _, entry := range entries {
+		if filterLevel != nil && entry.Level != *filterLevel {
+			continue
+		}
+		if componentFilter != "" && entry.Component != componentFilter {
+			continue
+		}
+		if sourceFilter != "" && entry.Source != sourceFilter {
+			continue
 		}
-	} else {
-		filteredEntries = entries
+		filteredEntries = append(filteredEntries, entry)
 	}
 	
-	// 转换为前端格式
+	// 转换为前端格式，同时找出最早的条目以叠加同一时间范围内的注释
 	var logEntries []LogEntry
+	oldest := time.Now()
 	for _, entry := range filteredEntries {
+		if entry.Timestamp.Before(oldest) {
+			oldest = entry.Timestamp
+		}
 		logEntries = append(logEntries, LogEntry{
+			ID:             entry.ID,
 			Level:          getLevelString(entry.Level),
 			Message:        entry.Message,
-			Timestamp:      entry.Timestamp.Format("2006-01-02 15:04:05"),
+			Timestamp:      config.FormatAPITime(entry.Timestamp),
 			Details:        entry.Details,
 			Count:          entry.Count,
+			Component:      string(entry.Component),
+			Source:         entry.Source,
 			ConnectionInfo: entry.ConnectionInfo,
+			Pinned:         entry.Pinned,
 		})
 	}
 	
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"logs":  logEntries,
-		"count": len(logEntries),
+		"logs":        logEntries,
+		"count":       len(logEntries),
+		"annotations": database.GetAnnotations().Between(oldest, time.Time{}, annotationProfile(c)),
 	})
 }
 
 // GetLogSummaryHandler 获取日志摘要处理器
 func GetLogSummaryHandler(c echo.Context) error {
-	logger := database.GetDatabaseLogger()
+	logger, ok := database.GetProfileRegistry().Logger(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
 	summary := logger.GetSummary()
 	
 	response := LogSummaryResponse{
@@ -130,6 +154,7 @@ func GetLogSummaryHandler(c echo.Context) error {
 }
 
 // SetLogLevelHandler 设置日志级别处理器
+// 指定 component 时只设置该组件的日志级别，level=inherit 取消组件的设置，恢复使用全局日志级别
 func SetLogLevelHandler(c echo.Context) error {
 	levelStr := c.QueryParam("level")
 	if levelStr == "" {
@@ -138,6 +163,30 @@ func SetLogLevelHandler(c echo.Context) error {
 		})
 	}
 	
+	logger, ok := database.GetProfileRegistry().Logger(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
+
+	var component database.LogComponent
+	if name := c.QueryParam("component"); name != "" {
+		parsed, err := database.ParseLogComponent(name)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		component = parsed
+	}
+	if component != "" && levelStr == "inherit" {
+		logger.ResetComponentLogLevel(component)
+		return c.JSON(http.StatusOK, map[string]string{
+			"message":   "component log level reset",
+			"component": string(component),
+			"level":     getLevelString(logger.LogLevel()),
+		})
+	}
+	
 	level, exists := logLevelMap[levelStr]
 	if !exists {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -145,7 +194,14 @@ func SetLogLevelHandler(c echo.Context) error {
 		})
 	}
 	
-	logger := database.GetDatabaseLogger()
+	if component != "" {
+		logger.SetComponentLogLevel(component, level)
+		return c.JSON(http.StatusOK, map[string]string{
+			"message":   "component log level updated",
+			"component": string(component),
+			"level":     levelStr,
+		})
+	}
 	logger.SetLogLevel(level)
 	
 	return c.JSON(http.StatusOK, map[string]string{
@@ -154,19 +210,51 @@ func SetLogLevelHandler(c echo.Context) error {
 	})
 }
 
+// GetLogLevelHandler 获取全局日志级别和各组件的日志级别，未单独设置的组件使用全局日志级别
+func GetLogLevelHandler(c echo.Context) error {
+	logger, ok := database.GetProfileRegistry().Logger(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
+	global := logger.LogLevel()
+	overrides := logger.ComponentLogLevels()
+	components := make(map[string]interface{}, len(database.LogComponents))
+	for _, component := range database.LogComponents {
+		level, overridden := overrides[component]
+		if !overridden {
+			level = global
+		}
+		components[string(component)] = map[string]interface{}{
+			"level":      getLevelString(level),
+			"overridden": overridden,
+		}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"level":      getLevelString(global),
+		"components": components,
+	})
+}
+
 // ClearLogsHandler 清空日志处理器
 func ClearLogsHandler(c echo.Context) error {
-	logger := database.GetDatabaseLogger()
-	logger.Clear()
+	logger, ok := database.GetProfileRegistry().Logger(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
+	kept := logger.Clear()
 	
-	return c.JSON(http.StatusOK, map[string]string{
+	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message": "logs cleared",
+		"kept":    kept,
 	})
 }
 
 // GetErrorSummariesHandler 获取错误摘要处理器
 func GetErrorSummariesHandler(c echo.Context) error {
-	analyzer := database.GetErrorAnalyzer()
+	analyzer, ok := database.GetProfileRegistry().ErrorAnalyzer(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
 	summaries := analyzer.GetErrorSummaries()
 	
 	var response []ErrorSummaryResponse
@@ -175,8 +263,8 @@ func GetErrorSummariesHandler(c echo.Context) error {
 			Type:          string(summary.Type),
 			Code:          summary.Code,
 			Count:         summary.Count,
-			FirstSeen:     summary.FirstSeen.Format("2006-01-02 15:04:05"),
-			LastSeen:      summary.LastSeen.Format("2006-01-02 15:04:05"),
+			FirstSeen:     config.FormatAPITime(summary.FirstSeen),
+			LastSeen:      config.FormatAPITime(summary.LastSeen),
 			FrequencyData: summary.FrequencyData,
 			Examples:      summary.Examples,
 			Resolved:      summary.Resolved,
@@ -196,7 +284,10 @@ func GetTopErrorsHandler(c echo.Context) error {
 		}
 	}
 	
-	analyzer := database.GetErrorAnalyzer()
+	analyzer, ok := database.GetProfileRegistry().ErrorAnalyzer(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
 	topErrors := analyzer.GetTopErrors(limit)
 	
 	var response []ErrorSummaryResponse
@@ -205,8 +296,8 @@ func GetTopErrorsHandler(c echo.Context) error {
 			Type:          string(summary.Type),
 			Code:          summary.Code,
 			Count:         summary.Count,
-			FirstSeen:     summary.FirstSeen.Format("2006-01-02 15:04:05"),
-			LastSeen:      summary.LastSeen.Format("2006-01-02 15:04:05"),
+			FirstSeen:     config.FormatAPITime(summary.FirstSeen),
+			LastSeen:      config.FormatAPITime(summary.LastSeen),
 			FrequencyData: summary.FrequencyData,
 			Examples:      summary.Examples,
 			Resolved:      summary.Resolved,
@@ -218,8 +309,14 @@ func GetTopErrorsHandler(c echo.Context) error {
 
 // GetErrorTrendsHandler 获取错误趋势处理器
 func GetErrorTrendsHandler(c echo.Context) error {
-	analyzer := database.GetErrorAnalyzer()
+	analyzer, ok := database.GetProfileRegistry().ErrorAnalyzer(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
 	trends := analyzer.GetErrorTrends()
+	// 叠加按小时统计范围内的注释，便于把错误变化与发布、演练等操作对应起来
+	since := time.Now().Add(-config.GetErrorAnalysisConfig().HourlyRetention)
+	trends["annotations"] = database.GetAnnotations().Between(since, time.Time{}, annotationProfile(c))
 	
 	return c.JSON(http.StatusOK, trends)
 }
@@ -235,7 +332,10 @@ func MarkErrorResolvedHandler(c echo.Context) error {
 		})
 	}
 	
-	analyzer := database.GetErrorAnalyzer()
+	analyzer, ok := database.GetProfileRegistry().ErrorAnalyzer(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
 	analyzer.MarkErrorResolved(database.ErrorType(errorType), code)
 	
 	return c.JSON(http.StatusOK, map[string]string{
@@ -255,7 +355,10 @@ func ClearOldErrorsHandler(c echo.Context) error {
 		}
 	}
 	
-	analyzer := database.GetErrorAnalyzer()
+	analyzer, ok := database.GetProfileRegistry().ErrorAnalyzer(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
 	cleared := analyzer.ClearOldErrors(time.Duration(hours) * time.Hour)
 	
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -281,4 +384,12 @@ func getLevelString(level database.LogLevel) string {
 	default:
 		return "unknown"
 	}
-}
\ No newline at end of file
+}
+
+// profileNotFound 返回 profile 参数指定的服务器不存在
+// 日志和错误分析接口通过 profile 参数指定服务器，为空时为默认连接
+func profileNotFound(c echo.Context) error {
+	return c.JSON(http.StatusNotFound, map[string]string{
+		"error": "profile not found",
+	})
+}
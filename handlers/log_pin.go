@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PinLogHandler 固定日志条目，固定的条目在清空日志和淘汰时保留
+func PinLogHandler(c echo.Context) error {
+	return setLogPinned(c, true)
+}
+
+// UnpinLogHandler 取消固定日志条目
+func UnpinLogHandler(c echo.Context) error {
+	return setLogPinned(c, false)
+}
+
+// setLogPinned 按路径参数 id 固定或取消固定日志条目
+func setLogPinned(c echo.Context, pinned bool) error {
+	logger, ok := database.GetProfileRegistry().Logger(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid id",
+		})
+	}
+
+	if pinned {
+		err = logger.Pin(id)
+	} else {
+		err = logger.Unpin(id)
+	}
+	switch {
+	case errors.Is(err, database.ErrLogEntryNotFound):
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "日志条目不存在或已被淘汰",
+		})
+	case errors.Is(err, database.ErrTooManyPinned):
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"error": "固定的日志条目已达上限 (LOG_MAX_PINNED)，请先取消固定其他条目",
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id":     id,
+		"pinned": pinned,
+	})
+}
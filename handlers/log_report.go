@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultReportLogLimit 日志报告默认包含的日志条数
+const defaultReportLogLimit = 200
+
+// reportLevels 日志报告摘要中展示的级别及名称
+var reportLevels = []struct {
+	key   string
+	label string
+}{
+	{"fatal", "致命"},
+	{"error", "错误"},
+	{"warn", "警告"},
+	{"info", "信息"},
+	{"debug", "调试"},
+}
+
+// LogsReportHandler 导出日志报告处理器
+// 将最近的日志、错误摘要和错误趋势渲染为单个 HTML 文件下载，样式内联且不含脚本，可作为故障邮件附件离线查看
+// profile 指定服务器，limit 为日志条数，默认 200
+func LogsReportHandler(c echo.Context) error {
+	profile := c.QueryParam("profile")
+	logger, ok := database.GetProfileRegistry().Logger(profile)
+	if !ok {
+		return profileNotFound(c)
+	}
+	analyzer, _ := database.GetProfileRegistry().ErrorAnalyzer(profile)
+	if profile == "" {
+		profile = database.DefaultProfile
+	}
+
+	limit := defaultReportLogLimit
+	if v := c.QueryParam("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid limit: " + v,
+			})
+		}
+		limit = parsed
+	}
+
+	now := time.Now()
+	data := templates.LogsReportData{
+		Profile:   profile,
+		Generated: config.FormatDisplayTime(now),
+		Limit:     limit,
+	}
+
+	summary := logger.GetSummary()
+	data.TotalEntries, _ = summary["total_entries"].(int)
+	levelCounts, _ := summary["level_counts"].(map[string]int)
+	for _, level := range reportLevels {
+		data.LevelCounts = append(data.LevelCounts, templates.ReportBar{Label: level.label, Count: levelCounts[level.key]})
+	}
+
+	var entries []LogEntry
+	for _, entry := range logger.GetRecentEntries(limit) {
+		entries = append(entries, LogEntry{
+			ID:             entry.ID,
+			Level:          getLevelString(entry.Level),
+			Message:        entry.Message,
+			Timestamp:      config.FormatDisplayTime(entry.Timestamp),
+			Details:        entry.Details,
+			Count:          entry.Count,
+			Component:      string(entry.Component),
+			Source:         entry.Source,
+			ConnectionInfo: entry.ConnectionInfo,
+			Pinned:         entry.Pinned,
+		})
+	}
+	data.Entries = entries
+
+	var summaries []ErrorSummaryResponse
+	for _, s := range analyzer.GetErrorSummaries() {
+		summaries = append(summaries, ErrorSummaryResponse{
+			Type:      string(s.Type),
+			Code:      s.Code,
+			Count:     s.Count,
+			FirstSeen: config.FormatDisplayTime(s.FirstSeen),
+			LastSeen:  config.FormatDisplayTime(s.LastSeen),
+			Examples:  s.Examples,
+			Resolved:  s.Resolved,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Count != summaries[j].Count {
+			return summaries[i].Count > summaries[j].Count
+		}
+		return summaries[i].LastSeen > summaries[j].LastSeen
+	})
+	data.Errors = summaries
+
+	trends := analyzer.GetErrorTrends()
+	data.TotalErrors, _ = trends["total_errors"].(int)
+	data.ResolvedCount, _ = trends["resolved_count"].(int)
+	data.Anomalies = trends["anomalies"]
+	hourly, _ := trends["hourly_data"].(map[string]int)
+	data.Hourly = hourlyBars(hourly, now)
+	from := now.Add(-23 * time.Hour).Truncate(time.Hour)
+	var outages []database.Outage
+	if reconnector, ok := database.GetProfileRegistry().Reconnector(profile); ok {
+		outages = reconnector.Outages(from)
+	}
+	annotations := database.GetAnnotations().Between(from, now, profile)
+	if len(outages) > 0 {
+		data.Outages = outages
+	}
+	if len(annotations) > 0 {
+		data.Annotations = annotations
+	}
+	if len(outages) > 0 || len(annotations) > 0 {
+		trend := database.CorrelateOutages(hourly, outages, from, now)
+		database.OverlayAnnotations(trend, annotations)
+		markTrendHours(data.Hourly, trend, now)
+	}
+	if daily, ok := trends["daily_data"].(map[string]int); ok {
+		data.Daily = dailyBars(daily)
+	}
+
+	html, err := templates.RenderLogsReport(data)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": "模板渲染错误: " + err.Error(),
+		})
+	}
+	filename := "logs-report-" + profile + "-" + now.Format("20060102-150405") + ".html"
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.HTML(http.StatusOK, html)
+}
+
+// hourlyBars 最近 24 小时每小时的错误数，没有错误的小时也保留以便看出时间分布
+// 全部为 0 时返回 nil
+func hourlyBars(hourly map[string]int, now time.Time) []templates.ReportBar {
+	bars := make([]templates.ReportBar, 0, 24)
+	total := 0
+	for i := 23; i >= 0; i-- {
+		hour := now.Add(-time.Duration(i) * time.Hour)
+		count := hourly[hour.Format("2006-01-02-15")]
+		total += count
+		bars = append(bars, templates.ReportBar{Label: hour.Format("01-02 15:00"), Count: count})
+	}
+	if total == 0 {
+		return nil
+	}
+	return scaleBars(bars)
+}
+
+// markTrendHours 在每小时错误数的条形图上标出该小时内的连接中断时长和注释编号，bars 与 hourlyBars 的小时一一对应
+func markTrendHours(bars []templates.ReportBar, hours []database.TrendHour, now time.Time) {
+	byHour := make(map[string]database.TrendHour, len(hours))
+	for _, h := range hours {
+		byHour[h.Hour] = h
+	}
+	for i := range bars {
+		hour := now.Add(-time.Duration(len(bars)-1-i) * time.Hour)
+		h := byHour[hour.Format("2006-01-02-15")]
+		var notes []string
+		if h.OutageSeconds > 0 {
+			notes = append(notes, fmt.Sprintf("中断 %v", time.Duration(h.OutageSeconds)*time.Second))
+		}
+		for _, id := range h.Annotations {
+			notes = append(notes, fmt.Sprintf("注释 #%d", id))
+		}
+		bars[i].Note = strings.Join(notes, ", ")
+	}
+}
+
+// dailyBars 按日期升序排列的每日错误数
+func dailyBars(daily map[string]int) []templates.ReportBar {
+	bars := make([]templates.ReportBar, 0, len(daily))
+	for day, count := range daily {
+		bars = append(bars, templates.ReportBar{Label: day, Count: count})
+	}
+	sort.Slice(bars, func(i, j int) bool { return bars[i].Label < bars[j].Label })
+	return scaleBars(bars)
+}
+
+// scaleBars 按最大计数计算各项的百分比
+func scaleBars(bars []templates.ReportBar) []templates.ReportBar {
+	peak := 0
+	for _, bar := range bars {
+		if bar.Count > peak {
+			peak = bar.Count
+		}
+	}
+	if peak == 0 {
+		return bars
+	}
+	for i := range bars {
+		bars[i].Percent = float64(bars[i].Count) * 100 / float64(peak)
+	}
+	return bars
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maintenanceRequest 创建维护窗口的请求体
+// start 为空时从当前时间开始；end 与 duration 二选一
+type maintenanceRequest struct {
+	Start    string `json:"start" form:"start"`
+	End      string `json:"end" form:"end"`
+	Duration string `json:"duration" form:"duration"`
+	Profile  string `json:"profile" form:"profile"`
+	Reason   string `json:"reason" form:"reason"`
+}
+
+// MaintenancePageHandler 维护窗口页面处理器
+func MaintenancePageHandler(c echo.Context) error {
+	manager := database.GetMaintenanceManager()
+	data := templates.MaintenanceData{
+		Windows: manager.List(),
+		Active:  manager.Active(database.DefaultProfile),
+	}
+
+	html, err := templates.RenderMaintenance(data)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
+
+// APIMaintenanceListHandler API 维护窗口列表处理器
+func APIMaintenanceListHandler(c echo.Context) error {
+	manager := database.GetMaintenanceManager()
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"windows": manager.List(),
+		"active":  manager.Active(database.DefaultProfile),
+	})
+}
+
+// APIMaintenanceCreateHandler API 创建维护窗口处理器
+func APIMaintenanceCreateHandler(c echo.Context) error {
+	var req maintenanceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid request body",
+		})
+	}
+
+	start := time.Now()
+	if req.Start != "" {
+		t, err := parseWindowTime(req.Start)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid start: " + err.Error(),
+			})
+		}
+		start = t
+	}
+
+	var end time.Time
+	switch {
+	case req.End != "":
+		t, err := parseWindowTime(req.End)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid end: " + err.Error(),
+			})
+		}
+		end = t
+	case req.Duration != "":
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid duration: " + err.Error(),
+			})
+		}
+		end = start.Add(d)
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "end or duration is required",
+		})
+	}
+
+	window, err := database.GetMaintenanceManager().Add(start, end, req.Profile, req.Reason)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusCreated, window)
+}
+
+// APIMaintenanceDeleteHandler API 删除维护窗口处理器
+func APIMaintenanceDeleteHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid id",
+		})
+	}
+	if !database.GetMaintenanceManager().Remove(id) {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "maintenance window not found",
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// parseWindowTime 解析 RFC 3339 时间或浏览器 datetime-local 格式 (按本地时区)
+func parseWindowTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02T15:04", value, time.Local)
+}
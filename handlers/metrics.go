@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/checks"
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// metricPrefix 导出指标名称的前缀
+const metricPrefix = "blockchecker_mysql_"
+
+// MetricsHandler Prometheus 指标处理器，以文本格式输出压力采样器的计数器累计值和最近一次增量
+// 启用 TABLE_METRICS_ENABLED 时同时输出按表的统计
+func MetricsHandler(c echo.Context) error {
+	tracker := database.GetPressureTracker()
+	totals := tracker.Totals()
+	samples := tracker.GetSamples()
+
+	var b strings.Builder
+	for _, counter := range database.PressureCounters {
+		name := metricPrefix + strings.ToLower(counter)
+		if total, ok := totals[counter]; ok {
+			fmt.Fprintf(&b, "# HELP %s_total MySQL global status %s.\n", name, counter)
+			fmt.Fprintf(&b, "# TYPE %s_total counter\n", name)
+			fmt.Fprintf(&b, "%s_total %d\n", name, total)
+		}
+		if len(samples) > 0 {
+			last := samples[len(samples)-1]
+			fmt.Fprintf(&b, "# HELP %s_delta Increase of %s over the last sampling interval.\n", name, counter)
+			fmt.Fprintf(&b, "# TYPE %s_delta gauge\n", name)
+			fmt.Fprintf(&b, "%s_delta %d\n", name, last.Deltas[counter])
+		}
+	}
+	if len(samples) > 0 {
+		fmt.Fprintf(&b, "# HELP %spressure_sample_interval_seconds Length of the last sampling interval.\n", metricPrefix)
+		fmt.Fprintf(&b, "# TYPE %spressure_sample_interval_seconds gauge\n", metricPrefix)
+		fmt.Fprintf(&b, "%spressure_sample_interval_seconds %g\n", metricPrefix, samples[len(samples)-1].Seconds)
+	}
+
+	writeProfileMetrics(&b)
+	writeCanaryMetrics(&b)
+	writeGrowthMetrics(&b)
+
+	if cfg := config.GetTableMetricsConfig(); cfg.Enabled {
+		if err := writeTableMetrics(c, &b, cfg); err != nil {
+			log.Printf("Failed to collect table metrics: %v", err)
+		}
+	}
+
+	return c.Blob(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
+
+// writeTableMetrics 输出按表的行数 (估算)、数据大小和索引大小，仅包含白名单内的表
+// 匹配的表超过 MaxTables 时按名称排序截断，截断的表数通过 dropped 指标报告
+func writeTableMetrics(c echo.Context, b *strings.Builder, cfg *config.TableMetricsConfig) error {
+	stats, err := store.GetTableStats(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	for key := range stats {
+		if tableAllowed(key, cfg.Allowlist) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	dropped := 0
+	if cfg.MaxTables > 0 && len(keys) > cfg.MaxTables {
+		dropped = len(keys) - cfg.MaxTables
+		keys = keys[:cfg.MaxTables]
+	}
+
+	series := []struct {
+		name  string
+		help  string
+		value func(database.TableStat) int64
+	}{
+		{"blockchecker_table_rows", "Estimated number of rows (information_schema.TABLES.TABLE_ROWS).", func(s database.TableStat) int64 { return s.Rows }},
+		{"blockchecker_table_data_bytes", "Table data size in bytes.", func(s database.TableStat) int64 { return s.DataSize }},
+		{"blockchecker_table_index_bytes", "Table index size in bytes.", func(s database.TableStat) int64 { return s.IndexSize }},
+	}
+	for _, s := range series {
+		fmt.Fprintf(b, "# HELP %s %s\n", s.name, s.help)
+		fmt.Fprintf(b, "# TYPE %s gauge\n", s.name)
+		for _, key := range keys {
+			dbName, table, _ := strings.Cut(key, ".")
+			fmt.Fprintf(b, "%s{database=\"%s\",table=\"%s\"} %d\n", s.name, escapeLabel(dbName), escapeLabel(table), s.value(stats[key]))
+		}
+	}
+	fmt.Fprintf(b, "# HELP blockchecker_table_metrics_dropped Tables matched by the allowlist but not exported due to TABLE_METRICS_MAX_TABLES.\n")
+	fmt.Fprintf(b, "# TYPE blockchecker_table_metrics_dropped gauge\n")
+	fmt.Fprintf(b, "blockchecker_table_metrics_dropped %d\n", dropped)
+	return nil
+}
+
+// tableAllowed 判断 db.table 是否匹配白名单中的任一模式，白名单为空时全部允许
+func tableAllowed(key string, allowlist []string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	for _, pattern := range allowlist {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeLabel 转义标签值中的反斜杠、双引号和换行
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// writeGrowthMetrics 输出按表增长趋势预测的磁盘写满天数，未配置 DISK_CAPACITY_GB 或数据量没有增长时不输出
+func writeGrowthMetrics(b *strings.Builder) {
+	forecasts := database.GetGrowthTracker().Forecast()
+	if len(forecasts) == 0 || forecasts[0].DaysUntilFull == nil {
+		return
+	}
+	total := forecasts[0]
+	fmt.Fprintf(b, "# HELP %sdisk_days_until_full Days until DISK_CAPACITY_GB is reached, by linear projection of table growth.\n", metricPrefix)
+	fmt.Fprintf(b, "# TYPE %sdisk_days_until_full gauge\n", metricPrefix)
+	fmt.Fprintf(b, "%sdisk_days_until_full %g\n", metricPrefix, *total.DaysUntilFull)
+	fmt.Fprintf(b, "# HELP %sdisk_growth_bytes_per_day Growth of data and index size per day, by linear fit of table growth samples.\n", metricPrefix)
+	fmt.Fprintf(b, "# TYPE %sdisk_growth_bytes_per_day gauge\n", metricPrefix)
+	fmt.Fprintf(b, "%sdisk_growth_bytes_per_day %g\n", metricPrefix, total.GrowthPerDay)
+}
+
+// writeCanaryMetrics 输出最近一次写入探测的结果和各步骤耗时，未开启或尚未运行时不输出
+func writeCanaryMetrics(b *strings.Builder) {
+	result := database.GetCanary().LastResult()
+	if result == nil {
+		return
+	}
+	success := 0
+	if result.Status == checks.StatusOK || result.Status == checks.StatusWarning {
+		success = 1
+	}
+	fmt.Fprintf(b, "# HELP %scanary_success Whether the last canary insert/read/delete succeeded.\n", metricPrefix)
+	fmt.Fprintf(b, "# TYPE %scanary_success gauge\n", metricPrefix)
+	fmt.Fprintf(b, "%scanary_success %d\n", metricPrefix, success)
+	fmt.Fprintf(b, "# HELP %scanary_last_run_timestamp_seconds Unix time of the last canary run.\n", metricPrefix)
+	fmt.Fprintf(b, "# TYPE %scanary_last_run_timestamp_seconds gauge\n", metricPrefix)
+	fmt.Fprintf(b, "%scanary_last_run_timestamp_seconds %d\n", metricPrefix, result.Time.Unix())
+
+	if _, ok := result.Details["write_ms"]; !ok {
+		return
+	}
+	fmt.Fprintf(b, "# HELP %scanary_latency_seconds Duration of each step of the last canary run.\n", metricPrefix)
+	fmt.Fprintf(b, "# TYPE %scanary_latency_seconds gauge\n", metricPrefix)
+	for _, step := range []string{"write", "read", "delete"} {
+		if ms, ok := result.Details[step+"_ms"].(float64); ok {
+			fmt.Fprintf(b, "%scanary_latency_seconds{step=%q} %g\n", metricPrefix, step, ms/1000)
+		}
+	}
+}
+
+// writeProfileMetrics 输出各服务器的连接状态、重连次数和错误数，按 profile 标签区分
+func writeProfileMetrics(b *strings.Builder) {
+	registry := database.GetProfileRegistry()
+	statuses := registry.Statuses()
+
+	fmt.Fprintf(b, "# HELP %sprofile_up Whether the last check of the profile succeeded.\n", metricPrefix)
+	fmt.Fprintf(b, "# TYPE %sprofile_up gauge\n", metricPrefix)
+	for _, s := range statuses {
+		up := 0
+		if s.Status == database.ProfileStatusOK {
+			up = 1
+		}
+		fmt.Fprintf(b, "%sprofile_up{profile=%q} %d\n", metricPrefix, s.Name, up)
+	}
+
+	fmt.Fprintf(b, "# HELP %sprofile_reconnect_retries Reconnection attempts since the profile lost its connection.\n", metricPrefix)
+	fmt.Fprintf(b, "# TYPE %sprofile_reconnect_retries gauge\n", metricPrefix)
+	for _, s := range statuses {
+		if reconnector, ok := registry.Reconnector(s.Name); ok {
+			fmt.Fprintf(b, "%sprofile_reconnect_retries{profile=%q} %d\n", metricPrefix, s.Name, reconnector.GetRetryCount())
+		}
+	}
+
+	fmt.Fprintf(b, "# HELP %sprofile_errors_total Connection errors analyzed for the profile.\n", metricPrefix)
+	fmt.Fprintf(b, "# TYPE %sprofile_errors_total counter\n", metricPrefix)
+	for _, s := range statuses {
+		analyzer, _ := registry.ErrorAnalyzer(s.Name)
+		total := 0
+		for _, summary := range analyzer.GetErrorSummaries() {
+			total += summary.Count
+		}
+		fmt.Fprintf(b, "%sprofile_errors_total{profile=%q} %d\n", metricPrefix, s.Name, total)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// JSONAlternative 页面路由的内容协商中间件，请求头 Accept 优先 application/json 时改由对应的 API 处理器响应
+// API 处理器与页面路由使用相同的路径参数名
+func JSONAlternative(api echo.HandlerFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if prefers(c.Request(), "application/json", "text/html") {
+				return api(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// HTMLErrors API 路由的内容协商中间件，浏览器 (Accept 优先 text/html) 访问出错时以错误页面代替 JSON 错误
+// 只缓冲状态码不低于 400 的响应，成功响应 (包括下载) 直接写出
+func HTMLErrors(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !strings.HasPrefix(c.Path(), "/api/") || !prefers(c.Request(), "text/html", "application/json") {
+			return next(c)
+		}
+
+		res := c.Response()
+		w := &errorCapture{ResponseWriter: res.Writer}
+		res.Writer = w
+		err := next(c)
+		res.Writer = w.ResponseWriter
+		if !w.capturing {
+			return err
+		}
+
+		message := strings.TrimSpace(w.body.String())
+		var body map[string]interface{}
+		if json.Unmarshal(w.body.Bytes(), &body) == nil {
+			if msg, ok := body["error"].(string); ok {
+				message = msg
+			}
+		}
+		html, renderErr := templates.RenderError(templates.ErrorData{
+			Title:   errorTitle(w.status),
+			Message: message,
+		})
+		if renderErr != nil {
+			w.ResponseWriter.WriteHeader(w.status)
+			_, writeErr := w.ResponseWriter.Write(w.body.Bytes())
+			return writeErr
+		}
+		w.ResponseWriter.Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+		w.ResponseWriter.Header().Del(echo.HeaderContentLength)
+		w.ResponseWriter.WriteHeader(w.status)
+		_, writeErr := w.ResponseWriter.Write([]byte(html))
+		return writeErr
+	}
+}
+
+// errorCapture 缓冲错误响应的 ResponseWriter
+type errorCapture struct {
+	http.ResponseWriter
+	status    int
+	capturing bool
+	body      bytes.Buffer
+}
+
+// WriteHeader 错误状态码时开始缓冲，否则直接写出
+func (w *errorCapture) WriteHeader(status int) {
+	if status >= http.StatusBadRequest {
+		w.status = status
+		w.capturing = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write 写入响应体
+func (w *errorCapture) Write(b []byte) (int, error) {
+	if w.capturing {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 支持流式响应
+func (w *errorCapture) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && !w.capturing {
+		f.Flush()
+	}
+}
+
+// prefers 判断请求头 Accept 中 want 是否出现且排在 other 之前
+func prefers(r *http.Request, want, other string) bool {
+	accept := strings.ToLower(r.Header.Get(echo.HeaderAccept))
+	i := strings.Index(accept, want)
+	if i < 0 {
+		return false
+	}
+	j := strings.Index(accept, other)
+	return j < 0 || i < j
+}
+
+// errorTitle 错误页面标题
+func errorTitle(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "参数错误"
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "权限不足"
+	case http.StatusNotFound:
+		return "未找到"
+	case http.StatusConflict:
+		return "操作冲突"
+	case http.StatusServiceUnavailable:
+		return "服务暂不可用"
+	case http.StatusGatewayTimeout:
+		return "查询超时"
+	default:
+		return "请求失败"
+	}
+}
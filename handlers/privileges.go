@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UsersPageHandler 账号权限页面处理器
+func UsersPageHandler(c echo.Context) error {
+	users, err := store.GetUsers(c.Request().Context())
+	if err != nil {
+		if database.IsTimeout(err) {
+			return renderTimeoutError(c)
+		}
+		data := templates.ErrorData{
+			Title:   "获取账号列表失败",
+			Message: err.Error(),
+		}
+		html, _ := templates.RenderError(data)
+		return c.HTML(http.StatusInternalServerError, html)
+	}
+
+	html, err := templates.RenderUsers(templates.UsersData{Users: users})
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
+
+// APIUsersHandler API 账号权限列表处理器
+func APIUsersHandler(c echo.Context) error {
+	users, err := store.GetUsers(c.Request().Context())
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"users": users,
+	})
+}
+
+// APIGrantsHandler API 数据库 (或表) 访问权限处理器
+func APIGrantsHandler(c echo.Context) error {
+	databaseName := c.Param("database")
+	if databaseName == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "数据库名称不能为空",
+		})
+	}
+	tableName := c.QueryParam("table")
+
+	grants, err := store.GetGrants(c.Request().Context(), databaseName, tableName)
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"database": databaseName,
+		"table":    tableName,
+		"grants":   grants,
+	})
+}
+
+// lookupGrants 为页面获取访问权限，仅对操作员展示，失败时不影响页面渲染
+func lookupGrants(c echo.Context, databaseName, tableName string) []database.ObjectGrant {
+	if !IsOperator(c) {
+		return nil
+	}
+	grants, err := store.GetGrants(c.Request().Context(), databaseName, tableName)
+	if err != nil {
+		log.Printf("Failed to lookup grants for %s.%s: %v", databaseName, tableName, err)
+		return nil
+	}
+	return grants
+}
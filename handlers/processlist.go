@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 进程列表跟踪的采样间隔范围
+const (
+	minTailInterval = time.Second
+	maxTailInterval = time.Minute
+)
+
+// activeTails 正在进行的进程列表跟踪数
+var activeTails int32
+
+// ProcessListPageHandler 进程列表实时跟踪页面处理器
+func ProcessListPageHandler(c echo.Context) error {
+	html, err := templates.RenderProcessList(templates.ProcessListData{
+		Interval: config.GetProcessListConfig().TailInterval.String(),
+	})
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
+
+// APIProcessListHandler API 进程列表处理器
+func APIProcessListHandler(c echo.Context) error {
+	processes, err := store.GetProcessList(c.Request().Context())
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"processes": processes,
+		"count":     len(processes),
+	})
+}
+
+// APIProcessListTailHandler API 进程列表跟踪处理器，以 SSE 推送进程列表的变化
+// 连接后先发送 snapshot 事件 (完整列表)，之后每个间隔比较一次，有变化时发送 diff 事件 (新增、结束和状态变化的会话)
+// 查询失败时发送 error 事件并继续跟踪；参数 interval 为采样间隔 (1s ~ 1m)
+func APIProcessListTailHandler(c echo.Context) error {
+	cfg := config.GetProcessListConfig()
+	interval := cfg.TailInterval
+	if v := c.QueryParam("interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < minTailInterval || d > maxTailInterval {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": fmt.Sprintf("interval 必须在 %v 到 %v 之间", minTailInterval, maxTailInterval),
+			})
+		}
+		interval = d
+	}
+
+	if n := atomic.AddInt32(&activeTails, 1); cfg.MaxTails > 0 && int(n) > cfg.MaxTails {
+		atomic.AddInt32(&activeTails, -1)
+		return c.JSON(http.StatusTooManyRequests, map[string]interface{}{
+			"error": "进程列表跟踪的客户端过多",
+		})
+	}
+	defer atomic.AddInt32(&activeTails, -1)
+
+	ctx := c.Request().Context()
+	processes, err := store.GetProcessList(ctx)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	send := func(event string, data interface{}) error {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+			return err
+		}
+		res.Flush()
+		return nil
+	}
+
+	if err := send("snapshot", map[string]interface{}{
+		"at":        time.Now(),
+		"interval":  interval.String(),
+		"processes": processes,
+	}); err != nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := store.GetProcessList(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if send("error", map[string]interface{}{"at": time.Now(), "error": err.Error()}) != nil {
+				return nil
+			}
+			continue
+		}
+
+		diff := database.DiffProcessList(processes, current)
+		processes = current
+		if diff.Empty() {
+			// 保持连接，避免被代理判定为空闲
+			if _, err := fmt.Fprint(res, ": keepalive\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+			continue
+		}
+		if send("diff", map[string]interface{}{
+			"at":      time.Now(),
+			"started": diff.Started,
+			"ended":   diff.Ended,
+			"changed": diff.Changed,
+			"total":   len(current),
+		}) != nil {
+			return nil
+		}
+	}
+}
+
+// SessionPageHandler 会话详情页面处理器，展示会话的当前事务、持有的锁和会话变量
+func SessionPageHandler(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		html, _ := templates.RenderError(templates.ErrorData{
+			Title:   "参数错误",
+			Message: "会话 ID 必须为正整数",
+		})
+		return c.HTML(http.StatusBadRequest, html)
+	}
+
+	detail, err := store.GetSessionDetail(c.Request().Context(), id)
+	if err != nil {
+		if database.IsTimeout(err) {
+			return renderTimeoutError(c)
+		}
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		html, _ := templates.RenderError(templates.ErrorData{
+			Title:   "获取会话详情失败",
+			Message: err.Error(),
+		})
+		return c.HTML(status, html)
+	}
+
+	html, err := templates.RenderSession(templates.SessionData{Detail: detail})
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
+
+// APISessionHandler API 会话详情处理器
+func APISessionHandler(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "invalid session id",
+		})
+	}
+
+	detail, err := store.GetSessionDetail(c.Request().Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrSessionNotFound):
+			return c.JSON(http.StatusNotFound, map[string]interface{}{
+				"error": err.Error(),
+			})
+		case database.IsTimeout(err):
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, detail)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/furutachiKurea/block-checker/pwa"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FaviconHandler 站点图标
+func FaviconHandler(c echo.Context) error {
+	return servePWAAsset(c, "favicon.ico", "public, max-age=86400")
+}
+
+// ManifestHandler PWA 清单
+func ManifestHandler(c echo.Context) error {
+	return servePWAAsset(c, "manifest.webmanifest", "public, max-age=3600")
+}
+
+// ServiceWorkerHandler service worker 脚本
+// 需由根路径提供以覆盖整个站点，不缓存以便浏览器及时获取新版本
+func ServiceWorkerHandler(c echo.Context) error {
+	return servePWAAsset(c, "sw.js", "no-cache")
+}
+
+// IconHandler PWA 图标
+func IconHandler(c echo.Context) error {
+	data, contentType, ok := pwa.Icon(c.Param("file"))
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "图标不存在",
+		})
+	}
+	c.Response().Header().Set("Cache-Control", "public, max-age=86400")
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
+// servePWAAsset 返回内嵌资源
+func servePWAAsset(c echo.Context, name, cacheControl string) error {
+	data, contentType, ok := pwa.Asset(name)
+	if !ok {
+		return c.NoContent(http.StatusNotFound)
+	}
+	c.Response().Header().Set("Cache-Control", cacheControl)
+	return c.Blob(http.StatusOK, contentType, data)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// QueryBudget 统计每个请求执行的数据库查询次数与耗时
+// 超过配置的次数或耗时阈值时记录带语句明细的警告，调试模式下通过 X-DB-Queries 响应头返回统计
+// http 组件的日志级别为调试时记录每个请求的状态码、耗时和查询统计
+func QueryBudget(cfg *config.QueryBudgetConfig) echo.MiddlewareFunc {
+	logger := database.GetDatabaseLogger().Component(database.ComponentHTTP)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			started := time.Now()
+			ctx, stats := database.WithQueryStats(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+			if cfg.Debug {
+				c.Response().Before(func() {
+					c.Response().Header().Set("X-DB-Queries", fmt.Sprintf("count=%d, total=%v", stats.Count(), stats.Total().Round(time.Microsecond)))
+				})
+			}
+
+			err := next(c)
+
+			// 事件流在客户端断开前持续查询，不适用单个请求的预算
+			if strings.HasPrefix(c.Response().Header().Get(echo.HeaderContentType), "text/event-stream") {
+				return err
+			}
+			count, total := stats.Count(), stats.Total()
+			if (cfg.MaxQueries > 0 && count > cfg.MaxQueries) || (cfg.MaxTime > 0 && total > cfg.MaxTime) {
+				logger.Warn(
+					fmt.Sprintf("请求 %s %s 执行了 %d 次查询，累计 %v", c.Request().Method, c.Path(), count, total.Round(time.Millisecond)),
+					stats.String())
+			} else if logger.Enabled(database.LogLevelDebug) {
+				logger.Debug(
+					fmt.Sprintf("请求 %s %s 返回 %d，耗时 %v，%d 次查询", c.Request().Method, c.Request().URL.Path,
+						c.Response().Status, time.Since(started).Round(time.Millisecond), count),
+					stats.String())
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultReconnectHistoryLimit 连接历史页面展示的重连尝试数
+const defaultReconnectHistoryLimit = 200
+
+// reconnectErrorGroup 按错误代码汇总的失败次数
+type reconnectErrorGroup struct {
+	Code  string
+	Type  database.ErrorType
+	Cause string
+	Count int
+	Last  time.Time
+}
+
+// groupReconnectErrors 按错误代码汇总失败的尝试，次数多的在前
+func groupReconnectErrors(attempts []database.ReconnectAttempt) []reconnectErrorGroup {
+	index := make(map[string]int)
+	var groups []reconnectErrorGroup
+	for _, a := range attempts {
+		if a.Success {
+			continue
+		}
+		i, ok := index[a.ErrorCode]
+		if !ok {
+			i = len(groups)
+			index[a.ErrorCode] = i
+			groups = append(groups, reconnectErrorGroup{Code: a.ErrorCode, Type: a.ErrorType, Cause: a.Cause})
+		}
+		groups[i].Count++
+		if a.Time.After(groups[i].Last) {
+			groups[i].Last = a.Time
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Count > groups[j].Count })
+	return groups
+}
+
+// ConnectionHistoryPageHandler 连接历史页面处理器，展示重连尝试及其错误分类，profile 参数指定服务器
+func ConnectionHistoryPageHandler(c echo.Context) error {
+	profile := c.QueryParam("profile")
+	if profile == "" {
+		profile = database.DefaultProfile
+	}
+	reconnector, ok := database.GetProfileRegistry().Reconnector(profile)
+	if !ok {
+		return c.HTML(http.StatusNotFound, "服务器不存在")
+	}
+
+	var profiles []string
+	for _, s := range database.GetProfileRegistry().Statuses() {
+		profiles = append(profiles, s.Name)
+	}
+	attempts := reconnector.AttemptHistory(time.Time{}, defaultReconnectHistoryLimit)
+	failures := 0
+	for _, a := range attempts {
+		if !a.Success {
+			failures++
+		}
+	}
+	state, since, _ := reconnector.StateHistory()
+
+	html, err := templates.RenderConnectionHistory(templates.ConnectionHistoryData{
+		Profile:  profile,
+		Profiles: profiles,
+		State:    string(state),
+		Since:    config.FormatDisplayTime(since),
+		Attempts: attempts,
+		Failures: failures,
+		Errors:   groupReconnectErrors(attempts),
+	})
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
+
+// APIReconnectHistoryHandler API 重连尝试历史处理器，按时间倒序返回
+// profile 指定服务器 (默认为默认连接)，since 支持 RFC3339 或 datetime-local 格式，limit 为 0 时不限制
+func APIReconnectHistoryHandler(c echo.Context) error {
+	reconnector, ok := database.GetProfileRegistry().Reconnector(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
+	var since time.Time
+	if v := c.QueryParam("since"); v != "" {
+		t, err := parseWindowTime(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid since: " + err.Error(),
+			})
+		}
+		since = t
+	}
+	limit := 0
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "limit 必须为非负整数",
+			})
+		}
+		limit = n
+	}
+
+	attempts := reconnector.AttemptHistory(since, limit)
+	byType := make(map[database.ErrorType]int)
+	for _, a := range attempts {
+		if !a.Success {
+			byType[a.ErrorType]++
+		}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"state":    reconnector.State(),
+		"attempts": attempts,
+		"count":    len(attempts),
+		"failures": byType,
+	})
+}
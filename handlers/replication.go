@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultLagWindow = 6 * time.Hour
+	lagChartWidth    = 600
+	lagChartHeight   = 160
+)
+
+// lagWindows 复制延迟页面可选的时间范围
+var lagWindows = []string{"1h", "6h", "24h", "48h"}
+
+// replicaLagView 复制延迟页面中单个从库的数据
+type replicaLagView struct {
+	Name       string
+	Current    int64
+	Stopped    bool
+	Max        int64
+	Samples    int
+	Sustained  string
+	Width      int
+	Height     int
+	Scale      int64
+	ThresholdY string
+	Segments   []string
+	From       string
+	To         string
+}
+
+// lagWindow 解析 window 参数，默认为 6h
+func lagWindow(c echo.Context) (time.Duration, error) {
+	v := c.QueryParam("window")
+	if v == "" {
+		return defaultLagWindow, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("window 必须为正的时长，例如 6h")
+	}
+	return d, nil
+}
+
+// ReplicationPageHandler 复制延迟页面处理器，按服务器绘制最近 window 内的延迟曲线
+func ReplicationPageHandler(c echo.Context) error {
+	window, err := lagWindow(c)
+	if err != nil {
+		return c.HTML(http.StatusBadRequest, err.Error())
+	}
+	threshold := int64(config.GetAlertConfig().ReplicaLagThreshold)
+	tracker := database.GetReplicaLagTracker()
+	now := time.Now()
+	since := now.Add(-window)
+
+	var replicas []replicaLagView
+	for _, name := range tracker.Profiles() {
+		samples := tracker.History(name, since)
+		if len(samples) == 0 {
+			continue
+		}
+		view := buildLagView(name, samples, since, now, threshold)
+		if threshold > 0 {
+			if d := tracker.SustainedAbove(name, threshold); d > 0 {
+				view.Sustained = d.Round(time.Second).String()
+			}
+		}
+		replicas = append(replicas, view)
+	}
+
+	data := templates.ReplicationData{
+		Replicas:  replicas,
+		Window:    window.String(),
+		Windows:   lagWindows,
+		Threshold: threshold,
+	}
+	for _, w := range lagWindows {
+		if d, _ := time.ParseDuration(w); d == window {
+			data.Window = w
+		}
+	}
+
+	html, err := templates.RenderReplication(data)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
+
+// buildLagView 计算曲线坐标，复制线程未运行的采样处断开曲线
+func buildLagView(name string, samples []database.LagSample, from, to time.Time, threshold int64) replicaLagView {
+	view := replicaLagView{
+		Name:    name,
+		Samples: len(samples),
+		Width:   lagChartWidth,
+		Height:  lagChartHeight,
+		From:    from.Format("01-02 15:04"),
+		To:      to.Format("01-02 15:04"),
+	}
+	latest := samples[len(samples)-1]
+	if latest.Seconds == nil {
+		view.Stopped = true
+	} else {
+		view.Current = *latest.Seconds
+	}
+	for _, s := range samples {
+		if s.Seconds != nil && *s.Seconds > view.Max {
+			view.Max = *s.Seconds
+		}
+	}
+
+	view.Scale = view.Max
+	if threshold > view.Scale {
+		view.Scale = threshold
+	}
+	if view.Scale < 1 {
+		view.Scale = 1
+	}
+	span := to.Sub(from).Seconds()
+	y := func(v int64) float64 {
+		return float64(lagChartHeight) * (1 - float64(v)/float64(view.Scale))
+	}
+	if threshold > 0 {
+		view.ThresholdY = fmt.Sprintf("%.1f", y(threshold))
+	}
+
+	var points []string
+	flush := func() {
+		// 单个孤立的点无法画成折线，复制为两个点
+		if len(points) == 1 {
+			points = append(points, points[0])
+		}
+		if len(points) > 0 {
+			view.Segments = append(view.Segments, strings.Join(points, " "))
+		}
+		points = nil
+	}
+	for _, s := range samples {
+		if s.Seconds == nil {
+			flush()
+			continue
+		}
+		x := float64(lagChartWidth) * s.Timestamp.Sub(from).Seconds() / span
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y(*s.Seconds)))
+	}
+	flush()
+	return view
+}
+
+// APIReplicationLagHandler API 复制延迟历史处理器
+// profile 只返回指定服务器，window 为时间范围 (默认 6h)，也可以用 since 指定起始时间
+func APIReplicationLagHandler(c echo.Context) error {
+	window, err := lagWindow(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	since := time.Now().Add(-window)
+	if v := c.QueryParam("since"); v != "" {
+		t, err := parseWindowTime(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid since: " + err.Error(),
+			})
+		}
+		since = t
+	}
+
+	tracker := database.GetReplicaLagTracker()
+	threshold := int64(config.GetAlertConfig().ReplicaLagThreshold)
+	names := tracker.Profiles()
+	if profile := c.QueryParam("profile"); profile != "" {
+		names = []string{profile}
+	}
+	replicas := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		samples := tracker.History(name, since)
+		if samples == nil {
+			samples = []database.LagSample{}
+		}
+		entry := map[string]interface{}{
+			"profile": name,
+			"samples": samples,
+		}
+		if threshold > 0 {
+			entry["sustained_above_seconds"] = tracker.SustainedAbove(name, threshold).Seconds()
+		}
+		replicas = append(replicas, entry)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"replicas":  replicas,
+		"since":     since,
+		"threshold": threshold,
+	})
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/schemagen"
+	"github.com/furutachiKurea/block-checker/snapshot"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APISchemaJSONHandler API 数据库元数据文档处理器
+// 输出带格式版本的完整元数据 (表、字段、索引、外键)，供代码生成和文档流水线使用
+func APISchemaJSONHandler(c echo.Context) error {
+	databaseName := c.Param("database")
+	if databaseName == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "数据库名称不能为空",
+		})
+	}
+
+	doc, err := snapshot.BuildMetadata(c.Request().Context(), store, databaseName)
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSONPretty(http.StatusOK, doc, "  ")
+}
+
+// APITableSchemaGenHandler API 表结构生成 Avro schema 或 Protobuf message 处理器
+// format 为 avro 或 proto，结果作为文件下载；类型映射可通过 SCHEMAGEN_TYPE_MAPPING 覆盖
+func APITableSchemaGenHandler(c echo.Context) error {
+	databaseName := c.Param("database")
+	tableName := c.Param("table")
+	format := c.Param("format")
+	if format != schemagen.FormatAvro && format != schemagen.FormatProto {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "format 必须为 avro 或 proto",
+		})
+	}
+
+	ctx := c.Request().Context()
+	detail, err := store.GetTableDetail(ctx, databaseName, tableName)
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if len(detail.Fields) == 0 {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "表不存在",
+		})
+	}
+
+	// 表注释仅用于生成文档，获取失败时忽略
+	comment := ""
+	if tables, err := store.GetTables(ctx, databaseName); err == nil {
+		for _, t := range tables {
+			if t.Name == tableName {
+				comment = t.Comment
+				break
+			}
+		}
+	}
+
+	mapping := schemagen.GetMapping()
+	if format == schemagen.FormatProto {
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+tableName+`.proto"`)
+		return c.Blob(http.StatusOK, "text/plain; charset=utf-8", schemagen.Proto(databaseName, tableName, comment, detail.Fields, mapping))
+	}
+
+	avsc, err := schemagen.Avro(databaseName, tableName, comment, detail.Fields, mapping)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+tableName+`.avsc"`)
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSONCharsetUTF8, avsc)
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// serverPagePressureSamples 服务器页面展示的压力采样数
+	serverPagePressureSamples = 30
+	// lockAdvisorWindow 锁设置建议默认参考的阻塞历史范围
+	lockAdvisorWindow = 7 * 24 * time.Hour
+)
+
+// ServerPageHandler 服务器状态页面处理器
+func ServerPageHandler(c echo.Context) error {
+	data := templates.ServerData{}
+
+	binlog, err := store.GetBinlogStatus(c.Request().Context())
+	if err != nil {
+		data.BinlogError = err.Error()
+	} else {
+		data.Binlog = binlog
+	}
+
+	locks, err := store.GetNamedLocks(c.Request().Context())
+	if err != nil {
+		data.NamedLocksError = err.Error()
+	} else {
+		data.NamedLocks = locks
+	}
+
+	data.Pressure = recentPressure(serverPagePressureSamples)
+
+	report, err := database.AdviseLockSettings(c.Request().Context(), time.Now().Add(-lockAdvisorWindow))
+	if err != nil {
+		data.LockAdviceError = err.Error()
+	} else {
+		data.LockAdvice = report
+	}
+
+	html, err := templates.RenderServer(data)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
+
+// APIBinlogHandler API 二进制日志状态处理器
+func APIBinlogHandler(c echo.Context) error {
+	binlog, err := store.GetBinlogStatus(c.Request().Context())
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, binlog)
+}
+
+// APINamedLocksHandler API 命名锁处理器
+func APINamedLocksHandler(c echo.Context) error {
+	locks, err := store.GetNamedLocks(c.Request().Context())
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"locks": locks,
+		"count": len(locks),
+	})
+}
+
+// APIPressureHandler API 临时表与排序压力处理器
+func APIPressureHandler(c echo.Context) error {
+	tracker := database.GetPressureTracker()
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"counters": database.PressureCounters,
+		"totals":   tracker.Totals(),
+		"samples":  tracker.GetSamples(),
+	})
+}
+
+// APIThreadsHandler API 连接数与活跃线程处理器，返回实时值和采样历史
+func APIThreadsHandler(c echo.Context) error {
+	usage, err := store.GetThreadUsage(c.Request().Context())
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"current":      usage,
+		"warn_percent": connectionWarnPercent(),
+		"samples":      database.GetThreadTracker().GetSamples(),
+	})
+}
+
+// APIDBStateHistoryHandler API 数据库连接状态与状态转换历史处理器，profile 参数指定服务器，默认为默认连接
+func APIDBStateHistoryHandler(c echo.Context) error {
+	reconnector, ok := database.GetProfileRegistry().Reconnector(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
+	state, since, transitions := reconnector.StateHistory()
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"state":         state,
+		"since":         since,
+		"retry_count":   reconnector.GetRetryCount(),
+		"transitions":   transitions,
+		"error_history": reconnector.GetErrorHistory(),
+	})
+}
+
+// APIDBReconnectHandler API 手动重连处理器 (仅操作员)，立即返回，重连结果通过状态历史查看
+// profile 参数指定服务器，默认为默认连接
+func APIDBReconnectHandler(c echo.Context) error {
+	reconnector, ok := database.GetProfileRegistry().Reconnector(c.QueryParam("profile"))
+	if !ok {
+		return profileNotFound(c)
+	}
+	reconnector.Restart()
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"state": reconnector.State(),
+	})
+}
+
+// connectionWarnPercent 连接使用率的警告线，与告警阈值一致，未配置告警时为 80%
+func connectionWarnPercent() float64 {
+	if p := config.GetAlertConfig().ConnectionUsagePercent; p > 0 {
+		return p
+	}
+	return 80
+}
+
+// recentPressure 返回最近 n 次压力采样，最新的在前
+func recentPressure(n int) []database.PressureSample {
+	samples := database.GetPressureTracker().GetSamples()
+	if len(samples) > n {
+		samples = samples[len(samples)-n:]
+	}
+	for i, j := 0, len(samples)-1; i < j; i, j = i+1, j-1 {
+		samples[i], samples[j] = samples[j], samples[i]
+	}
+	return samples
+}
+
+// APILockAdviceHandler API 锁设置建议处理器，检查锁等待超时、死锁检测、隔离级别和自动提交
+// since 指定参考的阻塞历史起始时间，默认为最近 7 天
+func APILockAdviceHandler(c echo.Context) error {
+	since := time.Now().Add(-lockAdvisorWindow)
+	if v := c.QueryParam("since"); v != "" {
+		t, err := parseWindowTime(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid since: " + err.Error(),
+			})
+		}
+		since = t
+	}
+
+	report, err := database.AdviseLockSettings(c.Request().Context(), since)
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, report)
+}
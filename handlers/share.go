@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/templates"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APIShareTableHandler API 创建表结构分享链接处理器
+// 快照不包含授权信息；ttl 为有效期 (如 2h)，默认由 SHARE_DEFAULT_TTL 决定
+func APIShareTableHandler(c echo.Context) error {
+	databaseName := c.Param("database")
+	tableName := c.Param("table")
+	ttl, err := parseShareTTL(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	detail, err := store.GetTableDetail(c.Request().Context(), databaseName, tableName)
+	if err != nil {
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	data := templates.TableDetailData{DatabaseName: databaseName, TableName: tableName, Detail: detail}
+	share, err := database.GetShareManager().Create(database.ShareKindTable, databaseName+"."+tableName, data, ttl)
+	return shareResponse(c, share, err)
+}
+
+// APIShareDiffHandler API 创建结构差异报告分享链接处理器，参数与 /api/diff/upload 相同
+func APIShareDiffHandler(c echo.Context) error {
+	ttl, err := parseShareTTL(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	report, status, err := uploadDiffReport(c)
+	if err != nil {
+		return c.JSON(status, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	share, err := database.GetShareManager().Create(database.ShareKindDiff, "diff "+c.QueryParam("database"), report, ttl)
+	return shareResponse(c, share, err)
+}
+
+// APIShareRevokeHandler API 撤销分享链接处理器
+func APIShareRevokeHandler(c echo.Context) error {
+	if !database.GetShareManager().Revoke(c.Param("token")) {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "分享链接不存在或已过期",
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ShareHandler 分享链接访问处理器，无需认证
+func ShareHandler(c echo.Context) error {
+	share, ok := database.GetShareManager().Get(c.Param("token"))
+	if !ok {
+		data := templates.ErrorData{
+			Title:   "分享链接无效",
+			Message: "链接不存在或已过期",
+		}
+		html, _ := templates.RenderError(data)
+		return c.HTML(http.StatusNotFound, html)
+	}
+
+	if share.Kind == database.ShareKindDiff {
+		return c.JSONPretty(http.StatusOK, share.Data, "  ")
+	}
+
+	data, _ := share.Data.(templates.TableDetailData)
+	data.Shared = &templates.ShareInfo{
+		CreatedAt: config.FormatDisplayTime(share.CreatedAt),
+		ExpiresAt: config.FormatDisplayTime(share.ExpiresAt),
+	}
+	html, err := templates.RenderTableDetail(data)
+	if err != nil {
+		return c.HTML(http.StatusInternalServerError, "模板渲染错误")
+	}
+	return c.HTML(http.StatusOK, html)
+}
+
+// parseShareTTL 解析 ttl 参数，未指定时返回 0
+func parseShareTTL(c echo.Context) (time.Duration, error) {
+	v := c.QueryParam("ttl")
+	if v == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(v)
+}
+
+// shareResponse 返回新建分享链接的地址和有效期
+func shareResponse(c echo.Context, share *database.Share, err error) error {
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"token":      share.Token,
+		"url":        "/share/" + share.Token,
+		"title":      share.Title,
+		"expires_at": share.ExpiresAt,
+	})
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/furutachiKurea/block-checker/slo"
+
+	"github.com/labstack/echo/v4"
+)
+
+// sloTracker 健康评分器，未启用时为 nil
+var sloTracker *slo.Tracker
+
+// SetSLOTracker 注入健康评分器
+func SetSLOTracker(t *slo.Tracker) {
+	sloTracker = t
+}
+
+// APISLOHandler API 健康评分与 SLO 处理器，history 参数指定附带的最近评分数 (默认 60)
+func APISLOHandler(c echo.Context) error {
+	if sloTracker == nil {
+		return c.JSON(http.StatusNotFound, map[string]interface{}{
+			"error": "健康评分未启用",
+		})
+	}
+
+	history := 60
+	if v := c.QueryParam("history"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "history 必须为非负整数",
+			})
+		}
+		history = n
+	}
+	return c.JSON(http.StatusOK, sloTracker.Report(history))
+}
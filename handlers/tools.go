@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/sqltools"
+
+	"github.com/labstack/echo/v4"
+)
+
+// sqlToolRequest SQL 工具请求体
+type sqlToolRequest struct {
+	SQL      string `json:"sql" form:"sql"`
+	Database string `json:"database" form:"database" query:"database"`
+}
+
+// bindSQLToolRequest 解析请求体，失败时返回错误信息
+func bindSQLToolRequest(c echo.Context) (*sqlToolRequest, string) {
+	var req sqlToolRequest
+	if err := c.Bind(&req); err != nil {
+		return nil, "invalid request body"
+	}
+	if req.SQL == "" {
+		return nil, "sql 不能为空"
+	}
+	return &req, ""
+}
+
+// APIFormatSQLHandler API SQL 美化处理器
+func APIFormatSQLHandler(c echo.Context) error {
+	req, msg := bindSQLToolRequest(c)
+	if req == nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": msg,
+		})
+	}
+
+	formatted, err := sqltools.Format(req.SQL)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"sql": formatted,
+	})
+}
+
+// APIQualifySQLHandler API 标识符限定处理器，为表引用补全库名并为标识符加上反引号
+func APIQualifySQLHandler(c echo.Context) error {
+	req, msg := bindSQLToolRequest(c)
+	if req == nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": msg,
+		})
+	}
+	if req.Database == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "数据库名称不能为空",
+		})
+	}
+
+	qualified, err := sqltools.Qualify(req.SQL, req.Database)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"database": req.Database,
+		"sql":      qualified,
+	})
+}
+
+// APIExportDDLHandler API 建表语句导出处理器
+func APIExportDDLHandler(c echo.Context) error {
+	databaseName := c.Param("database")
+	if databaseName == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "数据库名称不能为空",
+		})
+	}
+
+	ddl, err := store.ExportDDL(c.Request().Context(), databaseName)
+	if err != nil {
+		if status, message, ok := identifierError(err); ok {
+			return c.JSON(status, map[string]interface{}{
+				"error": message,
+			})
+		}
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	return c.String(http.StatusOK, ddl)
+}
+
+// APIFindValueHandler API 值搜索处理器 (仅操作员，跳过配置了脱敏规则的列)
+// 参数 value 为要查找的值，type 为列类型过滤 (string/numeric/temporal/all，默认 string)，match=contains 时做子串匹配
+func APIFindValueHandler(c echo.Context) error {
+	databaseName := c.Param("database")
+	value := c.QueryParam("value")
+	if databaseName == "" || value == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "数据库名称和 value 不能为空",
+		})
+	}
+	match := strings.ToLower(c.QueryParam("match"))
+	if match != "" && match != "exact" && match != "contains" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": "match 必须为 exact 或 contains",
+		})
+	}
+
+	result, err := store.FindValue(c.Request().Context(), databaseName, database.FindValueOptions{
+		Value:    value,
+		Type:     strings.ToLower(c.QueryParam("type")),
+		Contains: match == "contains",
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrFindValueRunning) {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"error": "已有值搜索正在进行",
+			})
+		}
+		if errors.Is(err, database.ErrInvalidFindOptions) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		if status, message, ok := identifierError(err); ok {
+			return c.JSON(status, map[string]interface{}{
+				"error": message,
+			})
+		}
+		if database.IsTimeout(err) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]interface{}{
+				"error": "query timeout",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, result)
+}
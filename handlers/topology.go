@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APITopologyHandler 服务器拓扑处理器，返回各服务器最近一次读取的身份和拓扑变化历史 (按时间倒序)
+// profile 只返回指定服务器的变化，since 支持 RFC3339 或 datetime-local 格式
+func APITopologyHandler(c echo.Context) error {
+	var since time.Time
+	if v := c.QueryParam("since"); v != "" {
+		t, err := parseWindowTime(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": "invalid since: " + err.Error(),
+			})
+		}
+		since = t
+	}
+
+	watcher := database.GetTopologyWatcher()
+	changes := watcher.Changes(c.QueryParam("profile"), since)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"identities": watcher.Identities(),
+		"changes":    changes,
+		"count":      len(changes),
+	})
+}
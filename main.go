@@ -1,67 +1,315 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
+	"net"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"syscall"
+	"time"
 
+	"github.com/furutachiKurea/block-checker/alert"
+	"github.com/furutachiKurea/block-checker/checks"
 	"github.com/furutachiKurea/block-checker/config"
 	"github.com/furutachiKurea/block-checker/database"
 	"github.com/furutachiKurea/block-checker/handlers"
+	"github.com/furutachiKurea/block-checker/slo"
+	"github.com/furutachiKurea/block-checker/snapshot"
+	"github.com/furutachiKurea/block-checker/storage"
 
 	"github.com/labstack/echo/v4"
+	"golang.org/x/net/http2"
 )
 
 func main() {
-	// 初始化数据库连接
-	if err := database.InitDB(); err != nil {
-		log.Printf("Failed to initialize database: %v", err)
-		// 不退出应用，继续运行
-	}
-	defer database.CloseDB()
-
-	// 创建 Echo 实例
-	e := echo.New()
-
-	// 配置静态文件服务
-	e.Static("/static", "static")
-
-	// 注册路由
-	e.GET("/", handlers.HomeHandler)
-	e.GET("/healthz", handlers.HealthHandler)
-
-	// 数据库浏览路由
-	e.GET("/databases", handlers.DatabasesHandler)
-	e.GET("/databases/:database/tables", handlers.TablesHandler)
-
-	// 表结构详情路由
-	e.GET("/database/:database/table/:table", handlers.TableDetailHandler)
-
-	// 日志管理路由
-	e.GET("/logs", handlers.LogsPageHandler)
-
-	// API 路由
-	e.GET("/api/databases", handlers.APIDatabasesHandler)
-	e.GET("/api/databases/:database/tables", handlers.APITablesHandler)
-	
-	// 日志管理 API 路由
-	e.GET("/api/logs", handlers.GetLogsHandler)
-	e.GET("/api/logs/summary", handlers.GetLogSummaryHandler)
-	e.POST("/api/logs/level", handlers.SetLogLevelHandler)
-	e.POST("/api/logs/clear", handlers.ClearLogsHandler)
-	
-	// 错误分析 API 路由
-	e.GET("/api/errors/summaries", handlers.GetErrorSummariesHandler)
-	e.GET("/api/errors/top", handlers.GetTopErrorsHandler)
-	e.GET("/api/errors/trends", handlers.GetErrorTrendsHandler)
-	e.POST("/api/errors/resolve", handlers.MarkErrorResolvedHandler)
-	e.POST("/api/errors/clear", handlers.ClearOldErrorsHandler)
+	validate := flag.Bool("validate", false, "校验配置和运行环境后退出")
+	flag.Parse()
+
+	// 所有时间输出使用 TIME_ZONE 时区，需在创建任何时间和打开数据库连接之前设置
+	timeConfig := config.GetTimeConfig()
+	time.Local = timeConfig.Location
+	database.UseLogTimeFormat(timeConfig)
+
+	if *validate {
+		os.Exit(runValidation(os.Stdout))
+	}
+
+	// 致命错误或 panic 导致的关闭在其他 defer 的清理完成后以非零状态退出，该 defer 需最先注册
+	exitCode := 0
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("panic: %v\n%s", r, debug.Stack())
+			exitCode = 2
+		}
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
+	// 打开持久化存储 (阻塞事件历史、诊断包)，需在连接数据库之前设置
+	if st, err := storage.New(context.Background(), config.GetStorageConfig()); err != nil {
+		log.Printf("Failed to open storage, persistence disabled: %v", err)
+	} else if st != nil {
+		database.SetStorage(st)
+		defer st.Close()
+	}
+
+	// 初始化数据库连接，演示模式使用内存中的演示数据，需在启动各采样器之前设置
+	if config.GetDBConfig().Driver == config.DriverDemo {
+		log.Printf("Demo mode: serving in-memory demo data, no MySQL connection is made")
+		handlers.SetStore(database.UseDemoStore())
+	} else {
+		if err := database.InitDB(); err != nil {
+			log.Printf("Failed to initialize database: %v", err)
+			// 不退出应用，继续运行
+		}
+		defer database.CloseDB()
+		handlers.SetStore(database.NewMySQLStore())
+	}
+	handlers.SetBuildInfo(handlers.BuildInfo{Version: version, Commit: commit, BuildDate: buildDate})
+
+	// 启动表增长采样
+	growthTracker := database.GetGrowthTracker()
+	growthTracker.Start(config.GetGrowthConfig().SampleInterval)
+	defer growthTracker.Stop()
+
+	// 启动临时表与排序压力采样
+	pressureTracker := database.GetPressureTracker()
+	pressureTracker.Start(config.GetPressureConfig().SampleInterval)
+	defer pressureTracker.Stop()
+
+	// 启动连接数与活跃线程采样
+	threadTracker := database.GetThreadTracker()
+	threadTracker.Start(config.GetThreadsConfig().SampleInterval)
+	defer threadTracker.Stop()
+
+	// 启动锁等待扫描，记录阻塞事件历史
+	blockScanner := database.GetBlockScanner()
+	blockScanner.Start(config.GetBlockHistoryConfig().ScanInterval)
+	defer blockScanner.Stop()
+
+	// 启动服务器总览的定期检查
+	profileRegistry := database.GetProfileRegistry()
+	profileRegistry.Start(config.GetProfilesConfig().CheckInterval)
+	defer profileRegistry.Stop()
+
+	// 启动主从表数据校验
+	checksumMonitor := database.GetChecksumMonitor()
+	checksumMonitor.Start(config.GetChecksumConfig().Interval)
+	defer checksumMonitor.Stop()
+
+	// 启动复制延迟采样
+	replicaLagTracker := database.GetReplicaLagTracker()
+	replicaLagTracker.Start(config.GetReplicaLagConfig().SampleInterval)
+	defer replicaLagTracker.Stop()
+
+	// 启动服务器拓扑变化检测
+	topologyWatcher := database.GetTopologyWatcher()
+	topologyWatcher.Start(config.GetTopologyConfig().CheckInterval)
+	defer topologyWatcher.Stop()
+
+	// 启动错误频率数据的合并清理
+	errorAnalyzer := database.GetErrorAnalyzer()
+	errorAnalyzer.StartCompaction(config.GetErrorAnalysisConfig().CompactInterval)
+	defer errorAnalyzer.StopCompaction()
+
+	// 启动结构快照定期导出
+	if snapshotConfig := config.GetSnapshotConfig(); snapshotConfig.Enabled() {
+		exporter := snapshot.NewExporter(database.DefaultStore(), snapshotConfig)
+		exporter.Start(snapshotConfig.Interval)
+		defer exporter.Stop()
+	}
+
+	// 启动迁移目录结构漂移检测
+	if migrationConfig := config.GetMigrationConfig(); migrationConfig.Enabled() {
+		driftChecker := snapshot.NewDriftChecker(database.DefaultStore(), migrationConfig)
+		handlers.SetDriftChecker(driftChecker)
+		driftChecker.Start(migrationConfig.CheckInterval)
+		defer driftChecker.Stop()
+	}
+
+	// 写入探测作为扩展检查运行
+	if config.GetCanaryConfig().Enabled {
+		checks.Register(database.GetCanary())
+	}
+
+	// 启动扩展检查 (编译注册的检查、外部检查目录与外部命令)，在告警之前启动以便生成检查的告警规则
+	checkRunner := newCheckRunner(config.GetChecksConfig())
+	if checkRunner != nil {
+		handlers.SetCheckRunner(checkRunner)
+		checkRunner.Start()
+		defer checkRunner.Stop()
+	}
+
+	// 启动告警规则评估，启用空闲事务策略时即使没有规则也创建引擎，用于发送操作通知
+	if alertConfig := config.GetAlertConfig(); alertConfig.Enabled() || config.GetIdleTrxConfig().Enabled() {
+		var notifiers []alert.Notifier
+		for _, url := range alertConfig.WebhookURLs {
+			notifiers = append(notifiers, alert.NewWebhookNotifier(url, alertConfig.ExternalURL))
+		}
+		if chatNotifiers, err := alert.ChatNotifiersFromConfig(alertConfig); err != nil {
+			log.Printf("Failed to configure chat notifiers: %v", err)
+		} else {
+			notifiers = append(notifiers, chatNotifiers...)
+		}
+		dbConfig := config.GetDBConfig()
+		rules := alert.RulesFromConfig(alertConfig, database.DefaultStore())
+		if alertConfig.CheckFailed && checkRunner != nil {
+			rules = append(rules, alert.CheckRules(checkRunner, alertConfig.CheckFailedFor)...)
+		}
+		alertEngine := alert.NewEngine(rules, notifiers,
+			net.JoinHostPort(dbConfig.Host, dbConfig.Port), alertConfig.ExternalURL)
+		if routes, err := alert.ParseRoutes(alertConfig.Routes); err != nil {
+			log.Printf("Failed to parse ALERT_ROUTES: %v", err)
+		} else {
+			alertEngine.SetRoutes(routes)
+		}
+		if escalations, err := alert.ParseEscalations(alertConfig.Escalations); err != nil {
+			log.Printf("Failed to parse ALERT_ESCALATIONS: %v", err)
+		} else {
+			alertEngine.SetEscalations(escalations)
+		}
+		if throttle, err := alert.ParseThrottle(alertConfig); err != nil {
+			log.Printf("Failed to configure alert throttling: %v", err)
+		} else {
+			alertEngine.SetThrottle(throttle)
+		}
+		alertEngine.SetAckSecret(alertConfig.AckSecret)
+		alertEngine.SetStorage(database.PersistentStorage())
+		handlers.SetAlertEngine(alertEngine)
+		database.GetIdleTrxKiller().SetNotifier(alertEngine.NotifyIdleTrxAction)
+		alertEngine.Start(alertConfig.EvalInterval)
+		defer alertEngine.Stop()
+	}
+
+	// 启动空闲事务自动终止策略 (默认关闭)
+	idleTrxKiller := database.GetIdleTrxKiller()
+	idleTrxKiller.Start(config.GetIdleTrxConfig().CheckInterval)
+	defer idleTrxKiller.Stop()
+
+	// 启动外部心跳推送
+	if heartbeatConfig := config.GetHeartbeatConfig(); heartbeatConfig.URL != "" {
+		heartbeat := alert.NewHeartbeat(database.DefaultStore(), heartbeatConfig)
+		heartbeat.Start(heartbeatConfig.Interval)
+		defer heartbeat.Stop()
+	}
+
+	// 启动健康评分
+	if sloConfig := config.GetSLOConfig(); sloConfig.Enabled {
+		sloTracker := slo.NewTracker(database.DefaultStore(), sloConfig)
+		handlers.SetSLOTracker(sloTracker)
+		sloTracker.Start(sloConfig.Interval)
+		defer sloTracker.Stop()
+	}
 
 	// 获取配置
 	appConfig := config.GetServerConfig()
 
-	// 启动服务器
+	// 等待数据库可达后再启动服务，超时则以失败状态退出
+	if appConfig.WaitForDB {
+		log.Printf("Waiting for database (timeout %v)", appConfig.WaitForDBTimeout)
+		if err := database.WaitForDB(appConfig.WaitForDBTimeout); err != nil {
+			log.Printf("Database wait failed: %v", err)
+			exitCode = 1
+			return
+		}
+	}
+
+	// 创建 Echo 实例并注册路由
+	e := newRouter()
+
+	// 启动服务器，收到退出信号或致命错误时停止服务，main 返回后执行 defer 的清理
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- startServer(e, appConfig)
+	}()
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			log.Printf("Server error: %v", err)
+		}
+		return
+	case sig := <-quit:
+		log.Printf("Received %v, shutting down", sig)
+	case err := <-database.FatalErrors():
+		log.Printf("Fatal error, shutting down: %v", err)
+		exitCode = 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), appConfig.ShutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown error: %v", err)
+	}
+}
+
+// newCheckRunner 汇总编译注册的检查、外部检查目录中的检查和外部命令检查，没有任何检查时返回 nil
+// 外部检查与已有检查重名时忽略后加载的外部检查
+func newCheckRunner(cfg *config.ChecksConfig) *checks.Runner {
+	list := checks.Registered()
+	var external []checks.Check
+	if cfg.ExecDir != "" {
+		execChecks, err := checks.ExecChecksFromDir(cfg.ExecDir, 0)
+		if err != nil {
+			log.Printf("Failed to load external checks: %v", err)
+		}
+		external = append(external, execChecks...)
+	}
+	if cfg.Commands != "" {
+		commandChecks, err := checks.ParseCommandChecks(cfg.Commands)
+		if err != nil {
+			log.Printf("Failed to parse CHECKS_COMMANDS: %v", err)
+		}
+		for _, c := range commandChecks {
+			external = append(external, c)
+		}
+	}
+	names := make(map[string]bool)
+	for _, c := range list {
+		names[c.Name()] = true
+	}
+	for _, c := range external {
+		if names[c.Name()] {
+			log.Printf("Skipping external check %q: name already registered", c.Name())
+			continue
+		}
+		names[c.Name()] = true
+		list = append(list, c)
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	log.Printf("Loaded %d checks", len(list))
+	runner := checks.NewRunner(list, cfg.Interval, cfg.Timeout)
+	runner.SetHistoryLimit(cfg.History)
+	return runner
+}
+
+// startServer 根据配置选择监听方式 (TCP 或 unix socket, 可选 h2c) 并启动服务器
+func startServer(e *echo.Echo, appConfig *config.ServerConfig) error {
 	serverAddr := "0.0.0.0:" + appConfig.Port
-	log.Printf("Starting server on %s", serverAddr)
-	if err := e.Start(serverAddr); err != nil {
-		log.Printf("Server error: %v", err)
+	if appConfig.SocketPath != "" {
+		// 清理上次运行残留的 socket 文件
+		if err := os.Remove(appConfig.SocketPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		listener, err := net.Listen("unix", appConfig.SocketPath)
+		if err != nil {
+			return err
+		}
+		e.Listener = listener
+		serverAddr = appConfig.SocketPath
+	}
+
+	if appConfig.EnableH2C {
+		log.Printf("Starting h2c server on %s", serverAddr)
+		return e.StartH2CServer(serverAddr, &http2.Server{})
 	}
+
+	log.Printf("Starting server on %s", serverAddr)
+	return e.Start(serverAddr)
 }
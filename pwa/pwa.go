@@ -0,0 +1,40 @@
+// Package pwa 内嵌的站点图标、PWA 清单和 service worker
+package pwa
+
+import (
+	"embed"
+	"path"
+)
+
+//go:embed favicon.ico manifest.webmanifest sw.js icons
+var assetFS embed.FS
+
+// contentTypes 按扩展名返回的内容类型
+var contentTypes = map[string]string{
+	".ico":         "image/x-icon",
+	".png":         "image/png",
+	".svg":         "image/svg+xml",
+	".js":          "text/javascript; charset=utf-8",
+	".webmanifest": "application/manifest+json; charset=utf-8",
+}
+
+// Asset 读取内嵌资源，返回内容和内容类型，资源不存在时 ok 为 false
+func Asset(name string) (data []byte, contentType string, ok bool) {
+	data, err := assetFS.ReadFile(path.Clean(name))
+	if err != nil {
+		return nil, "", false
+	}
+	contentType, ok = contentTypes[path.Ext(name)]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+	return data, contentType, true
+}
+
+// Icon 读取 icons 目录下的图标
+func Icon(name string) (data []byte, contentType string, ok bool) {
+	if name == "" || path.Base(name) != name {
+		return nil, "", false
+	}
+	return Asset("icons/" + name)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"log"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newRouter 创建 Echo 实例并注册中间件、页面和 API 路由
+// 处理器使用的 Store 需在调用前通过 handlers.SetStore 设置
+func newRouter() *echo.Echo {
+	e := echo.New()
+
+	// 配置静态文件服务
+	e.Static("/static", "static")
+
+	// 统计每个请求的数据库查询
+	e.Use(handlers.QueryBudget(config.GetQueryBudgetConfig()))
+	// 操作员可按请求开启 SQL 追踪
+	e.Use(handlers.DebugTrace)
+	// 浏览器访问 API 出错时返回错误页面
+	e.Use(handlers.HTMLErrors)
+
+	// 站点图标与 PWA 资源
+	e.GET("/favicon.ico", handlers.FaviconHandler)
+	e.GET("/manifest.webmanifest", handlers.ManifestHandler)
+	e.GET("/sw.js", handlers.ServiceWorkerHandler)
+	e.GET("/icons/:file", handlers.IconHandler)
+
+	// 注册路由
+	e.GET("/", handlers.HomeHandler)
+	e.GET("/healthz", handlers.HealthHandler)
+	e.GET("/status.svg", handlers.StatusBadgeSVGHandler)
+	e.GET("/share/:token", handlers.ShareHandler)
+
+	// 数据库浏览路由
+	// 页面路由在 Accept: application/json 时返回对应 API 的 JSON
+	e.GET("/databases", handlers.DatabasesHandler, handlers.RequireDB, handlers.JSONAlternative(handlers.APIDatabasesHandler))
+	e.GET("/databases/:database/tables", handlers.TablesHandler, handlers.RequireDB, handlers.JSONAlternative(handlers.APITablesHandler))
+
+	// 表结构详情路由
+	e.GET("/database/:database/table/:table", handlers.TableDetailHandler, handlers.RequireDB, handlers.JSONAlternative(handlers.APITableDetailHandler))
+
+	// 服务器状态路由
+	e.GET("/server", handlers.ServerPageHandler, handlers.RequireDB)
+	e.GET("/growth", handlers.GrowthPageHandler, handlers.JSONAlternative(handlers.APIGrowthForecastHandler))
+	e.GET("/replication", handlers.ReplicationPageHandler, handlers.JSONAlternative(handlers.APIReplicationLagHandler))
+	e.GET("/connection-history", handlers.ConnectionHistoryPageHandler, handlers.JSONAlternative(handlers.APIReconnectHistoryHandler))
+	e.GET("/processlist", handlers.ProcessListPageHandler)
+	e.GET("/processlist/:id", handlers.SessionPageHandler, handlers.RequireDB, handlers.JSONAlternative(handlers.APISessionHandler))
+	e.GET("/dashboard", handlers.DashboardPageHandler, handlers.JSONAlternative(handlers.APIProfilesHandler))
+	e.GET("/blocks/history", handlers.BlockHistoryPageHandler, handlers.JSONAlternative(handlers.APIBlockHistoryHandler))
+	e.GET("/engines", handlers.EnginesPageHandler, handlers.RequireDB, handlers.JSONAlternative(handlers.APIEnginesHandler))
+	e.GET("/maintenance", handlers.MaintenancePageHandler, handlers.JSONAlternative(handlers.APIMaintenanceListHandler))
+	e.GET("/alerts/:id/ack", handlers.AlertAckPageHandler)
+
+	// 日志管理路由
+	e.GET("/logs", handlers.LogsPageHandler, handlers.JSONAlternative(handlers.GetLogsHandler))
+
+	// 账号权限路由 (仅操作员)
+	e.GET("/users", handlers.UsersPageHandler, handlers.RequireOperator, handlers.RequireDB, handlers.JSONAlternative(handlers.APIUsersHandler))
+
+	// Prometheus 指标
+	e.GET("/metrics", handlers.MetricsHandler)
+
+	// API 路由
+	e.GET("/api/databases", handlers.APIDatabasesHandler, handlers.RequireDB)
+	e.GET("/api/databases/:database/tables", handlers.APITablesHandler, handlers.RequireDB)
+	e.GET("/api/databases/:database/tables/:table", handlers.APITableDetailHandler, handlers.RequireDB)
+	e.GET("/api/databases/:database/tables/:table/sample", handlers.APITableSampleHandler, handlers.RequireOperator, handlers.RequireDB)
+	e.GET("/api/databases/:database/ddl", handlers.APIExportDDLHandler, handlers.RequireDB)
+	e.GET("/api/databases/:database/schema.json", handlers.APISchemaJSONHandler, handlers.RequireDB)
+	e.GET("/api/databases/:database/tables/:table/schema/:format", handlers.APITableSchemaGenHandler, handlers.RequireDB)
+	e.POST("/api/databases/:database/tables/:table/fk-check", handlers.APIFKCheckStartHandler, handlers.RequireOperator, handlers.RequireDB)
+	e.GET("/api/fk-checks", handlers.APIFKCheckListHandler, handlers.RequireOperator)
+	e.GET("/api/fk-checks/:id", handlers.APIFKCheckStatusHandler, handlers.RequireOperator)
+	e.DELETE("/api/fk-checks/:id", handlers.APIFKCheckCancelHandler, handlers.RequireOperator)
+	e.GET("/api/fk-checks/:id/violations", handlers.APIFKCheckViolationsHandler, handlers.RequireOperator)
+	e.GET("/api/databases/:database/grants", handlers.APIGrantsHandler, handlers.RequireOperator, handlers.RequireDB)
+	e.GET("/api/users", handlers.APIUsersHandler, handlers.RequireOperator, handlers.RequireDB)
+	e.GET("/api/server/binlog", handlers.APIBinlogHandler, handlers.RequireDB)
+	e.GET("/api/server/named-locks", handlers.APINamedLocksHandler, handlers.RequireDB)
+	e.GET("/api/server/pressure", handlers.APIPressureHandler)
+	e.GET("/api/server/lock-advice", handlers.APILockAdviceHandler, handlers.RequireDB)
+	e.GET("/api/server/threads", handlers.APIThreadsHandler, handlers.RequireDB)
+	e.GET("/api/server/processlist", handlers.APIProcessListHandler, handlers.RequireDB)
+	e.GET("/api/server/processlist/tail", handlers.APIProcessListTailHandler, handlers.RequireDB)
+	e.GET("/api/server/processlist/:id", handlers.APISessionHandler, handlers.RequireDB)
+	e.GET("/api/blocks/history", handlers.APIBlockHistoryHandler)
+	e.GET("/api/profiles", handlers.APIProfilesHandler)
+	e.GET("/api/profiles/:name", handlers.APIProfileHandler)
+	e.GET("/api/diagnostics", handlers.APIDiagnosticsListHandler)
+	e.POST("/api/diagnostics", handlers.APIDiagnosticsCaptureHandler, handlers.RequireOperator)
+	e.GET("/api/diagnostics/:id", handlers.APIDiagnosticsDownloadHandler, handlers.RequireOperator)
+	e.GET("/api/db/state-history", handlers.APIDBStateHistoryHandler)
+	e.GET("/api/db/reconnect-history", handlers.APIReconnectHistoryHandler)
+	e.POST("/api/db/reconnect", handlers.APIDBReconnectHandler, handlers.RequireOperator)
+	e.GET("/api/engines", handlers.APIEnginesHandler, handlers.RequireDB)
+	e.GET("/api/growth/forecast", handlers.APIGrowthForecastHandler)
+	e.GET("/api/replication/lag", handlers.APIReplicationLagHandler)
+	e.GET("/api/topology", handlers.APITopologyHandler)
+	e.GET("/api/idle-trx", handlers.APIIdleTrxHandler, handlers.RequireOperator)
+	e.POST("/api/idle-trx/run", handlers.APIIdleTrxRunHandler, handlers.RequireOperator, handlers.RequireDB)
+	e.POST("/api/diff/upload", handlers.UploadDiffHandler, handlers.RequireOperator, handlers.RequireDB)
+	e.POST("/api/share/tables/:database/:table", handlers.APIShareTableHandler, handlers.RequireOperator, handlers.RequireDB)
+	e.POST("/api/share/diff", handlers.APIShareDiffHandler, handlers.RequireOperator)
+	e.DELETE("/api/share/:token", handlers.APIShareRevokeHandler, handlers.RequireOperator)
+	e.GET("/api/drift", handlers.APIDriftHandler)
+	e.GET("/api/checksum", handlers.APIChecksumHandler)
+	e.POST("/api/checksum/run", handlers.APIChecksumRunHandler, handlers.RequireOperator)
+	e.GET("/api/alerts", handlers.APIAlertsHandler)
+	e.POST("/api/alerts/:id/ack", handlers.APIAlertAckHandler)
+	e.GET("/api/status/badge", handlers.APIStatusBadgeHandler)
+	e.GET("/api/incidents/report", handlers.APIIncidentReportHandler)
+	e.GET("/api/slo", handlers.APISLOHandler)
+	e.GET("/api/capture", handlers.APICaptureHandler, handlers.RequireOperator, handlers.RequireDB)
+	e.GET("/api/checks", handlers.APIChecksHandler)
+	e.GET("/api/about", handlers.APIAboutHandler)
+	e.GET("/api/checks/:name/history", handlers.APICheckHistoryHandler)
+	e.POST("/api/checks/:name/run", handlers.APICheckRunHandler, handlers.RequireOperator)
+	e.POST("/api/tools/format-sql", handlers.APIFormatSQLHandler)
+	e.POST("/api/tools/qualify", handlers.APIQualifySQLHandler)
+	e.GET("/api/databases/:database/find", handlers.APIFindValueHandler, handlers.RequireOperator, handlers.RequireDB)
+	e.GET("/api/databases/:database/dictionary", handlers.APIDictionaryHandler, handlers.RequireDB)
+	e.GET("/api/maintenance", handlers.APIMaintenanceListHandler)
+	e.POST("/api/maintenance", handlers.APIMaintenanceCreateHandler, handlers.RequireOperator)
+	e.DELETE("/api/maintenance/:id", handlers.APIMaintenanceDeleteHandler, handlers.RequireOperator)
+	e.GET("/api/annotations", handlers.APIAnnotationListHandler)
+	e.POST("/api/annotations", handlers.APIAnnotationCreateHandler, handlers.RequireOperator)
+	e.DELETE("/api/annotations/:id", handlers.APIAnnotationDeleteHandler, handlers.RequireOperator)
+
+	// 故障模拟 (开发者模式) API 路由，仅在开启 CHAOS_ENABLED 时注册
+	if config.GetChaosConfig().Enabled {
+		log.Printf("Chaos mode enabled: simulated failures can be injected via /api/chaos")
+		e.GET("/api/chaos", handlers.APIChaosHandler)
+		e.POST("/api/chaos", handlers.APIChaosInjectHandler, handlers.RequireOperator)
+		e.DELETE("/api/chaos", handlers.APIChaosClearHandler, handlers.RequireOperator)
+	}
+
+	// 日志管理 API 路由
+	e.GET("/api/logs", handlers.GetLogsHandler)
+	e.POST("/api/logs", handlers.AddLogHandler, handlers.RequireOperator)
+	e.GET("/api/logs/summary", handlers.GetLogSummaryHandler)
+	e.GET("/api/logs/report", handlers.LogsReportHandler)
+	e.GET("/api/logs/level", handlers.GetLogLevelHandler)
+	e.POST("/api/logs/level", handlers.SetLogLevelHandler)
+	e.POST("/api/logs/clear", handlers.ClearLogsHandler)
+	e.POST("/api/logs/:id/pin", handlers.PinLogHandler)
+	e.DELETE("/api/logs/:id/pin", handlers.UnpinLogHandler)
+
+	// 错误分析 API 路由
+	e.GET("/api/errors/summaries", handlers.GetErrorSummariesHandler)
+	e.GET("/api/errors/top", handlers.GetTopErrorsHandler)
+	e.GET("/api/errors/trends", handlers.GetErrorTrendsHandler)
+	e.GET("/api/errors/trends/outages", handlers.APIErrorOutagesHandler)
+	e.POST("/api/errors/resolve", handlers.MarkErrorResolvedHandler)
+	e.POST("/api/errors/clear", handlers.ClearOldErrorsHandler)
+
+	return e
+}
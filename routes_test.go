@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/handlers"
+)
+
+// TestDemoRoutes 使用演示数据启动完整的路由，确认主要页面和 API 返回演示数据
+func TestDemoRoutes(t *testing.T) {
+	handlers.SetStore(database.UseDemoStore())
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	tests := []struct {
+		path     string
+		accept   string
+		contains []string
+	}{
+		{"/", "text/html", []string{"OK"}},
+		{"/databases", "text/html", []string{"shop", "analytics"}},
+		{"/databases", "application/json", []string{`"databases"`, `"shop"`}},
+		{"/server", "text/html", []string{"binlog.000042", "analytics.nightly_rollup"}},
+		{"/api/databases/shop/tables/orders", "", []string{`"database":"shop"`, `"table":"orders"`}},
+		{"/api/databases", "", []string{`"shop"`, `"analytics"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.accept, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", resp.StatusCode, body)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(string(body), want) {
+					t.Errorf("response does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+// TestDemoTableDetailAPI 演示数据中的表结构详情包含列和索引，不存在的表返回 404
+func TestDemoTableDetailAPI(t *testing.T) {
+	handlers.SetStore(database.UseDemoStore())
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/databases/shop/tables/orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Detail database.TableDetail `json:"detail"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Detail.Fields) == 0 || len(body.Detail.Indexes) == 0 {
+		t.Fatalf("detail has no fields or indexes: %+v", body.Detail)
+	}
+
+	resp, err = http.Get(server.URL + "/api/databases/shop/tables/no_such_table")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("missing table: status = %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,104 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// avroRecord Avro record 类型
+type avroRecord struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Doc       string      `json:"doc,omitempty"`
+	Fields    []avroField `json:"fields"`
+}
+
+// avroField Avro record 的字段
+type avroField struct {
+	Name    string      `json:"name"`
+	Type    interface{} `json:"type"`
+	Doc     string      `json:"doc,omitempty"`
+	Default interface{} `json:"default,omitempty"`
+	// nullDefault 可空字段需要显式输出 "default": null
+	nullDefault bool
+}
+
+// MarshalJSON 可空字段输出 "default": null
+func (f avroField) MarshalJSON() ([]byte, error) {
+	type plain avroField
+	if !f.nullDefault {
+		return json.Marshal(plain(f))
+	}
+	return json.Marshal(struct {
+		plain
+		Default interface{} `json:"default"`
+	}{plain: plain(f)})
+}
+
+// Avro 生成表对应的 Avro schema (.avsc)，可空字段为 ["null", T] 联合类型
+func Avro(databaseName, tableName, comment string, fields []database.TableField, m Mapping) ([]byte, error) {
+	record := avroRecord{
+		Type:      "record",
+		Name:      camelName(tableName),
+		Namespace: identifier(databaseName),
+		Doc:       comment,
+		Fields:    make([]avroField, 0, len(fields)),
+	}
+	for _, f := range fields {
+		field := avroField{Name: identifier(f.Name), Type: avroType(f.Type, m), Doc: f.Comment}
+		if f.IsNullable {
+			field.Type = []interface{}{"null", field.Type}
+			field.nullDefault = true
+		}
+		record.Fields = append(record.Fields, field)
+	}
+	return json.MarshalIndent(record, "", "  ")
+}
+
+// avroType MySQL 类型对应的 Avro 类型，日期时间和 decimal 使用逻辑类型
+func avroType(columnType string, m Mapping) interface{} {
+	if v, ok := m.lookup(FormatAvro, columnType); ok {
+		return v
+	}
+	if isBoolean(columnType) {
+		return "boolean"
+	}
+
+	switch baseType(columnType) {
+	case "tinyint", "smallint", "mediumint", "year":
+		return "int"
+	case "int", "integer":
+		if isUnsigned(columnType) {
+			return "long"
+		}
+		return "int"
+	case "bigint":
+		return "long"
+	case "float":
+		return "float"
+	case "double", "real":
+		return "double"
+	case "decimal", "numeric":
+		match := decimalPattern.FindStringSubmatch(strings.ToLower(columnType))
+		if match == nil {
+			return "string"
+		}
+		precision, _ := strconv.Atoi(match[1])
+		scale, _ := strconv.Atoi(match[2])
+		return map[string]interface{}{"type": "bytes", "logicalType": "decimal", "precision": precision, "scale": scale}
+	case "date":
+		return map[string]interface{}{"type": "int", "logicalType": "date"}
+	case "datetime", "timestamp":
+		return map[string]interface{}{"type": "long", "logicalType": "timestamp-millis"}
+	case "time":
+		return map[string]interface{}{"type": "long", "logicalType": "time-micros"}
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bit", "geometry", "point",
+		"linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection":
+		return "bytes"
+	}
+	return "string"
+}
@@ -0,0 +1,132 @@
+// Package schemagen 根据表结构生成 Avro schema 和 Protobuf message，供 CDC 和流式消费者使用
+package schemagen
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+// 输出格式
+const (
+	FormatAvro  = "avro"
+	FormatProto = "proto"
+)
+
+// Mapping 类型映射覆盖，键为格式和 MySQL 类型，值为目标类型
+// MySQL 类型可以是完整的列类型 (如 tinyint(1)) 或基础类型 (如 datetime)，完整类型优先
+type Mapping map[string]map[string]string
+
+// ParseMapping 解析 format:mysqltype=type 形式的规则
+func ParseMapping(rules []string) (Mapping, error) {
+	m := Mapping{}
+	for _, rule := range rules {
+		target, value, ok := strings.Cut(rule, "=")
+		format, mysqlType, ok2 := strings.Cut(target, ":")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("type mapping %q must be format:mysqltype=type", rule)
+		}
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format != FormatAvro && format != FormatProto {
+			return nil, fmt.Errorf("unknown format %q in type mapping %q", format, rule)
+		}
+		mysqlType = strings.ToLower(strings.TrimSpace(mysqlType))
+		value = strings.TrimSpace(value)
+		if mysqlType == "" || value == "" {
+			return nil, fmt.Errorf("type mapping %q must be format:mysqltype=type", rule)
+		}
+		if m[format] == nil {
+			m[format] = make(map[string]string)
+		}
+		m[format][mysqlType] = value
+	}
+	return m, nil
+}
+
+// lookup 查找列类型的覆盖映射
+func (m Mapping) lookup(format, columnType string) (string, bool) {
+	overrides := m[format]
+	if overrides == nil {
+		return "", false
+	}
+	if v, ok := overrides[strings.ToLower(columnType)]; ok {
+		return v, true
+	}
+	v, ok := overrides[baseType(columnType)]
+	return v, ok
+}
+
+var (
+	mapping     Mapping
+	mappingOnce sync.Once
+)
+
+// GetMapping 获取按配置解析的类型映射，配置无效时记录错误并使用默认映射
+func GetMapping() Mapping {
+	mappingOnce.Do(func() {
+		var err error
+		mapping, err = ParseMapping(config.GetSchemaGenConfig().TypeMapping)
+		if err != nil {
+			log.Printf("Invalid SCHEMAGEN_TYPE_MAPPING, using defaults: %v", err)
+			mapping = Mapping{}
+		}
+	})
+	return mapping
+}
+
+// baseType 去掉长度、精度和属性后的类型名，如 int(10) unsigned 返回 int
+func baseType(columnType string) string {
+	t := strings.ToLower(strings.TrimSpace(columnType))
+	if i := strings.IndexAny(t, "( "); i >= 0 {
+		t = t[:i]
+	}
+	return t
+}
+
+// isUnsigned 判断数值类型是否为无符号
+func isUnsigned(columnType string) bool {
+	return strings.Contains(strings.ToLower(columnType), "unsigned")
+}
+
+// isBoolean tinyint(1) 按惯例视为布尔值
+func isBoolean(columnType string) bool {
+	return strings.HasPrefix(strings.ToLower(columnType), "tinyint(1)")
+}
+
+// decimalPattern 匹配 decimal(p,s)
+var decimalPattern = regexp.MustCompile(`^(?:decimal|numeric)\((\d+)(?:,\s*(\d+))?\)`)
+
+// identifier 将名称转换为合法的标识符，非法字符替换为下划线，数字开头时加下划线前缀
+func identifier(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	s := b.String()
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "_" + s
+	}
+	return s
+}
+
+// camelName 将表名转换为大驼峰形式的类型名，如 order_items 返回 OrderItems
+func camelName(name string) string {
+	parts := strings.FieldsFunc(identifier(name), func(r rune) bool { return r == '_' })
+	var b strings.Builder
+	for _, p := range parts {
+		b.WriteString(strings.ToUpper(p[:1]) + p[1:])
+	}
+	if b.Len() == 0 || unicode.IsDigit(rune(b.String()[0])) {
+		return "T" + b.String()
+	}
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package schemagen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// timestampType 日期时间列使用的 well-known type
+const timestampType = "google.protobuf.Timestamp"
+
+// Proto 生成表对应的 proto3 message，字段编号按列的定义顺序分配，可空字段使用 optional
+func Proto(databaseName, tableName, comment string, fields []database.TableField, m Mapping) []byte {
+	var body strings.Builder
+	imports := map[string]bool{}
+	for i, f := range fields {
+		typ := protoType(f.Type, m)
+		if typ == timestampType {
+			imports["google/protobuf/timestamp.proto"] = true
+		}
+		if f.Comment != "" {
+			fmt.Fprintf(&body, "  // %s\n", singleLine(f.Comment))
+		}
+		label := ""
+		if f.IsNullable {
+			label = "optional "
+		}
+		fmt.Fprintf(&body, "  %s%s %s = %d;\n", label, typ, strings.ToLower(identifier(f.Name)), i+1)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// 由 block-checker 根据 %s.%s 的表结构生成\n", databaseName, tableName)
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&b, "package %s;\n\n", strings.ToLower(identifier(databaseName)))
+	if imports["google/protobuf/timestamp.proto"] {
+		b.WriteString("import \"google/protobuf/timestamp.proto\";\n\n")
+	}
+	if comment != "" {
+		fmt.Fprintf(&b, "// %s\n", singleLine(comment))
+	}
+	fmt.Fprintf(&b, "message %s {\n%s}\n", camelName(tableName), body.String())
+	return []byte(b.String())
+}
+
+// protoType MySQL 类型对应的 proto3 标量类型，decimal 以字符串保存避免精度损失
+func protoType(columnType string, m Mapping) string {
+	if v, ok := m.lookup(FormatProto, columnType); ok {
+		return v
+	}
+	if isBoolean(columnType) {
+		return "bool"
+	}
+
+	unsigned := isUnsigned(columnType)
+	switch baseType(columnType) {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "year":
+		if unsigned {
+			return "uint32"
+		}
+		return "int32"
+	case "bigint":
+		if unsigned {
+			return "uint64"
+		}
+		return "int64"
+	case "float":
+		return "float"
+	case "double", "real":
+		return "double"
+	case "datetime", "timestamp":
+		return timestampType
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bit", "geometry", "point",
+		"linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection":
+		return "bytes"
+	}
+	return "string"
+}
+
+// singleLine 将多行注释合并为一行
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
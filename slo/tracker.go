@@ -0,0 +1,261 @@
+package slo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// 各维度在综合评分中的权重，连接不可用时综合评分直接为 0
+const (
+	latencyWeight    = 0.4
+	replicaLagWeight = 0.3
+	blockedWeight    = 0.3
+)
+
+// maxPoints 评分历史的容量上限
+const maxPoints = 100000
+
+// burnRateWindows 报告错误预算消耗速率的时间窗口
+var burnRateWindows = []time.Duration{time.Hour, 6 * time.Hour, 24 * time.Hour, 72 * time.Hour}
+
+// Components 各维度得分 (0-100)
+type Components struct {
+	Connectivity float64 `json:"connectivity"`
+	Latency      float64 `json:"latency"`
+	ReplicaLag   float64 `json:"replica_lag"`
+	Blocked      float64 `json:"blocked_sessions"`
+}
+
+// Point 一次健康评分
+type Point struct {
+	Time        time.Time  `json:"time"`
+	Score       float64    `json:"score"`
+	Components  Components `json:"components"`
+	Good        bool       `json:"good"`
+	Maintenance bool       `json:"maintenance,omitempty"` // 维护窗口内的评分不计入 SLO
+}
+
+// Tracker 定期计算健康评分并保留历史，用于 SLO 达成率和错误预算消耗速率
+type Tracker struct {
+	mu       sync.Mutex
+	store    database.Store
+	cfg      *config.SLOConfig
+	points   []Point
+	capacity int
+	stop     chan struct{}
+	logger   *database.ComponentLogger
+}
+
+// NewTracker 根据配置创建评分器
+func NewTracker(store database.Store, cfg *config.SLOConfig) *Tracker {
+	capacity := maxPoints
+	if cfg.Interval > 0 {
+		if n := int(cfg.Window / cfg.Interval); n > 0 && n < capacity {
+			capacity = n
+		}
+	}
+	return &Tracker{
+		store:    store,
+		cfg:      cfg,
+		capacity: capacity,
+		logger:   database.GetDatabaseLogger().Component(database.ComponentScheduler),
+	}
+}
+
+// Start 按间隔开始评分
+func (t *Tracker) Start(interval time.Duration) {
+	t.mu.Lock()
+	if t.stop != nil || interval <= 0 {
+		t.mu.Unlock()
+		return
+	}
+	t.stop = make(chan struct{})
+	stop := t.stop
+	t.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		t.Evaluate(context.Background())
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				t.Evaluate(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop 停止评分
+func (t *Tracker) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop != nil {
+		close(t.stop)
+		t.stop = nil
+	}
+}
+
+// Evaluate 立即计算一次健康评分并记录
+func (t *Tracker) Evaluate(ctx context.Context) Point {
+	now := time.Now()
+	point := Point{Time: now, Maintenance: database.InMaintenance()}
+
+	if status := t.store.CheckStatus(ctx); status.Status == "OK" {
+		point.Components = t.components(ctx)
+		point.Score = math.Round(latencyWeight*point.Components.Latency +
+			replicaLagWeight*point.Components.ReplicaLag +
+			blockedWeight*point.Components.Blocked)
+	}
+	point.Good = point.Score >= t.cfg.GoodScore
+
+	t.mu.Lock()
+	if len(t.points) >= t.capacity {
+		t.points = t.points[1:]
+	}
+	t.points = append(t.points, point)
+	t.mu.Unlock()
+	return point
+}
+
+// LastEvaluated 最近一次评分的时间，尚未评分时为零值
+func (t *Tracker) LastEvaluated() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.points) == 0 {
+		return time.Time{}
+	}
+	return t.points[len(t.points)-1].Time
+}
+
+// components 计算连接可用时的各维度得分，无法获取的维度按满分处理
+func (t *Tracker) components(ctx context.Context) Components {
+	c := Components{Connectivity: 100, Latency: 100, ReplicaLag: 100, Blocked: 100}
+
+	// 评分间隔内所有状态检查 (含页面访问和心跳触发的检查) 的 p95
+	window := t.cfg.Interval
+	if window <= 0 {
+		window = time.Minute
+	}
+	now := time.Now()
+	if stats := database.GetIncidentRecorder().LatencyStats(now.Add(-window), now); stats.Samples > 0 {
+		target := float64(t.cfg.LatencyTarget) / float64(time.Millisecond)
+		c.Latency = linearScore(stats.P95, target, 5*target)
+	}
+
+	if lag, err := t.store.GetReplicaLag(ctx); err != nil {
+		t.logger.Debug("健康评分: 获取复制延迟失败", err.Error())
+	} else if lag != nil {
+		target := t.cfg.ReplicaLagTarget.Seconds()
+		c.ReplicaLag = linearScore(float64(*lag), target, 10*target)
+	}
+
+	if blocked, err := t.store.CountBlockedSessions(ctx); err != nil {
+		t.logger.Debug("健康评分: 获取锁等待会话数失败", err.Error())
+	} else if over := blocked - t.cfg.BlockedSessionsTarget; over > 0 {
+		c.Blocked = math.Max(0, 100-20*float64(over))
+	}
+	return c
+}
+
+// linearScore 取值不超过 good 时为 100，不低于 bad 时为 0，之间线性递减
+func linearScore(value, good, bad float64) float64 {
+	switch {
+	case value <= good:
+		return 100
+	case value >= bad:
+		return 0
+	}
+	return math.Round(100 * (bad - value) / (bad - good))
+}
+
+// Report SLO 报告
+type Report struct {
+	Target               float64            `json:"target"`
+	Window               string             `json:"window"`
+	GoodScore            float64            `json:"good_score"`
+	Current              *Point             `json:"current"`
+	Samples              int                `json:"samples"`                // 统计窗口内计入 SLO 的评分数
+	Compliance           *float64           `json:"compliance"`             // 达标评分占比 (百分比)
+	ErrorBudgetRemaining *float64           `json:"error_budget_remaining"` // 剩余错误预算 (百分比，可为负)
+	BurnRates            map[string]float64 `json:"burn_rates"`             // 各时间窗口的错误预算消耗速率，1 表示恰好在窗口结束时耗尽
+	History              []Point            `json:"history"`
+}
+
+// Report 生成 SLO 报告，history 为附带的最近评分数
+func (t *Tracker) Report(history int) *Report {
+	t.mu.Lock()
+	points := make([]Point, len(t.points))
+	copy(points, t.points)
+	t.mu.Unlock()
+
+	now := time.Now()
+	budget := 1 - t.cfg.Target/100
+	report := &Report{
+		Target:    t.cfg.Target,
+		Window:    t.cfg.Window.String(),
+		GoodScore: t.cfg.GoodScore,
+		BurnRates: make(map[string]float64),
+		History:   []Point{},
+	}
+	if len(points) > 0 {
+		report.Current = &points[len(points)-1]
+	}
+
+	if good, total := countGood(points, now.Add(-t.cfg.Window)); total > 0 {
+		report.Samples = total
+		compliance := round2(100 * float64(good) / float64(total))
+		report.Compliance = &compliance
+		if budget > 0 {
+			remaining := round2(100 * (1 - (1-float64(good)/float64(total))/budget))
+			report.ErrorBudgetRemaining = &remaining
+		}
+	}
+	if budget > 0 {
+		for _, w := range burnRateWindows {
+			if good, total := countGood(points, now.Add(-w)); total > 0 {
+				report.BurnRates[formatWindow(w)] = round2((1 - float64(good)/float64(total)) / budget)
+			}
+		}
+	}
+
+	if history > len(points) {
+		history = len(points)
+	}
+	if history > 0 {
+		report.History = points[len(points)-history:]
+	}
+	return report
+}
+
+// countGood 统计指定时间之后计入 SLO 的评分数和其中达标的数量
+func countGood(points []Point, since time.Time) (good, total int) {
+	for _, p := range points {
+		if p.Time.Before(since) || p.Maintenance {
+			continue
+		}
+		total++
+		if p.Good {
+			good++
+		}
+	}
+	return good, total
+}
+
+// formatWindow 将窗口格式化为 1h、24h 这样的简写
+func formatWindow(d time.Duration) string {
+	return fmt.Sprintf("%dh", int(d.Hours()))
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
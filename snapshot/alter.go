@@ -0,0 +1,246 @@
+package snapshot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// parseAlterTable 解析 ALTER TABLE 语句并应用到对应的表
+func (p *parser) parseAlterTable(schema *DatabaseSchema) error {
+	name := p.qualifiedName()
+	table := findTable(schema, name)
+	if table == nil {
+		return fmt.Errorf("alter unknown table %s", name)
+	}
+
+	for !p.done() {
+		if err := p.parseAlterSpec(schema, table); err != nil {
+			return fmt.Errorf("alter table %s: %w", name, err)
+		}
+		p.skipElement()
+		if !p.accept(",") {
+			break
+		}
+	}
+	finishTable(table)
+	return nil
+}
+
+// parseAlterSpec 解析单个 ALTER 子句
+func (p *parser) parseAlterSpec(schema *DatabaseSchema, table *TableSchema) error {
+	switch {
+	case p.accept("ADD"):
+		if p.accept("COLUMN") && p.peek().is("(") || p.peek().is("(") {
+			// ADD [COLUMN] (col1 def, col2 def)
+			p.accept("(")
+			for !p.done() {
+				if err := p.parseTableElement(table); err != nil {
+					return err
+				}
+				if p.accept(")") {
+					break
+				}
+				if err := p.expect(","); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return p.parseTableElement(table)
+
+	case p.accept("MODIFY"):
+		p.accept("COLUMN")
+		field, primary, unique, err := p.parseColumn()
+		if err != nil {
+			return err
+		}
+		replaceColumn(table, field.Name, field, primary, unique)
+
+	case p.accept("CHANGE"):
+		p.accept("COLUMN")
+		oldName := p.name()
+		field, primary, unique, err := p.parseColumn()
+		if err != nil {
+			return err
+		}
+		replaceColumn(table, oldName, field, primary, unique)
+
+	case p.acceptWords("DROP", "PRIMARY", "KEY"):
+		dropIndex(table, "PRIMARY")
+		for i := range table.Fields {
+			table.Fields[i].IsPrimary = false
+		}
+
+	case p.acceptWords("DROP", "FOREIGN", "KEY"):
+		dropConstraint(table, p.name())
+
+	case p.acceptWords("DROP", "INDEX"), p.acceptWords("DROP", "KEY"):
+		dropIndex(table, p.name())
+
+	case p.acceptWords("DROP", "CHECK"), p.acceptWords("DROP", "CONSTRAINT"):
+		name := p.name()
+		dropConstraint(table, name)
+		dropIndex(table, name)
+
+	case p.accept("DROP"):
+		p.accept("COLUMN")
+		dropColumn(table, p.name())
+
+	case p.acceptWords("RENAME", "COLUMN"):
+		from := p.name()
+		if err := p.expect("TO"); err != nil {
+			return err
+		}
+		renameColumn(table, from, p.name())
+
+	case p.acceptWords("RENAME", "INDEX"), p.acceptWords("RENAME", "KEY"):
+		from := p.name()
+		if err := p.expect("TO"); err != nil {
+			return err
+		}
+		to := p.name()
+		for i := range table.Indexes {
+			if strings.EqualFold(table.Indexes[i].Name, from) {
+				table.Indexes[i].Name = to
+			}
+		}
+		for i := range table.Constraints {
+			if strings.EqualFold(table.Constraints[i].Name, from) {
+				table.Constraints[i].Name = to
+			}
+		}
+
+	case p.accept("RENAME"):
+		_ = p.accept("TO") || p.accept("AS")
+		table.Name = p.qualifiedName()
+
+	case p.accept("COMMENT"):
+		p.accept("=")
+		table.Comment = p.next().text
+	}
+	return nil
+}
+
+// replaceColumn 用新定义替换字段，保留主键标记与字段位置
+func replaceColumn(table *TableSchema, oldName string, field *database.TableField, primary, unique bool) {
+	for i := range table.Fields {
+		if !strings.EqualFold(table.Fields[i].Name, oldName) {
+			continue
+		}
+		if table.Fields[i].IsPrimary {
+			field.IsPrimary = true
+			field.IsNullable = false
+		}
+		table.Fields[i] = *field
+		if !strings.EqualFold(oldName, field.Name) {
+			renameColumnRefs(table, oldName, field.Name)
+		}
+		break
+	}
+	if primary {
+		addPrimaryKey(table, []string{field.Name})
+	}
+	if unique {
+		addIndex(table, field.Name, []string{field.Name}, true)
+		table.Constraints = append(table.Constraints, database.TableConstraint{Name: field.Name, Type: "UNIQUE", Columns: []string{field.Name}})
+	}
+}
+
+// renameColumn 重命名字段
+func renameColumn(table *TableSchema, from, to string) {
+	for i := range table.Fields {
+		if strings.EqualFold(table.Fields[i].Name, from) {
+			table.Fields[i].Name = to
+		}
+	}
+	renameColumnRefs(table, from, to)
+}
+
+// renameColumnRefs 更新索引与约束中对字段的引用
+func renameColumnRefs(table *TableSchema, from, to string) {
+	for i := range table.Indexes {
+		for j, col := range table.Indexes[i].Columns {
+			if strings.EqualFold(col, from) {
+				table.Indexes[i].Columns[j] = to
+			}
+		}
+	}
+	for i := range table.Constraints {
+		for j, col := range table.Constraints[i].Columns {
+			if strings.EqualFold(col, from) {
+				table.Constraints[i].Columns[j] = to
+			}
+		}
+	}
+}
+
+// dropColumn 删除字段，并从索引中移除该字段 (索引为空时一并删除)
+func dropColumn(table *TableSchema, name string) {
+	for i := range table.Fields {
+		if strings.EqualFold(table.Fields[i].Name, name) {
+			table.Fields = append(table.Fields[:i], table.Fields[i+1:]...)
+			break
+		}
+	}
+
+	indexes := table.Indexes[:0]
+	for _, idx := range table.Indexes {
+		idx.Columns = removeString(idx.Columns, name)
+		if len(idx.Columns) > 0 {
+			indexes = append(indexes, idx)
+		}
+	}
+	table.Indexes = indexes
+
+	constraints := table.Constraints[:0]
+	for _, c := range table.Constraints {
+		c.Columns = removeString(c.Columns, name)
+		if len(c.Columns) > 0 {
+			constraints = append(constraints, c)
+		}
+	}
+	table.Constraints = constraints
+}
+
+// dropIndex 删除索引及同名的唯一/主键约束
+func dropIndex(table *TableSchema, name string) {
+	indexes := table.Indexes[:0]
+	for _, idx := range table.Indexes {
+		if !strings.EqualFold(idx.Name, name) {
+			indexes = append(indexes, idx)
+		}
+	}
+	table.Indexes = indexes
+
+	constraints := table.Constraints[:0]
+	for _, c := range table.Constraints {
+		if !strings.EqualFold(c.Name, name) || c.Type == "FOREIGN KEY" {
+			constraints = append(constraints, c)
+		}
+	}
+	table.Constraints = constraints
+}
+
+// dropConstraint 删除约束，外键自动创建的索引保留 (与 MySQL 行为一致)
+func dropConstraint(table *TableSchema, name string) {
+	constraints := table.Constraints[:0]
+	for _, c := range table.Constraints {
+		if !strings.EqualFold(c.Name, name) {
+			constraints = append(constraints, c)
+		}
+	}
+	table.Constraints = constraints
+}
+
+// removeString 从切片中移除指定值 (忽略大小写)
+func removeString(values []string, target string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !strings.EqualFold(v, target) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,199 @@
+package snapshot
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// DiffReport 两份数据库结构的差异报告
+type DiffReport struct {
+	Source             string      `json:"source"`
+	Target             string      `json:"target"`
+	Identical          bool        `json:"identical"`
+	TablesOnlyInSource []string    `json:"tables_only_in_source"`
+	TablesOnlyInTarget []string    `json:"tables_only_in_target"`
+	ChangedTables      []TableDiff `json:"changed_tables"`
+}
+
+// TableDiff 单个表的结构差异
+type TableDiff struct {
+	Name                string        `json:"name"`
+	ColumnsOnlyInSource []string      `json:"columns_only_in_source,omitempty"`
+	ColumnsOnlyInTarget []string      `json:"columns_only_in_target,omitempty"`
+	ChangedColumns      []ElementDiff `json:"changed_columns,omitempty"`
+	IndexesOnlyInSource []string      `json:"indexes_only_in_source,omitempty"`
+	IndexesOnlyInTarget []string      `json:"indexes_only_in_target,omitempty"`
+	ChangedIndexes      []ElementDiff `json:"changed_indexes,omitempty"`
+}
+
+// ElementDiff 字段或索引在两侧的定义
+type ElementDiff struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// empty 判断表差异是否为空
+func (d *TableDiff) empty() bool {
+	return len(d.ColumnsOnlyInSource) == 0 && len(d.ColumnsOnlyInTarget) == 0 && len(d.ChangedColumns) == 0 &&
+		len(d.IndexesOnlyInSource) == 0 && len(d.IndexesOnlyInTarget) == 0 && len(d.ChangedIndexes) == 0
+}
+
+// Compare 比较两个数据库的结构，source 通常为期望结构，target 为实际结构
+func Compare(sourceLabel string, source *DatabaseSchema, targetLabel string, target *DatabaseSchema) *DiffReport {
+	report := &DiffReport{
+		Source:             sourceLabel,
+		Target:             targetLabel,
+		TablesOnlyInSource: []string{},
+		TablesOnlyInTarget: []string{},
+		ChangedTables:      []TableDiff{},
+	}
+
+	sourceTables := indexTables(source)
+	targetTables := indexTables(target)
+
+	for _, name := range sortedKeys(sourceTables) {
+		targetTable, ok := targetTables[strings.ToLower(name)]
+		if !ok {
+			report.TablesOnlyInSource = append(report.TablesOnlyInSource, sourceTables[name].Name)
+			continue
+		}
+		if diff := compareTable(sourceTables[name], targetTable); !diff.empty() {
+			report.ChangedTables = append(report.ChangedTables, *diff)
+		}
+	}
+	for _, name := range sortedKeys(targetTables) {
+		if _, ok := sourceTables[name]; !ok {
+			report.TablesOnlyInTarget = append(report.TablesOnlyInTarget, targetTables[name].Name)
+		}
+	}
+
+	report.Identical = len(report.TablesOnlyInSource) == 0 && len(report.TablesOnlyInTarget) == 0 &&
+		len(report.ChangedTables) == 0
+	return report
+}
+
+// compareTable 比较单个表的字段和索引
+func compareTable(source, target TableSchema) *TableDiff {
+	diff := &TableDiff{Name: source.Name}
+
+	sourceFields := make(map[string]database.TableField)
+	for _, f := range source.Fields {
+		sourceFields[strings.ToLower(f.Name)] = f
+	}
+	targetFields := make(map[string]database.TableField)
+	for _, f := range target.Fields {
+		targetFields[strings.ToLower(f.Name)] = f
+	}
+	for _, name := range sortedKeys(sourceFields) {
+		t, ok := targetFields[name]
+		if !ok {
+			diff.ColumnsOnlyInSource = append(diff.ColumnsOnlyInSource, sourceFields[name].Name)
+			continue
+		}
+		if a, b := describeField(sourceFields[name]), describeField(t); a != b {
+			diff.ChangedColumns = append(diff.ChangedColumns, ElementDiff{Name: t.Name, Source: a, Target: b})
+		}
+	}
+	for _, name := range sortedKeys(targetFields) {
+		if _, ok := sourceFields[name]; !ok {
+			diff.ColumnsOnlyInTarget = append(diff.ColumnsOnlyInTarget, targetFields[name].Name)
+		}
+	}
+
+	sourceIndexes := make(map[string]database.TableIndex)
+	for _, idx := range source.Indexes {
+		sourceIndexes[strings.ToLower(idx.Name)] = idx
+	}
+	targetIndexes := make(map[string]database.TableIndex)
+	for _, idx := range target.Indexes {
+		targetIndexes[strings.ToLower(idx.Name)] = idx
+	}
+	for _, name := range sortedKeys(sourceIndexes) {
+		t, ok := targetIndexes[name]
+		if !ok {
+			diff.IndexesOnlyInSource = append(diff.IndexesOnlyInSource, sourceIndexes[name].Name)
+			continue
+		}
+		if a, b := describeIndex(sourceIndexes[name]), describeIndex(t); a != b {
+			diff.ChangedIndexes = append(diff.ChangedIndexes, ElementDiff{Name: t.Name, Source: a, Target: b})
+		}
+	}
+	for _, name := range sortedKeys(targetIndexes) {
+		if _, ok := sourceIndexes[name]; !ok {
+			diff.IndexesOnlyInTarget = append(diff.IndexesOnlyInTarget, targetIndexes[name].Name)
+		}
+	}
+
+	return diff
+}
+
+// intDisplayWidth 匹配整数类型的显示宽度，MySQL 8.0 起不再显示
+var intDisplayWidth = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|integer|bigint)\(\d+\)`)
+
+// normalizeType 规范化字段类型以忽略版本间的展示差异
+func normalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if !strings.HasPrefix(t, "tinyint(1)") {
+		t = intDisplayWidth.ReplaceAllString(t, "$1")
+	}
+	return strings.Replace(t, "integer", "int", 1)
+}
+
+// describeField 生成字段定义的可比较描述
+func describeField(f database.TableField) string {
+	desc := normalizeType(f.Type)
+	if f.IsNullable {
+		desc += " NULL"
+	} else {
+		desc += " NOT NULL"
+	}
+	if f.Default != nil {
+		desc += " DEFAULT " + *f.Default
+	}
+	return desc
+}
+
+// describeIndex 生成索引定义的可比较描述
+func describeIndex(idx database.TableIndex) string {
+	desc := "(" + strings.ToLower(strings.Join(idx.Columns, ",")) + ")"
+	if idx.Unique {
+		desc = "UNIQUE " + desc
+	}
+	return desc
+}
+
+// indexTables 以小写表名建立索引
+func indexTables(schema *DatabaseSchema) map[string]TableSchema {
+	tables := make(map[string]TableSchema)
+	if schema == nil {
+		return tables
+	}
+	for _, t := range schema.Tables {
+		tables[strings.ToLower(t.Name)] = t
+	}
+	return tables
+}
+
+// sortedKeys 返回排序后的映射键
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Summary 差异报告的一行摘要
+func (r *DiffReport) Summary() string {
+	if r.Identical {
+		return fmt.Sprintf("%s 与 %s 结构一致", r.Source, r.Target)
+	}
+	return fmt.Sprintf("%s 与 %s 存在差异: 仅在前者 %d 个表, 仅在后者 %d 个表, 结构不同 %d 个表",
+		r.Source, r.Target, len(r.TablesOnlyInSource), len(r.TablesOnlyInTarget), len(r.ChangedTables))
+}
@@ -0,0 +1,121 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// Exporter 定期将结构快照导出到对象存储，并只保留最近的若干份
+type Exporter struct {
+	mu        sync.Mutex
+	store     database.Store
+	client    *S3Client
+	prefix    string
+	retention int
+	stop      chan struct{}
+	logger    *database.ComponentLogger
+}
+
+// NewExporter 根据配置创建导出器
+func NewExporter(store database.Store, cfg *config.SnapshotConfig) *Exporter {
+	return &Exporter{
+		store:     store,
+		client:    NewS3Client(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey),
+		prefix:    cfg.S3Prefix,
+		retention: cfg.Retention,
+		logger:    database.GetDatabaseLogger().Component(database.ComponentScheduler),
+	}
+}
+
+// Start 按间隔开始导出
+func (e *Exporter) Start(interval time.Duration) {
+	e.mu.Lock()
+	if e.stop != nil || interval <= 0 {
+		e.mu.Unlock()
+		return
+	}
+	e.stop = make(chan struct{})
+	stop := e.stop
+	e.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := e.Export(context.Background()); err != nil {
+					e.logger.Warn("结构快照导出失败", err.Error())
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止导出
+func (e *Exporter) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stop != nil {
+		close(e.stop)
+		e.stop = nil
+	}
+}
+
+// Export 立即生成并上传一份快照，然后清理超出保留数的旧快照
+func (e *Exporter) Export(ctx context.Context) error {
+	snap, err := Build(ctx, e.store)
+	if err != nil {
+		return fmt.Errorf("build snapshot: %w", err)
+	}
+	body, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	// 键名使用 UTC 时间戳，按字典序即可得到时间顺序
+	key := e.prefix + "schema-" + snap.GeneratedAt.UTC().Format("20060102T150405Z") + ".json"
+	if err := e.client.PutObject(ctx, key, body, "application/json"); err != nil {
+		return fmt.Errorf("upload snapshot: %w", err)
+	}
+	e.logger.Info(fmt.Sprintf("结构快照已导出: %s", key))
+
+	return e.prune(ctx)
+}
+
+// prune 删除超出保留数的旧快照
+func (e *Exporter) prune(ctx context.Context) error {
+	if e.retention <= 0 {
+		return nil
+	}
+	keys, err := e.client.ListObjects(ctx, e.prefix+"schema-")
+	if err != nil {
+		return fmt.Errorf("list snapshots: %w", err)
+	}
+
+	var snapshots []string
+	for _, key := range keys {
+		if strings.HasSuffix(key, ".json") {
+			snapshots = append(snapshots, key)
+		}
+	}
+	sort.Strings(snapshots)
+
+	for len(snapshots) > e.retention {
+		if err := e.client.DeleteObject(ctx, snapshots[0]); err != nil {
+			return fmt.Errorf("delete snapshot %s: %w", snapshots[0], err)
+		}
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
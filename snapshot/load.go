@@ -0,0 +1,46 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Load 从导出的快照 (JSON) 或 DDL 脚本 (SQL) 中读取指定数据库的结构
+// name 为空时，若文件只包含一个数据库则直接使用它
+func Load(data []byte, name string) (*DatabaseSchema, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("empty schema file")
+	}
+	if trimmed[0] != '{' {
+		return ParseSQL(name, string(data))
+	}
+
+	var snap SchemaSnapshot
+	if err := json.Unmarshal(trimmed, &snap); err != nil {
+		return nil, fmt.Errorf("decode schema snapshot: %w", err)
+	}
+	if len(snap.Databases) == 0 {
+		// 单个数据库的结构文档
+		var schema DatabaseSchema
+		if err := json.Unmarshal(trimmed, &schema); err != nil || schema.Tables == nil {
+			return nil, fmt.Errorf("schema file contains no databases")
+		}
+		return &schema, nil
+	}
+
+	if name == "" {
+		if len(snap.Databases) == 1 {
+			return &snap.Databases[0], nil
+		}
+		return nil, fmt.Errorf("schema file contains %d databases, please specify one", len(snap.Databases))
+	}
+	for i := range snap.Databases {
+		if strings.EqualFold(snap.Databases[i].Name, name) {
+			return &snap.Databases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("database %s not found in schema file", name)
+}
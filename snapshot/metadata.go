@@ -0,0 +1,157 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// MetadataSchemaVersion 元数据文档的格式标识，字段含义发生不兼容变化时递增
+// 新增字段不视为不兼容变化，消费方应忽略未知字段
+const MetadataSchemaVersion = "block-checker.metadata/v1"
+
+// MetadataDocument 单个数据库的完整元数据，供代码生成和文档工具使用
+// 表、索引和外键均按名称排序，字段按定义顺序排列，相同结构总是生成相同的文档 (generated_at 除外)
+type MetadataDocument struct {
+	SchemaVersion string          `json:"schema_version"`
+	GeneratedAt   time.Time       `json:"generated_at"`
+	Database      string          `json:"database"`
+	Tables        []MetadataTable `json:"tables"`
+}
+
+// MetadataTable 表的元数据
+type MetadataTable struct {
+	Name        string               `json:"name"`
+	Comment     string               `json:"comment"`
+	Columns     []MetadataColumn     `json:"columns"`
+	PrimaryKey  []string             `json:"primary_key"`
+	Indexes     []MetadataIndex      `json:"indexes"`
+	ForeignKeys []MetadataForeignKey `json:"foreign_keys"`
+}
+
+// MetadataColumn 字段的元数据
+type MetadataColumn struct {
+	Name          string  `json:"name"`
+	Position      int     `json:"position"` // 从 1 开始
+	Type          string  `json:"type"`     // 完整的列类型，如 varchar(64)、int unsigned
+	Nullable      bool    `json:"nullable"`
+	Default       *string `json:"default"`
+	AutoIncrement bool    `json:"auto_increment"`
+	Generated     string  `json:"generated,omitempty"` // VIRTUAL/STORED
+	Expression    string  `json:"expression,omitempty"`
+	Comment       string  `json:"comment"`
+}
+
+// MetadataIndex 索引的元数据，主键不在此列出
+type MetadataIndex struct {
+	Name     string   `json:"name"`
+	Columns  []string `json:"columns"`
+	SubParts []int64  `json:"sub_parts,omitempty"` // 前缀索引的长度，0 表示整列；没有前缀列时省略
+	Unique   bool     `json:"unique"`
+	Type     string   `json:"type"`
+	Visible  bool     `json:"visible"`
+}
+
+// MetadataForeignKey 外键的元数据
+type MetadataForeignKey struct {
+	Name               string   `json:"name"`
+	Columns            []string `json:"columns"`
+	ReferencedDatabase string   `json:"referenced_database"`
+	ReferencedTable    string   `json:"referenced_table"`
+	ReferencedColumns  []string `json:"referenced_columns"`
+}
+
+// BuildMetadata 生成单个数据库的元数据文档
+func BuildMetadata(ctx context.Context, store database.Store, databaseName string) (*MetadataDocument, error) {
+	schema, err := BuildDatabase(ctx, store, databaseName)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys, err := store.GetForeignKeys(ctx, databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("foreign keys of %s: %w", databaseName, err)
+	}
+
+	doc := &MetadataDocument{
+		SchemaVersion: MetadataSchemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		Database:      databaseName,
+		Tables:        make([]MetadataTable, 0, len(schema.Tables)),
+	}
+	for _, table := range schema.Tables {
+		doc.Tables = append(doc.Tables, metadataTable(table, foreignKeys[table.Name]))
+	}
+	sort.Slice(doc.Tables, func(i, j int) bool { return doc.Tables[i].Name < doc.Tables[j].Name })
+	return doc, nil
+}
+
+// metadataTable 将表结构转换为元数据
+func metadataTable(table TableSchema, foreignKeys []database.ForeignKey) MetadataTable {
+	meta := MetadataTable{
+		Name:        table.Name,
+		Comment:     table.Comment,
+		Columns:     make([]MetadataColumn, 0, len(table.Fields)),
+		PrimaryKey:  []string{},
+		Indexes:     []MetadataIndex{},
+		ForeignKeys: []MetadataForeignKey{},
+	}
+	for i, f := range table.Fields {
+		meta.Columns = append(meta.Columns, MetadataColumn{
+			Name:          f.Name,
+			Position:      i + 1,
+			Type:          f.Type,
+			Nullable:      f.IsNullable,
+			Default:       f.Default,
+			AutoIncrement: strings.Contains(strings.ToLower(f.Extra), "auto_increment"),
+			Generated:     f.Generated,
+			Expression:    f.GenerationExpression,
+			Comment:       f.Comment,
+		})
+	}
+
+	for _, idx := range table.Indexes {
+		if idx.Name == "PRIMARY" {
+			meta.PrimaryKey = append(meta.PrimaryKey, idx.Columns...)
+			continue
+		}
+		indexType := idx.Type
+		if indexType == "" {
+			indexType = "BTREE"
+		}
+		meta.Indexes = append(meta.Indexes, MetadataIndex{
+			Name:     idx.Name,
+			Columns:  idx.Columns,
+			SubParts: prefixLengths(idx.SubParts),
+			Unique:   idx.Unique,
+			Type:     indexType,
+			Visible:  idx.Visible,
+		})
+	}
+	sort.Slice(meta.Indexes, func(i, j int) bool { return meta.Indexes[i].Name < meta.Indexes[j].Name })
+
+	for _, fk := range foreignKeys {
+		meta.ForeignKeys = append(meta.ForeignKeys, MetadataForeignKey{
+			Name:               fk.Name,
+			Columns:            fk.Columns,
+			ReferencedDatabase: fk.ReferencedDatabase,
+			ReferencedTable:    fk.ReferencedTable,
+			ReferencedColumns:  fk.ReferencedColumns,
+		})
+	}
+	sort.Slice(meta.ForeignKeys, func(i, j int) bool { return meta.ForeignKeys[i].Name < meta.ForeignKeys[j].Name })
+	return meta
+}
+
+// prefixLengths 所有键部分均为整列时返回 nil
+func prefixLengths(subParts []int64) []int64 {
+	for _, n := range subParts {
+		if n > 0 {
+			return subParts
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// LoadMigrations 按文件名顺序执行目录中的 *.sql 迁移文件，得到期望的最终结构
+// 回滚脚本 (*.down.sql) 会被跳过
+func LoadMigrations(dir, name string) (*DatabaseSchema, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations dir: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		fileName := strings.ToLower(entry.Name())
+		if entry.IsDir() || !strings.HasSuffix(fileName, ".sql") || strings.HasSuffix(fileName, ".down.sql") {
+			continue
+		}
+		files = append(files, entry.Name())
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no migration files in %s", dir)
+	}
+	sort.Strings(files)
+
+	schema := &DatabaseSchema{Name: name, Tables: []TableSchema{}}
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+		statements, err := splitStatements(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for i, stmt := range statements {
+			if err := applyStatement(schema, stmt); err != nil {
+				return nil, fmt.Errorf("%s: statement %d: %w", file, i+1, err)
+			}
+		}
+	}
+	return schema, nil
+}
+
+// DriftChecker 比较迁移目录的期望结构与在线数据库，检测结构漂移
+type DriftChecker struct {
+	mu        sync.RWMutex
+	store     database.Store
+	dir       string
+	database  string
+	last      *DiffReport
+	lastError string
+	checkedAt time.Time
+	stop      chan struct{}
+	logger    *database.ComponentLogger
+}
+
+// DriftResult 最近一次漂移检测结果
+type DriftResult struct {
+	Report    *DiffReport `json:"report,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	CheckedAt time.Time   `json:"checked_at"`
+}
+
+// NewDriftChecker 根据配置创建漂移检测器
+func NewDriftChecker(store database.Store, cfg *config.MigrationConfig) *DriftChecker {
+	return &DriftChecker{
+		store:    store,
+		dir:      cfg.Dir,
+		database: cfg.Database,
+		logger:   database.GetDatabaseLogger().Component(database.ComponentScheduler),
+	}
+}
+
+// Start 按间隔定期检测，interval 为 0 时不启动
+func (d *DriftChecker) Start(interval time.Duration) {
+	d.mu.Lock()
+	if d.stop != nil || interval <= 0 {
+		d.mu.Unlock()
+		return
+	}
+	d.stop = make(chan struct{})
+	stop := d.stop
+	d.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		d.Check(context.Background())
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				d.Check(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop 停止定期检测
+func (d *DriftChecker) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stop != nil {
+		close(d.stop)
+		d.stop = nil
+	}
+}
+
+// Check 立即执行一次检测，发现漂移时写入警告日志
+func (d *DriftChecker) Check(ctx context.Context) DriftResult {
+	report, err := d.compare(ctx)
+	result := DriftResult{Report: report, CheckedAt: time.Now()}
+	if err != nil {
+		result.Error = err.Error()
+		d.logger.Warn("迁移结构漂移检测失败", err.Error())
+	} else if !report.Identical {
+		d.logger.Warn("检测到结构漂移", report.Summary())
+	}
+
+	d.mu.Lock()
+	d.last = result.Report
+	d.lastError = result.Error
+	d.checkedAt = result.CheckedAt
+	d.mu.Unlock()
+	return result
+}
+
+// Last 获取最近一次检测结果，尚未检测时返回 false
+func (d *DriftChecker) Last() (DriftResult, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.checkedAt.IsZero() {
+		return DriftResult{}, false
+	}
+	return DriftResult{Report: d.last, Error: d.lastError, CheckedAt: d.checkedAt}, true
+}
+
+// compare 加载迁移结构并与在线数据库比较
+func (d *DriftChecker) compare(ctx context.Context) (*DiffReport, error) {
+	expected, err := LoadMigrations(d.dir, d.database)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := BuildDatabase(ctx, d.store, d.database)
+	if err != nil {
+		return nil, fmt.Errorf("build live schema: %w", err)
+	}
+	return Compare("migrations", expected, d.database, actual), nil
+}
@@ -0,0 +1,84 @@
+// Package snapshot 提供数据库结构快照的生成、导出与比较
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// FormatVersion 快照文档格式版本，结构发生不兼容变化时递增
+const FormatVersion = 1
+
+// SchemaSnapshot 数据库结构快照
+type SchemaSnapshot struct {
+	Version     int              `json:"version"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Databases   []DatabaseSchema `json:"databases"`
+}
+
+// DatabaseSchema 单个数据库的结构
+type DatabaseSchema struct {
+	Name   string        `json:"name"`
+	Tables []TableSchema `json:"tables"`
+}
+
+// TableSchema 单个表的结构
+type TableSchema struct {
+	Name        string                     `json:"name"`
+	Comment     string                     `json:"comment,omitempty"`
+	Fields      []database.TableField      `json:"fields"`
+	Indexes     []database.TableIndex      `json:"indexes"`
+	Constraints []database.TableConstraint `json:"constraints"`
+}
+
+// Build 生成全部用户数据库的结构快照
+func Build(ctx context.Context, store database.Store) (*SchemaSnapshot, error) {
+	databases, err := store.GetDatabases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list databases: %w", err)
+	}
+
+	snap := &SchemaSnapshot{
+		Version:     FormatVersion,
+		GeneratedAt: time.Now(),
+		Databases:   make([]DatabaseSchema, 0, len(databases)),
+	}
+	for _, db := range databases {
+		schema, err := BuildDatabase(ctx, store, db.Name)
+		if err != nil {
+			return nil, err
+		}
+		snap.Databases = append(snap.Databases, *schema)
+	}
+	return snap, nil
+}
+
+// BuildDatabase 生成单个数据库的结构
+func BuildDatabase(ctx context.Context, store database.Store, databaseName string) (*DatabaseSchema, error) {
+	tables, err := store.GetTables(ctx, databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("list tables of %s: %w", databaseName, err)
+	}
+
+	schema := &DatabaseSchema{
+		Name:   databaseName,
+		Tables: make([]TableSchema, 0, len(tables)),
+	}
+	for _, table := range tables {
+		detail, err := store.GetTableDetail(ctx, databaseName, table.Name)
+		if err != nil {
+			return nil, fmt.Errorf("describe %s.%s: %w", databaseName, table.Name, err)
+		}
+		schema.Tables = append(schema.Tables, TableSchema{
+			Name:        table.Name,
+			Comment:     table.Comment,
+			Fields:      detail.Fields,
+			Indexes:     detail.Indexes,
+			Constraints: detail.Constraints,
+		})
+	}
+	return schema, nil
+}
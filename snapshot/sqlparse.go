@@ -0,0 +1,681 @@
+package snapshot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/furutachiKurea/block-checker/database"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokenWord   tokenKind = iota // 关键字或未加引号的标识符
+	tokenIdent                   // 反引号或双引号包裹的标识符
+	tokenString                  // 单引号字符串
+	tokenNumber                  // 数字
+	tokenPunct                   // 标点
+)
+
+// token 词法单元
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// is 判断是否为指定关键字或标点 (忽略大小写)
+func (t token) is(word string) bool {
+	return (t.kind == tokenWord || t.kind == tokenPunct) && strings.EqualFold(t.text, word)
+}
+
+// ParseSQL 解析 DDL 脚本，按顺序应用语句得到最终的数据库结构
+// 仅识别影响表结构的语句，其他语句会被忽略
+func ParseSQL(name, script string) (*DatabaseSchema, error) {
+	schema := &DatabaseSchema{Name: name, Tables: []TableSchema{}}
+	statements, err := splitStatements(script)
+	if err != nil {
+		return nil, err
+	}
+	for i, stmt := range statements {
+		if err := applyStatement(schema, stmt); err != nil {
+			return nil, fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	return schema, nil
+}
+
+// applyStatement 将单条语句应用到结构上
+func applyStatement(schema *DatabaseSchema, tokens []token) error {
+	p := &parser{tokens: tokens}
+	switch {
+	case p.acceptWords("CREATE", "TABLE"), p.acceptWords("CREATE", "TEMPORARY", "TABLE"):
+		p.acceptWords("IF", "NOT", "EXISTS")
+		table, err := p.parseCreateTable()
+		if err != nil {
+			return err
+		}
+		removeTable(schema, table.Name)
+		schema.Tables = append(schema.Tables, *table)
+	case p.acceptWords("DROP", "TABLE"), p.acceptWords("DROP", "TEMPORARY", "TABLE"):
+		p.acceptWords("IF", "EXISTS")
+		for {
+			removeTable(schema, p.qualifiedName())
+			if !p.accept(",") {
+				break
+			}
+		}
+	case p.acceptWords("ALTER", "TABLE"), p.acceptWords("ALTER", "ONLINE", "TABLE"), p.acceptWords("ALTER", "IGNORE", "TABLE"):
+		return p.parseAlterTable(schema)
+	case p.acceptWords("RENAME", "TABLE"):
+		for {
+			from := p.qualifiedName()
+			if err := p.expect("TO"); err != nil {
+				return err
+			}
+			to := p.qualifiedName()
+			if table := findTable(schema, from); table != nil {
+				table.Name = to
+			}
+			if !p.accept(",") {
+				break
+			}
+		}
+	case p.accept("CREATE"):
+		unique := p.accept("UNIQUE")
+		_ = p.accept("FULLTEXT") || p.accept("SPATIAL")
+		if !p.accept("INDEX") {
+			return nil
+		}
+		name := p.name()
+		p.skipIndexType()
+		if err := p.expect("ON"); err != nil {
+			return err
+		}
+		table := findTable(schema, p.qualifiedName())
+		if table == nil {
+			return nil
+		}
+		cols := p.indexColumns()
+		addIndex(table, name, cols, unique)
+		if unique {
+			table.Constraints = append(table.Constraints, database.TableConstraint{Name: name, Type: "UNIQUE", Columns: cols})
+		}
+	case p.acceptWords("DROP", "INDEX"):
+		name := p.name()
+		if err := p.expect("ON"); err != nil {
+			return err
+		}
+		if table := findTable(schema, p.qualifiedName()); table != nil {
+			dropIndex(table, name)
+		}
+	}
+	return nil
+}
+
+// findTable 按名称 (忽略大小写) 查找表
+func findTable(schema *DatabaseSchema, name string) *TableSchema {
+	for i := range schema.Tables {
+		if strings.EqualFold(schema.Tables[i].Name, name) {
+			return &schema.Tables[i]
+		}
+	}
+	return nil
+}
+
+// removeTable 移除表
+func removeTable(schema *DatabaseSchema, name string) {
+	for i := range schema.Tables {
+		if strings.EqualFold(schema.Tables[i].Name, name) {
+			schema.Tables = append(schema.Tables[:i], schema.Tables[i+1:]...)
+			return
+		}
+	}
+}
+
+// parser 基于词法单元的递归下降解析器
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// peek 查看当前词法单元
+func (p *parser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokenPunct}
+	}
+	return p.tokens[p.pos]
+}
+
+// next 读取当前词法单元并前进
+func (p *parser) next() token {
+	t := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return t
+}
+
+// done 是否已读完
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// accept 当前单元匹配时前进
+func (p *parser) accept(word string) bool {
+	if p.peek().is(word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// acceptWords 连续匹配多个单元，任一不匹配则不前进
+func (p *parser) acceptWords(words ...string) bool {
+	for i, w := range words {
+		if p.pos+i >= len(p.tokens) || !p.tokens[p.pos+i].is(w) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+// expect 要求当前单元匹配
+func (p *parser) expect(word string) error {
+	if !p.accept(word) {
+		return fmt.Errorf("expected %q, got %q", word, p.peek().text)
+	}
+	return nil
+}
+
+// name 读取一个标识符
+func (p *parser) name() string {
+	return p.next().text
+}
+
+// qualifiedName 读取可能带库名前缀的名称，仅返回对象名
+func (p *parser) qualifiedName() string {
+	n := p.name()
+	if p.accept(".") {
+		n = p.name()
+	}
+	return n
+}
+
+// skipParens 跳过一对括号及其内容，返回括号内的原文
+func (p *parser) skipParens() string {
+	if !p.accept("(") {
+		return ""
+	}
+	var parts []string
+	depth := 1
+	for !p.done() {
+		t := p.next()
+		if t.is("(") {
+			depth++
+		} else if t.is(")") {
+			depth--
+			if depth == 0 {
+				break
+			}
+		}
+		parts = append(parts, t.render())
+	}
+	return joinTokens(parts)
+}
+
+// render 以 SQL 形式还原词法单元
+func (t token) render() string {
+	switch t.kind {
+	case tokenString:
+		return "'" + strings.ReplaceAll(t.text, "'", "''") + "'"
+	case tokenIdent:
+		return "`" + t.text + "`"
+	default:
+		return t.text
+	}
+}
+
+// joinTokens 拼接还原后的词法单元，逗号后不留空格以贴近 information_schema 的格式
+func joinTokens(parts []string) string {
+	var b strings.Builder
+	for i, part := range parts {
+		if i > 0 && part != "," && part != ")" && part != "(" && parts[i-1] != "," && parts[i-1] != "(" {
+			b.WriteByte(' ')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// parseCreateTable 解析 CREATE TABLE 语句的剩余部分
+func (p *parser) parseCreateTable() (*TableSchema, error) {
+	table := &TableSchema{
+		Name:        p.qualifiedName(),
+		Fields:      []database.TableField{},
+		Indexes:     []database.TableIndex{},
+		Constraints: []database.TableConstraint{},
+	}
+	if p.acceptWords("LIKE") {
+		return nil, fmt.Errorf("CREATE TABLE %s LIKE is not supported", table.Name)
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	for !p.done() {
+		if err := p.parseTableElement(table); err != nil {
+			return nil, fmt.Errorf("table %s: %w", table.Name, err)
+		}
+		if p.accept(")") {
+			break
+		}
+		if err := p.expect(","); err != nil {
+			return nil, fmt.Errorf("table %s: %w", table.Name, err)
+		}
+	}
+
+	// 表选项中只关心注释
+	for !p.done() {
+		if p.accept("COMMENT") {
+			p.accept("=")
+			table.Comment = p.next().text
+			continue
+		}
+		p.next()
+	}
+
+	finishTable(table)
+	return table, nil
+}
+
+// parseTableElement 解析表定义中的一个元素 (字段、索引或约束)
+func (p *parser) parseTableElement(table *TableSchema) error {
+	constraintName := ""
+	if p.accept("CONSTRAINT") {
+		if t := p.peek(); !t.is("PRIMARY") && !t.is("UNIQUE") && !t.is("FOREIGN") && !t.is("CHECK") {
+			constraintName = p.name()
+		}
+	}
+
+	switch {
+	case p.acceptWords("PRIMARY", "KEY"):
+		p.skipIndexType()
+		addPrimaryKey(table, p.indexColumns())
+		p.skipIndexOptions()
+	case p.accept("UNIQUE"):
+		_ = p.accept("KEY") || p.accept("INDEX")
+		name := constraintName
+		if !p.peek().is("(") && !p.peek().is("USING") {
+			name = p.name()
+		}
+		p.skipIndexType()
+		cols := p.indexColumns()
+		if name == "" && len(cols) > 0 {
+			name = cols[0]
+		}
+		addIndex(table, name, cols, true)
+		table.Constraints = append(table.Constraints, database.TableConstraint{Name: name, Type: "UNIQUE", Columns: cols})
+		p.skipIndexOptions()
+	case p.accept("KEY"), p.accept("INDEX"),
+		p.acceptWords("FULLTEXT", "KEY"), p.acceptWords("FULLTEXT", "INDEX"), p.accept("FULLTEXT"),
+		p.acceptWords("SPATIAL", "KEY"), p.acceptWords("SPATIAL", "INDEX"), p.accept("SPATIAL"):
+		name := ""
+		if !p.peek().is("(") && !p.peek().is("USING") {
+			name = p.name()
+		}
+		p.skipIndexType()
+		cols := p.indexColumns()
+		if name == "" && len(cols) > 0 {
+			name = cols[0]
+		}
+		addIndex(table, name, cols, false)
+		p.skipIndexOptions()
+	case p.acceptWords("FOREIGN", "KEY"):
+		if !p.peek().is("(") {
+			p.name()
+		}
+		cols := p.indexColumns()
+		if err := p.expect("REFERENCES"); err != nil {
+			return err
+		}
+		refTable := p.qualifiedName()
+		refCols := p.indexColumns()
+		if constraintName == "" {
+			constraintName = fmt.Sprintf("%s_ibfk_%d", table.Name, countForeignKeys(table)+1)
+		}
+		c := database.TableConstraint{Name: constraintName, Type: "FOREIGN KEY", Columns: cols, ReferencedTable: &refTable}
+		if len(refCols) > 0 {
+			c.ReferencedColumn = &refCols[0]
+		}
+		table.Constraints = append(table.Constraints, c)
+		p.skipElement()
+	case p.accept("CHECK"):
+		p.skipElement()
+	default:
+		field, inlinePK, inlineUnique, err := p.parseColumn()
+		if err != nil {
+			return err
+		}
+		table.Fields = append(table.Fields, *field)
+		if inlinePK {
+			addPrimaryKey(table, []string{field.Name})
+		}
+		if inlineUnique {
+			addIndex(table, field.Name, []string{field.Name}, true)
+			table.Constraints = append(table.Constraints, database.TableConstraint{Name: field.Name, Type: "UNIQUE", Columns: []string{field.Name}})
+		}
+	}
+	return nil
+}
+
+// parseColumn 解析字段定义
+func (p *parser) parseColumn() (*database.TableField, bool, bool, error) {
+	field := &database.TableField{Name: p.name(), IsNullable: true}
+	typeName := strings.ToLower(p.next().text)
+	if typeName == "" {
+		return nil, false, false, fmt.Errorf("column %s: missing type", field.Name)
+	}
+	if args := p.skipParens(); args != "" {
+		typeName += "(" + args + ")"
+	}
+	field.Type = typeName
+
+	primary, unique := false, false
+	var extras []string
+	for !p.done() && !p.peek().is(",") && !p.peek().is(")") {
+		switch {
+		case p.accept("UNSIGNED"):
+			field.Type += " unsigned"
+		case p.accept("ZEROFILL"):
+			field.Type += " zerofill"
+		case p.acceptWords("CHARACTER", "SET"), p.accept("CHARSET"), p.accept("COLLATE"):
+			p.next()
+		case p.acceptWords("NOT", "NULL"):
+			field.IsNullable = false
+		case p.accept("NULL"):
+			field.IsNullable = true
+		case p.accept("DEFAULT"):
+			field.Default = p.defaultValue()
+		case p.accept("AUTO_INCREMENT"):
+			extras = append(extras, "auto_increment")
+		case p.acceptWords("ON", "UPDATE"):
+			extras = append(extras, "on update "+strings.ToUpper(p.next().text))
+			p.skipParens()
+		case p.acceptWords("PRIMARY", "KEY"):
+			primary = true
+		case p.accept("KEY"):
+			primary = true
+		case p.accept("UNIQUE"):
+			_ = p.accept("KEY")
+			unique = true
+		case p.accept("COMMENT"):
+			field.Comment = p.next().text
+		case p.acceptWords("GENERATED", "ALWAYS", "AS"), p.accept("AS"):
+			p.skipParens()
+		case p.accept("VIRTUAL"):
+			extras = append(extras, "VIRTUAL GENERATED")
+		case p.accept("STORED"):
+			extras = append(extras, "STORED GENERATED")
+		default:
+			if p.peek().is("(") {
+				p.skipParens()
+			} else {
+				p.next()
+			}
+		}
+	}
+	field.Extra = strings.Join(extras, " ")
+	if primary {
+		field.IsNullable = false
+	}
+	return field, primary, unique, nil
+}
+
+// defaultValue 读取 DEFAULT 子句的值，NULL 返回 nil
+func (p *parser) defaultValue() *string {
+	if p.peek().is("(") {
+		v := "(" + p.skipParens() + ")"
+		return &v
+	}
+	t := p.next()
+	if t.is("NULL") {
+		return nil
+	}
+	v := t.text
+	if t.is("-") || t.is("+") {
+		v += p.next().text
+	}
+	if t.kind == tokenWord {
+		v = strings.ToUpper(v)
+		// CURRENT_TIMESTAMP(3) 等带精度的写法
+		if p.peek().is("(") {
+			v += "(" + p.skipParens() + ")"
+		}
+	}
+	return &v
+}
+
+// indexColumns 读取括号内的索引字段列表，忽略前缀长度和排序方向
+func (p *parser) indexColumns() []string {
+	var cols []string
+	if !p.accept("(") {
+		return cols
+	}
+	for !p.done() {
+		if p.peek().is("(") {
+			// 函数索引表达式
+			cols = append(cols, "("+p.skipParens()+")")
+		} else {
+			cols = append(cols, p.name())
+		}
+		p.skipParens()
+		_ = p.accept("ASC") || p.accept("DESC")
+		if p.accept(")") {
+			break
+		}
+		p.accept(",")
+	}
+	return cols
+}
+
+// skipIndexType 跳过 USING BTREE/HASH
+func (p *parser) skipIndexType() {
+	if p.accept("USING") {
+		p.next()
+	}
+}
+
+// skipIndexOptions 跳过索引选项
+func (p *parser) skipIndexOptions() {
+	p.skipElement()
+}
+
+// skipElement 跳过当前元素剩余部分，直到顶层逗号或右括号
+func (p *parser) skipElement() {
+	for !p.done() && !p.peek().is(",") && !p.peek().is(")") {
+		if p.peek().is("(") {
+			p.skipParens()
+		} else {
+			p.next()
+		}
+	}
+}
+
+// addPrimaryKey 添加主键索引和约束，并标记主键字段
+func addPrimaryKey(table *TableSchema, cols []string) {
+	addIndex(table, "PRIMARY", cols, true)
+	table.Constraints = append(table.Constraints, database.TableConstraint{Name: "PRIMARY", Type: "PRIMARY KEY", Columns: cols})
+	for i := range table.Fields {
+		for _, col := range cols {
+			if strings.EqualFold(table.Fields[i].Name, col) {
+				table.Fields[i].IsPrimary = true
+				table.Fields[i].IsNullable = false
+			}
+		}
+	}
+}
+
+// addIndex 添加索引
+func addIndex(table *TableSchema, name string, cols []string, unique bool) {
+	table.Indexes = append(table.Indexes, database.TableIndex{Name: name, Columns: cols, Unique: unique, Visible: true})
+}
+
+// countForeignKeys 统计外键数量
+func countForeignKeys(table *TableSchema) int {
+	n := 0
+	for _, c := range table.Constraints {
+		if c.Type == "FOREIGN KEY" {
+			n++
+		}
+	}
+	return n
+}
+
+// finishTable 补全表定义：表级主键声明可能早于字段出现，且 InnoDB 会为外键自动创建索引
+func finishTable(table *TableSchema) {
+	for _, c := range table.Constraints {
+		switch c.Type {
+		case "PRIMARY KEY":
+			for i := range table.Fields {
+				for _, col := range c.Columns {
+					if strings.EqualFold(table.Fields[i].Name, col) {
+						table.Fields[i].IsPrimary = true
+						table.Fields[i].IsNullable = false
+					}
+				}
+			}
+		case "FOREIGN KEY":
+			if !hasIndexPrefix(table, c.Columns) {
+				addIndex(table, c.Name, c.Columns, false)
+			}
+		}
+	}
+}
+
+// hasIndexPrefix 判断是否存在以指定字段为前缀的索引
+func hasIndexPrefix(table *TableSchema, cols []string) bool {
+	for _, idx := range table.Indexes {
+		if len(idx.Columns) < len(cols) {
+			continue
+		}
+		match := true
+		for i, col := range cols {
+			if !strings.EqualFold(idx.Columns[i], col) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// splitStatements 将脚本切分为语句，每条语句为一组词法单元
+func splitStatements(script string) ([][]token, error) {
+	var statements [][]token
+	var current []token
+	delimiter := ";"
+
+	for i := 0; i < len(script); {
+		ch := script[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+			i++
+		case strings.HasPrefix(script[i:], "--") || ch == '#':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case len(current) == 0 && hasWordPrefix(script[i:], "DELIMITER"):
+			// mysql 客户端的 DELIMITER 指令
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			delimiter = strings.TrimSpace(script[i+len("DELIMITER") : i+end])
+			if delimiter == "" {
+				// 空分隔符无法切分语句 (也会导致死循环)
+				return nil, fmt.Errorf("empty DELIMITER")
+			}
+			i += end
+		case strings.HasPrefix(script[i:], delimiter):
+			if len(current) > 0 {
+				statements = append(statements, current)
+				current = nil
+			}
+			i += len(delimiter)
+		case ch == '\'' || ch == '"' || ch == '`':
+			text, n, err := readQuoted(script[i:], ch)
+			if err != nil {
+				return nil, err
+			}
+			kind := tokenString
+			if ch != '\'' {
+				kind = tokenIdent
+			}
+			current = append(current, token{kind: kind, text: text})
+			i += n
+		case isWordChar(ch):
+			j := i
+			kind := tokenWord
+			if ch >= '0' && ch <= '9' {
+				kind = tokenNumber
+			}
+			for j < len(script) && (isWordChar(script[j]) || kind == tokenNumber && script[j] == '.') {
+				j++
+			}
+			current = append(current, token{kind: kind, text: script[i:j]})
+			i = j
+		default:
+			current = append(current, token{kind: tokenPunct, text: string(ch)})
+			i++
+		}
+	}
+	if len(current) > 0 {
+		statements = append(statements, current)
+	}
+	return statements, nil
+}
+
+// readQuoted 读取引号包裹的内容，支持重复引号和反斜杠转义，返回内容和消耗的字节数
+func readQuoted(s string, quote byte) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '\\' && quote != '`' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		case ch == quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				b.WriteByte(quote)
+				i++
+				continue
+			}
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+// isWordChar 是否为标识符或数字字符 (含多字节字符)
+func isWordChar(ch byte) bool {
+	return ch == '_' || ch == '$' ||
+		(ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') || ch >= 0x80
+}
+
+// hasWordPrefix 判断是否以指定关键字开头 (忽略大小写)
+func hasWordPrefix(s, word string) bool {
+	return len(s) > len(word) && strings.EqualFold(s[:len(word)], word) && !isWordChar(s[len(word)])
+}
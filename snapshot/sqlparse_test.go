@@ -0,0 +1,274 @@
+package snapshot
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mysqldumpScript mysqldump 导出的典型片段，包含可执行注释和表选项
+const mysqldumpScript = "/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;\n" +
+	"/*!40101 SET NAMES utf8mb4 */;\n" +
+	"/*!40014 SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0 */;\n" +
+	"\n" +
+	"--\n" +
+	"-- Table structure for table `orders`\n" +
+	"--\n" +
+	"\n" +
+	"DROP TABLE IF EXISTS `orders`;\n" +
+	"/*!40101 SET @saved_cs_client     = @@character_set_client */;\n" +
+	"/*!50503 SET character_set_client = utf8mb4 */;\n" +
+	"CREATE TABLE `orders` (\n" +
+	"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+	"  `customer_id` int NOT NULL,\n" +
+	"  `note` varchar(255) COLLATE utf8mb4_bin DEFAULT NULL COMMENT 'it''s a note',\n" +
+	"  `status` enum('new','paid') NOT NULL DEFAULT 'new',\n" +
+	"  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  KEY `idx_customer` (`customer_id`,`created_at`)\n" +
+	") ENGINE=InnoDB AUTO_INCREMENT=42 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='订单';\n" +
+	"/*!40101 SET character_set_client = @saved_cs_client */;\n" +
+	"\n" +
+	"LOCK TABLES `orders` WRITE;\n" +
+	"/*!40000 ALTER TABLE `orders` DISABLE KEYS */;\n" +
+	"INSERT INTO `orders` VALUES (1,7,'a;b','new','2024-01-01 00:00:00.000');\n" +
+	"/*!40000 ALTER TABLE `orders` ENABLE KEYS */;\n" +
+	"UNLOCK TABLES;\n" +
+	"/*!40014 SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS */;\n"
+
+// describeTable 以紧凑的文本描述表的索引和约束，便于在用例中比较
+// 索引为 "名称(字段)"，唯一索引前加 "!"；约束为 "类型 名称(字段)"，外键附加引用的表
+func describeTable(table *TableSchema) (indexes, constraints []string) {
+	for _, idx := range table.Indexes {
+		s := fmt.Sprintf("%s(%s)", idx.Name, strings.Join(idx.Columns, ","))
+		if idx.Unique {
+			s = "!" + s
+		}
+		indexes = append(indexes, s)
+	}
+	for _, c := range table.Constraints {
+		s := fmt.Sprintf("%s %s(%s)", c.Type, c.Name, strings.Join(c.Columns, ","))
+		if c.ReferencedTable != nil {
+			s += " -> " + *c.ReferencedTable
+		}
+		constraints = append(constraints, s)
+	}
+	return indexes, constraints
+}
+
+// parseWithTimeout 解析脚本，超时视为死循环
+func parseWithTimeout(t *testing.T, script string) (*DatabaseSchema, error) {
+	t.Helper()
+	type result struct {
+		schema *DatabaseSchema
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		schema, err := ParseSQL("test", script)
+		done <- result{schema, err}
+	}()
+	select {
+	case r := <-done:
+		return r.schema, r.err
+	case <-time.After(2 * time.Second):
+		t.Fatalf("ParseSQL did not return within 2s")
+		return nil, nil
+	}
+}
+
+func TestParseSQL(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      string
+		wantErr     string
+		tables      []string
+		indexes     map[string][]string
+		constraints map[string][]string
+	}{
+		{
+			name:    "mysqldump output",
+			script:  mysqldumpScript,
+			tables:  []string{"orders"},
+			indexes: map[string][]string{"orders": {"!PRIMARY(id)", "idx_customer(customer_id,created_at)"}},
+		},
+		{
+			name: "DELIMITER ;; with trigger body",
+			script: "CREATE TABLE t (id int PRIMARY KEY, n int);\n" +
+				"DELIMITER ;;\n" +
+				"CREATE TRIGGER trg BEFORE INSERT ON t FOR EACH ROW BEGIN\n" +
+				"  SET NEW.n = 1;\n" +
+				"  SET NEW.n = NEW.n + 1;\n" +
+				"END ;;\n" +
+				"DELIMITER ;\n" +
+				"CREATE TABLE u (id int);\n",
+			tables: []string{"t", "u"},
+		},
+		{
+			name: "mysqldump trigger in executable comments",
+			script: "CREATE TABLE t (id int);\n" +
+				"DELIMITER ;;\n" +
+				"/*!50003 CREATE*/ /*!50017 DEFINER=`root`@`%`*/ /*!50003 TRIGGER trg BEFORE INSERT ON t FOR EACH ROW SET NEW.id = 1 */;;\n" +
+				"DELIMITER ;\n",
+			tables: []string{"t"},
+		},
+		{
+			name:    "empty DELIMITER",
+			script:  "DELIMITER\nCREATE TABLE t (id int);",
+			wantErr: "empty DELIMITER",
+		},
+		{
+			name:    "DELIMITER followed by spaces",
+			script:  "DELIMITER   \t\nCREATE TABLE t (id int);",
+			wantErr: "empty DELIMITER",
+		},
+		{
+			name:   "inline PRIMARY KEY and UNIQUE",
+			script: "CREATE TABLE users (id int PRIMARY KEY, email varchar(100) NOT NULL UNIQUE, code int UNIQUE KEY, alt int KEY);",
+			tables: []string{"users"},
+			indexes: map[string][]string{"users": {
+				"!PRIMARY(id)", "!email(email)", "!code(code)", "!PRIMARY(alt)",
+			}},
+			constraints: map[string][]string{"users": {
+				"PRIMARY KEY PRIMARY(id)", "UNIQUE email(email)", "UNIQUE code(code)", "PRIMARY KEY PRIMARY(alt)",
+			}},
+		},
+		{
+			name: "table-level FOREIGN KEY without index",
+			script: "CREATE TABLE customers (id int PRIMARY KEY);\n" +
+				"CREATE TABLE orders (id int PRIMARY KEY, customer_id int, FOREIGN KEY (customer_id) REFERENCES customers (id));",
+			tables:  []string{"customers", "orders"},
+			indexes: map[string][]string{"orders": {"!PRIMARY(id)", "orders_ibfk_1(customer_id)"}},
+			constraints: map[string][]string{"orders": {
+				"PRIMARY KEY PRIMARY(id)", "FOREIGN KEY orders_ibfk_1(customer_id) -> customers",
+			}},
+		},
+		{
+			name: "FOREIGN KEY covered by an existing index",
+			script: "CREATE TABLE items (id int, order_id int, sku int, KEY idx_order (order_id, sku), " +
+				"CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE);",
+			tables:      []string{"items"},
+			indexes:     map[string][]string{"items": {"idx_order(order_id,sku)"}},
+			constraints: map[string][]string{"items": {"FOREIGN KEY fk_order(order_id) -> orders"}},
+		},
+		{
+			name: "CREATE INDEX, DROP INDEX and RENAME TABLE",
+			script: "CREATE TABLE a (id int, x int, y int);\n" +
+				"CREATE UNIQUE INDEX ux ON a (x);\n" +
+				"CREATE INDEX iy USING BTREE ON a (y DESC);\n" +
+				"DROP INDEX iy ON a;\n" +
+				"RENAME TABLE a TO b;\n",
+			tables:      []string{"b"},
+			indexes:     map[string][]string{"b": {"!ux(x)"}},
+			constraints: map[string][]string{"b": {"UNIQUE ux(x)"}},
+		},
+		{
+			name:   "DROP TABLE",
+			script: "CREATE TABLE a (id int); CREATE TABLE b (id int); DROP TABLE IF EXISTS a, missing;",
+			tables: []string{"b"},
+		},
+		{
+			name:   "delimiter inside string and comment",
+			script: "CREATE TABLE t (c varchar(10) DEFAULT 'a;b' COMMENT \"x;y\" /* ; */, d int); -- ;\n# ;\n",
+			tables: []string{"t"},
+		},
+		{
+			name:    "unterminated single quote",
+			script:  "CREATE TABLE t (c varchar(10) DEFAULT 'abc);",
+			wantErr: "unterminated quoted string",
+		},
+		{
+			name:    "unterminated backtick",
+			script:  "CREATE TABLE `t (id int);",
+			wantErr: "unterminated quoted string",
+		},
+		{
+			name:    "unterminated comment",
+			script:  "CREATE TABLE t (id int); /* trailing",
+			wantErr: "unterminated comment",
+		},
+		{
+			name:    "CREATE TABLE LIKE",
+			script:  "CREATE TABLE a (id int);\nCREATE TABLE b LIKE a;",
+			wantErr: "statement 2: CREATE TABLE b LIKE is not supported",
+		},
+		{
+			name:    "missing column list",
+			script:  "CREATE TABLE t;",
+			wantErr: `expected "("`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parseWithTimeout(t, tt.script)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseSQL error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSQL: %v", err)
+			}
+			var names []string
+			for _, table := range schema.Tables {
+				names = append(names, table.Name)
+			}
+			if !reflect.DeepEqual(names, tt.tables) {
+				t.Fatalf("tables = %v, want %v", names, tt.tables)
+			}
+			for name, want := range tt.indexes {
+				indexes, _ := describeTable(findTable(schema, name))
+				if !reflect.DeepEqual(indexes, want) {
+					t.Errorf("%s indexes = %v, want %v", name, indexes, want)
+				}
+			}
+			for name, want := range tt.constraints {
+				_, constraints := describeTable(findTable(schema, name))
+				if !reflect.DeepEqual(constraints, want) {
+					t.Errorf("%s constraints = %v, want %v", name, constraints, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSQLColumns(t *testing.T) {
+	schema, err := parseWithTimeout(t, mysqldumpScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := findTable(schema, "orders")
+	if table.Comment != "订单" {
+		t.Errorf("table comment = %q, want 订单", table.Comment)
+	}
+
+	str := func(s string) *string { return &s }
+	want := []struct {
+		name     string
+		typ      string
+		nullable bool
+		primary  bool
+		def      *string
+		extra    string
+		comment  string
+	}{
+		{"id", "bigint unsigned", false, true, nil, "auto_increment", ""},
+		{"customer_id", "int", false, false, nil, "", ""},
+		{"note", "varchar(255)", true, false, nil, "", "it's a note"},
+		{"status", "enum('new','paid')", false, false, str("new"), "", ""},
+		{"created_at", "datetime(3)", false, false, str("CURRENT_TIMESTAMP(3)"), "", ""},
+	}
+	if len(table.Fields) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(table.Fields), len(want))
+	}
+	for i, w := range want {
+		f := table.Fields[i]
+		if f.Name != w.name || f.Type != w.typ || f.IsNullable != w.nullable || f.IsPrimary != w.primary ||
+			f.Extra != w.extra || f.Comment != w.comment || !reflect.DeepEqual(f.Default, w.def) {
+			t.Errorf("field %d = %+v, want %+v", i, f, w)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package sqltools
+
+import (
+	"strings"
+)
+
+// indentUnit 缩进单位
+const indentUnit = "    "
+
+// frameKind 缩进帧类型
+type frameKind int
+
+const (
+	frameStatement frameKind = iota // 语句顶层
+	frameSubquery                   // 括号内的子查询
+	frameElements                   // CREATE TABLE 的字段和索引定义列表
+)
+
+// frame 一层缩进上下文
+type frame struct {
+	kind   frameKind
+	base   int    // 子句关键字的缩进层级
+	depth  int    // 帧内普通括号的层级，大于 0 时不换行
+	clause string // 当前所在子句
+	empty  bool   // 帧内尚未输出任何单元
+}
+
+// listClauses 逗号后换行的子句
+var listClauses = map[string]bool{"SELECT": true, "SET": true, "VALUES": true, "ALTER": true}
+
+// conditionClauses AND/OR 前换行的子句
+var conditionClauses = map[string]bool{"WHERE": true, "HAVING": true, "ON": true}
+
+// joinModifiers JOIN 前的修饰词
+var joinModifiers = map[string]bool{
+	"LEFT": true, "RIGHT": true, "INNER": true, "CROSS": true, "NATURAL": true, "FULL": true, "OUTER": true,
+}
+
+// Format 美化 SQL：保留字大写，主要子句换行，选择列表和条件逐项换行，多条语句以空行分隔
+func Format(sql string) (string, error) {
+	tokens, err := lex(sql)
+	if err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for _, stmt := range splitStatements(tokens) {
+		if len(significant(stmt)) == 0 {
+			// 仅含注释的片段原样保留
+			for _, t := range stmt {
+				if t.kind == tokenComment {
+					parts = append(parts, strings.TrimSpace(t.text))
+				}
+			}
+			continue
+		}
+		parts = append(parts, formatStatement(stmt)+";")
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// formatter 单条语句的格式化状态
+type formatter struct {
+	b         strings.Builder
+	frames    []*frame
+	tokens    []token // 去掉空白后的单元
+	pos       int
+	prev      token // 上一个输出的非注释单元
+	prevPrev  token
+	lineStart bool
+	unary     bool // 上一个单元为一元运算符
+	between   bool // 等待 BETWEEN ... AND 中的 AND
+	breakNext bool // 行注释之后必须换行
+	create    bool // CREATE TABLE 语句，等待字段定义的左括号
+}
+
+// formatStatement 格式化单条语句
+func formatStatement(stmt []token) string {
+	f := &formatter{frames: []*frame{{kind: frameStatement, empty: true}}, lineStart: true}
+	for _, t := range stmt {
+		if t.kind != tokenSpace {
+			f.tokens = append(f.tokens, t)
+		}
+	}
+	if sig := significant(f.tokens); len(sig) > 1 && sig[0].is("CREATE") {
+		for _, t := range sig[1:] {
+			if t.is("TABLE") {
+				f.create = true
+				break
+			}
+			if !t.is("TEMPORARY") {
+				break
+			}
+		}
+	}
+
+	for f.pos = 0; f.pos < len(f.tokens); f.pos++ {
+		f.emit(f.tokens[f.pos])
+	}
+	return strings.TrimSpace(f.b.String())
+}
+
+// current 当前缩进帧
+func (f *formatter) current() *frame {
+	return f.frames[len(f.frames)-1]
+}
+
+// peek 返回之后第 n 个非注释单元
+func (f *formatter) peek(n int) token {
+	for i := f.pos + 1; i < len(f.tokens); i++ {
+		if f.tokens[i].kind == tokenComment {
+			continue
+		}
+		if n--; n == 0 {
+			return f.tokens[i]
+		}
+	}
+	return token{}
+}
+
+// newline 换行并缩进到指定层级
+func (f *formatter) newline(indent int) {
+	if f.b.Len() > 0 {
+		f.b.WriteByte('\n')
+	}
+	f.b.WriteString(strings.Repeat(indentUnit, indent))
+	f.lineStart = true
+}
+
+// emit 输出一个单元，并按其语义调整换行和缩进
+func (f *formatter) emit(t token) {
+	cur := f.current()
+	if f.breakNext {
+		f.breakNext = false
+		if cur.empty {
+			f.newline(cur.base)
+		} else {
+			f.newline(cur.base + 1)
+		}
+	}
+
+	switch {
+	case t.kind == tokenComment:
+		f.write(t, strings.TrimSpace(t.text))
+		if !strings.HasPrefix(t.text, "/*") {
+			f.breakNext = true
+		}
+		return
+	case t.kind == tokenWord:
+		f.emitWord(t, cur)
+		return
+	case t.is("("):
+		f.emitOpen(t, cur)
+		return
+	case t.is(")"):
+		if cur.depth > 0 || len(f.frames) == 1 {
+			if cur.depth > 0 {
+				cur.depth--
+			}
+			f.write(t, ")")
+			return
+		}
+		f.frames = f.frames[:len(f.frames)-1]
+		f.newline(cur.base - 1)
+		f.write(t, ")")
+		return
+	case t.is(","):
+		f.write(t, ",")
+		if cur.depth == 0 && (cur.kind == frameElements || listClauses[cur.clause]) {
+			if cur.kind == frameElements {
+				f.newline(cur.base)
+			} else {
+				f.newline(cur.base + 1)
+			}
+		}
+		return
+	}
+	f.write(t, t.text)
+}
+
+// emitWord 输出关键字或名称
+func (f *formatter) emitWord(t token, cur *frame) {
+	up := t.upper()
+	text := t.text
+	if f.shouldUpper(t) {
+		text = up
+	}
+	if !isKeyword(t) || f.prev.is(".") || f.peek(1).is(".") || cur.depth > 0 {
+		f.write(t, text)
+		return
+	}
+
+	next := f.peek(1)
+	breakHere := false
+	switch up {
+	case "SELECT", "WHERE", "HAVING", "LIMIT", "UNION", "EXCEPT", "INTERSECT", "WINDOW":
+		breakHere = !(up == "SELECT" && (f.prev.is("UNION") || f.prev.is("ALL") || f.prev.is("DISTINCT")))
+		cur.clause = up
+	case "FROM":
+		breakHere = !f.prev.is("DELETE")
+		cur.clause = up
+	case "VALUES", "VALUE":
+		if cur.clause != "SET" {
+			breakHere = true
+			cur.clause = "VALUES"
+		}
+	case "GROUP", "ORDER", "PARTITION":
+		if next.is("BY") {
+			breakHere = true
+			cur.clause = up
+		}
+	case "UPDATE":
+		if f.prev.is("KEY") {
+			// ON DUPLICATE KEY UPDATE
+			cur.clause = "SET"
+		} else {
+			cur.clause = up
+		}
+	case "SET":
+		if cur.clause == "UPDATE" {
+			breakHere = true
+			cur.clause = up
+		}
+	case "ALTER", "WITH", "INSERT", "REPLACE", "DELETE":
+		if cur.empty {
+			cur.clause = up
+		}
+	case "JOIN", "STRAIGHT_JOIN":
+		breakHere = !joinModifiers[f.prev.upper()] || f.prev.kind != tokenWord
+		cur.clause = "JOIN"
+	case "LEFT", "RIGHT", "INNER", "CROSS", "NATURAL", "FULL":
+		if next.is("JOIN") || next.is("OUTER") {
+			breakHere = !joinModifiers[f.prev.upper()] || f.prev.kind != tokenWord
+			cur.clause = "JOIN"
+		}
+	case "ON":
+		if next.is("DUPLICATE") {
+			breakHere = true
+			cur.clause = "SET"
+		} else if cur.clause == "JOIN" {
+			cur.clause = "ON"
+		}
+	case "BETWEEN":
+		f.between = true
+	case "AND", "OR", "XOR":
+		if up == "AND" && f.between {
+			f.between = false
+		} else if conditionClauses[cur.clause] {
+			f.newline(cur.base + 1)
+		}
+	}
+
+	if breakHere && !cur.empty {
+		f.newline(cur.base)
+	}
+	f.write(t, text)
+}
+
+// shouldUpper 判断关键字是否应转为大写
+// 非保留关键字同样可以用作表名或别名，且表名可能区分大小写，因此只转换保留字
+func (f *formatter) shouldUpper(t token) bool {
+	return (isReserved(t) || t.is("OFFSET") || t.is("DUPLICATE")) && !f.prev.is(".") && !f.peek(1).is(".")
+}
+
+// emitOpen 输出左括号，子查询和 CREATE TABLE 定义列表会开启新的缩进帧
+func (f *formatter) emitOpen(t token, cur *frame) {
+	next := f.peek(1)
+	switch {
+	case cur.depth == 0 && (next.is("SELECT") || next.is("WITH")):
+		f.write(t, "(")
+		f.frames = append(f.frames, &frame{kind: frameSubquery, base: cur.base + 1, empty: true})
+		f.newline(cur.base + 1)
+	case f.create && cur.kind == frameStatement && cur.depth == 0:
+		f.create = false
+		f.write(t, "(")
+		f.frames = append(f.frames, &frame{kind: frameElements, base: cur.base + 1, empty: true})
+		f.newline(cur.base + 1)
+	default:
+		cur.depth++
+		f.write(t, "(")
+	}
+}
+
+// write 写入文本，按前后单元决定是否插入空格
+func (f *formatter) write(t token, text string) {
+	if !f.lineStart && f.needSpace(t) {
+		f.b.WriteByte(' ')
+	}
+	f.b.WriteString(text)
+	f.lineStart = false
+	if t.kind == tokenComment {
+		return
+	}
+	f.current().empty = false
+
+	f.unary = (t.is("-") || t.is("+") || t.is("~") || t.is("!")) &&
+		(f.prev.kind == tokenPunct && !f.prev.is(")") || isKeyword(f.prev) || f.prev.text == "")
+	f.prevPrev, f.prev = f.prev, t
+}
+
+// needSpace 判断两个单元之间是否需要空格
+func (f *formatter) needSpace(t token) bool {
+	prev := f.prev
+	switch {
+	case prev.text == "":
+		return false
+	case t.is(",") || t.is(")") || t.is("."):
+		return false
+	case prev.is("(") || prev.is(".") || f.unary:
+		return false
+	case t.is("("):
+		// 函数调用和类型参数紧贴括号，表名后的字段列表保留空格
+		if f.prevPrev.kind == tokenWord && tableKeywords[f.prevPrev.upper()] {
+			return true
+		}
+		if cur := f.current(); cur.kind == frameElements && cur.depth == 0 {
+			return true
+		} else if prev.is("VALUES") {
+			// ON DUPLICATE KEY UPDATE 中的 VALUES(col) 为函数
+			return cur.clause != "SET"
+		}
+		if prev.kind == tokenIdent {
+			return false
+		}
+		return !(prev.kind == tokenWord && (!isKeyword(prev) || callableKeywords[prev.upper()]))
+	}
+	return true
+}
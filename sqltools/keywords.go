@@ -0,0 +1,109 @@
+package sqltools
+
+import "strings"
+
+// keywordList MySQL 保留字以及 DDL/DML 中常见的非保留关键字、类型名和选项值
+const keywordList = `
+ACCESSIBLE ACTION ADD AFTER AGAINST ALGORITHM ALL ALTER ALWAYS ANALYZE AND ANY AS ASC ASCII AUTO_INCREMENT
+AVG_ROW_LENGTH BEFORE BETWEEN BIGINT BINARY BIT BLOB BOOL BOOLEAN BOTH BTREE BY CALL CASCADE CASE CHANGE CHAR
+CHARACTER CHARSET CHECK CHECKSUM COLLATE COLUMN COLUMNS COMMENT COMMIT COMMITTED COMPACT COMPRESSED COMPRESSION
+CONNECTION CONSTRAINT CONTINUE CONVERT COPY CREATE CROSS CUBE CURRENT CURRENT_DATE CURRENT_TIME
+CURRENT_TIMESTAMP CURRENT_USER CURSOR DATA DATABASE DATABASES DATE DATETIME DAY DAY_HOUR DAY_MICROSECOND
+DAY_MINUTE DAY_SECOND DEC DECIMAL DECLARE DEFAULT DEFAULT_GENERATED DEFINER DELAYED DELAY_KEY_WRITE DELETE
+DENSE_RANK DESC DESCRIBE DETERMINISTIC DISABLE DISCARD DISK DISTINCT DISTINCTROW DIV DO DOUBLE DROP DUAL
+DUPLICATE DYNAMIC EACH ELSE ELSEIF ENABLE ENCLOSED ENCRYPTION END ENFORCED ENGINE ENUM ESCAPE ESCAPED EVENT
+EXCEPT EXCLUSIVE EXISTS EXIT EXPLAIN EXTENDED FALSE FETCH FIELDS FIRST FIRST_VALUE FIXED FLOAT FLOAT4 FLOAT8
+FOLLOWING FOR FORCE FOREIGN FORMAT FROM FULL FULLTEXT FUNCTION GENERATED GEOMETRY GET GLOBAL GRANT GROUP
+GROUPING GROUPS HASH HAVING HIGH_PRIORITY HOUR HOUR_MICROSECOND HOUR_MINUTE HOUR_SECOND IF IGNORE IMPORT IN
+INDEX INFILE INNER INOUT INPLACE INSENSITIVE INSERT INSERT_METHOD INSTANT INT INT1 INT2 INT3 INT4
+INT8 INTEGER INTERSECT INTERVAL INTO INVISIBLE INVOKER IS ISOLATION ITERATE JOIN JSON JSON_TABLE KEY KEYS
+KEY_BLOCK_SIZE KILL LAG LAST LAST_VALUE LATERAL LEAD LEADING LEAVE LEFT LESS LEVEL LIKE LIMIT LINEAR LINES
+LOAD LOCAL LOCALTIME LOCALTIMESTAMP LOCK LOCKED LONG LONGBLOB LONGTEXT LOOP LOW_PRIORITY MASTER_BIND
+MATCH MAX_ROWS MAXVALUE MEDIUMBLOB MEDIUMINT MEDIUMTEXT MEMORY MERGE MIDDLEINT MINUTE MINUTE_MICROSECOND
+MINUTE_SECOND MIN_ROWS MOD MODE MODIFIES MODIFY MONTH NATIONAL NATURAL NCHAR NO NONE NOT NOWAIT
+NO_WRITE_TO_BINLOG NTH_VALUE NTILE NULL NULLS NUMERIC NVARCHAR OF OFF OFFSET ON ONLINE ONLY OPTIMIZE
+OPTIMIZER_COSTS OPTION OPTIONALLY OR ORDER OTHERS OUT OUTER OUTFILE OVER PACK_KEYS PARSER PARTIAL PARTITION
+PARTITIONS PASSWORD PERCENT_RANK PERSISTENT POINT POLYGON PRECEDING PRECISION PRIMARY PROCEDURE PURGE QUICK
+RANGE RANK READ READS READ_WRITE REAL RECURSIVE REDUNDANT REFERENCES REGEXP RELEASE RENAME REPEAT REPEATABLE
+REPLACE REQUIRE RESIGNAL RESTRICT RETURN RETURNS REVOKE RIGHT RLIKE ROLLBACK ROLLUP ROW ROWS ROW_FORMAT
+ROW_NUMBER SCHEMA SCHEMAS SECOND SECOND_MICROSECOND SECURITY SELECT SENSITIVE SEPARATOR SERIAL SERIALIZABLE
+SESSION SET SHARE SHARED SHOW SIGNAL SIGNED SIMPLE SKIP SMALLINT SPATIAL SPECIFIC SQL SQLEXCEPTION SQLSTATE
+SQLWARNING SQL_BIG_RESULT SQL_CALC_FOUND_ROWS SQL_NO_CACHE SQL_SMALL_RESULT SSL START STARTING STATS_AUTO_RECALC
+STATS_PERSISTENT STATS_SAMPLE_PAGES STATUS STORAGE STORED STRAIGHT_JOIN SYSTEM TABLE TABLES TABLESPACE
+TEMPORARY TEMPTABLE TERMINATED TEXT THAN THEN TIME TIMESTAMP TINYBLOB TINYINT TINYTEXT TO TRAILING TRANSACTION
+TRIGGER TRUE TRUNCATE TYPE UNBOUNDED UNCOMMITTED UNDEFINED UNDO UNION UNIQUE UNKNOWN UNLOCK UNSIGNED UPDATE
+USAGE USE USING UTC_DATE UTC_TIME UTC_TIMESTAMP VALIDATION VALUE VALUES VARBINARY VARCHAR VARCHARACTER
+VARIABLES VARYING VIEW VIRTUAL VISIBLE WAIT WARNINGS WHEN WHERE WHILE WINDOW WITH WITHOUT WORK WRITE XOR
+YEAR YEAR_MONTH ZEROFILL
+`
+
+// keywords 关键字集合 (大写)
+var keywords = func() map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(keywordList) {
+		m[w] = true
+	}
+	return m
+}()
+
+// isKeyword 判断词法单元是否为关键字
+func isKeyword(t token) bool {
+	return t.kind == tokenWord && keywords[t.upper()]
+}
+
+// callableKeywords 紧跟括号时按函数调用或类型参数处理的关键字
+var callableKeywords = map[string]bool{
+	"BIGINT": true, "BINARY": true, "BIT": true, "CHAR": true, "CHARACTER": true, "CONVERT": true,
+	"CURRENT_TIMESTAMP": true, "DATETIME": true, "DEC": true, "DECIMAL": true, "DOUBLE": true, "ENUM": true,
+	"FLOAT": true, "IF": true, "INT": true, "INTEGER": true, "LEFT": true, "MATCH": true,
+	"MEDIUMINT": true, "MOD": true, "NCHAR": true, "NUMERIC": true, "REAL": true, "REPEAT": true, "REPLACE": true,
+	"RIGHT": true, "SMALLINT": true, "TIME": true, "TIMESTAMP": true, "TINYINT": true, "TRUNCATE": true,
+	"VARBINARY": true, "VARCHAR": true, "YEAR": true, "CURRENT_TIME": true, "LOCALTIME": true,
+	"LOCALTIMESTAMP": true, "UTC_TIMESTAMP": true, "RANK": true, "DENSE_RANK": true, "ROW_NUMBER": true,
+	"LAG": true, "LEAD": true, "FIRST_VALUE": true, "LAST_VALUE": true, "NTH_VALUE": true, "NTILE": true,
+	"PERCENT_RANK": true, "JSON_TABLE": true, "GROUPING": true, "DATE": true, "ROW": true,
+}
+
+// reservedList MySQL 8.0 保留字，保留字不能直接用作标识符，可安全地统一大写
+const reservedList = `
+ACCESSIBLE ADD ALL ALTER ANALYZE AND AS ASC BEFORE BETWEEN BIGINT BINARY BLOB BOTH BY CALL CASCADE CASE CHANGE
+CHAR CHARACTER CHECK COLLATE COLUMN CONDITION CONSTRAINT CONTINUE CONVERT CREATE CROSS CUBE CUME_DIST
+CURRENT_DATE CURRENT_TIME CURRENT_TIMESTAMP CURRENT_USER CURSOR DATABASE DATABASES DAY_HOUR DAY_MICROSECOND
+DAY_MINUTE DAY_SECOND DEC DECIMAL DECLARE DEFAULT DELAYED DELETE DENSE_RANK DESC DESCRIBE DETERMINISTIC
+DISTINCT DISTINCTROW DIV DOUBLE DROP DUAL EACH ELSE ELSEIF EMPTY ENCLOSED ESCAPED EXCEPT EXISTS EXIT EXPLAIN
+FALSE FETCH FIRST_VALUE FLOAT FLOAT4 FLOAT8 FOR FORCE FOREIGN FROM FULLTEXT FUNCTION GENERATED GET GRANT GROUP
+GROUPING GROUPS HAVING HIGH_PRIORITY HOUR_MICROSECOND HOUR_MINUTE HOUR_SECOND IF IGNORE IN INDEX INFILE INNER
+INOUT INSENSITIVE INSERT INT INT1 INT2 INT3 INT4 INT8 INTEGER INTERSECT INTERVAL INTO IS ITERATE JOIN JSON_TABLE
+KEY KEYS KILL LAG LAST_VALUE LATERAL LEAD LEADING LEAVE LEFT LIKE LIMIT LINEAR LINES LOAD LOCALTIME
+LOCALTIMESTAMP LOCK LONG LONGBLOB LONGTEXT LOOP LOW_PRIORITY MATCH MAXVALUE MEDIUMBLOB MEDIUMINT MEDIUMTEXT
+MIDDLEINT MINUTE_MICROSECOND MINUTE_SECOND MOD MODIFIES NATURAL NOT NO_WRITE_TO_BINLOG NTH_VALUE NTILE NULL
+NUMERIC OF ON OPTIMIZE OPTIMIZER_COSTS OPTION OPTIONALLY OR ORDER OUT OUTER OUTFILE OVER PARTITION PERCENT_RANK
+PRECISION PRIMARY PROCEDURE PURGE RANGE RANK READ READS READ_WRITE REAL RECURSIVE REFERENCES REGEXP RELEASE
+RENAME REPEAT REPLACE REQUIRE RESIGNAL RESTRICT RETURN REVOKE RIGHT RLIKE ROW ROWS ROW_NUMBER SCHEMA SCHEMAS
+SECOND_MICROSECOND SELECT SENSITIVE SEPARATOR SET SHOW SIGNAL SMALLINT SPATIAL SPECIFIC SQL SQLEXCEPTION
+SQLSTATE SQLWARNING SQL_BIG_RESULT SQL_CALC_FOUND_ROWS SQL_SMALL_RESULT SSL STARTING STORED STRAIGHT_JOIN
+SYSTEM TABLE TERMINATED THEN TINYBLOB TINYINT TINYTEXT TO TRAILING TRIGGER TRUE UNDO UNION UNIQUE UNLOCK
+UNSIGNED UPDATE USAGE USE USING UTC_DATE UTC_TIME UTC_TIMESTAMP VALUES VARBINARY VARCHAR VARCHARACTER VARYING
+VIRTUAL WHEN WHERE WHILE WINDOW WITH WRITE XOR YEAR_MONTH ZEROFILL
+`
+
+// reserved 保留字集合 (大写)
+var reserved = func() map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(reservedList) {
+		m[w] = true
+		keywords[w] = true
+	}
+	return m
+}()
+
+// isReserved 判断词法单元是否为保留字
+func isReserved(t token) bool {
+	return t.kind == tokenWord && reserved[t.upper()]
+}
+
+// tableKeywords 其后紧跟表名的关键字
+var tableKeywords = map[string]bool{
+	"FROM": true, "JOIN": true, "INTO": true, "UPDATE": true, "TABLE": true, "REFERENCES": true,
+	"STRAIGHT_JOIN": true,
+}
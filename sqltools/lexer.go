@@ -0,0 +1,170 @@
+package sqltools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokenWord     tokenKind = iota // 关键字或未加引号的标识符
+	tokenIdent                     // 反引号包裹的标识符
+	tokenString                    // 单引号或双引号字符串
+	tokenNumber                    // 数字
+	tokenVariable                  // 用户变量、系统变量或占位符
+	tokenComment                   // 注释
+	tokenPunct                     // 标点和运算符
+	tokenSpace                     // 空白
+)
+
+// token 词法单元，text 保留原文
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// is 判断是否为指定关键字或标点 (忽略大小写)
+func (t token) is(word string) bool {
+	return (t.kind == tokenWord || t.kind == tokenPunct) && strings.EqualFold(t.text, word)
+}
+
+// upper 关键字的大写形式
+func (t token) upper() string {
+	return strings.ToUpper(t.text)
+}
+
+// operators 多字符运算符，长的在前
+var operators = []string{"<=>", "->>", "<=", ">=", "<>", "!=", ":=", "||", "&&", "->", "<<", ">>"}
+
+// lex 将 SQL 切分为词法单元，保留空白和注释以便原样还原
+func lex(sql string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(sql); {
+		ch := sql[i]
+		start := i
+		kind := tokenPunct
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+			for i < len(sql) && strings.IndexByte(" \t\r\n", sql[i]) >= 0 {
+				i++
+			}
+			kind = tokenSpace
+		case strings.HasPrefix(sql[i:], "-- ") || strings.HasPrefix(sql[i:], "--\n") || sql[i:] == "--" || ch == '#':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			kind = tokenComment
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+			kind = tokenComment
+		case ch == '\'' || ch == '"' || ch == '`':
+			n, err := quotedLength(sql[i:], ch)
+			if err != nil {
+				return nil, err
+			}
+			i += n
+			kind = tokenString
+			if ch == '`' {
+				kind = tokenIdent
+			}
+		case ch == '@' || ch == '?':
+			i++
+			for i < len(sql) && (sql[i] == '@' || isWordChar(sql[i]) || sql[i] == '.') {
+				i++
+			}
+			kind = tokenVariable
+		case ch >= '0' && ch <= '9' || ch == '.' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9' && !afterWord(tokens):
+			for i < len(sql) && (isWordChar(sql[i]) || sql[i] == '.' ||
+				(sql[i] == '+' || sql[i] == '-') && (sql[i-1] == 'e' || sql[i-1] == 'E')) {
+				i++
+			}
+			kind = tokenNumber
+		case isWordChar(ch):
+			for i < len(sql) && isWordChar(sql[i]) {
+				i++
+			}
+			kind = tokenWord
+		default:
+			i++
+			for _, op := range operators {
+				if strings.HasPrefix(sql[start:], op) {
+					i = start + len(op)
+					break
+				}
+			}
+		}
+		tokens = append(tokens, token{kind: kind, text: sql[start:i]})
+	}
+	return tokens, nil
+}
+
+// afterWord 判断上一个单元是否为名称，用于区分 t.1col 与 .5 这样的小数
+func afterWord(tokens []token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	return last.kind == tokenWord || last.kind == tokenIdent
+}
+
+// quotedLength 返回引号包裹内容 (含引号) 的长度，支持重复引号和反斜杠转义
+func quotedLength(s string, quote byte) (int, error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated quoted string")
+}
+
+// isWordChar 是否为标识符或数字字符 (含多字节字符)
+func isWordChar(ch byte) bool {
+	return ch == '_' || ch == '$' ||
+		(ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') || ch >= 0x80
+}
+
+// splitStatements 按分号切分语句，去掉分号本身
+func splitStatements(tokens []token) [][]token {
+	var statements [][]token
+	var current []token
+	for _, t := range tokens {
+		if t.is(";") {
+			statements = append(statements, current)
+			current = nil
+			continue
+		}
+		current = append(current, t)
+	}
+	return append(statements, current)
+}
+
+// significant 过滤掉空白和注释
+func significant(tokens []token) []token {
+	var result []token
+	for _, t := range tokens {
+		if t.kind != tokenSpace && t.kind != tokenComment {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// QuoteIdentifier 用反引号引用标识符
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,235 @@
+package sqltools
+
+import (
+	"strings"
+)
+
+// Qualify 为未指定库名的表引用补全库名，并为标识符加上反引号，空白和注释保持不变
+// 函数名、关键字以及表选项的取值 (如 ENGINE=InnoDB) 不做处理；SHOW 语句原样返回
+func Qualify(sql, databaseName string) (string, error) {
+	tokens, err := lex(sql)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i, stmt := range splitStatements(tokens) {
+		if i > 0 {
+			b.WriteByte(';')
+		}
+		q := &qualifier{database: databaseName, tokens: stmt, clauses: []string{""}, funcs: []bool{false}}
+		b.WriteString(q.run())
+	}
+	return b.String(), nil
+}
+
+// qualifier 单条语句的限定状态
+type qualifier struct {
+	database    string
+	tokens      []token
+	sig         []int    // 非空白、非注释单元的下标
+	out         []string // 输出文本，与 tokens 一一对应
+	first       string   // 语句首个关键字
+	clauses     []string // 各括号层级当前所在的子句
+	funcs       []bool   // 各括号层级是否为函数调用的参数
+	ctes        map[string]bool
+	expectTable bool // 下一个名称为表引用
+	qualified   bool // 表引用已带库名，下一个名称为表名本身
+	rename      bool // ALTER TABLE ... RENAME 之后等待 TO/AS
+	onTable     bool // CREATE/DROP INDEX 或 CREATE TRIGGER 语句，ON 之后为表名
+}
+
+// run 处理整条语句并返回结果
+func (q *qualifier) run() string {
+	q.out = make([]string, len(q.tokens))
+	for i, t := range q.tokens {
+		q.out[i] = t.text
+		if t.kind != tokenSpace && t.kind != tokenComment {
+			q.sig = append(q.sig, i)
+		}
+	}
+	if len(q.sig) == 0 {
+		return strings.Join(q.out, "")
+	}
+	q.first = q.tokens[q.sig[0]].upper()
+	if q.first == "SHOW" {
+		return strings.Join(q.out, "")
+	}
+	q.collectCTEs()
+
+	for k := range q.sig {
+		q.visit(k)
+	}
+	return strings.Join(q.out, "")
+}
+
+// at 返回第 k 个有效单元，越界时返回空单元
+func (q *qualifier) at(k int) token {
+	if k < 0 || k >= len(q.sig) {
+		return token{}
+	}
+	return q.tokens[q.sig[k]]
+}
+
+// collectCTEs 收集 WITH 子句定义的临时结果集名称，它们不属于任何库
+func (q *qualifier) collectCTEs() {
+	q.ctes = make(map[string]bool)
+	for k := range q.sig {
+		t := q.at(k)
+		if t.kind != tokenWord && t.kind != tokenIdent {
+			continue
+		}
+		prev := q.at(k - 1)
+		if !(prev.is("WITH") || prev.is("RECURSIVE") || prev.is(",")) {
+			continue
+		}
+		next := q.at(k + 1)
+		if next.is("AS") || next.is("(") {
+			q.ctes[strings.ToLower(identName(t))] = true
+		}
+	}
+}
+
+// depth 当前括号层级
+func (q *qualifier) depth() int {
+	return len(q.clauses) - 1
+}
+
+// visit 处理第 k 个有效单元
+func (q *qualifier) visit(k int) {
+	t, prev, next := q.at(k), q.at(k-1), q.at(k+1)
+
+	switch t.kind {
+	case tokenPunct:
+		q.visitPunct(t, prev, next)
+		return
+	case tokenWord:
+		if q.expectTable && (t.is("IF") || t.is("NOT") || t.is("EXISTS") || t.is("ONLY")) {
+			return
+		}
+		if isReserved(t) || isKeyword(t) && !q.expectTable {
+			q.expectTable = false
+			q.visitKeyword(t, prev, next)
+			return
+		}
+		if isOptionValue(prev, q.at(k-2)) {
+			return
+		}
+		if next.is("(") && !prev.is(".") && !q.expectTable {
+			return // 函数调用
+		}
+	case tokenIdent:
+	default:
+		q.expectTable = false
+		return
+	}
+
+	name := identName(t)
+	i := q.sig[k]
+	switch {
+	case q.qualified:
+		q.qualified = false
+		q.out[i] = QuoteIdentifier(name)
+	case q.expectTable && next.is("."):
+		q.qualified = true
+		q.out[i] = QuoteIdentifier(name)
+	case q.expectTable && !q.ctes[strings.ToLower(name)]:
+		q.out[i] = QuoteIdentifier(q.database) + "." + QuoteIdentifier(name)
+	default:
+		q.out[i] = QuoteIdentifier(name)
+	}
+	if !q.qualified {
+		q.expectTable = false
+	}
+}
+
+// visitKeyword 根据关键字更新状态
+func (q *qualifier) visitKeyword(t, prev, next token) {
+	up := t.upper()
+	inFunc := q.funcs[q.depth()]
+	switch up {
+	case "FROM":
+		if !inFunc {
+			q.clauses[q.depth()] = up
+			q.expectTable = true
+		}
+	case "JOIN", "STRAIGHT_JOIN", "REFERENCES":
+		q.clauses[q.depth()] = up
+		q.expectTable = true
+	case "INTO":
+		q.expectTable = next.kind == tokenIdent || next.kind == tokenWord && !next.is("OUTFILE") && !next.is("DUMPFILE")
+	case "UPDATE":
+		q.expectTable = q.depth() == 0 && (prev.text == "" || prev.is("LOW_PRIORITY") || prev.is("IGNORE"))
+	case "TABLE", "TABLES":
+		q.clauses[q.depth()] = "TABLE"
+		q.expectTable = next.kind == tokenIdent || next.kind == tokenWord
+	case "TRUNCATE", "DESCRIBE", "DESC", "EXPLAIN":
+		q.expectTable = prev.text == "" && (next.kind == tokenIdent || next.kind == tokenWord && !isReserved(next))
+	case "LIKE":
+		q.expectTable = q.first == "CREATE" && q.depth() == 0
+	case "INDEX", "TRIGGER":
+		q.onTable = q.onTable || q.depth() == 0 && (q.first == "CREATE" || q.first == "DROP")
+	case "ON":
+		q.clauses[q.depth()] = up
+		q.expectTable = q.onTable && q.depth() == 0
+		q.onTable = false
+	case "RENAME":
+		if next.is("TO") || next.is("AS") {
+			q.rename = true
+		} else if q.first == "ALTER" && (next.kind == tokenIdent || next.kind == tokenWord && !isKeyword(next)) {
+			q.expectTable = true
+		}
+	case "TO", "AS":
+		if q.rename || up == "TO" && q.first == "RENAME" {
+			q.rename = false
+			q.expectTable = true
+		}
+	case "WHERE", "SET", "USING", "GROUP", "ORDER", "HAVING", "LIMIT", "UNION", "SELECT", "VALUES":
+		q.clauses[q.depth()] = up
+	}
+}
+
+// visitPunct 处理括号和逗号
+func (q *qualifier) visitPunct(t, prev, next token) {
+	switch {
+	case t.is("("):
+		// 紧跟在名称后的括号为函数调用 (表名后的字段列表除外)
+		fn := prev.kind == tokenWord && !q.expectTable && (!isKeyword(prev) || callableKeywords[prev.upper()])
+		q.clauses = append(q.clauses, "")
+		q.funcs = append(q.funcs, fn)
+		q.expectTable = false
+	case t.is(")"):
+		if q.depth() > 0 {
+			q.clauses = q.clauses[:len(q.clauses)-1]
+			q.funcs = q.funcs[:len(q.funcs)-1]
+		}
+	case t.is(","):
+		switch q.clauses[q.depth()] {
+		case "FROM":
+			q.expectTable = true
+		case "TABLE":
+			// DROP TABLE a, b / RENAME TABLE a TO b, c TO d / LOCK TABLES a READ, b WRITE
+			q.expectTable = q.first == "DROP" || q.first == "RENAME" || q.first == "LOCK"
+		}
+	case t.is("."):
+	default:
+		q.expectTable = false
+	}
+}
+
+// isOptionValue 判断名称是否为表选项或字符集的取值，如 ENGINE=InnoDB、CHARSET utf8mb4
+func isOptionValue(prev, prevPrev token) bool {
+	if prev.is("=") {
+		return isKeyword(prevPrev)
+	}
+	return prev.is("CHARSET") || prev.is("COLLATE") || prev.is("ENGINE") ||
+		prev.is("SET") && prevPrev.is("CHARACTER")
+}
+
+// identName 返回标识符的名称，去掉反引号
+func identName(t token) string {
+	if t.kind != tokenIdent {
+		return t.text
+	}
+	return strings.ReplaceAll(t.text[1:len(t.text)-1], "``", "`")
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltOpenTimeout 等待文件锁的时间，另一个进程 (如 --validate) 持有数据库时不会一直阻塞
+const boltOpenTimeout = 2 * time.Second
+
+// boltModifiedSize 记录值前缀中修改时间 (UnixNano) 的长度
+const boltModifiedSize = 8
+
+// BoltStorage 基于 bbolt 的单文件存储，每个分组一个 bucket
+// 值的前 8 字节保存修改时间，List 无需额外的元数据 bucket
+type BoltStorage struct {
+	db *bolt.DB
+}
+
+// NewBoltStorage 打开 path 指定的 bbolt 数据库文件，目录或文件不存在时创建
+func NewBoltStorage(path string) (*BoltStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return &BoltStorage{db: db}, nil
+}
+
+// Put 写入记录，已存在时覆盖
+func (s *BoltStorage) Put(ctx context.Context, bucket, key string, value []byte) error {
+	if err := validKey(bucket); err != nil {
+		return err
+	}
+	if err := validKey(key); err != nil {
+		return err
+	}
+	data := make([]byte, boltModifiedSize+len(value))
+	binary.BigEndian.PutUint64(data, uint64(time.Now().UnixNano()))
+	copy(data[boltModifiedSize:], value)
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+	if err != nil {
+		return fmt.Errorf("put %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// Get 读取记录
+func (s *BoltStorage) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	var value []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return ErrNotFound
+		}
+		data := b.Get([]byte(key))
+		if len(data) < boltModifiedSize {
+			return ErrNotFound
+		}
+		// 事务结束后 bbolt 返回的切片不再有效，需要复制
+		value = bytes.Clone(data[boltModifiedSize:])
+		return nil
+	})
+	if err == ErrNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get %s/%s: %w", bucket, key, err)
+	}
+	return value, nil
+}
+
+// Delete 删除记录，记录不存在时不报错
+func (s *BoltStorage) Delete(ctx context.Context, bucket, key string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+	if err != nil {
+		return fmt.Errorf("delete %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// List 列出分组中的记录，bbolt 按字节序遍历键，即按键升序
+func (s *BoltStorage) List(ctx context.Context, bucket string) ([]Item, error) {
+	items := []Item{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			if len(v) < boltModifiedSize {
+				return nil
+			}
+			items = append(items, Item{
+				Key:      string(k),
+				Size:     int64(len(v) - boltModifiedSize),
+				Modified: time.Unix(0, int64(binary.BigEndian.Uint64(v))),
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", bucket, err)
+	}
+	return items, nil
+}
+
+// Close 关闭数据库文件
+func (s *BoltStorage) Close() error {
+	return s.db.Close()
+}
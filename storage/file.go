@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileStorage 基于本地目录的存储，每个分组一个子目录，每条记录一个文件
+type FileStorage struct {
+	dir string
+}
+
+// NewFileStorage 创建目录存储，目录不存在时创建
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+	return &FileStorage{dir: dir}, nil
+}
+
+// path 获取记录的文件路径
+func (s *FileStorage) path(bucket, key string) (string, error) {
+	if err := validKey(bucket); err != nil {
+		return "", err
+	}
+	if key == "" {
+		return filepath.Join(s.dir, bucket), nil
+	}
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, bucket, key), nil
+}
+
+// Put 写入记录，先写临时文件再改名，读取时不会看到写了一半的记录
+func (s *FileStorage) Put(ctx context.Context, bucket, key string, value []byte) error {
+	path, err := s.path(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create bucket directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+key+".tmp*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("save %s: %w", path, err)
+	}
+	return nil
+}
+
+// Get 读取记录
+func (s *FileStorage) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	path, err := s.path(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// Delete 删除记录，记录不存在时不报错
+func (s *FileStorage) Delete(ctx context.Context, bucket, key string) error {
+	path, err := s.path(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete %s: %w", path, err)
+	}
+	return nil
+}
+
+// List 列出分组中的记录，忽略临时文件和子目录
+func (s *FileStorage) List(ctx context.Context, bucket string) ([]Item, error) {
+	dir, err := s.path(bucket, "")
+	if err != nil {
+		return nil, err
+	}
+	items := []Item{}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return items, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		items = append(items, Item{Key: entry.Name(), Size: info.Size(), Modified: info.ModTime()})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	return items, nil
+}
+
+// Close 目录存储无需关闭
+func (s *FileStorage) Close() error {
+	return nil
+}
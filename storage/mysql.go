@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/sqltools"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQLStorage 把数据保存在 MySQL 的专用库中
+// 使用独立的连接 (不受 READ_ONLY 限制)，库和表不存在时自动创建
+type MySQLStorage struct {
+	db    *sql.DB
+	table string // 引用后的 `库`.`表`
+}
+
+// NewMySQLStorage 连接 dsn 指定的服务器并在 schema 库中建表，dsn 中的库名会被忽略
+func NewMySQLStorage(ctx context.Context, dsn, schema string) (*MySQLStorage, error) {
+	if dsn == "" {
+		return nil, errors.New("STORAGE_MYSQL_DSN is required for the mysql storage backend")
+	}
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse storage dsn: %w", err)
+	}
+	cfg.DBName = ""
+	cfg.ParseTime = true
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("storage connector: %w", err)
+	}
+	db := sql.OpenDB(connector)
+	db.SetMaxOpenConns(4)
+	db.SetConnMaxLifetime(time.Hour)
+
+	s := &MySQLStorage{
+		db:    db,
+		table: sqltools.QuoteIdentifier(schema) + "." + sqltools.QuoteIdentifier("storage"),
+	}
+	// item_key 使用二进制排序规则，仅大小写不同的键 (如诊断包文件名) 不会互相覆盖
+	for _, stmt := range []string{
+		"CREATE DATABASE IF NOT EXISTS " + sqltools.QuoteIdentifier(schema),
+		"CREATE TABLE IF NOT EXISTS " + s.table + ` (
+			bucket VARCHAR(191) NOT NULL,
+			item_key VARCHAR(191) COLLATE utf8mb4_bin NOT NULL,
+			value LONGBLOB NOT NULL,
+			modified DATETIME(3) NOT NULL,
+			PRIMARY KEY (bucket, item_key)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("prepare storage schema: %w", err)
+		}
+	}
+	if err := s.upgradeKeyCollation(ctx, schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// upgradeKeyCollation 把旧版本创建的 item_key 列改为 utf8mb4_bin
+func (s *MySQLStorage) upgradeKeyCollation(ctx context.Context, schema string) error {
+	var collation sql.NullString
+	err := s.db.QueryRowContext(ctx, `SELECT COLLATION_NAME FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = 'storage' AND COLUMN_NAME = 'item_key'`, schema).Scan(&collation)
+	if err != nil {
+		return fmt.Errorf("check storage schema: %w", err)
+	}
+	if collation.String == "utf8mb4_bin" {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, "ALTER TABLE "+s.table+" MODIFY item_key VARCHAR(191) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL"); err != nil {
+		return fmt.Errorf("upgrade storage schema: %w", err)
+	}
+	return nil
+}
+
+// Put 写入记录，已存在时覆盖
+func (s *MySQLStorage) Put(ctx context.Context, bucket, key string, value []byte) error {
+	if err := validKey(bucket); err != nil {
+		return err
+	}
+	if err := validKey(key); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, "INSERT INTO "+s.table+` (bucket, item_key, value, modified) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE value = VALUES(value), modified = VALUES(modified)`,
+		bucket, key, value, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("put %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// Get 读取记录
+func (s *MySQLStorage) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	var value []byte
+	err := s.db.QueryRowContext(ctx, "SELECT value FROM "+s.table+" WHERE bucket = ? AND item_key = ?", bucket, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get %s/%s: %w", bucket, key, err)
+	}
+	return value, nil
+}
+
+// Delete 删除记录，记录不存在时不报错
+func (s *MySQLStorage) Delete(ctx context.Context, bucket, key string) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM "+s.table+" WHERE bucket = ? AND item_key = ?", bucket, key); err != nil {
+		return fmt.Errorf("delete %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// List 列出分组中的记录
+func (s *MySQLStorage) List(ctx context.Context, bucket string) ([]Item, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT item_key, LENGTH(value), modified FROM "+s.table+" WHERE bucket = ? ORDER BY item_key", bucket)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", bucket, err)
+	}
+	defer rows.Close()
+	items := []Item{}
+	for rows.Next() {
+		var item Item
+		if err := rows.Scan(&item.Key, &item.Size, &item.Modified); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", bucket, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list %s: %w", bucket, err)
+	}
+	return items, nil
+}
+
+// Close 关闭连接
+func (s *MySQLStorage) Close() error {
+	return s.db.Close()
+}
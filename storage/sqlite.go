@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	// 纯 Go 实现的 SQLite 驱动，不依赖 cgo
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStorage 基于 SQLite 单文件数据库的存储，适合需要用 sqlite3 命令行查看数据的环境
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+// NewSQLiteStorage 打开 path 指定的 SQLite 数据库文件并建表，目录或文件不存在时创建
+func NewSQLiteStorage(ctx context.Context, path string) (*SQLiteStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	// SQLite 同一时间只允许一个写入者，单连接避免 SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	_, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS storage (
+		bucket TEXT NOT NULL,
+		item_key TEXT NOT NULL,
+		value BLOB NOT NULL,
+		modified INTEGER NOT NULL,
+		PRIMARY KEY (bucket, item_key)
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("prepare storage schema: %w", err)
+	}
+	return &SQLiteStorage{db: db}, nil
+}
+
+// Put 写入记录，已存在时覆盖
+func (s *SQLiteStorage) Put(ctx context.Context, bucket, key string, value []byte) error {
+	if err := validKey(bucket); err != nil {
+		return err
+	}
+	if err := validKey(key); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO storage (bucket, item_key, value, modified) VALUES (?, ?, ?, ?)
+		ON CONFLICT (bucket, item_key) DO UPDATE SET value = excluded.value, modified = excluded.modified`,
+		bucket, key, value, time.Now().UnixNano())
+	if err != nil {
+		return fmt.Errorf("put %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// Get 读取记录
+func (s *SQLiteStorage) Get(ctx context.Context, bucket, key string) ([]byte, error) {
+	var value []byte
+	err := s.db.QueryRowContext(ctx, "SELECT value FROM storage WHERE bucket = ? AND item_key = ?", bucket, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get %s/%s: %w", bucket, key, err)
+	}
+	return value, nil
+}
+
+// Delete 删除记录，记录不存在时不报错
+func (s *SQLiteStorage) Delete(ctx context.Context, bucket, key string) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM storage WHERE bucket = ? AND item_key = ?", bucket, key); err != nil {
+		return fmt.Errorf("delete %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// List 列出分组中的记录，TEXT 默认使用 BINARY 排序规则，与其他后端一样按字节序
+func (s *SQLiteStorage) List(ctx context.Context, bucket string) ([]Item, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT item_key, LENGTH(value), modified FROM storage WHERE bucket = ? ORDER BY item_key", bucket)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", bucket, err)
+	}
+	defer rows.Close()
+	items := []Item{}
+	for rows.Next() {
+		var item Item
+		var modified int64
+		if err := rows.Scan(&item.Key, &item.Size, &modified); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", bucket, err)
+		}
+		item.Modified = time.Unix(0, modified)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list %s: %w", bucket, err)
+	}
+	return items, nil
+}
+
+// Close 关闭数据库
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/config"
+)
+
+var (
+	// ErrNotFound 记录不存在
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidKey 分组名或键不合法
+	ErrInvalidKey = errors.New("invalid storage key")
+)
+
+// 存储后端
+const (
+	BackendFile   = "file"
+	BackendMySQL  = "mysql"
+	BackendBolt   = "bbolt"
+	BackendSQLite = "sqlite"
+	BackendNone   = "none"
+)
+
+// 数据目录中 bbolt 和 SQLite 后端使用的文件名
+const (
+	boltFileName   = "block-checker.db"
+	sqliteFileName = "block-checker.sqlite"
+)
+
+// maxKeyLength 分组名和键的最大长度
+const maxKeyLength = 191
+
+// Item 存储中的一条记录
+type Item struct {
+	Key      string    `json:"key"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// Storage block-checker 自身数据 (阻塞事件历史、诊断包等) 的持久化存储
+// 数据按分组 (bucket) 保存为键值对，List 按键升序返回
+type Storage interface {
+	Put(ctx context.Context, bucket, key string, value []byte) error
+	Get(ctx context.Context, bucket, key string) ([]byte, error)
+	Delete(ctx context.Context, bucket, key string) error
+	List(ctx context.Context, bucket string) ([]Item, error)
+	Close() error
+}
+
+// New 按配置打开存储后端，配置为 none 或本地后端 (bbolt、sqlite、file) 未指定目录时返回 nil
+func New(ctx context.Context, cfg *config.StorageConfig) (Storage, error) {
+	switch cfg.Backend {
+	case BackendNone:
+		return nil, nil
+	case BackendBolt:
+		if cfg.Dir == "" {
+			return nil, nil
+		}
+		return NewBoltStorage(filepath.Join(cfg.Dir, boltFileName))
+	case BackendSQLite:
+		if cfg.Dir == "" {
+			return nil, nil
+		}
+		return NewSQLiteStorage(ctx, filepath.Join(cfg.Dir, sqliteFileName))
+	case BackendFile:
+		if cfg.Dir == "" {
+			return nil, nil
+		}
+		return NewFileStorage(cfg.Dir)
+	case BackendMySQL:
+		return NewMySQLStorage(ctx, cfg.MySQLDSN, cfg.MySQLSchema)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+}
+
+// validKey 校验分组名或键：不能为空、不能以 . 开头、不能包含路径分隔符
+func validKey(key string) error {
+	switch {
+	case key == "", len(key) > maxKeyLength:
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	case strings.HasPrefix(key, "."), strings.ContainsAny(key, "/\\\x00"):
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"embed"
 	"html/template"
+
+	"github.com/furutachiKurea/block-checker/config"
 )
 
 //go:embed *.html
@@ -18,40 +20,156 @@ var (
 
 var (
 	tableDetailTemplate *template.Template
+	usersTemplate       *template.Template
+	serverTemplate      *template.Template
+	growthTemplate      *template.Template
+	maintenanceTemplate *template.Template
+	enginesTemplate     *template.Template
+	processListTemplate *template.Template
+	blocksTemplate      *template.Template
+	dashboardTemplate   *template.Template
+	debugTraceTemplate  *template.Template
+	logsTemplate        *template.Template
+	logsReportTemplate  *template.Template
+	replicationTemplate *template.Template
+	sessionTemplate     *template.Template
+	connHistoryTemplate *template.Template
+	alertAckTemplate    *template.Template
 )
 
+// funcs 页面模板中可用的函数
+var funcs = template.FuncMap{
+	// formatTime 按 TIME_ZONE 和 TIME_FORMAT_DISPLAY 格式化时间
+	"formatTime": config.FormatDisplayTime,
+}
+
+// parseTemplate 解析页面模板并注册 funcs 中的函数
+func parseTemplate(name string) (*template.Template, error) {
+	return template.New(name).Funcs(funcs).ParseFS(templateFS, name)
+}
+
 // 初始化模板
 func init() {
 	var err error
 
 	// 加载主页模板
-	homeTemplate, err = template.ParseFS(templateFS, "home.html")
+	homeTemplate, err = parseTemplate("home.html")
 	if err != nil {
 		panic("failed to parse home template: " + err.Error())
 	}
 
 	// 加载错误模板
-	errorTemplate, err = template.ParseFS(templateFS, "error.html")
+	errorTemplate, err = parseTemplate("error.html")
 	if err != nil {
 		panic("failed to parse error template: " + err.Error())
 	}
 
 	// 加载数据库列表模板
-	databasesTemplate, err = template.ParseFS(templateFS, "databases.html")
+	databasesTemplate, err = parseTemplate("databases.html")
 	if err != nil {
 		panic("failed to parse databases template: " + err.Error())
 	}
 
 	// 加载表列表模板
-	tablesTemplate, err = template.ParseFS(templateFS, "tables.html")
+	tablesTemplate, err = parseTemplate("tables.html")
 	if err != nil {
 		panic("failed to parse tables template: " + err.Error())
 	}
 	// 加载表结构详情模板
-	tableDetailTemplate, err = template.ParseFS(templateFS, "table_detail.html")
+	tableDetailTemplate, err = parseTemplate("table_detail.html")
 	if err != nil {
 		panic("failed to parse table_detail template: " + err.Error())
 	}
+
+	// 加载账号权限模板
+	usersTemplate, err = parseTemplate("users.html")
+	if err != nil {
+		panic("failed to parse users template: " + err.Error())
+	}
+
+	// 加载服务器状态模板
+	serverTemplate, err = parseTemplate("server.html")
+	if err != nil {
+		panic("failed to parse server template: " + err.Error())
+	}
+
+	// 加载容量增长模板
+	growthTemplate, err = parseTemplate("growth.html")
+	if err != nil {
+		panic("failed to parse growth template: " + err.Error())
+	}
+
+	// 加载维护窗口模板
+	maintenanceTemplate, err = parseTemplate("maintenance.html")
+	if err != nil {
+		panic("failed to parse maintenance template: " + err.Error())
+	}
+
+	// 加载存储引擎模板
+	enginesTemplate, err = parseTemplate("engines.html")
+	if err != nil {
+		panic("failed to parse engines template: " + err.Error())
+	}
+
+	// 加载进程列表模板
+	processListTemplate, err = parseTemplate("processlist.html")
+	if err != nil {
+		panic("failed to parse processlist template: " + err.Error())
+	}
+
+	// 加载阻塞事件历史模板
+	blocksTemplate, err = parseTemplate("blocks_history.html")
+	if err != nil {
+		panic("failed to parse blocks_history template: " + err.Error())
+	}
+
+	// 加载服务器总览模板
+	dashboardTemplate, err = parseTemplate("dashboard.html")
+	if err != nil {
+		panic("failed to parse dashboard template: " + err.Error())
+	}
+
+	// 加载 SQL 追踪页脚模板
+	debugTraceTemplate, err = parseTemplate("debug_trace.html")
+	if err != nil {
+		panic("failed to parse debug_trace template: " + err.Error())
+	}
+
+	// 加载日志页面模板
+	logsTemplate, err = parseTemplate("logs.html")
+	if err != nil {
+		panic("failed to parse logs template: " + err.Error())
+	}
+
+	// 加载日志报告模板
+	logsReportTemplate, err = parseTemplate("logs_report.html")
+	if err != nil {
+		panic("failed to parse logs_report template: " + err.Error())
+	}
+
+	// 加载复制延迟模板
+	replicationTemplate, err = parseTemplate("replication.html")
+	if err != nil {
+		panic("failed to parse replication template: " + err.Error())
+	}
+
+	// 加载会话详情模板
+	sessionTemplate, err = parseTemplate("session.html")
+	if err != nil {
+		panic("failed to parse session template: " + err.Error())
+	}
+
+	// 加载连接历史模板
+	connHistoryTemplate, err = parseTemplate("connection_history.html")
+	if err != nil {
+		panic("failed to parse connection history template: " + err.Error())
+	}
+
+	// 加载告警确认模板
+	alertAckTemplate, err = parseTemplate("alert_ack.html")
+	if err != nil {
+		panic("failed to parse alert ack template: " + err.Error())
+	}
 }
 
 // HomeData 主页数据
@@ -59,18 +177,31 @@ type HomeData struct {
 	Status       string
 	StatusClass  string
 	Timestamp    string
+	Host         string
 	Error        string
 	ErrorDetails *ErrorDetails
+	Threads      *ThreadGauge
+	Compact      bool // 紧凑视图，供手机访问
+}
+
+// ThreadGauge 主页连接数仪表
+type ThreadGauge struct {
+	Connected      int64
+	Running        int64
+	MaxConnections int64
+	Percent        float64
+	WarnPercent    float64 // 超过该百分比时以警告色显示
 }
 
 type ErrorDetails struct {
-	Type       string `json:"type"`
-	Code       string `json:"code,omitempty"`
-	Message    string `json:"message"`
-	Cause      string `json:"cause,omitempty"`
-	Suggestion string `json:"suggestion,omitempty"`
-	Timestamp  string `json:"timestamp"`
-	RetryCount int    `json:"retry_count,omitempty"`
+	Type       string      `json:"type"`
+	Code       string      `json:"code,omitempty"`
+	Message    string      `json:"message"`
+	Cause      string      `json:"cause,omitempty"`
+	Suggestion string      `json:"suggestion,omitempty"`
+	Timestamp  string      `json:"timestamp"`
+	RetryCount int         `json:"retry_count,omitempty"`
+	Network    interface{} `json:"network,omitempty"` // 网络错误时的域名解析和 TCP 连接诊断
 }
 
 // ErrorData 错误页面数据
@@ -88,6 +219,7 @@ type DatabasesData struct {
 type TablesData struct {
 	DatabaseName string
 	Tables       []TableInfo
+	Grants       interface{}
 }
 
 // DatabaseInfo 数据库信息
@@ -108,6 +240,14 @@ type TableDetailData struct {
 	DatabaseName string
 	TableName    string
 	Detail       interface{}
+	Grants       interface{}
+	Shared       *ShareInfo // 通过分享链接访问的快照，为 nil 时为正常页面
+}
+
+// ShareInfo 分享快照的生成和过期时间
+type ShareInfo struct {
+	CreatedAt string
+	ExpiresAt string
 }
 
 func RenderTableDetail(data TableDetailData) (string, error) {
@@ -143,3 +283,231 @@ func RenderTables(data TablesData) (string, error) {
 	err := tablesTemplate.Execute(&buf, data)
 	return buf.String(), err
 }
+
+// UsersData 账号权限页面数据
+type UsersData struct {
+	Users interface{}
+}
+
+// RenderUsers 渲染账号权限页面
+func RenderUsers(data UsersData) (string, error) {
+	var buf bytes.Buffer
+	err := usersTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// ServerData 服务器状态页面数据
+type ServerData struct {
+	Binlog          interface{}
+	BinlogError     string
+	NamedLocks      interface{}
+	NamedLocksError string
+	Pressure        interface{}
+	LockAdvice      interface{}
+	LockAdviceError string
+}
+
+// RenderServer 渲染服务器状态页面
+func RenderServer(data ServerData) (string, error) {
+	var buf bytes.Buffer
+	err := serverTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// GrowthData 容量增长页面数据
+type GrowthData struct {
+	Forecasts interface{}
+}
+
+// RenderGrowth 渲染容量增长页面
+func RenderGrowth(data GrowthData) (string, error) {
+	var buf bytes.Buffer
+	err := growthTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// MaintenanceData 维护窗口页面数据
+type MaintenanceData struct {
+	Windows interface{}
+	Active  interface{}
+}
+
+// RenderMaintenance 渲染维护窗口页面
+func RenderMaintenance(data MaintenanceData) (string, error) {
+	var buf bytes.Buffer
+	err := maintenanceTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// EnginesData 存储引擎页面数据
+type EnginesData struct {
+	Report interface{}
+}
+
+// RenderEngines 渲染存储引擎页面
+func RenderEngines(data EnginesData) (string, error) {
+	var buf bytes.Buffer
+	err := enginesTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// ProcessListData 进程列表页面数据
+type ProcessListData struct {
+	Interval string
+}
+
+// RenderProcessList 渲染进程列表页面
+func RenderProcessList(data ProcessListData) (string, error) {
+	var buf bytes.Buffer
+	err := processListTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// BlockHistoryData 阻塞事件历史页面数据
+type BlockHistoryData struct {
+	Events  interface{}
+	Active  interface{}
+	Compact bool // 紧凑视图，只保留关键列
+}
+
+// RenderBlockHistory 渲染阻塞事件历史页面
+func RenderBlockHistory(data BlockHistoryData) (string, error) {
+	var buf bytes.Buffer
+	err := blocksTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// DashboardData 服务器总览页面数据
+type DashboardData struct {
+	Groups   interface{}
+	GroupBy  string // 分组使用的标签，为空时不分组
+	Selector string // 当前的标签筛选条件
+	Interval string
+}
+
+// RenderDashboard 渲染服务器总览页面
+func RenderDashboard(data DashboardData) (string, error) {
+	var buf bytes.Buffer
+	err := dashboardTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// DebugTraceData SQL 追踪页脚数据
+type DebugTraceData struct {
+	Queries    int
+	Total      string
+	Elapsed    string
+	Statements interface{}
+	Truncated  int
+}
+
+// RenderDebugTrace 渲染附加在页面末尾的 SQL 追踪页脚
+func RenderDebugTrace(data DebugTraceData) (string, error) {
+	var buf bytes.Buffer
+	err := debugTraceTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// LogsData 日志页面数据
+type LogsData struct {
+	Compact bool // 紧凑视图，日志条目只显示单行摘要
+}
+
+// RenderLogs 渲染日志页面
+func RenderLogs(data LogsData) (string, error) {
+	var buf bytes.Buffer
+	err := logsTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// LogsReportData 离线日志报告数据
+type LogsReportData struct {
+	Profile       string
+	Generated     string
+	Limit         int
+	TotalEntries  int
+	LevelCounts   []ReportBar
+	Entries       interface{}
+	Errors        interface{}
+	TotalErrors   int
+	ResolvedCount int
+	Hourly        []ReportBar
+	Daily         []ReportBar
+	Anomalies     interface{}
+	Outages       interface{}
+	Annotations   interface{}
+}
+
+// ReportBar 报告中的一项计数，Percent 为相对最大值的百分比，用于绘制条形图
+type ReportBar struct {
+	Label   string
+	Count   int
+	Percent float64
+	Note    string // 附加说明，如该小时内的连接中断时长和注释
+}
+
+// RenderLogsReport 渲染离线日志报告，样式内联，不依赖脚本和外部资源
+func RenderLogsReport(data LogsReportData) (string, error) {
+	var buf bytes.Buffer
+	err := logsReportTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// ReplicationData 复制延迟页面数据
+type ReplicationData struct {
+	Replicas  interface{}
+	Window    string
+	Windows   []string
+	Threshold int64
+}
+
+// RenderReplication 渲染复制延迟页面
+func RenderReplication(data ReplicationData) (string, error) {
+	var buf bytes.Buffer
+	err := replicationTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// SessionData 会话详情页面数据
+type SessionData struct {
+	Detail interface{}
+}
+
+// RenderSession 渲染会话详情页面
+func RenderSession(data SessionData) (string, error) {
+	var buf bytes.Buffer
+	err := sessionTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// ConnectionHistoryData 连接历史页面数据
+type ConnectionHistoryData struct {
+	Profile  string
+	Profiles []string
+	State    string
+	Since    string
+	Attempts interface{}
+	Failures int
+	Errors   interface{}
+}
+
+// RenderConnectionHistory 渲染连接历史页面
+func RenderConnectionHistory(data ConnectionHistoryData) (string, error) {
+	var buf bytes.Buffer
+	err := connHistoryTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// AlertAckData 告警确认页面数据
+type AlertAckData struct {
+	ID    string
+	Sig   string
+	State interface{} // 告警已恢复或不存在时为 nil
+}
+
+// RenderAlertAck 渲染告警确认页面
+func RenderAlertAck(data AlertAckData) (string, error) {
+	var buf bytes.Buffer
+	err := alertAckTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
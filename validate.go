@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/furutachiKurea/block-checker/alert"
+	"github.com/furutachiKurea/block-checker/checks"
+	"github.com/furutachiKurea/block-checker/config"
+	"github.com/furutachiKurea/block-checker/database"
+	"github.com/furutachiKurea/block-checker/schemagen"
+	"github.com/furutachiKurea/block-checker/storage"
+)
+
+// validateDialTimeout 自检时数据库 TCP 连接的超时
+const validateDialTimeout = 3 * time.Second
+
+// validationReport 启动自检结果
+type validationReport struct {
+	out    io.Writer
+	failed int
+	warned int
+}
+
+// ok 记录通过的检查项
+func (r *validationReport) ok(item, format string, args ...interface{}) {
+	fmt.Fprintf(r.out, "[ OK ] %-12s %s\n", item, fmt.Sprintf(format, args...))
+}
+
+// warn 记录不影响启动但需要注意的检查项
+func (r *validationReport) warn(item, format string, args ...interface{}) {
+	r.warned++
+	fmt.Fprintf(r.out, "[WARN] %-12s %s\n", item, fmt.Sprintf(format, args...))
+}
+
+// fail 记录失败的检查项
+func (r *validationReport) fail(item, format string, args ...interface{}) {
+	r.failed++
+	fmt.Fprintf(r.out, "[FAIL] %-12s %s\n", item, fmt.Sprintf(format, args...))
+}
+
+// runValidation 校验配置、凭据、监听地址、静态资源和数据库可达性，输出报告并返回退出码
+// 模板在程序初始化时解析，解析失败时进程无法运行到此处
+func runValidation(out io.Writer) int {
+	r := &validationReport{out: out}
+
+	validateConfig(r)
+	validateCredentials(r)
+	validateListener(r, config.GetServerConfig())
+	r.ok("templates", "all templates parsed")
+	validateStatic(r)
+	validateStorage(r, config.GetStorageConfig())
+	validateDatabase(r, config.GetDBConfig())
+
+	fmt.Fprintf(out, "\n%d failed, %d warnings\n", r.failed, r.warned)
+	if r.failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// validateConfig 读取全部配置，报告无法解析的环境变量和无效的扩展配置
+func validateConfig(r *validationReport) {
+	config.GetDBConfig()
+	config.GetServerConfig()
+	config.GetGrowthConfig()
+	config.GetPressureConfig()
+	config.GetThreadsConfig()
+	config.GetShareConfig()
+	config.GetTableMetricsConfig()
+	config.GetSnapshotConfig()
+	config.GetMigrationConfig()
+	alertConfig := config.GetAlertConfig()
+	config.GetHeartbeatConfig()
+	config.GetMaskingConfig()
+	schemaGenConfig := config.GetSchemaGenConfig()
+	config.GetErrorAnalysisConfig()
+	config.GetSLOConfig()
+	config.GetQueryBudgetConfig()
+	checksConfig := config.GetChecksConfig()
+	config.GetDictionaryConfig()
+	config.GetProcessListConfig()
+	config.GetBlockHistoryConfig()
+	config.GetDiagnosticsConfig()
+	config.GetStorageConfig()
+	profilesConfig := config.GetProfilesConfig()
+
+	invalid := config.InvalidEnv()
+	keys := make([]string, 0, len(invalid))
+	for key := range invalid {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		r.fail("config", "%s=%q cannot be parsed, default would be used", key, invalid[key])
+	}
+
+	if _, err := schemagen.ParseMapping(schemaGenConfig.TypeMapping); err != nil {
+		r.fail("config", "SCHEMAGEN_TYPE_MAPPING: %v", err)
+	}
+	if _, err := alert.ChatNotifiersFromConfig(alertConfig); err != nil {
+		r.fail("config", "chat notifiers: %v", err)
+	}
+	if _, err := alert.ParseRoutes(alertConfig.Routes); err != nil {
+		r.fail("config", "ALERT_ROUTES: %v", err)
+	}
+	if _, err := alert.ParseEscalations(alertConfig.Escalations); err != nil {
+		r.fail("config", "ALERT_ESCALATIONS: %v", err)
+	}
+	if _, err := alert.ParseThrottle(alertConfig); err != nil {
+		r.fail("config", "alert throttle: %v", err)
+	}
+	if _, err := alert.ParseTableRowRules(alertConfig.TableRows, alertConfig.RowDropPercent, alertConfig.RowStallFor); err != nil {
+		r.fail("config", "ALERT_TABLE_ROWS: %v", err)
+	}
+	if checksConfig.ExecDir != "" {
+		if list, err := checks.ExecChecksFromDir(checksConfig.ExecDir, 0); err != nil {
+			r.fail("config", "CHECKS_EXEC_DIR: %v", err)
+		} else {
+			r.ok("config", "%d external checks in %s", len(list), checksConfig.ExecDir)
+		}
+	}
+	if checksConfig.Commands != "" {
+		if list, err := checks.ParseCommandChecks(checksConfig.Commands); err != nil {
+			r.fail("config", "CHECKS_COMMANDS: %v", err)
+		} else {
+			for _, c := range list {
+				if _, err := exec.LookPath(c.Path()); err != nil {
+					r.warn("config", "CHECKS_COMMANDS: check %s: %v", c.Name(), err)
+				}
+			}
+			r.ok("config", "%d command checks", len(list))
+		}
+	}
+	if pinyinFile := config.GetDictionaryConfig().PinyinFile; pinyinFile != "" {
+		if _, err := database.LoadPinyinTable(pinyinFile); err != nil {
+			r.fail("config", "DICTIONARY_PINYIN_FILE: %v", err)
+		}
+	}
+	for _, profile := range profilesConfig.Profiles {
+		if err := database.ValidateProfile(profile); err != nil {
+			r.fail("config", "PROFILES: %v", err)
+		}
+	}
+	if checksumConfig := config.GetChecksumConfig(); checksumConfig.Enabled() {
+		profiles := map[string]bool{database.DefaultProfile: true}
+		for _, profile := range profilesConfig.Profiles {
+			profiles[profile.Name] = true
+		}
+		for _, name := range []string{checksumConfig.Source, checksumConfig.Replica} {
+			if !profiles[name] {
+				r.fail("config", "CHECKSUM_SOURCE/CHECKSUM_REPLICA: profile %q not in PROFILES", name)
+			}
+		}
+		if checksumConfig.Source == checksumConfig.Replica {
+			r.fail("config", "CHECKSUM_REPLICA: must differ from CHECKSUM_SOURCE")
+		}
+		for _, table := range checksumConfig.Tables {
+			if db, name, ok := strings.Cut(table, "."); !ok || db == "" || name == "" {
+				r.fail("config", "CHECKSUM_TABLES: %q is not database.table", table)
+			}
+		}
+	}
+	if idleTrxConfig := config.GetIdleTrxConfig(); idleTrxConfig.KillAfter > 0 {
+		if len(idleTrxConfig.Schemas) == 0 {
+			r.fail("config", "IDLE_TRX_KILL_SCHEMAS: required when IDLE_TRX_KILL_AFTER is set")
+		} else if !idleTrxConfig.DryRun && config.GetDBConfig().ReadOnly {
+			r.warn("config", "IDLE_TRX_KILL_DRY_RUN=false has no effect in READ_ONLY mode, KILL is rejected")
+		}
+	}
+	if canaryConfig := config.GetCanaryConfig(); canaryConfig.Enabled {
+		if err := database.ValidateIdentifier(canaryConfig.Database); err != nil {
+			r.fail("config", "CANARY_DATABASE: %v", err)
+		} else if database.IsSystemDatabase(canaryConfig.Database) {
+			r.warn("config", "CANARY_DATABASE: %s is a system schema, set a dedicated database", canaryConfig.Database)
+		}
+		if err := database.ValidateIdentifier(canaryConfig.Table); err != nil {
+			r.fail("config", "CANARY_TABLE: %v", err)
+		}
+		if canaryConfig.Interval <= 0 || canaryConfig.Timeout <= 0 {
+			r.fail("config", "CANARY_INTERVAL and CANARY_TIMEOUT must be positive")
+		} else if canaryConfig.Timeout >= canaryConfig.Interval {
+			r.warn("config", "CANARY_TIMEOUT (%v) should be shorter than CANARY_INTERVAL (%v)", canaryConfig.Timeout, canaryConfig.Interval)
+		}
+		if config.GetDBConfig().ReadOnly {
+			r.warn("config", "CANARY_ENABLED has no effect in READ_ONLY mode, writes are rejected")
+		}
+		if config.GetDBConfig().Driver == config.DriverDemo {
+			r.warn("config", "CANARY_ENABLED has no effect with DB_DRIVER=demo")
+		}
+	}
+	if chaosConfig := config.GetChaosConfig(); chaosConfig.Enabled {
+		r.warn("config", "CHAOS_ENABLED: simulated failures can be injected, do not enable in production")
+		if chaosConfig.MaxDuration <= 0 {
+			r.fail("config", "CHAOS_MAX_DURATION must be positive")
+		}
+		if config.GetDBConfig().Driver == config.DriverDemo {
+			r.warn("config", "CHAOS_ENABLED has no effect with DB_DRIVER=demo, faults are injected at the MySQL driver")
+		}
+	}
+	logConfig := config.GetLogConfig()
+	for name := range logConfig.Retention {
+		if _, err := database.ParseLogLevel(name); err != nil {
+			r.fail("config", "LOG_RETENTION: %v", err)
+		}
+	}
+	if logConfig.ClearKeepLevel != "" {
+		if _, err := database.ParseLogLevel(logConfig.ClearKeepLevel); err != nil {
+			r.fail("config", "LOG_CLEAR_KEEP_LEVEL: %v", err)
+		}
+	}
+	if r.failed == 0 {
+		r.ok("config", "environment parsed")
+	}
+}
+
+// validateCredentials 按 DB_AUTH 获取一次密码，确认密码命令或云凭据可用
+func validateCredentials(r *validationReport) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := database.ResolveCredentials(ctx); err != nil {
+		r.fail("credentials", "%v", err)
+		return
+	}
+	r.ok("credentials", "resolved")
+}
+
+// validateListener 确认监听端口或 socket 路径可用
+func validateListener(r *validationReport, cfg *config.ServerConfig) {
+	if cfg.SocketPath != "" {
+		dir := filepath.Dir(cfg.SocketPath)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			r.fail("listen", "socket directory %s does not exist", dir)
+			return
+		}
+		r.ok("listen", "unix socket %s", cfg.SocketPath)
+		return
+	}
+
+	listener, err := net.Listen("tcp", "0.0.0.0:"+cfg.Port)
+	if err != nil {
+		r.fail("listen", "port %s: %v", cfg.Port, err)
+		return
+	}
+	listener.Close()
+	r.ok("listen", "port %s is available", cfg.Port)
+}
+
+// validateStatic 确认静态资源目录存在
+func validateStatic(r *validationReport) {
+	if _, err := os.Stat(filepath.Join("static", "css", "styles.css")); err != nil {
+		r.fail("static", "%v", err)
+		return
+	}
+	r.ok("static", "static assets found")
+}
+
+// validateStorage 打开持久化存储并写入、读取、删除一条测试记录
+func validateStorage(r *validationReport, cfg *config.StorageConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), validateDialTimeout)
+	defer cancel()
+	st, err := storage.New(ctx, cfg)
+	if err != nil {
+		r.fail("storage", "%v", err)
+		return
+	}
+	if st == nil {
+		r.warn("storage", "persistence disabled, block history is kept in memory and diagnostic bundles are not captured")
+		return
+	}
+	defer st.Close()
+	if err := st.Put(ctx, "validate", "probe", []byte("ok")); err != nil {
+		r.fail("storage", "%s backend is not writable: %v", cfg.Backend, err)
+		return
+	}
+	if err := st.Delete(ctx, "validate", "probe"); err != nil {
+		r.fail("storage", "%s backend: %v", cfg.Backend, err)
+		return
+	}
+	r.ok("storage", "%s backend is writable", cfg.Backend)
+}
+
+// validateDatabase 依次尝试连接各数据库主机的 TCP 端口，至少一个可达即通过；演示模式不连接数据库
+func validateDatabase(r *validationReport, cfg *config.DBConfig) {
+	switch cfg.Driver {
+	case config.DriverMySQL:
+	case config.DriverDemo:
+		r.ok("database", "DB_DRIVER=demo, serving in-memory demo data")
+		return
+	default:
+		r.fail("database", "unknown DB_DRIVER %q (expected %s or %s)", cfg.Driver, config.DriverMySQL, config.DriverDemo)
+		return
+	}
+
+	reachable := 0
+	for _, host := range cfg.Hosts {
+		conn, err := net.DialTimeout("tcp", host.Address(), validateDialTimeout)
+		if err != nil {
+			r.warn("database", "%s: %v", host.Address(), err)
+			continue
+		}
+		conn.Close()
+		reachable++
+		r.ok("database", "%s is reachable", host.Address())
+	}
+	if reachable == 0 {
+		r.fail("database", "no database host is reachable")
+	}
+}